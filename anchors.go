@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mmarkdown/mmark/v2/mparser"
+)
+
+// anchorsCommand lists the anchors of documents with the lines they are referenced on, and the references
+// to anchors that don't exist. It returns 1 if there are any of the latter.
+func anchorsCommand(args []string) int {
+	fs := flag.NewFlagSet("anchors", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print the anchors and the dangling references as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "SYNOPSIS: %s anchors [OPTIONS] %s\n", os.Args[0], "FILE...")
+		fmt.Fprintln(fs.Output(), "\nList the anchors of a document and the cross references and citations to them.\n\nOPTIONS:")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	status := 0
+	for _, fileName := range fs.Args() {
+		doc, d, _, err := parseText(fileName)
+		if err != nil {
+			reporter.reportError(fileName, "read", err)
+			status = 1
+			continue
+		}
+		anchors, dangling := mparser.Anchors(doc, d)
+		if len(dangling) > 0 {
+			status = 1
+		}
+
+		if *asJSON {
+			diags := []diagnostic{}
+			for _, diag := range dangling {
+				diags = append(diags, diagnostic{diag.Severity, diag.Check, fileName, diag.Line, diag.Column, diag.Message})
+			}
+			data, _ := json.MarshalIndent(struct {
+				File     string           `json:"file"`
+				Anchors  []mparser.Anchor `json:"anchors"`
+				Dangling []diagnostic     `json:"dangling"`
+			}{fileName, anchors, diags}, "", "  ")
+			fmt.Println(string(data))
+			continue
+		}
+		fmt.Printf("%s\n", fileName)
+		for _, a := range anchors {
+			where := "fetched"
+			if a.Line > 0 {
+				where = "line " + strconv.Itoa(a.Line)
+			}
+			title := ""
+			if a.Title != "" {
+				title = fmt.Sprintf(" %q", a.Title)
+			}
+			refs := "never referenced"
+			if len(a.References) > 0 {
+				lines := make([]string, len(a.References))
+				for i, l := range a.References {
+					lines[i] = strconv.Itoa(l)
+				}
+				refs = strings.Join(lines, ", ")
+			}
+			fmt.Printf("  #%s (%s, %s)%s: %s\n", a.Anchor, a.Kind, where, title, refs)
+		}
+		for _, diag := range dangling {
+			fmt.Printf("%s:%s\n", fileName, diag)
+		}
+	}
+	return status
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io/fs"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/mparser"
+)
+
+// input is a document to render. Name is the name of its output files, without the extension: for a
+// file in a tree (dir/...) this is its path relative to dir, so the tree is kept in the outdir.
+type input struct {
+	file string
+	name string
+	tree bool
+}
+
+// expandArgs returns the inputs for the files in args, an argument ending in "/..." is a tree: all
+// markdown (.md) files in it, and its subdirectories, are rendered.
+func expandArgs(args []string) ([]input, error) {
+	inputs := []input{}
+	for _, arg := range args {
+		root, tree := strings.CutSuffix(arg, "/...")
+		if !tree {
+			name := "mmark"
+			if arg != "os.Stdin" {
+				name = strings.TrimSuffix(filepath.Base(arg), filepath.Ext(arg))
+			}
+			inputs = append(inputs, input{file: arg, name: name})
+			continue
+		}
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || filepath.Ext(path) != ".md" {
+				return err
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			inputs = append(inputs, input{file: path, name: strings.TrimSuffix(rel, ".md"), tree: true})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return inputs, nil
+}
+
+// renderInputs renders the inputs to the targets, the inputs are rendered in parallel by a pool of
+// workers, one for each CPU. When a tree is rendered to HTML, an index page with links to all the
+// documents is written as well, see writeIndex. With -incremental the outputs that are up to date are
+// left alone, see upToDate. It returns the highest status of the inputs.
+func renderInputs(inputs []input, targets []target) int {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		status int
+		titles = map[string]string{} // the titles of the documents, for the index.
+		index  = indexDir(inputs, targets)
+	)
+	work := make(chan input)
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for in := range work {
+				d, err := readDocument(in.file)
+				s := 1
+				switch {
+				case err != nil:
+				case (*flagIncr || *flagWatch) && upToDate(in, d, targets):
+					s = 0 // the outputs are newer than the document.
+				default:
+					s = renderTargets(in, d, targets)
+				}
+				mu.Lock()
+				if s > status {
+					status = s
+				}
+				if index != nil {
+					titles[in.name] = title(d, in.name)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, in := range inputs {
+		work <- in
+	}
+	close(work)
+	wg.Wait()
+
+	if index != nil {
+		if err := writeIndex(*index, inputs, titles); err != nil {
+			reporter.reportError(filepath.Join(*index, IndexFile), "write", err)
+			status = 1
+		}
+	}
+	return status
+}
+
+// indexDir returns the outdir of the HTML target, if a tree is rendered to HTML, otherwise nil.
+func indexDir(inputs []input, targets []target) *string {
+	for _, in := range inputs {
+		if !in.tree {
+			continue
+		}
+		for _, t := range targets {
+			if t.output == "html" && t.file == "" {
+				return &t.outdir
+			}
+		}
+	}
+	return nil
+}
+
+// title returns the title of the document d, from its title block, or name if it has none.
+func title(d []byte, name string) string {
+	ignore := mparser.Initial{Report: func(mparser.Diagnostic) {}} // the conversion reports the title block.
+	if node, _, _ := ignore.Hook(d); node != nil {
+		if t, ok := node.(*mast.Title); ok && t.Title != "" {
+			return t.Title
+		}
+	}
+	return name
+}
+
+// IndexFile is the name of the index page of a tree rendered to HTML.
+const IndexFile = "index.html"
+
+// writeIndex writes the index page to outdir, it links to the HTML of the inputs, with their titles, in
+// the order of their names. If one of the inputs is rendered to the index page itself, no index is
+// written.
+func writeIndex(outdir string, inputs []input, titles map[string]string) error {
+	names := []string{}
+	for _, in := range inputs {
+		if in.name+extensionOf("html") == IndexFile {
+			reporter.report(in.file, mparser.Diagnostic{Message: fmt.Sprintf("not writing an index, the document is rendered to %q", IndexFile),
+				Check: "index", Severity: mparser.SeverityWarning})
+			return nil
+		}
+		names = append(names, in.name)
+	}
+	sort.Strings(names)
+
+	b := &bytes.Buffer{}
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>Index</title>\n</head>\n<body>\n<ul>\n")
+	for _, name := range names {
+		href := (&url.URL{Path: filepath.ToSlash(name) + extensionOf("html")}).String()
+		fmt.Fprintf(b, "<li><a href=\"%s\">%s</a></li>\n", html.EscapeString(href), html.EscapeString(titles[name]))
+	}
+	b.WriteString("</ul>\n</body>\n</html>\n")
+	return writeFile(filepath.Join(outdir, IndexFile), b.Bytes())
+}
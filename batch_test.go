@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderInputs(t *testing.T) {
+	dir := t.TempDir()
+	docs := map[string]string{
+		"a.md":      "%%%\ntitle = \"Document A\"\n%%%\n\n# Introduction\n\nHello A.\n",
+		"sub/b.md":  "# Introduction\n\nHello B.\n",
+		"sub/c.txt": "Not markdown.\n",
+	}
+	for file, d := range docs {
+		file = filepath.Join(dir, "docs", file)
+		os.MkdirAll(filepath.Dir(file), 0755)
+		if err := os.WriteFile(file, []byte(d), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	inputs, err := expandArgs([]string{filepath.Join(dir, "docs") + "/..."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) != 2 || inputs[0].name != "a" || inputs[1].name != filepath.Join("sub", "b") {
+		t.Fatalf("expected inputs a and sub/b, got %v", inputs)
+	}
+
+	build := filepath.Join(dir, "build")
+	targets, _ := outputTargets(nil, "html", build)
+	if status := renderInputs(inputs, targets); status != 0 {
+		t.Fatalf("expected status 0, got %d", status)
+	}
+	for _, file := range []string{"a.html", "sub/b.html"} {
+		if _, err := os.Stat(filepath.Join(build, file)); err != nil {
+			t.Error(err)
+		}
+	}
+	index, err := os.ReadFile(filepath.Join(build, IndexFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, link := range []string{`<a href="a.html">Document A</a>`, `<a href="sub/b.html">sub/b</a>`} {
+		if !strings.Contains(string(index), link) {
+			t.Errorf("expected %q in the index, got %q", link, index)
+		}
+	}
+}
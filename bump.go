@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mmarkdown/mmark/v2/mparser"
+)
+
+// bumpCommand increments the revision of the Internet-Draft in a file, sets its date and prints the new
+// name.
+func bumpCommand(args []string) int {
+	fs := flag.NewFlagSet("bump", flag.ExitOnError)
+	rename := fs.Bool("rename", false, "rename the file when it has the name of the draft, i.e. draft-foo-00.md to draft-foo-01.md")
+	date := fs.String("date", "", "date (YYYY-MM-DD) to set, defaults to the current date")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "SYNOPSIS: %s bump [OPTIONS] %s\n", os.Args[0], "FILE")
+		fmt.Fprintln(fs.Output(), "\nIncrement the revision of an Internet-Draft and update its date.\n\nOPTIONS:")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	day := mparser.Today
+	if *date != "" {
+		d, err := time.Parse("2006-01-02", *date)
+		if err != nil {
+			reporter.report("", mparser.Diagnostic{Message: fmt.Sprintf("couldn't parse date %q: %s", *date, err), Check: "usage", Severity: mparser.SeverityError})
+			return 1
+		}
+		day = d
+	}
+
+	fileName := fs.Arg(0)
+	d, err := os.ReadFile(fileName)
+	if err != nil {
+		reporter.reportError(fileName, "read", err)
+		return 1
+	}
+	d, old, name, err := mparser.Bump(d, day)
+	if err != nil {
+		reporter.reportError(fileName, "bump", err)
+		return 1
+	}
+	newName := fileName
+	if base := filepath.Base(fileName); *rename && strings.Contains(base, old) {
+		newName = filepath.Join(filepath.Dir(fileName), strings.Replace(base, old, name, 1))
+	}
+	if err := writeFile(newName, d); err != nil {
+		reporter.reportError(newName, "write", err)
+		return 1
+	}
+	if newName != fileName {
+		if err := os.Remove(fileName); err != nil {
+			reporter.reportError(fileName, "write", err)
+			return 1
+		}
+	}
+	fmt.Println(name)
+	return 0
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ConfigFile is the name of the configuration file that is searched for in the current directory and
+// its parents.
+const ConfigFile = ".mmark.toml"
+
+// findConfig returns the path of the configuration file in the current directory or the closest parent,
+// or the empty string if there is none.
+func findConfig() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		file := filepath.Join(dir, ConfigFile)
+		if _, err := os.Stat(file); err == nil {
+			return file
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// loadConfig reads the configuration in file and sets the flags in it that aren't given on the command
+// line. The top level keys are flags for all outputs, a table named after an output (html, text, xml,
+// ...) has the flags for that output only:
+//
+//	index = false
+//	bibdir = "refs"
+//
+//	[html]
+//	css = "style.css"
+//
+// A flag that can be repeated is set with an array. The tables are returned, they are applied with
+// apply once the outputs are known.
+func loadConfig(file string) (*outputConfig, error) {
+	config := map[string]interface{}{}
+	if _, err := toml.DecodeFile(file, &config); err != nil {
+		return nil, err
+	}
+	given := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { given[f.Name] = true })
+
+	c := &outputConfig{file: file, tables: map[string]map[string]interface{}{}, given: given}
+	for key, value := range config {
+		if table, ok := value.(map[string]interface{}); ok {
+			c.tables[key] = table
+			continue
+		}
+		if err := setFlag(key, value, given); err != nil {
+			return nil, fmt.Errorf("%s: %s", file, err)
+		}
+	}
+	for key := range c.tables {
+		if !isOutput(key) {
+			return nil, fmt.Errorf("%s: unknown output %q", file, key)
+		}
+	}
+	return c, nil
+}
+
+// outputConfig holds the tables of the configuration file, with the flags for one output.
+type outputConfig struct {
+	file   string
+	tables map[string]map[string]interface{}
+	given  map[string]bool // flags given on the command line.
+}
+
+// apply sets the flags of the table of the output that is rendered. The flags are shared by all
+// outputs, so when more than one output is rendered, with -o or -formats, their tables can't be
+// applied and an error is returned if one of them has a table.
+func (c *outputConfig) apply(outputs []string) error {
+	if len(outputs) > 1 {
+		for _, o := range outputs {
+			if len(c.tables[o]) > 0 {
+				return fmt.Errorf("%s: [%s] can't be used when rendering %d outputs (%s), put its flags on the command line",
+					c.file, o, len(outputs), strings.Join(outputs, ", "))
+			}
+		}
+		return nil
+	}
+	for _, o := range outputs {
+		for key, value := range c.tables[o] {
+			if err := setFlag(key, value, c.given); err != nil {
+				return fmt.Errorf("%s: [%s]: %s", c.file, o, err)
+			}
+		}
+	}
+	return nil
+}
+
+// setFlag sets the flag name to value, unless it was given on the command line.
+func setFlag(name string, value interface{}, given map[string]bool) error {
+	if flag.Lookup(name) == nil || name == "config" {
+		return fmt.Errorf("unknown flag %q", name)
+	}
+	if given[name] {
+		return nil
+	}
+	values, ok := value.([]interface{})
+	if !ok {
+		values = []interface{}{value}
+	}
+	for _, v := range values {
+		if err := flag.Set(name, fmt.Sprint(v)); err != nil {
+			return fmt.Errorf("flag %q: %s", name, err)
+		}
+	}
+	return nil
+}
+
+// outputs are the flags that select an output, "xml" is used when none of them is given.
+var outputs = []struct {
+	name string
+	flag *bool
+}{
+	{"json", flagJSON}, {"pandoc", flagPandoc}, {"html", flagHTML}, {"epub", flagEpub}, {"meta", flagMeta},
+	{"odt", flagODT}, {"slides", flagSlides}, {"man", flagMan}, {"mdoc", flagMdoc}, {"latex", flagLatex},
+	{"jats", flagJATS}, {"asciidoc", flagAsciiDoc}, {"gemtext", flagGemtext}, {"pdf", flagPDF}, {"text", flagText},
+	{"xml", nil},
+}
+
+// output returns the name of the selected output, when more than one is given the first one in outputs
+// is used, as mmark does.
+func output() string {
+	for _, o := range outputs {
+		if o.flag != nil && *o.flag {
+			return o.name
+		}
+	}
+	return "xml"
+}
+
+func isOutput(name string) bool {
+	for _, o := range outputs {
+		if o.name == name {
+			return true
+		}
+	}
+	return false
+}
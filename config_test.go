@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), ConfigFile)
+	config := "index = false\ncss = \"all.css\"\n\n[text]\nwidth = 40\n\n[html]\nwidth = 100\n"
+	if err := os.WriteFile(file, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	*flagText = true
+	defer func() {
+		*flagText, *flagIndex, *flagCSS, *flagWidth = false, true, "", 72
+	}()
+
+	c, err := loadConfig(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.apply(targetOutputs(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if *flagIndex || *flagCSS != "all.css" || *flagWidth != 40 {
+		t.Errorf("expected index=false, css=all.css and width=40, got %t, %s and %d", *flagIndex, *flagCSS, *flagWidth)
+	}
+
+	if err := os.WriteFile(file, []byte("nosuchflag = 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadConfig(file); err == nil {
+		t.Errorf("expected an error for an unknown flag")
+	}
+}
+
+func TestApplyConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), ConfigFile)
+	if err := os.WriteFile(file, []byte("[html]\nhead = \"head.html\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { *flagHead = "" }()
+
+	c, err := loadConfig(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	targets, _ := outputTargets([]string{"draft.html"}, "", "")
+	if err := c.apply(targetOutputs(targets)); err != nil {
+		t.Fatal(err)
+	}
+	if *flagHead != "head.html" {
+		t.Errorf("expected head=head.html for -o draft.html, got %q", *flagHead)
+	}
+
+	*flagHead = ""
+	targets, _ = outputTargets(nil, "xml,html", "dist")
+	if err := c.apply(targetOutputs(targets)); err == nil {
+		t.Errorf("expected an error for [html] with -formats xml,html")
+	}
+	targets, _ = outputTargets(nil, "xml,text", "dist")
+	if err := c.apply(targetOutputs(targets)); err != nil {
+		t.Errorf("expected no error without a table for xml or text, got %s", err)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mmarkdown/mmark/v2/mparser"
+)
+
+// diagnostics reports the diagnostics of mmark on out, as text (the default) or as JSON, one object per
+// line. It is also the output of the log package, which mmark only uses for the errors it can't go on
+// after, see Write. It counts the warnings and errors. It is safe to use from multiple goroutines.
+type diagnostics struct {
+	out  io.Writer
+	json bool
+
+	mu       sync.Mutex
+	logger   *log.Logger // for the text output, so it looks as the rest of the log.
+	warnings int
+	errors   int
+}
+
+// reporter reports the diagnostics of the document conversion.
+var reporter = newDiagnostics(os.Stderr, false)
+
+func newDiagnostics(out io.Writer, asJSON bool) *diagnostics {
+	return &diagnostics{out: out, json: asJSON, logger: log.New(out, "", log.LstdFlags)}
+}
+
+// diagnostic is the JSON of a diagnostic.
+type diagnostic struct {
+	Severity mparser.Severity `json:"severity"`
+	Check    string           `json:"check,omitempty"`
+	File     string           `json:"file,omitempty"`
+	Line     int              `json:"line,omitempty"`
+	Column   int              `json:"column,omitempty"`
+	Message  string           `json:"message"`
+}
+
+// report reports the diagnostic d found in file.
+func (d *diagnostics) report(file string, diag mparser.Diagnostic) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.count(diag.Severity)
+	if d.json {
+		data, _ := json.Marshal(diagnostic{diag.Severity, diag.Check, file, diag.Line, diag.Column, diag.Message})
+		d.out.Write(append(data, '\n'))
+		return
+	}
+	s := diag.String()
+	if diag.Line > 0 && diag.Column > 0 {
+		s = strings.Replace(s, strconv.Itoa(diag.Line)+":", fmt.Sprintf("%d:%d:", diag.Line, diag.Column), 1)
+	}
+	if file == "" {
+		d.logger.Print(s)
+		return
+	}
+	if diag.Line == 0 {
+		s = " " + s
+	}
+	d.logger.Printf("%s:%s", file, s)
+}
+
+// forFile returns a reporter for the diagnostics found in file.
+func (d *diagnostics) forFile(file string) mparser.Reporter {
+	return func(diag mparser.Diagnostic) { d.report(file, diag) }
+}
+
+// reportError reports err, the failure of check in file, as an error. The path of the file is left
+// out of the message when err has it as well.
+func (d *diagnostics) reportError(file, check string, err error) {
+	msg := err.Error()
+	var perr *fs.PathError
+	if errors.As(err, &perr) && perr.Path == file {
+		msg = perr.Op + ": " + perr.Err.Error()
+	}
+	d.report(file, mparser.Diagnostic{Message: msg, Check: check, Severity: mparser.SeverityError})
+}
+
+// reportAll reports all diags found in file, their check is set to check, if it isn't set already.
+func (d *diagnostics) reportAll(file string, diags []mparser.Diagnostic, check string) {
+	for _, diag := range diags {
+		if diag.Check == "" {
+			diag.Check = check
+		}
+		d.report(file, diag)
+	}
+}
+
+func (d *diagnostics) count(s mparser.Severity) {
+	if s == mparser.SeverityError {
+		d.errors++
+		return
+	}
+	d.warnings++
+}
+
+// logLine matches a logged message with a position: "file:line: message" or "file:line:column: message".
+var logLine = regexp.MustCompile(`^(\S+?):(\d+):(?:(\d+):)? (.*)$`)
+
+// Write implements io.Writer, the log package calls it once for every message. As all problems are
+// reported, what is logged is an error mmark can't go on after, i.e. from log.Fatalf. In the text
+// output the message is written as is, in the JSON output it becomes an error, with the file and line
+// if the message starts with those.
+func (d *diagnostics) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.count(mparser.SeverityError)
+	if !d.json {
+		return d.out.Write(p)
+	}
+
+	diag := diagnostic{Severity: mparser.SeverityError, Message: strings.TrimSpace(string(p))}
+	if m := logLine.FindStringSubmatch(diag.Message); m != nil {
+		diag.File, diag.Message = m[1], m[4]
+		diag.Line, _ = strconv.Atoi(m[2])
+		diag.Column, _ = strconv.Atoi(m[3])
+	}
+	data, _ := json.Marshal(diag)
+	d.out.Write(append(data, '\n'))
+	return len(p), nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"io/fs"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/mmarkdown/mmark/v2/mparser"
+)
+
+func TestDiagnosticsJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	d := newDiagnostics(buf, true)
+	d.report("draft.md", mparser.Diagnostic{Line: 3, Column: 7, Message: "bad", Check: "xml2rfc", Severity: mparser.SeverityError})
+	l := log.New(d, "", 0)
+	l.Printf("draft.md:5: failure to read %q", "a.md")
+	l.Print("Unknown slug style \"kebab\"")
+
+	want := []string{
+		`{"severity":"error","check":"xml2rfc","file":"draft.md","line":3,"column":7,"message":"bad"}`,
+		`{"severity":"error","file":"draft.md","line":5,"message":"failure to read \"a.md\""}`,
+		`{"severity":"error","message":"Unknown slug style \"kebab\""}`,
+	}
+	got := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(want, "\n"), buf)
+	}
+	if d.errors != 3 || d.warnings != 0 {
+		t.Errorf("expected 3 errors and no warnings, got %d and %d", d.errors, d.warnings)
+	}
+}
+
+func TestDiagnosticsText(t *testing.T) {
+	buf := &bytes.Buffer{}
+	d := newDiagnostics(buf, false)
+	d.logger.SetFlags(0)
+	d.reportAll("draft.md", []mparser.Diagnostic{{Line: 2, Column: 70, Message: "long"}}, mparser.LintLineLength)
+	if want := "draft.md:2:70: long [line-length]\n"; buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf)
+	}
+
+	buf.Reset()
+	d.forFile("draft.md")(mparser.Diagnostic{Message: "the document is incomplete", Check: "include", Severity: mparser.SeverityError})
+	d.reportError("a.md", "read", &fs.PathError{Op: "open", Path: "a.md", Err: fs.ErrNotExist})
+	want := "draft.md: the document is incomplete [include]\na.md: open: file does not exist [read]\n"
+	if buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf)
+	}
+	if d.errors != 2 || d.warnings != 1 {
+		t.Errorf("expected 2 errors and 1 warning, got %d and %d", d.errors, d.warnings)
+	}
+}
@@ -0,0 +1,258 @@
+// Package diff compares two texts, i.e. two versions of a draft rendered as text, as rfcdiff does: the
+// lines are compared first and the lines that changed are then compared word by word. The result can
+// be written as HTML or as the new text with change bars.
+package diff
+
+import (
+	"bytes"
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Op is the kind of an Edit.
+type Op int
+
+// The edit operations.
+const (
+	Equal Op = iota
+	Delete
+	Insert
+)
+
+// Edit is a piece of text that is in both texts, or only in the old (Delete) or the new one (Insert).
+type Edit struct {
+	Op   Op
+	Text string
+}
+
+// Words returns the edits that turn the text a into b. Adjacent edits with the same Op are merged.
+func Words(a, b string) []Edit {
+	la, lb := lines(a), lines(b)
+	edits := []Edit{}
+	del, ins := []string{}, []string{}
+	flush := func() {
+		if len(del) == 0 && len(ins) == 0 {
+			return
+		}
+		// white space is compared as a single space, so rewrapped text doesn't show up as changed.
+		wa, wb := words(strings.Join(del, "")), words(strings.Join(ins, ""))
+		i, j := 0, 0
+		for _, e := range myers(normalize(wa), normalize(wb)) {
+			switch e.Op {
+			case Equal:
+				edits = append(edits, Edit{Equal, wb[j]})
+				i++
+				j++
+			case Delete:
+				edits = append(edits, Edit{Delete, wa[i]})
+				i++
+			case Insert:
+				edits = append(edits, Edit{Insert, wb[j]})
+				j++
+			}
+		}
+		del, ins = del[:0], ins[:0]
+	}
+	for _, e := range myers(la, lb) {
+		switch e.Op {
+		case Equal:
+			flush()
+			edits = append(edits, e)
+		case Delete:
+			del = append(del, e.Text)
+		case Insert:
+			ins = append(ins, e.Text)
+		}
+	}
+	flush()
+	return merge(edits)
+}
+
+// HTML returns a complete HTML document with the edits, deleted text is in <del> and inserted text in
+// <ins>, both get a color. The text is put in a <pre>, so the layout of the text stays the same.
+func HTML(edits []Edit, title string) []byte {
+	b := &bytes.Buffer{}
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>" + html.EscapeString(title) + "</title>\n")
+	b.WriteString("<style>\ndel { background: #fdd; color: #900; }\nins { background: #dfd; color: #060; text-decoration: none; }\n</style>\n")
+	b.WriteString("</head>\n<body>\n<pre>")
+	for _, e := range edits {
+		text := html.EscapeString(e.Text)
+		switch e.Op {
+		case Equal:
+			b.WriteString(text)
+		case Delete:
+			b.WriteString("<del>" + text + "</del>")
+		case Insert:
+			b.WriteString("<ins>" + text + "</ins>")
+		}
+	}
+	b.WriteString("</pre>\n</body>\n</html>\n")
+	return b.Bytes()
+}
+
+// ChangeBars returns the new text with a change bar, "|", in front of each line that has inserted text
+// or where text was deleted. The other lines are indented by two spaces.
+func ChangeBars(edits []Edit) []byte {
+	b := &bytes.Buffer{}
+	line := &strings.Builder{}
+	changed := false
+	for _, e := range edits {
+		if e.Op != Equal {
+			changed = true
+		}
+		if e.Op == Delete {
+			continue
+		}
+		for _, r := range e.Text {
+			if r != '\n' {
+				line.WriteRune(r)
+				continue
+			}
+			bar(b, line.String(), changed)
+			line.Reset()
+			changed = e.Op == Insert
+		}
+	}
+	if line.Len() > 0 {
+		bar(b, line.String(), changed)
+	}
+	return b.Bytes()
+}
+
+func bar(b *bytes.Buffer, line string, changed bool) {
+	switch {
+	case changed:
+		b.WriteString("| ")
+	case line != "":
+		b.WriteString("  ")
+	}
+	b.WriteString(line + "\n")
+}
+
+// lines splits s into lines, each with its newline.
+func lines(s string) []string {
+	l := strings.SplitAfter(s, "\n")
+	if l[len(l)-1] == "" {
+		l = l[:len(l)-1]
+	}
+	return l
+}
+
+// words splits s into words and the white space between them.
+func words(s string) []string {
+	w := []string{}
+	start := 0
+	space := false
+	for i, r := range s {
+		if i > 0 && unicode.IsSpace(r) != space {
+			w = append(w, s[start:i])
+			start = i
+		}
+		space = unicode.IsSpace(r)
+	}
+	if start < len(s) {
+		w = append(w, s[start:])
+	}
+	return w
+}
+
+// normalize returns w with the white space replaced by a single space.
+func normalize(w []string) []string {
+	n := make([]string, len(w))
+	for i := range w {
+		n[i] = w[i]
+		if strings.TrimSpace(w[i]) == "" {
+			n[i] = " "
+		}
+	}
+	return n
+}
+
+// merge merges adjacent edits with the same Op.
+func merge(edits []Edit) []Edit {
+	merged := []Edit{}
+	for _, e := range edits {
+		if n := len(merged); n > 0 && merged[n-1].Op == e.Op {
+			merged[n-1].Text += e.Text
+			continue
+		}
+		merged = append(merged, e)
+	}
+	return merged
+}
+
+// myers returns the shortest edit script that turns a into b, with Myers' O(ND) algorithm.
+func myers(a, b []string) []Edit {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	trace := [][]int{}
+	for d := 0; d <= max; d++ {
+		// only keep the part of v that was used, to keep the memory at O(D²).
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b, d)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack walks the trace of myers back to create the edits.
+func backtrack(trace [][]int, a, b []string, d int) []Edit {
+	edits := []Edit{}
+	x, y := len(a), len(b)
+	for ; d >= 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d+1] } // v holds k from -d-1 to d+1
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := 0
+		if d > 0 {
+			prevX = at(prevK)
+		}
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, Edit{Equal, a[x]})
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			y--
+			edits = append(edits, Edit{Insert, b[y]})
+		} else {
+			x--
+			edits = append(edits, Edit{Delete, a[x]})
+		}
+	}
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
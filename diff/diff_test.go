@@ -0,0 +1,71 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	a := "The quick brown fox\njumps over\nthe lazy dog.\n"
+	b := "The quick red fox\njumps over\nthe lazy dog.\nThe end.\n"
+	want := []Edit{
+		{Equal, "The quick "},
+		{Delete, "brown"},
+		{Insert, "red"},
+		{Equal, " fox\njumps over\nthe lazy dog.\n"},
+		{Insert, "The end.\n"},
+	}
+	if got := Words(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestWordsRewrap(t *testing.T) {
+	a := "one two\nthree four\n"
+	b := "one 2 two three\nfour\n"
+	want := []Edit{{Equal, "one "}, {Insert, "2 "}, {Equal, "two three\nfour\n"}}
+	if got := Words(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestChangeBars(t *testing.T) {
+	a := "one\ntwo\n\nthree\n"
+	b := "one\n2\n\nthree\nfour\n"
+	want := "  one\n| 2\n\n  three\n| four\n"
+	if got := string(ChangeBars(Words(a, b))); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestMyers(t *testing.T) {
+	for _, tc := range []struct{ a, b []string }{
+		{nil, []string{"a"}},
+		{[]string{"a"}, nil},
+		{[]string{"a", "b", "c", "a", "b", "b", "a"}, []string{"c", "b", "a", "b", "a", "c"}},
+	} {
+		edits := myers(tc.a, tc.b)
+		a, b := []string{}, []string{}
+		for _, e := range edits {
+			if e.Op != Insert {
+				a = append(a, e.Text)
+			}
+			if e.Op != Delete {
+				b = append(b, e.Text)
+			}
+		}
+		if len(a) != len(tc.a) || len(b) != len(tc.b) {
+			t.Fatalf("edits %v don't turn %v into %v", edits, tc.a, tc.b)
+		}
+		for i := range a {
+			if a[i] != tc.a[i] {
+				t.Errorf("edits %v don't turn %v into %v", edits, tc.a, tc.b)
+			}
+		}
+		for i := range b {
+			if b[i] != tc.b[i] {
+				t.Errorf("edits %v don't turn %v into %v", edits, tc.a, tc.b)
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/diff"
+	"github.com/mmarkdown/mmark/v2/lang"
+	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/mparser"
+	"github.com/mmarkdown/mmark/v2/render/text"
+)
+
+// diffCommand renders two versions of a document as text and writes a word level diff of them to
+// standard output, as HTML or as the new text with change bars.
+func diffCommand(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	bars := fs.Bool("text", false, "output the new text with change bars instead of HTML")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "SYNOPSIS: %s diff [OPTIONS] %s\n", os.Args[0], "OLD NEW")
+		fmt.Fprintln(fs.Output(), "\nShow the differences between two versions of a document.\n\nOPTIONS:")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+
+	texts := [2]string{}
+	for i, fileName := range fs.Args() {
+		t, err := diffText(fileName)
+		if err != nil {
+			reporter.reportError(fileName, "read", err)
+			return 1
+		}
+		texts[i] = t
+	}
+	edits := diff.Words(texts[0], texts[1])
+	if *bars {
+		os.Stdout.Write(diff.ChangeBars(edits))
+		return 0
+	}
+	os.Stdout.Write(diff.HTML(edits, fs.Arg(0)+" - "+fs.Arg(1)))
+	return 0
+}
+
+// diffText returns the document in fileName as text, without the title page and pagination, so they
+// don't show up in the diff. References are not fetched.
+func diffText(fileName string) (string, error) {
+	doc, _, documentLanguage, err := parseText(fileName)
+	if err != nil {
+		return "", err
+	}
+	renderer := text.NewRenderer(text.RendererOptions{Flags: text.TextFragment, Language: lang.New(documentLanguage)})
+	return string(markdown.Render(doc, renderer)), nil
+}
+
+// parseText parses the document in fileName for the text renderer, with the transforms that don't need
+// any flags. It returns the document, its source and its language.
+func parseText(fileName string) (ast.Node, []byte, string, error) {
+	d, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	init := mparser.NewInitial(fileName)
+	d = markdown.NormalizeNewlines(d)
+	d = mparser.ExpandVariables(d)
+	d = mparser.DivFences(d)
+	init.Source = d
+	init.Report = reporter.forFile(fileName)
+
+	p := parser.NewWithExtensions(mparser.Extensions)
+	mparser.RegisterInlines(p)
+	documentLanguage := "en"
+	p.Opts = parser.Options{
+		ParserHook: func(data []byte) (ast.Node, []byte, int) {
+			node, data, consumed := init.Hook(data)
+			if t, ok := node.(*mast.Title); ok && t.TitleData.Language != "" {
+				documentLanguage = t.TitleData.Language
+			}
+			return node, data, consumed
+		},
+		ReadIncludeFn: init.ReadInclude,
+	}
+	doc := markdown.Parse(d, p)
+
+	mparser.AddContributors(doc)
+	mparser.AddCallouts(doc)
+	mparser.AddUnicode(doc)
+	mparser.AddCrefs(doc, false)
+	mparser.AddAbbreviations(doc, "")
+	mparser.AddGlossary(doc)
+	mparser.ParseIndexModifiers(doc)
+	mparser.AddRowSpans(doc)
+	mparser.AddImageAttributes(doc)
+	mparser.AddBibliography(doc)
+	mparser.FlattenTableCells(doc)
+	return doc, d, documentLanguage, nil
+}
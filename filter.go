@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/render/json"
+)
+
+// filterFlag is a flag that can be repeated, it holds the filters the AST is piped through.
+type filterFlag []string
+
+func (f *filterFlag) String() string { return "" }
+
+func (f *filterFlag) Set(s string) error {
+	if strings.TrimSpace(s) == "" {
+		return fmt.Errorf("empty filter")
+	}
+	*f = append(*f, s)
+	return nil
+}
+
+// filter pipes the AST doc as JSON, see render/json, through command and returns the AST it writes back.
+// As with Pandoc filters the output the document is rendered to, i.e. "html", is given as the first
+// argument; command itself may have arguments too, "python3 filter.py". What the filter writes to
+// standard error is shown as is.
+func filter(doc ast.Node, source []byte, command, out string) (ast.Node, error) {
+	args := append(strings.Fields(command), out)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(markdown.Render(doc, json.NewRenderer(json.RendererOptions{Source: source})))
+	stdout := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("filter %q: %s", command, err)
+	}
+	doc, err := json.Parse(stdout.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("filter %q: %s", command, err)
+	}
+	return doc, nil
+}
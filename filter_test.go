@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/lang"
+	"github.com/mmarkdown/mmark/v2/mparser"
+	"github.com/mmarkdown/mmark/v2/render/xml"
+)
+
+func TestFilter(t *testing.T) {
+	source := []byte("# Introduction\n\nHello World.\n")
+	p := parser.NewWithExtensions(mparser.Extensions)
+	doc := markdown.Parse(source, p)
+
+	script := filepath.Join(t.TempDir(), "filter")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ntest \"$1\" = html && cat\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	filtered, err := filter(doc, source, script, "html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := filter(doc, source, script, "xml"); err == nil {
+		t.Errorf("expected an error for a failing filter")
+	}
+	text := ast.GetLastChild(ast.GetLastChild(filtered))
+	if leaf := text.AsLeaf(); leaf == nil || string(leaf.Literal) != "Hello World." {
+		t.Errorf("expected the text of the document after filtering, got %#v", text)
+	}
+}
+
+func TestFilterIdentity(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "filter")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ncat\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	render := func(doc ast.Node) string {
+		return string(markdown.Render(doc, xml.NewRenderer(xml.RendererOptions{Flags: xml.CommonFlags, Language: lang.New("en")})))
+	}
+	for _, file := range []string{"testdata/blockquote-attribution.md", "rfc/2100.md"} {
+		doc, source, _, err := parseText(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := render(doc)
+		doc, _, _, _ = parseText(file)
+		filtered, err := filter(doc, source, script, "xml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := render(filtered); got != want {
+			t.Errorf("%s: expected the same XML after an identity filter, got\n%s\nwant\n%s", file, got, want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/mmarkdown/mmark/v2/mparser"
+)
+
+// fmtCommand formats markdown files, see mparser.Format, and writes them to standard output, back to the
+// file with -w, or with -check only lists the files that aren't formatted.
+func fmtCommand(args []string) int {
+	fs := flag.NewFlagSet("fmt", flag.ExitOnError)
+	width := fs.Int("width", 100, "width to wrap paragraphs at, 0 doesn't wrap them")
+	check := fs.Bool("check", false, "list the files that aren't formatted and exit with status 1 if there are any")
+	write := fs.Bool("w", false, "write the result to the file instead of standard output")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "SYNOPSIS: %s fmt [OPTIONS] %s\n", os.Args[0], "[FILE...]")
+		fmt.Fprintln(fs.Output(), "\nFormat mmark markdown.\n\nOPTIONS:")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"os.Stdin"}
+	}
+	status := 0
+	for _, fileName := range files {
+		var (
+			d   []byte
+			err error
+		)
+		if fileName == "os.Stdin" {
+			d, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			d, err = ioutil.ReadFile(fileName)
+		}
+		if err != nil {
+			reporter.reportError(fileName, "read", err)
+			status = 1
+			continue
+		}
+		formatted := mparser.Format(d, *width)
+		switch {
+		case *check:
+			if !bytes.Equal(d, formatted) {
+				fmt.Println(fileName)
+				status = 1
+			}
+		case *write && fileName != "os.Stdin":
+			if bytes.Equal(d, formatted) {
+				continue
+			}
+			if err := writeFile(fileName, formatted); err != nil {
+				reporter.reportError(fileName, "write", err)
+				status = 1
+			}
+		default:
+			os.Stdout.Write(formatted)
+		}
+	}
+	return status
+}
@@ -6,4 +6,5 @@ require (
 	github.com/BurntSushi/toml v1.3.2
 	github.com/gomarkdown/markdown v0.0.0-20230922112808-5421fefb8386
 	github.com/google/go-cmp v0.2.0
+	gopkg.in/yaml.v3 v3.0.1
 )
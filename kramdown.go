@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/mmarkdown/mmark/v2/kramdown"
+)
+
+// kramdownCommand converts kramdown-rfc files to mmark markdown and writes the result to standard output.
+func kramdownCommand(args []string) int {
+	fs := flag.NewFlagSet("kramdown", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "SYNOPSIS: %s kramdown %s\n", os.Args[0], "[FILE...]")
+		fmt.Fprintln(fs.Output(), "\nConvert kramdown-rfc markdown to mmark markdown.")
+	}
+	fs.Parse(args)
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"os.Stdin"}
+	}
+	status := 0
+	for _, fileName := range files {
+		var (
+			d   []byte
+			err error
+		)
+		if fileName == "os.Stdin" {
+			d, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			d, err = ioutil.ReadFile(fileName)
+		}
+		if err != nil {
+			reporter.reportError(fileName, "read", err)
+			status = 1
+			continue
+		}
+		md, err := kramdown.Convert(d, reporter.forFile(fileName))
+		if err != nil {
+			reporter.reportError(fileName, "kramdown", err)
+			status = 1
+			continue
+		}
+		os.Stdout.Write(md)
+	}
+	return status
+}
@@ -0,0 +1,352 @@
+package kramdown
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// status maps the kramdown-rfc category to the status of the seriesInfo.
+var status = map[string]string{
+	"std":      "standard",
+	"bcp":      "bcp",
+	"info":     "informational",
+	"exp":      "experimental",
+	"historic": "historic",
+}
+
+// titleBlock converts the YAML header to a TOML title block.
+func titleBlock(h map[string]interface{}) string {
+	t := &toml{}
+	t.WriteString("%%%\n")
+	t.kv("title", str(h["title"]))
+	t.kv("abbrev", str(h["abbrev"]))
+	t.kv("ipr", str(h["ipr"]))
+	t.kv("area", str(h["area"]))
+	t.kv("workgroup", str(first(h["wg"], h["workgroup"])))
+	t.kv("submissiontype", str(h["submissiontype"]))
+	if b, ok := h["consensus"].(bool); ok {
+		t.WriteString(fmt.Sprintf("consensus = %t\n", b))
+	}
+	t.list("keyword", strs(first(h["kw"], h["keyword"])))
+	t.documents("updates", strs(h["updates"]))
+	t.documents("obsoletes", strs(h["obsoletes"]))
+	if d := date(h["date"]); !d.IsZero() {
+		t.WriteString("date = " + d.Format("2006-01-02T00:00:00Z") + "\n")
+	}
+
+	t.WriteString("\n[seriesInfo]\n")
+	if n := str(h["number"]); n != "" {
+		t.kv("name", "RFC")
+		t.kv("value", n)
+	} else {
+		t.kv("name", "Internet-Draft")
+		t.kv("value", str(h["docname"]))
+	}
+	stream := str(h["submissiontype"])
+	if stream == "" {
+		stream = "IETF"
+	}
+	t.kv("stream", stream)
+	if s, ok := status[str(h["cat"])]; ok {
+		t.kv("status", s)
+	} else if s, ok := status[str(h["category"])]; ok {
+		t.kv("status", s)
+	}
+
+	for _, a := range list(first(h["author"], h["authors"])) {
+		t.author(mapping(a))
+	}
+	t.WriteString("%%%\n")
+	return t.String()
+}
+
+// author outputs an [[author]] table.
+func (t *toml) author(a map[string]interface{}) {
+	t.WriteString("\n[[author]]\n")
+	initials, surname := split(str(a["ins"]))
+	if s := str(a["surname"]); s != "" {
+		surname = s
+	}
+	if s := str(a["initials"]); s != "" {
+		initials = s
+	}
+	t.kv("initials", initials)
+	t.kv("surname", surname)
+	t.kv("fullname", str(a["name"]))
+	t.kv("asciiFullname", str(a["asciiName"]))
+	t.kv("role", str(a["role"]))
+	t.kv("organization", str(first(a["org"], a["organization"])))
+	t.kv("abbrev", str(a["abbrev"]))
+
+	emails := strs(a["email"])
+	if len(emails) == 0 && str(a["phone"]) == "" && str(a["uri"]) == "" && !postal(a) {
+		return
+	}
+	t.WriteString("  [author.address]\n")
+	if len(emails) == 1 {
+		t.kv("  email", emails[0])
+	} else {
+		t.list("  emails", emails)
+	}
+	t.kv("  phone", str(a["phone"]))
+	t.kv("  uri", str(a["uri"]))
+	if !postal(a) {
+		return
+	}
+	t.WriteString("  [author.address.postal]\n")
+	if streets := strs(a["street"]); len(streets) == 1 {
+		t.kv("  street", streets[0])
+	} else {
+		t.list("  streets", streets)
+	}
+	t.kv("  city", str(a["city"]))
+	t.kv("  region", str(a["region"]))
+	t.kv("  code", str(a["code"]))
+	t.kv("  country", str(a["country"]))
+}
+
+// postal returns true if the author has any postal address elements.
+func postal(a map[string]interface{}) bool {
+	for _, k := range []string{"street", "city", "region", "code", "country"} {
+		if a[k] != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// references returns the XML references for the references in the header that have a title, these
+// aren't RFCs or Internet-Drafts that can be included automatically.
+func references(h map[string]interface{}) string {
+	b := &strings.Builder{}
+	for _, kind := range []string{"normative", "informative"} {
+		refs := mapping(h[kind])
+		keys := []string{}
+		for k := range refs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			ref := mapping(refs[k])
+			if str(ref["title"]) == "" {
+				continue
+			}
+			b.WriteString(reference(k, ref))
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// reference returns the XML for a single reference.
+func reference(anchor string, ref map[string]interface{}) string {
+	b := &strings.Builder{}
+	b.WriteString(`<reference anchor="` + escape(anchor) + `"`)
+	if target := str(ref["target"]); target != "" {
+		b.WriteString(` target="` + escape(target) + `"`)
+	}
+	b.WriteString(">\n  <front>\n")
+	b.WriteString("    <title>" + escape(str(ref["title"])) + "</title>\n")
+	for _, a := range list(ref["author"]) {
+		author := mapping(a)
+		if s, ok := a.(string); ok {
+			author = map[string]interface{}{"name": s}
+		}
+		name := str(first(author["name"], author["ins"]))
+		initials, surname := split(str(first(author["ins"], author["name"])))
+		b.WriteString("    <author")
+		if name != "" {
+			b.WriteString(` initials="` + escape(initials) + `" surname="` + escape(surname) + `" fullname="` + escape(name) + `"`)
+		}
+		if org := str(author["org"]); org != "" {
+			b.WriteString(">\n      <organization>" + escape(org) + "</organization>\n    </author>\n")
+			continue
+		}
+		b.WriteString(">\n      <organization/>\n    </author>\n")
+	}
+	b.WriteString("    " + dateElement(ref["date"]) + "\n")
+	b.WriteString("  </front>\n")
+	series := mapping(first(ref["seriesinfo"], ref["seriesInfo"]))
+	keys := []string{}
+	for k := range series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b.WriteString(`  <seriesInfo name="` + escape(k) + `" value="` + escape(str(series[k])) + `"/>` + "\n")
+	}
+	if rc := str(first(ref["refcontent"], ref["rc"])); rc != "" {
+		b.WriteString("  <refcontent>" + escape(rc) + "</refcontent>\n")
+	}
+	if ann := str(ref["ann"]); ann != "" {
+		b.WriteString("  <annotation>" + escape(ann) + "</annotation>\n")
+	}
+	b.WriteString("</reference>\n")
+	return b.String()
+}
+
+// dateElement returns the date element for a reference date, which can be a full date, a year and
+// month or just a year.
+func dateElement(v interface{}) string {
+	if d, ok := v.(time.Time); ok {
+		return fmt.Sprintf(`<date year="%d" month="%s" day="%d"/>`, d.Year(), d.Month(), d.Day())
+	}
+	s := str(v)
+	for _, layout := range []string{"2006-01-02", "2006-01"} {
+		if d, err := time.Parse(layout, s); err == nil {
+			if layout == "2006-01" {
+				return fmt.Sprintf(`<date year="%d" month="%s"/>`, d.Year(), d.Month())
+			}
+			return fmt.Sprintf(`<date year="%d" month="%s" day="%d"/>`, d.Year(), d.Month(), d.Day())
+		}
+	}
+	if s == "" || s == "false" {
+		return "<date/>"
+	}
+	return `<date year="` + escape(s) + `"/>`
+}
+
+// date returns the date of the document.
+func date(v interface{}) time.Time {
+	if d, ok := v.(time.Time); ok {
+		return d
+	}
+	d, _ := time.Parse("2006-01-02", str(v))
+	return d
+}
+
+// split splits a name like "J. Doe" into the initials and the surname.
+func split(name string) (string, string) {
+	i := strings.LastIndex(name, " ")
+	if i < 0 {
+		return "", name
+	}
+	return name[:i], name[i+1:]
+}
+
+// toml helps writing a TOML title block.
+type toml struct{ strings.Builder }
+
+// kv writes key = "value", if value isn't empty.
+func (t *toml) kv(key, value string) {
+	if value == "" {
+		return
+	}
+	t.WriteString(key + " = " + quote(value) + "\n")
+}
+
+// list writes key = ["value", ...], if values isn't empty.
+func (t *toml) list(key string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	q := make([]string, len(values))
+	for i := range values {
+		q[i] = quote(values[i])
+	}
+	t.WriteString(key + " = [" + strings.Join(q, ", ") + "]\n")
+}
+
+// documents writes key = [value, ...], RFC numbers are written as numbers and draft names as strings.
+func (t *toml) documents(key string, values []string) {
+	docs := []string{}
+	for _, v := range values {
+		for _, n := range strings.Split(v, ",") {
+			n = strings.TrimSpace(n)
+			if n == "" {
+				continue
+			}
+			if _, err := strconv.Atoi(strings.TrimPrefix(n, "RFC")); err == nil {
+				docs = append(docs, strings.TrimPrefix(n, "RFC"))
+				continue
+			}
+			docs = append(docs, quote(n))
+		}
+	}
+	if len(docs) > 0 {
+		t.WriteString(key + " = [" + strings.Join(docs, ", ") + "]\n")
+	}
+}
+
+// quote returns s as a TOML basic string.
+func quote(s string) string {
+	b := &strings.Builder{}
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteString(`\` + string(r))
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r < 0x20:
+			fmt.Fprintf(b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func escape(s string) string {
+	b := &strings.Builder{}
+	xml.EscapeText(b, []byte(s))
+	return b.String()
+}
+
+// first returns the first non nil value.
+func first(vs ...interface{}) interface{} {
+	for _, v := range vs {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// str returns v as a string.
+func str(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case time.Time:
+		return v.Format("2006-01-02")
+	}
+	return fmt.Sprint(v)
+}
+
+// strs returns v as a list of strings, a single value is a list with one element.
+func strs(v interface{}) []string {
+	s := []string{}
+	for _, e := range list(v) {
+		if e := str(e); e != "" {
+			s = append(s, e)
+		}
+	}
+	return s
+}
+
+// list returns v as a list, a single value is a list with one element.
+func list(v interface{}) []interface{} {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return v
+	}
+	return []interface{}{v}
+}
+
+// mapping returns v as a mapping, or an empty one if it isn't.
+func mapping(v interface{}) map[string]interface{} {
+	if m, ok := v.(map[string]interface{}); ok {
+		return m
+	}
+	return map[string]interface{}{}
+}
@@ -0,0 +1,346 @@
+// Package kramdown converts kramdown-rfc documents to mmark markdown. The YAML header is converted to a
+// TOML title block, {{...}} citations and cross references become mmark citations and cross
+// references, and IALs ({: ...}) are moved in front of the block they apply to.
+package kramdown
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mmarkdown/mmark/v2/mast"
+	"gopkg.in/yaml.v3"
+)
+
+// converter holds the state of a conversion.
+type converter struct {
+	normative   map[string]bool
+	informative map[string]bool
+	cited       map[string]bool
+	bcp14       bool // the document uses the bcp14 boilerplate, so keywords are made strong
+	report      mast.Reporter
+}
+
+// Convert converts the kramdown-rfc document in data to mmark markdown. What can't be converted as is,
+// i.e. a normative reference that isn't cited, is reported with report, if nil it is logged.
+func Convert(data []byte, report mast.Reporter) ([]byte, error) {
+	c := &converter{normative: map[string]bool{}, informative: map[string]bool{}, cited: map[string]bool{}, report: report}
+
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	header := map[string]interface{}{}
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		end := 1
+		for end < len(lines) && !strings.HasPrefix(lines[end], "---") {
+			end++
+		}
+		if err := yaml.Unmarshal([]byte(strings.Join(lines[1:end], "\n")), &header); err != nil {
+			return nil, err
+		}
+		if end < len(lines) && strings.TrimSpace(lines[end]) == "---" {
+			end++
+		}
+		lines = lines[end:]
+	}
+	for k := range mapping(header["normative"]) {
+		c.normative[k] = true
+	}
+	for k := range mapping(header["informative"]) {
+		c.informative[k] = true
+	}
+
+	// split the document in its parts, text before any part marker is put in the middle.
+	parts := map[string][]string{}
+	notes := []string{}
+	part := "middle"
+	for _, l := range lines {
+		if strings.HasPrefix(l, "--- ") {
+			part = strings.TrimSpace(l[4:])
+			if strings.HasPrefix(part, "note_") {
+				notes = append(notes, part)
+			}
+			continue
+		}
+		parts[part] = append(parts[part], l)
+	}
+	for _, l := range append(parts["abstract"], parts["middle"]...) {
+		if strings.Contains(l, "{::boilerplate bcp14") {
+			c.bcp14 = true
+		}
+	}
+
+	out := &bytes.Buffer{}
+	if len(header) > 0 {
+		out.WriteString(titleBlock(header))
+		out.WriteString("\n")
+	}
+	if abstract := c.body(parts["abstract"]); strings.TrimSpace(abstract) != "" {
+		out.WriteString(".# Abstract\n\n" + abstract + "\n\n")
+	}
+	for _, n := range notes {
+		title := strings.ReplaceAll(strings.TrimPrefix(n, "note_"), "_", " ")
+		out.WriteString(".# " + title + "\n\n" + c.body(parts[n]) + "\n\n")
+	}
+	if len(header) > 0 || len(parts) > 1 {
+		out.WriteString("{mainmatter}\n\n")
+	}
+	out.WriteString(c.body(parts["middle"]) + "\n")
+	back := c.body(parts["back"])
+
+	if uncited := c.uncited(); len(uncited) > 0 {
+		out.WriteString("\n[" + strings.Join(uncited, "; ") + "]\n")
+	}
+
+	refs := references(header)
+	if strings.TrimSpace(back) != "" || refs != "" {
+		out.WriteString("\n{backmatter}\n\n")
+		out.WriteString(refs)
+		out.WriteString(back)
+	}
+	return append(bytes.TrimRight(out.Bytes(), "\n"), '\n'), nil
+}
+
+// uncited returns suppressed citations for the references that are listed in the header, but not
+// cited in the text. Kramdown-rfc includes them in the references sections.
+func (c *converter) uncited() []string {
+	cites := []string{}
+	for _, refs := range []map[string]bool{c.normative, c.informative} {
+		keys := []string{}
+		for k := range refs {
+			if !c.cited[k] {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if c.normative[k] {
+				c.report.Reportf(mast.SeverityWarning, "reference", "normative reference %q is not cited, it will be added as an informative reference", k)
+			}
+			cites = append(cites, "@-"+k)
+		}
+	}
+	return cites
+}
+
+var (
+	ialLine = regexp.MustCompile(`^\s*\{:\s*(.*?)\s*\}\s*$`)
+	fence   = regexp.MustCompile("^(```+|~~~+)")
+)
+
+// body converts the lines of a part of the document.
+func (c *converter) body(lines []string) string {
+	out := []string{}
+	start := -1 // index in out of the first line of the current block
+	blank := true
+	inFence := ""
+	comment := false
+
+	for _, l := range lines {
+		if inFence != "" {
+			out = append(out, l)
+			if strings.HasPrefix(strings.TrimSpace(l), inFence) {
+				inFence = ""
+			}
+			continue
+		}
+		if comment {
+			if t := strings.TrimSpace(l); t == "{:/comment}" || t == "{:/}" {
+				out = append(out, "-->")
+				comment = false
+				continue
+			}
+			out = append(out, l)
+			continue
+		}
+
+		trimmed := strings.TrimSpace(l)
+		switch {
+		case trimmed == "":
+			out = append(out, l)
+			blank = true
+			continue
+		case trimmed == "{::comment}":
+			out = append(out, "<!--")
+			comment = true
+			continue
+		case strings.HasPrefix(trimmed, "{::boilerplate bcp14"):
+			out = append(out, strings.Split(bcp14, "\n")...)
+			c.cited["RFC2119"], c.cited["RFC8174"] = true, true
+			continue
+		case strings.HasPrefix(trimmed, "{::boilerplate"):
+			continue
+		}
+
+		if m := ialLine.FindStringSubmatch(l); m != nil {
+			attrs, title := attributes(m[1])
+			if blank || start < 0 {
+				// the IAL precedes the block
+				start = len(out)
+				if attrs != "" {
+					out = append(out, "{"+attrs+"}")
+				}
+				blank = false
+				continue
+			}
+			if title != "" {
+				switch {
+				case fence.MatchString(strings.TrimSpace(out[start])):
+					out = append(out, "Figure: "+title)
+				case strings.Contains(out[start], "|"):
+					out = append(out, "Table: "+title)
+				default:
+					attrs = strings.TrimSpace(attrs + ` title="` + title + `"`)
+				}
+			}
+			if attrs != "" {
+				out = append(out[:start], append([]string{"{" + attrs + "}"}, out[start:]...)...)
+			}
+			continue
+		}
+
+		if m := fence.FindString(trimmed); m != "" {
+			if blank || !strings.HasPrefix(out[len(out)-1], "{") {
+				start = len(out)
+			}
+			inFence = m
+			out = append(out, l)
+			blank = false
+			continue
+		}
+		if blank || strings.HasPrefix(trimmed, "#") {
+			start = len(out)
+		}
+		out = append(out, c.inline(l))
+		blank = false
+	}
+	return strings.Trim(strings.Join(out, "\n"), "\n")
+}
+
+// attributes converts the contents of an IAL to mmark attributes, a title attribute is returned
+// separately.
+func attributes(ial string) (attrs string, title string) {
+	a := []string{}
+	for _, t := range tokens(ial) {
+		i := strings.Index(t, "=")
+		if i < 0 {
+			a = append(a, t)
+			continue
+		}
+		key, value := t[:i], strings.Trim(t[i+1:], `"'`)
+		if key == "title" {
+			title = value
+			continue
+		}
+		a = append(a, key+`="`+strings.ReplaceAll(value, `"`, `&quot;`)+`"`)
+	}
+	return strings.Join(a, " "), title
+}
+
+// tokens splits s on white space, except in quoted strings.
+func tokens(s string) []string {
+	toks := []string{}
+	tok := &strings.Builder{}
+	quote := rune(0)
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ' ' || r == '\t':
+			if tok.Len() > 0 {
+				toks = append(toks, tok.String())
+				tok.Reset()
+			}
+			continue
+		}
+		tok.WriteRune(r)
+	}
+	if tok.Len() > 0 {
+		toks = append(toks, tok.String())
+	}
+	return toks
+}
+
+var (
+	braces    = regexp.MustCompile(`\{\{\s*(.+?)\s*\}\}`)
+	sectionOf = regexp.MustCompile(`(?i)^(section|appendix)\s+(\S+)\s+of\s+(\S+)$`)
+	ofSection = regexp.MustCompile(`(?i)^(\S+),\s*(section|appendix)\s+(\S+)$`)
+	refAnchor = regexp.MustCompile(`^(RFC\d+|BCP\d+|STD\d+|FYI\d+|I-D\.|DOI\.|W3C\.|IEEE\.)`)
+	headerIAL = regexp.MustCompile(`\{:\s*(.*?)\s*\}\s*$`)
+	keywords  = regexp.MustCompile(`\b(MUST NOT|MUST|SHALL NOT|SHALL|SHOULD NOT|SHOULD|NOT RECOMMENDED|RECOMMENDED|MAY|REQUIRED|OPTIONAL)\b`)
+)
+
+// inline converts citations and cross references in a line, code spans are left alone.
+func (c *converter) inline(l string) string {
+	if strings.HasPrefix(strings.TrimSpace(l), "#") {
+		l = headerIAL.ReplaceAllStringFunc(l, func(s string) string {
+			attrs, _ := attributes(headerIAL.FindStringSubmatch(s)[1])
+			return "{" + attrs + "}"
+		})
+	}
+	parts := strings.Split(l, "`")
+	for i := 0; i < len(parts); i += 2 {
+		parts[i] = braces.ReplaceAllStringFunc(parts[i], func(s string) string {
+			return c.reference(braces.FindStringSubmatch(s)[1])
+		})
+		if c.bcp14 {
+			parts[i] = strong(parts[i])
+		}
+	}
+	return strings.Join(parts, "`")
+}
+
+// reference converts the contents of {{...}} to a citation or a cross reference.
+func (c *converter) reference(s string) string {
+	anchor, suffix := s, ""
+	if m := sectionOf.FindStringSubmatch(s); m != nil {
+		anchor, suffix = m[3], ", "+strings.ToLower(m[1])+" "+m[2]
+	} else if m := ofSection.FindStringSubmatch(s); m != nil {
+		anchor, suffix = m[1], ", "+strings.ToLower(m[2])+" "+m[3]
+	}
+
+	mark := ""
+	switch anchor[0] {
+	case '!', '?':
+		mark, anchor = anchor[:1], anchor[1:]
+	case '-':
+		anchor = anchor[1:]
+	}
+	if !c.normative[anchor] && !c.informative[anchor] && !refAnchor.MatchString(anchor) {
+		return "(#" + anchor + ")"
+	}
+	if mark == "" {
+		switch {
+		case c.normative[anchor]:
+			mark = "!"
+		case c.informative[anchor]:
+			mark = "?"
+		}
+	}
+	c.cited[anchor] = true
+	return "[@" + mark + anchor + suffix + "]"
+}
+
+// strong makes the BCP 14 keywords in s strong, unless they already are.
+func strong(s string) string {
+	b := &strings.Builder{}
+	prev := 0
+	for _, m := range keywords.FindAllStringIndex(s, -1) {
+		if m[0] > 0 && s[m[0]-1] == '*' {
+			continue
+		}
+		b.WriteString(s[prev:m[0]] + "**" + s[m[0]:m[1]] + "**")
+		prev = m[1]
+	}
+	b.WriteString(s[prev:])
+	return b.String()
+}
+
+// bcp14 is the text of the bcp14 boilerplate.
+const bcp14 = `The key words "**MUST**", "**MUST NOT**", "**REQUIRED**", "**SHALL**", "**SHALL NOT**",
+"**SHOULD**", "**SHOULD NOT**", "**RECOMMENDED**", "**NOT RECOMMENDED**", "**MAY**", and
+"**OPTIONAL**" in this document are to be interpreted as described in BCP 14 [@!RFC2119] [@!RFC8174]
+when, and only when, they appear in all capitals, as shown here.`
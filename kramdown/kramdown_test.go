@@ -0,0 +1,56 @@
+package kramdown
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestConvert(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		input, err := ioutil.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := ioutil.ReadFile(strings.TrimSuffix(f, ".md") + ".mmark")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := Convert(input, nil)
+		if err != nil {
+			t.Fatalf("%s: %s", f, err)
+		}
+		if diff := cmp.Diff(string(want), string(got)); diff != "" {
+			t.Errorf("%s: differs: (-want +got)\n%s", f, diff)
+		}
+	}
+}
+
+func TestReference(t *testing.T) {
+	c := &converter{
+		normative:   map[string]bool{"RFC2119": true},
+		informative: map[string]bool{"FOO": true},
+		cited:       map[string]bool{},
+	}
+	tests := map[string]string{
+		"RFC2119":                 "[@!RFC2119]",
+		"?RFC8446":                "[@?RFC8446]",
+		"FOO":                     "[@?FOO]",
+		"Section 4.1 of RFC2119":  "[@!RFC2119, section 4.1]",
+		"RFC8446, Appendix A":     "[@RFC8446, appendix A]",
+		"I-D.ietf-quic-transport": "[@I-D.ietf-quic-transport]",
+		"sec-intro":               "(#sec-intro)",
+	}
+	for in, want := range tests {
+		if got := c.reference(in); got != want {
+			t.Errorf("%q: want %q, got %q", in, want, got)
+		}
+	}
+}
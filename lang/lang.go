@@ -12,49 +12,73 @@ func New(language string) Lang {
 	// The keys must be in all lower case for normalized lookup.
 	l.m = map[string]Term{
 		"en": {
+			Contents:     "Contents",
 			And:          "and",
 			Authors:      "Authors",
 			Bibliography: "Bibliography",
 			Footnotes:    "Footnotes",
 			Index:        "Index",
+			Terminology:  "Terminology",
+			Normative:    "Normative References",
+			Informative:  "Informative References",
 			WrittenBy:    "Written by",
 			See:          "see",
+			SeeAlso:      "see also",
+			Of:           "of",
 			Section:      "section",
 			UseCounter:   "use counter",
 			UseTitle:     "use title",
 		},
 		"nl": {
+			Contents:     "Inhoud",
 			And:          "en",
 			Bibliography: "Bibliografie",
 			Footnotes:    "Voetnoten",
 			Index:        "Index",
+			Terminology:  "Terminologie",
+			Normative:    "Normatieve Referenties",
+			Informative:  "Informatieve Referenties",
 			See:          "zie",
+			SeeAlso:      "zie ook",
+			Of:           "van",
 			Section:      "sectie",
 			UseCounter:   "gebruik nummer",
 			UseTitle:     "gebruik titel",
 		},
 		"de": {
+			Contents:     "Inhaltsverzeichnis",
 			And:          "und",
 			Bibliography: "Literaturverzeichnis",
 			Footnotes:    "Fußnoten",
 			Index:        "Index",
+			Terminology:  "Terminologie",
+			Normative:    "Normative Referenzen",
+			Informative:  "Informative Referenzen",
 			See:          "siehe",
+			SeeAlso:      "siehe auch",
+			Of:           "von",
 			Section:      "abschnit",
 		},
 		"ja": {
+			Contents:     "目次",
 			Bibliography: "参考文献",
 			Footnotes:    "脚注",
 			Index:        "索引",
+			Terminology:  "用語",
 		},
 		"zh-cn": {
+			Contents:     "目录",
 			Bibliography: "参考文献",
 			Footnotes:    "注释",
 			Index:        "索引",
+			Terminology:  "术语",
 		},
 		"zh-tw": {
+			Contents:     "目錄",
 			Bibliography: "參考文獻",
 			Footnotes:    "註釋",
 			Index:        "索引",
+			Terminology:  "術語",
 		},
 	}
 
@@ -73,17 +97,34 @@ type Term struct {
 	And          string
 	Authors      string
 	Bibliography string
+	Contents     string
 	Footnotes    string
 	Index        string
+	Informative  string // title of the informative references section
+	Normative    string // title of the normative references section
+	Terminology  string // title of the section with the abbreviations
 	WrittenBy    string
 
 	// for cross references
 	See        string
+	SeeAlso    string // as in "see also" in the index
+	Of         string // as in "Section 2 of [RFC2119]"
 	Section    string
 	UseCounter string
 	UseTitle   string
 }
 
+// String returns the language tag of l.
+func (l Lang) String() string { return l.language }
+
+func (l Lang) Contents() string {
+	t, ok := l.m[l.language]
+	if !ok {
+		return l.m["en"].Contents
+	}
+	return t.Contents
+}
+
 func (l Lang) Footnotes() string {
 	t, ok := l.m[l.language]
 	if !ok {
@@ -108,6 +149,30 @@ func (l Lang) Index() string {
 	return t.Index
 }
 
+func (l Lang) Terminology() string {
+	t, ok := l.m[l.language]
+	if !ok {
+		return l.m["en"].Terminology
+	}
+	return t.Terminology
+}
+
+func (l Lang) Normative() string {
+	t, ok := l.m[l.language]
+	if !ok {
+		return l.m["en"].Normative
+	}
+	return t.Normative
+}
+
+func (l Lang) Informative() string {
+	t, ok := l.m[l.language]
+	if !ok {
+		return l.m["en"].Informative
+	}
+	return t.Informative
+}
+
 func (l Lang) Authors() string {
 	t, ok := l.m[l.language]
 	if !ok {
@@ -140,6 +205,22 @@ func (l Lang) See() string {
 	return t.See
 }
 
+func (l Lang) SeeAlso() string {
+	t, ok := l.m[l.language]
+	if !ok {
+		return l.m["en"].SeeAlso
+	}
+	return t.SeeAlso
+}
+
+func (l Lang) Of() string {
+	t, ok := l.m[l.language]
+	if !ok {
+		return l.m["en"].Of
+	}
+	return t.Of
+}
+
 func (l Lang) Section() string {
 	t, ok := l.m[l.language]
 	if !ok {
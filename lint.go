@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/mparser"
+	"github.com/mmarkdown/mmark/v2/render/xml"
+)
+
+// lintCommand checks Internet-Drafts for the problems idnits reports and prints them, one per line, as
+// "file:line: message [check]". It returns 1 if anything was found.
+func lintCommand(args []string) int {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	skip := fs.String("skip", "", "comma separated IDs of the checks to skip, i.e. \"non-ascii,unreferenced-section\"")
+	index := fs.String("rfc-index", "", "rfc-index.xml to find obsolete references with, instead of the built-in list")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "SYNOPSIS: %s lint [OPTIONS] %s\n", os.Args[0], "[FILE...]")
+		fmt.Fprintln(fs.Output(), "\nCheck an Internet-Draft for problems.\n\nOPTIONS:")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	skipped := map[string]bool{}
+	for _, id := range strings.Split(*skip, ",") {
+		skipped[strings.TrimSpace(id)] = true
+	}
+	obsoleted := mparser.ObsoletedBy
+	if *index != "" {
+		data, err := ioutil.ReadFile(*index)
+		if err == nil {
+			obsoleted, err = mparser.ReadRFCIndex(data)
+		}
+		if err != nil {
+			reporter.reportError(*index, "read", err)
+			return 1
+		}
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"os.Stdin"}
+	}
+	status := 0
+	for _, fileName := range files {
+		var (
+			d    []byte
+			err  error
+			init mparser.Initial
+		)
+		if fileName == "os.Stdin" {
+			init = mparser.NewInitial("")
+			d, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			init = mparser.NewInitial(fileName)
+			d, err = ioutil.ReadFile(fileName)
+		}
+		if err != nil {
+			reporter.reportError(fileName, "read", err)
+			status = 1
+			continue
+		}
+		d = markdown.NormalizeNewlines(d)
+		d = mparser.ExpandVariables(d)
+		d = mparser.DivFences(d)
+		init.Source = d
+		var diags []mparser.Diagnostic // the problems found while parsing, e.g. a failed include.
+		init.Report = func(diag mparser.Diagnostic) { diags = append(diags, diag) }
+
+		// without AutoHeadingIDs only the anchors given in the document are set, see CheckUnreferenced.
+		p := parser.NewWithExtensions(mparser.Extensions &^ parser.AutoHeadingIDs)
+		mparser.RegisterInlines(p)
+		p.Opts = parser.Options{ParserHook: init.Hook, ReadIncludeFn: init.ReadInclude}
+		doc := markdown.Parse(d, p)
+
+		diags = append(diags, mparser.CheckBCP14(doc, d)...)
+		diags = append(diags, mparser.CheckSections(doc)...)
+		diags = append(diags, lintCheck(mparser.CheckLineLength(doc, d, xml.FoldWidth), mparser.LintLineLength)...)
+		diags = append(diags, mparser.CheckASCII(d)...)
+		diags = append(diags, mparser.CheckObsolete(doc, d, obsoleted)...)
+		diags = append(diags, mparser.CheckUnreferenced(doc, d)...)
+		diags = append(diags, lintCheck(mparser.ValidateReferences(doc, d), mparser.LintReference)...)
+		diags = append(diags, lintCheck(mparser.CheckReferences(doc, d), mparser.LintReference)...)
+		diags = append(diags, lintCheck(mparser.CheckImages(doc, d), mparser.LintImageAlt)...)
+		sort.SliceStable(diags, func(i, j int) bool { return diags[i].Line < diags[j].Line })
+
+		for _, diag := range diags {
+			if skipped[diag.Check] {
+				continue
+			}
+			fmt.Printf("%s:%s\n", fileName, diag)
+			status = 1
+		}
+	}
+	return status
+}
+
+// lintCheck sets the check of diags to id.
+func lintCheck(diags []mparser.Diagnostic, id string) []mparser.Diagnostic {
+	for i := range diags {
+		diags[i].Check = id
+	}
+	return diags
+}
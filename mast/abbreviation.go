@@ -0,0 +1,23 @@
+package mast
+
+import (
+	"github.com/gomarkdown/markdown/ast"
+)
+
+// Abbreviation is the use of an abbreviation, the abbreviation is in Literal and Title is what it
+// stands for. First is true for the first use in the document, where it is expanded.
+type Abbreviation struct {
+	ast.Leaf
+
+	Title string
+	First bool
+}
+
+// String returns the abbreviation as shown in outputs without abbreviations, the first use is
+// expanded: "Transport Layer Security (TLS)".
+func (a *Abbreviation) String() string {
+	if a.First {
+		return a.Title + " (" + string(a.Literal) + ")"
+	}
+	return string(a.Literal)
+}
@@ -0,0 +1,45 @@
+package mast
+
+import (
+	"regexp"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+// CalloutDelimiter is the delimiter of the ordered list that is the legend of the callouts in a code
+// block, see CalloutLegend.
+const CalloutDelimiter = '>'
+
+var calloutMarkers = regexp.MustCompile(`(?:[ \t]*<\d+>)+[ \t]*$`)
+var calloutMarker = regexp.MustCompile(`<(\d+)>`)
+
+// CalloutMarkers returns the numbers of the callouts at the end of line, i.e. "1" and "2" for "x := 1
+// // <1> <2>", and the index in line where they start. If there are none, it returns nil and -1.
+func CalloutMarkers(line []byte) ([]string, int) {
+	loc := calloutMarkers.FindIndex(line)
+	if loc == nil {
+		return nil, -1
+	}
+	ids := []string{}
+	for _, m := range calloutMarker.FindAllSubmatch(line[loc[0]:], -1) {
+		ids = append(ids, string(m[1]))
+	}
+	return ids, loc[0]
+}
+
+// CalloutLegend returns the legend of the callouts in codeBlock: the list directly after it, or after
+// the figure it's in, with CalloutDelimiter as its delimiter. It returns nil if there is none.
+func CalloutLegend(codeBlock *ast.CodeBlock) *ast.List {
+	var block ast.Node = codeBlock
+	if _, ok := codeBlock.Parent.(*ast.CaptionFigure); ok {
+		block = codeBlock.Parent
+	}
+	if block.GetParent() == nil {
+		return nil
+	}
+	list, ok := ast.GetNextNode(block).(*ast.List)
+	if !ok || list.Delimiter != CalloutDelimiter {
+		return nil
+	}
+	return list
+}
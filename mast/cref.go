@@ -0,0 +1,21 @@
+package mast
+
+import (
+	"github.com/gomarkdown/markdown/ast"
+)
+
+// Cref is an editorial comment, the text is in Literal and Source is who made it. In RFC 7991 XML it
+// becomes a <cref>.
+type Cref struct {
+	ast.Leaf
+
+	Source string
+}
+
+// String returns the comment as shown in outputs without editorial comments: "[source: text]".
+func (c *Cref) String() string {
+	if c.Source == "" {
+		return "[" + string(c.Literal) + "]"
+	}
+	return "[" + c.Source + ": " + string(c.Literal) + "]"
+}
@@ -0,0 +1,62 @@
+package mast
+
+import (
+	"fmt"
+	"log"
+)
+
+// Diagnostic is a problem found in the document, while it is parsed, checked or rendered.
+type Diagnostic struct {
+	Line     int // line in the source, 0 if unknown, i.e. when it comes from an included file
+	Column   int // column (in characters) in the line, 0 if unknown
+	Message  string
+	Check    string // ID of the check that found it, used to suppress it, may be empty
+	Severity Severity
+}
+
+// Severity is the severity of a Diagnostic.
+type Severity int
+
+const (
+	SeverityWarning Severity = iota // The output is made, but may not be what was intended.
+	SeverityError                   // The output is incomplete or invalid.
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Severity) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+func (d Diagnostic) String() string {
+	s := d.Message
+	if d.Check != "" {
+		s += " [" + d.Check + "]"
+	}
+	if d.Line == 0 {
+		return s
+	}
+	return fmt.Sprintf("%d: %s", d.Line, s)
+}
+
+// Reporter is called with the diagnostics that are found while a document is parsed or rendered, by
+// code that can't return them, such as the hooks of the parser and the renderers.
+type Reporter func(Diagnostic)
+
+// Report reports d with r, when r is nil d is logged.
+func (r Reporter) Report(d Diagnostic) {
+	if r == nil {
+		log.Print(d)
+		return
+	}
+	r(d)
+}
+
+// Reportf reports a diagnostic for check with severity s and the message made from format and a.
+func (r Reporter) Reportf(s Severity, check, format string, a ...interface{}) {
+	r.Report(Diagnostic{Message: fmt.Sprintf(format, a...), Check: check, Severity: s})
+}
@@ -0,0 +1,14 @@
+package mast
+
+import (
+	"github.com/gomarkdown/markdown/ast"
+)
+
+// Div is a fenced div: a block level container started with "::: name" and ended with ":::". Name
+// says what it is, "section" or "note" for instance. Outputs that have no such element render its
+// children as if there was no div.
+type Div struct {
+	ast.Container
+
+	Name string
+}
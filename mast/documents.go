@@ -0,0 +1,89 @@
+package mast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Documents is a list of documents that are updated or obsoleted. A document is an RFC number or the
+// name of an Internet-Draft, in the title block these can be mixed: updates = [7511, "draft-foo-bar"].
+type Documents []string
+
+// UnmarshalTOML implements toml.Unmarshaler, so the list can contain numbers and strings.
+func (d *Documents) UnmarshalTOML(v interface{}) error {
+	list, ok := v.([]interface{})
+	if !ok {
+		list = []interface{}{v}
+	}
+	for _, e := range list {
+		switch e := e.(type) {
+		case int64:
+			*d = append(*d, strconv.FormatInt(e, 10))
+		case string:
+			*d = append(*d, strings.TrimSpace(e))
+		default:
+			return fmt.Errorf("expected a number or a string, got %T", e)
+		}
+	}
+	return nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, so the list can contain numbers and strings.
+func (d *Documents) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*d = append(*d, strings.TrimSpace(n.Value))
+		return nil
+	}
+	list := []string{}
+	if err := n.Decode(&list); err != nil {
+		return err
+	}
+	for _, e := range list {
+		*d = append(*d, strings.TrimSpace(e))
+	}
+	return nil
+}
+
+// Numbers returns the RFC numbers in d, "RFC 7511" and "RFC7511" are also numbers.
+func (d Documents) Numbers() []int {
+	nums := []int{}
+	for _, doc := range d {
+		if n, ok := number(doc); ok {
+			nums = append(nums, n)
+		}
+	}
+	return nums
+}
+
+// Names returns the documents in d that aren't RFCs, these are Internet-Draft names.
+func (d Documents) Names() []string {
+	names := []string{}
+	for _, doc := range d {
+		if _, ok := number(doc); !ok {
+			names = append(names, doc)
+		}
+	}
+	return names
+}
+
+// String returns the documents in d as a comma separated list, RFCs are given as their number.
+func (d Documents) String() string {
+	s := make([]string, len(d))
+	for i, doc := range d {
+		if n, ok := number(doc); ok {
+			s[i] = strconv.Itoa(n)
+			continue
+		}
+		s[i] = doc
+	}
+	return strings.Join(s, ", ")
+}
+
+func number(doc string) (int, bool) {
+	doc = strings.TrimSpace(strings.TrimPrefix(strings.ToUpper(doc), "RFC"))
+	n, err := strconv.Atoi(doc)
+	return n, err == nil
+}
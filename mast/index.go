@@ -2,6 +2,25 @@ package mast
 
 import "github.com/gomarkdown/markdown/ast"
 
+// Attributes set on an *ast.Index for the modifiers written after a '|' in the index: (!item|see other),
+// (!item|see also other), (!item|begin) and (!item|end).
+const (
+	IndexSee     = "see"     // the item this index redirects to
+	IndexSeeAlso = "seealso" // the related item this index points to
+	IndexRange   = "range"   // "begin" or "end" of a range of text this index applies to
+)
+
+// IndexRedirect returns true if the index is a "see" or "see also" redirect, these have no location
+// in the text.
+func IndexRedirect(i *ast.Index) bool {
+	return Attribute(i, IndexSee) != nil || Attribute(i, IndexSeeAlso) != nil
+}
+
+// IndexRangeEnd returns true if the index ends a range.
+func IndexRangeEnd(i *ast.Index) bool {
+	return string(Attribute(i, IndexRange)) == "end"
+}
+
 // DocumentIndex represents markdown document index node.
 type DocumentIndex struct {
 	ast.Container
@@ -12,6 +31,9 @@ type IndexItem struct {
 	ast.Container
 
 	*ast.Index
+
+	See     [][]byte // items this item redirects to
+	SeeAlso [][]byte // related items
 }
 
 // IndexSubItem contains an sub item index for the indices section.
@@ -19,6 +41,9 @@ type IndexSubItem struct {
 	ast.Container
 
 	*ast.Index
+
+	See     [][]byte
+	SeeAlso [][]byte
 }
 
 // IndexLetter has the Letter of this index item.
@@ -31,4 +56,5 @@ type IndexLink struct {
 	*ast.Link
 
 	Primary bool
+	End     bool // this link ends the range started by the previous link
 }
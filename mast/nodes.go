@@ -3,6 +3,7 @@ package mast
 import (
 	"bytes"
 	"sort"
+	"strconv"
 
 	"github.com/gomarkdown/markdown/ast"
 )
@@ -20,6 +21,43 @@ func MoveChildren(a, b ast.Node) {
 	}
 }
 
+// InTableCell returns true if node is in a table cell.
+func InTableCell(node ast.Node) bool {
+	for p := node.GetParent(); p != nil; p = p.GetParent() {
+		if _, ok := p.(*ast.TableCell); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// BlockCell returns true if the table cell contains block level elements, as a cell of a grid table
+// can.
+func BlockCell(cell *ast.TableCell) bool {
+	for _, c := range cell.Children {
+		switch c.(type) {
+		case *ast.Paragraph, *ast.List, *ast.CodeBlock, *ast.BlockQuote, *ast.Heading, *ast.HorizontalRule, *ast.Table, *ast.MathBlock:
+			return true
+		}
+	}
+	return false
+}
+
+// RowSpan returns the number of rows the table cell spans, this is the "rowspan" attribute and 1 if
+// that isn't set. A cell covered by a cell spanning from a row above has a rowspan of 0, it should not
+// be rendered by outputs that support row spans.
+func RowSpan(cell *ast.TableCell) int {
+	span := Attribute(cell, "rowspan")
+	if span == nil {
+		return 1
+	}
+	n, err := strconv.Atoi(string(span))
+	if err != nil || n < 0 {
+		return 1
+	}
+	return n
+}
+
 // Some attribute helper functions.
 
 // AttributeFromNode returns the attribute from the node, if it was there was one.
@@ -82,3 +82,17 @@ type Reference struct {
 	RefContent []string     `xml:"refcontent,omitempty"`
 	Annotation []string     `xml:"annotation,omitempty"`
 }
+
+// Group is the <referencegroup> structure, used for BCPs and STDs that consist of multiple RFCs.
+type Group struct {
+	XMLName    xml.Name    `xml:"referencegroup"`
+	Anchor     string      `xml:"anchor,attr"`
+	Target     string      `xml:"target,attr,omitempty"`
+	References []Reference `xml:"reference"`
+	Includes   []Include   `xml:"include,omitempty"` // xi:include elements, these need to be resolved before use
+}
+
+// Include is an <xi:include> element.
+type Include struct {
+	Href string `xml:"href,attr"`
+}
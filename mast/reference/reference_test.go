@@ -60,3 +60,21 @@ func TestReferenceDate(t *testing.T) {
 		t.Errorf("expected\n%s\ngot\n%s", expect, str)
 	}
 }
+
+func TestGroup(t *testing.T) {
+	in := []byte(`<referencegroup anchor="BCP14" target="https://www.rfc-editor.org/info/bcp14">
+  <reference anchor="RFC2119" target="https://www.rfc-editor.org/info/rfc2119"><front><title>Key words</title></front></reference>
+  <xi:include href="https://bib.ietf.org/public/rfc/bibxml/reference.RFC.8174.xml"/>
+</referencegroup>`)
+
+	var g Group
+	if err := xml.Unmarshal(in, &g); err != nil {
+		t.Fatalf("failed to unmarshal reference group: %s", err)
+	}
+	if g.Anchor != "BCP14" || len(g.References) != 1 || g.References[0].Anchor != "RFC2119" {
+		t.Errorf("unexpected reference group: %+v", g)
+	}
+	if len(g.Includes) != 1 || g.Includes[0].Href != "https://bib.ietf.org/public/rfc/bibxml/reference.RFC.8174.xml" {
+		t.Errorf("unexpected includes: %+v", g.Includes)
+	}
+}
@@ -21,6 +21,8 @@ func NewTitle() *Title {
 			Ipr:          "trust200902",
 			Consensus:    false,
 			IndexInclude: true,
+			TocInclude:   true,
+			SymRefs:      true,
 			SortRefs:     false,
 		},
 	}
@@ -33,16 +35,27 @@ type TitleData struct {
 	Abbrev string
 
 	SeriesInfo     reference.SeriesInfo
+	SeriesInfos    []reference.SeriesInfo // Plurals when more than one is needed, i.e. an RFC that is also a BCP.
 	IndexInclude   bool
+	TocInclude     bool
+	SymRefs        bool
 	Consensus      bool
 	SortRefs       bool
 	TocDepth       int
 	Ipr            string // See https://tools.ietf.org/html/rfc7991#appendix-A.1
-	Obsoletes      []int
-	Updates        []int
-	Links          []Link
+	IprExtract     string // Anchor of the section that can be extracted, see RFC 7991 Section 2.45.7.
+	Obsoletes      Documents
+	Updates        Documents
+	Links          []Link `toml:"link" yaml:"link"`
+	Venue          Venue
 	SubmissionType string // IETF, IAB, IRTF or independent, defaults to IETF.
 
+	RfcAttributes    map[string]interface{} // Extra attributes for the <rfc> element, these override ours.
+	CSL              []string               // CSL-JSON files with references.
+	XIInclude        XIInclude              `toml:"xiInclude" yaml:"xiinclude"`
+	DisplayReference map[string]string      `toml:"displayReference" yaml:"displayreference"` // Anchors to show differently, i.e. "I-D.ietf-foo-bar" as "FOO".
+	ReferenceTypes   map[string]string      `toml:"referenceTypes" yaml:"referencetypes"`     // Anchors and their type, "normative" or "informative", overriding the citations.
+
 	Date      time.Time
 	Area      string
 	Workgroup string
@@ -53,21 +66,49 @@ type TitleData struct {
 	Language string
 }
 
+// Expires returns the date an Internet-Draft expires: 185 days after its date. For an RFC, or when
+// there is no date, the zero time is returned.
+func (t *TitleData) Expires() time.Time {
+	if t.SeriesInfo.Name == "RFC" || t.Date.IsZero() {
+		return time.Time{}
+	}
+	return t.Date.AddDate(0, 0, 185)
+}
+
+// Link is a link to a related resource, i.e. the previous version of the document.
 type Link struct {
 	Href string
 	Rel  string
 }
 
+// Venue holds where a draft is discussed: the mailing list and the repository.
+type Venue struct {
+	Group  string // name of the group, i.e. "QUIC"
+	Type   string // type of the group, defaults to "Working Group"
+	Mail   string // mailing list address
+	Arch   string // mailing list archive, derived from Mail when empty
+	GitHub string // repository as "owner/repo" or a complete URL
+	Latest string // URL of the latest editor's copy
+}
+
 // Author denotes an RFC author.
 type Author struct {
 	Initials           string
 	Surname            string
 	Fullname           string
 	Organization       string
-	OrganizationAbbrev string `toml:"abbrev"`
-	Role               string
-	ASCII              string
+	OrganizationAbbrev string `toml:"abbrev" yaml:"abbrev"`
+	OrganizationASCII  string `toml:"organizationAscii" yaml:"organizationascii"`
+	Role               string // i.e. "editor", other roles are only shown in the Contributors section.
+	ASCII              string // Deprecated: use AsciiFullname.
+	Contribution       string // What was contributed, shown in the Contributors section.
+	Contributor        bool   // Only listed in the Contributors section, not on the front page.
 	Address            Address
+
+	// ASCII versions of the name, required by RFC 7991 when the name contains non-ASCII characters.
+	AsciiInitials string
+	AsciiSurname  string
+	AsciiFullname string
 }
 
 // Contact denotes an RFC contact.
@@ -0,0 +1,67 @@
+package mast
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+// UnicodeFormat is the default format of a Unicode node, as in xml2rfc.
+const UnicodeFormat = "lit-name-num"
+
+// Unicode represents the characters in Literal that should be shown with their code points, as the RFC
+// 7991 <u> element does. Format is how they are shown, a dash separated list of "lit", "char", "num",
+// "name" and "ascii", i.e. "lit-num".
+type Unicode struct {
+	ast.Leaf
+
+	Format string
+}
+
+// IsUnicodeFormat returns true if format is a valid format for a Unicode node.
+func IsUnicodeFormat(format string) bool {
+	if format == "" {
+		return false
+	}
+	for _, p := range strings.Split(format, "-") {
+		switch p {
+		case "lit", "char", "num", "name", "ascii":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the presentation of u for outputs without a <u> element, the first part of the format is
+// shown and the others follow in parentheses: "lit-num" gives `"é" (U+00E9)`. The names of the
+// characters aren't known, "name" and "ascii" are left out.
+func (u *Unicode) String() string {
+	format := u.Format
+	if format == "" {
+		format = UnicodeFormat
+	}
+	parts := []string{}
+	for _, p := range strings.Split(format, "-") {
+		switch p {
+		case "lit":
+			parts = append(parts, `"`+string(u.Literal)+`"`)
+		case "char":
+			parts = append(parts, string(u.Literal))
+		case "num":
+			nums := []string{}
+			for _, r := range string(u.Literal) {
+				nums = append(nums, fmt.Sprintf("U+%04X", r))
+			}
+			parts = append(parts, strings.Join(nums, " "))
+		}
+	}
+	switch len(parts) {
+	case 0:
+		return string(u.Literal)
+	case 1:
+		return parts[0]
+	}
+	return parts[0] + " (" + strings.Join(parts[1:], ", ") + ")"
+}
@@ -0,0 +1,63 @@
+package mast
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// XIInclude selects the references that are output as an xi:include of their bib.ietf.org URL,
+// instead of as XML. In the title block this is either a boolean, for all references, or a list of
+// anchors: xiInclude = ["RFC2119", "I-D.ietf-foo-bar"].
+type XIInclude struct {
+	All     bool
+	Anchors []string
+}
+
+// UnmarshalTOML implements toml.Unmarshaler, so the value can be a boolean or a list.
+func (x *XIInclude) UnmarshalTOML(v interface{}) error {
+	switch v := v.(type) {
+	case bool:
+		x.All = v
+		return nil
+	case string:
+		x.Anchors = append(x.Anchors, strings.TrimSpace(v))
+		return nil
+	case []interface{}:
+		for _, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				return fmt.Errorf("expected a string, got %T", e)
+			}
+			x.Anchors = append(x.Anchors, strings.TrimSpace(s))
+		}
+		return nil
+	}
+	return fmt.Errorf("expected a boolean or a list, got %T", v)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, so the value can be a boolean or a list.
+func (x *XIInclude) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		if n.Tag == "!!bool" {
+			return n.Decode(&x.All)
+		}
+		x.Anchors = append(x.Anchors, strings.TrimSpace(n.Value))
+		return nil
+	}
+	return n.Decode(&x.Anchors)
+}
+
+// Includes returns true when the reference with anchor should be an xi:include.
+func (x XIInclude) Includes(anchor string) bool {
+	if x.All {
+		return true
+	}
+	for _, a := range x.Anchors {
+		if strings.EqualFold(a, anchor) {
+			return true
+		}
+	}
+	return false
+}
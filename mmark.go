@@ -1,11 +1,19 @@
 package main
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gomarkdown/markdown"
 	"github.com/gomarkdown/markdown/ast"
@@ -14,34 +22,254 @@ import (
 	"github.com/mmarkdown/mmark/v2/lang"
 	"github.com/mmarkdown/mmark/v2/mast"
 	"github.com/mmarkdown/mmark/v2/mparser"
+	"github.com/mmarkdown/mmark/v2/render/asciidoc"
+	"github.com/mmarkdown/mmark/v2/render/diagram"
+	"github.com/mmarkdown/mmark/v2/render/epub"
+	"github.com/mmarkdown/mmark/v2/render/gemtext"
+	"github.com/mmarkdown/mmark/v2/render/jats"
+	"github.com/mmarkdown/mmark/v2/render/json"
+	"github.com/mmarkdown/mmark/v2/render/latex"
 	"github.com/mmarkdown/mmark/v2/render/man"
+	"github.com/mmarkdown/mmark/v2/render/mdoc"
+	"github.com/mmarkdown/mmark/v2/render/meta"
 	"github.com/mmarkdown/mmark/v2/render/mhtml"
+	"github.com/mmarkdown/mmark/v2/render/odt"
+	"github.com/mmarkdown/mmark/v2/render/outline"
+	"github.com/mmarkdown/mmark/v2/render/pandoc"
+	"github.com/mmarkdown/mmark/v2/render/pdf"
+	"github.com/mmarkdown/mmark/v2/render/refs"
+	"github.com/mmarkdown/mmark/v2/render/slides"
+	"github.com/mmarkdown/mmark/v2/render/text"
 	"github.com/mmarkdown/mmark/v2/render/xml"
 )
 
 var (
-	flagCSS       = flag.String("css", "", "link to a CSS stylesheet (only used with -html)")
-	flagHead      = flag.String("head", "", "link to HTML to be included in head (only used with -html)")
-	flagAst       = flag.Bool("ast", false, "print abstract syntax tree and exit")
-	flagBib       = flag.Bool("bibliography", true, "generate a bibliography section after the back matter")
-	flagFragment  = flag.Bool("fragment", false, "don't create a full document")
-	flagHTML      = flag.Bool("html", false, "create HTML output")
-	flagIndex     = flag.Bool("index", true, "generate an index at the end of the document")
-	flagMan       = flag.Bool("man", false, "generate manual pages (nroff)")
-	flagUnsafe    = flag.Bool("unsafe", false, "allow unsafe includes")
-	flagIntraEmph = flag.Bool("intra-emphasis", false, "interpret camel_case_value as emphasizing \"case\" (legacy behavior)")
-	flagVersion   = flag.Bool("version", false, "show mmark version")
-	flagUnicode   = flag.Bool("unicode", true, "from xml2rfc 3.16 onwards unicode is allowed in <t>")
+	flagCSS        = flag.String("css", "", "link to a CSS stylesheet (only used with -html), or a CSS file to include (with -epub)")
+	flagHead       = flag.String("head", "", "link to HTML to be included in head (only used with -html)")
+	flagAsciiDoc   = flag.Bool("asciidoc", false, "create AsciiDoc output")
+	flagAst        = flag.Bool("ast", false, "print abstract syntax tree and exit")
+	flagBCP14      = flag.Bool("bcp14", false, "mark up the uppercase BCP 14 key words (MUST, SHOULD NOT, ...) in the text as if they were strong")
+	flagBib        = flag.Bool("bibliography", true, "generate a bibliography section after the back matter")
+	flagCiteStyle  = flag.String("citestyle", "anchor", "citation style for HTML output: \"anchor\", \"numeric\" or \"author-year\"")
+	flagConfig     = flag.String("config", "", "file with the default flags, defaults to "+ConfigFile+" in the current directory or a parent")
+	flagComments   = flag.String("comments", "", "what to do with <!-- comments -->: \"strip\" them, \"keep\" them as XML comments or make them a \"cref\"")
+	flagBibDir     = flag.String("bibdir", "", "directory with reference.*.xml files, used before fetching references")
+	flagBibXML     = flag.String("bibxml", "https://bib.ietf.org/public/rfc", "base URL to fetch RFC, I-D, BCP, STD, W3C, 3GPP and IEEE references from")
+	flagCPUProf    = flag.String("cpuprofile", "", "write a CPU profile to this file, for go tool pprof")
+	flagDate       = flag.String("date", "", "date (YYYY-MM-DD) to use for \"today\" and title blocks without a date, defaults to the current date")
+	flagDiagram    = engineFlag{}
+	flagSource     = typeFlag{}
+	flagDeps       = flag.Bool("deps", false, "print the files the document includes, one per line, and exit")
+	flagIncr       = flag.Bool("incremental", false, "only render the outputs that are older than the document, a file it includes or an image")
+	flagWatch      = flag.Bool("watch", false, "render the outputs again when the document, a file it includes or an image changes")
+	flagDefine     = flag.String("define", "", "comma separated names for conditional includes, {{file.md}}[if=name]")
+	flagEpub       = flag.Bool("epub", false, "create an EPUB3 container")
+	flagTerms      = flag.Bool("terminology", false, "add a section listing the abbreviations, *[TLS]: Transport Layer Security, at the end of the document")
+	flagFold       = flag.String("fold", "", "fold code lines longer than 69 characters in XML as in RFC 8792: \"single\" or \"double\" backslash, or \"check\" to report them")
+	flagFilter     = filterFlag{}
+	flagFormats    = flag.String("formats", "", "comma separated outputs or their extensions, i.e. \"xml,html,txt\", to render to files in -outdir or the current directory")
+	flagFootnotes  = flag.String("footnotes", "", "how footnotes are rendered in XML: left out (the default), as \"cref\" or in a \"notes\" section")
+	flagFinal      = flag.Bool("final", false, "leave out the editorial comments, [^source: text] and cref code blocks")
+	flagFragment   = flag.Bool("fragment", false, "don't create a full document")
+	flagGemtext    = flag.Bool("gemtext", false, "create gemtext (Gemini) output")
+	flagGFM        = flag.Bool("gfm", false, "follow GitHub Flavored Markdown: task lists, ~strikethrough~ and www. and e-mail autolinks")
+	flagHTML       = flag.Bool("html", false, "create HTML output")
+	flagIncludeTTL = flag.Duration("include-ttl", 24*time.Hour, "how long remote includes are cached, 0 fetches them every time")
+	flagIndex      = flag.Bool("index", true, "generate an index at the end of the document")
+	flagJATS       = flag.Bool("jats", false, "create JATS XML output")
+	flagJSON       = flag.Bool("json", false, "print abstract syntax tree as JSON and exit")
+	flagLatex      = flag.Bool("latex", false, "create LaTeX output")
+	flagMan        = flag.Bool("man", false, "generate manual pages (nroff)")
+	flagMdoc       = flag.Bool("mdoc", false, "generate BSD manual pages (mdoc)")
+	flagMemProf    = flag.String("memprofile", "", "write a memory profile to this file when done, for go tool pprof")
+	flagMeta       = flag.Bool("meta", false, "print the title block and document metadata as JSON")
+	flagNormRefs   = flag.Bool("normalize-refs", false, "normalize the author initials and dates of the references")
+	flagNoFetch    = flag.Bool("no-fetch", false, "don't fetch references")
+	flagODT        = flag.Bool("odt", false, "create an OpenDocument text (ODT) file")
+	flagOffline    = flag.Bool("offline", false, "only use cached references, don't fetch them")
+	flagOutdir     = flag.String("outdir", "", "directory to write the output to, named after the document, instead of standard output")
+	flagOutput     = outputFlag{}
+	flagOutline    = flag.String("outline", "", "print a graph of the sections, cross references and citations, as \"dot\" or \"mermaid\"")
+	flagPandoc     = flag.Bool("pandoc", false, "create Pandoc JSON output")
+	flagPDF        = flag.Bool("pdf", false, "create PDF output, laid out as the plain text output")
+	flagReproduce  = flag.Bool("reproducible", false, "create the same output for the same input: pin the date to $SOURCE_DATE_EPOCH, or 1970-01-01, unless -date is given")
+	flagRefs       = flag.Bool("refs", false, "print the resolved bibliography as JSON")
+	flagRefOrder   = flag.String("reforder", "anchor", "order of the references: \"anchor\", \"appearance\" or \"numeric\"")
+	flagSlides     = flag.Bool("slides", false, "create a reveal.js slide deck")
+	flagSlug       = flag.String("slug", "", "how heading anchors are made: \"mmark\" (the default), \"github\" or \"rfc\" (name-...)")
+	flagSlugPrefix = flag.String("slug-prefix", "", "prefix for the heading anchors")
+	flagSlugMap    = flag.String("slug-map", "", "JSON file with the heading anchors, the ones in it are kept and it is updated with the new ones")
+	flagText       = flag.Bool("text", false, "create plain text output (RFC 7994 style)")
+	flagTheme      = flag.String("theme", slides.DefaultTheme, "reveal.js theme (only used with -slides)")
+	flagTrans      = flag.String("transition", slides.DefaultTransition, "reveal.js slide transition (only used with -slides)")
+	flagUnsafe     = flag.Bool("unsafe", false, "allow unsafe includes")
+	flagInlineSVG  = flag.Bool("inline-svg", false, "inline local SVG images, with scripts and external references removed")
+	flagIntraEmph  = flag.Bool("intra-emphasis", false, "interpret camel_case_value as emphasizing \"case\" (legacy behavior)")
+	flagXML2RFC    = flag.String("xml2rfc", "", "convert the XML with xml2rfc to \"text\", \"html\" or \"pdf\"")
+	flagXML2RFCURL = flag.String("xml2rfc-url", "", "xml2rfc service to use instead of the local xml2rfc, i.e. https://author-tools.ietf.org/api/render")
+	flagVersion    = flag.Bool("version", false, "show mmark version")
+	flagWidth      = flag.Int("width", 72, "width of the text and PDF output")
+	flagDiag       = flag.String("diag", "text", "how warnings and errors are reported: as \"text\" or as \"json\", one object per line")
+	flagStrict     = flag.Bool("strict", false, "exit with status 1 when there are warnings")
+	flagUnicode    = flag.Bool("unicode", true, "from xml2rfc 3.16 onwards unicode is allowed in <t>")
 )
 
+func init() {
+	flag.Var(flagDiagram, "diagram", "command converting a diagram to SVG as \"language=command\", i.e. \"dot=dot -Tsvg\", or to text as \"language.text=command\", \"language=\" shows the source, can be repeated")
+	flag.Var(&flagFilter, "filter", "command the abstract syntax tree is piped through as JSON, it writes the changed tree back, can be repeated")
+	flag.Var(&flagOutput, "o", "file to render to, the output is selected by its extension, i.e. \".html\" for -html, can be repeated")
+	flag.Var(flagSource, "sourcecode", "sourcecode type of a code block language in XML as \"language=type\", i.e. \"proto=protobuf\", can be repeated")
+}
+
+// typeFlag is a flag that can be repeated, it maps a code block language to a sourcecode type.
+type typeFlag map[string]string
+
+func (t typeFlag) String() string { return "" }
+
+func (t typeFlag) Set(s string) error {
+	lang, typ, ok := strings.Cut(s, "=")
+	if !ok || strings.TrimSpace(lang) == "" || strings.TrimSpace(typ) == "" {
+		return fmt.Errorf("expected \"language=type\", got %q", s)
+	}
+	t[strings.ToLower(strings.TrimSpace(lang))] = strings.TrimSpace(typ)
+	return nil
+}
+
+// engineFlag is a flag that can be repeated, it maps a diagram language to the command that converts it.
+type engineFlag map[string]string
+
+func (e engineFlag) String() string { return "" }
+
+// engines returns the default diagram engines with the commands of the flag: "language=command" sets
+// the command converting to SVG and "language.text=command" the one converting to text. A URL instead
+// of a command is a PlantUML server.
+func (e engineFlag) engines() map[string]diagram.Engine {
+	engines := map[string]diagram.Engine{}
+	for lang, e := range diagram.Engines {
+		engines[lang] = e
+	}
+	keys := make([]string, 0, len(e))
+	for key := range e {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys) // "dot" before "dot.text"
+
+	for _, key := range keys {
+		cmd := diagram.Command(e[key])
+		lang, text := strings.CutSuffix(key, ".text")
+		c, ok := engines[lang].(diagram.Commands)
+		if svg, isCommand := engines[lang].(diagram.Command); isCommand {
+			c, ok = diagram.Commands{SVGCommand: svg}, true
+		}
+		switch {
+		case !text && (strings.HasPrefix(string(cmd), "https://") || strings.HasPrefix(string(cmd), "http://")):
+			engines[lang] = diagram.PlantUMLServer(cmd)
+			continue
+		case text && ok && c.SVGCommand != "":
+			c.TextCommand = cmd
+		case text:
+			continue
+		case cmd == "":
+			delete(engines, lang)
+			continue
+		default:
+			c.SVGCommand = cmd
+		}
+		engines[lang] = c
+	}
+	return engines
+}
+
+func (e engineFlag) Set(s string) error {
+	lang, cmd, ok := strings.Cut(s, "=")
+	if !ok || strings.TrimSpace(lang) == "" {
+		return fmt.Errorf("expected \"language=command\", got %q", s)
+	}
+	e[strings.ToLower(strings.TrimSpace(lang))] = strings.TrimSpace(cmd)
+	return nil
+}
+
+// anchorLine returns the line in source where anchor is set, or 0 if it can't be found.
+func anchorLine(source []byte, anchor string) int {
+	if anchor == "" {
+		return 0
+	}
+	loc := regexp.MustCompile(`[#"']` + regexp.QuoteMeta(anchor) + `[\s}"']`).FindIndex(source)
+	if loc == nil {
+		return 0
+	}
+	return bytes.Count(source[:loc[0]], []byte("\n")) + 1
+}
+
+// sourceDate returns the day of $SOURCE_DATE_EPOCH, the seconds since the Unix epoch as set by
+// reproducible build systems, or the day of the epoch itself when it isn't set.
+func sourceDate() (time.Time, error) {
+	epoch := int64(0)
+	if s := os.Getenv("SOURCE_DATE_EPOCH"); s != "" {
+		var err error
+		if epoch, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return time.Time{}, err
+		}
+	}
+	t := time.Unix(epoch, 0).UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+// commands are the subcommands of mmark, these are selected by the first argument.
+var commands = map[string]func(args []string) int{
+	"kramdown": kramdownCommand,
+	"lint":     lintCommand,
+	"diff":     diffCommand,
+	"new":      newCommand,
+	"bump":     bumpCommand,
+	"stats":    statsCommand,
+	"fmt":      fmtCommand,
+	"anchors":  anchorsCommand,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			os.Exit(cmd(os.Args[2:]))
+		}
+	}
+
 	flag.Usage = func() {
 		fmt.Fprintf(flag.CommandLine.Output(), "SYNOPSIS: %s [OPTIONS] %s\n", os.Args[0], "[FILE...]")
+		fmt.Fprintf(flag.CommandLine.Output(), "          %s kramdown %s\n", os.Args[0], "[FILE...]")
+		fmt.Fprintf(flag.CommandLine.Output(), "          %s lint %s\n", os.Args[0], "[OPTIONS] [FILE...]")
+		fmt.Fprintf(flag.CommandLine.Output(), "          %s diff %s\n", os.Args[0], "[OPTIONS] OLD NEW")
+		fmt.Fprintf(flag.CommandLine.Output(), "          %s new %s\n", os.Args[0], "[OPTIONS] NAME")
+		fmt.Fprintf(flag.CommandLine.Output(), "          %s bump %s\n", os.Args[0], "[OPTIONS] FILE")
+		fmt.Fprintf(flag.CommandLine.Output(), "          %s stats %s\n", os.Args[0], "[OPTIONS] FILE...")
+		fmt.Fprintf(flag.CommandLine.Output(), "          %s fmt %s\n", os.Args[0], "[OPTIONS] [FILE...]")
+		fmt.Fprintf(flag.CommandLine.Output(), "          %s anchors %s\n", os.Args[0], "[OPTIONS] FILE...")
 		fmt.Println("\nOPTIONS:")
 		flag.PrintDefaults()
 	}
 
 	flag.Parse()
+	config := *flagConfig
+	if config == "" {
+		config = findConfig()
+	}
+	var outputConfig *outputConfig
+	if config != "" {
+		var err error
+		if outputConfig, err = loadConfig(config); err != nil {
+			log.Fatalf("Couldn't read configuration: %s", err)
+		}
+	}
+	// the flags of the outputs, in the configuration, are set before any flag is used.
+	targets, err := outputTargets(flagOutput, *flagFormats, *flagOutdir)
+	if err != nil {
+		log.Fatalf("Failure to parse -formats: %s", err)
+	}
+	if outputConfig != nil {
+		if err := outputConfig.apply(targetOutputs(targets)); err != nil {
+			log.Fatalf("Couldn't read configuration: %s", err)
+		}
+	}
 	args := flag.Args()
 	if len(args) == 0 {
 		args = []string{"os.Stdin"}
@@ -50,152 +278,619 @@ func main() {
 		fmt.Println(Version)
 		os.Exit(0)
 	}
+	if *flagDate != "" {
+		d, err := time.Parse("2006-01-02", *flagDate)
+		if err != nil {
+			log.Fatalf("Couldn't parse date %q: %q", *flagDate, err)
+		}
+		mparser.Today = d
+	}
+	if *flagReproduce && *flagDate == "" {
+		d, err := sourceDate()
+		if err != nil {
+			log.Fatalf("Couldn't parse SOURCE_DATE_EPOCH: %s", err)
+		}
+		mparser.Today = d
+	}
 
-	for _, fileName := range args {
-		var (
-			d    []byte
-			err  error
-			init mparser.Initial
-		)
-		if fileName == "os.Stdin" {
-			init = mparser.NewInitial("")
-			d, err = ioutil.ReadAll(os.Stdin)
-			if err != nil {
-				log.Printf("Couldn't read %q: %q", fileName, err)
-				continue
+	if !*flagIntraEmph {
+		mparser.Extensions |= parser.NoIntraEmphasis
+	}
+	switch *flagSlug {
+	case "", mparser.SlugMmark, mparser.SlugGitHub, mparser.SlugRFC:
+	default:
+		log.Fatalf("Unknown slug style %q, use \"mmark\", \"github\" or \"rfc\"", *flagSlug)
+	}
+	if *flagSlug != "" || *flagSlugPrefix != "" || *flagSlugMap != "" {
+		mparser.Extensions &^= parser.AutoHeadingIDs // AddSlugs makes them.
+	}
+	switch *flagRefOrder {
+	case "anchor":
+		mparser.BibliographyOrder = mparser.OrderAnchor
+	case "appearance":
+		mparser.BibliographyOrder = mparser.OrderAppearance
+	case "numeric":
+		mparser.BibliographyOrder = mparser.OrderNumeric
+	default:
+		log.Fatalf("Unknown reference order %q, use \"anchor\", \"appearance\" or \"numeric\"", *flagRefOrder)
+	}
+
+	switch *flagDiag {
+	case "text":
+	case "json":
+		reporter.json = true
+		log.SetFlags(0)
+	default:
+		log.Fatalf("Unknown diagnostics format %q, use \"text\" or \"json\"", *flagDiag)
+	}
+	log.SetOutput(reporter)
+
+	switch *flagXML2RFC {
+	case "", "text", "html", "pdf":
+	default:
+		log.Fatalf("Unknown xml2rfc format %q, use \"text\", \"html\" or \"pdf\"", *flagXML2RFC)
+	}
+
+	if err := startProfiles(*flagCPUProf, *flagMemProf); err != nil {
+		log.Fatalf("Couldn't start profiling: %s", err)
+	}
+
+	inputs, err := expandArgs(args)
+	if err != nil {
+		log.Fatalf("Couldn't read the tree: %s", err)
+	}
+	if len(flagOutput) > 0 && len(inputs) > 1 {
+		log.Fatalf("Can't write %d files to -o, use -formats and -outdir", len(inputs))
+	}
+
+	if (*flagIncr || *flagWatch) && len(targets) == 0 {
+		log.Fatalf("Can't compare the outputs with the document, use -o, -formats or -outdir")
+	}
+	if *flagWatch && slices.Contains(args, "os.Stdin") {
+		log.Fatalf("Can't watch standard input")
+	}
+
+	status := 0
+	if *flagWatch && !*flagDeps && !*flagAst {
+		watch(args, targets)
+	}
+	if len(targets) > 0 && !*flagDeps && !*flagAst {
+		status = renderInputs(inputs, targets)
+		if *flagStrict && reporter.warnings > 0 {
+			status = 1
+		}
+		exit(status)
+	}
+
+	for _, in := range inputs {
+		fileName := in.file
+		d, err := readDocument(fileName)
+		if err != nil {
+			continue
+		}
+
+		out := output()
+		x, pages, s := convert(fileName, d, out, true)
+		if s > status {
+			status = s
+		}
+		if !writePages("", pages) {
+			status = 1
+		}
+		if x == nil {
+			continue
+		}
+		if *flagXML2RFC != "" && out == "xml" {
+			data, messages, err := xml2rfc(x, *flagXML2RFC, *flagXML2RFCURL)
+			for _, m := range messages {
+				reporter.report(fileName, xml2rfcDiagnostic(m, x, d))
 			}
-		} else {
-			init = mparser.NewInitial(fileName)
-			d, err = ioutil.ReadFile(fileName)
 			if err != nil {
-				log.Printf("Couldn't open %q: %q", fileName, err)
+				reporter.reportError(fileName, "xml2rfc", err)
+				status = 1
 				continue
 			}
+			os.Stdout.Write(data)
+			continue
 		}
+		if binary(out) { // binary output, no trailing newline.
+			os.Stdout.Write(x)
+			continue
+		}
+		fmt.Println(string(x))
+	}
+	if *flagStrict && reporter.warnings > 0 {
+		status = 1
+	}
+	exit(status)
+}
 
-		d = markdown.NormalizeNewlines(d)
+// readDocument reads the document in fileName, or standard input if it is "os.Stdin", and expands the
+// variables and the fenced divs in it. Errors are logged.
+func readDocument(fileName string) ([]byte, error) {
+	var (
+		d   []byte
+		err error
+	)
+	if fileName == "os.Stdin" {
+		if d, err = ioutil.ReadAll(os.Stdin); err != nil {
+			reporter.reportError("", "read", err)
+			return nil, err
+		}
+	} else {
+		if d, err = ioutil.ReadFile(fileName); err != nil {
+			reporter.reportError(fileName, "read", err)
+			return nil, err
+		}
+	}
 
-		if *flagUnsafe {
-			init.Flags |= mparser.UnsafeInclude
+	d = markdown.NormalizeNewlines(d)
+	d = mparser.ExpandVariables(d)
+	d = mparser.DivFences(d)
+	return d, nil
+}
+
+// newInitial returns the mparser.Initial for the document d in fileName, set up with the flags.
+func newInitial(fileName string, d []byte) mparser.Initial {
+	init := mparser.NewInitial(fileName)
+	if fileName == "os.Stdin" {
+		init = mparser.NewInitial("")
+	}
+	if *flagUnsafe {
+		init.Flags |= mparser.UnsafeInclude
+	}
+	init.Source = d
+	init.Defines = map[string]bool{}
+	for _, name := range strings.Split(*flagDefine, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			init.Defines[name] = true
 		}
+	}
+	return init
+}
 
-		if !*flagIntraEmph {
-			mparser.Extensions |= parser.NoIntraEmphasis
+// convert parses the document d in fileName and renders it as out, one of the outputs. Only when
+// primary is true the diagnostics are logged and the slug map is written, so these happen once when
+// the document is rendered to more than one output. The returned data is nil when there is nothing
+// left to write, or when the document is split in manual pages, these are returned instead. The status
+// is 1 when the document is incomplete.
+func convert(fileName string, d []byte, out string, primary bool) ([]byte, []man.Page, int) {
+	init := newInitial(fileName, d)
+	// the parse diagnostics are the same for every output, the rendering ones are not.
+	report := reporter.forFile(fileName)
+	init.Report = func(mparser.Diagnostic) {}
+	if primary {
+		init.Report = report
+	}
+	deps := []string{}
+	if *flagDeps {
+		cwd, _ := os.Getwd()
+		seen := map[string]bool{}
+		init.Record = func(dep string) {
+			if rel, err := filepath.Rel(cwd, dep); err == nil && (*flagReproduce || !strings.HasPrefix(rel, "..")) {
+				dep = rel
+			}
+			if !seen[dep] {
+				seen[dep] = true
+				deps = append(deps, dep)
+			}
 		}
+	}
+	init.Fetcher = mparser.NewFetcher()
+	init.Fetcher.TTL = *flagIncludeTTL
+	init.Fetcher.Offline = *flagOffline
+	init.Fetcher.MaxSize = mparser.MaxIncludeSize
+	init.Fetcher.Report = init.Report
+
+	citeStyle := mhtml.CiteAnchor
+	switch *flagCiteStyle {
+	case "anchor":
+	case "numeric":
+		citeStyle = mhtml.CiteNumeric
+	case "author-year":
+		citeStyle = mhtml.CiteAuthorYear
+	default:
+		log.Fatalf("Unknown citation style %q, use \"anchor\", \"numeric\" or \"author-year\"", *flagCiteStyle)
+	}
 
-		p := parser.NewWithExtensions(mparser.Extensions)
-		parserFlags := parser.FlagsNone
-		documentTitle := ""      // hack to get document title from toml title block and then set it here.
-		documentLanguage := "en" // get document language from title block if it is set.
-		if !*flagHTML && !*flagMan {
+	footnotes := xml.FootnoteNone
+	switch *flagFootnotes {
+	case "":
+	case "cref":
+		footnotes = xml.FootnoteCref
+	case "notes":
+		footnotes = xml.FootnoteNotes
+	default:
+		log.Fatalf("Unknown footnote style %q, use \"cref\" or \"notes\"", *flagFootnotes)
+	}
+
+	switch *flagComments {
+	case "", "strip", "keep", "cref":
+	default:
+		log.Fatalf("Unknown comment policy %q, use \"strip\", \"keep\" or \"cref\"", *flagComments)
+	}
+
+	slugs := *flagSlug != "" || *flagSlugPrefix != "" || *flagSlugMap != ""
+	p := parser.NewWithExtensions(mparser.Extensions)
+	mparser.RegisterInlines(p)
+	parserFlags := parser.FlagsNone
+	documentTitle := ""      // hack to get document title from toml title block and then set it here.
+	documentLanguage := "en" // get document language from title block if it is set.
+	if out == "xml" || out == "meta" {
+		if footnotes == xml.FootnoteNone {
 			parserFlags |= parser.SkipFootnoteList // both xml formats don't deal with footnotes well.
 		}
-		p.Opts = parser.Options{
-			ParserHook: func(data []byte) (ast.Node, []byte, int) {
-				node, data, consumed := mparser.Hook(data)
-				if t, ok := node.(*mast.Title); ok {
-					documentTitle = t.TitleData.Title
-					documentLanguage = t.TitleData.Language
-				}
-				return node, data, consumed
-			},
-			ReadIncludeFn: init.ReadInclude,
-			Flags:         parserFlags,
-		}
-
-		doc := markdown.Parse(d, p)
-		if *flagMan {
-			title := false
-			// If there isn't a title block the resulting manual page does not start
-			// with .TH, this messes up the entire rendering. Walk to AST to check for
-			// a title block, and if none is found inject an empty one.
-			ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
-				if _, ok := node.(*mast.Title); ok {
-					title = true
-					return ast.Terminate
-				}
-				return ast.GoToNext
-			})
-			if !title {
-				t := &mast.Title{TitleData: &mast.TitleData{Title: "User Commands 1"}}
-				c := doc.GetChildren()
-				newc := append([]ast.Node{t}, c...)
-				doc.SetChildren(newc) // t must be the first element.
-			} else {
-				ast.AppendChild(doc, &mast.Authors{})
+	}
+	p.Opts = parser.Options{
+		ParserHook: func(data []byte) (ast.Node, []byte, int) {
+			node, data, consumed := init.Hook(data)
+			if t, ok := node.(*mast.Title); ok {
+				documentTitle = t.TitleData.Title
+				documentLanguage = t.TitleData.Language
 			}
+			return node, data, consumed
+		},
+		ReadIncludeFn: init.ReadInclude,
+		Flags:         parserFlags,
+	}
+	if out == "asciidoc" {
+		p.Opts.ReadIncludeFn = asciidoc.ReadInclude(init.ReadInclude)
+	}
 
+	status := 0
+	doc := markdown.Parse(d, p)
+	if init.Failed() {
+		status = 1 // the document is still output, but incomplete.
+		if primary {
+			reporter.report(fileName, mparser.Diagnostic{Message: "the document is incomplete, an include failed", Check: "include", Severity: mparser.SeverityError})
 		}
-		if *flagBib {
-			mparser.AddBibliography(doc)
+	}
+	if slugs {
+		var pinned []mparser.Slug
+		if *flagSlugMap != "" {
+			var err error
+			if pinned, err = mparser.ReadSlugs(*flagSlugMap); err != nil {
+				log.Fatalf("Failure to read slug map: %s", err)
+			}
 		}
-		if *flagIndex {
-			mparser.AddIndex(doc)
+		s := mparser.AddSlugs(doc, *flagSlug, *flagSlugPrefix, pinned)
+		if *flagSlugMap != "" && primary {
+			if err := mparser.WriteSlugs(*flagSlugMap, s); err != nil {
+				log.Fatalf("Failure to write slug map: %s", err)
+			}
 		}
-
-		if *flagAst {
-			ast.Print(os.Stdout, doc)
-			fmt.Print("\n")
-			return
+	}
+	if out == "man" {
+		title := false
+		// If there isn't a title block the resulting manual page does not start
+		// with .TH, this messes up the entire rendering. Walk to AST to check for
+		// a title block, and if none is found inject an empty one.
+		ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+			if _, ok := node.(*mast.Title); ok {
+				title = true
+				return ast.Terminate
+			}
+			return ast.GoToNext
+		})
+		if !title {
+			t := &mast.Title{TitleData: &mast.TitleData{Title: "User Commands 1", Date: mparser.Today}}
+			c := doc.GetChildren()
+			newc := append([]ast.Node{t}, c...)
+			doc.SetChildren(newc) // t must be the first element.
+		} else {
+			ast.AppendChild(doc, &mast.Authors{})
 		}
 
-		var renderer markdown.Renderer
-
+	}
+	if *flagGFM {
+		mparser.AddGFM(doc, out == "html" || out == "epub" || out == "slides")
+	}
+	mparser.AddContributors(doc)
+	mparser.AddCallouts(doc)
+	mparser.AddUnicode(doc)
+	switch {
+	case *flagComments == "strip", *flagComments == "cref" && *flagFinal:
+		mparser.StripComments(doc)
+	case *flagComments == "cref":
+		mparser.AddCommentCrefs(doc)
+	}
+	mparser.AddCrefs(doc, *flagFinal)
+	terminology := ""
+	if *flagTerms {
+		terminology = lang.New(documentLanguage).Terminology()
+	}
+	mparser.AddAbbreviations(doc, terminology)
+	mparser.AddGlossary(doc)
+	mparser.ParseIndexModifiers(doc)
+	mparser.AddRowSpans(doc)
+	mparser.AddImageAttributes(doc)
+	if primary {
+		reporter.reportAll(fileName, mparser.CheckImages(doc, d), mparser.LintImageAlt)
+	}
+	if *flagFold == "check" && primary {
+		reporter.reportAll(fileName, mparser.CheckLineLength(doc, d, xml.FoldWidth), mparser.LintLineLength)
+	}
+	if *flagBCP14 {
+		mparser.AddBCP14(doc)
+	}
+	init.AddCSL(doc)
+	if *flagDeps {
+		for _, dep := range deps {
+			fmt.Println(dep)
+		}
+		return nil, nil, status
+	}
+	if *flagBib {
 		switch {
-		case *flagHTML:
-			mhtmlOpts := mhtml.RendererOptions{
-				Language: lang.New(documentLanguage),
-			}
-			opts := html.RendererOptions{
-				Comments:       [][]byte{[]byte("//"), []byte("#")}, // TODO(miek): make this an option.
-				RenderNodeHook: mhtmlOpts.RenderHook,
-				Flags:          html.CommonFlags | html.FootnoteNoHRTag | html.FootnoteReturnLinks,
-				Generator:      `  <meta name="GENERATOR" content="github.com/mmarkdown/mmark Mmark Markdown Processor - mmark.miek.nl`,
-			}
-			if !*flagFragment {
-				opts.Flags |= html.CompletePage
-			}
-			opts.CSS = *flagCSS
-			if *flagHead != "" {
-				head, err := ioutil.ReadFile(*flagHead)
-				if err != nil {
-					log.Printf("Couldn't open %q, error: %q", *flagHead, err)
-					continue
+		case !*flagNoFetch:
+			f := mparser.NewFetcher()
+			f.Dir = *flagBibDir
+			f.URL = *flagBibXML
+			f.Offline = *flagOffline
+			f.Report = init.Report
+			f.AddReferences(doc)
+		case *flagBibDir != "":
+			f := &mparser.Fetcher{Dir: *flagBibDir, Report: init.Report}
+			f.AddReferences(doc)
+		}
+		if primary {
+			reporter.reportAll(fileName, append(mparser.ValidateReferences(doc, d), mparser.CheckReferences(doc, d)...), mparser.LintReference)
+		}
+		if *flagNormRefs {
+			mparser.NormalizeReferences(doc)
+		}
+		if *flagRefs {
+			// the bibliography is output on its own, so it doesn't need the back matter.
+			norm, inform := mparser.CitationToBibliography(doc)
+			for _, b := range []ast.Node{norm, inform} {
+				if b != nil {
+					ast.AppendChild(doc, b)
 				}
-				opts.Head = head
-			}
-			if documentTitle != "" {
-				opts.Title = documentTitle
 			}
+		} else {
+			mparser.AddBibliography(doc)
+		}
+	}
+	if *flagIndex {
+		mparser.AddIndex(doc)
+	}
+	for _, command := range flagFilter {
+		filtered, err := filter(doc, d, command, out)
+		if err != nil {
+			reporter.report(fileName, mparser.Diagnostic{Message: err.Error(), Check: "filter", Severity: mparser.SeverityError})
+			return nil, nil, 1
+		}
+		doc = filtered
+	}
+	switch out {
+	case "text", "pdf", "man", "mdoc", "latex", "gemtext", "odt":
+		mparser.FlattenTableCells(doc) // these can only have inline elements in a table cell.
+	}
 
-			renderer = html.NewRenderer(opts)
-		case *flagMan:
-			opts := man.RendererOptions{
-				Comments: [][]byte{[]byte("//"), []byte("#")},
-				Language: lang.New(documentLanguage),
-			}
-			if *flagFragment {
-				opts.Flags |= man.ManFragment
-			}
-			renderer = man.NewRenderer(opts)
-		default:
-			opts := xml.RendererOptions{
-				Flags:    xml.CommonFlags,
-				Comments: [][]byte{[]byte("//"), []byte("#")},
-				Language: lang.New(documentLanguage),
-			}
-			if *flagFragment {
-				opts.Flags |= xml.XMLFragment
-			}
-			if *flagUnicode {
-				opts.Flags |= xml.AllowUnicode
+	if *flagAst {
+		ast.Print(os.Stdout, doc)
+		fmt.Print("\n")
+		exit(status)
+	}
+
+	var renderer markdown.Renderer
+	diagrams := diagram.New(flagDiagram.engines())
+	diagrams.Report = report
+	var svg func(src string) []byte
+	if *flagInlineSVG {
+		svg = init.SVG
+	}
+
+	switch {
+	case out == "json":
+		renderer = json.NewRenderer(json.RendererOptions{Flags: json.CommonFlags, Source: d})
+	case out == "pandoc":
+		renderer = pandoc.NewRenderer(pandoc.RendererOptions{})
+	case out == "html":
+		mhtmlOpts := mhtml.RendererOptions{
+			Language: lang.New(documentLanguage),
+			Labels:   mhtml.Labels(doc, citeStyle),
+			Diagrams: diagrams,
+			SVG:      svg,
+		}
+		opts := html.RendererOptions{
+			Comments:       [][]byte{[]byte("//"), []byte("#")}, // TODO(miek): make this an option.
+			RenderNodeHook: mhtmlOpts.RenderHook,
+			Flags:          html.CommonFlags | html.FootnoteNoHRTag | html.FootnoteReturnLinks,
+			Generator:      `  <meta name="GENERATOR" content="github.com/mmarkdown/mmark Mmark Markdown Processor - mmark.miek.nl`,
+		}
+		if !*flagFragment {
+			opts.Flags |= html.CompletePage
+		}
+		opts.CSS = *flagCSS
+		if *flagHead != "" {
+			head, err := ioutil.ReadFile(*flagHead)
+			if err != nil {
+				reporter.reportError(*flagHead, "read", err)
+				return nil, nil, status
 			}
+			opts.Head = head
+		}
+		if documentTitle != "" {
+			opts.Title = documentTitle
+		}
 
-			renderer = xml.NewRenderer(opts)
+		renderer = html.NewRenderer(opts)
+	case out == "epub":
+		mhtmlOpts := mhtml.RendererOptions{
+			Language: lang.New(documentLanguage),
+			Labels:   mhtml.Labels(doc, citeStyle),
+			Diagrams: diagrams,
+			SVG:      svg,
+		}
+		opts := epub.RendererOptions{
+			Language:       lang.New(documentLanguage),
+			CSS:            *flagCSS,
+			RenderNodeHook: mhtmlOpts.RenderHook,
+			Report:         report,
+		}
+		if fileName != "os.Stdin" {
+			opts.Dir = filepath.Dir(fileName)
+		}
+		if *flagReproduce {
+			opts.Modified = mparser.Today
+		}
+		renderer = epub.NewRenderer(opts)
+	case *flagOutline != "":
+		opts := outline.RendererOptions{}
+		switch *flagOutline {
+		case "dot":
+		case "mermaid":
+			opts.Flags |= outline.Mermaid
+		default:
+			log.Fatalf("Unknown outline format %q, use \"dot\" or \"mermaid\"", *flagOutline)
+		}
+		renderer = outline.NewRenderer(opts)
+	case out == "meta":
+		renderer = meta.NewRenderer(meta.RendererOptions{})
+	case *flagRefs:
+		renderer = refs.NewRenderer(refs.RendererOptions{})
+	case out == "odt":
+		opts := odt.RendererOptions{
+			Language: lang.New(documentLanguage),
+			Report:   report,
+		}
+		if fileName != "os.Stdin" {
+			opts.Dir = filepath.Dir(fileName)
+		}
+		if *flagReproduce {
+			opts.Modified = mparser.Today
+		}
+		renderer = odt.NewRenderer(opts)
+	case out == "slides":
+		mhtmlOpts := mhtml.RendererOptions{
+			Language: lang.New(documentLanguage),
+			Labels:   mhtml.Labels(doc, citeStyle),
+			Diagrams: diagrams,
+			SVG:      svg,
+		}
+		opts := slides.RendererOptions{
+			Language:       lang.New(documentLanguage),
+			Theme:          *flagTheme,
+			Transition:     *flagTrans,
+			CSS:            *flagCSS,
+			RenderNodeHook: mhtmlOpts.RenderHook,
+		}
+		if *flagFragment {
+			opts.Flags |= slides.SlidesFragment
+		}
+		renderer = slides.NewRenderer(opts)
+	case out == "man":
+		opts := man.RendererOptions{
+			Comments: [][]byte{[]byte("//"), []byte("#")},
+			Language: lang.New(documentLanguage),
+			Diagrams: diagrams,
+			Date:     mparser.Today,
+			Report:   report,
+		}
+		if *flagFragment {
+			opts.Flags |= man.ManFragment
+		}
+		renderer = man.NewRenderer(opts)
+	case out == "mdoc":
+		opts := mdoc.RendererOptions{
+			Language: lang.New(documentLanguage),
+			Date:     mparser.Today,
+			Report:   report,
+		}
+		if *flagFragment {
+			opts.Flags |= mdoc.MdocFragment
+		}
+		renderer = mdoc.NewRenderer(opts)
+	case out == "latex":
+		opts := latex.RendererOptions{
+			Language: lang.New(documentLanguage),
+		}
+		if *flagFragment {
+			opts.Flags |= latex.LatexFragment
+		}
+		renderer = latex.NewRenderer(opts)
+	case out == "jats":
+		opts := jats.RendererOptions{
+			Language: lang.New(documentLanguage),
+		}
+		if *flagFragment {
+			opts.Flags |= jats.JATSFragment
+		}
+		renderer = jats.NewRenderer(opts)
+	case out == "asciidoc":
+		opts := asciidoc.RendererOptions{
+			Language: lang.New(documentLanguage),
+		}
+		if *flagFragment {
+			opts.Flags |= asciidoc.AsciiDocFragment
+		}
+		renderer = asciidoc.NewRenderer(opts)
+	case out == "gemtext":
+		opts := gemtext.RendererOptions{
+			Language: lang.New(documentLanguage),
+		}
+		if *flagFragment {
+			opts.Flags |= gemtext.GemtextFragment
+		}
+		renderer = gemtext.NewRenderer(opts)
+	case out == "pdf":
+		opts := pdf.RendererOptions{
+			Language: lang.New(documentLanguage),
+			Width:    *flagWidth,
+		}
+		if *flagFragment {
+			opts.Flags |= pdf.PDFFragment
+		}
+		renderer = pdf.NewRenderer(opts)
+	case out == "text":
+		opts := text.RendererOptions{
+			Language: lang.New(documentLanguage),
+			Diagrams: diagrams,
+			Width:    *flagWidth,
+		}
+		if *flagFragment {
+			opts.Flags |= text.TextFragment
+		}
+		renderer = text.NewRenderer(opts)
+	default:
+		opts := xml.RendererOptions{
+			Flags:      xml.CommonFlags,
+			Comments:   [][]byte{[]byte("//"), []byte("#")},
+			Language:   lang.New(documentLanguage),
+			Diagrams:   diagrams,
+			Footnotes:  footnotes,
+			SVG:        svg,
+			Sourcecode: flagSource,
+			Report:     report,
+		}
+		if *flagFragment {
+			opts.Flags |= xml.XMLFragment
+		}
+		if *flagUnicode {
+			opts.Flags |= xml.AllowUnicode
+		}
+		if *flagComments == "keep" {
+			opts.Flags |= xml.KeepComments
+		}
+		switch *flagFold {
+		case "", "check":
+		case "single":
+			opts.Flags |= xml.FoldLines
+		case "double":
+			opts.Flags |= xml.FoldDouble
+		default:
+			log.Fatalf("Unknown fold strategy %q, use \"single\", \"double\" or \"check\"", *flagFold)
 		}
 
-		x := markdown.Render(doc, renderer)
+		renderer = xml.NewRenderer(opts)
+	}
 
-		fmt.Println(string(x))
+	x := markdown.Render(doc, renderer)
+
+	if m, ok := renderer.(*man.Renderer); ok && m.Pages() != nil {
+		return nil, m.Pages(), status
 	}
+
+	return x, nil, status
 }
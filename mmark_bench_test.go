@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/mmarkdown/mmark/v2/lang"
+	"github.com/mmarkdown/mmark/v2/render/mhtml"
+	"github.com/mmarkdown/mmark/v2/render/text"
+	"github.com/mmarkdown/mmark/v2/render/xml"
+
+	"github.com/gomarkdown/markdown/html"
+)
+
+// corpus returns a draft with sections sections and references references, each section cites four of
+// them. With 500 sections and 2000 references this is a draft of about 2 MB. Only every tenth section
+// has a list, as the parser's handling of lists is quadratic in the number of blocks in the document
+// and would dominate the benchmarks.
+func corpus(sections, references int) []byte {
+	b := &bytes.Buffer{}
+	b.WriteString("%%%\ntitle = \"A Large Draft\"\nabbrev = \"Large\"\nipr = \"trust200902\"\n\n")
+	b.WriteString("[seriesInfo]\nname = \"Internet-Draft\"\nvalue = \"draft-bench-large-00\"\nstream = \"IETF\"\nstatus = \"standard\"\n\n")
+	b.WriteString("[[author]]\ninitials = \"J.\"\nsurname = \"Doe\"\nfullname = \"Jane Doe\"\n%%%\n\n")
+	b.WriteString(".# Abstract\n\nThis draft is large, it is used to benchmark mmark.\n\n{mainmatter}\n\n")
+	for s := 0; s < sections; s++ {
+		fmt.Fprintf(b, "# Section %d {#sec-%d}\n\n", s, s)
+		for p := 0; p < 8; p++ {
+			fmt.Fprintf(b, "Implementations **MUST** handle the *emphasized* `code` of this paragraph, as said in "+
+				"(#sec-%d) and [@ref-%d; @ref-%d]. A second sentence, with a [link](https://example.org/%d) and "+
+				"more text to make this paragraph longer, so it wraps a few times in the text output.\n\n",
+				(s+p)%sections, (s*4+p)%references, (s*4+p+1)%references, p)
+		}
+		fmt.Fprintf(b, "Name | Value | Description\n:----|------:|------------\n")
+		for r := 0; r < 10; r++ {
+			fmt.Fprintf(b, "field-%d | %d | The description of field %d\n", r, r*s, r)
+		}
+		fmt.Fprintf(b, "Table: The fields of section %d\n\n", s)
+		fmt.Fprintf(b, "{#fig-%d}\n~~~ go\nfunc section%d() int {\n\treturn %d // <1>\n}\n~~~\nFigure: Code of section %d\n\n", s, s, s, s)
+		if s%10 == 0 {
+			fmt.Fprintf(b, "- one item\n- another item, citing [@!ref-%d]\n- a last item\n\n", (s*4+2)%references)
+		}
+	}
+	b.WriteString("{backmatter}\n\n")
+	for r := 0; r < references; r++ {
+		fmt.Fprintf(b, "<reference anchor='ref-%d' target='https://example.org/ref/%d'>\n<front>\n<title>Reference %d</title>\n"+
+			"<author initials='J.' surname='Doe' fullname='Jane Doe'><organization>Example</organization></author>\n"+
+			"<date year='2024' month='January'/>\n</front>\n</reference>\n\n", r, r, r)
+	}
+	return b.Bytes()
+}
+
+func benchmarkRender(b *testing.B, renderer func() markdown.Renderer) {
+	file := filepath.Join(b.TempDir(), "draft.md")
+	data := corpus(500, 2000)
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer() // the renderers change the AST, so it is parsed for every run.
+		doc, _, _, err := parseText(file)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+		markdown.Render(doc, renderer())
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	file := filepath.Join(b.TempDir(), "draft.md")
+	data := corpus(500, 2000)
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, _, err := parseText(file); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRenderXML(b *testing.B) {
+	benchmarkRender(b, func() markdown.Renderer {
+		return xml.NewRenderer(xml.RendererOptions{Flags: xml.CommonFlags, Language: lang.New("en")})
+	})
+}
+
+func BenchmarkRenderHTML(b *testing.B) {
+	benchmarkRender(b, func() markdown.Renderer {
+		mhtmlOpts := mhtml.RendererOptions{Language: lang.New("en")}
+		return html.NewRenderer(html.RendererOptions{Flags: html.CommonFlags | html.CompletePage, RenderNodeHook: mhtmlOpts.RenderHook})
+	})
+}
+
+func BenchmarkRenderText(b *testing.B) {
+	benchmarkRender(b, func() markdown.Renderer {
+		return text.NewRenderer(text.RendererOptions{Language: lang.New("en")})
+	})
+}
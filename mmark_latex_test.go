@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/google/go-cmp/cmp"
+	"github.com/mmarkdown/mmark/v2/mparser"
+	"github.com/mmarkdown/mmark/v2/render/latex"
+)
+
+func TestMmarkLatex(t *testing.T) {
+	dir := "testdata/latex"
+	testFiles, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("could not read %s: %q", dir, err)
+	}
+	for _, f := range testFiles {
+		if f.IsDir() {
+			continue
+		}
+
+		if filepath.Ext(f.Name()) != ".md" {
+			continue
+		}
+		base := f.Name()[:len(f.Name())-3]
+		opts := latex.RendererOptions{Flags: latex.LatexFragment}
+
+		renderer := latex.NewRenderer(opts)
+
+		doTestLatex(t, dir, base, renderer)
+	}
+}
+
+func doTestLatex(t *testing.T, dir, basename string, renderer markdown.Renderer) {
+	filename := filepath.Join(dir, basename+".md")
+	input, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Errorf("couldn't open '%s', error: %v\n", filename, err)
+		return
+	}
+
+	filename = filepath.Join(dir, basename+".tex")
+	expected, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Errorf("couldn't open '%s', error: %v\n", filename, err)
+	}
+	expected = bytes.TrimSpace(expected)
+
+	p := parser.NewWithExtensions(mparser.Extensions)
+	p.Opts = parser.Options{ParserHook: mparser.Hook}
+	doc := markdown.Parse(input, p)
+	actual := markdown.Render(doc, renderer)
+	actual = bytes.TrimSpace(actual)
+
+	if diff := cmp.Diff(string(expected), string(actual)); diff != "" {
+		t.Errorf("%s: differs: (-want +got)\n%s", basename+".md", diff)
+		t.Logf("\n%s\n%s\n%s\n", "---", string(actual), "---")
+	}
+}
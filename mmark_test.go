@@ -71,6 +71,7 @@ func doTest(t *testing.T, dir, basename string, renderer markdown.Renderer) {
 	expected = bytes.TrimSpace(expected)
 
 	p := parser.NewWithExtensions(mparser.Extensions)
+	mparser.RegisterInlines(p)
 
 	init := mparser.NewInitial(filename)
 	p.Opts = parser.Options{
@@ -85,3 +86,23 @@ func doTest(t *testing.T, dir, basename string, renderer markdown.Renderer) {
 			basename+".md", expected, actual)
 	}
 }
+
+func TestSourceDate(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	d, err := sourceDate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := d.Format("2006-01-02T15:04:05"); s != "2023-11-14T00:00:00" {
+		t.Errorf("expected %q, got %q", "2023-11-14T00:00:00", s)
+	}
+
+	t.Setenv("SOURCE_DATE_EPOCH", "")
+	if d, _ := sourceDate(); d.Year() != 1970 {
+		t.Errorf("expected the epoch, got %s", d)
+	}
+	t.Setenv("SOURCE_DATE_EPOCH", "yesterday")
+	if _, err := sourceDate(); err == nil {
+		t.Errorf("expected an error for an invalid SOURCE_DATE_EPOCH")
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/google/go-cmp/cmp"
+	"github.com/mmarkdown/mmark/v2/lang"
+	"github.com/mmarkdown/mmark/v2/mparser"
+	"github.com/mmarkdown/mmark/v2/render/text"
+)
+
+func TestMmarkText(t *testing.T) {
+	dir := "testdata/text"
+	testFiles, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("could not read %s: %q", dir, err)
+	}
+	for _, f := range testFiles {
+		if f.IsDir() {
+			continue
+		}
+
+		if filepath.Ext(f.Name()) != ".md" {
+			continue
+		}
+		base := f.Name()[:len(f.Name())-3]
+		opts := text.RendererOptions{Flags: text.TextFragment}
+
+		renderer := text.NewRenderer(opts)
+
+		doTestText(t, dir, base, renderer)
+	}
+}
+
+// TestMmarkTextDocument renders full documents, with a title page, table of contents, pages and
+// references.
+func TestMmarkTextDocument(t *testing.T) {
+	dir := "testdata/text/document"
+	testFiles, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("could not read %s: %q", dir, err)
+	}
+	for _, f := range testFiles {
+		if filepath.Ext(f.Name()) != ".md" {
+			continue
+		}
+		base := f.Name()[:len(f.Name())-3]
+		input, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := ioutil.ReadFile(filepath.Join(dir, base+".txt"))
+		if err != nil {
+			t.Errorf("couldn't open '%s', error: %v\n", base+".txt", err)
+		}
+
+		p := parser.NewWithExtensions(mparser.Extensions)
+		mparser.RegisterInlines(p)
+		p.Opts = parser.Options{ParserHook: mparser.Hook}
+		doc := markdown.Parse(input, p)
+		mparser.AddBibliography(doc)
+		actual := markdown.Render(doc, text.NewRenderer(text.RendererOptions{Language: lang.New("en")}))
+
+		if diff := cmp.Diff(string(expected), string(actual)); diff != "" {
+			t.Errorf("%s: differs: (-want +got)\n%s", f.Name(), diff)
+		}
+	}
+}
+
+func doTestText(t *testing.T, dir, basename string, renderer markdown.Renderer) {
+	filename := filepath.Join(dir, basename+".md")
+	input, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Errorf("couldn't open '%s', error: %v\n", filename, err)
+		return
+	}
+
+	filename = filepath.Join(dir, basename+".txt")
+	expected, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Errorf("couldn't open '%s', error: %v\n", filename, err)
+	}
+	expected = bytes.TrimSpace(expected)
+
+	p := parser.NewWithExtensions(mparser.Extensions)
+	p.Opts = parser.Options{ParserHook: mparser.Hook}
+	doc := markdown.Parse(input, p)
+	actual := markdown.Render(doc, renderer)
+	actual = bytes.TrimSpace(actual)
+
+	if diff := cmp.Diff(string(expected), string(actual)); diff != "" {
+		t.Errorf("%s: differs: (-want +got)\n%s", basename+".md", diff)
+		t.Logf("\n%s\n%s\n%s\n", "---", string(actual), "---")
+	}
+}
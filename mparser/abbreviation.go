@@ -0,0 +1,133 @@
+package mparser
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+var abbreviationSyntax = regexp.MustCompile(`^\*\[([^\]]+)\]:[ \t]*(.+)$`)
+
+// AddAbbreviations finds the abbreviation definitions, *[TLS]: Transport Layer Security, in doc,
+// removes them and replaces the uses of the abbreviations in the text with mast.Abbreviation nodes. A
+// paragraph with definitions must contain only definitions. If terminology isn't empty a section with
+// that title is added to the end of doc, listing the abbreviations.
+func AddAbbreviations(doc ast.Node, terminology string) {
+	titles := map[string]string{}
+	defs := []ast.Node{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		para, ok := node.(*ast.Paragraph)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		t, ok := ast.GetFirstChild(para).(*ast.Text)
+		if !ok || len(para.Children) != 1 {
+			return ast.SkipChildren
+		}
+		found := map[string]string{}
+		for _, line := range bytes.Split(t.Literal, []byte("\n")) {
+			m := abbreviationSyntax.FindSubmatch(bytes.TrimSpace(line))
+			if m == nil {
+				return ast.SkipChildren
+			}
+			found[string(m[1])] = string(bytes.TrimSpace(m[2]))
+		}
+		for k, v := range found {
+			titles[k] = v
+		}
+		defs = append(defs, para)
+		return ast.SkipChildren
+	})
+	if len(titles) == 0 {
+		return
+	}
+	for _, d := range defs {
+		ast.RemoveFromTree(d)
+	}
+
+	abbrevs := make([]string, 0, len(titles))
+	for k := range titles {
+		abbrevs = append(abbrevs, k)
+	}
+	// longest first, so "QUICv2" is matched before "QUIC".
+	sort.Slice(abbrevs, func(i, j int) bool {
+		if len(abbrevs[i]) != len(abbrevs[j]) {
+			return len(abbrevs[i]) > len(abbrevs[j])
+		}
+		return abbrevs[i] < abbrevs[j]
+	})
+	quoted := make([]string, len(abbrevs))
+	for i := range abbrevs {
+		quoted[i] = regexp.QuoteMeta(abbrevs[i])
+	}
+	uses := regexp.MustCompile(`\b(?:` + strings.Join(quoted, "|") + `)\b`)
+
+	texts := []*ast.Text{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch n := node.(type) {
+		case *ast.Heading, *ast.Link, *mast.Title, *mast.ReferenceBlock:
+			return ast.SkipChildren
+		case *ast.Text:
+			if uses.Match(n.Literal) {
+				texts = append(texts, n)
+			}
+		}
+		return ast.GoToNext
+	})
+
+	seen := map[string]bool{}
+	for _, t := range texts {
+		nodes := []ast.Node{}
+		prev := 0
+		for _, m := range uses.FindAllIndex(t.Literal, -1) {
+			if m[0] > prev {
+				nodes = append(nodes, &ast.Text{Leaf: ast.Leaf{Literal: t.Literal[prev:m[0]]}})
+			}
+			abbr := string(t.Literal[m[0]:m[1]])
+			a := &mast.Abbreviation{Title: titles[abbr], First: !seen[abbr]}
+			a.Literal = []byte(abbr)
+			seen[abbr] = true
+			nodes = append(nodes, a)
+			prev = m[1]
+		}
+		if prev < len(t.Literal) {
+			nodes = append(nodes, &ast.Text{Leaf: ast.Leaf{Literal: t.Literal[prev:]}})
+		}
+		replaceText(t, nodes)
+	}
+
+	if terminology != "" {
+		sort.Strings(abbrevs)
+		addTerminology(doc, terminology, abbrevs, titles)
+	}
+}
+
+// addTerminology adds a section with the title and a definition list of the abbreviations to the end
+// of doc.
+func addTerminology(doc ast.Node, title string, abbrevs []string, titles map[string]string) {
+	heading := &ast.Heading{Level: 1, HeadingID: "terminology"}
+	ast.AppendChild(heading, &ast.Text{Leaf: ast.Leaf{Literal: []byte(title)}})
+	ast.AppendChild(doc, heading)
+
+	list := &ast.List{ListFlags: ast.ListTypeDefinition, Tight: true}
+	for _, a := range abbrevs {
+		for _, item := range []struct {
+			text  string
+			flags ast.ListType
+		}{{a, ast.ListTypeDefinition | ast.ListTypeTerm}, {titles[a], ast.ListTypeDefinition}} {
+			li := &ast.ListItem{ListFlags: item.flags, Tight: true}
+			para := &ast.Paragraph{}
+			ast.AppendChild(para, &ast.Text{Leaf: ast.Leaf{Literal: []byte(item.text)}})
+			ast.AppendChild(li, para)
+			ast.AppendChild(list, li)
+		}
+	}
+	ast.AppendChild(doc, list)
+}
@@ -0,0 +1,34 @@
+package mparser
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/render/xml"
+)
+
+func TestAddAbbreviations(t *testing.T) {
+	const doc = "# QUIC\n\nWe use QUIC, not QUICK.\n\n*[QUIC]: A UDP-Based Multiplexed and Secure Transport\n*[TLS]: Transport Layer Security\n\nQUIC and TLS again.\n"
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook}
+	d := markdown.Parse([]byte(doc), p)
+	AddAbbreviations(d, "Terminology")
+	out := markdown.Render(d, xml.NewRenderer(xml.RendererOptions{Flags: xml.CommonFlags | xml.XMLFragment}))
+
+	for _, want := range []string{
+		"<name>QUIC</name>",
+		"<t>We use A UDP-Based Multiplexed and Secure Transport (QUIC), not QUICK.</t>",
+		"<t>QUIC and Transport Layer Security (TLS) again.</t>",
+		`<section anchor="terminology"><name>Terminology</name>`,
+		"<dt>QUIC</dt>\n<dd>A UDP-Based Multiplexed and Secure Transport</dd>\n<dt>TLS</dt>\n<dd>Transport Layer Security</dd>",
+	} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Errorf("expected %s in:\n%s", want, out)
+		}
+	}
+	if bytes.Contains(out, []byte("*[")) {
+		t.Errorf("expected no definitions in:\n%s", out)
+	}
+}
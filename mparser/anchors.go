@@ -0,0 +1,240 @@
+package mparser
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// Anchor is an anchor defined in a document, as listed by mmark anchors.
+type Anchor struct {
+	Anchor     string `json:"anchor"`
+	Kind       string `json:"kind"` // "section", "figure", "table", "code", "quote", "reference", ...
+	Title      string `json:"title,omitempty"`
+	Line       int    `json:"line,omitempty"`
+	References []int  `json:"references"` // Lines of the cross references and citations to the anchor.
+}
+
+// Anchors returns the anchors defined in doc, in the order of the document: the sections, the blocks with
+// an ID, i.e. {#fig-a}, and the XML references. References that aren't in the document, but can be
+// fetched, i.e. RFC2119, are added after these. It also returns a diagnostic for each cross reference
+// or citation to an anchor that isn't defined. Doc must have its heading IDs set. The line numbers are
+// found by searching source, the markdown of doc.
+func Anchors(doc ast.Node, source []byte) ([]Anchor, []Diagnostic) {
+	anchors := []Anchor{}
+	defined := map[string]int{}    // anchor to index in anchors.
+	references := map[string]int{} // lowercased anchor of a reference to index in anchors.
+	xrefs := []string{}
+	cited := []string{}
+	add := func(a Anchor) {
+		if _, ok := defined[a.Anchor]; ok || a.Anchor == "" {
+			return
+		}
+		defined[a.Anchor] = len(anchors)
+		if a.Kind == "reference" {
+			references[strings.ToLower(a.Anchor)] = len(anchors)
+		}
+		anchors = append(anchors, a)
+	}
+
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch n := node.(type) {
+		case *mast.Title, *mast.Bibliography, *mast.DocumentIndex:
+			return ast.SkipChildren
+		case *ast.Heading:
+			if n.IsTitleblock {
+				return ast.SkipChildren
+			}
+			text := strings.TrimSpace(plainText(n))
+			line := lineOf(source, `\{#`+regexp.QuoteMeta(n.HeadingID)+`[\s}]`)
+			if line == 0 {
+				line = lineOf(source, `(?m)^\.?#+[ \t]*`+regexp.QuoteMeta(text))
+			}
+			add(Anchor{Anchor: n.HeadingID, Kind: "section", Title: text, Line: line})
+		case *mast.ReferenceBlock:
+			if a := anchorFromReference(n.Literal); a != nil {
+				add(Anchor{Anchor: string(a), Kind: "reference", Line: anchorLine(source, string(a))})
+			}
+			return ast.SkipChildren
+		case *ast.CrossReference:
+			xrefs = append(xrefs, string(n.Destination))
+		case *ast.Link:
+			if bytes.HasPrefix(n.Destination, []byte("#")) {
+				xrefs = append(xrefs, string(n.Destination[1:]))
+			}
+		case *ast.Citation:
+			for _, d := range n.Destination {
+				cited = append(cited, string(d))
+			}
+		}
+		if a := attributeID(node); a != "" {
+			add(Anchor{Anchor: a, Kind: kind(node), Title: caption(node), Line: anchorLine(source, a)})
+		}
+		return ast.GoToNext
+	})
+	// cited references that aren't in the document are fetched, unless we can't.
+	for _, c := range cited {
+		if _, ok := references[strings.ToLower(c)]; ok || !fetchable(c) {
+			continue
+		}
+		add(Anchor{Anchor: c, Kind: "reference"})
+	}
+
+	diags := []Diagnostic{}
+	dangling := map[string]bool{}
+	for _, x := range xrefs {
+		i, ok := defined[x]
+		if !ok {
+			dangling[x] = true
+			continue
+		}
+		anchors[i].References = uses(source, `\(#`+regexp.QuoteMeta(x)+`[\s,)]|\]\(#`+regexp.QuoteMeta(x)+`\)`)
+	}
+	for _, c := range cited {
+		i, ok := references[strings.ToLower(c)]
+		if !ok {
+			i, ok = defined[c]
+		}
+		if !ok {
+			dangling[c] = true
+			continue
+		}
+		anchors[i].References = append(anchors[i].References, uses(source, `@[!?-]?`+regexp.QuoteMeta(c)+`\b`)...)
+	}
+	for i := range anchors {
+		anchors[i].References = unique(anchors[i].References)
+	}
+
+	for d := range dangling {
+		line := lineOf(source, `[#@]`+regexp.QuoteMeta(d)+`\b`)
+		diags = append(diags, Diagnostic{Line: line, Check: "dangling-reference", Severity: SeverityError,
+			Message: fmt.Sprintf("reference to %q, which isn't defined", d)})
+	}
+	sort.Slice(diags, func(i, j int) bool {
+		if diags[i].Line == diags[j].Line {
+			return diags[i].Message < diags[j].Message
+		}
+		return diags[i].Line < diags[j].Line
+	})
+	return anchors, diags
+}
+
+// attributeID returns the ID of node given with an IAL, or the empty string.
+func attributeID(node ast.Node) string {
+	if _, ok := node.(*ast.Heading); ok {
+		return ""
+	}
+	if c := node.AsContainer(); c != nil && c.Attribute != nil {
+		return string(c.Attribute.ID)
+	}
+	if l := node.AsLeaf(); l != nil && l.Attribute != nil {
+		return string(l.Attribute.ID)
+	}
+	return ""
+}
+
+// kind returns the kind of anchor node has: "figure", "table", "code" or "quote", or for the other
+// blocks their type in lower case, i.e. "paragraph".
+func kind(node ast.Node) string {
+	switch n := node.(type) {
+	case *ast.CaptionFigure:
+		for _, c := range n.GetChildren() {
+			switch c.(type) {
+			case *ast.Table:
+				return "table"
+			case *ast.BlockQuote:
+				return "quote"
+			}
+		}
+		return "figure"
+	case *ast.Table:
+		return "table"
+	case *ast.CodeBlock:
+		return "code"
+	case *ast.BlockQuote:
+		return "quote"
+	case *ast.Image:
+		return "figure"
+	case *ast.Paragraph:
+		if image(n) {
+			return "figure"
+		}
+	}
+	t := fmt.Sprintf("%T", node)
+	return strings.ToLower(t[strings.LastIndex(t, ".")+1:])
+}
+
+// image returns true if the paragraph p only holds an image.
+func image(p *ast.Paragraph) bool {
+	images := 0
+	for _, c := range p.GetChildren() {
+		switch c := c.(type) {
+		case *ast.Image:
+			images++
+		case *ast.Text:
+			if len(bytes.TrimSpace(c.Literal)) > 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return images == 1
+}
+
+// caption returns the caption of a figure, or of the figure node is in, or the empty string.
+func caption(node ast.Node) string {
+	f, ok := node.(*ast.CaptionFigure)
+	if !ok {
+		f, ok = node.GetParent().(*ast.CaptionFigure)
+	}
+	if ok {
+		for _, c := range f.GetChildren() {
+			if c, ok := c.(*ast.Caption); ok {
+				return strings.TrimSpace(plainText(c))
+			}
+		}
+	}
+	return ""
+}
+
+// fetchable returns true if the reference for anchor is fetched when it is cited.
+func fetchable(anchor string) bool {
+	_, doi := isDOI(anchor)
+	_, erratum := isErratum(anchor)
+	return doi || erratum || isURL(anchor) || bibPath(anchor) != ""
+}
+
+// anchorLine returns the line of the definition of anchor in source, {#anchor} or anchor="anchor", or 0.
+func anchorLine(source []byte, anchor string) int {
+	return lineOf(source, `(\{#|anchor=["'])`+regexp.QuoteMeta(anchor)+`[\s}"']`)
+}
+
+// uses returns the lines of all matches of the regular expression expr in source.
+func uses(source []byte, expr string) []int {
+	re := regexp.MustCompile(expr)
+	lines := []int{}
+	for _, loc := range re.FindAllIndex(source, -1) {
+		lines = append(lines, bytes.Count(source[:loc[0]], []byte("\n"))+1)
+	}
+	return lines
+}
+
+func unique(lines []int) []int {
+	sort.Ints(lines)
+	u := []int{}
+	for i, l := range lines {
+		if i == 0 || l != lines[i-1] {
+			u = append(u, l)
+		}
+	}
+	return u
+}
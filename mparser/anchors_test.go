@@ -0,0 +1,72 @@
+package mparser
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+func TestAnchors(t *testing.T) {
+	doc := []byte(`# Introduction {#intro}
+
+See (#fig-a), [the table](#tab-a), [@pandoc; @RFC2119] and (#nope).
+
+{#fig-a}
+~~~
+code
+~~~
+Figure: A figure
+
+{#tab-a}
+| a | b |
+|---|---|
+| 1 | 2 |
+Table: A table
+
+# Discussion
+
+As said in (#intro), see [@missing].
+
+{backmatter}
+
+<reference anchor='pandoc' target='http://example.org'>
+<front><title>Pandoc</title></front>
+</reference>
+`)
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook}
+	d := markdown.Parse(doc, p)
+
+	anchors, diags := Anchors(d, doc)
+	want := []string{
+		`intro section "Introduction" 1 [19]`,
+		`fig-a figure "A figure" 5 [3]`,
+		`tab-a table "A table" 11 [3]`,
+		`discussion section "Discussion" 17 []`,
+		`pandoc reference "" 23 [3]`,
+		`RFC2119 reference "" 0 [3]`,
+	}
+	if len(anchors) != len(want) {
+		t.Fatalf("expected %d anchors, got %v", len(want), anchors)
+	}
+	for i, a := range anchors {
+		if got := fmt.Sprintf("%s %s %q %d %v", a.Anchor, a.Kind, a.Title, a.Line, a.References); got != want[i] {
+			t.Errorf("expected %s, got %s", want[i], got)
+		}
+	}
+
+	wantDiags := []string{
+		`3: reference to "nope", which isn't defined [dangling-reference]`,
+		`19: reference to "missing", which isn't defined [dangling-reference]`,
+	}
+	if len(diags) != len(wantDiags) {
+		t.Fatalf("expected %d diagnostics, got %v", len(wantDiags), diags)
+	}
+	for i := range wantDiags {
+		if diags[i].String() != wantDiags[i] {
+			t.Errorf("expected %q, got %q", wantDiags[i], diags[i])
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package mparser
+
+import (
+	"regexp"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+var keywords2119 = regexp.MustCompile(`\b(MUST NOT|MUST|SHALL NOT|SHALL|SHOULD NOT|SHOULD|NOT RECOMMENDED|RECOMMENDED|MAY|REQUIRED|OPTIONAL)\b`)
+
+// AddBCP14 makes the uppercase BCP 14 (RFC 2119) key words in the text of doc strong, so they are
+// rendered as <bcp14> in the XML output. Key words in code, block quotes, headings, links and text that
+// is already strong are left alone.
+func AddBCP14(doc ast.Node) {
+	texts := []*ast.Text{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch n := node.(type) {
+		case *ast.BlockQuote, *ast.Heading, *ast.Strong, *ast.Link, *mast.Title:
+			return ast.SkipChildren
+		case *ast.Text:
+			if keywords2119.Match(n.Literal) {
+				texts = append(texts, n)
+			}
+		}
+		return ast.GoToNext
+	})
+
+	for _, t := range texts {
+		nodes := []ast.Node{}
+		prev := 0
+		for _, m := range keywords2119.FindAllIndex(t.Literal, -1) {
+			if m[0] > prev {
+				nodes = append(nodes, &ast.Text{Leaf: ast.Leaf{Literal: t.Literal[prev:m[0]]}})
+			}
+			strong := &ast.Strong{}
+			ast.AppendChild(strong, &ast.Text{Leaf: ast.Leaf{Literal: t.Literal[m[0]:m[1]]}})
+			nodes = append(nodes, strong)
+			prev = m[1]
+		}
+		if prev < len(t.Literal) {
+			nodes = append(nodes, &ast.Text{Leaf: ast.Leaf{Literal: t.Literal[prev:]}})
+		}
+
+		replaceText(t, nodes)
+	}
+}
+
+// replaceText replaces t with nodes in its parent.
+func replaceText(t *ast.Text, nodes []ast.Node) {
+	parent := t.Parent
+	children := []ast.Node{}
+	for _, c := range parent.GetChildren() {
+		if c != t {
+			children = append(children, c)
+			continue
+		}
+		for _, n := range nodes {
+			n.SetParent(parent)
+		}
+		children = append(children, nodes...)
+	}
+	parent.SetChildren(children)
+}
@@ -0,0 +1,27 @@
+package mparser
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/render/xml"
+)
+
+func TestAddBCP14(t *testing.T) {
+	const doc = "# MUST Heading\n\nClients MUST NOT send this, servers SHOULD and **MAY** reply, a MUSTARD `MUST`.\n\n> Quoted MUST.\n"
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook}
+	d := markdown.Parse([]byte(doc), p)
+	AddBCP14(d)
+	out := markdown.Render(d, xml.NewRenderer(xml.RendererOptions{Flags: xml.CommonFlags | xml.XMLFragment}))
+
+	const want = "<t>Clients <bcp14>MUST NOT</bcp14> send this, servers <bcp14>SHOULD</bcp14> and <bcp14>MAY</bcp14> reply, a MUSTARD <tt>MUST</tt>.</t>"
+	if !bytes.Contains(out, []byte(want)) {
+		t.Errorf("expected %s in:\n%s", want, out)
+	}
+	if bytes.Count(out, []byte("<bcp14>")) != 3 {
+		t.Errorf("expected 3 key words, got:\n%s", out)
+	}
+}
@@ -3,8 +3,8 @@ package mparser
 import (
 	"bytes"
 	"encoding/xml"
-	"log"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/gomarkdown/markdown/ast"
@@ -12,16 +12,64 @@ import (
 	"github.com/mmarkdown/mmark/v2/mast/reference"
 )
 
+// Order is the order of the references in the bibliography.
+type Order int
+
+const (
+	OrderAnchor     Order = iota // sorted on anchor
+	OrderAppearance              // in order of first citation
+	OrderNumeric                 // RFCs first, sorted on number, then the other references sorted on anchor
+)
+
+// BibliographyOrder is the order of the references in the bibliography.
+var BibliographyOrder = OrderAnchor
+
+// sortAnchors sorts the anchors, which are in order of appearance, in order.
+func sortAnchors(anchors []string, order Order) []string {
+	keys := append([]string{}, anchors...)
+	switch order {
+	case OrderAppearance:
+	case OrderNumeric:
+		sort.SliceStable(keys, func(i, j int) bool {
+			ni, iok := number(keys[i])
+			nj, jok := number(keys[j])
+			switch {
+			case iok && jok:
+				return ni < nj
+			case iok != jok:
+				return iok
+			}
+			return keys[i] < keys[j]
+		})
+	default:
+		// sort on anchor, so it is stable when outputting the bibliography.
+		sort.Strings(keys)
+	}
+	return keys
+}
+
+// number returns the number of the RFC anchor.
+func number(anchor string) (int, bool) {
+	if !strings.HasPrefix(anchor, "RFC") {
+		return 0, false
+	}
+	n, err := strconv.Atoi(anchor[3:])
+	return n, err == nil
+}
+
 // CitationToBibliography walks the AST and gets all the citations from HTML blocks and groups them into
 // normative and informative references.
 func CitationToBibliography(doc ast.Node) (normative ast.Node, informative ast.Node) {
 	seen := map[string]*mast.BibliographyItem{}
 	raw := map[string][]byte{}
 	names := []string{} // names of the authors and contacts
+	order := []string{} // anchors in order of appearance
 
+	types := map[string]ast.CitationTypes{} // overrides from the title block
 	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
 		if t, ok := node.(*mast.Title); ok {
 			names = authContFromTitle(t)
+			types = referenceTypes(t)
 			return ast.Terminate
 		}
 		return ast.GoToNext
@@ -41,14 +89,20 @@ func CitationToBibliography(doc ast.Node) (normative ast.Node, informative ast.N
 					}
 
 				}
-				if _, ok := seen[string(bytes.ToLower(d))]; ok {
+				// An anchor cited more than once gets the strongest type: normative wins over
+				// informative, which wins over suppressed.
+				if ref, ok := seen[string(bytes.ToLower(d))]; ok {
+					if c.Type[i] > ref.Type {
+						ref.Type = c.Type[i]
+					}
 					continue Destination
 				}
 				ref := &mast.BibliographyItem{}
 				ref.Anchor = d
 				ref.Type = c.Type[i]
 
-				seen[string(d)] = ref
+				order = append(order, string(d))
+				seen[string(bytes.ToLower(d))] = ref
 			}
 		case *mast.ReferenceBlock:
 			anchor := anchorFromReference(c.Literal)
@@ -59,23 +113,25 @@ func CitationToBibliography(doc ast.Node) (normative ast.Node, informative ast.N
 		return ast.GoToNext
 	})
 
-	// sort on anchor, so it is stable when outputting the bibliography.
-	keys := make([]string, len(seen))
-	i := 0
-	for k := range seen {
-		keys[i] = k
-		i++
-	}
-	sort.Strings(keys)
+	keys := sortAnchors(order, BibliographyOrder)
 
 	for _, k := range keys {
-		r := seen[k]
-		// If we have a reference anchor and the raw XML add that here.
-		if rw, ok := raw[string(bytes.ToLower(r.Anchor))]; ok {
+		r := seen[strings.ToLower(k)]
+		if t, ok := types[strings.ToLower(k)]; ok {
+			r.Type = t
+		}
+		// If we have a reference anchor and the raw XML add that here. A versioned I-D
+		// citation (I-D.foo#03) uses the reference of the draft (I-D.foo).
+		rw, ok := raw[string(bytes.ToLower(r.Anchor))]
+		if !ok {
+			rw, ok = raw[strings.ToLower(withoutVersion(string(r.Anchor)))]
+		}
+		if ok && bytes.HasPrefix(rw, []byte("<referencegroup ")) {
+			r.ReferenceGroup = rw
+		} else if ok {
 			var x reference.Reference
 			if e := xml.Unmarshal(rw, &x); e != nil {
-				log.Printf("Failed to unmarshal reference: %q: %s, assuming <referencegroup>", r.Anchor, e)
-				r.ReferenceGroup = rw
+				r.ReferenceGroup = rw // ValidateReferences reports it.
 			} else {
 				r.Reference = &x
 			}
@@ -195,14 +251,11 @@ func fmtReference(data []byte) []byte {
 
 // AddBibliography adds the bibliography to the document. It will be
 // added just after the backmatter node. If that node can't be found this
-// function returns false and does nothing.
+// function returns false and does nothing, CheckReferences reports it.
 func AddBibliography(doc ast.Node) bool {
 	norm, inform := CitationToBibliography(doc)
 	where := NodeBackMatter(doc)
 	if where == nil {
-		if norm != nil || inform != nil {
-			log.Print("No {backmatter} found, can't insert bibliography")
-		}
 		return false
 	}
 
@@ -223,6 +276,24 @@ func AddBibliography(doc ast.Node) bool {
 	return (norm != nil) || (inform != nil)
 }
 
+// referenceTypes returns the types set in the title block's referenceTypes, keyed by the lowercased
+// anchor. Unknown types are skipped, CheckReferences reports them.
+func referenceTypes(t *mast.Title) map[string]ast.CitationTypes {
+	types := map[string]ast.CitationTypes{}
+	if t == nil || t.TitleData == nil {
+		return types
+	}
+	for anchor, typ := range t.ReferenceTypes {
+		switch strings.ToLower(typ) {
+		case "normative":
+			types[strings.ToLower(anchor)] = ast.CitationTypeNormative
+		case "informative":
+			types[strings.ToLower(anchor)] = ast.CitationTypeInformative
+		}
+	}
+	return types
+}
+
 // Authors FromTitle returns all the authors or contacts from the title block.
 func authContFromTitle(t *mast.Title) []string {
 	if t == nil {
@@ -1,6 +1,7 @@
 package mparser
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -35,3 +36,16 @@ func TestReferenceHook(t *testing.T) {
 		t.Errorf("want %d, got %d, for input %s...", len(ref), read, ref[:20])
 	}
 }
+
+func TestSortAnchors(t *testing.T) {
+	anchors := []string{"RFC8174", "pandoc", "RFC791", "BCP14", "RFC2119"}
+	for order, want := range map[Order]string{
+		OrderAnchor:     "BCP14 RFC2119 RFC791 RFC8174 pandoc",
+		OrderAppearance: "RFC8174 pandoc RFC791 BCP14 RFC2119",
+		OrderNumeric:    "RFC791 RFC2119 RFC8174 BCP14 pandoc",
+	} {
+		if got := strings.Join(sortAnchors(anchors, order), " "); got != want {
+			t.Errorf("expected %q for order %d, got %q", want, order, got)
+		}
+	}
+}
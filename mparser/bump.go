@@ -0,0 +1,67 @@
+package mparser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+var (
+	draftRevision = regexp.MustCompile(`^(draft-.+)-(\d\d)$`)
+	titleDate     = regexp.MustCompile(`(?m)^([ \t]*date[ \t]*[=:][ \t]*).*$`)
+)
+
+// Bump returns the document data with the revision of the Internet-Draft, the -NN at the end of its
+// name in the seriesInfo, incremented and the date in the title block set to date. A date of "today"
+// is left alone. The rest of the document, and the layout of the title block, isn't changed. It also
+// returns the old and the new name.
+func Bump(data []byte, date time.Time) (out []byte, old, name string, err error) {
+	node, _, end := titleHook(data, func(d Diagnostic) {
+		if err == nil {
+			err = errors.New(d.Message)
+		}
+	})
+	if err != nil {
+		return nil, "", "", err
+	}
+	title, ok := node.(*mast.Title)
+	if !ok {
+		return nil, "", "", errors.New("no title block")
+	}
+	for _, s := range append(title.SeriesInfos, title.SeriesInfo) {
+		if s.Name == "Internet-Draft" {
+			old = s.Value
+		}
+	}
+	m := draftRevision.FindStringSubmatch(old)
+	if m == nil {
+		return nil, "", "", fmt.Errorf("no Internet-Draft name with a revision (draft-...-NN) in the title block, got %q", old)
+	}
+	rev, _ := strconv.Atoi(m[2])
+	if rev == 99 {
+		return nil, "", "", fmt.Errorf("can't bump %q beyond -99", old)
+	}
+	name = fmt.Sprintf("%s-%02d", m[1], rev+1)
+
+	// the name is replaced where it is used in the block, i.e. in the seriesInfo and the docName.
+	block := regexp.MustCompile(regexp.QuoteMeta(old)+`\b`).ReplaceAll(data[:end], []byte(name))
+	isTOML := data[0] == '%'
+	block = titleDate.ReplaceAllFunc(block, func(line []byte) []byte {
+		m := titleDate.FindSubmatch(line)
+		if bytes.Contains(line[len(m[1]):], []byte("today")) {
+			return line
+		}
+		layout := "2006-01-02"
+		if isTOML {
+			layout = "2006-01-02T15:04:05Z"
+		}
+		return append(append([]byte{}, m[1]...), date.Format(layout)...)
+	})
+	out = append(block, data[end:]...)
+	return out, old, name, nil
+}
@@ -0,0 +1,41 @@
+package mparser
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBump(t *testing.T) {
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in, out string
+	}{
+		{
+			"%%%\ntitle = \"A\"\ndate = 2023-01-02T00:00:00Z\n\n[seriesInfo]\nname = \"Internet-Draft\"\nvalue = \"draft-ietf-wg-a-09\"\n%%%\n\nSee draft-ietf-wg-a-09.\n",
+			"%%%\ntitle = \"A\"\ndate = 2024-03-01T00:00:00Z\n\n[seriesInfo]\nname = \"Internet-Draft\"\nvalue = \"draft-ietf-wg-a-10\"\n%%%\n\nSee draft-ietf-wg-a-09.\n",
+		},
+		{
+			"---\ntitle: A\ndate: 2023-01-02\nseriesinfo:\n  name: Internet-Draft\n  value: draft-gieben-a-00\n---\n\n# A\n",
+			"---\ntitle: A\ndate: 2024-03-01\nseriesinfo:\n  name: Internet-Draft\n  value: draft-gieben-a-01\n---\n\n# A\n",
+		},
+		{
+			"%%%\ntitle = \"A\"\ndate = \"today\"\n[seriesInfo]\nname = \"Internet-Draft\"\nvalue = \"draft-a-b-01\"\n%%%\n",
+			"%%%\ntitle = \"A\"\ndate = \"today\"\n[seriesInfo]\nname = \"Internet-Draft\"\nvalue = \"draft-a-b-02\"\n%%%\n",
+		},
+	}
+	for i, tc := range tests {
+		out, _, _, err := Bump([]byte(tc.in), date)
+		if err != nil {
+			t.Errorf("test %d: %s", i, err)
+			continue
+		}
+		if string(out) != tc.out {
+			t.Errorf("test %d: expected\n%s\ngot\n%s", i, tc.out, out)
+		}
+	}
+
+	if _, _, _, err := Bump([]byte("%%%\ntitle = \"A\"\n%%%\n"), date); err == nil || !strings.Contains(err.Error(), "revision") {
+		t.Errorf("expected an error for a missing name, got %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package mparser
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+var legendMarker = regexp.MustCompile(`^<(\d+)>$`)
+
+// AddCallouts turns the legend of the callouts in a code block into an ordered list. A callout is <N>
+// at the end of a line in the code block, the legend is a paragraph directly after the block, or
+// after the figure it's in, where each line starts with <N>, numbered from 1:
+//
+//	~~~ go
+//	x := 1 // <1>
+//	~~~
+//	<1> Declares x.
+//
+// The list has mast.CalloutDelimiter as its delimiter, the class "callouts" and the anchor
+// "callouts-K", where K counts the legends in the document, to let the renderers link the callouts to
+// the legend.
+func AddCallouts(doc ast.Node) {
+	k := 0
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		codeBlock, ok := node.(*ast.CodeBlock)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		max := 0
+		for _, line := range bytes.Split(codeBlock.Literal, []byte("\n")) {
+			ids, _ := mast.CalloutMarkers(line)
+			for _, id := range ids {
+				if n, _ := strconv.Atoi(id); n > max {
+					max = n
+				}
+			}
+		}
+		if max == 0 {
+			return ast.GoToNext
+		}
+
+		var block ast.Node = codeBlock
+		if _, ok := codeBlock.Parent.(*ast.CaptionFigure); ok {
+			block = codeBlock.Parent
+		}
+		para, ok := ast.GetNextNode(block).(*ast.Paragraph)
+		if !ok {
+			return ast.GoToNext
+		}
+		items := legend(para)
+		if len(items) < max {
+			return ast.GoToNext
+		}
+
+		k++
+		list := &ast.List{ListFlags: ast.ListTypeOrdered, Tight: true, Delimiter: mast.CalloutDelimiter}
+		list.Attribute = &ast.Attribute{ID: []byte("callouts-" + strconv.Itoa(k)), Classes: [][]byte{[]byte("callouts")}, Attrs: map[string][]byte{}}
+		for _, item := range items {
+			li := &ast.ListItem{ListFlags: ast.ListTypeOrdered, Tight: true, Delimiter: mast.CalloutDelimiter}
+			p := &ast.Paragraph{}
+			for _, n := range item {
+				ast.AppendChild(p, n)
+			}
+			ast.AppendChild(li, p)
+			ast.AppendChild(list, li)
+		}
+
+		parent := para.Parent
+		children := parent.GetChildren()
+		for i := range children {
+			if children[i] == para {
+				children[i] = list
+				list.Parent = parent
+			}
+		}
+		return ast.GoToNext
+	})
+}
+
+// legend splits the inline nodes of para into the items of a legend: each line must start with <N>,
+// which the parser sees as HTML, and N must count from 1. It returns nil if para isn't a legend.
+func legend(para *ast.Paragraph) [][]ast.Node {
+	items := [][]ast.Node{}
+	lineStart := true
+	for _, n := range para.GetChildren() {
+		if span, ok := n.(*ast.HTMLSpan); ok && lineStart {
+			m := legendMarker.FindSubmatch(span.Literal)
+			if m == nil || string(m[1]) != strconv.Itoa(len(items)+1) {
+				return nil
+			}
+			items = append(items, []ast.Node{})
+			continue
+		}
+		text, isText := n.(*ast.Text)
+		if isText && len(text.Literal) == 0 {
+			continue
+		}
+		if len(items) == 0 {
+			return nil // text before <1>
+		}
+		lineStart = isText && bytes.HasSuffix(text.Literal, []byte("\n"))
+		items[len(items)-1] = append(items[len(items)-1], n)
+	}
+
+	for _, item := range items {
+		if len(item) == 0 {
+			continue
+		}
+		if text, ok := item[0].(*ast.Text); ok {
+			text.Literal = bytes.TrimLeft(text.Literal, " \t")
+		}
+		if text, ok := item[len(item)-1].(*ast.Text); ok {
+			text.Literal = bytes.TrimRight(text.Literal, "\n")
+		}
+		for _, n := range item {
+			n.SetParent(nil)
+		}
+	}
+	return items
+}
@@ -0,0 +1,55 @@
+package mparser
+
+import (
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+const calloutDoc = "~~~ go\nx := 1 // <1>\ny := 2 <2>\n~~~\n<1> Declares `x`.\n<2> Declares y\n  over two lines.\n\n" +
+	"~~~\nz := 3 <1>\n~~~\n<2> Out of order.\n"
+
+func TestAddCallouts(t *testing.T) {
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook}
+	doc := markdown.Parse([]byte(calloutDoc), p)
+	AddCallouts(doc)
+
+	children := doc.GetChildren()
+	list, ok := children[1].(*ast.List)
+	if !ok {
+		t.Fatalf("expected a legend after the first code block, got %T", children[1])
+	}
+	if list.Delimiter != mast.CalloutDelimiter || string(list.Attribute.ID) != "callouts-1" || len(list.Children) != 2 {
+		t.Fatalf("unexpected legend: %+v", list)
+	}
+	if l := mast.CalloutLegend(children[0].(*ast.CodeBlock)); l != list {
+		t.Errorf("expected CalloutLegend to return the legend, got %v", l)
+	}
+
+	first := list.Children[0].GetChildren()[0].GetChildren()
+	if len(first) != 3 || string(first[0].AsLeaf().Literal) != "Declares " || string(first[2].AsLeaf().Literal) != "." {
+		t.Errorf("unexpected first item: %v", first)
+	}
+	second := list.Children[1].GetChildren()[0].GetChildren()
+	if len(second) != 1 || string(second[0].AsLeaf().Literal) != "Declares y\n  over two lines." {
+		t.Errorf("unexpected second item: %v", second)
+	}
+
+	if _, ok := children[3].(*ast.Paragraph); !ok {
+		t.Errorf("expected the legend that is out of order to stay a paragraph, got %T", children[3])
+	}
+}
+
+func TestCalloutMarkers(t *testing.T) {
+	ids, start := mast.CalloutMarkers([]byte("x := 1 // <1> <2>"))
+	if len(ids) != 2 || ids[0] != "1" || ids[1] != "2" || start != 9 {
+		t.Errorf("expected callouts 1 and 2 at 9, got %v at %d", ids, start)
+	}
+	if ids, start := mast.CalloutMarkers([]byte("vector<1> x")); ids != nil || start != -1 {
+		t.Errorf("expected no callouts, got %v at %d", ids, start)
+	}
+}
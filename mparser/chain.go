@@ -0,0 +1,112 @@
+package mparser
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// chain keeps track of the files that are being included, so a failing include can be reported with
+// the chain of includes that lead to it, and include cycles are detected. The parser doesn't tell us
+// when it's done with an included file, an included file is assumed to be done when the next include
+// isn't in it.
+type chain struct {
+	files  []*included // files[0] is the document itself
+	failed bool
+}
+
+// included is a file on the chain.
+type included struct {
+	name   string // name used in messages
+	path   string // full path, URL or git:rev:path
+	dir    string // the from of the includes in this file, as the parser's include stack has it
+	data   []byte
+	cursor int // offset in data after the last include we have seen
+	line   int // line of the last include we have seen
+}
+
+func newChain(name, path string) *chain {
+	return &chain{files: []*included{{name: name, path: path}}}
+}
+
+// enter finds the file that includes file from the directory from, and pops the files that are done.
+// It returns false when file is already on the chain, which would be an include cycle.
+func (c *chain) enter(from, file, path string) bool {
+	directive := []byte("{{" + file + "}}")
+	for len(c.files) > 1 {
+		top := c.files[len(c.files)-1]
+		if top.dir == from && bytes.Contains(top.data[top.cursor:], directive) {
+			break
+		}
+		c.files = c.files[:len(c.files)-1]
+	}
+	top := c.files[len(c.files)-1]
+	if i := bytes.Index(top.data[top.cursor:], directive); i >= 0 {
+		top.line = bytes.Count(top.data[:top.cursor+i], []byte("\n")) + 1
+		top.cursor += i + len(directive)
+	} else {
+		top.line = 0
+	}
+	for _, f := range c.files {
+		if f.path == path {
+			return false
+		}
+	}
+	return true
+}
+
+// push adds the file that was just read to the chain.
+func (c *chain) push(from, file, path string, data []byte) {
+	dir := filepath.Join(from, file)
+	if filepath.IsAbs(file) {
+		dir = file
+	}
+	c.files = append(c.files, &included{name: displayName(from, file), path: path, dir: filepath.Dir(dir), data: data})
+}
+
+// String returns the chain as main.md:5 → chapters/a.md:3.
+func (c *chain) String() string {
+	s := make([]string, len(c.files))
+	for i, f := range c.files {
+		s[i] = f.name
+		if f.line > 0 {
+			s[i] += ":" + strconv.Itoa(f.line)
+		}
+	}
+	return strings.Join(s, " → ")
+}
+
+// displayName returns the name of the file included from from, as used in messages.
+func displayName(from, file string) string {
+	if _, ok := remote(from, file); ok || path.IsAbs(file) || strings.HasPrefix(file, GitPrefix) {
+		return file
+	}
+	return filepath.Join(from, file)
+}
+
+// failf reports the failure of an include, with the chain of includes that lead to it, and records that
+// an include failed, see Failed. The line of the diagnostic is the line of the include in the document,
+// the rest of the chain is in the message.
+func (i Initial) failf(format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	if i.chain == nil {
+		i.Report.Report(Diagnostic{Message: msg, Check: "include", Severity: SeverityError})
+		return
+	}
+	i.chain.failed = true
+	if i.Report == nil {
+		log.Printf("%s: %s", i.chain, msg)
+		return
+	}
+	if len(i.chain.files) > 1 {
+		msg = (&chain{files: i.chain.files[1:]}).String() + ": " + msg
+	}
+	i.Report(Diagnostic{Line: i.chain.files[0].line, Message: msg, Check: "include", Severity: SeverityError})
+}
+
+// Failed returns true if an include failed.
+func (i Initial) Failed() bool { return i.chain != nil && i.chain.failed }
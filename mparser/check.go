@@ -0,0 +1,296 @@
+package mparser
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/mast/reference"
+)
+
+// Diagnostic is a problem found in the document.
+type Diagnostic = mast.Diagnostic
+
+// Severity is the severity of a Diagnostic.
+type Severity = mast.Severity
+
+const (
+	SeverityWarning = mast.SeverityWarning // The output is made, but may not be what was intended.
+	SeverityError   = mast.SeverityError   // The output is incomplete or invalid.
+)
+
+// Reporter reports the diagnostics found by code that can't return them.
+type Reporter = mast.Reporter
+
+// CheckReferences checks the references of doc and returns a diagnostic for each XML reference in the
+// document that is never cited, and for each citation that has no reference and can't be resolved by
+// xml2rfc either (as it can for RFCs, I-Ds, BCPs, STDs, W3C, 3GPP and IEEE documents). The line
+// numbers are found by searching source, the markdown of doc. References that don't come from the
+// source, i.e. fetched ones and the ones from CSL-JSON files, aren't reported as unused. Unknown types
+// in the title block's referenceTypes and citations in a document without a {backmatter}, where the
+// bibliography goes, are reported too.
+func CheckReferences(doc ast.Node, source []byte) []Diagnostic {
+	names := []string{}
+	overrides := map[string]ast.CitationTypes{}
+	normative := []string{}
+	isNormative := map[string]bool{}
+	cited := map[string]bool{}
+	citations := []string{}
+	refs := map[string]bool{}
+	references := []string{}
+	unknown := map[string]string{} // anchors in referenceTypes with an unknown type, to the type.
+
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		switch n := node.(type) {
+		case *mast.Title:
+			names = authContFromTitle(n)
+			overrides = referenceTypes(n)
+			if n.TitleData != nil {
+				for anchor, typ := range n.ReferenceTypes {
+					if _, ok := overrides[strings.ToLower(anchor)]; !ok {
+						unknown[anchor] = typ
+					}
+				}
+			}
+		case *ast.Citation:
+			for i, d := range n.Destination {
+				a := strings.ToLower(string(d))
+				if n.Type[i] == ast.CitationTypeNormative && !isNormative[a] {
+					normative = append(normative, string(d))
+					isNormative[a] = true
+				}
+				if !cited[a] {
+					citations = append(citations, string(d))
+				}
+				cited[a] = true
+				cited[strings.ToLower(withoutVersion(a))] = true
+			}
+		case *mast.ReferenceBlock:
+			if anchor := anchorFromReference(n.Literal); anchor != nil {
+				refs[string(bytes.ToLower(anchor))] = true
+				references = append(references, string(anchor))
+			}
+		}
+		return ast.GoToNext
+	})
+
+	diags := []Diagnostic{}
+	for _, r := range references {
+		if cited[strings.ToLower(r)] {
+			continue
+		}
+		line := lineOf(source, `anchor=["']`+regexp.QuoteMeta(r)+`["']`)
+		if line == 0 {
+			continue // not from the document
+		}
+		diags = append(diags, Diagnostic{Line: line, Message: fmt.Sprintf("reference %q is never cited", r)})
+	}
+
+	bibliography := ""
+Citations:
+	for _, c := range citations {
+		for _, n := range names {
+			if strings.EqualFold(n, c) {
+				continue Citations
+			}
+		}
+		if bibliography == "" {
+			bibliography = c
+		}
+		if refs[strings.ToLower(c)] || refs[strings.ToLower(withoutVersion(c))] {
+			continue
+		}
+		if bibPath(c) != "" {
+			continue
+		}
+		line := lineOf(source, `@[!?-]?`+regexp.QuoteMeta(c)+`\b`)
+		diags = append(diags, Diagnostic{Line: line, Message: fmt.Sprintf("citation %q has no reference", c)})
+	}
+
+	if bibliography != "" && NodeBackMatter(doc) == nil {
+		line := lineOf(source, `@[!?-]?`+regexp.QuoteMeta(bibliography)+`\b`)
+		diags = append(diags, Diagnostic{Line: line, Message: "no {backmatter} found, can't insert the bibliography"})
+	}
+
+	anchors := make([]string, 0, len(unknown))
+	for anchor := range unknown {
+		anchors = append(anchors, anchor)
+	}
+	sort.Strings(anchors)
+	for _, anchor := range anchors {
+		line := lineOf(source, `(?m)^\s*["']?`+regexp.QuoteMeta(anchor)+`["']?\s*[=:]`)
+		diags = append(diags, Diagnostic{Line: line, Message: fmt.Sprintf("unknown reference type %q for %q, use \"normative\" or \"informative\"",
+			unknown[anchor], anchor)})
+	}
+
+	// [@X] is informative as well, only the explicit [@?X] conflicts with [@!X].
+	for _, c := range normative {
+		if _, ok := overrides[strings.ToLower(c)]; ok {
+			continue
+		}
+		inform := linesOf(source, `@\?`+regexp.QuoteMeta(c)+`\b`)
+		if len(inform) == 0 {
+			continue
+		}
+		norm := linesOf(source, `@!`+regexp.QuoteMeta(c)+`\b`)
+		diags = append(diags, Diagnostic{Line: inform[0], Message: fmt.Sprintf("citation %q is normative (%s) and informative (%s), using normative",
+			c, lines(norm), lines(inform))})
+	}
+
+	sort.SliceStable(diags, func(i, j int) bool { return diags[i].Line < diags[j].Line })
+	return diags
+}
+
+// CheckImages returns a diagnostic for each image in doc without alt text. The line numbers are found
+// by searching source, the markdown of doc.
+func CheckImages(doc ast.Node, source []byte) []Diagnostic {
+	diags := []Diagnostic{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		img, ok := node.(*ast.Image)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		if strings.TrimSpace(plainText(img)) == "" {
+			line := lineOf(source, `!\[[^\]]*\]\(`+regexp.QuoteMeta(string(img.Destination)))
+			diags = append(diags, Diagnostic{Line: line, Message: fmt.Sprintf("image %q has no alt text", img.Destination)})
+		}
+		return ast.SkipChildren
+	})
+	return diags
+}
+
+// CheckLineLength returns a diagnostic for each line in a code block of doc that is longer than width
+// characters. The line numbers are found by searching source, the markdown of doc.
+func CheckLineLength(doc ast.Node, source []byte, width int) []Diagnostic {
+	diags := []Diagnostic{}
+	from := 0 // search after the previous long line, so repeated lines get their own line number
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		code, ok := node.(*ast.CodeBlock)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		for _, l := range strings.Split(string(code.Literal), "\n") {
+			n := utf8.RuneCountInString(l)
+			if n <= width {
+				continue
+			}
+			line := 0
+			if i := bytes.Index(source[from:], []byte(l)); i >= 0 {
+				from += i
+				line = bytes.Count(source[:from], []byte("\n")) + 1
+				from += len(l)
+			}
+			diags = append(diags, Diagnostic{Line: line, Column: width + 1, Message: fmt.Sprintf("code line is %d characters, longer than %d", n, width)})
+		}
+		return ast.GoToNext
+	})
+	return diags
+}
+
+// linesOf returns the lines of all matches of the regular expression expr in source.
+func linesOf(source []byte, expr string) []int {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil
+	}
+	lines := []int{}
+	for _, loc := range re.FindAllIndex(source, -1) {
+		lines = append(lines, bytes.Count(source[:loc[0]], []byte("\n"))+1)
+	}
+	return lines
+}
+
+// lines returns "line 3" or "lines 3, 5".
+func lines(l []int) string {
+	s := make([]string, len(l))
+	for i := range l {
+		s[i] = strconv.Itoa(l[i])
+	}
+	if len(l) == 1 {
+		return "line " + s[0]
+	}
+	return "lines " + strings.Join(s, ", ")
+}
+
+// lineOf returns the line of the first match of the regular expression expr in source, or 0.
+func lineOf(source []byte, expr string) int {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return 0
+	}
+	loc := re.FindIndex(source)
+	if loc == nil {
+		return 0
+	}
+	return bytes.Count(source[:loc[0]], []byte("\n")) + 1
+}
+
+// ValidateReferences returns a diagnostic for each XML reference in doc that isn't well-formed or
+// lacks an anchor, a title or a date, which RFC 7991 requires. References in a <referencegroup> are
+// checked as well. The line numbers are found by searching source, the markdown of doc.
+func ValidateReferences(doc ast.Node, source []byte) []Diagnostic {
+	diags := []Diagnostic{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		n, ok := node.(*mast.ReferenceBlock)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		line := 0
+		anchor := anchorFromReference(n.Literal)
+		if anchor != nil {
+			line = lineOf(source, `anchor=["']`+regexp.QuoteMeta(string(anchor))+`["']`)
+		}
+		if line == 0 {
+			if i := bytes.Index(source, n.Literal); i >= 0 {
+				line = bytes.Count(source[:i], []byte("\n")) + 1
+			}
+		}
+
+		refs := []reference.Reference{}
+		var err error
+		if bytes.HasPrefix(n.Literal, []byte("<referencegroup ")) {
+			g := reference.Group{}
+			err = xml.Unmarshal(n.Literal, &g)
+			if err == nil && g.Anchor == "" {
+				diags = append(diags, Diagnostic{Line: line, Message: "reference group has no anchor"})
+			}
+			refs = g.References
+		} else {
+			ref := reference.Reference{}
+			err = xml.Unmarshal(n.Literal, &ref)
+			refs = append(refs, ref)
+		}
+		if err != nil {
+			if serr, ok := err.(*xml.SyntaxError); ok && line > 0 {
+				diags = append(diags, Diagnostic{Line: line + serr.Line - 1, Message: fmt.Sprintf("malformed reference: %s", serr.Msg)})
+			} else {
+				diags = append(diags, Diagnostic{Line: line, Message: fmt.Sprintf("malformed reference: %s", err)})
+			}
+			return ast.GoToNext
+		}
+
+		for _, ref := range refs {
+			name := fmt.Sprintf("reference %q", ref.Anchor)
+			if ref.Anchor == "" {
+				diags = append(diags, Diagnostic{Line: line, Message: "reference has no anchor"})
+				name = "reference"
+			}
+			if strings.TrimSpace(ref.Front.Title) == "" {
+				diags = append(diags, Diagnostic{Line: line, Message: name + " has no title"})
+			}
+			if ref.Front.Date == nil {
+				diags = append(diags, Diagnostic{Line: line, Message: name + " has no date"})
+			}
+		}
+		return ast.GoToNext
+	})
+	sort.SliceStable(diags, func(i, j int) bool { return diags[i].Line < diags[j].Line })
+	return diags
+}
@@ -0,0 +1,118 @@
+package mparser
+
+import (
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+func TestCheckReferences(t *testing.T) {
+	doc := []byte(`%%%
+title = "Check"
+[[author]]
+fullname = "Jane Doe"
+%%%
+
+See [@pandoc], [@Jane Doe] and [@missing] and [@RFC2119].
+
+{backmatter}
+
+<reference anchor='pandoc' target='http://example.org'>
+<front><title>Pandoc</title></front>
+</reference>
+
+<reference anchor='unused' target='http://example.org'>
+<front><title>Unused</title></front>
+</reference>
+`)
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook}
+	d := markdown.Parse(doc, p)
+
+	diags := CheckReferences(d, doc)
+	want := []string{`7: citation "missing" has no reference`, `15: reference "unused" is never cited`}
+	if len(diags) != len(want) {
+		t.Fatalf("expected %d diagnostics, got %v", len(want), diags)
+	}
+	for i := range want {
+		if diags[i].String() != want[i] {
+			t.Errorf("expected %q, got %q", want[i], diags[i])
+		}
+	}
+}
+
+func TestValidateReferences(t *testing.T) {
+	doc := []byte(`See [@good], [@notitle] and [@broken].
+
+{backmatter}
+
+<reference anchor='good' target='http://example.org'>
+<front><title>Good</title><date year='2020'/></front>
+</reference>
+
+<reference anchor='notitle' target='http://example.org'>
+<front><title></title></front>
+</reference>
+
+<reference anchor='broken' target='http://example.org'>
+<front>
+<title>Broken</titel>
+</front>
+</reference>
+`)
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook}
+	d := markdown.Parse(doc, p)
+
+	diags := ValidateReferences(d, doc)
+	want := []string{
+		`9: reference "notitle" has no title`,
+		`9: reference "notitle" has no date`,
+		`15: malformed reference: element <title> closed by </titel>`,
+	}
+	if len(diags) != len(want) {
+		t.Fatalf("expected %d diagnostics, got %v", len(want), diags)
+	}
+	for i := range want {
+		if diags[i].String() != want[i] {
+			t.Errorf("expected %q, got %q", want[i], diags[i])
+		}
+	}
+}
+
+func TestCheckReferencesConflict(t *testing.T) {
+	doc := []byte(`See [@!RFC2119] and [@RFC2119].
+
+Also [@?RFC2119] and [@?RFC8174], [@!RFC8174].
+
+{backmatter}
+`)
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook}
+	d := markdown.Parse(doc, p)
+
+	diags := CheckReferences(d, doc)
+	want := `3: citation "RFC2119" is normative (line 1) and informative (line 3), using normative`
+	if len(diags) != 2 || diags[0].String() != want {
+		t.Fatalf("expected %q, got %v", want, diags)
+	}
+
+	norm, inform := CitationToBibliography(d)
+	if inform != nil || len(norm.GetChildren()) != 2 {
+		t.Errorf("expected all references to be normative")
+	}
+
+	// With an override there is no warning, and the override is used.
+	doc = append([]byte("%%%\ntitle = \"x\"\n[referenceTypes]\nRFC2119 = \"informative\"\nRFC8174 = \"informative\"\n%%%\n"), doc...)
+	p = parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook}
+	d = markdown.Parse(doc, p)
+	if diags := CheckReferences(d, doc); len(diags) != 0 {
+		t.Errorf("expected no diagnostics, got %v", diags)
+	}
+	norm, inform = CitationToBibliography(d)
+	if norm != nil || len(inform.GetChildren()) != 2 {
+		t.Errorf("expected all references to be informative")
+	}
+}
@@ -0,0 +1,92 @@
+package mparser
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+var commentSource = regexp.MustCompile(`^([^\s:]+): `)
+
+// StripComments removes the HTML comments, <!-- ... -->, from doc, both the block level ones and those
+// in the text.
+func StripComments(doc ast.Node) {
+	for _, c := range comments(doc) {
+		parent := c.GetParent()
+		// don't leave two spaces where an inline comment was.
+		prev, ok1 := ast.GetPrevNode(c).(*ast.Text)
+		next, ok2 := ast.GetNextNode(c).(*ast.Text)
+		if ok1 && ok2 && bytes.HasSuffix(prev.Literal, []byte(" ")) && bytes.HasPrefix(next.Literal, []byte(" ")) {
+			prev.Literal = bytes.TrimRight(prev.Literal, " ")
+		}
+		ast.RemoveFromTree(c)
+		if p, ok := parent.(*ast.Paragraph); ok && emptyParagraph(p) {
+			ast.RemoveFromTree(p)
+		}
+	}
+}
+
+// AddCommentCrefs replaces the HTML comments in doc with mast.Cref nodes, so they show up as editorial
+// comments. A comment that starts with "name: " gets name as its source, like [^name: text] does.
+func AddCommentCrefs(doc ast.Node) {
+	for _, c := range comments(doc) {
+		text, _ := comment(c.AsLeaf().Literal)
+		cref := &mast.Cref{}
+		if m := commentSource.FindSubmatch(text); m != nil {
+			cref.Source = string(m[1])
+			text = text[len(m[0]):]
+		}
+		cref.Literal = text
+		if _, ok := c.(*ast.HTMLBlock); ok {
+			para := &ast.Paragraph{}
+			ast.AppendChild(para, cref)
+			replace(c, para)
+			continue
+		}
+		replace(c, cref)
+	}
+}
+
+// comments returns the HTML blocks and spans in doc that are a comment.
+func comments(doc ast.Node) []ast.Node {
+	nodes := []ast.Node{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch n := node.(type) {
+		case *ast.HTMLBlock, *ast.HTMLSpan:
+			if _, ok := comment(n.AsLeaf().Literal); ok {
+				nodes = append(nodes, n)
+			}
+		}
+		return ast.GoToNext
+	})
+	return nodes
+}
+
+// comment returns the trimmed text of the HTML comment in data, and true if data is a single comment.
+func comment(data []byte) ([]byte, bool) {
+	data = bytes.TrimSpace(data)
+	if !bytes.HasPrefix(data, []byte("<!--")) || !bytes.HasSuffix(data, []byte("-->")) || len(data) < 7 {
+		return nil, false
+	}
+	text := data[4 : len(data)-3]
+	if bytes.Contains(text, []byte("-->")) {
+		return nil, false
+	}
+	return bytes.TrimSpace(text), true
+}
+
+// emptyParagraph returns true if p only holds whitespace.
+func emptyParagraph(p *ast.Paragraph) bool {
+	for _, c := range p.GetChildren() {
+		t, ok := c.(*ast.Text)
+		if !ok || len(bytes.TrimSpace(t.Literal)) > 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,36 @@
+package mparser
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/render/xml"
+)
+
+func TestComments(t *testing.T) {
+	const doc = "Some text <!-- ekr: check this number --> here.\n\n<!--\nThis section\nis wrong.\n-->\n\nEnd.\n"
+	tests := []struct {
+		cref bool
+		want []string
+	}{
+		{false, []string{"<t>Some text here.</t>\n<t>End.</t>"}},
+		{true, []string{"<t>Some text <cref source=\"ekr\">check this number</cref> here.</t>", "<t><cref>This section\nis wrong.</cref></t>"}},
+	}
+	for _, tc := range tests {
+		p := parser.NewWithExtensions(Extensions)
+		d := markdown.Parse([]byte(doc), p)
+		if tc.cref {
+			AddCommentCrefs(d)
+		} else {
+			StripComments(d)
+		}
+		out := markdown.Render(d, xml.NewRenderer(xml.RendererOptions{Flags: xml.CommonFlags | xml.XMLFragment}))
+		for _, w := range tc.want {
+			if !bytes.Contains(out, []byte(w)) {
+				t.Errorf("expected %s in:\n%s", w, out)
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package mparser
+
+import (
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// AddContributors adds a Contributors section to the end of the document, when the title block has
+// contributors, or authors with a contribution. The section starts with a paragraph that cites each
+// contributor, which renders them as a contact, followed by a paragraph for each contribution. If
+// the document already has a Contributors section, or there are no contributors, this returns false
+// and nothing is added.
+func AddContributors(doc ast.Node) bool {
+	var t *mast.Title
+	exists := false
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		switch n := node.(type) {
+		case *mast.Title:
+			t = n
+		case *ast.Heading:
+			if strings.EqualFold(strings.TrimSpace(string(headingText(n))), "contributors") {
+				exists = true
+				return ast.Terminate
+			}
+		}
+		return ast.GoToNext
+	})
+	if t == nil || t.TitleData == nil || exists {
+		return false
+	}
+
+	people := []mast.Author{}
+	contacts := &ast.Paragraph{}
+	for _, c := range t.Contact {
+		if !c.Contributor {
+			continue
+		}
+		people = append(people, mast.Author(c))
+		cite := &ast.Citation{Destination: [][]byte{[]byte(c.Fullname)}, Type: []ast.CitationTypes{ast.CitationTypeInformative}, Suffix: [][]byte{nil}}
+		ast.AppendChild(contacts, cite)
+	}
+	people = append(t.Author, people...)
+
+	notes := []ast.Node{}
+	for _, a := range people {
+		if a.Contribution == "" {
+			continue
+		}
+		who := a.Fullname
+		if a.Role != "" {
+			who += " (" + a.Role + ")"
+		}
+		para := &ast.Paragraph{}
+		ast.AppendChild(para, &ast.Text{Leaf: ast.Leaf{Literal: []byte(who + ": " + a.Contribution)}})
+		notes = append(notes, para)
+	}
+	if len(contacts.Children) == 0 && len(notes) == 0 {
+		return false
+	}
+
+	heading := &ast.Heading{Level: 1, HeadingID: "contributors"}
+	heading.Attribute = &ast.Attribute{Attrs: map[string][]byte{"numbered": []byte("false")}}
+	ast.AppendChild(heading, &ast.Text{Leaf: ast.Leaf{Literal: []byte("Contributors")}})
+	ast.AppendChild(doc, heading)
+	if len(contacts.Children) > 0 {
+		ast.AppendChild(doc, contacts)
+	}
+	for _, n := range notes {
+		ast.AppendChild(doc, n)
+	}
+	return true
+}
+
+// headingText returns the text of the heading h.
+func headingText(h *ast.Heading) []byte {
+	text := []byte{}
+	for _, c := range h.Children {
+		if l := c.AsLeaf(); l != nil {
+			text = append(text, l.Literal...)
+		}
+	}
+	return text
+}
@@ -0,0 +1,59 @@
+package mparser
+
+import (
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+const contributorsDoc = `%%%
+title = "Test"
+[[author]]
+fullname = "Jane Doe"
+role = "editor"
+contribution = "Wrote the first version."
+[[author]]
+fullname = "John Roe"
+contributor = true
+%%%
+
+# Intro
+
+Hi.
+`
+
+func TestAddContributors(t *testing.T) {
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook}
+	doc := markdown.Parse([]byte(contributorsDoc), p)
+
+	title := doc.GetChildren()[0].(*mast.Title)
+	if len(title.Author) != 1 || len(title.Contact) != 1 || title.Contact[0].Fullname != "John Roe" {
+		t.Fatalf("expected the contributor to be moved to the contacts, got %v and %v", title.Author, title.Contact)
+	}
+
+	if !AddContributors(doc) {
+		t.Fatal("expected a Contributors section to be added")
+	}
+	children := doc.GetChildren()
+	if len(children) < 3 {
+		t.Fatalf("expected heading and paragraphs, got %d children", len(children))
+	}
+	children = children[len(children)-3:]
+	if h, ok := children[0].(*ast.Heading); !ok || h.HeadingID != "contributors" {
+		t.Errorf("expected Contributors heading, got %T", children[0])
+	}
+	if c, ok := children[1].GetChildren()[0].(*ast.Citation); !ok || string(c.Destination[0]) != "John Roe" {
+		t.Errorf("expected a citation of the contributor, got %T", children[1].GetChildren()[0])
+	}
+	if text := string(children[2].GetChildren()[0].AsLeaf().Literal); text != "Jane Doe (editor): Wrote the first version." {
+		t.Errorf("expected the contribution, got %q", text)
+	}
+
+	if AddContributors(doc) {
+		t.Error("expected no second Contributors section")
+	}
+}
@@ -0,0 +1,90 @@
+package mparser
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+var crefSyntax = regexp.MustCompile(`[ \t]?\[\^([^\]\s:]+): ([^\]]+)\]`)
+
+// AddCrefs replaces the editorial comments in doc with mast.Cref nodes. An inline comment is written as
+// [^source: text], a block comment as a fenced code block with the language "cref" and an optional
+// source attribute. If final is true the comments are removed instead.
+func AddCrefs(doc ast.Node, final bool) {
+	texts := []*ast.Text{}
+	blocks := []*ast.CodeBlock{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch n := node.(type) {
+		case *ast.Text:
+			if crefSyntax.Match(n.Literal) {
+				texts = append(texts, n)
+			}
+		case *ast.CodeBlock:
+			if f := bytes.Fields(n.Info); len(f) > 0 && string(f[0]) == "cref" {
+				blocks = append(blocks, n)
+			}
+		}
+		return ast.GoToNext
+	})
+
+	for _, t := range texts {
+		if final {
+			t.Literal = crefSyntax.ReplaceAll(t.Literal, nil)
+			continue
+		}
+		nodes := []ast.Node{}
+		prev := 0
+		for _, m := range crefSyntax.FindAllSubmatchIndex(t.Literal, -1) {
+			// keep the space before the comment.
+			start := m[0]
+			if t.Literal[start] == ' ' || t.Literal[start] == '\t' {
+				start++
+			}
+			if start > prev {
+				nodes = append(nodes, &ast.Text{Leaf: ast.Leaf{Literal: t.Literal[prev:start]}})
+			}
+			cref := &mast.Cref{Source: string(t.Literal[m[2]:m[3]])}
+			cref.Literal = t.Literal[m[4]:m[5]]
+			nodes = append(nodes, cref)
+			prev = m[1]
+		}
+		if prev < len(t.Literal) {
+			nodes = append(nodes, &ast.Text{Leaf: ast.Leaf{Literal: t.Literal[prev:]}})
+		}
+		replaceText(t, nodes)
+	}
+
+	for _, b := range blocks {
+		if final {
+			ast.RemoveFromTree(b)
+			continue
+		}
+		cref := &mast.Cref{}
+		cref.Literal = bytes.TrimSpace(b.Literal)
+		if a := b.Attribute; a != nil {
+			cref.Source = string(a.Attrs["source"])
+		}
+		para := &ast.Paragraph{}
+		ast.AppendChild(para, cref)
+		replace(b, para)
+	}
+}
+
+// replace replaces node with n in its parent.
+func replace(node, n ast.Node) {
+	parent := node.GetParent()
+	children := parent.GetChildren()
+	for i := range children {
+		if children[i] == node {
+			children[i] = n
+			n.SetParent(parent)
+		}
+	}
+	node.SetParent(nil)
+}
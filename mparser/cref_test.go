@@ -0,0 +1,34 @@
+package mparser
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/render/xml"
+)
+
+func TestAddCrefs(t *testing.T) {
+	const doc = "Some text [^ekr: check this number] here.\n\n{source=\"mt\"}\n``` cref\nThis section\nis wrong.\n```\n"
+	for _, final := range []bool{false, true} {
+		p := parser.NewWithExtensions(Extensions)
+		p.Opts = parser.Options{ParserHook: Hook}
+		d := markdown.Parse([]byte(doc), p)
+		AddCrefs(d, final)
+		out := markdown.Render(d, xml.NewRenderer(xml.RendererOptions{Flags: xml.CommonFlags | xml.XMLFragment}))
+
+		want := []string{"<t>Some text <cref source=\"ekr\">check this number</cref> here.</t>", "<t><cref source=\"mt\">This section\nis wrong.</cref></t>"}
+		if final {
+			want = []string{"<t>Some text here.</t>"}
+			if bytes.Contains(out, []byte("cref")) || bytes.Contains(out, []byte("wrong")) {
+				t.Errorf("expected no comments with final, got:\n%s", out)
+			}
+		}
+		for _, w := range want {
+			if !bytes.Contains(out, []byte(w)) {
+				t.Errorf("expected %s in:\n%s", w, out)
+			}
+		}
+	}
+}
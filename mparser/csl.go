@@ -0,0 +1,233 @@
+package mparser
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/mast/reference"
+)
+
+// CSLItem is an item in a CSL-JSON file, as exported by Zotero and Mendeley. Only the variables we
+// can map to a reference are defined. See https://citeproc-js.readthedocs.io/en/latest/csl-json/markup.html.
+type CSLItem struct {
+	ID             string    `json:"id"`
+	Type           string    `json:"type"`
+	Title          CSLString `json:"title"`
+	Author         []CSLName `json:"author"`
+	Editor         []CSLName `json:"editor"`
+	Issued         *CSLDate  `json:"issued"`
+	ContainerTitle CSLString `json:"container-title"`
+	Publisher      string    `json:"publisher"`
+	Volume         CSLString `json:"volume"`
+	Issue          CSLString `json:"issue"`
+	Page           CSLString `json:"page"`
+	DOI            string    `json:"DOI"`
+	URL            string    `json:"URL"`
+}
+
+// CSLName is a name in CSL-JSON, either a person or, with literal, an organization.
+type CSLName struct {
+	Family  string `json:"family"`
+	Given   string `json:"given"`
+	Literal string `json:"literal"`
+}
+
+// CSLDate is a date in CSL-JSON.
+type CSLDate struct {
+	DateParts [][]CSLString `json:"date-parts"`
+	Raw       string        `json:"raw"`
+	Literal   string        `json:"literal"`
+}
+
+// CSLString is a CSL-JSON variable that is either a string or a number. Some APIs return an array of
+// strings, the first one is used then.
+type CSLString string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *CSLString) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		*s = CSLString(str)
+		return nil
+	}
+	var strs []string
+	if err := json.Unmarshal(data, &strs); err == nil {
+		if len(strs) > 0 {
+			*s = CSLString(strs[0])
+		}
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*s = CSLString(n)
+	return nil
+}
+
+// CSL parses the CSL-JSON in data and returns the items as references.
+func CSL(data []byte) ([]reference.Reference, error) {
+	items := []CSLItem{}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	refs := make([]reference.Reference, 0, len(items))
+	for _, item := range items {
+		if item.ID == "" {
+			continue
+		}
+		refs = append(refs, item.Reference())
+	}
+	return refs, nil
+}
+
+// Reference returns the item as a reference.
+func (c CSLItem) Reference() reference.Reference {
+	ref := reference.Reference{Anchor: c.ID, Target: c.URL}
+	ref.Front.Title = string(c.Title)
+	for _, a := range c.Author {
+		ref.Front.Authors = append(ref.Front.Authors, a.author(""))
+	}
+	for _, a := range c.Editor {
+		ref.Front.Authors = append(ref.Front.Authors, a.author("editor"))
+	}
+	if c.Issued != nil {
+		ref.Front.Date = c.Issued.date()
+	}
+
+	content := []string{}
+	if c.ContainerTitle != "" {
+		content = append(content, string(c.ContainerTitle))
+	}
+	if c.Volume != "" {
+		content = append(content, "Vol. "+string(c.Volume))
+	}
+	if c.Issue != "" {
+		content = append(content, "No. "+string(c.Issue))
+	}
+	if c.Page != "" {
+		content = append(content, "pp. "+string(c.Page))
+	}
+	if c.Publisher != "" {
+		content = append(content, c.Publisher)
+	}
+	if len(content) > 0 {
+		ref.RefContent = []string{strings.Join(content, ", ")}
+	}
+
+	if c.DOI != "" {
+		ref.Series = append(ref.Series, reference.SeriesInfo{Name: "DOI", Value: c.DOI})
+		if ref.Target == "" {
+			ref.Target = "https://doi.org/" + c.DOI
+		}
+	}
+	return ref
+}
+
+func (n CSLName) author(role string) reference.Author {
+	if n.Literal != "" {
+		return reference.Author{Role: role, Organization: &reference.Organization{Value: n.Literal}}
+	}
+	a := reference.Author{Surname: n.Family, Fullname: strings.TrimSpace(n.Given + " " + n.Family), Role: role}
+	initials := []string{}
+	for _, g := range strings.Fields(n.Given) {
+		r, _ := utf8.DecodeRuneInString(g)
+		initials = append(initials, string(r)+".")
+	}
+	a.Initials = strings.Join(initials, " ")
+	return a
+}
+
+func (d *CSLDate) date() *reference.Date {
+	if len(d.DateParts) == 0 || len(d.DateParts[0]) == 0 {
+		year := d.Raw
+		if year == "" {
+			year = d.Literal
+		}
+		if year == "" {
+			return nil
+		}
+		return &reference.Date{Year: year}
+	}
+	parts := d.DateParts[0]
+	date := &reference.Date{Year: string(parts[0])}
+	if len(parts) > 1 {
+		var m int
+		fmt.Sscanf(string(parts[1]), "%d", &m)
+		if m >= 1 && m <= 12 {
+			date.Month = time.Month(m).String()
+		}
+	}
+	if len(parts) > 2 {
+		date.Day = string(parts[2])
+	}
+	return date
+}
+
+// AddCSL reads the CSL-JSON files from the title block's csl element and adds their items as
+// reference blocks to the end of doc, so they end up in the bibliography when cited. The files are
+// relative to the document and, unless unsafe includes are allowed, must be on the same level or
+// below it. Items with an anchor that is also used by an XML reference in the document are skipped.
+// If no references are added this returns false.
+func (i Initial) AddCSL(doc ast.Node) bool {
+	var t *mast.Title
+	anchors := map[string]bool{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		switch n := node.(type) {
+		case *mast.Title:
+			t = n
+		case *mast.ReferenceBlock:
+			if anchor := anchorFromReference(n.Literal); anchor != nil {
+				anchors[string(bytes.ToLower(anchor))] = true
+			}
+		}
+		return ast.GoToNext
+	})
+	if t == nil || t.TitleData == nil || len(t.CSL) == 0 {
+		return false
+	}
+
+	added := false
+	for _, file := range t.CSL {
+		path := i.path("", file)
+		if i.Flags&UnsafeInclude == 0 && !i.pathAllowed(path) {
+			i.Report.Reportf(SeverityError, "csl", "failure to read %q: path is not on or below %q", path, i.i)
+			continue
+		}
+		data, err := i.readFile(path)
+		if err != nil {
+			i.Report.Reportf(SeverityError, "csl", "failure to read CSL-JSON: %s", err)
+			continue
+		}
+		if i.Record != nil {
+			i.Record(path)
+		}
+		refs, err := CSL(data)
+		if err != nil {
+			i.Report.Reportf(SeverityError, "csl", "failure to parse CSL-JSON %q: %s", path, err)
+			continue
+		}
+		for _, ref := range refs {
+			if anchors[strings.ToLower(ref.Anchor)] {
+				continue
+			}
+			anchors[strings.ToLower(ref.Anchor)] = true
+			out, err := xml.MarshalIndent(ref, "", "   ")
+			if err != nil {
+				continue
+			}
+			node := &mast.ReferenceBlock{}
+			node.Literal = out
+			ast.AppendChild(doc, node)
+			added = true
+		}
+	}
+	return added
+}
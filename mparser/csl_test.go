@@ -0,0 +1,53 @@
+package mparser
+
+import "testing"
+
+func TestCSL(t *testing.T) {
+	data := []byte(`[
+  {"id": "smith2020", "type": "article-journal", "title": "On Things",
+   "author": [{"family": "Smith", "given": "John Paul"}, {"literal": "ACME Corp"}],
+   "editor": [{"family": "Doe", "given": "Jane"}],
+   "issued": {"date-parts": [[2020, 5, 1]]}, "container-title": "Journal of Stuff",
+   "volume": 12, "issue": "3", "page": "1-10", "DOI": "10.1000/xyz"},
+  {"id": "doe2019", "title": "Other", "URL": "https://example.org", "issued": {"raw": "2019"}},
+  {"title": "No id"}
+]`)
+	refs, err := CSL(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 2 {
+		t.Fatalf("expected 2 references, got %d", len(refs))
+	}
+
+	r := refs[0]
+	if r.Anchor != "smith2020" || r.Front.Title != "On Things" {
+		t.Errorf("expected anchor and title, got %q and %q", r.Anchor, r.Front.Title)
+	}
+	if len(r.Front.Authors) != 3 {
+		t.Fatalf("expected 3 authors, got %d", len(r.Front.Authors))
+	}
+	if a := r.Front.Authors[0]; a.Initials != "J. P." || a.Surname != "Smith" || a.Fullname != "John Paul Smith" {
+		t.Errorf("unexpected author: %+v", a)
+	}
+	if a := r.Front.Authors[1]; a.Organization == nil || a.Organization.Value != "ACME Corp" {
+		t.Errorf("expected organization author, got %+v", a)
+	}
+	if a := r.Front.Authors[2]; a.Role != "editor" {
+		t.Errorf("expected editor, got %+v", a)
+	}
+	if d := r.Front.Date; d == nil || d.Year != "2020" || d.Month != "May" || d.Day != "1" {
+		t.Errorf("unexpected date: %+v", d)
+	}
+	if len(r.RefContent) != 1 || r.RefContent[0] != "Journal of Stuff, Vol. 12, No. 3, pp. 1-10" {
+		t.Errorf("unexpected refcontent: %q", r.RefContent)
+	}
+	if len(r.Series) != 1 || r.Series[0].Name != "DOI" || r.Target != "https://doi.org/10.1000/xyz" {
+		t.Errorf("expected DOI seriesInfo and target, got %v and %q", r.Series, r.Target)
+	}
+
+	r = refs[1]
+	if r.Target != "https://example.org" || r.Front.Date == nil || r.Front.Date.Year != "2019" {
+		t.Errorf("unexpected reference: %+v", r)
+	}
+}
@@ -0,0 +1,128 @@
+package mparser
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+var (
+	divOpen  = regexp.MustCompile(`^ {0,3}:{3,}[ \t]*([A-Za-z][\w-]*)?[ \t]*(\{[^}\n]*\})?[ \t]*:*[ \t]*$`)
+	divClose = regexp.MustCompile(`^ {0,3}:{3,}[ \t]*$`)
+	divAttr  = regexp.MustCompile(`(?:#|\.)[^\s}]+|[\w-]+="[^"]*"`)
+)
+
+// DivHook parses a fenced div, a block level container that can hold any other block:
+//
+//	::: note {#important}
+//	This is a *note*.
+//	:::
+//
+// The fence has at least three colons, the opening one is followed by a name and/or attributes. Divs
+// can be nested, the closing fence closes the innermost open div. A div named "aside" becomes an
+// aside, all others a mast.Div. The source must be run through DivFences before parsing.
+func DivHook(data []byte) (ast.Node, []byte, int) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " "), []byte(":::")) {
+		return nil, nil, 0
+	}
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	m := divOpen.FindSubmatch(bytes.TrimRight(lines[0], "\n"))
+	if m == nil || (len(m[1]) == 0 && len(m[2]) == 0) {
+		return nil, nil, 0
+	}
+
+	depth := 1
+	consumed := len(lines[0])
+	end := 0 // start of the closing fence
+	divLines(lines[1:], func(line []byte, open bool) bool {
+		consumed += len(line)
+		if open {
+			depth++
+			return true
+		}
+		depth--
+		if depth > 0 {
+			return true
+		}
+		end = consumed - len(line)
+		return false
+	}, func(line []byte) { consumed += len(line) })
+	if depth > 0 {
+		return nil, nil, 0
+	}
+
+	var div ast.Node = &mast.Div{Name: string(m[1])}
+	if string(m[1]) == "aside" {
+		div = &ast.Aside{}
+	}
+	if len(m[2]) > 0 {
+		div.AsContainer().Attribute = divAttribute(m[2])
+	}
+	return div, data[len(lines[0]):end], consumed
+}
+
+// divAttribute parses the attributes, {#id .class key="value"}, of a fenced div.
+func divAttribute(data []byte) *ast.Attribute {
+	a := &ast.Attribute{Attrs: map[string][]byte{}}
+	for _, attr := range divAttr.FindAll(data, -1) {
+		switch attr[0] {
+		case '#':
+			a.ID = attr[1:]
+		case '.':
+			a.Classes = append(a.Classes, attr[1:])
+		default:
+			k, v, _ := bytes.Cut(attr, []byte("="))
+			a.Attrs[string(k)] = bytes.Trim(v, `"`)
+		}
+	}
+	return a
+}
+
+// DivFences returns data with the fences of the fenced divs indented by a space, as otherwise a fence
+// after a paragraph and an empty line is parsed as a definition. The ones in code blocks are left
+// alone.
+func DivFences(data []byte) []byte {
+	buf := &bytes.Buffer{}
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	divLines(lines, func(line []byte, _ bool) bool {
+		if line[0] == ':' {
+			buf.WriteByte(' ')
+		}
+		buf.Write(line)
+		return true
+	}, func(line []byte) { buf.Write(line) })
+	return buf.Bytes()
+}
+
+// divLines calls fence for each line that is an opening (open is true) or closing fence of a fenced
+// div, and other for the other lines. Fences in code blocks are not recognized. It stops when fence
+// returns false.
+func divLines(lines [][]byte, fence func(line []byte, open bool) bool, other func(line []byte)) {
+	var code []byte // the open fence of a code block, if we're in one
+	for _, line := range lines {
+		l := bytes.TrimRight(line, " \t\n")
+		switch {
+		case code != nil:
+			if bytes.HasPrefix(l, code) && len(bytes.Trim(l, string(code[:1]))) == 0 {
+				code = nil
+			}
+		case bytes.HasPrefix(l, []byte("```")) || bytes.HasPrefix(l, []byte("~~~")):
+			code = l[:3]
+		case divClose.Match(l):
+			if !fence(line, false) {
+				return
+			}
+			continue
+		case divOpen.Match(l):
+			if m := divOpen.FindSubmatch(l); len(m[1]) > 0 || len(m[2]) > 0 {
+				if !fence(line, true) {
+					return
+				}
+				continue
+			}
+		}
+		other(line)
+	}
+}
@@ -0,0 +1,61 @@
+package mparser
+
+import (
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+func TestDivHook(t *testing.T) {
+	const doc = `::: note {#n1 .important level="2"}
+A note.
+
+::: warning
+~~~
+:::
+~~~
+:::
+:::
+
+::: aside
+An aside.
+:::
+
+:::
+Not a div.
+`
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook}
+	d := markdown.Parse(DivFences([]byte(doc)), p)
+
+	children := d.GetChildren()
+	if len(children) != 3 {
+		t.Fatalf("expected 3 children, got %d", len(children))
+	}
+	note, ok := children[0].(*mast.Div)
+	if !ok || note.Name != "note" {
+		t.Fatalf("expected a note div, got %T", children[0])
+	}
+	if string(note.Attribute.ID) != "n1" || !mast.AttributeClass(note, "important") || string(mast.Attribute(note, "level")) != "2" {
+		t.Errorf("unexpected attributes %+v", note.Attribute)
+	}
+	if len(note.Children) != 2 {
+		t.Fatalf("expected a paragraph and a div in the note, got %d children", len(note.Children))
+	}
+	warning, ok := note.Children[1].(*mast.Div)
+	if !ok || warning.Name != "warning" {
+		t.Fatalf("expected a warning div, got %T", note.Children[1])
+	}
+	if code, ok := warning.Children[0].(*ast.CodeBlock); !ok || string(code.Literal) != ":::\n" {
+		t.Errorf("expected the code block to hold the fence, got %T", warning.Children[0])
+	}
+	if _, ok := children[1].(*ast.Aside); !ok {
+		t.Errorf("expected an aside, got %T", children[1])
+	}
+	if _, ok := children[2].(*ast.Paragraph); !ok {
+		t.Errorf("expected a paragraph for a fence without a name, got %T", children[2])
+	}
+}
@@ -0,0 +1,50 @@
+package mparser
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/url"
+	"strings"
+)
+
+// DOIPrefix is the prefix of a citation of a DOI, i.e. [@doi:10.1145/3133956].
+const DOIPrefix = "doi:"
+
+// isDOI returns the DOI of a DOI citation.
+func isDOI(anchor string) (string, bool) {
+	if len(anchor) <= len(DOIPrefix) || !strings.EqualFold(anchor[:len(DOIPrefix)], DOIPrefix) {
+		return "", false
+	}
+	return anchor[len(DOIPrefix):], true
+}
+
+// DOIAnchor returns the anchor used for the reference of doi, a DOI contains characters that aren't
+// allowed in an XML ID, these are replaced with underscores: 10.1145/3133956 becomes
+// DOI.10.1145_3133956.
+func DOIAnchor(doi string) string {
+	return "DOI." + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, doi)
+}
+
+// DOI resolves doi via content negotiation on doi.org, which works for DOIs registered with Crossref
+// and DataCite, and returns the XML of the reference, with DOIAnchor(doi) as anchor.
+func (f *Fetcher) DOI(doi string) ([]byte, error) {
+	data, err := f.fetch("doi/"+url.PathEscape(doi)+".json", f.DOIURL+"/"+doi, "application/vnd.citationstyles.csl+json")
+	if err != nil {
+		return nil, err
+	}
+	item := CSLItem{}
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, err
+	}
+	item.ID = DOIAnchor(doi)
+	if item.DOI == "" {
+		item.DOI = doi
+	}
+	return xml.MarshalIndent(item.Reference(), "", "   ")
+}
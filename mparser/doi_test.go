@@ -0,0 +1,63 @@
+package mparser
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+func TestDOIAnchor(t *testing.T) {
+	if a := DOIAnchor("10.1145/3133956"); a != "DOI.10.1145_3133956" {
+		t.Errorf("expected DOI.10.1145_3133956, got %s", a)
+	}
+}
+
+func TestFetcherDOI(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/10.1145/3133956" || r.Header.Get("Accept") != "application/vnd.citationstyles.csl+json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"type": "paper-conference", "title": "A Paper",
+"author": [{"given": "Jane", "family": "Doe", "sequence": "first"}],
+"issued": {"date-parts": [[2017, 10, 30]]}, "container-title": ["Proceedings"],
+"DOI": "10.1145/3133956", "URL": "http://dx.doi.org/10.1145/3133956"}`))
+	}))
+	defer srv.Close()
+
+	f := NewFetcher()
+	f.DOIURL = srv.URL
+	f.Cache = t.TempDir()
+
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook}
+	d := markdown.Parse([]byte("See [@doi:10.1145/3133956].\n\n{backmatter}\n"), p)
+	if !f.AddReferences(d) {
+		t.Fatal("expected a reference to be added")
+	}
+
+	ast.WalkFunc(d, func(node ast.Node, entering bool) ast.WalkStatus {
+		if c, ok := node.(*ast.Citation); ok && string(c.Destination[0]) != "DOI.10.1145_3133956" {
+			t.Errorf("expected citation to use the DOI anchor, got %s", c.Destination[0])
+		}
+		return ast.GoToNext
+	})
+
+	_, inform := CitationToBibliography(d)
+	item := inform.GetChildren()[0].(*mast.BibliographyItem)
+	if item.Reference == nil {
+		t.Fatal("expected a reference for the DOI")
+	}
+	r := item.Reference
+	if r.Front.Title != "A Paper" || len(r.Front.Authors) != 1 || r.Front.Date.Year != "2017" {
+		t.Errorf("unexpected reference: %+v", r.Front)
+	}
+	if len(r.Series) != 1 || r.Series[0].Value != "10.1145/3133956" || len(r.RefContent) != 1 || r.RefContent[0] != "Proceedings" {
+		t.Errorf("unexpected reference: %+v %+v", r.Series, r.RefContent)
+	}
+}
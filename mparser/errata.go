@@ -0,0 +1,80 @@
+package mparser
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmarkdown/mmark/v2/mast/reference"
+)
+
+var errataAnchor = regexp.MustCompile(`^Err(\d+)$`)
+
+// isErratum returns the errata ID of an erratum citation, i.e. [@Err5744].
+func isErratum(anchor string) (string, bool) {
+	m := errataAnchor.FindStringSubmatch(anchor)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// Erratum is an RFC erratum, as returned by the errata API of the RFC Editor.
+type Erratum struct {
+	ID      interface{} `json:"errata_id"` // a number or a string
+	DocID   string      `json:"doc-id"`    // i.e. RFC8446
+	Section string      `json:"section"`
+	Type    string      `json:"errata_type_code"`   // Technical or Editorial
+	Status  string      `json:"errata_status_code"` // Verified, Reported, Held for Document Update or Rejected
+	Date    string      `json:"submit_date"`        // YYYY-MM-DD
+}
+
+// Erratum returns the XML of the reference for the erratum anchor, i.e. Err5744. The details come
+// from the errata API of the RFC Editor, which lists all errata and is cached as errata.json.
+func (f *Fetcher) Erratum(anchor string) ([]byte, error) {
+	id, ok := isErratum(anchor)
+	if !ok {
+		return nil, fmt.Errorf("no erratum for %q", anchor)
+	}
+	data, err := f.fetch("errata.json", f.ErrataURL, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	errata := []Erratum{}
+	if err := json.Unmarshal(data, &errata); err != nil {
+		return nil, err
+	}
+	for _, e := range errata {
+		if fmt.Sprint(e.ID) == id {
+			return erratumReference(anchor, id, e)
+		}
+	}
+	return nil, fmt.Errorf("erratum %s not found", id)
+}
+
+// erratumReference returns the XML of the reference for erratum e. The title and target only need the
+// errata ID, so when e is empty this is still a usable reference.
+func erratumReference(anchor, id string, e Erratum) ([]byte, error) {
+	ref := reference.Reference{Anchor: anchor, Target: "https://www.rfc-editor.org/errata/eid" + id}
+	ref.Front.Title = "Erratum ID " + id
+	ref.Front.Authors = []reference.Author{{Organization: &reference.Organization{Value: "RFC Errata"}}}
+	if d, err := time.Parse("2006-01-02", e.Date); err == nil {
+		ref.Front.Date = &reference.Date{Year: strconv.Itoa(d.Year()), Month: d.Month().String(), Day: strconv.Itoa(d.Day())}
+	}
+
+	content := []string{}
+	if strings.HasPrefix(e.DocID, "RFC") {
+		content = append(content, "RFC "+strings.TrimLeft(e.DocID[3:], "0"))
+	}
+	if e.Section != "" {
+		content = append(content, "Section "+e.Section)
+	}
+	if len(content) > 0 {
+		ref.RefContent = []string{strings.Join(content, ", ")}
+	}
+	return xml.MarshalIndent(ref, "", "   ")
+}
@@ -0,0 +1,59 @@
+package mparser
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+func TestFetcherErratum(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"errata_id": 5744, "doc-id": "RFC8446", "section": "4.2.1",
+"errata_type_code": "Technical", "errata_status_code": "Verified", "submit_date": "2019-06-12"},
+{"errata_id": "5745", "doc-id": "RFC8446"}]`))
+	}))
+	defer srv.Close()
+
+	f := NewFetcher()
+	f.URL = srv.URL + "/none"
+	f.ErrataURL = srv.URL
+	f.Cache = t.TempDir()
+
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook}
+	d := markdown.Parse([]byte("See [@Err5744] and [@Err9999].\n\n{backmatter}\n"), p)
+	if !f.AddReferences(d) {
+		t.Fatal("expected a reference to be added")
+	}
+
+	_, inform := CitationToBibliography(d)
+	refs := map[string]*mast.BibliographyItem{}
+	for _, c := range inform.GetChildren() {
+		item := c.(*mast.BibliographyItem)
+		refs[string(item.Anchor)] = item
+	}
+
+	r := refs["Err5744"].Reference
+	if r == nil {
+		t.Fatal("expected a reference for Err5744")
+	}
+	if r.Front.Title != "Erratum ID 5744" || r.Target != "https://www.rfc-editor.org/errata/eid5744" {
+		t.Errorf("unexpected reference: %+v", r)
+	}
+	if r.Front.Date == nil || r.Front.Date.Year != "2019" || r.Front.Date.Month != "June" {
+		t.Errorf("unexpected date: %+v", r.Front.Date)
+	}
+	if len(r.RefContent) != 1 || r.RefContent[0] != "RFC 8446, Section 4.2.1" {
+		t.Errorf("unexpected refcontent: %+v", r.RefContent)
+	}
+
+	// An unknown erratum still gets a reference, without the details.
+	r = refs["Err9999"].Reference
+	if r == nil || r.Front.Title != "Erratum ID 9999" || len(r.RefContent) != 0 {
+		t.Errorf("unexpected reference for Err9999: %+v", r)
+	}
+}
@@ -1,6 +1,7 @@
 package mparser
 
 import (
+	"github.com/gomarkdown/markdown/ast"
 	"github.com/gomarkdown/markdown/parser"
 )
 
@@ -9,3 +10,20 @@ var Extensions = parser.Tables | parser.FencedCode | parser.Autolink | parser.St
 	parser.HeadingIDs | parser.BackslashLineBreak | parser.SuperSubscript | parser.DefinitionLists | parser.MathJax |
 	parser.AutoHeadingIDs | parser.Footnotes | parser.Strikethrough | parser.OrderedListStart | parser.Attributes |
 	parser.Mmark | parser.Includes | parser.NonBlockingSpace
+
+// NonBreakingHyphen is what a `\-` between two words becomes, i.e. "RFC\-1234".
+const NonBreakingHyphen = "\u2011"
+
+// RegisterInlines registers the inline parsers mmark adds to the ones of gomarkdown with p: a `\-`
+// between two words is a non-breaking hyphen, other escapes are left to gomarkdown.
+func RegisterInlines(p *parser.Parser) {
+	escape := p.RegisterInline('\\', nil)
+	p.RegisterInline('\\', func(p *parser.Parser, data []byte, offset int) (int, ast.Node) {
+		if offset > 0 && offset+2 < len(data) && data[offset+1] == '-' && !isSpace(data[offset-1]) && !isSpace(data[offset+2]) {
+			return 2, &ast.Text{Leaf: ast.Leaf{Literal: []byte(NonBreakingHyphen)}}
+		}
+		return escape(p, data, offset)
+	})
+}
+
+func isSpace(c byte) bool { return c == ' ' || c == '\t' || c == '\n' }
@@ -0,0 +1,394 @@
+package mparser
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/mast/reference"
+)
+
+// Fetcher fetches the XML of references to RFCs, I-Ds, BCPs, STDs, W3C, 3GPP and IEEE documents from
+// bib.ietf.org, or a mirror of it, and caches these on disk. References in Dir are used before
+// anything is fetched, a Fetcher without a URL only uses Dir.
+type Fetcher struct {
+	Dir       string        // directory with local reference.*.xml files, these take precedence
+	URL       string        // base URL of the bibxml directories, defaults to https://bib.ietf.org/public/rfc
+	DOIURL    string        // URL to resolve DOIs, defaults to https://doi.org
+	ErrataURL string        // URL of the errata API, defaults to https://www.rfc-editor.org/errata.json
+	Cache     string        // cache directory, if empty nothing is cached
+	TTL       time.Duration // cached references older than this are fetched again
+	Offline   bool          // only use the cache, even when the cached references are too old
+	MaxSize   int64         // maximum size of a fetched document in bytes, 0 means no limit
+	Report    Reporter      // if set, called with the references that couldn't be fetched, otherwise these are logged
+
+	Client *http.Client
+}
+
+// NewFetcher returns a Fetcher that uses bib.ietf.org and caches in the mmark directory of the user's
+// cache directory, i.e. $XDG_CACHE_HOME/mmark.
+func NewFetcher() *Fetcher {
+	f := &Fetcher{
+		URL:       "https://bib.ietf.org/public/rfc",
+		DOIURL:    "https://doi.org",
+		ErrataURL: "https://www.rfc-editor.org/errata.json",
+		TTL:       7 * 24 * time.Hour,
+		Client:    &http.Client{Timeout: 10 * time.Second},
+	}
+	if dir, err := os.UserCacheDir(); err == nil {
+		f.Cache = filepath.Join(dir, "mmark")
+	}
+	return f
+}
+
+var fetchAnchor = regexp.MustCompile(`^(RFC|BCP|STD)(\d+)$`)
+
+// libraries maps the anchor prefixes of the other bibxml libraries to their directory.
+var libraries = map[string]string{
+	"W3C.":  "bibxml4",
+	"3GPP.": "bibxml5",
+	"IEEE.": "bibxml6",
+}
+
+// bibPath returns the path of the reference for anchor below the base URL, or the empty string if we
+// can't fetch anchor. Anchors with a path separator or ".." can't be fetched, they would escape the
+// library, and the cache.
+func bibPath(anchor string) string {
+	if strings.ContainsAny(anchor, `/\`) || strings.Contains(anchor, "..") {
+		return ""
+	}
+	if strings.HasPrefix(anchor, "I-D.") && len(anchor) > 4 {
+		draft := anchor[4:]
+		// I-D.foo#03 is version 03 of draft-foo.
+		if hash := strings.Index(draft, "#"); hash > 0 {
+			draft = "draft-" + draft[:hash] + "-" + draft[hash+1:]
+		}
+		return "bibxml3/reference.I-D." + draft + ".xml"
+	}
+	// The other libraries use the anchor as is: W3C.REC-xml-20081126, 3GPP.23.501 and IEEE.802.1Q.
+	for prefix, dir := range libraries {
+		if strings.HasPrefix(anchor, prefix) && len(anchor) > len(prefix) {
+			return dir + "/reference." + anchor + ".xml"
+		}
+	}
+	m := fetchAnchor.FindStringSubmatch(anchor)
+	if m == nil {
+		return ""
+	}
+	n, _ := strconv.Atoi(m[2])
+	if m[1] == "RFC" {
+		return fmt.Sprintf("bibxml/reference.RFC.%04d.xml", n)
+	}
+	return fmt.Sprintf("bibxml9/reference.%s.%04d.xml", m[1], n)
+}
+
+// local returns the path of the reference for anchor in Dir, or the empty string if Dir doesn't have
+// it. The file is either named after the anchor, reference.ACME-SPEC-12.xml, or as in the bibxml
+// libraries, reference.RFC.2119.xml.
+func (f *Fetcher) local(anchor string) string {
+	if f.Dir == "" || strings.ContainsAny(anchor, `/\`) {
+		return ""
+	}
+	names := []string{"reference." + anchor + ".xml"}
+	if path := bibPath(anchor); path != "" {
+		names = append(names, filepath.Base(path))
+	}
+	for _, name := range names {
+		file := filepath.Join(f.Dir, name)
+		if fi, err := os.Stat(file); err == nil && fi.Mode().IsRegular() {
+			return file
+		}
+	}
+	return ""
+}
+
+// Reference returns the XML of the reference for anchor, without the XML declaration. The reference
+// in Dir is returned if there is one.
+func (f *Fetcher) Reference(anchor string) ([]byte, error) {
+	if file := f.local(anchor); file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		return stripDeclaration(data), nil
+	}
+	path := bibPath(anchor)
+	if path == "" || f.URL == "" {
+		return nil, fmt.Errorf("no reference for %q", anchor)
+	}
+	data, err := f.fetch(path, f.URL+"/"+path, "")
+	if err != nil {
+		return nil, err
+	}
+	return stripDeclaration(data), nil
+}
+
+var includeRFC = regexp.MustCompile(`reference\.RFC\.(\d+)\.xml$`)
+
+// Group returns the XML of the <referencegroup> for a BCP or STD anchor, containing the references of
+// the RFCs that make up the BCP or STD. Constituent RFCs that are included with <xi:include> are
+// fetched and inlined.
+func (f *Fetcher) Group(anchor string) ([]byte, error) {
+	data, err := f.Reference(anchor)
+	if err != nil {
+		return nil, err
+	}
+	g := reference.Group{}
+	if err := xml.Unmarshal(data, &g); err != nil {
+		return nil, err
+	}
+	for _, inc := range g.Includes {
+		m := includeRFC.FindStringSubmatch(inc.Href)
+		if m == nil {
+			return nil, fmt.Errorf("can't include %q in %q", inc.Href, anchor)
+		}
+		n, _ := strconv.Atoi(m[1])
+		data, err := f.Reference(fmt.Sprintf("RFC%d", n))
+		if err != nil {
+			return nil, err
+		}
+		ref := reference.Reference{}
+		if err := xml.Unmarshal(data, &ref); err != nil {
+			return nil, err
+		}
+		g.References = append(g.References, ref)
+	}
+	g.Includes = nil
+	if len(g.References) == 0 {
+		return nil, fmt.Errorf("no references in %q", anchor)
+	}
+	if g.Target == "" {
+		g.Target = "https://www.rfc-editor.org/info/" + strings.ToLower(anchor)
+	}
+	return xml.MarshalIndent(g, "", "   ")
+}
+
+// isGroup returns true if anchor is a BCP or STD, these are reference groups.
+func isGroup(anchor string) bool {
+	m := fetchAnchor.FindStringSubmatch(anchor)
+	return m != nil && m[1] != "RFC"
+}
+
+// fetch returns the data of url, which is cached as path in the cache directory. If accept isn't
+// empty, it is used as the Accept header.
+func (f *Fetcher) fetch(path, url, accept string) ([]byte, error) {
+	cached := ""
+	if f.Cache != "" {
+		cached = filepath.Join(f.Cache, filepath.FromSlash(path))
+		if rel, err := filepath.Rel(f.Cache, cached); err != nil || !filepath.IsLocal(rel) {
+			return nil, fmt.Errorf("%q is outside the cache", path)
+		}
+		if fi, err := os.Stat(cached); err == nil && (f.Offline || time.Since(fi.ModTime()) < f.TTL) {
+			if data, err := os.ReadFile(cached); err == nil {
+				return data, nil
+			}
+		}
+	}
+	if f.Offline {
+		return nil, errors.New("not in the cache and offline")
+	}
+
+	data, err := f.get(url, accept)
+	if err != nil {
+		// a stale cached copy is better than none.
+		if cached != "" {
+			if stale, err1 := os.ReadFile(cached); err1 == nil {
+				return stale, nil
+			}
+		}
+		return nil, err
+	}
+	if cached != "" {
+		// written to a temporary file first, as another mmark may read or write it at the same time.
+		if err := os.MkdirAll(filepath.Dir(cached), 0o755); err == nil {
+			if tmp, err := os.CreateTemp(filepath.Dir(cached), ".fetch"); err == nil {
+				_, err = tmp.Write(data)
+				tmp.Close()
+				if err == nil {
+					err = os.Rename(tmp.Name(), cached)
+				}
+				if err != nil {
+					os.Remove(tmp.Name())
+				}
+			}
+		}
+	}
+	return data, nil
+}
+
+func (f *Fetcher) get(url, accept string) ([]byte, error) {
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	if f.MaxSize <= 0 {
+		return io.ReadAll(resp.Body)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.MaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > f.MaxSize {
+		return nil, fmt.Errorf("%s: larger than %d bytes", url, f.MaxSize)
+	}
+	return data, nil
+}
+
+// stripDeclaration removes the <?xml ...?> declaration from data.
+func stripDeclaration(data []byte) []byte {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("<?xml")) {
+		if end := bytes.Index(data, []byte("?>")); end > 0 {
+			data = bytes.TrimSpace(data[end+2:])
+		}
+	}
+	return data
+}
+
+// AddReferences fetches the references for the citations of RFCs, I-Ds, BCPs, STDs, W3C, 3GPP and
+// IEEE documents that don't have an XML reference in the document and adds these as reference blocks
+// to the end of doc. BCPs and STDs become a <referencegroup> of their RFCs, see Group. DOI citations,
+// [@doi:10.1145/3133956], are resolved as well, these citations are changed to use DOIAnchor as their
+// anchor. Errata citations, [@Err5744], get a reference to the erratum. URL citations,
+// [@https://example.org/spec], get a reference with the page's title, these citations are changed to
+// use URLAnchor as their anchor. References that can't be fetched are logged and left out, these are
+// then handled as if there is no fetcher. Citations of references in Dir are resolved from there, for
+// any anchor. If no references are added this returns false.
+func (f *Fetcher) AddReferences(doc ast.Node) bool {
+	type fetch struct {
+		anchor string
+		doi    string
+		url    string
+	}
+	cited := map[string]bool{}
+	have := map[string]bool{}
+	fetches := []fetch{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		switch n := node.(type) {
+		case *ast.Citation:
+			for i, d := range n.Destination {
+				a, doi, u := string(d), "", ""
+				if f.local(a) != "" {
+					if !cited[strings.ToLower(a)] {
+						cited[strings.ToLower(a)] = true
+						fetches = append(fetches, fetch{anchor: a})
+					}
+					continue
+				}
+				if f.URL == "" {
+					continue // only Dir is used
+				}
+				if x, ok := isDOI(a); ok {
+					a, doi = DOIAnchor(x), x
+					n.Destination[i] = []byte(a)
+				}
+				if isURL(a) {
+					a, u = URLAnchor(a), a
+					n.Destination[i] = []byte(a)
+				}
+				_, erratum := isErratum(a)
+				if (doi == "" && u == "" && !erratum && bibPath(a) == "") || cited[strings.ToLower(a)] {
+					continue
+				}
+				cited[strings.ToLower(a)] = true
+				fetches = append(fetches, fetch{a, doi, u})
+			}
+		case *mast.ReferenceBlock:
+			if anchor := anchorFromReference(n.Literal); anchor != nil {
+				have[string(bytes.ToLower(anchor))] = true
+			}
+		}
+		return ast.GoToNext
+	})
+
+	refs := make([][]byte, len(fetches))
+	var wg sync.WaitGroup
+	for i, x := range fetches {
+		// A versioned I-D citation uses the reference of the draft, when the document has it.
+		if have[strings.ToLower(x.anchor)] || have[strings.ToLower(withoutVersion(x.anchor))] {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, x fetch) {
+			defer wg.Done()
+			var (
+				data []byte
+				err  error
+			)
+			switch {
+			case x.doi != "":
+				data, err = f.DOI(x.doi)
+			case x.url != "":
+				if data, err = f.Page(x.url); err != nil {
+					// the URL is enough for a reference.
+					f.Report.Reportf(SeverityWarning, "fetch", "failure to fetch %q: %s, using the URL as title", x.url, err)
+					data, err = urlReference(x.url, "")
+				}
+			case isGroup(x.anchor):
+				data, err = f.Group(x.anchor)
+			case errataAnchor.MatchString(x.anchor):
+				if data, err = f.Erratum(x.anchor); err != nil {
+					// the target and title only need the errata ID.
+					f.Report.Reportf(SeverityWarning, "fetch", "failure to fetch erratum %q: %s, leaving out its details", x.anchor, err)
+					id, _ := isErratum(x.anchor)
+					data, err = erratumReference(x.anchor, id, Erratum{})
+				}
+			default:
+				data, err = f.Reference(x.anchor)
+			}
+			if err != nil {
+				f.Report.Reportf(SeverityWarning, "fetch", "failure to fetch reference %q: %s", x.anchor, err)
+				return
+			}
+			refs[i] = data
+		}(i, x)
+	}
+	wg.Wait()
+
+	added := false
+	for _, data := range refs {
+		if data == nil {
+			continue
+		}
+		node := &mast.ReferenceBlock{}
+		node.Literal = fmtReference(data)
+		ast.AppendChild(doc, node)
+		added = true
+	}
+	return added
+}
+
+// withoutVersion returns the anchor without the #version of an I-D.
+func withoutVersion(anchor string) string {
+	if hash := strings.Index(anchor, "#"); hash > 0 {
+		return anchor[:hash]
+	}
+	return anchor
+}
@@ -0,0 +1,186 @@
+package mparser
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+func TestBibPath(t *testing.T) {
+	for anchor, want := range map[string]string{
+		"RFC2119":              "bibxml/reference.RFC.2119.xml",
+		"RFC791":               "bibxml/reference.RFC.0791.xml",
+		"BCP14":                "bibxml9/reference.BCP.0014.xml",
+		"STD7":                 "bibxml9/reference.STD.0007.xml",
+		"I-D.ietf-foo-bar":     "bibxml3/reference.I-D.ietf-foo-bar.xml",
+		"I-D.ietf-foo-bar#03":  "bibxml3/reference.I-D.draft-ietf-foo-bar-03.xml",
+		"W3C.REC-xml-20081126": "bibxml4/reference.W3C.REC-xml-20081126.xml",
+		"3GPP.23.501":          "bibxml5/reference.3GPP.23.501.xml",
+		"IEEE.802.1Q":          "bibxml6/reference.IEEE.802.1Q.xml",
+		"IEEE.":                "",
+		"I-D.x/../../escaped":  "",
+		"W3C.a\\..\\b":         "",
+		"IEEE...":              "",
+		"pandoc":               "",
+	} {
+		if got := bibPath(anchor); got != want {
+			t.Errorf("expected %q for %s, got %q", want, anchor, got)
+		}
+	}
+}
+
+func TestFetcher(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path != "/bibxml/reference.RFC.2119.xml" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<reference anchor="RFC2119" target="https://www.rfc-editor.org/info/rfc2119"><front><title>Key words</title></front></reference>`))
+	}))
+	defer srv.Close()
+
+	f := NewFetcher()
+	f.URL = srv.URL
+	f.Cache = t.TempDir()
+
+	doc := "See [@RFC2119] and [@RFC8174] and [@pandoc].\n\n{backmatter}\n"
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook}
+	d := markdown.Parse([]byte(doc), p)
+	if !f.AddReferences(d) {
+		t.Fatal("expected a reference to be added")
+	}
+	_, inform := CitationToBibliography(d)
+	for _, c := range inform.GetChildren() {
+		item := c.(*mast.BibliographyItem)
+		if string(item.Anchor) == "RFC2119" && (item.Reference == nil || item.Reference.Front.Title != "Key words") {
+			t.Errorf("expected the fetched reference for RFC2119, got %+v", item.Reference)
+		}
+		if string(item.Anchor) != "RFC2119" && item.Reference != nil {
+			t.Errorf("expected no reference for %s", item.Anchor)
+		}
+	}
+	if atomic.LoadInt32(&requests) != 2 {
+		t.Errorf("expected 2 requests, got %d", atomic.LoadInt32(&requests))
+	}
+
+	// The reference is now cached, and can be used offline.
+	f.Offline = true
+	data, err := f.Reference("RFC2119")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), `<reference anchor="RFC2119"`) {
+		t.Errorf("expected cached reference without XML declaration, got %s", data)
+	}
+	if _, err := f.Reference("RFC8174"); err == nil {
+		t.Error("expected an error for a reference that isn't cached")
+	}
+	if atomic.LoadInt32(&requests) != 2 {
+		t.Errorf("expected no requests when offline, got %d", atomic.LoadInt32(&requests))
+	}
+}
+
+func TestFetchOutsideCache(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<reference anchor="escaped"/>`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	f := NewFetcher()
+	f.URL = srv.URL
+	f.Cache = filepath.Join(dir, "cache")
+	if _, err := f.Reference("I-D.x/../../../escaped"); err == nil {
+		t.Error("expected an error for an anchor with a path")
+	}
+	if _, err := f.fetch("bibxml3/../../escaped.xml", srv.URL, ""); err == nil {
+		t.Error("expected an error for a path outside the cache")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "escaped.xml")); err == nil {
+		t.Error("expected nothing to be written outside the cache")
+	}
+}
+
+func TestFetcherGroup(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bibxml9/reference.BCP.0014.xml":
+			w.Write([]byte(`<referencegroup anchor="BCP14" target="https://www.rfc-editor.org/info/bcp14">
+  <reference anchor="RFC2119" target="https://www.rfc-editor.org/info/rfc2119"><front><title>Key words</title></front></reference>
+  <xi:include href="https://bib.ietf.org/public/rfc/bibxml/reference.RFC.8174.xml"/>
+</referencegroup>`))
+		case "/bibxml/reference.RFC.8174.xml":
+			w.Write([]byte(`<reference anchor="RFC8174" target="https://www.rfc-editor.org/info/rfc8174"><front><title>Ambiguity</title></front></reference>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	f := NewFetcher()
+	f.URL = srv.URL
+	f.Cache = ""
+
+	doc := "See [@!BCP14].\n\n{backmatter}\n"
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook}
+	d := markdown.Parse([]byte(doc), p)
+	if !f.AddReferences(d) {
+		t.Fatal("expected a reference group to be added")
+	}
+	norm, _ := CitationToBibliography(d)
+	item := norm.GetChildren()[0].(*mast.BibliographyItem)
+	group := string(item.ReferenceGroup)
+	if !strings.HasPrefix(group, `<referencegroup anchor="BCP14" target="https://www.rfc-editor.org/info/bcp14">`) {
+		t.Errorf("expected a reference group for BCP14, got %s", group)
+	}
+	if !strings.Contains(group, `<reference anchor="RFC2119"`) || !strings.Contains(group, `<reference anchor="RFC8174"`) {
+		t.Errorf("expected RFC2119 and RFC8174 in the reference group, got %s", group)
+	}
+	if strings.Contains(group, "include") {
+		t.Errorf("expected the xi:include to be resolved, got %s", group)
+	}
+}
+
+func TestFetcherDir(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "reference.RFC.2119.xml"), []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<reference anchor="RFC2119" target="https://www.rfc-editor.org/info/rfc2119"><front><title>Key words</title></front></reference>`), 0o644)
+	os.WriteFile(filepath.Join(dir, "reference.ACME-SPEC-12.xml"), []byte(`<reference anchor="ACME-SPEC-12"><front><title>Widgets</title></front></reference>`), 0o644)
+
+	// Without a URL only the directory is used, so nothing is fetched.
+	f := &Fetcher{Dir: dir}
+	doc := "See [@RFC2119], [@ACME-SPEC-12] and [@RFC8174].\n\n{backmatter}\n"
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook}
+	d := markdown.Parse([]byte(doc), p)
+	if !f.AddReferences(d) {
+		t.Fatal("expected references to be added")
+	}
+	_, inform := CitationToBibliography(d)
+	titles := map[string]string{}
+	for _, c := range inform.GetChildren() {
+		item := c.(*mast.BibliographyItem)
+		if item.Reference != nil {
+			titles[string(item.Anchor)] = item.Reference.Front.Title
+		}
+	}
+	if titles["RFC2119"] != "Key words" || titles["ACME-SPEC-12"] != "Widgets" {
+		t.Errorf("expected the references from the directory, got %v", titles)
+	}
+	if _, ok := titles["RFC8174"]; ok {
+		t.Error("expected no reference for RFC8174")
+	}
+}
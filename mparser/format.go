@@ -0,0 +1,367 @@
+package mparser
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Format returns the markdown in data in its canonical layout, as done by mmark fmt. Only the layout is
+// changed, the document is the same:
+//
+//   - paragraphs are wrapped at width characters, a width of 0 leaves them alone;
+//   - the columns of pipe tables are aligned, with a pipe at the start and end of every row;
+//   - the XML of reference blocks is indented with two spaces for every level.
+//
+// The title block, code, HTML, lists, quotes and the other blocks are copied as is. A paragraph with a
+// hard line break or math isn't wrapped, neither is a table with row or footer separators.
+func Format(data []byte, width int) []byte {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	out := []string{}
+
+	i := 0
+	if len(lines) > 0 && (lines[0] == "%%%" || lines[0] == "---") {
+		end := 1
+		for end < len(lines) && lines[end] != lines[0] {
+			end++
+		}
+		i = min(end+1, len(lines))
+		out = append(out, lines[:i]...)
+	}
+
+	for i < len(lines) {
+		l := lines[i]
+		switch {
+		case strings.TrimSpace(l) == "":
+			out = append(out, "")
+			i++
+		case fence.MatchString(l):
+			end := i + 1
+			marker := strings.TrimLeft(fence.FindString(l), " ")
+			for end < len(lines) && !closingFence(lines[end], marker) {
+				end++
+			}
+			end = min(end+1, len(lines))
+			out = append(out, lines[i:end]...)
+			i = end
+		case referenceStart.MatchString(l):
+			closing := "</" + referenceStart.FindStringSubmatch(l)[1] + ">"
+			end := i + 1
+			for end < len(lines) && !strings.Contains(lines[end-1], closing) {
+				end++
+			}
+			out = append(out, indentXML(lines[i:end])...)
+			i = end
+		case strings.HasPrefix(l, "<!--"):
+			end := i + 1
+			for end < len(lines) && !strings.Contains(lines[end-1], "-->") {
+				end++
+			}
+			out = append(out, lines[i:end]...)
+			i = end
+		case strings.HasPrefix(l, "<"):
+			// HTML runs until a blank line.
+			end := i + 1
+			for end < len(lines) && strings.TrimSpace(lines[end]) != "" {
+				end++
+			}
+			out = append(out, lines[i:end]...)
+			i = end
+		case delimiterRow(l), i+1 < len(lines) && strings.Contains(l, "|") && delimiterRow(lines[i+1]):
+			delim := 1
+			if delimiterRow(l) {
+				delim = 0 // a table without a header.
+			}
+			end := i + delim + 1
+			for end < len(lines) && strings.TrimSpace(lines[end]) != "" && strings.Contains(lines[end], "|") {
+				end++
+			}
+			out = append(out, formatTable(lines[i:end], delim)...)
+			i = end
+		case !paragraphLine(l):
+			out = append(out, l)
+			i++
+		default:
+			end := i + 1
+			for end < len(lines) && paragraphLine(lines[end]) && !(end+1 < len(lines) && delimiterRow(lines[end+1])) {
+				end++
+			}
+			// a following line of ===, --- or a definition makes this a heading or a term.
+			if width == 0 || (end < len(lines) && (setext.MatchString(lines[end]) || strings.HasPrefix(lines[end], ":"))) {
+				out = append(out, lines[i:end]...)
+			} else {
+				out = append(out, wrap(lines[i:end], width)...)
+			}
+			i = end
+		}
+	}
+	return []byte(strings.Join(out, "\n") + "\n")
+}
+
+var (
+	fence          = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	referenceStart = regexp.MustCompile(`^\s*<(reference|referencegroup)[\s>]`)
+	tableDelimiter = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	setext         = regexp.MustCompile(`^\s*(=+|-+)\s*$`)
+
+	// blockStart matches the start of a line that isn't the start of a paragraph: headings, quotes,
+	// asides and notes, lists, definitions, IALs, includes, captions, footnotes, link definitions,
+	// abbreviations, math and the document divisions.
+	blockStart = regexp.MustCompile(`^(\s|#|\.#|>|[A-Z]>|[-+*]\s|[-+*]$|\d+[.)](\s|$)|:|\{|\||\$\$|%%%|` +
+		`Figure:|Table:|Quote:|\[[^\]]*\]:|\*\[|[-=_*]+\s*$)`)
+)
+
+// paragraphLine returns true if l can be a line of a paragraph that is wrapped.
+func paragraphLine(l string) bool {
+	return strings.TrimSpace(l) != "" && !blockStart.MatchString(l) && !fence.MatchString(l) && !strings.HasPrefix(l, "<") &&
+		!delimiterRow(l)
+}
+
+// closingFence returns true if l closes the code block opened with the fence marker: it has at least as
+// many of the same characters, and nothing after them.
+func closingFence(l, marker string) bool {
+	l = strings.TrimLeft(l, " ")
+	rest := strings.TrimLeft(l, marker[:1])
+	return len(l)-len(rest) >= len(marker) && strings.TrimSpace(rest) == ""
+}
+
+// delimiterRow returns true if l is the delimiter row of a table, the row with the dashes.
+func delimiterRow(l string) bool {
+	return strings.Contains(l, "|") && tableDelimiter.MatchString(l)
+}
+
+// wrap wraps the paragraph in lines at width characters. A word is never broken, nor is a line started
+// with a word that would start another block. A paragraph with a hard line break, or math, is returned
+// as is.
+func wrap(lines []string, width int) []string {
+	for _, l := range lines {
+		if strings.HasSuffix(l, "  ") || strings.HasSuffix(l, "\\") || strings.Contains(l, "$$") {
+			return lines
+		}
+	}
+	words := unbroken(fields(strings.Join(lines, " ")))
+	wrapped := []string{}
+	line, n := words[0], utf8.RuneCountInString(words[0])
+	for _, w := range words[1:] {
+		c := utf8.RuneCountInString(w)
+		if n+1+c > width && !blockStart.MatchString(w+" ") && !fence.MatchString(w) && !strings.HasPrefix(w, "<") {
+			wrapped = append(wrapped, line)
+			line, n = w, c
+			continue
+		}
+		line += " " + w
+		n += 1 + c
+	}
+	return append(wrapped, line)
+}
+
+// fields splits s in words at spaces and tabs. A code span is a single word and keeps its spacing, the
+// spaces in it are part of the code.
+func fields(s string) []string {
+	words := []string{}
+	word := &strings.Builder{}
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case ' ', '\t':
+			if word.Len() > 0 {
+				words = append(words, word.String())
+				word.Reset()
+			}
+			i++
+		case '\\':
+			end := min(i+2, len(s))
+			word.WriteString(s[i:end])
+			i = end
+		case '`':
+			n := len(s[i:]) - len(strings.TrimLeft(s[i:], "`"))
+			end := i + n
+			if c := closingBackticks(s[end:], n); c >= 0 {
+				end += c
+			}
+			word.WriteString(s[i:end])
+			i = end
+		default:
+			word.WriteByte(s[i])
+			i++
+		}
+	}
+	if word.Len() > 0 {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// closingBackticks returns the index in s just after the first run of exactly n backticks, the end of a
+// code span opened with n backticks, or -1 if there is none.
+func closingBackticks(s string, n int) int {
+	for i := 0; i < len(s); {
+		if s[i] != '`' {
+			i++
+			continue
+		}
+		m := len(s[i:]) - len(strings.TrimLeft(s[i:], "`"))
+		if m == n {
+			return i + m
+		}
+		i += m
+	}
+	return -1
+}
+
+// unbroken joins the words that must stay on one line: citations and other text in brackets, index
+// items and cross references in parentheses, and strong text, as BCP 14 keywords are only found when
+// they are on one line.
+func unbroken(words []string) []string {
+	joined := []string{}
+	cur := ""
+	for _, w := range words {
+		if cur != "" {
+			cur += " "
+		}
+		cur += w
+		i := max(strings.LastIndex(cur, "(!"), strings.LastIndex(cur, "(#"))
+		if strings.Count(cur, "[") > strings.Count(cur, "]") || strings.Count(cur, "**")%2 == 1 ||
+			(i >= 0 && !strings.Contains(cur[i:], ")")) {
+			continue
+		}
+		joined = append(joined, cur)
+		cur = ""
+	}
+	if cur != "" {
+		joined = append(joined, cur)
+	}
+	return joined
+}
+
+// formatTable aligns the columns of the pipe table in lines, the line delim is the delimiter row.
+func formatTable(lines []string, delim int) []string {
+	rows := [][]string{}
+	cols := 0
+	for i, l := range lines {
+		if i != delim && (tableDelimiter.MatchString(l) || strings.Contains(l, "===")) {
+			return lines // a block table or a table with a footer.
+		}
+		row := cells(l)
+		rows = append(rows, row)
+		cols = max(cols, len(row))
+	}
+
+	align := make([]string, cols)
+	widths := make([]int, cols)
+	for c := range widths {
+		widths[c] = 3
+	}
+	for c, cell := range rows[delim] {
+		switch {
+		case strings.HasPrefix(cell, ":") && strings.HasSuffix(cell, ":"):
+			align[c] = "center"
+		case strings.HasSuffix(cell, ":"):
+			align[c] = "right"
+		case strings.HasPrefix(cell, ":"):
+			align[c] = "left"
+		}
+	}
+	for i, row := range rows {
+		if i == delim {
+			continue
+		}
+		for c, cell := range row {
+			widths[c] = max(widths[c], utf8.RuneCountInString(cell))
+		}
+	}
+
+	out := make([]string, len(rows))
+	for i, row := range rows {
+		b := &strings.Builder{}
+		b.WriteString("|")
+		for c := 0; c < cols; c++ {
+			cell := ""
+			if c < len(row) {
+				cell = row[c]
+			}
+			b.WriteString(" ")
+			if i == delim {
+				b.WriteString(delimiter(align[c], widths[c]))
+			} else {
+				b.WriteString(pad(cell, align[c], widths[c]))
+			}
+			b.WriteString(" |")
+		}
+		out[i] = b.String()
+	}
+	return out
+}
+
+// cells returns the trimmed cells of a table row, pipes in code spans or escaped with a backslash don't
+// separate cells.
+func cells(l string) []string {
+	l = strings.TrimSpace(l)
+	l = strings.TrimPrefix(l, "|")
+	if strings.HasSuffix(l, "|") && !strings.HasSuffix(l, "\\|") {
+		l = l[:len(l)-1]
+	}
+	row := []string{}
+	code := false
+	start := 0
+	for i := 0; i < len(l); i++ {
+		switch l[i] {
+		case '\\':
+			i++
+		case '`':
+			code = !code
+		case '|':
+			if !code {
+				row = append(row, strings.TrimSpace(l[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(row, strings.TrimSpace(l[start:]))
+}
+
+func delimiter(align string, width int) string {
+	switch align {
+	case "center":
+		return ":" + strings.Repeat("-", width-2) + ":"
+	case "right":
+		return strings.Repeat("-", width-1) + ":"
+	case "left":
+		return ":" + strings.Repeat("-", width-1)
+	}
+	return strings.Repeat("-", width)
+}
+
+func pad(cell, align string, width int) string {
+	n := width - utf8.RuneCountInString(cell)
+	switch align {
+	case "center":
+		return strings.Repeat(" ", n/2) + cell + strings.Repeat(" ", n-n/2)
+	case "right":
+		return strings.Repeat(" ", n) + cell
+	}
+	return cell + strings.Repeat(" ", n)
+}
+
+// indentXML indents the XML of a reference block with two spaces for every level of nesting.
+func indentXML(lines []string) []string {
+	out := make([]string, len(lines))
+	depth := 0
+	for i, l := range lines {
+		l = strings.TrimSpace(l)
+		if strings.HasPrefix(l, "</") {
+			depth = max(depth-1, 0)
+		}
+		out[i] = strings.Repeat("  ", depth) + l
+		if l == "" {
+			out[i] = ""
+		}
+		if strings.HasPrefix(l, "<") && !strings.HasPrefix(l, "</") && !strings.HasPrefix(l, "<!--") &&
+			!strings.HasSuffix(l, "/>") && !strings.Contains(l, "</") {
+			depth++
+		}
+	}
+	return out
+}
@@ -0,0 +1,58 @@
+package mparser
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		in, out string
+	}{
+		{
+			"Mmark is a markdown processor,\nwritten in Go,   geared towards writing IETF documents.\n",
+			"Mmark is a markdown processor, written\nin Go, geared towards writing IETF\ndocuments.\n",
+		},
+		{
+			// citations and BCP 14 keywords stay on one line, as does a list marker.
+			"Clients **MUST NOT** send this [@RFC2119; @RFC8174] as in RFC 2119 or\n- not\n",
+			"Clients **MUST NOT** send this\n[@RFC2119; @RFC8174] as in RFC 2119 or\n- not\n",
+		},
+		{
+			"| Name|Value |\n|:--|--:|\n|a `|` pipe|1|\n| long name | 100 |\n",
+			"| Name       | Value |\n| :--------- | ----: |\n| a `|` pipe |     1 |\n| long name  |   100 |\n",
+		},
+		{
+			"----|:-:\nA|B\n",
+			"| --- | :-: |\n| A   |  B  |\n",
+		},
+		{
+			"<reference anchor=\"A\" target=\"https://example.org\">\n<front>\n      <title>A</title>\n<author fullname=\"B\"/>\n</front>\n</reference>\n",
+			"<reference anchor=\"A\" target=\"https://example.org\">\n  <front>\n    <title>A</title>\n    <author fullname=\"B\"/>\n  </front>\n</reference>\n",
+		},
+		{
+			"%%%\ntitle = \"A very long title that is not wrapped at all, because it is the title block\"\n%%%\n\n~~~\ncode   that is\nnot wrapped\n~~~\n",
+			"%%%\ntitle = \"A very long title that is not wrapped at all, because it is the title block\"\n%%%\n\n~~~\ncode   that is\nnot wrapped\n~~~\n",
+		},
+		{
+			// the spacing in code spans is kept, and a code span isn't broken over two lines.
+			"Use `a   b` and ``c ` d`` or\n`e\tf` in the text, \\`not code`.\n",
+			"Use `a   b` and ``c ` d`` or `e\tf` in\nthe text, \\`not code`.\n",
+		},
+		{
+			// a ``` line doesn't close a block opened with ````.
+			"````\n```\nnot   the end\n````\n\nText\nafter.\n",
+			"````\n```\nnot   the end\n````\n\nText after.\n",
+		},
+		{
+			"A heading that is followed by a line\nof dashes\n---\n",
+			"A heading that is followed by a line\nof dashes\n---\n",
+		},
+	}
+	for i, tc := range tests {
+		out := Format([]byte(tc.in), 38)
+		if string(out) != tc.out {
+			t.Errorf("test %d: expected\n%s\ngot\n%s", i, tc.out, out)
+		}
+		if again := Format(out, 38); string(again) != string(out) {
+			t.Errorf("test %d: expected the same output when formatted again, got\n%s", i, again)
+		}
+	}
+}
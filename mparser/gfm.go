@@ -0,0 +1,132 @@
+package mparser
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+var (
+	gfmTask  = regexp.MustCompile(`^\[([ xX])\]\s+`)
+	gfmLinks = regexp.MustCompile(`\bwww\.[\w-]+(?:\.[\w-]+)+[^\s<]*|[\w.+-]+@[\w-]+(?:\.[\w-]+)+`)
+)
+
+// AddGFM makes doc follow GitHub Flavored Markdown where mmark differs from it: a list item starting
+// with "[ ]" or "[x]" is a task, ~text~ is struck through instead of a subscript, and
+// www.example.org and e-mail addresses are links. With html the tasks get a checkbox, otherwise a
+// ballot box: ☐ or ☒.
+func AddGFM(doc ast.Node, html bool) {
+	texts := []*ast.Text{}
+	subs := []*ast.Subscript{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch n := node.(type) {
+		case *ast.Link, *ast.Image, *ast.Code, *ast.CodeBlock, *mast.Title:
+			return ast.SkipChildren
+		case *ast.ListItem:
+			addTask(n, html)
+		case *ast.Subscript:
+			subs = append(subs, n)
+		case *ast.Text:
+			if gfmLinks.Match(n.Literal) {
+				texts = append(texts, n)
+			}
+		}
+		return ast.GoToNext
+	})
+
+	for _, s := range subs {
+		del := &ast.Del{}
+		ast.AppendChild(del, &ast.Text{Leaf: ast.Leaf{Literal: s.Literal}})
+		replace(s, del)
+	}
+
+	for _, t := range texts {
+		nodes := []ast.Node{}
+		prev := 0
+		for _, m := range gfmLinks.FindAllIndex(t.Literal, -1) {
+			text := gfmTrim(t.Literal[m[0]:m[1]])
+			if len(text) == 0 {
+				continue
+			}
+			if m[0] > prev {
+				nodes = append(nodes, &ast.Text{Leaf: ast.Leaf{Literal: t.Literal[prev:m[0]]}})
+			}
+			dest := append([]byte("mailto:"), text...)
+			if bytes.HasPrefix(text, []byte("www.")) {
+				dest = append([]byte("http://"), text...)
+			}
+			link := &ast.Link{Destination: dest}
+			ast.AppendChild(link, &ast.Text{Leaf: ast.Leaf{Literal: text}})
+			nodes = append(nodes, link)
+			prev = m[0] + len(text)
+		}
+		if prev == 0 {
+			continue
+		}
+		if prev < len(t.Literal) {
+			nodes = append(nodes, &ast.Text{Leaf: ast.Leaf{Literal: t.Literal[prev:]}})
+		}
+		replaceText(t, nodes)
+	}
+}
+
+// addTask turns item into a task if its text starts with "[ ]" or "[x]".
+func addTask(item *ast.ListItem, html bool) {
+	if item.ListFlags&(ast.ListTypeDefinition|ast.ListTypeTerm) != 0 || len(item.Children) == 0 {
+		return
+	}
+	para, ok := item.Children[0].(*ast.Paragraph)
+	if !ok || len(para.Children) == 0 {
+		return
+	}
+	text, ok := para.Children[0].(*ast.Text)
+	if !ok {
+		return
+	}
+	m := gfmTask.FindSubmatch(text.Literal)
+	if m == nil {
+		return
+	}
+	done := m[1][0] != ' '
+	text.Literal = text.Literal[len(m[0]):]
+
+	var box ast.Node = &ast.Text{Leaf: ast.Leaf{Literal: []byte("☐ ")}}
+	if done {
+		box = &ast.Text{Leaf: ast.Leaf{Literal: []byte("☒ ")}}
+	}
+	if html {
+		checkbox := `<input type="checkbox" class="task-list-item-checkbox" disabled="" />`
+		if done {
+			checkbox = `<input type="checkbox" class="task-list-item-checkbox" checked="" disabled="" />`
+		}
+		box = &ast.HTMLSpan{Leaf: ast.Leaf{Literal: []byte(checkbox + " ")}}
+	}
+	box.SetParent(para)
+	para.Children = append([]ast.Node{box}, para.Children...)
+
+	if list, ok := item.Parent.(*ast.List); ok && !mast.AttributeClass(list, "contains-task-list") {
+		mast.AttributeInit(list)
+		list.Attribute.Classes = append(list.Attribute.Classes, []byte("contains-task-list"))
+	}
+}
+
+// gfmTrim removes the trailing punctuation from an autolink, and a closing parenthesis that isn't
+// matched by an opening one in the link.
+func gfmTrim(link []byte) []byte {
+	for len(link) > 0 {
+		switch c := link[len(link)-1]; {
+		case bytes.IndexByte([]byte(`?!.,:*_~'"`), c) >= 0:
+			link = link[:len(link)-1]
+		case c == ')' && bytes.Count(link, []byte("(")) < bytes.Count(link, []byte(")")):
+			link = link[:len(link)-1]
+		default:
+			return link
+		}
+	}
+	return link
+}
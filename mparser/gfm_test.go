@@ -0,0 +1,43 @@
+package mparser
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+func TestAddGFM(t *testing.T) {
+	const doc = `* [ ] todo
+* [x] done
+
+~~gone~~, ~one~ and www.example.org/a_(b). Mail me@example.org, or see https://example.org.
+`
+	p := parser.NewWithExtensions(Extensions)
+	d := markdown.Parse([]byte(doc), p)
+	AddGFM(d, true)
+	out := markdown.Render(d, html.NewRenderer(html.RendererOptions{}))
+
+	for _, want := range []string{
+		`<ul class="contains-task-list">`,
+		`<li><input type="checkbox" class="task-list-item-checkbox" disabled="" /> todo</li>`,
+		`<li><input type="checkbox" class="task-list-item-checkbox" checked="" disabled="" /> done</li>`,
+		`<del>gone</del>, <del>one</del>`,
+		`<a href="http://www.example.org/a_(b)">www.example.org/a_(b)</a>.`,
+		`<a href="mailto:me@example.org">me@example.org</a>, or`,
+		`<a href="https://example.org">https://example.org</a>.`,
+	} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Errorf("expected %s, got %s", want, out)
+		}
+	}
+
+	d = markdown.Parse([]byte(doc), parser.NewWithExtensions(Extensions))
+	AddGFM(d, false)
+	out = markdown.Render(d, html.NewRenderer(html.RendererOptions{}))
+	if !bytes.Contains(out, []byte("<li>☐ todo</li>")) {
+		t.Errorf("expected a ballot box, got %s", out)
+	}
+}
@@ -0,0 +1,154 @@
+package mparser
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// GlossaryClass is the class of a definition list whose terms are put in the glossary.
+const GlossaryClass = "glossary"
+
+type glossaryEntry struct {
+	term  string
+	items []*ast.ListItem // the term and its definitions
+}
+
+// AddGlossary replaces the {glossary} placeholder paragraph in doc with a definition list of the terms
+// defined in the definition lists with the class GlossaryClass, sorted on the term. These lists are
+// moved into the glossary. Each definition gets a cross reference to the section where the term is
+// first used. If there is no placeholder, doc is left alone.
+func AddGlossary(doc ast.Node) {
+	var placeholder *ast.Paragraph
+	lists := []*ast.List{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch n := node.(type) {
+		case *ast.Paragraph:
+			if t, ok := ast.GetFirstChild(n).(*ast.Text); ok && len(n.Children) == 1 && string(bytes.TrimSpace(t.Literal)) == "{glossary}" {
+				placeholder = n
+			}
+			return ast.SkipChildren
+		case *ast.List:
+			if n.ListFlags&ast.ListTypeDefinition != 0 && hasClass(n, GlossaryClass) {
+				lists = append(lists, n)
+				return ast.SkipChildren
+			}
+		}
+		return ast.GoToNext
+	})
+	if placeholder == nil {
+		return
+	}
+
+	entries := []*glossaryEntry{}
+	tight := true
+	for _, l := range lists {
+		tight = tight && l.Tight
+		for _, c := range l.Children {
+			item, ok := c.(*ast.ListItem)
+			if !ok {
+				continue
+			}
+			if item.ListFlags&ast.ListTypeTerm != 0 {
+				entries = append(entries, &glossaryEntry{term: strings.TrimSpace(plainText(item))})
+			}
+			if len(entries) > 0 {
+				e := entries[len(entries)-1]
+				e.items = append(e.items, item)
+			}
+		}
+		ast.RemoveFromTree(l)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return strings.ToLower(entries[i].term) < strings.ToLower(entries[j].term) })
+
+	uses := firstUses(doc, entries)
+	glossary := &ast.List{ListFlags: ast.ListTypeDefinition, Tight: tight}
+	for _, e := range entries {
+		for _, item := range e.items {
+			item.SetParent(nil)
+			ast.AppendChild(glossary, item)
+		}
+		id, ok := uses[e.term]
+		if !ok || len(e.items) < 2 {
+			continue
+		}
+		para, ok := ast.GetLastChild(e.items[len(e.items)-1]).(*ast.Paragraph)
+		if !ok {
+			continue
+		}
+		ast.AppendChild(para, &ast.Text{Leaf: ast.Leaf{Literal: []byte(" (")}})
+		ast.AppendChild(para, &ast.CrossReference{Destination: []byte(id)})
+		ast.AppendChild(para, &ast.Text{Leaf: ast.Leaf{Literal: []byte(")")}})
+	}
+	replace(placeholder, glossary)
+}
+
+// firstUses returns the ID of the section where each term of entries is first used in the text of doc.
+func firstUses(doc ast.Node, entries []*glossaryEntry) map[string]string {
+	terms := map[string]*regexp.Regexp{}
+	for _, e := range entries {
+		terms[e.term] = regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(e.term) + `\b`)
+	}
+	uses := map[string]string{}
+	section := ""
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch n := node.(type) {
+		case *ast.Heading:
+			section = n.HeadingID
+			if a := mast.AttributeFromNode(n); a != nil && len(a.ID) > 0 {
+				section = string(a.ID)
+			}
+			return ast.SkipChildren
+		case *ast.Link, *mast.Title:
+			return ast.SkipChildren
+		case *ast.Text:
+			if section == "" {
+				break
+			}
+			for term, re := range terms {
+				if re.Match(n.Literal) {
+					uses[term] = section
+					delete(terms, term)
+				}
+			}
+		}
+		return ast.GoToNext
+	})
+	return uses
+}
+
+// hasClass returns true if node has the class c in its attribute.
+func hasClass(node ast.Node, c string) bool {
+	a := mast.AttributeFromNode(node)
+	if a == nil {
+		return false
+	}
+	for _, class := range a.Classes {
+		if string(class) == c {
+			return true
+		}
+	}
+	return false
+}
+
+// plainText returns the text of the leaves under node.
+func plainText(node ast.Node) string {
+	b := &strings.Builder{}
+	ast.WalkFunc(node, func(n ast.Node, entering bool) ast.WalkStatus {
+		if l := n.AsLeaf(); l != nil && entering {
+			b.Write(l.Literal)
+		}
+		return ast.GoToNext
+	})
+	return b.String()
+}
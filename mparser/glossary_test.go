@@ -0,0 +1,52 @@
+package mparser
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/render/xml"
+)
+
+func TestAddGlossary(t *testing.T) {
+	const doc = `# Intro
+
+Nothing here.
+
+# Protocol
+
+A PDU is sent by a client.
+
+{.glossary}
+PDU
+:   Protocol data unit.
+
+Client
+:   The *initiator*.
+
+# Glossary
+
+{glossary}
+`
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook}
+	d := markdown.Parse([]byte(doc), p)
+	AddGlossary(d)
+	out := markdown.Render(d, xml.NewRenderer(xml.RendererOptions{Flags: xml.CommonFlags | xml.XMLFragment}))
+
+	const want = `<section anchor="glossary"><name>Glossary</name>
+
+<dl spacing="compact">
+<dt>Client</dt>
+<dd>The <em>initiator</em>. (<xref target="protocol"></xref>)</dd>
+<dt>PDU</dt>
+<dd>Protocol data unit. (<xref target="protocol"></xref>)</dd>
+</dl>`
+	if !bytes.Contains(out, []byte(want)) {
+		t.Errorf("expected %s in:\n%s", want, out)
+	}
+	if bytes.Count(out, []byte("<dl")) != 1 || bytes.Contains(out, []byte("{glossary}")) {
+		t.Errorf("expected the definitions to be moved to the glossary, got:\n%s", out)
+	}
+}
@@ -0,0 +1,274 @@
+package mparser
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+var (
+	gridSeparator = regexp.MustCompile(`^\+(?:[-=:]+\+)+[ \t]*$`)
+	gridCaptionID = regexp.MustCompile(`\{#([^}\s]+)\}\s*$`)
+)
+
+// GridTableHook parses a grid table, where the cells can contain block level elements, such as lists,
+// multiple paragraphs and code:
+//
+//	+--------+------------------------+
+//	| Name   | Description            |
+//	+========+========================+
+//	| flags  | The flags:             |
+//	|        |                        |
+//	|        | * A: answer            |
+//	|        | * Q: query             |
+//	+--------+------------------------+
+//
+// The rows are separated by lines of '-', the optional header is separated from the body by a line of
+// '='. A ':' at the start and/or end of a column in that line (or the first line) sets the alignment.
+// A cell spans columns when the '|' between them is missing. Like other tables a grid table can be
+// followed by a "Table: " caption.
+func GridTableHook(data []byte) (ast.Node, []byte, int) {
+	if len(data) < 2 || data[0] != '+' {
+		return nil, nil, 0
+	}
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	if !gridSeparator.Match(bytes.TrimRight(lines[0], "\n")) {
+		return nil, nil, 0
+	}
+
+	first := []rune(strings.TrimRight(string(lines[0]), " \t\n"))
+	bounds := []int{}
+	for i, r := range first {
+		if r == '+' {
+			bounds = append(bounds, i)
+		}
+	}
+	align := gridAlign(first, bounds)
+
+	var (
+		rows     [][][]rune
+		row      [][]rune
+		header   = 0
+		consumed = len(lines[0])
+		end      = consumed // end of the last separator line
+	)
+	for _, line := range lines[1:] {
+		l := []rune(strings.TrimRight(string(line), " \t\n"))
+		if len(l) == 0 || (l[0] != '+' && l[0] != '|') {
+			break
+		}
+		consumed += len(line)
+		if l[0] == '|' {
+			row = append(row, l)
+			continue
+		}
+		if !gridSeparator.MatchString(string(l)) || row == nil {
+			return nil, nil, 0
+		}
+		rows = append(rows, row)
+		row = nil
+		end = consumed
+		if strings.ContainsRune(string(l), '=') && header == 0 {
+			header = len(rows)
+			align = gridAlign(l, bounds)
+		}
+	}
+	if len(rows) == 0 || row != nil {
+		return nil, nil, 0
+	}
+
+	table := &ast.Table{}
+	var body ast.Node = &ast.TableBody{}
+	if header > 0 {
+		body = &ast.TableHeader{}
+	}
+	ast.AppendChild(table, body)
+	for i, r := range rows {
+		if i == header && header > 0 {
+			body = &ast.TableBody{}
+			ast.AppendChild(table, body)
+		}
+		ast.AppendChild(body, gridRow(r, bounds, align, i < header))
+	}
+
+	caption, id, n := gridCaption(data[end:])
+	if n == 0 {
+		return table, []byte{}, end
+	}
+	figure := &ast.CaptionFigure{}
+	figure.HeadingID = id
+	ast.AppendChild(figure, table)
+	ast.AppendChild(figure, caption)
+	return figure, []byte{}, end + n
+}
+
+// gridAlign returns the alignment of the columns set in the separator line l.
+func gridAlign(l []rune, bounds []int) []ast.CellAlignFlags {
+	align := make([]ast.CellAlignFlags, len(bounds)-1)
+	for i := range align {
+		if bounds[i+1] >= len(l) {
+			break
+		}
+		if l[bounds[i]+1] == ':' {
+			align[i] |= ast.TableAlignmentLeft
+		}
+		if l[bounds[i+1]-1] == ':' {
+			align[i] |= ast.TableAlignmentRight
+		}
+	}
+	return align
+}
+
+// gridRow returns the table row for the lines of a grid table row.
+func gridRow(lines [][]rune, bounds []int, align []ast.CellAlignFlags, header bool) *ast.TableRow {
+	row := &ast.TableRow{}
+	start := 0
+	for col := 1; col < len(bounds); col++ {
+		// a cell continues into the next column if the '|' between them is missing.
+		if col < len(bounds)-1 && !gridBound(lines, bounds[col]) {
+			continue
+		}
+		cell := &ast.TableCell{IsHeader: header, Align: align[start]}
+		if span := col - start; span > 1 {
+			cell.ColSpan = span
+		}
+		text := &bytes.Buffer{}
+		for _, l := range lines {
+			from, to := bounds[start]+1, bounds[col]
+			if from > len(l) {
+				from = len(l)
+			}
+			if to > len(l) {
+				to = len(l)
+			}
+			text.WriteString(strings.TrimRight(string(l[from:to]), " \t"))
+			text.WriteByte('\n')
+		}
+		for _, c := range gridContent(dedent(text.Bytes())) {
+			ast.AppendChild(cell, c)
+		}
+		ast.AppendChild(row, cell)
+		start = col
+	}
+	return row
+}
+
+// gridBound returns true if all lines have a '|' at position b.
+func gridBound(lines [][]rune, b int) bool {
+	for _, l := range lines {
+		if b >= len(l) || l[b] != '|' {
+			return false
+		}
+	}
+	return true
+}
+
+// gridContent parses the text of a cell, a single paragraph is returned as its inline elements.
+// The cell is parsed on its own, footnotes and index entries in it are not supported.
+func gridContent(text []byte) []ast.Node {
+	p := parser.NewWithExtensions(Extensions)
+	RegisterInlines(p)
+	doc := p.Parse(text)
+	children := doc.GetChildren()
+	if len(children) == 1 {
+		if para, ok := children[0].(*ast.Paragraph); ok {
+			children = para.GetChildren()
+		}
+	}
+	for _, c := range children {
+		c.SetParent(nil)
+	}
+	return children
+}
+
+// gridCaption parses the "Table: " caption following a grid table, it returns the caption, its ID
+// and the number of bytes consumed.
+func gridCaption(data []byte) (*ast.Caption, string, int) {
+	if !bytes.HasPrefix(data, []byte("Table: ")) {
+		return nil, "", 0
+	}
+	n := len(data)
+	if i := bytes.Index(data, []byte("\n\n")); i > 0 {
+		n = i + 1
+	}
+	text := bytes.TrimSpace(data[len("Table: "):n])
+	id := ""
+	if m := gridCaptionID.FindSubmatchIndex(text); m != nil {
+		id = string(text[m[2]:m[3]])
+		text = bytes.TrimSpace(text[:m[0]])
+	}
+	caption := &ast.Caption{}
+	for _, c := range gridContent(text) {
+		ast.AppendChild(caption, c)
+	}
+	return caption, id, n
+}
+
+// FlattenTableCells replaces the block level elements in the table cells of doc with their inline
+// elements, for outputs that can only have inline elements in a table cell. The blocks are separated
+// with a space, list items start with a bullet or their number.
+func FlattenTableCells(doc ast.Node) {
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		cell, ok := node.(*ast.TableCell)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		if mast.BlockCell(cell) {
+			inlines := flatten(cell.Children)
+			cell.Children = nil
+			for _, n := range inlines {
+				n.SetParent(nil)
+				ast.AppendChild(cell, n)
+			}
+		}
+		return ast.SkipChildren
+	})
+}
+
+// flatten returns the inline elements of blocks.
+func flatten(blocks []ast.Node) []ast.Node {
+	inlines := []ast.Node{}
+	space := func() {
+		if len(inlines) > 0 {
+			inlines = append(inlines, &ast.Text{Leaf: ast.Leaf{Literal: []byte(" ")}})
+		}
+	}
+	for _, b := range blocks {
+		switch b := b.(type) {
+		case *ast.Paragraph, *ast.Heading:
+			space()
+			inlines = append(inlines, b.GetChildren()...)
+		case *ast.CodeBlock:
+			space()
+			inlines = append(inlines, &ast.Code{Leaf: ast.Leaf{Literal: bytes.TrimSpace(b.Literal)}})
+		case *ast.List:
+			start := b.Start
+			if start == 0 {
+				start = 1
+			}
+			for i, item := range b.Children {
+				space()
+				marker := "• "
+				if b.ListFlags&ast.ListTypeOrdered != 0 {
+					marker = strconv.Itoa(start+i) + ". "
+				}
+				inlines = append(inlines, &ast.Text{Leaf: ast.Leaf{Literal: []byte(marker)}})
+				inlines = append(inlines, flatten(item.GetChildren())...)
+			}
+		case *ast.HorizontalRule:
+		default:
+			if b.AsLeaf() != nil {
+				inlines = append(inlines, b)
+				continue
+			}
+			space()
+			inlines = append(inlines, flatten(b.GetChildren())...)
+		}
+	}
+	return inlines
+}
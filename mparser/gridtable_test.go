@@ -0,0 +1,56 @@
+package mparser
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/render/xml"
+)
+
+const gridTable = `+-------+-------------------+
+| Name  | Description       |
++=======+==================:+
+| flags | The flags:        |
+|       |                   |
+|       | * A: answer       |
++-------+-------------------+
+| both columns              |
++-------+-------------------+
+Table: Parameters
+
+After.
+`
+
+func TestGridTableHook(t *testing.T) {
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook}
+	d := markdown.Parse([]byte(gridTable), p)
+	out := markdown.Render(d, xml.NewRenderer(xml.RendererOptions{Flags: xml.CommonFlags | xml.XMLFragment}))
+
+	for _, want := range []string{
+		`<table><name>Parameters</name>`,
+		`<th align="right">Description</th>`,
+		`<td align="right"><t>The flags:</t>` + "\n\n" + `<ul spacing="compact">` + "\n" + `<li>A: answer</li>`,
+		`<td colspan="2">both columns</td>`,
+		`<t>After.</t>`,
+	} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Errorf("expected %s, got %s", want, out)
+		}
+	}
+}
+
+func TestFlattenTableCells(t *testing.T) {
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook}
+	d := markdown.Parse([]byte(gridTable), p)
+	FlattenTableCells(d)
+	out := markdown.Render(d, xml.NewRenderer(xml.RendererOptions{Flags: xml.CommonFlags | xml.XMLFragment}))
+
+	const want = `<td align="right">The flags: <u format="char-num">•</u> A: answer</td>`
+	if !bytes.Contains(out, []byte(want)) {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+}
@@ -1,9 +1,9 @@
 package mparser
 
 import (
-	"io/ioutil"
-	"log"
-	"path/filepath"
+	"bytes"
+	"strconv"
+	"strings"
 
 	"github.com/gomarkdown/markdown/ast"
 	"github.com/gomarkdown/markdown/parser"
@@ -11,14 +11,26 @@ import (
 
 var UnsafeInclude parser.Flags = 1 << 3
 
-// Hook will call both TitleHook and ReferenceHook.
-func Hook(data []byte) (ast.Node, []byte, int) {
-	n, b, i := TitleHook(data)
+// Hook will call TitleHook, ReferenceHook, GridTableHook and DivHook.
+func Hook(data []byte) (ast.Node, []byte, int) { return Initial{}.Hook(data) }
+
+// Hook is Hook for the document of i, a title block that fails to parse is reported with i.Report.
+func (i Initial) Hook(data []byte) (ast.Node, []byte, int) {
+	n, b, c := titleHook(data, i.Report)
+	if n != nil {
+		return n, b, c
+	}
+	n, b, c = ReferenceHook(data)
+	if n != nil {
+		return n, b, c
+	}
+
+	n, b, c = GridTableHook(data)
 	if n != nil {
-		return n, b, i
+		return n, b, c
 	}
 
-	return ReferenceHook(data)
+	return DivHook(data)
 }
 
 // ReadInclude is the hook to read includes.
@@ -28,27 +40,162 @@ func Hook(data []byte) (ast.Node, []byte, int) {
 // N, - line numbers, end not specified, read until the end.
 // /start/,/end/ - regexp separated by commas
 // optional a prefix="" string.
+// optional a shift=N, which demotes the headings by N levels.
+// optional a snippet=name, which selects the lines between the "snippet: name" and "end snippet" markers.
+// optional a if=name, which only includes the file when name is defined, see Initial.Defines.
+// optional a dedent, which removes the indentation the lines have in common, before the prefix is added.
+// optional a header="" and footer="" string, lines added before and after the file, \n starts a new line.
+// optional parameters, name="foo", ref="RFC9000", that replace ${name} and $1 in the file.
+//
+// The file can be an https URL, which is fetched with i's Fetcher. Relative includes in such a
+// remote file are fetched relative to its URL.
+//
+// The file can be read from a git revision of the repository: git:v1.2.0:protocol.md. Relative
+// includes in such a file are read from the same revision.
+//
+// The file can also be a glob pattern, chapters/*.md, or a directory, chapters/, which includes all
+// its *.md files. The matching files are included in lexical order, unless the directory has a
+// manifest, see Manifest. The address is applied to each file.
 func (i Initial) ReadInclude(from, file string, address []byte) []byte {
+	cond, address := addressOption(address, "if")
+	if cond != "" && !i.defined(cond) {
+		return nil
+	}
 	path := i.path(from, file)
-
-	if i.Flags&UnsafeInclude == 0 {
-		if ok := i.pathAllowed(path); !ok {
-			log.Printf("Failure to read: %q: path is not on or below %q", path, i.i)
+	if u, ok := remote(from, file); ok {
+		path = u
+	} else if rev, gitFrom, gitFile, ok := revision(from, file); ok {
+		path = GitPrefix + rev + ":" + i.path(gitFrom, gitFile)
+	}
+	if i.chain != nil {
+		if i.chain.files[0].data == nil {
+			i.chain.files[0].data = i.Source
+		}
+		if !i.chain.enter(from, file, path) {
+			i.failf("include cycle: %q includes itself", displayName(from, file))
 			return nil
 		}
 	}
+	data := i.read(from, file, address)
+	if data != nil && i.chain != nil {
+		i.chain.push(from, file, path, data)
+	}
+	return data
+}
 
-	data, err := ioutil.ReadFile(path)
+// read reads the include file, see ReadInclude.
+func (i Initial) read(from, file string, address []byte) []byte {
+	var (
+		data []byte
+		err  error
+	)
+	path := i.path(from, file)
+	u, isRemote := remote(from, file)
+	rev, gitFrom, gitFile, isGit := revision(from, file)
+	if !isRemote && !isGit && i.isGlob(path) {
+		files, err := i.glob(path)
+		if err != nil {
+			i.failf("failure to read %q: %s", displayName(from, file), err)
+			return nil
+		}
+		buf := &bytes.Buffer{}
+		for _, f := range files {
+			if i.FS != nil {
+				f = "/" + f // relative to the root of FS
+			}
+			data := i.read(from, f, address)
+			if data == nil {
+				return nil
+			}
+			if buf.Len() > 0 {
+				buf.WriteByte('\n') // the files must not run into each other.
+			}
+			buf.Write(data)
+		}
+		return buf.Bytes()
+	}
+	switch {
+	case isRemote:
+		path = u
+		if i.Fetcher == nil {
+			i.failf("failure to read %q: remote includes are not allowed", u)
+			return nil
+		}
+		data, err = i.Fetcher.Include(u)
+	case isGit:
+		path = i.path(gitFrom, gitFile)
+		if i.Flags&UnsafeInclude == 0 && !i.pathAllowed(path) {
+			i.failf("failure to read %q: path is not on or below %q", path, i.i)
+			return nil
+		}
+		if i.FS != nil {
+			i.failf("failure to read %q: git includes are not supported", file)
+			return nil
+		}
+		data, err = gitShow(rev, path)
+	default:
+		if i.Flags&UnsafeInclude == 0 && !i.pathAllowed(path) {
+			i.failf("failure to read %q: path is not on or below %q", path, i.i)
+			return nil
+		}
+		data, err = i.readFile(path)
+	}
 	if err != nil {
-		log.Printf("Failure to read: %q (from %q)", err, filepath.Join(from, "*"))
+		i.failf("failure to read %q: %s", displayName(from, file), err)
 		return nil
 	}
+	if i.Record != nil {
+		if isGit {
+			i.Record(GitPrefix + rev + ":" + path)
+		} else {
+			i.Record(path)
+		}
+	}
 
+	shift, address := addressOption(address, "shift")
+	name, address := addressOption(address, "snippet")
+	prefix, address := addressOption(address, "prefix")
+	header, address := addressOption(address, "header")
+	footer, address := addressOption(address, "footer")
+	dedented, address := addressOption(address, "dedent")
+	params, address := templateParameters(address)
+	if len(params) > 0 {
+		data = expandParameters(data, params)
+	}
+	if name != "" {
+		if data, err = snippet(data, name); err != nil {
+			i.failf("failure to parse address for %q: %s", displayName(from, file), err)
+			return nil
+		}
+	}
 	data, err = parseAddress(address, data)
 	if err != nil {
-		log.Printf("Failure to parse address for %q: %q (from %q)", path, err, filepath.Join(from, "*"))
+		i.failf("failure to parse address for %q: %s", displayName(from, file), err)
 		return nil
 	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		data = append(data, '\n')
+	}
+	if dedented == "true" {
+		data = dedent(data)
+	}
+	if prefix != "" && len(data) > 0 {
+		data = append(addPrefix(data, []byte(prefix)), '\n')
+	}
+	if header != "" {
+		data = append([]byte(strings.ReplaceAll(header, `\n`, "\n")+"\n"), data...)
+	}
+	if footer != "" {
+		data = append(data, strings.ReplaceAll(footer, `\n`, "\n")+"\n"...)
+	}
+	if shift != "" {
+		n, err := strconv.Atoi(shift)
+		if err != nil || n < 0 {
+			i.failf("failure to parse address for %q: invalid shift %q", displayName(from, file), shift)
+			return nil
+		}
+		data = shiftHeadings(data, n)
+	}
 	if len(data) == 0 {
 		return data
 	}
@@ -0,0 +1,82 @@
+package mparser
+
+import (
+	"bytes"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// ImageAttributes are the attributes of a block that are moved to the images in it.
+var ImageAttributes = []string{"width", "height", "alt", "align"}
+
+// AddImageAttributes moves the ImageAttributes of a paragraph that only contains images, or of a figure
+// with images, to these images, "alt" replaces the text of the images. This allows setting them with a
+// block level attribute:
+//
+//	{width="80%" alt="The protocol stack"}
+//	![](stack.svg)
+func AddImageAttributes(doc ast.Node) {
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch n := node.(type) {
+		case *ast.Paragraph:
+			if images := onlyImages(n); len(images) > 0 {
+				moveImageAttributes(n, images)
+			}
+			return ast.SkipChildren
+		case *ast.CaptionFigure:
+			images := []*ast.Image{}
+			for _, c := range n.Children {
+				if p, ok := c.(*ast.Paragraph); ok {
+					images = append(images, onlyImages(p)...)
+				}
+			}
+			moveImageAttributes(n, images)
+		}
+		return ast.GoToNext
+	})
+}
+
+// onlyImages returns the images in p if it contains nothing else, apart from white space.
+func onlyImages(p *ast.Paragraph) []*ast.Image {
+	images := []*ast.Image{}
+	for _, c := range p.Children {
+		switch c := c.(type) {
+		case *ast.Image:
+			images = append(images, c)
+		case *ast.Text:
+			if len(bytes.TrimSpace(c.Literal)) > 0 {
+				return nil
+			}
+		default:
+			return nil
+		}
+	}
+	return images
+}
+
+func moveImageAttributes(node ast.Node, images []*ast.Image) {
+	if len(images) == 0 {
+		return
+	}
+	for _, key := range ImageAttributes {
+		value := mast.Attribute(node, key)
+		if value == nil {
+			continue
+		}
+		for _, img := range images {
+			// the alt text is the text of the image, that is what the renderers use.
+			if key == "alt" {
+				img.Children = nil
+				ast.AppendChild(img, &ast.Text{Leaf: ast.Leaf{Literal: value}})
+				continue
+			}
+			mast.AttributeInit(img)
+			mast.SetAttribute(img, key, value)
+		}
+		mast.DeleteAttribute(node, key)
+	}
+}
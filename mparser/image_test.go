@@ -0,0 +1,32 @@
+package mparser
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/render/xml"
+)
+
+func TestAddImageAttributes(t *testing.T) {
+	const doc = `{width="80%" alt="The protocol stack"}
+![](stack.svg)
+
+![](noalt.svg)
+`
+	p := parser.NewWithExtensions(Extensions)
+	d := markdown.Parse([]byte(doc), p)
+	AddImageAttributes(d)
+
+	diags := CheckImages(d, []byte(doc))
+	if len(diags) != 1 || diags[0].String() != `4: image "noalt.svg" has no alt text` {
+		t.Errorf("expected a single diagnostic for noalt.svg, got %v", diags)
+	}
+
+	out := markdown.Render(d, xml.NewRenderer(xml.RendererOptions{Flags: xml.CommonFlags | xml.XMLFragment}))
+	const want = `<t><artwork src="stack.svg" type="svg" width="80%" alt="The protocol stack"/></t>`
+	if !bytes.Contains(out, []byte(want)) {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+}
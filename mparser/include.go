@@ -11,10 +11,14 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io/fs"
+	"net/url"
 	"os"
+	"os/exec"
 	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -23,25 +27,46 @@ import (
 
 // Initial is the initial file we are working on, empty for stdin and adjusted is we we have an absolute or relative file.
 type Initial struct {
-	Flags parser.Flags
-	i     string
+	Flags   parser.Flags
+	Fetcher *Fetcher         // fetches remote (https) includes, if nil these aren't allowed
+	Defines map[string]bool  // names for conditional includes, {{file.md}}[if=internal]
+	Record  func(dep string) // if set, called with each file that is read: a path, URL or git:rev:path
+	FS      fs.FS            // if set, files are read from FS instead of the file system, see NewInitialFS
+	Source  []byte           // the document, to find the lines of the includes when an include fails
+	Report  Reporter         // if set, called with the diagnostics found while parsing, otherwise these are logged
+	i       string
+	chain   *chain
 }
 
 // NewInitial returns an initialized Initial.
 func NewInitial(s string) Initial {
 	if path.IsAbs(s) {
-		return Initial{i: path.Dir(s)}
+		return Initial{i: path.Dir(s), chain: newChain(s, s)}
 	}
 
 	cwd, _ := os.Getwd()
 	if s == "" {
-		return Initial{i: cwd}
+		return Initial{i: cwd, chain: newChain("stdin", "")}
 	}
-	return Initial{i: path.Dir(filepath.Join(cwd, s))}
+	return Initial{i: path.Dir(filepath.Join(cwd, s)), chain: newChain(s, filepath.Join(cwd, s))}
+}
+
+// NewInitialFS returns an Initial that reads the includes of the file name from fsys, i.e. an
+// embed.FS or a file system backed by object storage. Includes are relative to name, an absolute
+// include is relative to the root of fsys. As with NewInitial, includes must be on the same level or
+// below name, unless unsafe includes are allowed. Includes from a git revision aren't supported.
+func NewInitialFS(fsys fs.FS, name string) Initial {
+	return Initial{FS: fsys, i: path.Dir(name), chain: newChain(name, path.Clean(name))}
 }
 
 // path returns the full path we should use according to from, file and initial.
 func (i Initial) path(from, file string) string {
+	if i.FS != nil {
+		if path.IsAbs(file) {
+			return path.Clean(file[1:])
+		}
+		return path.Join(i.i, from, file)
+	}
 	if path.IsAbs(file) {
 		return file
 	}
@@ -54,8 +79,200 @@ func (i Initial) path(from, file string) string {
 	return filepath.Join(f1, file)
 }
 
+// defined returns true if the condition of a conditional include holds: the name is defined, or,
+// for !name, it isn't. Names can be combined with commas, all of these must hold.
+func (i Initial) defined(cond string) bool {
+	for _, name := range strings.Split(cond, ",") {
+		name = strings.TrimSpace(name)
+		if strings.HasPrefix(name, "!") {
+			if i.Defines[strings.TrimSpace(name[1:])] {
+				return false
+			}
+			continue
+		}
+		if !i.Defines[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// MaxIncludeSize is the maximum size of a remote include.
+const MaxIncludeSize = 1 << 20
+
+// remote returns the URL of file if it is a remote include. This is the case when file is a URL, or
+// when it's a relative path in a file that was itself included from a URL.
+func remote(from, file string) (string, bool) {
+	if isURL(file) {
+		return file, true
+	}
+	// The include stack cleans the directory of a remote file, https://example.org/a becomes
+	// https:/example.org/a.
+	from = filepath.ToSlash(from)
+	for _, scheme := range []string{"https:/", "http:/"} {
+		if !strings.HasPrefix(from, scheme) || strings.HasPrefix(from, scheme+"/") {
+			continue
+		}
+		base, err := url.Parse(scheme + "/" + from[len(scheme):] + "/")
+		if err != nil {
+			return "", false
+		}
+		ref, err := url.Parse(filepath.ToSlash(file))
+		if err != nil {
+			return "", false
+		}
+		return base.ResolveReference(ref).String(), true
+	}
+	return "", false
+}
+
+// GitPrefix is the prefix of an include from a git revision, git:v1.2.0:protocol.md.
+const GitPrefix = "git:"
+
+// revision returns the git revision of file and the from and file to use for its path, if file is
+// included from a git revision. This is the case when file starts with GitPrefix, or when it's a
+// relative path in a file that was itself included from a git revision.
+func revision(from, file string) (rev, from1, file1 string, ok bool) {
+	split := func(s string) (string, string, bool) {
+		if !strings.HasPrefix(s, GitPrefix) {
+			return "", "", false
+		}
+		rev, name, ok := strings.Cut(s[len(GitPrefix):], ":")
+		if !ok || rev == "" || strings.HasPrefix(rev, "-") {
+			return "", "", false
+		}
+		return rev, name, true
+	}
+	if rev, name, ok := split(file); ok {
+		return rev, from, name, true
+	}
+	if path.IsAbs(file) {
+		return "", "", "", false
+	}
+	// The include stack keeps the directory of the included file, git:v1.2.0:docs, or
+	// chapters/git:v1.2.0:docs when it was included from chapters.
+	dir := ""
+	from = filepath.ToSlash(from)
+	if i := strings.LastIndex(from, "/"+GitPrefix); i >= 0 {
+		dir, from = from[:i], from[i+1:]
+	}
+	if rev, name, ok := split(from); ok {
+		return rev, filepath.Join(dir, name), file, true
+	}
+	return "", "", "", false
+}
+
+// gitShow returns the contents of the file at path in revision rev of the git repository path is in.
+func gitShow(rev, path string) ([]byte, error) {
+	cmd := exec.Command("git", "-C", filepath.Dir(path), "show", rev+":./"+filepath.Base(path))
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	data, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git show %s:%s: %s", rev, path, msg)
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// Include fetches the file at the https URL u for a remote include.
+func (f *Fetcher) Include(u string) ([]byte, error) {
+	if !strings.HasPrefix(u, "https://") {
+		return nil, fmt.Errorf("%s: only https URLs can be included", u)
+	}
+	return f.fetch("include/"+url.PathEscape(u), u, "")
+}
+
+// Manifest is the name of the file that sets the order of the files in a glob or directory include.
+// It lists a file name per line, lines starting with # are ignored. The listed files are included
+// first, in this order, followed by the other matching files in lexical order.
+const Manifest = ".order"
+
+// readFile reads the file at path from i's FS, or from the file system if i has no FS.
+func (i Initial) readFile(path string) ([]byte, error) {
+	if i.FS != nil {
+		return fs.ReadFile(i.FS, path)
+	}
+	return os.ReadFile(path)
+}
+
+// stat returns the file info of the file at path, see readFile.
+func (i Initial) stat(path string) (fs.FileInfo, error) {
+	if i.FS != nil {
+		return fs.Stat(i.FS, path)
+	}
+	return os.Stat(path)
+}
+
+// isGlob returns true if path is a glob pattern or a directory.
+func (i Initial) isGlob(path string) bool {
+	if strings.ContainsAny(filepath.Base(path), "*?[") {
+		return true
+	}
+	fi, err := i.stat(path)
+	return err == nil && fi.IsDir()
+}
+
+// glob returns the files matching the glob pattern, or the *.md files if pattern is a directory.
+// Only regular files are returned, in the order of the Manifest if there is one.
+func (i Initial) glob(pattern string) ([]string, error) {
+	join, dir, match := filepath.Join, filepath.Dir, filepath.Glob
+	if i.FS != nil {
+		join, dir = path.Join, path.Dir
+		match = func(pattern string) ([]string, error) { return fs.Glob(i.FS, pattern) }
+	}
+	if fi, err := i.stat(pattern); err == nil && fi.IsDir() {
+		pattern = join(pattern, "*.md")
+	}
+	matches, err := match(pattern)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{}
+	for _, m := range matches {
+		if fi, err := i.stat(m); err == nil && fi.Mode().IsRegular() {
+			files = append(files, m)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files match %q", pattern)
+	}
+	sort.Strings(files)
+
+	manifest, err := i.readFile(join(dir(pattern), Manifest))
+	if err != nil {
+		return files, nil
+	}
+	ordered := []string{}
+	seen := map[string]bool{}
+	for _, line := range strings.Split(string(manifest), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name := join(dir(pattern), line)
+		for _, f := range files {
+			if f == name && !seen[f] {
+				ordered = append(ordered, f)
+				seen[f] = true
+			}
+		}
+	}
+	for _, f := range files {
+		if !seen[f] {
+			ordered = append(ordered, f)
+		}
+	}
+	return ordered, nil
+}
+
 // pathAllowed returns true is file is on the same level or below the initial file.
 func (i Initial) pathAllowed(file string) bool {
+	if i.FS != nil {
+		return fs.ValidPath(file) && (i.i == "." || file == i.i || strings.HasPrefix(file, i.i+"/"))
+	}
 	x, err := filepath.Rel(i.i, file)
 	if err != nil {
 		return false
@@ -120,6 +337,216 @@ func parseAddress(addr []byte, data []byte) ([]byte, error) {
 	return data, nil
 }
 
+// addressOption removes the option name=value from the address and returns its value and the rest of
+// the address. The value can be quoted. Options are separated from the rest of the address with a
+// semicolon, as in 3,5;shift=1. If the option isn't there the value is empty. A bare name, as in
+// 3,5;dedent, has the value "true".
+func addressOption(addr []byte, name string) (string, []byte) {
+	parts := splitAddress(addr)
+	for i, p := range parts {
+		p = bytes.TrimSpace(p)
+		if string(p) == name {
+			rest := append(parts[:i:i], parts[i+1:]...)
+			return "true", bytes.TrimSpace(bytes.Join(rest, []byte(";")))
+		}
+		if !bytes.HasPrefix(p, []byte(name+"=")) {
+			continue
+		}
+		value := string(p[len(name)+1:])
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		rest := append(parts[:i:i], parts[i+1:]...)
+		return value, bytes.TrimSpace(bytes.Join(rest, []byte(";")))
+	}
+	return "", addr
+}
+
+// splitAddress splits addr on the semicolons that aren't quoted.
+func splitAddress(addr []byte) [][]byte {
+	parts := [][]byte{}
+	quote := byte(0)
+	start := 0
+	for i, c := range addr {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ';':
+			parts = append(parts, addr[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, addr[start:])
+}
+
+var (
+	parameter   = regexp.MustCompile(`^\s*(?:([A-Za-z_][A-Za-z0-9_]*)\s*=\s*)?("[^"]*"|'[^']*')\s*(?:,|$)`)
+	placeholder = regexp.MustCompile(`\\?\$(?:\{([A-Za-z_][A-Za-z0-9_]*)\}|([1-9][0-9]*))`)
+)
+
+// templateParameters removes the parameters of a template include from the address and returns these
+// and the rest of the address. Parameters are quoted values, optionally named and separated by
+// commas: name="foo", ref="RFC9000". The parameters are numbered from 1 as well, in the order given.
+func templateParameters(addr []byte) (map[string]string, []byte) {
+	params := map[string]string{}
+	rest := [][]byte{}
+	n := 0
+	for _, part := range splitAddress(addr) {
+		type param struct{ name, value string }
+		found := []param{}
+		p := part
+		for len(bytes.TrimSpace(p)) > 0 {
+			m := parameter.FindSubmatchIndex(p)
+			if m == nil {
+				break
+			}
+			x := param{value: string(p[m[4]+1 : m[5]-1])}
+			if m[2] >= 0 {
+				x.name = string(p[m[2]:m[3]])
+			}
+			found = append(found, x)
+			p = p[m[1]:]
+		}
+		if len(found) == 0 || len(bytes.TrimSpace(p)) > 0 {
+			rest = append(rest, part) // not parameters, i.e. 3,5 or /start/,/end/
+			continue
+		}
+		for _, x := range found {
+			n++
+			params[strconv.Itoa(n)] = x.value
+			if x.name != "" {
+				params[x.name] = x.value
+			}
+		}
+	}
+	return params, bytes.TrimSpace(bytes.Join(rest, []byte(";")))
+}
+
+// expandParameters replaces the placeholders ${name} and $1 in data with the values of the
+// parameters. Unknown placeholders are left alone, as are escaped ones: \${name}.
+func expandParameters(data []byte, params map[string]string) []byte {
+	return placeholder.ReplaceAllFunc(data, func(p []byte) []byte {
+		if p[0] == '\\' {
+			return p
+		}
+		m := placeholder.FindSubmatch(p)
+		name := string(m[1])
+		if name == "" {
+			name = string(m[2])
+		}
+		if value, ok := params[name]; ok {
+			return []byte(value)
+		}
+		return p
+	})
+}
+
+// dedent removes the indentation all non-blank lines of data have in common.
+func dedent(data []byte) []byte {
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	var common []byte
+	first := true
+	for _, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		indent := line[:len(line)-len(bytes.TrimLeft(line, " \t"))]
+		if first {
+			common, first = indent, false
+			continue
+		}
+		n := 0
+		for n < len(common) && n < len(indent) && common[n] == indent[n] {
+			n++
+		}
+		common = common[:n]
+	}
+	if len(common) == 0 {
+		return data
+	}
+	buf := &bytes.Buffer{}
+	for _, line := range lines {
+		buf.Write(bytes.TrimPrefix(line, common))
+	}
+	return buf.Bytes()
+}
+
+var (
+	snippetStart = regexp.MustCompile(`\bsnippet:\s*(\S+)`)
+	snippetEnd   = regexp.MustCompile(`\bend snippet\b`)
+)
+
+// snippet returns the lines of data between the markers of the snippet name: a line containing
+// "snippet: name", i.e. the comment // snippet: handshake, and the next line containing "end
+// snippet" that isn't the end of a snippet nested in it. The markers are left out, as are the markers
+// of the nested snippets.
+func snippet(data []byte, name string) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	depth := 0 // snippets nest, depth is 1 in the snippet itself
+	found := false
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if m := snippetStart.FindSubmatch(line); m != nil {
+			if depth > 0 {
+				depth++
+			} else if string(m[1]) == name && !found {
+				depth, found = 1, true
+			}
+			continue
+		}
+		if snippetEnd.Match(line) {
+			if depth--; depth == 0 {
+				break
+			}
+			if depth < 0 {
+				depth = 0
+			}
+			continue
+		}
+		if depth > 0 {
+			buf.Write(line)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no snippet %q", name)
+	}
+	return buf.Bytes(), nil
+}
+
+// shiftHeadings demotes the ATX headings in data by n levels: with n is 1, # Heading becomes ##
+// Heading. Special headings, .# Abstract, are shifted as well. Headings in fenced code blocks are
+// left alone.
+func shiftHeadings(data []byte, n int) []byte {
+	if n <= 0 {
+		return data
+	}
+	hashes := bytes.Repeat([]byte("#"), n)
+	buf := &bytes.Buffer{}
+	fence := ""
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		trimmed := bytes.TrimLeft(line, " ")
+		switch {
+		case fence != "":
+			if bytes.HasPrefix(trimmed, []byte(fence)) {
+				fence = ""
+			}
+		case bytes.HasPrefix(trimmed, []byte("```")), bytes.HasPrefix(trimmed, []byte("~~~")):
+			fence = string(trimmed[:3])
+		case bytes.HasPrefix(line, []byte("#")):
+			buf.Write(hashes)
+		case bytes.HasPrefix(line, []byte(".#")):
+			buf.WriteByte('.')
+			buf.Write(hashes)
+			line = line[1:]
+		}
+		buf.Write(line)
+	}
+	return buf.Bytes()
+}
+
 // addrToByteRange evaluates the given address. It returns the start and end index of the data we should return.
 // Supported syntax:  N, M  or /start/, /end/ .
 func addrToByteRange(addr, data []byte) (lo, hi int, err error) {
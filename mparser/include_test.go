@@ -0,0 +1,366 @@
+package mparser
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+func TestRemote(t *testing.T) {
+	for _, tc := range []struct {
+		from, file, want string
+	}{
+		{"", "https://example.org/a.md", "https://example.org/a.md"},
+		{"https:/example.org/shared", "terms.md", "https://example.org/shared/terms.md"},
+		{"https:/example.org/shared", "../x/terms.md", "https://example.org/x/terms.md"},
+		{"docs", "terms.md", ""},
+	} {
+		got, _ := remote(tc.from, tc.file)
+		if got != tc.want {
+			t.Errorf("%q, %q: expected %q, got %q", tc.from, tc.file, tc.want, got)
+		}
+	}
+}
+
+func TestReadIncludeRemote(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/shared/security.md":
+			w.Write([]byte("# Security Considerations\n\n{{terms.md}}\n"))
+		case "/shared/terms.md":
+			w.Write([]byte("No new terms.\n"))
+		case "/big.md":
+			w.Write([]byte(strings.Repeat("x", 200)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	init := NewInitial("")
+	init.Fetcher = NewFetcher()
+	init.Fetcher.Client = srv.Client()
+	init.Fetcher.Cache = t.TempDir()
+	init.Fetcher.MaxSize = 100
+
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook, ReadIncludeFn: init.ReadInclude}
+	doc := markdown.Parse([]byte("{{"+srv.URL+"/shared/security.md}}\n"), p)
+
+	text := ""
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if l := node.AsLeaf(); l != nil && entering {
+			text += string(l.Literal) + "|"
+		}
+		return ast.GoToNext
+	})
+	if text != "Security Considerations|No new terms.|" {
+		t.Errorf("expected the remote include and its relative include, got %q", text)
+	}
+
+	if data := init.ReadInclude("", srv.URL+"/big.md", nil); data != nil {
+		t.Errorf("expected nothing for an include that is too large, got %d bytes", len(data))
+	}
+	if data := init.ReadInclude("", strings.Replace(srv.URL, "https", "http", 1)+"/shared/terms.md", nil); data != nil {
+		t.Errorf("expected nothing for an http include, got %q", data)
+	}
+	init.Fetcher = nil
+	if data := init.ReadInclude("", srv.URL+"/shared/terms.md", nil); data != nil {
+		t.Errorf("expected nothing without a fetcher, got %q", data)
+	}
+}
+
+func TestReadIncludeGlob(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "chapters"), 0o755)
+	for name, data := range map[string]string{
+		"chapters/a.md":   "# A\n",
+		"chapters/b.md":   "# B\n",
+		"chapters/c.md":   "# C\n",
+		"chapters/c.txt":  "not markdown\n",
+		"chapters/.order": "# intro first\nc.md\nmissing.md\n",
+	} {
+		os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644)
+	}
+	init := NewInitial(filepath.Join(dir, "main.md"))
+
+	if data := string(init.ReadInclude("", "chapters/*.md", nil)); data != "# C\n\n# A\n\n# B\n" {
+		t.Errorf("expected the chapters in the order of the manifest, got %q", data)
+	}
+	if data := string(init.ReadInclude("", "chapters/", nil)); data != "# C\n\n# A\n\n# B\n" {
+		t.Errorf("expected the markdown files of the directory, got %q", data)
+	}
+	os.Remove(filepath.Join(dir, "chapters", Manifest))
+	if data := string(init.ReadInclude("", "chapters/*.md", []byte("1,2"))); data != "# A\n\n# B\n\n# C\n" {
+		t.Errorf("expected the chapters in lexical order, got %q", data)
+	}
+	if data := init.ReadInclude("", "chapters/*.tex", nil); data != nil {
+		t.Errorf("expected nothing when no files match, got %q", data)
+	}
+}
+
+func TestShiftHeadings(t *testing.T) {
+	data := "# One\n\ntext #\n\n.# Abstract\n\n~~~\n# comment\n~~~\n## Two\n"
+	want := "## One\n\ntext #\n\n.## Abstract\n\n~~~\n# comment\n~~~\n### Two\n"
+	if got := string(shiftHeadings([]byte(data), 1)); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestAddressOption(t *testing.T) {
+	for _, tc := range []struct {
+		addr, value, rest string
+	}{
+		{"shift=1", "1", ""},
+		{"3,5;shift=2", "2", "3,5"},
+		{`shift="1";prefix="C: "`, "1", `prefix="C: "`},
+		{"3,5", "", "3,5"},
+	} {
+		value, rest := addressOption([]byte(tc.addr), "shift")
+		if value != tc.value || string(rest) != tc.rest {
+			t.Errorf("%q: expected %q and %q, got %q and %q", tc.addr, tc.value, tc.rest, value, rest)
+		}
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	data := `package main
+
+// snippet: handshake
+func handshake() {
+	// snippet: inner
+	hello()
+	// end snippet
+}
+// end snippet
+
+# snippet: other
+other()
+# end snippet
+`
+	for _, tc := range []struct {
+		name, want string
+	}{
+		{"handshake", "func handshake() {\n\thello()\n}\n"},
+		{"inner", "\thello()\n"},
+		{"other", "other()\n"},
+	} {
+		got, err := snippet([]byte(data), tc.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.want, got)
+		}
+	}
+	if _, err := snippet([]byte(data), "missing"); err == nil {
+		t.Error("expected an error for a missing snippet")
+	}
+}
+
+func TestReadIncludeIf(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte("Internal notes.\n"), 0o644)
+	init := NewInitial(filepath.Join(dir, "main.md"))
+	init.Defines = map[string]bool{"internal": true}
+
+	for _, tc := range []struct {
+		address, want string
+	}{
+		{"if=internal", "Internal notes.\n"},
+		{"if=public", ""},
+		{"if=!public", "Internal notes.\n"},
+		{"if=internal,!public", "Internal notes.\n"},
+		{"if=internal,public", ""},
+		{"1,;if=internal", "Internal notes.\n"},
+	} {
+		if got := string(init.ReadInclude("", "notes.md", []byte(tc.address))); got != tc.want {
+			t.Errorf("%q: expected %q, got %q", tc.address, tc.want, got)
+		}
+	}
+	// The file isn't read when the condition doesn't hold, so it doesn't need to exist.
+	if got := init.ReadInclude("", "missing.md", []byte("if=public")); got != nil {
+		t.Errorf("expected nothing, got %q", got)
+	}
+}
+
+func TestReadIncludeGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.org"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s: %s", args, err, out)
+		}
+	}
+	os.Mkdir(filepath.Join(dir, "spec"), 0o755)
+	git("init", "-q")
+	os.WriteFile(filepath.Join(dir, "spec", "protocol.md"), []byte("Version 1.\n\n{{details.md}}\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "spec", "details.md"), []byte("Old details.\n"), 0o644)
+	git("add", ".")
+	git("commit", "-q", "-m", "v1")
+	git("tag", "v1")
+	os.WriteFile(filepath.Join(dir, "spec", "protocol.md"), []byte("Version 2.\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "spec", "details.md"), []byte("New details.\n"), 0o644)
+
+	init := NewInitial(filepath.Join(dir, "main.md"))
+	if got := string(init.ReadInclude("", "git:v1:spec/protocol.md", nil)); got != "Version 1.\n\n{{details.md}}\n" {
+		t.Errorf("expected the file from v1, got %q", got)
+	}
+	// A relative include in a file from v1 is read from v1 as well.
+	if got := string(init.ReadInclude("git:v1:spec", "details.md", nil)); got != "Old details.\n" {
+		t.Errorf("expected the relative include from v1, got %q", got)
+	}
+	if got := init.ReadInclude("", "git:v9:spec/protocol.md", nil); got != nil {
+		t.Errorf("expected nothing for an unknown revision, got %q", got)
+	}
+	if got := init.ReadInclude("", "git:-v1:spec/protocol.md", nil); got != nil {
+		t.Errorf("expected nothing for an invalid revision, got %q", got)
+	}
+}
+
+func TestReadIncludeRecord(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.md"), []byte("{{b.md}}\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "b.md"), []byte("B\n"), 0o644)
+	deps := []string{}
+	init := NewInitial(filepath.Join(dir, "main.md"))
+	init.Record = func(dep string) { deps = append(deps, dep) }
+
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook, ReadIncludeFn: init.ReadInclude}
+	markdown.Parse([]byte("{{a.md}}\n\n{{missing.md}}\n"), p)
+	if len(deps) != 2 || deps[0] != filepath.Join(dir, "a.md") || deps[1] != filepath.Join(dir, "b.md") {
+		t.Errorf("expected a.md and b.md, got %v", deps)
+	}
+}
+
+func TestReadIncludeFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"docs/main.md":         {Data: []byte("{{chapters/*.md}}\n")},
+		"docs/chapters/a.md":   {Data: []byte("A\n\n{{../shared/terms.md}}\n")},
+		"docs/chapters/b.md":   {Data: []byte("B\n")},
+		"docs/shared/terms.md": {Data: []byte("Terms\n")},
+		"secret.md":            {Data: []byte("Secret\n")},
+	}
+	init := NewInitialFS(fsys, "docs/main.md")
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook, ReadIncludeFn: init.ReadInclude}
+	doc := markdown.Parse(fsys["docs/main.md"].Data, p)
+
+	text := ""
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if l := node.AsLeaf(); l != nil && entering {
+			text += string(l.Literal) + "|"
+		}
+		return ast.GoToNext
+	})
+	if text != "A|Terms|B|" {
+		t.Errorf("expected the includes from the FS, got %q", text)
+	}
+	if data := init.ReadInclude("", "/docs/shared/terms.md", nil); string(data) != "Terms\n" {
+		t.Errorf("expected an absolute include relative to the root, got %q", data)
+	}
+	if data := init.ReadInclude("", "../secret.md", nil); data != nil {
+		t.Errorf("expected nothing above the document, got %q", data)
+	}
+	init.Flags |= UnsafeInclude
+	if data := init.ReadInclude("", "../secret.md", nil); string(data) != "Secret\n" {
+		t.Errorf("expected an unsafe include above the document, got %q", data)
+	}
+}
+
+func TestReadIncludeChain(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "chapters"), 0o755)
+	for name, data := range map[string]string{
+		"chapters/a.md": "A\n\n{{b.md}}\n",
+		"chapters/b.md": "B\n\nB\n\n{{missing.md}}\n",
+		"loop.md":       "Loop\n\n{{loop2.md}}\n",
+		"loop2.md":      "Loop 2\n\n{{loop.md}}\n",
+	} {
+		os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644)
+	}
+	buf := &bytes.Buffer{}
+	log.SetOutput(buf)
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	}()
+
+	source := []byte("# Intro\n\n{{chapters/a.md}}\n\n{{loop.md}}\n")
+	init := NewInitial(filepath.Join(dir, "main.md"))
+	init.Source = source
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook, ReadIncludeFn: init.ReadInclude}
+	markdown.Parse(source, p)
+
+	if !init.Failed() {
+		t.Error("expected the includes to fail")
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 failures, got %q", lines)
+	}
+	main := filepath.Join(dir, "main.md")
+	want := main + `:3 → chapters/a.md:3 → chapters/b.md:5: failure to read "chapters/missing.md": open ` + filepath.Join(dir, "chapters", "missing.md") + ": no such file or directory"
+	if lines[0] != want {
+		t.Errorf("expected %q, got %q", want, lines[0])
+	}
+	want = main + `:5 → loop.md:3 → loop2.md:3: include cycle: "loop.md" includes itself`
+	if lines[1] != want {
+		t.Errorf("expected %q, got %q", want, lines[1])
+	}
+}
+
+func TestReadIncludeModifiers(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "example.txt"), []byte("    func() {\n        x := 1\n\n    }\n"), 0o644)
+	init := NewInitial(filepath.Join(dir, "main.md"))
+
+	for _, tc := range []struct {
+		address, want string
+	}{
+		{"dedent", "func() {\n    x := 1\n\n}\n"},
+		{`dedent;prefix="; "`, "; func() {\n;     x := 1\n; \n; }\n"},
+		{`2,3;dedent`, "x := 1\n"},
+		{`dedent;header="NOTE: '\' line wrapping per RFC 8792\n";footer="-- end"`,
+			"NOTE: '\\' line wrapping per RFC 8792\n\nfunc() {\n    x := 1\n\n}\n-- end\n"},
+	} {
+		if got := string(init.ReadInclude("", "example.txt", []byte(tc.address))); got != tc.want {
+			t.Errorf("%q: expected %q, got %q", tc.address, tc.want, got)
+		}
+	}
+}
+
+func TestReadIncludeTemplate(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "iana.md"), []byte("Name: ${name}\nReference: $2\nCost: \\${name} and ${unknown}\n"), 0o644)
+	init := NewInitial(filepath.Join(dir, "main.md"))
+
+	for _, tc := range []struct {
+		address, want string
+	}{
+		{`name="foo", ref="RFC9000"`, "Name: foo\nReference: RFC9000\nCost: \\${name} and ${unknown}\n"},
+		{`"foo";'RFC9000'`, "Name: ${name}\nReference: RFC9000\nCost: \\${name} and ${unknown}\n"},
+		{`1,2;name="foo"`, "Name: foo\n"},
+	} {
+		if got := string(init.ReadInclude("", "iana.md", []byte(tc.address))); got != tc.want {
+			t.Errorf("%q: expected %q, got %q", tc.address, tc.want, got)
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"fmt"
 	"sort"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/gomarkdown/markdown/ast"
 	"github.com/mmarkdown/mmark/v2/mast"
@@ -35,20 +37,27 @@ func IndexToDocumentIndex(doc ast.Node) *mast.DocumentIndex {
 			}
 			// only the main item
 			if i.Subitem == nil {
-				ast.AppendChild(main[item], newLink(i.ID, len(main[item].GetChildren()), i.Primary))
+				it := main[item]
+				if !addRedirect(i, &it.See, &it.SeeAlso) {
+					ast.AppendChild(it, newLink(i, len(it.GetChildren())))
+				}
 				return ast.GoToNext
 			}
-			// check if we already have a child with the subitem and then just add the link
-			for _, sub := range subitem[item] {
-				if bytes.Compare(sub.Subitem, i.Subitem) == 0 {
-					ast.AppendChild(sub, newLink(i.ID, len(sub.GetChildren()), i.Primary))
-					return ast.GoToNext
+			// check if we already have a child with the subitem, otherwise create it
+			var sub *mast.IndexSubItem
+			for _, s := range subitem[item] {
+				if bytes.Equal(s.Subitem, i.Subitem) {
+					sub = s
+					break
 				}
 			}
-
-			sub := &mast.IndexSubItem{Index: i}
-			ast.AppendChild(sub, newLink(i.ID, len(subitem[item]), i.Primary))
-			subitem[item] = append(subitem[item], sub)
+			if sub == nil {
+				sub = &mast.IndexSubItem{Index: i}
+				subitem[item] = append(subitem[item], sub)
+			}
+			if !addRedirect(i, &sub.See, &sub.SeeAlso) {
+				ast.AppendChild(sub, newLink(i, len(sub.GetChildren())))
+			}
 		}
 		return ast.GoToNext
 	})
@@ -58,7 +67,7 @@ func IndexToDocumentIndex(doc ast.Node) *mast.DocumentIndex {
 
 	// Now add a subitem children to the correct main item.
 	for k, sub := range subitem {
-		// sort sub here ideally
+		sort.SliceStable(sub, func(i, j int) bool { return less(string(sub[i].Subitem), string(sub[j].Subitem)) })
 		for j := range sub {
 			ast.AppendChild(main[k], sub[j])
 		}
@@ -68,16 +77,17 @@ func IndexToDocumentIndex(doc ast.Node) *mast.DocumentIndex {
 	for k := range main {
 		keys = append(keys, k)
 	}
-	sort.Strings(keys)
+	sort.Slice(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
 
 	letters := []*mast.IndexLetter{}
-	var prevLetter byte
+	prevLetter := ""
 	var il *mast.IndexLetter
 	for _, k := range keys {
-		letter := k[0]
+		r, _ := utf8.DecodeRuneInString(k)
+		letter := strings.ToUpper(string(r))
 		if letter != prevLetter {
 			il = &mast.IndexLetter{}
-			il.Literal = []byte{letter}
+			il.Literal = []byte(letter)
 			letters = append(letters, il)
 		}
 		ast.AppendChild(il, main[k])
@@ -91,9 +101,32 @@ func IndexToDocumentIndex(doc ast.Node) *mast.DocumentIndex {
 	return docIndex
 }
 
-func newLink(id string, number int, primary bool) *mast.IndexLink {
-	link := &ast.Link{Destination: []byte(id)}
-	il := &mast.IndexLink{Link: link, Primary: primary}
+// less sorts the items of the index case insensitively.
+func less(a, b string) bool {
+	la, lb := strings.ToLower(a), strings.ToLower(b)
+	if la == lb {
+		return a < b
+	}
+	return la < lb
+}
+
+// addRedirect adds the target of i to see or seeAlso if i is a redirect and returns true, otherwise
+// it returns false.
+func addRedirect(i *ast.Index, see, seeAlso *[][]byte) bool {
+	if s := mast.Attribute(i, mast.IndexSee); s != nil {
+		*see = append(*see, s)
+		return true
+	}
+	if s := mast.Attribute(i, mast.IndexSeeAlso); s != nil {
+		*seeAlso = append(*seeAlso, s)
+		return true
+	}
+	return false
+}
+
+func newLink(i *ast.Index, number int) *mast.IndexLink {
+	link := &ast.Link{Destination: []byte(i.ID)}
+	il := &mast.IndexLink{Link: link, Primary: i.Primary, End: mast.IndexRangeEnd(i)}
 	il.Literal = []byte(fmt.Sprintf("%d", number))
 	return il
 }
@@ -109,3 +142,39 @@ func AddIndex(doc ast.Node) bool {
 	ast.AppendChild(doc, idx)
 	return true
 }
+
+// ParseIndexModifiers strips the modifier after a '|' from the (sub)item of each index in doc and sets
+// it as an attribute: "see other" and "see also other" make the index a redirect to "other" (mast.IndexSee
+// and mast.IndexSeeAlso), "begin" and "end" mark a range (mast.IndexRange). Unknown modifiers are left
+// alone.
+func ParseIndexModifiers(doc ast.Node) {
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		i, ok := node.(*ast.Index)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		text := &i.Item
+		if len(i.Subitem) > 0 {
+			text = &i.Subitem
+		}
+		bar := bytes.IndexByte(*text, '|')
+		if bar < 0 {
+			return ast.GoToNext
+		}
+		key, value := "", bytes.TrimSpace((*text)[bar+1:])
+		switch {
+		case bytes.HasPrefix(value, []byte("see also ")):
+			key, value = mast.IndexSeeAlso, bytes.TrimSpace(value[len("see also "):])
+		case bytes.HasPrefix(value, []byte("see ")):
+			key, value = mast.IndexSee, bytes.TrimSpace(value[len("see "):])
+		case string(value) == "begin" || string(value) == "end":
+			key = mast.IndexRange
+		default:
+			return ast.GoToNext
+		}
+		mast.AttributeInit(i)
+		mast.SetAttribute(i, key, value)
+		*text = bytes.TrimSpace((*text)[:bar])
+		return ast.GoToNext
+	})
+}
@@ -0,0 +1,50 @@
+package mparser
+
+import (
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+func TestIndexToDocumentIndex(t *testing.T) {
+	const doc = `TLS (!TLS|begin) (!!TLS, handshake) (!ssl|see TLS) (!TLS, records|see also record layer) (!TLS|end)
+`
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook}
+	d := markdown.Parse([]byte(doc), p)
+	ParseIndexModifiers(d)
+	idx := IndexToDocumentIndex(d)
+	if idx == nil {
+		t.Fatal("expected an index")
+	}
+
+	letters := idx.GetChildren()
+	if len(letters) != 2 {
+		t.Fatalf("expected 2 letters, got %d", len(letters))
+	}
+	if l := string(letters[0].AsContainer().Literal); l != "S" {
+		t.Errorf("expected letter %q, got %q", "S", l)
+	}
+
+	ssl := letters[0].GetChildren()[0].(*mast.IndexItem)
+	if string(ssl.Item) != "ssl" || len(ssl.See) != 1 || string(ssl.See[0]) != "TLS" || len(ssl.GetChildren()) != 0 {
+		t.Errorf("expected ssl to only redirect to TLS, got %q %q", ssl.Item, ssl.See)
+	}
+
+	tls := letters[1].GetChildren()[0].(*mast.IndexItem)
+	children := tls.GetChildren()
+	if len(children) != 4 {
+		t.Fatalf("expected 4 children for TLS, got %d", len(children))
+	}
+	if begin, end := children[0].(*mast.IndexLink), children[1].(*mast.IndexLink); begin.End || !end.End {
+		t.Errorf("expected a range, got %v and %v", begin.End, end.End)
+	}
+	if sub := children[2].(*mast.IndexSubItem); string(sub.Subitem) != "handshake" || !sub.GetChildren()[0].(*mast.IndexLink).Primary {
+		t.Errorf("expected a primary handshake subitem, got %q", sub.Subitem)
+	}
+	if sub := children[3].(*mast.IndexSubItem); string(sub.Subitem) != "records" || string(sub.SeeAlso[0]) != "record layer" {
+		t.Errorf("expected records to see also record layer, got %q %q", sub.Subitem, sub.SeeAlso)
+	}
+}
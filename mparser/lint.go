@@ -0,0 +1,306 @@
+package mparser
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// The IDs of the checks done by the lint subcommand, these don't change so they can be used to suppress
+// a check.
+const (
+	LintBCP14Boilerplate = "bcp14-boilerplate" // BCP 14 key words without the boilerplate.
+	LintBCP14Unused      = "bcp14-unused"      // The BCP 14 boilerplate without key words.
+	LintBCP14Citation    = "bcp14-citation"    // The boilerplate without normative citations of RFC 2119 and 8174.
+	LintSecurity         = "security-considerations"
+	LintIANA             = "iana-considerations"
+	LintLineLength       = "line-length"
+	LintNonASCII         = "non-ascii"
+	LintObsolete         = "obsolete-reference"
+	LintUnreferenced     = "unreferenced-section"
+	LintReference        = "reference" // The diagnostics of CheckReferences and ValidateReferences.
+	LintImageAlt         = "image-alt"
+)
+
+var bcp14Boilerplate = regexp.MustCompile(`BCP[\s\x{a0}]*14`)
+
+// CheckBCP14 checks the use of the BCP 14 (RFC 2119) key words in doc: if they are used the document
+// must have the boilerplate, and the boilerplate must cite RFC 2119 and RFC 8174 as normative references.
+// A document with the boilerplate that doesn't use the key words is reported as well. The line numbers
+// are found by searching source, the markdown of doc.
+func CheckBCP14(doc ast.Node, source []byte) []Diagnostic {
+	keyword := ""
+	boilerplate := false
+	cited := map[string]ast.CitationTypes{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch n := node.(type) {
+		case *ast.BlockQuote, *ast.Heading, *mast.Title:
+			return ast.SkipChildren
+		case *ast.Paragraph:
+			if bcp14Boilerplate.MatchString(plainText(n)) {
+				boilerplate = true
+				for _, c := range citations(n) {
+					if cited[rfc(string(c.dest))] != ast.CitationTypeNormative {
+						cited[rfc(string(c.dest))] = c.typ
+					}
+				}
+				return ast.SkipChildren
+			}
+		case *ast.Text:
+			if m := keywords2119.Find(n.Literal); m != nil && keyword == "" {
+				keyword = string(m)
+			}
+		}
+		return ast.GoToNext
+	})
+
+	diags := []Diagnostic{}
+	switch {
+	case keyword != "" && !boilerplate:
+		diags = append(diags, Diagnostic{Line: lineOf(source, `\b`+keyword+`\b`), Check: LintBCP14Boilerplate,
+			Message: fmt.Sprintf("BCP 14 key word %q is used, but the BCP 14 boilerplate is missing", keyword)})
+	case keyword == "" && boilerplate:
+		diags = append(diags, Diagnostic{Line: lineOf(source, bcp14Boilerplate.String()), Check: LintBCP14Unused,
+			Message: "the BCP 14 boilerplate is there, but no key words are used"})
+	}
+	if !boilerplate {
+		return diags
+	}
+	for _, r := range []string{"RFC2119", "RFC8174"} {
+		typ, ok := cited[r]
+		switch {
+		case !ok:
+			diags = append(diags, Diagnostic{Line: lineOf(source, bcp14Boilerplate.String()), Check: LintBCP14Citation,
+				Message: fmt.Sprintf("the BCP 14 boilerplate doesn't cite %s", r)})
+		case typ != ast.CitationTypeNormative:
+			diags = append(diags, Diagnostic{Line: lineOf(source, `@\??`+r+`\b`), Check: LintBCP14Citation,
+				Message: fmt.Sprintf("%s is cited by the BCP 14 boilerplate, but not as a normative reference", r)})
+		}
+	}
+	return diags
+}
+
+type citation struct {
+	dest []byte
+	typ  ast.CitationTypes
+}
+
+// citations returns the citations under node.
+func citations(node ast.Node) []citation {
+	cs := []citation{}
+	ast.WalkFunc(node, func(n ast.Node, entering bool) ast.WalkStatus {
+		if c, ok := n.(*ast.Citation); ok && entering {
+			for i := range c.Destination {
+				cs = append(cs, citation{c.Destination[i], c.Type[i]})
+			}
+		}
+		return ast.GoToNext
+	})
+	return cs
+}
+
+// CheckSections returns a diagnostic if doc lacks a Security Considerations or an IANA Considerations
+// section, which every RFC must have.
+func CheckSections(doc ast.Node) []Diagnostic {
+	security, iana := false, false
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if h, ok := node.(*ast.Heading); ok && entering {
+			text := strings.ToLower(plainText(h))
+			security = security || strings.Contains(text, "security considerations")
+			iana = iana || strings.Contains(text, "iana considerations")
+			return ast.SkipChildren
+		}
+		return ast.GoToNext
+	})
+	diags := []Diagnostic{}
+	if !security {
+		diags = append(diags, Diagnostic{Message: "there is no Security Considerations section", Check: LintSecurity})
+	}
+	if !iana {
+		diags = append(diags, Diagnostic{Message: "there is no IANA Considerations section", Check: LintIANA})
+	}
+	return diags
+}
+
+// CheckASCII returns a diagnostic for each line in source with a non-ASCII character. The title block
+// (the lines starting with %) is skipped, as author names may need them.
+func CheckASCII(source []byte) []Diagnostic {
+	diags := []Diagnostic{}
+	for i, line := range bytes.Split(source, []byte("\n")) {
+		if bytes.HasPrefix(line, []byte("%")) {
+			continue
+		}
+		for j, r := range []rune(string(line)) {
+			if r > 127 {
+				diags = append(diags, Diagnostic{Line: i + 1, Column: j + 1, Check: LintNonASCII, Message: fmt.Sprintf("non-ASCII character %q (%U)", r, r)})
+				break
+			}
+		}
+	}
+	return diags
+}
+
+// ObsoletedBy lists commonly cited RFCs that are obsolete, and the RFCs that obsolete them. Use ReadRFCIndex
+// for the complete list.
+var ObsoletedBy = map[string][]string{
+	"RFC793":  {"RFC9293"},
+	"RFC821":  {"RFC2821"},
+	"RFC822":  {"RFC2822"},
+	"RFC2234": {"RFC4234"},
+	"RFC2246": {"RFC4346"},
+	"RFC2396": {"RFC3986"},
+	"RFC2459": {"RFC3280"},
+	"RFC2460": {"RFC8200"},
+	"RFC2560": {"RFC6960"},
+	"RFC2616": {"RFC7230", "RFC7231", "RFC7232", "RFC7233", "RFC7234", "RFC7235"},
+	"RFC2818": {"RFC9110"},
+	"RFC2821": {"RFC5321"},
+	"RFC2822": {"RFC5322"},
+	"RFC3280": {"RFC5280"},
+	"RFC3501": {"RFC9051"},
+	"RFC3548": {"RFC4648"},
+	"RFC3852": {"RFC5652"},
+	"RFC4234": {"RFC5234"},
+	"RFC4346": {"RFC5246"},
+	"RFC4347": {"RFC6347"},
+	"RFC4566": {"RFC8866"},
+	"RFC4627": {"RFC7159"},
+	"RFC5246": {"RFC8446"},
+	"RFC5389": {"RFC8489"},
+	"RFC5405": {"RFC8085"},
+	"RFC6347": {"RFC9147"},
+	"RFC7049": {"RFC8949"},
+	"RFC7159": {"RFC8259"},
+	"RFC7230": {"RFC9110", "RFC9112"},
+	"RFC7231": {"RFC9110"},
+	"RFC7232": {"RFC9110"},
+	"RFC7233": {"RFC9110"},
+	"RFC7234": {"RFC9111"},
+	"RFC7235": {"RFC9110"},
+	"RFC7525": {"RFC9325"},
+	"RFC7540": {"RFC9113"},
+	"RFC7807": {"RFC9457"},
+	"RFC8152": {"RFC9052", "RFC9053"},
+}
+
+var rfcNumber = regexp.MustCompile(`^(?i)RFC\s*0*([0-9]+)$`)
+
+// ReadRFCIndex returns the obsolete RFCs and the RFCs that obsolete them from the RFC index, rfc-index.xml,
+// that is published by the RFC Editor.
+func ReadRFCIndex(data []byte) (map[string][]string, error) {
+	index := struct {
+		Entries []struct {
+			DocID       string   `xml:"doc-id"`
+			ObsoletedBy []string `xml:"obsoleted-by>doc-id"`
+		} `xml:"rfc-entry"`
+	}{}
+	if err := xml.Unmarshal(data, &index); err != nil {
+		return nil, err
+	}
+	obsoleted := map[string][]string{}
+	for _, e := range index.Entries {
+		if len(e.ObsoletedBy) == 0 {
+			continue
+		}
+		by := make([]string, len(e.ObsoletedBy))
+		for i := range e.ObsoletedBy {
+			by[i] = rfc(e.ObsoletedBy[i])
+		}
+		obsoleted[rfc(e.DocID)] = by
+	}
+	return obsoleted, nil
+}
+
+// rfc returns "RFC0793" and "RFC 793" as "RFC793", and anything else as is.
+func rfc(s string) string {
+	if m := rfcNumber.FindStringSubmatch(strings.TrimSpace(s)); m != nil {
+		return "RFC" + m[1]
+	}
+	return s
+}
+
+// LintObsolete returns a diagnostic for each RFC cited in doc that is obsoleted according to obsoleted,
+// see ObsoletedBy and ReadRFCIndex. The line numbers are found by searching source, the markdown of doc.
+func CheckObsolete(doc ast.Node, source []byte, obsoleted map[string][]string) []Diagnostic {
+	diags := []Diagnostic{}
+	seen := map[string]bool{}
+	for _, c := range citations(doc) {
+		r := rfc(string(c.dest))
+		by, ok := obsoleted[r]
+		if !ok || seen[r] {
+			continue
+		}
+		seen[r] = true
+		line := lineOf(source, `@[!?-]?`+regexp.QuoteMeta(string(c.dest))+`\b`)
+		diags = append(diags, Diagnostic{Line: line, Check: LintObsolete,
+			Message: fmt.Sprintf("%s is obsoleted by %s", r, strings.Join(by, ", "))})
+	}
+	return diags
+}
+
+// LintUnreferenced returns a diagnostic for each section with an anchor, and each appendix, that isn't
+// referenced in the document with (#anchor) or a link. Doc must be parsed without parser.AutoHeadingIDs,
+// so the headings only have the anchors given in the document, the anchor of an appendix without one is
+// made with MakeSlug. The line numbers are found by searching source, the markdown of doc.
+func CheckUnreferenced(doc ast.Node, source []byte) []Diagnostic {
+	type section struct {
+		anchor string
+		text   string
+		given  bool
+	}
+	sections := []section{}
+	referenced := map[string]bool{}
+	back := false
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch n := node.(type) {
+		case *ast.DocumentMatter:
+			back = n.Matter == ast.DocumentMatterBack
+		case *ast.Heading:
+			if n.IsTitleblock || n.IsSpecial {
+				return ast.SkipChildren
+			}
+			text := strings.TrimSpace(plainText(n))
+			if a := anchor(n); a != "" {
+				sections = append(sections, section{a, text, true})
+			} else if back && n.Level == 1 {
+				sections = append(sections, section{MakeSlug(text, SlugMmark), text, false})
+			}
+			return ast.SkipChildren
+		case *ast.CrossReference:
+			referenced[string(n.Destination)] = true
+		case *ast.Link:
+			if bytes.HasPrefix(n.Destination, []byte("#")) {
+				referenced[string(n.Destination[1:])] = true
+			}
+		}
+		return ast.GoToNext
+	})
+
+	diags := []Diagnostic{}
+	for _, s := range sections {
+		if referenced[s.anchor] {
+			continue
+		}
+		line := 0
+		if s.given {
+			line = lineOf(source, `\{#`+regexp.QuoteMeta(s.anchor)+`[\s}]`)
+		}
+		if line == 0 {
+			line = lineOf(source, `(?m)^#+[ \t]*`+regexp.QuoteMeta(s.text))
+		}
+		diags = append(diags, Diagnostic{Line: line, Check: LintUnreferenced,
+			Message: fmt.Sprintf("section %q (#%s) is never referenced", s.text, s.anchor)})
+	}
+	return diags
+}
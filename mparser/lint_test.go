@@ -0,0 +1,65 @@
+package mparser
+
+import (
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+func TestLint(t *testing.T) {
+	doc := []byte(`# Introduction
+
+The client MUST do this, see [@RFC2616] and (#used).
+
+# Terminology
+
+The key words "MUST" and "MUST NOT" are to be interpreted as described in BCP 14 [@!RFC2119] [@RFC8174].
+
+# Used {#used}
+
+Café.
+
+# Unused {#unused}
+
+# Security Considerations
+`)
+	p := parser.NewWithExtensions(Extensions &^ parser.AutoHeadingIDs)
+	p.Opts = parser.Options{ParserHook: Hook}
+	d := markdown.Parse(doc, p)
+
+	diags := CheckBCP14(d, doc)
+	diags = append(diags, CheckSections(d)...)
+	diags = append(diags, CheckASCII(doc)...)
+	diags = append(diags, CheckObsolete(d, doc, ObsoletedBy)...)
+	diags = append(diags, CheckUnreferenced(d, doc)...)
+	want := []string{
+		`7: RFC8174 is cited by the BCP 14 boilerplate, but not as a normative reference [bcp14-citation]`,
+		`there is no IANA Considerations section [iana-considerations]`,
+		`11: non-ASCII character 'é' (U+00E9) [non-ascii]`,
+		`3: RFC2616 is obsoleted by RFC7230, RFC7231, RFC7232, RFC7233, RFC7234, RFC7235 [obsolete-reference]`,
+		`13: section "Unused" (#unused) is never referenced [unreferenced-section]`,
+	}
+	if len(diags) != len(want) {
+		t.Fatalf("expected %d diagnostics, got %v", len(want), diags)
+	}
+	for i := range want {
+		if diags[i].String() != want[i] {
+			t.Errorf("expected %q, got %q", want[i], diags[i])
+		}
+	}
+}
+
+func TestReadRFCIndex(t *testing.T) {
+	index := []byte(`<rfc-index xmlns="https://www.rfc-editor.org/rfc-index">
+<rfc-entry><doc-id>RFC0793</doc-id><obsoleted-by><doc-id>RFC9293</doc-id></obsoleted-by></rfc-entry>
+<rfc-entry><doc-id>RFC9293</doc-id></rfc-entry>
+</rfc-index>`)
+	obsoleted, err := ReadRFCIndex(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(obsoleted) != 1 || len(obsoleted["RFC793"]) != 1 || obsoleted["RFC793"][0] != "RFC9293" {
+		t.Errorf("expected RFC793 to be obsoleted by RFC9293, got %v", obsoleted)
+	}
+}
@@ -0,0 +1,104 @@
+package mparser
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/mast/reference"
+)
+
+// NormalizeReferences normalizes the author initials and dates of the XML references in doc, as
+// RFC 7322 wants them: initials as "J. M." and dates with the full month name, i.e. "March" instead
+// of "3" or "Mar". References that don't parse are left alone, ValidateReferences reports these.
+func NormalizeReferences(doc ast.Node) {
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		n, ok := node.(*mast.ReferenceBlock)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		var (
+			out []byte
+			err error
+		)
+		if bytes.HasPrefix(n.Literal, []byte("<referencegroup ")) {
+			g := reference.Group{}
+			if xml.Unmarshal(n.Literal, &g) != nil || len(g.Includes) > 0 {
+				return ast.GoToNext
+			}
+			for i := range g.References {
+				normalizeReference(&g.References[i])
+			}
+			out, err = xml.MarshalIndent(g, "", "   ")
+		} else {
+			ref := reference.Reference{}
+			if xml.Unmarshal(n.Literal, &ref) != nil {
+				return ast.GoToNext
+			}
+			normalizeReference(&ref)
+			out, err = xml.MarshalIndent(ref, "", "   ")
+		}
+		if err == nil {
+			n.Literal = out
+		}
+		return ast.GoToNext
+	})
+}
+
+func normalizeReference(ref *reference.Reference) {
+	for i := range ref.Front.Authors {
+		ref.Front.Authors[i].Initials = normalizeInitials(ref.Front.Authors[i].Initials)
+	}
+	if d := ref.Front.Date; d != nil {
+		d.Year = strings.TrimSpace(d.Year)
+		d.Month = normalizeMonth(d.Month)
+		d.Day = strings.TrimLeft(strings.TrimSpace(d.Day), "0")
+	}
+}
+
+// normalizeInitials adds the missing periods and spaces to initials: "J.M" becomes "J. M." and
+// "J.-P" becomes "J.-P.". Initials of more than one letter, like "Ch.", are left as is.
+func normalizeInitials(s string) string {
+	fields := strings.Fields(strings.ReplaceAll(s, ".", ". "))
+	out := []string{}
+	for _, f := range fields {
+		if utf8.RuneCountInString(f) == 1 && f != "-" {
+			f += "."
+		}
+		if strings.HasPrefix(f, "-") && len(out) > 0 {
+			if utf8.RuneCountInString(f) == 2 {
+				f += "."
+			}
+			out[len(out)-1] += f
+			continue
+		}
+		out = append(out, f)
+	}
+	return strings.Join(out, " ")
+}
+
+// normalizeMonth returns the full name of the month m, which can be a number or an abbreviation. If m
+// isn't a month it is returned as is.
+func normalizeMonth(m string) string {
+	m = strings.TrimSpace(m)
+	if n, err := strconv.Atoi(m); err == nil {
+		if n >= 1 && n <= 12 {
+			return time.Month(n).String()
+		}
+		return m
+	}
+	if len(m) < 3 {
+		return m
+	}
+	for i := time.January; i <= time.December; i++ {
+		if strings.HasPrefix(strings.ToLower(i.String()), strings.ToLower(strings.TrimSuffix(m, "."))) {
+			return i.String()
+		}
+	}
+	return m
+}
@@ -0,0 +1,64 @@
+package mparser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+func TestNormalizeInitials(t *testing.T) {
+	for in, want := range map[string]string{
+		"J":      "J.",
+		"J.":     "J.",
+		"J.M":    "J. M.",
+		"J M":    "J. M.",
+		"J.-P":   "J.-P.",
+		"Ch.":    "Ch.",
+		"J. M. ": "J. M.",
+	} {
+		if got := normalizeInitials(in); got != want {
+			t.Errorf("expected %q for %q, got %q", want, in, got)
+		}
+	}
+}
+
+func TestNormalizeMonth(t *testing.T) {
+	for in, want := range map[string]string{
+		"3":       "March",
+		"03":      "March",
+		"Mar":     "March",
+		"sept.":   "September",
+		"March":   "March",
+		"13":      "13",
+		"Unknown": "Unknown",
+	} {
+		if got := normalizeMonth(in); got != want {
+			t.Errorf("expected %q for %q, got %q", want, in, got)
+		}
+	}
+}
+
+func TestNormalizeReferences(t *testing.T) {
+	doc := []byte(`<reference anchor='x' target='http://example.org'>
+<front><title>X</title><author initials='J.M' surname='Doe'/><date year='2020' month='3' day='07'/></front>
+</reference>
+`)
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook}
+	d := markdown.Parse(doc, p)
+	NormalizeReferences(d)
+
+	var ref string
+	for _, c := range d.GetChildren() {
+		if b := c.AsLeaf(); b != nil {
+			ref = string(b.Literal)
+		}
+	}
+	for _, want := range []string{`initials="J. M."`, `month="March"`, `day="7"`} {
+		if !strings.Contains(ref, want) {
+			t.Errorf("expected %s in %s", want, ref)
+		}
+	}
+}
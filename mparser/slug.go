@@ -0,0 +1,171 @@
+package mparser
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	unicd "unicode"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+// Heading anchor styles for AddSlugs.
+const (
+	SlugMmark  = "mmark"  // "Hello, World!" becomes "hello-world", as the parser does.
+	SlugGitHub = "github" // "Hello, World!" becomes "hello-world", "a -- b" becomes "a----b".
+	SlugRFC    = "rfc"    // "Hello, World!" becomes "name-hello-world", as xml2rfc does.
+)
+
+// Slug is the anchor of a heading.
+type Slug struct {
+	Heading string `json:"heading"`
+	Anchor  string `json:"anchor"`
+}
+
+// MakeSlug returns the anchor for a heading with text in style, an unknown style is handled as SlugMmark.
+func MakeSlug(text, style string) string {
+	b := &strings.Builder{}
+	switch style {
+	case SlugGitHub:
+		for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+			switch {
+			case unicd.IsLetter(r) || unicd.IsNumber(r) || unicd.IsMark(r) || r == '_' || r == '-':
+				b.WriteRune(r)
+			case r == ' ':
+				b.WriteRune('-')
+			}
+		}
+		return b.String()
+	case SlugRFC:
+		b.WriteString("name-")
+		dash := false
+		for _, r := range strings.ToLower(text) {
+			switch {
+			case unicd.IsLetter(r) || unicd.IsNumber(r) || r == '_':
+				if dash && b.Len() > len("name-") {
+					b.WriteRune('-')
+				}
+				dash = false
+				b.WriteRune(r)
+			case unicd.IsSpace(r) || r == '-':
+				dash = true
+			}
+		}
+		return b.String()
+	}
+
+	dash := false
+	for _, r := range text {
+		if !unicd.IsLetter(r) && !unicd.IsNumber(r) {
+			dash = true
+			continue
+		}
+		if dash && b.Len() > 0 {
+			b.WriteRune('-')
+		}
+		dash = false
+		b.WriteRune(unicd.ToLower(r))
+	}
+	if b.Len() == 0 {
+		return "empty"
+	}
+	return b.String()
+}
+
+// AddSlugs gives the headings in doc that don't have an anchor one made with MakeSlug from their text in
+// style, with prefix in front of it. The anchors in pinned, from a previous run, are used for the headings
+// with the same text (in order, for headings that have the same text), so links to the document stay
+// valid when the style changes or headings are added. An anchor that is already taken gets "-1", "-2", etc.
+// appended, in document order. AddSlugs returns the anchor of every heading in doc.
+//
+// The parser must not have made anchors for the headings, i.e. parser.AutoHeadingIDs must not be set.
+func AddSlugs(doc ast.Node, style, prefix string, pinned []Slug) []Slug {
+	pins := map[string][]string{}
+	for _, s := range pinned {
+		pins[s.Heading] = append(pins[s.Heading], s.Anchor)
+	}
+
+	headings := []*ast.Heading{}
+	taken := map[string]bool{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch n := node.(type) {
+		case *ast.Heading:
+			if n.IsTitleblock {
+				return ast.GoToNext
+			}
+			headings = append(headings, n)
+			taken[anchor(n)] = true
+		case *ast.CaptionFigure:
+			taken[n.HeadingID] = true
+		}
+		return ast.GoToNext
+	})
+	delete(taken, "")
+
+	// first the pinned anchors, so new headings can't take them.
+	texts := make([]string, len(headings))
+	for i, h := range headings {
+		texts[i] = strings.TrimSpace(plainText(h))
+		if anchor(h) != "" || len(pins[texts[i]]) == 0 {
+			continue
+		}
+		a := pins[texts[i]][0]
+		pins[texts[i]] = pins[texts[i]][1:]
+		if !taken[a] {
+			h.HeadingID = a
+			taken[a] = true
+		}
+	}
+
+	slugs := make([]Slug, len(headings))
+	for i, h := range headings {
+		if anchor(h) == "" {
+			slug := prefix + MakeSlug(texts[i], style)
+			a := slug
+			for n := 1; taken[a]; n++ {
+				a = slug + "-" + strconv.Itoa(n)
+			}
+			h.HeadingID = a
+			taken[a] = true
+		}
+		slugs[i] = Slug{Heading: texts[i], Anchor: anchor(h)}
+	}
+	return slugs
+}
+
+// anchor returns the anchor of h, an {#id} block attribute wins from the heading's id.
+func anchor(h *ast.Heading) string {
+	if h.Attribute != nil && len(h.Attribute.ID) > 0 {
+		return string(h.Attribute.ID)
+	}
+	return h.HeadingID
+}
+
+// ReadSlugs reads the anchors written by WriteSlugs from file. A file that doesn't exist has no anchors.
+func ReadSlugs(file string) ([]Slug, error) {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	slugs := []Slug{}
+	err = json.Unmarshal(data, &slugs)
+	return slugs, err
+}
+
+// WriteSlugs writes slugs as JSON to file.
+func WriteSlugs(file string, slugs []Slug) error {
+	data, err := json.MarshalIndent(slugs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0644)
+}
@@ -0,0 +1,49 @@
+package mparser
+
+import (
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+func TestMakeSlug(t *testing.T) {
+	tests := []struct {
+		text  string
+		style string
+		want  string
+	}{
+		{"Hello, World!", SlugMmark, "hello-world"},
+		{"Hello, World!", SlugGitHub, "hello-world"},
+		{"Hello, World!", SlugRFC, "name-hello-world"},
+		{"A -- B", SlugMmark, "a-b"},
+		{"A -- B", SlugGitHub, "a----b"},
+		{"A -- B", SlugRFC, "name-a-b"},
+		{"snake_case", SlugGitHub, "snake_case"},
+		{"!!", SlugMmark, "empty"},
+	}
+	for _, tc := range tests {
+		if got := MakeSlug(tc.text, tc.style); got != tc.want {
+			t.Errorf("expected %q for %q in style %s, got %q", tc.want, tc.text, tc.style, got)
+		}
+	}
+}
+
+func TestAddSlugs(t *testing.T) {
+	const doc = "# New\n\n# Intro\n\n# Intro\n\n# Own {#own}\n\n# Moved\n"
+	p := parser.NewWithExtensions(Extensions &^ parser.AutoHeadingIDs)
+	d := markdown.Parse([]byte(doc), p)
+	pinned := []Slug{{"Intro", "name-intro"}, {"Moved", "new"}}
+	slugs := AddSlugs(d, SlugRFC, "", pinned)
+
+	// "New" can't take "new" from "Moved" and the second "Intro" isn't pinned.
+	want := []Slug{{"New", "name-new"}, {"Intro", "name-intro"}, {"Intro", "name-intro-1"}, {"Own", "own"}, {"Moved", "new"}}
+	if len(slugs) != len(want) {
+		t.Fatalf("expected %d anchors, got %d", len(want), len(slugs))
+	}
+	for i := range want {
+		if slugs[i] != want[i] {
+			t.Errorf("expected %v, got %v", want[i], slugs[i])
+		}
+	}
+}
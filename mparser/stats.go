@@ -0,0 +1,111 @@
+package mparser
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	unicd "unicode"
+	"unicode/utf8"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// Stats are the numbers of a document, as reported by mmark stats.
+type Stats struct {
+	Words       int       `json:"words"` // Words in the text, code blocks aren't counted.
+	Sections    []Section `json:"sections"`
+	Normative   int       `json:"normative"`   // References cited as normative.
+	Informative int       `json:"informative"` // References only cited as informative.
+	Figures     int       `json:"figures"`
+	Tables      int       `json:"tables"`
+	Longest     int       `json:"longest"`      // Characters in the longest line of a code block.
+	LongestLine int       `json:"longest_line"` // The line in the source of that line.
+}
+
+// Section are the words in a section, without the ones in its subsections.
+type Section struct {
+	Level int    `json:"level"`
+	Title string `json:"title"`
+	Words int    `json:"words"`
+}
+
+// Statistics returns the Stats of doc. A reference that is cited as both normative and informative is
+// counted as normative. Figures are the captioned figures that aren't a table or a quote. Source is the
+// markdown of doc and used to find the line of the longest code line.
+func Statistics(doc ast.Node, source []byte) Stats {
+	s := Stats{}
+	cited := map[string]ast.CitationTypes{}
+	longest := ""
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch n := node.(type) {
+		case *mast.Title, *mast.ReferenceBlock, *mast.Bibliography, *mast.DocumentIndex:
+			return ast.SkipChildren
+		case *ast.Heading:
+			s.Sections = append(s.Sections, Section{Level: n.Level, Title: plainText(n)})
+			return ast.SkipChildren
+		case *ast.Citation:
+			for i, dest := range n.Destination {
+				if t := n.Type[i]; t > cited[string(dest)] {
+					cited[string(dest)] = t
+				}
+			}
+		case *ast.CaptionFigure:
+			if !tableOrQuote(n) {
+				s.Figures++
+			}
+		case *ast.Table:
+			s.Tables++
+		case *ast.CodeBlock:
+			for _, l := range strings.Split(string(n.Literal), "\n") {
+				if c := utf8.RuneCountInString(l); c > s.Longest {
+					s.Longest, longest = c, l
+				}
+			}
+		case *ast.Text, *ast.Code:
+			w := words(node.AsLeaf().Literal)
+			s.Words += w
+			if len(s.Sections) > 0 {
+				s.Sections[len(s.Sections)-1].Words += w
+			}
+		}
+		return ast.GoToNext
+	})
+	for _, t := range cited {
+		switch t {
+		case ast.CitationTypeNormative:
+			s.Normative++
+		case ast.CitationTypeInformative:
+			s.Informative++
+		}
+	}
+	if longest != "" {
+		s.LongestLine = lineOf(source, regexp.QuoteMeta(longest))
+	}
+	return s
+}
+
+// words returns the number of words in text, punctuation isn't a word.
+func words(text []byte) int {
+	n := 0
+	for _, f := range bytes.Fields(text) {
+		if bytes.IndexFunc(f, func(r rune) bool { return unicd.IsLetter(r) || unicd.IsDigit(r) }) >= 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// tableOrQuote returns true if c holds a table or a quote, these aren't figures.
+func tableOrQuote(c *ast.CaptionFigure) bool {
+	for _, child := range c.GetChildren() {
+		switch child.(type) {
+		case *ast.Table, *ast.BlockQuote:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package mparser
+
+import (
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+func TestStatistics(t *testing.T) {
+	doc := []byte(`# Introduction
+
+One two three, see [@!RFC2119] and [@RFC8174].
+
+## Terminology
+
+Four five [@RFC2119].
+
+~~~
+short
+a longer line
+~~~
+Figure: A figure.
+
+| a | b |
+|---|---|
+| c | d |
+Table: A table.
+`)
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook}
+	d := markdown.Parse(doc, p)
+
+	s := Statistics(d, doc)
+	if s.Normative != 1 || s.Informative != 1 {
+		t.Errorf("expected 1 normative and 1 informative reference, got %d and %d", s.Normative, s.Informative)
+	}
+	if s.Figures != 1 || s.Tables != 1 {
+		t.Errorf("expected 1 figure and 1 table, got %d and %d", s.Figures, s.Tables)
+	}
+	if s.Longest != 13 || s.LongestLine != 11 {
+		t.Errorf("expected the longest code line to be 13 characters on line 11, got %d on line %d", s.Longest, s.LongestLine)
+	}
+	if len(s.Sections) != 2 || s.Sections[0].Words != 5 || s.Sections[1].Title != "Terminology" || s.Sections[1].Level != 2 {
+		t.Errorf("expected Introduction (5 words) and Terminology, got %v", s.Sections)
+	}
+}
@@ -0,0 +1,183 @@
+package mparser
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// SVG returns the sanitized contents of the local SVG image src, see SanitizeSVG, so it can be inlined
+// in the output. It returns nil if src is not a local .svg file or can't be read, the latter is
+// reported. Like includes, the file must be on the same level or below the initial file.
+func (i Initial) SVG(src string) []byte {
+	if isURL(src) || strings.Contains(src, ":") || strings.ToLower(path.Ext(src)) != ".svg" {
+		return nil
+	}
+	p := i.path("", src)
+	if i.Flags&UnsafeInclude == 0 && !i.pathAllowed(p) {
+		i.Report.Reportf(SeverityWarning, "svg", "failure to read %q: path is not on or below %q", p, i.i)
+		return nil
+	}
+	data, err := i.readFile(p)
+	if err != nil {
+		i.Report.Reportf(SeverityWarning, "svg", "failure to read SVG: %s", err)
+		return nil
+	}
+	if i.Record != nil {
+		i.Record(p)
+	}
+	svg, err := SanitizeSVG(data)
+	if err != nil {
+		i.Report.Reportf(SeverityWarning, "svg", "failure to parse SVG %q: %s", p, err)
+		return nil
+	}
+	return svg
+}
+
+var svgExternalURL = regexp.MustCompile(`(?i)url\(\s*['"]?[^#'"\s)]|@import`)
+
+var svgJavaScript = regexp.MustCompile(`(?i)javascript\s*:`)
+
+var svgEscape = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+// SanitizeSVG returns svg without the parts that can run code or load external resources: <script>
+// and <foreignObject> elements, event handler attributes, links that don't point into the document or
+// to an embedded (data:image/) image, animations of links, values with a javascript: URL, and styles
+// that refer to an external URL. The XML declaration,
+// doctype and comments are removed and the <svg> element gets the SVG namespace if it lacks it.
+func SanitizeSVG(svg []byte) ([]byte, error) {
+	d := xml.NewDecoder(bytes.NewReader(svg))
+	d.Strict = false
+	out := &bytes.Buffer{}
+	var (
+		next  xml.Token // token read ahead to detect empty elements
+		skip  = 0       // depth in a removed element
+		root  = true
+		depth = 0
+		style = false // in a <style> element
+	)
+	for {
+		var tok xml.Token
+		if next != nil {
+			tok, next = next, nil
+		} else {
+			t, err := d.RawToken()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			tok = xml.CopyToken(t)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if skip > 0 || svgRemoved(t) {
+				skip++
+				continue
+			}
+			if root && t.Name.Local != "svg" {
+				return nil, fmt.Errorf("not an SVG image, the root element is <%s>", t.Name.Local)
+			}
+			out.WriteString("<" + qname(t.Name))
+			xmlns := false
+			for _, a := range t.Attr {
+				if a.Name.Space == "" && a.Name.Local == "xmlns" {
+					xmlns = true
+				}
+				if !svgAllowed(a) {
+					continue
+				}
+				out.WriteString(" " + qname(a.Name) + `="`)
+				out.WriteString(svgEscape.Replace(a.Value))
+				out.WriteString(`"`)
+			}
+			if root && !xmlns {
+				out.WriteString(` xmlns="http://www.w3.org/2000/svg"`)
+			}
+			root = false
+			depth++
+			style = t.Name.Local == "style"
+
+			t2, err := d.RawToken()
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			if end, ok := t2.(xml.EndElement); ok && end.Name == t.Name {
+				out.WriteString("/>")
+				depth--
+				continue
+			}
+			out.WriteString(">")
+			if t2 != nil {
+				next = xml.CopyToken(t2)
+			}
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+				continue
+			}
+			out.WriteString("</" + qname(t.Name) + ">")
+			depth--
+			style = false
+		case xml.CharData:
+			if skip > 0 || depth == 0 {
+				continue
+			}
+			if style && svgExternalURL.Match(t) {
+				continue
+			}
+			out.WriteString(svgEscape.Replace(string(t)))
+		}
+	}
+	if root {
+		return nil, fmt.Errorf("no <svg> element")
+	}
+	return out.Bytes(), nil
+}
+
+// svgRemoved returns true if the element must be removed from the SVG. Next to the elements that embed
+// code or other documents, these are the animations (<set>, <animate>) of a link, they would change it
+// after it is checked.
+func svgRemoved(t xml.StartElement) bool {
+	switch strings.ToLower(t.Name.Local) {
+	case "script", "foreignobject", "iframe", "embed", "object":
+		return true
+	}
+	for _, a := range t.Attr {
+		if strings.ToLower(a.Name.Local) != "attributename" {
+			continue
+		}
+		if v := strings.ToLower(strings.TrimSpace(a.Value)); v == "href" || v == "xlink:href" {
+			return true
+		}
+	}
+	return false
+}
+
+// svgAllowed returns true if the attribute a can stay in the SVG.
+func svgAllowed(a xml.Attr) bool {
+	name := strings.ToLower(a.Name.Local)
+	switch {
+	case strings.HasPrefix(name, "on"):
+		return false
+	case svgJavaScript.MatchString(a.Value):
+		return false
+	case name == "href" || name == "src":
+		v := strings.TrimSpace(a.Value)
+		return strings.HasPrefix(v, "#") || strings.HasPrefix(v, "data:image/")
+	}
+	return !svgExternalURL.MatchString(a.Value)
+}
+
+func qname(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
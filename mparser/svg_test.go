@@ -0,0 +1,74 @@
+package mparser
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/render/xml"
+)
+
+func TestSanitizeSVG(t *testing.T) {
+	const svg = `<?xml version="1.0"?>
+<!-- made by hand -->
+<svg xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 10 10" onload="alert(1)">
+<script>alert(2)</script>
+<style>@import url(https://example.org/x.css);</style>
+<foreignObject><div>html</div></foreignObject>
+<a xlink:href="https://example.org"><rect width="5" height="5" fill="url(#grad)"/></a>
+<image href="https://example.org/x.png"/>
+<text x="1" y="1" style="fill: url(https://example.org/f)">a &lt; b</text>
+<a><set attributeName="href" to="javascript:alert(3)"/><circle r="1"/></a>
+<a href="#c"><animate attributeName="xlink:href" values="#c;javascript:alert(4)"/></a>
+<animate attributeName="opacity" values="0;1" dur="1s"/>
+<text data-x="JavaScript :alert(5)">c</text>
+</svg>`
+	const want = `<svg xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 10 10" xmlns="http://www.w3.org/2000/svg">
+
+<style></style>
+
+<a><rect width="5" height="5" fill="url(#grad)"/></a>
+<image/>
+<text x="1" y="1">a &lt; b</text>
+<a><circle r="1"/></a>
+<a href="#c"></a>
+<animate attributeName="opacity" values="0;1" dur="1s"/>
+<text>c</text>
+</svg>`
+	out, err := SanitizeSVG([]byte(svg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, out)
+	}
+
+	if _, err := SanitizeSVG([]byte(`<html></html>`)); err == nil {
+		t.Error("expected an error for a non SVG document")
+	}
+}
+
+func TestInlineSVG(t *testing.T) {
+	fsys := fstest.MapFS{
+		"docs/main.md":    {},
+		"docs/stack.svg":  {Data: []byte(`<svg xmlns="http://www.w3.org/2000/svg"><script/></svg>`)},
+		"secret/priv.svg": {Data: []byte(`<svg/>`)},
+	}
+	init := NewInitialFS(fsys, "docs/main.md")
+	if init.SVG("../secret/priv.svg") != nil {
+		t.Error("expected no SVG for a file above the initial file")
+	}
+	if init.SVG("https://example.org/x.svg") != nil {
+		t.Error("expected no SVG for a remote image")
+	}
+
+	p := parser.NewWithExtensions(Extensions)
+	d := markdown.Parse([]byte("![Stack](stack.svg)\n"), p)
+	out := markdown.Render(d, xml.NewRenderer(xml.RendererOptions{Flags: xml.CommonFlags | xml.XMLFragment, SVG: init.SVG}))
+	const want = `<artwork type="svg" alt="Stack"><svg xmlns="http://www.w3.org/2000/svg"></svg></artwork>`
+	if !bytes.Contains(out, []byte(want)) {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+}
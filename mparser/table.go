@@ -0,0 +1,80 @@
+package mparser
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// RowSpanMarker is the content of a table cell that is merged with the cell above it.
+const RowSpanMarker = "^^"
+
+// AddRowSpans merges each table cell that only contains RowSpanMarker with the cell above it: that
+// cell gets a "rowspan" attribute and the merged cell a rowspan of 0, see mast.RowSpan. Cells are only
+// merged within the header, body or footer of a table.
+func AddRowSpans(doc ast.Node) {
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		switch node.(type) {
+		case *ast.TableHeader, *ast.TableBody, *ast.TableFooter:
+			if entering {
+				rowSpans(node)
+			}
+			return ast.SkipChildren
+		}
+		return ast.GoToNext
+	})
+}
+
+// rowSpans merges the cells in the rows of section.
+func rowSpans(section ast.Node) {
+	above := map[int]*ast.TableCell{} // the cell each column continues from
+	for _, row := range section.GetChildren() {
+		col := 0
+		for _, c := range row.GetChildren() {
+			cell, ok := c.(*ast.TableCell)
+			if !ok {
+				continue
+			}
+			span := cell.ColSpan
+			if span < 1 {
+				span = 1
+			}
+			if top, ok := above[col]; ok && isRowSpanMarker(cell) {
+				mast.AttributeInit(top)
+				mast.SetAttribute(top, "rowspan", []byte(strconv.Itoa(mast.RowSpan(top)+1)))
+				mast.AttributeInit(cell)
+				mast.SetAttribute(cell, "rowspan", []byte("0"))
+				cell.Children = nil
+			} else {
+				for i := 0; i < span; i++ {
+					above[col+i] = cell
+				}
+			}
+			col += span
+		}
+	}
+}
+
+// isRowSpanMarker returns true if cell only contains RowSpanMarker, with the SuperSubscript extension
+// this is parsed as an empty superscript.
+func isRowSpanMarker(cell *ast.TableCell) bool {
+	sup := 0
+	for _, c := range cell.Children {
+		switch c := c.(type) {
+		case *ast.Superscript:
+			if len(c.Literal) > 0 {
+				return false
+			}
+			sup++
+		case *ast.Text:
+			if strings.TrimSpace(string(c.Literal)) != "" {
+				return strings.TrimSpace(plainText(cell)) == RowSpanMarker
+			}
+		default:
+			return false
+		}
+	}
+	return sup == 1
+}
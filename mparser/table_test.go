@@ -0,0 +1,44 @@
+package mparser
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/render/xml"
+)
+
+func TestAddRowSpans(t *testing.T) {
+	const doc = `Value | Description | Reference
+------|-------------|----------
+0     | Reserved    | RFC 1
+1     | ^^          | RFC 2
+2     | ^^          | RFC 3
+`
+	p := parser.NewWithExtensions(Extensions)
+	d := markdown.Parse([]byte(doc), p)
+	AddRowSpans(d)
+	out := markdown.Render(d, xml.NewRenderer(xml.RendererOptions{Flags: xml.CommonFlags | xml.XMLFragment}))
+
+	const want = `<tbody>
+<tr>
+<td>0</td>
+<td rowspan="3">Reserved</td>
+<td>RFC 1</td>
+</tr>
+
+<tr>
+<td>1</td>
+<td>RFC 2</td>
+</tr>
+
+<tr>
+<td>2</td>
+<td>RFC 3</td>
+</tr>
+</tbody>`
+	if !bytes.Contains(out, []byte(want)) {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+}
@@ -1,23 +1,46 @@
 package mparser
 
 import (
-	"log"
+	"bytes"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/gomarkdown/markdown/ast"
 	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/mast/reference"
+	"gopkg.in/yaml.v3"
+)
+
+// Today is the date used for a title block with date "today" or without a date. It defaults to the
+// current date and can be set to pin the date, i.e. for reproducible builds.
+var Today = today(time.Now())
+
+func today(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+var (
+	todayTOML = regexp.MustCompile(`(?m)^[ \t]*date[ \t]*=[ \t]*"today"[ \t]*$`)
+	todayYAML = regexp.MustCompile(`(?m)^date:[ \t]*["']?today["']?[ \t]*$`)
 )
 
 // TitleHook will parse a title and returns it. The start and ending can
-// be signalled with %%%.
-func TitleHook(data []byte) (ast.Node, []byte, int) {
+// be signalled with %%% for a TOML title block, or with --- for a YAML one.
+// A title block that fails to parse is logged.
+func TitleHook(data []byte) (ast.Node, []byte, int) { return titleHook(data, nil) }
+
+// titleHook is TitleHook, a title block that fails to parse is reported with report.
+func titleHook(data []byte, report Reporter) (ast.Node, []byte, int) {
 	i := 0
 	if len(data) < 4 {
 		return nil, nil, 0
 	}
 
-	c := data[i] // first char must be %
-	if c != '%' {
+	c := data[i] // first char must be % or -
+	if c != '%' && c != '-' {
 		return nil, nil, 0
 	}
 
@@ -25,6 +48,10 @@ func TitleHook(data []byte) (ast.Node, []byte, int) {
 		return nil, nil, 0
 	}
 
+	if c == '-' {
+		return yamlTitle(data, report)
+	}
+
 	i += 3
 	beg := i
 	found := false
@@ -43,15 +70,111 @@ func TitleHook(data []byte) (ast.Node, []byte, int) {
 	node := mast.NewTitle()
 	buf := data[beg:i]
 
-	if c == '-' {
-		node.Content = buf
-		return node, nil, i + 3
+	if _, err := toml.Decode(string(todayTOML.ReplaceAll(buf, nil)), node.TitleData); err != nil {
+		report.Reportf(SeverityError, "title", "failure parsing title block: %s", err)
 	}
-
-	if _, err := toml.Decode(string(buf), node.TitleData); err != nil {
-		log.Printf("Failure parsing title block: %s", err)
+	seriesInfo(node.TitleData)
+	contributors(node.TitleData)
+	if node.Date.IsZero() {
+		node.Date = Today
 	}
 	node.Content = buf
 
 	return node, nil, i + 3
 }
+
+// yamlTitle parses a YAML title block, as used by kramdown-rfc and Hugo. It starts with --- and ends
+// with --- or .... As --- is also a horizontal rule, the block is only a title block if it is a
+// YAML mapping with a title. Keys are matched case insensitively, just as in a TOML title block.
+func yamlTitle(data []byte, report Reporter) (ast.Node, []byte, int) {
+	beg := 3
+	end := -1
+	for i := beg; i < len(data)-3; i++ {
+		if data[i] != '\n' {
+			continue
+		}
+		if l := data[i+1:]; bytes.HasPrefix(l, []byte("---\n")) || bytes.HasPrefix(l, []byte("...\n")) {
+			end = i + 1
+			break
+		}
+	}
+	if end < 0 {
+		return nil, nil, 0
+	}
+	buf := data[beg:end]
+
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(todayYAML.ReplaceAll(buf, nil), doc); err != nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, 0
+	}
+	lowerKeys(doc)
+	if !hasKey(doc.Content[0], "title") {
+		return nil, nil, 0
+	}
+
+	node := mast.NewTitle()
+	if err := doc.Content[0].Decode(node.TitleData); err != nil {
+		report.Reportf(SeverityError, "title", "failure parsing title block: %s", err)
+	}
+	seriesInfo(node.TitleData)
+	contributors(node.TitleData)
+	if node.Date.IsZero() {
+		node.Date = Today
+	}
+	node.Content = buf
+
+	return node, nil, end + 3
+}
+
+// seriesInfo makes the first of seriesInfos the seriesInfo of the document, when only seriesInfos is
+// used, so renderers only need to look at SeriesInfo for the document's name and status.
+func seriesInfo(d *mast.TitleData) {
+	if d.SeriesInfo != (reference.SeriesInfo{}) || len(d.SeriesInfos) == 0 {
+		return
+	}
+	d.SeriesInfo = d.SeriesInfos[0]
+	d.SeriesInfos = d.SeriesInfos[1:]
+}
+
+// contributors moves the authors that are marked as contributor to the contacts, so they are left out
+// of the front page and can be listed in the Contributors section.
+func contributors(d *mast.TitleData) {
+	authors := d.Author[:0]
+	for _, a := range d.Author {
+		if a.Contributor {
+			d.Contact = append(d.Contact, mast.Contact(a))
+			continue
+		}
+		authors = append(authors, a)
+	}
+	d.Author = authors
+}
+
+// lowerKeys lower cases all keys of the mappings in n, this matches the default field names of the
+// YAML decoder. The keys of rfcAttributes are XML attribute names and the ones of displayReference
+// and referenceTypes are anchors, these are left alone.
+func lowerKeys(n *yaml.Node) {
+	if n.Kind != yaml.MappingNode {
+		for _, c := range n.Content {
+			lowerKeys(c)
+		}
+		return
+	}
+	for i := 0; i < len(n.Content); i += 2 {
+		n.Content[i].Value = strings.ToLower(n.Content[i].Value)
+		switch n.Content[i].Value {
+		case "rfcattributes", "displayreference", "referencetypes":
+			continue
+		}
+		lowerKeys(n.Content[i+1])
+	}
+}
+
+func hasKey(n *yaml.Node, key string) bool {
+	for i := 0; i < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return true
+		}
+	}
+	return false
+}
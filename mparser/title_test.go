@@ -0,0 +1,141 @@
+package mparser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+func TestYAMLTitleHook(t *testing.T) {
+	in := `---
+title: A YAML Title
+abbrev: YAML
+date: 2024-01-02
+seriesInfo:
+  name: Internet-Draft
+  value: draft-yaml-00
+keyword: [yaml, toml]
+author:
+- initials: J.
+  surname: Doe
+  fullname: Jane Doe
+  abbrev: Ex
+  address:
+    email: jane@example.org
+---
+
+Text.
+`
+	node, _, consumed := TitleHook([]byte(in))
+	title, ok := node.(*mast.Title)
+	if !ok {
+		t.Fatalf("expected a title block, got %T", node)
+	}
+	if consumed != len("---")+len(title.Content)+len("---") {
+		t.Errorf("expected the whole title block to be consumed, got %d", consumed)
+	}
+	if title.Title != "A YAML Title" || title.Abbrev != "YAML" {
+		t.Errorf("expected title and abbrev to be set, got %q and %q", title.Title, title.Abbrev)
+	}
+	if !title.Date.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected date to be set, got %s", title.Date)
+	}
+	if title.SeriesInfo.Value != "draft-yaml-00" {
+		t.Errorf("expected seriesInfo to be set, got %v", title.SeriesInfo)
+	}
+	if len(title.Keyword) != 2 {
+		t.Errorf("expected 2 keywords, got %v", title.Keyword)
+	}
+	if len(title.Author) != 1 || title.Author[0].OrganizationAbbrev != "Ex" || title.Author[0].Address.Email != "jane@example.org" {
+		t.Errorf("expected author to be set, got %v", title.Author)
+	}
+	if title.Area != "Internet" {
+		t.Errorf("expected default area to be kept, got %q", title.Area)
+	}
+}
+
+func TestYAMLTitleHookRule(t *testing.T) {
+	// horizontal rules around text aren't a title block.
+	for _, in := range []string{
+		"---\n\nSome text.\n\n---\n",
+		"---\nkey: value\n---\n",
+	} {
+		if node, _, _ := TitleHook([]byte(in)); node != nil {
+			t.Errorf("expected no title block for %q, got %T", in, node)
+		}
+	}
+}
+
+func TestTitleHookSeriesInfos(t *testing.T) {
+	in := `%%%
+title = "BCP"
+
+[[seriesInfos]]
+name = "RFC"
+value = "8174"
+stream = "IETF"
+status = "bcp"
+
+[[seriesInfos]]
+name = "BCP"
+value = "14"
+%%%
+`
+	node, _, _ := TitleHook([]byte(in))
+	title, ok := node.(*mast.Title)
+	if !ok {
+		t.Fatalf("expected a title block, got %T", node)
+	}
+	if title.SeriesInfo.Name != "RFC" || title.SeriesInfo.Value != "8174" {
+		t.Errorf("expected the first seriesInfos to be the seriesInfo, got %v", title.SeriesInfo)
+	}
+	if len(title.SeriesInfos) != 1 || title.SeriesInfos[0].Name != "BCP" {
+		t.Errorf("expected one more seriesInfo, got %v", title.SeriesInfos)
+	}
+}
+
+func TestTitleHookToday(t *testing.T) {
+	defer func(d time.Time) { Today = d }(Today)
+	Today = time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	for _, in := range []string{
+		"%%%\ntitle = \"Today\"\ndate = \"today\"\n%%%\n",
+		"%%%\ntitle = \"No date\"\n%%%\n",
+		"---\ntitle: Today\ndate: today\n---\n",
+	} {
+		node, _, _ := TitleHook([]byte(in))
+		title, ok := node.(*mast.Title)
+		if !ok {
+			t.Fatalf("expected a title block for %q, got %T", in, node)
+		}
+		if !title.Date.Equal(Today) {
+			t.Errorf("expected date %s for %q, got %s", Today, in, title.Date)
+		}
+		if e := title.Expires(); !e.Equal(time.Date(2024, 7, 5, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("expected expiry 185 days later, got %s", e)
+		}
+	}
+}
+
+func TestTitleHookDocuments(t *testing.T) {
+	for _, in := range []string{
+		"%%%\ntitle = \"Docs\"\nupdates = [7511, \"draft-foo-bar\", \"RFC 1925\"]\n%%%\n",
+		"---\ntitle: Docs\nupdates: [7511, draft-foo-bar, RFC 1925]\n---\n",
+	} {
+		node, _, _ := TitleHook([]byte(in))
+		title, ok := node.(*mast.Title)
+		if !ok {
+			t.Fatalf("expected a title block for %q, got %T", in, node)
+		}
+		if n := title.Updates.Numbers(); len(n) != 2 || n[0] != 7511 || n[1] != 1925 {
+			t.Errorf("expected RFC numbers 7511 and 1925, got %v", n)
+		}
+		if n := title.Updates.Names(); len(n) != 1 || n[0] != "draft-foo-bar" {
+			t.Errorf("expected draft-foo-bar, got %v", n)
+		}
+		if s := title.Updates.String(); s != "7511, draft-foo-bar, 1925" {
+			t.Errorf("expected %q, got %q", "7511, draft-foo-bar, 1925", s)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package mparser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+var unicodeSyntax = regexp.MustCompile(`\[\[u:([^\]]+)\]\]`)
+var codePoint = regexp.MustCompile(`^U\+([0-9A-Fa-f]{4,6})$`)
+
+// AddUnicode replaces the [[u:...]] syntax in the text of doc with mast.Unicode nodes. The content is a
+// space separated list of code points (U+00E9) and characters, optionally followed by the format, i.e.
+// [[u:U+0065 U+0301 char-num]].
+func AddUnicode(doc ast.Node) {
+	texts := []*ast.Text{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if t, ok := node.(*ast.Text); ok && entering && unicodeSyntax.Match(t.Literal) {
+			texts = append(texts, t)
+		}
+		return ast.GoToNext
+	})
+
+	for _, t := range texts {
+		nodes := []ast.Node{}
+		prev := 0
+		for _, m := range unicodeSyntax.FindAllSubmatchIndex(t.Literal, -1) {
+			u := unicode(string(t.Literal[m[2]:m[3]]))
+			if u == nil {
+				continue
+			}
+			if m[0] > prev {
+				nodes = append(nodes, &ast.Text{Leaf: ast.Leaf{Literal: t.Literal[prev:m[0]]}})
+			}
+			nodes = append(nodes, u)
+			prev = m[1]
+		}
+		if len(nodes) == 0 {
+			continue
+		}
+		if prev < len(t.Literal) {
+			nodes = append(nodes, &ast.Text{Leaf: ast.Leaf{Literal: t.Literal[prev:]}})
+		}
+		replaceText(t, nodes)
+	}
+}
+
+// unicode parses the content of [[u:...]], it returns nil if there are no characters in it.
+func unicode(content string) *mast.Unicode {
+	u := &mast.Unicode{Format: mast.UnicodeFormat}
+	fields := strings.Fields(content)
+	if len(fields) > 1 && mast.IsUnicodeFormat(fields[len(fields)-1]) {
+		u.Format = fields[len(fields)-1]
+		fields = fields[:len(fields)-1]
+	}
+	chars := &strings.Builder{}
+	for _, f := range fields {
+		if m := codePoint.FindStringSubmatch(f); m != nil {
+			r, _ := strconv.ParseUint(m[1], 16, 32)
+			chars.WriteRune(rune(r))
+			continue
+		}
+		chars.WriteString(f)
+	}
+	if chars.Len() == 0 {
+		return nil
+	}
+	u.Literal = []byte(chars.String())
+	return u
+}
@@ -0,0 +1,39 @@
+package mparser
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/render/xml"
+)
+
+func TestAddUnicode(t *testing.T) {
+	const doc = "The letter [[u:U+00E9]] is also [[u:U+0065 U+0301 char-num]], not [[u:]] or [[u:ü lit-num]].\n"
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook}
+	d := markdown.Parse([]byte(doc), p)
+	AddUnicode(d)
+	out := markdown.Render(d, xml.NewRenderer(xml.RendererOptions{Flags: xml.CommonFlags | xml.XMLFragment}))
+
+	const want = "<t>The letter <u format=\"lit-name-num\">é</u> is also <u format=\"char-num\">e\u0301</u>, not [[u:]] or <u format=\"lit-num\">ü</u>.</t>"
+	if !bytes.Contains(out, []byte(want)) {
+		t.Errorf("expected %s in:\n%s", want, out)
+	}
+
+	texts := []string{}
+	ast.WalkFunc(d, func(node ast.Node, entering bool) ast.WalkStatus {
+		if u, ok := node.(*mast.Unicode); ok {
+			texts = append(texts, u.String())
+		}
+		return ast.GoToNext
+	})
+	for i, want := range []string{`"é" (U+00E9)`, "e\u0301 (U+0065 U+0301)", `"ü" (U+00FC)`} {
+		if i >= len(texts) || texts[i] != want {
+			t.Errorf("expected %q, got %q", want, texts)
+		}
+	}
+}
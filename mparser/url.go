@@ -0,0 +1,58 @@
+package mparser
+
+import (
+	"encoding/xml"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/mmarkdown/mmark/v2/mast/reference"
+)
+
+// isURL returns true if the citation anchor is a URL, i.e. [@https://example.org/spec].
+func isURL(anchor string) bool {
+	return strings.HasPrefix(anchor, "https://") || strings.HasPrefix(anchor, "http://")
+}
+
+// URLAnchor returns the anchor used for the reference of the URL u, the characters that aren't
+// allowed in an XML ID are replaced with underscores: https://example.org/spec becomes
+// URL.example.org_spec.
+func URLAnchor(u string) string {
+	u = strings.TrimPrefix(strings.TrimPrefix(u, "https://"), "http://")
+	u = strings.TrimSuffix(u, "/")
+	return "URL." + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, u)
+}
+
+var htmlTitle = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// Page fetches the page at u and returns the XML of a reference with the page's <title> as its title
+// and u as its target, with URLAnchor(u) as anchor.
+func (f *Fetcher) Page(u string) ([]byte, error) {
+	data, err := f.fetch("url/"+url.PathEscape(u)+".html", u, "text/html")
+	if err != nil {
+		return nil, err
+	}
+	title := ""
+	if m := htmlTitle.FindSubmatch(data); m != nil {
+		title = strings.Join(strings.Fields(html.UnescapeString(string(m[1]))), " ")
+	}
+	return urlReference(u, title)
+}
+
+// urlReference returns the XML of the reference for the URL u, if title is empty u is used as the title.
+func urlReference(u, title string) ([]byte, error) {
+	if title == "" {
+		title = u
+	}
+	ref := reference.Reference{Anchor: URLAnchor(u), Target: u}
+	ref.Front.Title = title
+	ref.Front.Date = &reference.Date{}
+	return xml.MarshalIndent(ref, "", "   ")
+}
@@ -0,0 +1,59 @@
+package mparser
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+func TestURLAnchor(t *testing.T) {
+	for u, want := range map[string]string{
+		"https://example.org/spec":      "URL.example.org_spec",
+		"http://example.org/":           "URL.example.org",
+		"https://example.org/a?b=c#d-e": "URL.example.org_a_b_c_d-e",
+	} {
+		if got := URLAnchor(u); got != want {
+			t.Errorf("expected %s for %s, got %s", want, u, got)
+		}
+	}
+}
+
+func TestFetcherPage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/spec" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("<html><head><TITLE>\n  The Spec &amp; More\n</TITLE></head></html>"))
+	}))
+	defer srv.Close()
+
+	f := NewFetcher()
+	f.Cache = t.TempDir()
+
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.Options{ParserHook: Hook}
+	d := markdown.Parse([]byte("See [@"+srv.URL+"/spec] and [@"+srv.URL+"/gone].\n\n{backmatter}\n"), p)
+	if !f.AddReferences(d) {
+		t.Fatal("expected a reference to be added")
+	}
+
+	_, inform := CitationToBibliography(d)
+	refs := map[string]*mast.BibliographyItem{}
+	for _, c := range inform.GetChildren() {
+		item := c.(*mast.BibliographyItem)
+		refs[string(item.Anchor)] = item
+	}
+	spec, gone := refs[URLAnchor(srv.URL+"/spec")], refs[URLAnchor(srv.URL+"/gone")]
+	if spec == nil || spec.Reference == nil || spec.Reference.Front.Title != "The Spec & More" || spec.Reference.Target != srv.URL+"/spec" {
+		t.Errorf("unexpected reference for the spec: %+v", spec)
+	}
+	// A page that can't be fetched uses the URL as the title.
+	if gone == nil || gone.Reference == nil || gone.Reference.Front.Title != srv.URL+"/gone" {
+		t.Errorf("unexpected reference for the missing page: %+v", gone)
+	}
+}
@@ -0,0 +1,124 @@
+package mparser
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+var variable = regexp.MustCompile(`\\?\{\{\s*([A-Za-z0-9_.]+)\s*\}\}`)
+
+// ExpandVariables replaces {{name}} in data with the value of name in the title block, that must
+// start data. The name is a path into the title block, i.e. {{author.0.email}}, elements are matched
+// case insensitively and fields of nested elements (like the author's address) may be left out. Two
+// names are computed: {{docName}} is the value of the seriesInfo and {{expires}} the expiration date
+// of a draft. Names that aren't found are left alone, as these may be includes. A variable is not
+// expanded when escaped as \{{name}}. The expansion is done before parsing, so it works in code
+// blocks and link destinations too.
+func ExpandVariables(data []byte) []byte {
+	i := 0
+	for i < len(data) && (data[i] == '\n' || data[i] == ' ') {
+		i++
+	}
+	node, _, consumed := titleHook(data[i:], func(Diagnostic) {}) // reported when the document is parsed.
+	t, ok := node.(*mast.Title)
+	if !ok || t.TitleData == nil {
+		return data
+	}
+	i += consumed
+
+	body := variable.ReplaceAllFunc(data[i:], func(v []byte) []byte {
+		if v[0] == '\\' {
+			return v
+		}
+		name := string(variable.FindSubmatch(v)[1])
+		value, ok := lookup(t.TitleData, name)
+		if !ok {
+			return v
+		}
+		return []byte(value)
+	})
+	return append(data[:i:i], body...)
+}
+
+// lookup returns the value of name in the title block d.
+func lookup(d *mast.TitleData, name string) (string, bool) {
+	switch strings.ToLower(name) {
+	case "docname":
+		return d.SeriesInfo.Value, d.SeriesInfo.Value != ""
+	case "expires":
+		e := d.Expires()
+		return format(reflect.ValueOf(e)), !e.IsZero()
+	}
+
+	v := reflect.ValueOf(*d)
+	for _, elem := range strings.Split(name, ".") {
+		var ok bool
+		if v, ok = element(v, elem); !ok {
+			return "", false
+		}
+	}
+	s := format(v)
+	return s, s != ""
+}
+
+// element returns the field or slice element named elem of v.
+func element(v reflect.Value, elem string) (reflect.Value, bool) {
+	switch v.Kind() {
+	case reflect.Slice:
+		i, err := strconv.Atoi(elem)
+		if err != nil || i < 0 || i >= v.Len() {
+			return reflect.Value{}, false
+		}
+		return v.Index(i), true
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(time.Time{}) {
+			return reflect.Value{}, false
+		}
+		typ := v.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			tag, _, _ := strings.Cut(f.Tag.Get("toml"), ",")
+			if strings.EqualFold(f.Name, elem) || (tag != "" && strings.EqualFold(tag, elem)) {
+				return v.Field(i), true
+			}
+		}
+		// Not found, look in the nested elements, so author.0.email works.
+		for i := 0; i < typ.NumField(); i++ {
+			if v.Field(i).Kind() != reflect.Struct {
+				continue
+			}
+			if f, ok := element(v.Field(i), elem); ok {
+				return f, true
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// format returns the value of v as text.
+func format(v reflect.Value) string {
+	switch x := v.Interface().(type) {
+	case time.Time:
+		if x.IsZero() {
+			return ""
+		}
+		return x.Format("2006-01-02")
+	case fmt.Stringer:
+		return x.String()
+	case []string:
+		return strings.Join(x, ", ")
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool, reflect.Int:
+		return fmt.Sprint(v.Interface())
+	}
+	return ""
+}
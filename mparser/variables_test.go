@@ -0,0 +1,39 @@
+package mparser
+
+import "testing"
+
+func TestExpandVariables(t *testing.T) {
+	title := `%%%
+title = "Test"
+date = 2024-01-02T00:00:00Z
+[seriesInfo]
+name = "Internet-Draft"
+value = "draft-test-03"
+[[author]]
+fullname = "Jane Doe"
+[author.address]
+email = "jane@example.org"
+%%%
+`
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{"{{docName}} is here\n", "draft-test-03 is here\n"},
+		{"by <{{ author.0.email }}>\n", "by <jane@example.org>\n"},
+		{"{{date}} {{expires}} {{Title}}\n", "2024-01-02 2024-07-05 Test\n"},
+		{"{{author.1.email}} {{include.md}}\n", "{{author.1.email}} {{include.md}}\n"},
+		{"\\{{docName}}\n", "\\{{docName}}\n"},
+	}
+	for _, test := range tests {
+		out := string(ExpandVariables([]byte(title + test.in)))
+		if out != title+test.out {
+			t.Errorf("expected %q, got %q", title+test.out, out)
+		}
+	}
+
+	// without a title block nothing is expanded.
+	if out := string(ExpandVariables([]byte("{{docName}}\n"))); out != "{{docName}}\n" {
+		t.Errorf("expected no expansion, got %q", out)
+	}
+}
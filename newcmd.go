@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mmarkdown/mmark/v2/mparser"
+)
+
+// AuthorFile is the file, in the mmark directory of the user's configuration directory, with the
+// [[author]] tables that `mmark new` puts in the title block.
+const AuthorFile = "author.toml"
+
+// newCommand creates the skeleton of a new Internet-Draft in NAME.md.
+func newCommand(args []string) int {
+	fs := flag.NewFlagSet("new", flag.ExitOnError)
+	author := fs.String("author", authorFile(), "file with the [[author]] tables for the title block")
+	title := fs.String("title", "", "title of the document, defaults to the topic in the name")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "SYNOPSIS: %s new [OPTIONS] %s\n", os.Args[0], "NAME")
+		fmt.Fprintln(fs.Output(), "\nCreate a new Internet-Draft, i.e. draft-ietf-wg-topic, in NAME.md.\n\nOPTIONS:")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	name := strings.TrimSuffix(fs.Arg(0), ".md")
+	name = draftRevision.ReplaceAllString(name, "")
+	if !draftName.MatchString(name) {
+		reporter.report("", mparser.Diagnostic{Message: fmt.Sprintf("not a draft name %q, use draft-<name or wg>-<topic>", fs.Arg(0)),
+			Check: "usage", Severity: mparser.SeverityError})
+		return 1
+	}
+	authors, err := os.ReadFile(*author)
+	if err != nil && !os.IsNotExist(err) {
+		reporter.reportError(*author, "read", err)
+		return 1
+	}
+	file := name + ".md"
+	if _, err := os.Stat(file); err == nil {
+		reporter.report(file, mparser.Diagnostic{Message: "not overwriting it", Check: "write", Severity: mparser.SeverityError})
+		return 1
+	}
+	d, err := skeleton(name, *title, authors)
+	if err == nil {
+		err = os.WriteFile(file, d, 0644)
+	}
+	if err != nil {
+		reporter.reportError(file, "write", err)
+		return 1
+	}
+	return 0
+}
+
+// authorFile returns the path of AuthorFile, or the empty string if there is no configuration directory.
+func authorFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "mmark", AuthorFile)
+}
+
+var (
+	draftName     = regexp.MustCompile(`^draft-[a-z0-9]+(-[a-z0-9]+)+$`)
+	draftRevision = regexp.MustCompile(`-\d\d$`)
+)
+
+// skeleton returns the markdown of a new draft called name: the title block, the sections every draft
+// must have and the back matter. The authors are the [[author]] tables for the title block, when empty
+// a placeholder author is used. The stream and the workgroup are taken from name,
+// draft-ietf-wg-topic is in the IETF stream and from the "WG Working Group".
+func skeleton(name, title string, authors []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(authors)) == 0 {
+		authors = []byte("[[author]]\ninitials = \"A.\"\nsurname = \"Author\"\nfullname = \"Anne Author\"\norganization = \"\"\n  [author.address]\n  email = \"author@example.org\"\n")
+	}
+	if _, err := toml.Decode(string(authors), &struct{}{}); err != nil {
+		return nil, fmt.Errorf("authors: %s", err)
+	}
+
+	parts := strings.Split(name, "-")
+	stream, workgroup, topic := "IETF", "Network Working Group", parts[2:]
+	switch {
+	case parts[1] == "ietf" && len(parts) > 3:
+		workgroup, topic = strings.ToUpper(parts[2])+" Working Group", parts[3:]
+	case parts[1] == "irtf" && len(parts) > 3:
+		stream, workgroup, topic = "IRTF", strings.ToUpper(parts[2])+" Research Group", parts[3:]
+	}
+	if title == "" {
+		for i := range topic {
+			topic[i] = strings.ToUpper(topic[i][:1]) + topic[i][1:]
+		}
+		title = strings.Join(topic, " ")
+	}
+
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "%%%%%%\ntitle = %q\nabbrev = %q\nipr = \"trust200902\"\narea = \"\"\n", title, title)
+	fmt.Fprintf(b, "workgroup = %q\nsubmissiontype = %q\nkeyword = [\"\"]\n", workgroup, stream)
+	fmt.Fprintf(b, "date = %s\n\n", mparser.Today.Format("2006-01-02T15:04:05Z"))
+	fmt.Fprintf(b, "[seriesInfo]\nname = \"Internet-Draft\"\nvalue = \"%s-00\"\nstream = %q\nstatus = \"standard\"\n\n", name, stream)
+	b.Write(bytes.TrimSpace(authors))
+	b.WriteString("\n%%%\n\n")
+	b.WriteString(".# Abstract\n\nThis document ...\n\n{mainmatter}\n\n")
+	b.WriteString("# Introduction\n\n## Terminology\n\n")
+	b.WriteString("The key words \"MUST\", \"MUST NOT\", \"REQUIRED\", \"SHALL\", \"SHALL NOT\", \"SHOULD\", \"SHOULD NOT\",\n")
+	b.WriteString("\"RECOMMENDED\", \"NOT RECOMMENDED\", \"MAY\", and \"OPTIONAL\" in this document are to be interpreted as\n")
+	b.WriteString("described in BCP 14 [@!RFC2119] [@!RFC8174] when, and only when, they appear in all capitals, as\n")
+	b.WriteString("shown here.\n\n")
+	b.WriteString("# Security Considerations\n\nTODO\n\n")
+	b.WriteString("# IANA Considerations\n\nThis document has no IANA actions.\n\n")
+	b.WriteString("{backmatter}\n")
+	return b.Bytes(), nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSkeleton(t *testing.T) {
+	authors := "[[author]]\nfullname = \"Miek Gieben\"\n"
+	d, err := skeleton("draft-irtf-cfrg-fast-hash", "", []byte(authors))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`title = "Fast Hash"`, `workgroup = "CFRG Research Group"`, `submissiontype = "IRTF"`,
+		`value = "draft-irtf-cfrg-fast-hash-00"`, authors, "# Security Considerations", "# IANA Considerations", "{backmatter}",
+	} {
+		if !strings.Contains(string(d), s) {
+			t.Errorf("expected skeleton to contain %q", s)
+		}
+	}
+
+	if _, err := skeleton("draft-gieben-foo", "", []byte("[[author]\n")); err == nil {
+		t.Errorf("expected an error for invalid TOML")
+	}
+}
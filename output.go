@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mmarkdown/mmark/v2/mparser"
+	"github.com/mmarkdown/mmark/v2/render/man"
+)
+
+// outputFlag is a flag that can be repeated, it holds the files to render to.
+type outputFlag []string
+
+func (o *outputFlag) String() string { return "" }
+
+func (o *outputFlag) Set(s string) error {
+	if _, ok := outputOf(s); !ok {
+		return fmt.Errorf("unknown extension of %q", s)
+	}
+	*o = append(*o, s)
+	return nil
+}
+
+// extensions maps the extension of a file to the output that is rendered to it. The first extension of
+// an output is used for the files of -formats.
+var extensions = []struct {
+	ext    string
+	output string
+}{
+	{".slides.html", "slides"}, {".html", "html"}, {".htm", "html"}, {".epub", "epub"}, {".odt", "odt"},
+	{".pdf", "pdf"}, {".txt", "text"}, {".jats.xml", "jats"}, {".xml", "xml"}, {".pandoc.json", "pandoc"},
+	{".meta.json", "meta"}, {".json", "json"}, {".1", "man"}, {".mdoc", "mdoc"}, {".tex", "latex"},
+	{".adoc", "asciidoc"}, {".gmi", "gemtext"},
+}
+
+// outputOf returns the output for file, going by its extension.
+func outputOf(file string) (string, bool) {
+	for _, e := range extensions {
+		if strings.HasSuffix(file, e.ext) {
+			return e.output, true
+		}
+	}
+	return "", false
+}
+
+// formatOf returns the output for format, an output of -formats. This is the name of the output, i.e.
+// "text", or the extension of its files, with or without the dot, i.e. "txt" or ".1".
+func formatOf(format string) (string, bool) {
+	if isOutput(format) {
+		return format, true
+	}
+	for _, e := range extensions {
+		if e.ext == "."+strings.TrimPrefix(format, ".") {
+			return e.output, true
+		}
+	}
+	return "", false
+}
+
+// extensionOf returns the extension of the files of output.
+func extensionOf(output string) string {
+	for _, e := range extensions {
+		if e.output == output {
+			return e.ext
+		}
+	}
+	return ""
+}
+
+// binary returns true if output is a binary format, these don't get a trailing newline.
+func binary(output string) bool {
+	return output == "epub" || output == "pdf" || output == "odt"
+}
+
+// target is a file and the output that is rendered to it. The file of a -formats output is relative to
+// the document, see targetFile.
+type target struct {
+	output string
+	file   string
+	outdir string
+}
+
+// outputTargets returns the targets of the -o files and the -formats outputs, see formatOf, which are
+// written in outdir. If there are none and outdir is given, the output selected with the flags is written in it.
+func outputTargets(files []string, formats, outdir string) ([]target, error) {
+	targets := []target{}
+	if len(files) == 0 && strings.TrimSpace(formats) == "" && outdir != "" {
+		return []target{{output: output(), outdir: outdir}}, nil
+	}
+	for _, f := range files {
+		output, _ := outputOf(f)
+		targets = append(targets, target{output: output, file: f})
+	}
+	for _, format := range strings.Split(formats, ",") {
+		if format = strings.TrimSpace(format); format == "" {
+			continue
+		}
+		output, ok := formatOf(format)
+		if !ok {
+			return nil, fmt.Errorf("unknown output %q", format)
+		}
+		targets = append(targets, target{output: output, outdir: outdir})
+	}
+	return targets, nil
+}
+
+// targetOutputs returns the outputs of targets, without duplicates, or the output selected with the
+// flags if there are no targets.
+func targetOutputs(targets []target) []string {
+	if len(targets) == 0 {
+		return []string{output()}
+	}
+	outputs := []string{}
+	for _, t := range targets {
+		if !slices.Contains(outputs, t.output) {
+			outputs = append(outputs, t.output)
+		}
+	}
+	return outputs
+}
+
+// targetFile returns the file t is written to for the document in, for a -formats target that is the
+// name of the document with the extension of the output, in the outdir.
+func targetFile(t target, in input) string {
+	if t.file != "" {
+		return t.file
+	}
+	return filepath.Join(t.outdir, in.name+extensionOf(t.output))
+}
+
+// renderTargets renders the document d of in to all targets at the same time and writes them. The
+// document is read from disk once, but each output parses its own copy of d: the parser changes the
+// data it parses and the renderers change the AST, so neither can be shared. An incomplete
+// document isn't written, so it doesn't replace a good one. It returns the highest status of the
+// outputs, or 1 if a file couldn't be written.
+func renderTargets(in input, d []byte, targets []target) int {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		status int
+	)
+	for i, t := range targets {
+		wg.Add(1)
+		go func(t target, primary bool) {
+			defer wg.Done()
+			x, pages, s := convert(in.file, append([]byte(nil), d...), t.output, primary)
+			if s != 0 && (x != nil || pages != nil) {
+				reporter.report(targetFile(t, in), mparser.Diagnostic{Message: "not writing it, the document is incomplete", Check: "write",
+					Severity: mparser.SeverityError})
+				x, pages = nil, nil
+			}
+			if pages != nil {
+				if !writePages(filepath.Dir(targetFile(t, in)), pages) {
+					s = 1
+				}
+				x = joinPages(pages, targetFile(t, in))
+			}
+			if x != nil {
+				if !binary(t.output) {
+					x = append(x, '\n')
+				}
+				if err := writeFile(targetFile(t, in), x); err != nil {
+					reporter.reportError(targetFile(t, in), "write", err)
+					s = 1
+				}
+			}
+			mu.Lock()
+			if s > status {
+				status = s
+			}
+			mu.Unlock()
+		}(t, i == 0)
+	}
+	wg.Wait()
+	return status
+}
+
+// writePages writes the manual pages a document is split in to dir, errors are reported. It returns false
+// if a page couldn't be written.
+func writePages(dir string, pages []man.Page) bool {
+	ok := true
+	for _, page := range pages {
+		file := filepath.Join(dir, page.Name)
+		if err := writeFile(file, page.Data); err != nil {
+			reporter.reportError(file, "write", err)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// joinPages returns the manual pages, one after the other, to write to the target file, as one manual
+// page file can hold more than one page. It returns nil when file is one of the pages.
+func joinPages(pages []man.Page, file string) []byte {
+	var b bytes.Buffer
+	for _, page := range pages {
+		if filepath.Join(filepath.Dir(file), page.Name) == filepath.Clean(file) {
+			return nil
+		}
+		b.Write(page.Data)
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n"))
+}
+
+// writeFile writes data to file, through a temporary file that is renamed to file, so file is either the
+// old or the new version, and never a partial one.
+func writeFile(file string, data []byte) error {
+	dir := filepath.Dir(file)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(file))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // fails after the rename.
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(0644)
+	}
+	if err1 := tmp.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
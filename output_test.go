@@ -0,0 +1,120 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestOutputOf(t *testing.T) {
+	tests := map[string]string{
+		"draft.xml":         "xml",
+		"draft.jats.xml":    "jats",
+		"draft.html":        "html",
+		"draft.slides.html": "slides",
+		"draft.txt":         "text",
+		"mmark.1":           "man",
+	}
+	for file, expected := range tests {
+		if output, _ := outputOf(file); output != expected {
+			t.Errorf("expected %q for %q, got %q", expected, file, output)
+		}
+	}
+	if _, ok := outputOf("draft.md"); ok {
+		t.Errorf("expected no output for %q", "draft.md")
+	}
+}
+
+func TestRenderTargets(t *testing.T) {
+	dir := t.TempDir()
+	targets, err := outputTargets([]string{filepath.Join(dir, "draft.txt")}, "xml,html", filepath.Join(dir, "dist"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := []byte("# Introduction\r\n\r\nHello World.\r\n")
+	if status := renderTargets(input{file: "docs/draft.md", name: "draft"}, d, targets); status != 0 {
+		t.Fatalf("expected status 0, got %d", status)
+	}
+	if string(d) != "# Introduction\r\n\r\nHello World.\r\n" {
+		t.Errorf("expected the document to be left alone, got %q", d) // the parser normalizes the newlines in place.
+	}
+	for _, file := range []string{"draft.txt", "dist/draft.xml", "dist/draft.html"} {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "Hello World.") {
+			t.Errorf("expected %q to contain the document, got %q", file, data)
+		}
+	}
+
+	targets, err = outputTargets(nil, "txt, .1,tex,jats.xml,man", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outputs := targetOutputs(targets); !slices.Equal(outputs, []string{"text", "man", "latex", "jats"}) {
+		t.Errorf("expected the outputs of the extensions, got %v", outputs)
+	}
+	if _, err := outputTargets(nil, "xml,docx", dir); err == nil {
+		t.Errorf("expected an error for an unknown output")
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "out", "draft.xml")
+	for _, data := range []string{"old", "new"} {
+		if err := writeFile(file, []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new" {
+		t.Errorf("expected %q, got %q", "new", data)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(file))
+	if len(entries) != 1 {
+		t.Errorf("expected only %q, got %d files", file, len(entries))
+	}
+}
+
+func TestRenderTargetsIncomplete(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "draft.xml")
+	if err := os.WriteFile(file, []byte("good"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	targets, _ := outputTargets(nil, "", dir)
+	d := []byte("# Introduction\n\n{{no-such-file.md}}\n")
+	if status := renderTargets(input{file: filepath.Join(dir, "draft.md"), name: "draft"}, d, targets); status != 1 {
+		t.Fatalf("expected status 1, got %d", status)
+	}
+	if data, _ := os.ReadFile(file); string(data) != "good" {
+		t.Errorf("expected %q to be left alone, got %q", file, data)
+	}
+}
+
+func TestRenderTargetsManPages(t *testing.T) {
+	dir := t.TempDir()
+	targets, err := outputTargets(nil, "man", filepath.Join(dir, "build"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := []byte("# foo(1) {#foo.1}\n\n## NAME\n\nfoo - does foo\n\n# bar(8) {#bar.8}\n\n## NAME\n\nbar - does bar\n")
+	if status := renderTargets(input{file: "tools.md", name: "tools"}, d, targets); status != 0 {
+		t.Fatalf("expected status 0, got %d", status)
+	}
+	for file, want := range map[string]string{"foo.1": `.TH "FOO" 1`, "bar.8": `.TH "BAR" 8`, "tools.1": `.TH "BAR" 8`} {
+		data, err := os.ReadFile(filepath.Join(dir, "build", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %q to contain %q, got %q", file, want, data)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// stopProfiles stops the profiles started with startProfiles, it is called by exit.
+var stopProfiles = func() {}
+
+// startProfiles starts the CPU profile, written to cpu, and arranges for the heap profile to be written
+// to mem when mmark exits. Either may be empty. The profiles are for go tool pprof.
+func startProfiles(cpu, mem string) error {
+	if cpu != "" {
+		f, err := os.Create(cpu)
+		if err != nil {
+			return err
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return fmt.Errorf("%s: %s", cpu, err)
+		}
+		stop := stopProfiles
+		stopProfiles = func() {
+			pprof.StopCPUProfile()
+			f.Close()
+			stop()
+		}
+	}
+	if mem != "" {
+		stop := stopProfiles
+		stopProfiles = func() {
+			stop()
+			f, err := os.Create(mem)
+			if err != nil {
+				reporter.reportError(mem, "profile", err)
+				return
+			}
+			defer f.Close()
+			runtime.GC() // get up-to-date statistics.
+			if err := pprof.WriteHeapProfile(f); err != nil {
+				reporter.reportError(mem, "profile", err)
+			}
+		}
+	}
+	return nil
+}
+
+// exit stops the profiles and exits with status.
+func exit(status int) {
+	stopProfiles()
+	os.Exit(status)
+}
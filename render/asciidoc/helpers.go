@@ -0,0 +1,184 @@
+package asciidoc
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+// outs writes s and keeps track of the last bytes written, so we know if we're at the start of a line.
+func (r *Renderer) outs(w io.Writer, s string) {
+	if s == "" {
+		return
+	}
+	io.WriteString(w, s)
+	r.tail += s
+	if len(r.tail) > 2 {
+		r.tail = r.tail[len(r.tail)-2:]
+	}
+}
+
+func (r *Renderer) out(w io.Writer, d []byte) { r.outs(w, string(d)) }
+
+// bol returns true if we're at the beginning of a line.
+func (r *Renderer) bol() bool { return r.tail == "" || strings.HasSuffix(r.tail, "\n") }
+
+// cr makes sure the next output starts on a new line.
+func (r *Renderer) cr(w io.Writer) {
+	if !r.bol() {
+		r.outs(w, "\n")
+	}
+}
+
+// blank makes sure the next output is preceded by an empty line.
+func (r *Renderer) blank(w io.Writer) {
+	if r.tail == "" || r.tail == "\n\n" {
+		return
+	}
+	r.cr(w)
+	r.outs(w, "\n")
+}
+
+func (r *Renderer) outOneOf(w io.Writer, outFirst bool, first string, second string) {
+	if outFirst {
+		r.outs(w, first)
+	} else {
+		r.outs(w, second)
+	}
+}
+
+// escaper replaces the characters that start inline formatting, cross references and anchors with
+// AsciiDoc's character replacement attributes. A backslash escape only works when a valid construct
+// follows, otherwise the backslash is output as well.
+var escaper = strings.NewReplacer(
+	`*`, `{asterisk}`,
+	"`", `{backtick}`,
+	`^`, `{caret}`,
+	`~`, `{tilde}`,
+	`+`, `{plus}`,
+	`<<`, `{lt}<`,
+	`[[`, `{startsb}[`,
+)
+
+// Escape returns s with the AsciiDoc formatting characters escaped.
+func Escape(s string) string { return escaper.Replace(s) }
+
+// text writes text escaped, lines that would be interpreted as a block delimiter, list item or
+// attribute entry are prefixed with {empty}.
+func (r *Renderer) text(w io.Writer, s string) {
+	s = Escape(s)
+	if r.inTable {
+		s = strings.ReplaceAll(s, "|", `\|`)
+	}
+	lines := strings.SplitAfter(s, "\n")
+	for i, l := range lines {
+		if (i > 0 || r.bol()) && lineStart(l) {
+			l = "{empty}" + l
+		}
+		r.outs(w, l)
+	}
+}
+
+// lineStart returns true if l starts with something that has a meaning at the start of a line.
+func lineStart(l string) bool {
+	for _, p := range []string{"=", ".", "-", "|", "//", ":", "[", "'", "<", ">"} {
+		if strings.HasPrefix(l, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// attribute outputs an attribute entry line, if value is empty nothing is output.
+func (r *Renderer) attribute(w io.Writer, name, value string) {
+	if value == "" {
+		return
+	}
+	r.outs(w, ":"+name+": "+strings.ReplaceAll(value, "\n", " ")+"\n")
+}
+
+// anchor outputs a block anchor for node, if it has an ID.
+func (r *Renderer) anchor(w io.Writer, node ast.Node) {
+	if id := nodeID(node); id != "" {
+		r.outs(w, "[["+id+"]]\n")
+	}
+}
+
+// nodeID returns the ID from the attribute of node, or the heading ID.
+func nodeID(node ast.Node) string {
+	switch n := node.(type) {
+	case *ast.Heading:
+		return n.HeadingID
+	case *ast.CaptionFigure:
+		if n.HeadingID != "" {
+			return n.HeadingID
+		}
+	}
+	var a *ast.Attribute
+	if c := node.AsContainer(); c != nil {
+		a = c.Attribute
+	}
+	if l := node.AsLeaf(); l != nil {
+		a = l.Attribute
+	}
+	if a == nil {
+		return ""
+	}
+	return string(a.ID)
+}
+
+// depth returns how many lists of the same kind as list are its ancestors, including itself.
+func depth(list *ast.List) int {
+	kind := list.ListFlags & (ast.ListTypeOrdered | ast.ListTypeDefinition)
+	d := 0
+	for n := ast.Node(list); n != nil; n = n.GetParent() {
+		if l, ok := n.(*ast.List); ok && l.ListFlags&(ast.ListTypeOrdered|ast.ListTypeDefinition) == kind {
+			d++
+		}
+	}
+	return d
+}
+
+// cols returns the cols attribute for a table, derived from the first row.
+func cols(tab *ast.Table) string {
+	spec := []string{}
+	ast.WalkFunc(tab, func(node ast.Node, entering bool) ast.WalkStatus {
+		row, ok := node.(*ast.TableRow)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		for _, c := range row.GetChildren() {
+			cell := c.(*ast.TableCell)
+			n := cell.ColSpan
+			if n < 1 {
+				n = 1
+			}
+			for i := 0; i < n; i++ {
+				spec = append(spec, cellAlign(cell.Align))
+			}
+		}
+		return ast.Terminate
+	})
+	return strings.Join(spec, ",")
+}
+
+func cellAlign(a ast.CellAlignFlags) string {
+	switch a {
+	case ast.TableAlignmentRight:
+		return ">"
+	case ast.TableAlignmentCenter:
+		return "^"
+	}
+	return "<"
+}
+
+// delimiter returns a block delimiter made of c that doesn't occur in literal.
+func delimiter(c string, literal []byte) string {
+	d := strings.Repeat(c, 4)
+	for bytes.Contains(literal, []byte("\n"+d)) || bytes.HasPrefix(literal, []byte(d)) {
+		d += c
+	}
+	return d
+}
@@ -0,0 +1,57 @@
+package asciidoc
+
+import (
+	"bytes"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gomarkdown/markdown/parser"
+)
+
+// ReadInclude returns a parser.ReadIncludeFunc that keeps includes of files that aren't markdown as
+// AsciiDoc include directives, for code includes these end up in a source block. Markdown files, and
+// includes that use an address that can't be expressed in AsciiDoc, are read with read and converted.
+func ReadInclude(read parser.ReadIncludeFunc) parser.ReadIncludeFunc {
+	return func(from, file string, address []byte) []byte {
+		switch strings.ToLower(path.Ext(file)) {
+		case ".md", ".markdown", ".mmark":
+			return read(from, file, address)
+		}
+		attrs, ok := includeAttributes(address)
+		if !ok {
+			return read(from, file, address)
+		}
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(from, file)
+		}
+		return []byte("include::" + filepath.ToSlash(file) + "[" + attrs + "]\n")
+	}
+}
+
+// includeAttributes converts an include address to the attributes of an include directive. Only
+// line ranges (N,M) can be converted.
+func includeAttributes(address []byte) (string, bool) {
+	address = bytes.TrimSpace(address)
+	if len(address) == 0 {
+		return "", true
+	}
+	lines := strings.Split(string(address), ",")
+	if len(lines) != 2 {
+		return "", false
+	}
+	lo, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	if err != nil {
+		return "", false
+	}
+	hi := -1
+	if s := strings.TrimSpace(lines[1]); s != "" {
+		// the end line is exclusive in mmark.
+		if hi, err = strconv.Atoi(s); err != nil {
+			return "", false
+		}
+		hi--
+	}
+	return "lines=" + strconv.Itoa(lo) + ".." + strconv.Itoa(hi), true
+}
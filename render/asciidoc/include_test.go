@@ -0,0 +1,23 @@
+package asciidoc
+
+import "testing"
+
+func TestIncludeAttributes(t *testing.T) {
+	tests := []struct {
+		address string
+		attrs   string
+		ok      bool
+	}{
+		{"", "", true},
+		{"4,9", "lines=4..8", true},
+		{"4,", "lines=4..-1", true},
+		{"/start/,/end/", "", false},
+		{"prefix=\"C\"", "", false},
+	}
+	for _, tc := range tests {
+		attrs, ok := includeAttributes([]byte(tc.address))
+		if attrs != tc.attrs || ok != tc.ok {
+			t.Errorf("address %q: got %q, %t, want %q, %t", tc.address, attrs, ok, tc.attrs, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,620 @@
+// The package asciidoc outputs AsciiDoc from mmark markdown. The title block is converted to the
+// document header with attribute entries.
+package asciidoc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/v2/lang"
+	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/mast/reference"
+)
+
+// Flags control optional behavior of AsciiDoc renderer.
+type Flags int
+
+// AsciiDoc renderer configuration options.
+const (
+	FlagsNone        Flags = 0
+	AsciiDocFragment Flags = 1 << iota // Don't generate a document header
+
+	CommonFlags Flags = FlagsNone
+)
+
+// RendererOptions is a collection of supplementary parameters tweaking
+// the behavior of various parts of AsciiDoc renderer.
+type RendererOptions struct {
+	Flags Flags // Flags allow customizing this renderer's behavior
+
+	Language lang.Lang // Output language for the document.
+
+	// if set, called at the start of RenderNode(). Allows replacing rendering of some nodes
+	RenderNodeHook html.RenderNodeFunc
+}
+
+// Renderer implements Renderer interface for AsciiDoc output.
+type Renderer struct {
+	opts RendererOptions
+
+	Title  *mast.Title
+	matter ast.DocumentMatters
+
+	tail     string // last bytes written
+	inTable  bool   // we are in a table
+	inlining bool   // rendering inline content to a string, see inline
+}
+
+// NewRenderer creates and configures an Renderer object, which satisfies the Renderer interface.
+func NewRenderer(opts RendererOptions) *Renderer {
+	return &Renderer{opts: opts}
+}
+
+// blockStart separates a block from the previous one. In a list item the first block follows the
+// list marker and other blocks are attached with a list continuation.
+func (r *Renderer) blockStart(w io.Writer, node ast.Node) {
+	if r.inlining {
+		return
+	}
+	switch node.GetParent().(type) {
+	case *ast.ListItem:
+		if ast.GetPrevNode(node) == nil {
+			return
+		}
+		r.cr(w)
+		if _, ok := node.(*ast.List); !ok {
+			r.outs(w, "+\n")
+		}
+		return
+	case *ast.CaptionFigure:
+		// the block title or attribute list is already output.
+		if ast.GetPrevNode(node) == nil {
+			return
+		}
+	case *ast.BlockQuote, *ast.Aside:
+		if ast.GetPrevNode(node) == nil {
+			r.cr(w)
+			return
+		}
+	}
+	r.blank(w)
+	// two adjacent lists are joined, unless separated by a line comment.
+	if _, ok := node.(*ast.List); ok {
+		if _, ok := ast.GetPrevNode(node).(*ast.List); ok {
+			r.outs(w, "//-\n\n")
+		}
+	}
+}
+
+// inline returns the AsciiDoc for the children of node as a single line.
+func (r *Renderer) inline(node ast.Node) string {
+	buf := &bytes.Buffer{}
+	tail, inlining := r.tail, r.inlining
+	r.tail, r.inlining = " ", true
+	for _, c := range node.GetChildren() {
+		ast.WalkFunc(c, func(n ast.Node, entering bool) ast.WalkStatus {
+			return r.RenderNode(buf, n, entering)
+		})
+	}
+	r.tail, r.inlining = tail, inlining
+	return strings.TrimSpace(strings.ReplaceAll(buf.String(), "\n", " "))
+}
+
+func (r *Renderer) heading(w io.Writer, node *ast.Heading, entering bool) {
+	if !entering {
+		r.outs(w, "\n")
+		return
+	}
+	r.blockStart(w, node)
+	level := node.Level
+	switch {
+	case node.IsSpecial && strings.EqualFold(string(node.Literal), "abstract"):
+		r.outs(w, "[abstract]\n")
+		level = 1
+	case node.IsSpecial:
+		r.outs(w, "[preface]\n")
+		level = 1
+	case r.matter == ast.DocumentMatterBack && level == 1:
+		r.outs(w, "[appendix]\n")
+	}
+	if !node.IsSpecial {
+		r.anchor(w, node)
+	}
+	r.outs(w, strings.Repeat("=", level+1)+" ")
+}
+
+func (r *Renderer) paragraph(w io.Writer, para *ast.Paragraph, entering bool) ast.WalkStatus {
+	if r.inlining {
+		if !entering {
+			r.outs(w, " ")
+		}
+		return ast.GoToNext
+	}
+	if item, ok := para.Parent.(*ast.ListItem); ok {
+		if !entering && item.ListFlags&ast.ListTypeTerm == 0 {
+			r.cr(w)
+		}
+		return ast.GoToNext
+	}
+	if !entering {
+		r.cr(w)
+		return ast.GoToNext
+	}
+	r.blockStart(w, para)
+	if img, ok := blockImage(para); ok {
+		r.outs(w, "image::"+string(img.Destination)+"["+bracket(r.inline(img))+"]\n")
+		return ast.SkipChildren
+	}
+	return ast.GoToNext
+}
+
+// blockImage returns the image if para only holds an image and is part of a figure.
+func blockImage(para *ast.Paragraph) (*ast.Image, bool) {
+	if len(para.Children) != 1 {
+		return nil, false
+	}
+	if _, ok := para.Parent.(*ast.CaptionFigure); !ok {
+		return nil, false
+	}
+	img, ok := para.Children[0].(*ast.Image)
+	return img, ok
+}
+
+// bracket escapes the closing bracket for use in an attribute list or macro.
+func bracket(s string) string { return strings.ReplaceAll(s, "]", `\]`) }
+
+func (r *Renderer) list(w io.Writer, list *ast.List, entering bool) ast.WalkStatus {
+	if list.IsFootnotesList {
+		// rendered in place with footnote:[]
+		return ast.SkipChildren
+	}
+	if entering {
+		r.blockStart(w, list)
+		r.anchor(w, list)
+		if list.ListFlags&ast.ListTypeOrdered != 0 && list.Start > 1 {
+			r.outs(w, fmt.Sprintf("[start=%d]\n", list.Start))
+		}
+		return ast.GoToNext
+	}
+	r.cr(w)
+	return ast.GoToNext
+}
+
+func (r *Renderer) listItem(w io.Writer, item *ast.ListItem, entering bool) {
+	list, _ := item.Parent.(*ast.List)
+	d := 1
+	if list != nil {
+		d = depth(list)
+	}
+	switch {
+	case item.ListFlags&ast.ListTypeTerm != 0:
+		if entering {
+			r.cr(w)
+			return
+		}
+		r.outs(w, strings.Repeat(":", d+1)+"\n")
+	case item.ListFlags&ast.ListTypeDefinition != 0:
+		if !entering {
+			r.cr(w)
+		}
+	case item.ListFlags&ast.ListTypeOrdered != 0:
+		if entering {
+			r.cr(w)
+			r.outs(w, strings.Repeat(".", d)+" ")
+		}
+	default:
+		if entering {
+			r.cr(w)
+			r.outs(w, strings.Repeat("*", d)+" ")
+		}
+	}
+}
+
+func (r *Renderer) codeBlock(w io.Writer, codeBlock *ast.CodeBlock) {
+	r.blockStart(w, codeBlock)
+	if _, ok := codeBlock.Parent.(*ast.CaptionFigure); !ok {
+		r.anchor(w, codeBlock)
+	}
+	if info := strings.Fields(string(codeBlock.Info)); len(info) > 0 {
+		r.outs(w, "[source,"+info[0]+"]\n")
+	}
+	delim := delimiter("-", codeBlock.Literal)
+	r.outs(w, delim+"\n")
+	r.out(w, codeBlock.Literal)
+	r.cr(w)
+	r.outs(w, delim+"\n")
+}
+
+func (r *Renderer) htmlBlock(w io.Writer, block *ast.HTMLBlock) {
+	r.blockStart(w, block)
+	literal := bytes.TrimSpace(block.Literal)
+	if bytes.HasPrefix(literal, []byte("<!--")) && bytes.HasSuffix(literal, []byte("-->")) {
+		comment := bytes.TrimSpace(literal[4 : len(literal)-3])
+		delim := delimiter("/", comment)
+		r.outs(w, delim+"\n")
+		r.out(w, comment)
+		r.cr(w)
+		r.outs(w, delim+"\n")
+		return
+	}
+	delim := delimiter("+", literal)
+	r.outs(w, delim+"\n")
+	r.out(w, literal)
+	r.cr(w)
+	r.outs(w, delim+"\n")
+}
+
+func (r *Renderer) table(w io.Writer, tab *ast.Table, entering bool) {
+	if !entering {
+		r.cr(w)
+		r.outs(w, "|===\n")
+		r.inTable = false
+		return
+	}
+	r.blockStart(w, tab)
+	if _, ok := tab.Parent.(*ast.CaptionFigure); !ok {
+		r.anchor(w, tab)
+	}
+	options := []string{}
+	for _, c := range tab.GetChildren() {
+		switch c.(type) {
+		case *ast.TableHeader:
+			options = append(options, "header")
+		case *ast.TableFooter:
+			options = append(options, "footer")
+		}
+	}
+	attrs := `cols="` + cols(tab) + `"`
+	if len(options) > 0 {
+		attrs += `,options="` + strings.Join(options, ",") + `"`
+	}
+	r.outs(w, "["+attrs+"]\n|===\n")
+	r.inTable = true
+}
+
+func (r *Renderer) tableCell(w io.Writer, cell *ast.TableCell, entering bool) {
+	if !entering {
+		return
+	}
+	if ast.GetPrevNode(cell) != nil {
+		r.outs(w, " ")
+	}
+	if cell.ColSpan > 1 {
+		r.outs(w, fmt.Sprintf("%d+", cell.ColSpan))
+	}
+	if mast.BlockCell(cell) {
+		r.outs(w, "a") // the cell holds AsciiDoc blocks
+	}
+	r.outs(w, "|")
+}
+
+func (r *Renderer) captionFigure(w io.Writer, figure *ast.CaptionFigure, entering bool) {
+	if !entering {
+		return
+	}
+	r.blockStart(w, figure)
+	caption := ""
+	for _, c := range figure.Children {
+		if capt, ok := c.(*ast.Caption); ok {
+			caption = r.inline(capt)
+		}
+	}
+	r.anchor(w, figure)
+	if _, ok := ast.GetFirstChild(figure).(*ast.BlockQuote); ok {
+		if caption == "" {
+			return
+		}
+		r.outs(w, `[quote,"`+strings.ReplaceAll(caption, `"`, `\"`)+`"]`+"\n")
+		return
+	}
+	if caption != "" {
+		r.outs(w, "."+caption+"\n")
+	}
+}
+
+func (r *Renderer) delimited(w io.Writer, node ast.Node, delim string, entering bool) {
+	if !entering {
+		r.cr(w)
+		r.outs(w, delim+"\n")
+		return
+	}
+	r.blockStart(w, node)
+	if _, ok := node.GetParent().(*ast.CaptionFigure); !ok {
+		r.anchor(w, node)
+	}
+	r.outs(w, delim+"\n")
+}
+
+func (r *Renderer) link(w io.Writer, link *ast.Link, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.GoToNext
+	}
+	if link.Footnote != nil {
+		r.outs(w, "footnote:["+bracket(r.inline(link.Footnote))+"]")
+		return ast.SkipChildren
+	}
+	dest := string(link.Destination)
+	text := r.inline(link)
+	if !strings.Contains(dest, "://") && !strings.HasPrefix(dest, "mailto:") {
+		dest = "link:" + dest
+	} else if text == "" || text == Escape(dest) {
+		r.outs(w, dest)
+		return ast.SkipChildren
+	}
+	r.outs(w, dest+"["+bracket(text)+"]")
+	return ast.SkipChildren
+}
+
+func (r *Renderer) citation(w io.Writer, node *ast.Citation, entering bool) {
+	if !entering {
+		return
+	}
+	for i, c := range node.Destination {
+		// author or contact citation, output the name.
+		if r.authorOrContact(c) {
+			r.text(w, string(c))
+			continue
+		}
+		if node.Type[i] == ast.CitationTypeSuppressed {
+			continue
+		}
+		if len(node.Suffix) > i && len(node.Suffix[i]) > 0 {
+			r.outs(w, "<<"+string(c)+",["+string(c)+"], "+Escape(string(node.Suffix[i]))+">>")
+			continue
+		}
+		r.outs(w, "<<"+string(c)+">>")
+	}
+}
+
+func (r *Renderer) authorOrContact(name []byte) bool {
+	if r.Title == nil {
+		return false
+	}
+	for _, a := range r.Title.Author {
+		if strings.EqualFold(a.Fullname, string(name)) {
+			return true
+		}
+	}
+	for _, c := range r.Title.Contact {
+		if strings.EqualFold(c.Fullname, string(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Renderer) bibliography(w io.Writer, node ast.Node, entering bool) {
+	if !entering || len(node.GetChildren()) == 0 {
+		return
+	}
+	r.blockStart(w, node)
+	b, ok := node.(*mast.Bibliography)
+	if !ok {
+		r.outs(w, "== "+r.opts.Language.Bibliography()+"\n")
+		return
+	}
+	level := "=="
+	if _, ok := b.Parent.(*mast.BibliographyWrapper); ok {
+		level = "==="
+	}
+	title := "Informative References"
+	if b.Type == ast.CitationTypeNormative {
+		title = "Normative References"
+	}
+	r.outs(w, "[bibliography]\n"+level+" "+title+"\n\n")
+}
+
+// bibliographyItem outputs a bibliography entry, the anchor is defined with [[[anchor]]].
+func (r *Renderer) bibliographyItem(w io.Writer, node *mast.BibliographyItem) {
+	r.cr(w)
+	r.outs(w, "* [[["+string(node.Anchor)+"]]]")
+	text := ""
+	if node.Reference != nil {
+		text = referenceText(node.Reference)
+	} else if strings.HasPrefix(string(node.Anchor), "RFC") {
+		num := strings.TrimLeft(string(node.Anchor[3:]), "0")
+		text = "RFC " + num + ", https://www.rfc-editor.org/info/rfc" + num
+	}
+	if text != "" {
+		r.outs(w, " ")
+		r.text(w, text)
+	}
+	r.outs(w, "\n")
+}
+
+// referenceText returns the text for a reference: authors, title, series, date and target.
+func referenceText(ref *reference.Reference) string {
+	parts := []string{}
+	names := []string{}
+	for _, a := range ref.Front.Authors {
+		switch {
+		case a.Fullname != "":
+			names = append(names, a.Fullname)
+		case a.Surname != "":
+			names = append(names, strings.TrimSpace(a.Initials+" "+a.Surname))
+		case a.Organization != nil && a.Organization.Value != "":
+			names = append(names, a.Organization.Value)
+		}
+	}
+	if len(names) > 0 {
+		parts = append(parts, strings.Join(names, ", "))
+	}
+	parts = append(parts, `"`+ref.Front.Title+`"`)
+	for _, s := range ref.Series {
+		parts = append(parts, strings.TrimSpace(s.Name+" "+s.Value))
+	}
+	if d := ref.Front.Date; d != nil && d.Year != "" {
+		parts = append(parts, strings.TrimSpace(d.Month+" "+d.Year))
+	}
+	if ref.Target != "" {
+		parts = append(parts, ref.Target)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// RenderNode renders a markdown node to AsciiDoc.
+func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.WalkStatus {
+	if r.opts.RenderNodeHook != nil {
+		status, didHandle := r.opts.RenderNodeHook(w, node, entering)
+		if didHandle {
+			return status
+		}
+	}
+
+	switch node := node.(type) {
+	case *ast.Document:
+		// do nothing
+	case *mast.Title:
+		r.Title = node
+		if entering && r.opts.Flags&AsciiDocFragment == 0 {
+			r.titleBlock(w, node)
+		}
+	case *mast.Authors:
+		// ignore
+	case *mast.BibliographyWrapper, *mast.Bibliography:
+		r.bibliography(w, node, entering)
+	case *mast.BibliographyItem:
+		if entering {
+			r.bibliographyItem(w, node)
+		}
+	case *mast.DocumentIndex:
+		if entering {
+			r.blockStart(w, node)
+			r.outs(w, "[index]\n== "+r.opts.Language.Index()+"\n")
+		}
+		// generated by the AsciiDoc processor
+		return ast.SkipChildren
+	case *mast.IndexLetter, *mast.IndexItem, *mast.IndexSubItem, *mast.IndexLink:
+	case *mast.ReferenceBlock:
+		// skip, added and done by AddBibliography
+	case *ast.Footnotes:
+		// rendered in place with footnote:[]
+		return ast.SkipChildren
+	case *ast.DocumentMatter:
+		if entering {
+			r.matter = node.Matter
+		}
+	case *ast.Text:
+		r.text(w, string(node.Literal))
+	case *ast.Softbreak:
+		r.outs(w, "\n")
+	case *ast.Hardbreak:
+		r.outs(w, " +\n")
+	case *ast.NonBlockingSpace:
+		r.outs(w, "{nbsp}")
+	case *ast.Callout:
+		r.text(w, "<"+string(node.ID)+">")
+	case *mast.Unicode:
+		r.text(w, node.String())
+	case *mast.Abbreviation:
+		r.text(w, node.String())
+	case *mast.Cref:
+		r.text(w, node.String())
+	case *ast.Emph:
+		r.outs(w, "__")
+	case *ast.Strong:
+		r.outs(w, "**")
+	case *ast.Del:
+		r.outOneOf(w, entering, "[.line-through]#", "#")
+	case *ast.Citation:
+		r.citation(w, node, entering)
+	case *ast.Heading:
+		r.heading(w, node, entering)
+		if entering && node.IsSpecial {
+			r.text(w, string(node.Literal))
+			return ast.SkipChildren
+		}
+	case *ast.HorizontalRule:
+		r.blockStart(w, node)
+		r.outs(w, "'''\n")
+	case *ast.Paragraph:
+		return r.paragraph(w, node, entering)
+	case *ast.HTMLSpan:
+		switch {
+		case bytes.HasPrefix(node.Literal, []byte("<!--")):
+		case bytes.HasPrefix(bytes.ToLower(node.Literal), []byte("<br")):
+			r.outs(w, " +\n")
+		default:
+			r.outs(w, "pass:["+bracket(string(node.Literal))+"]")
+		}
+	case *ast.HTMLBlock:
+		r.htmlBlock(w, node)
+	case *ast.List:
+		return r.list(w, node, entering)
+	case *ast.ListItem:
+		r.listItem(w, node, entering)
+	case *ast.CodeBlock:
+		r.codeBlock(w, node)
+	case *ast.Caption:
+		// output as the block title, see captionFigure.
+		return ast.SkipChildren
+	case *ast.CaptionFigure:
+		r.captionFigure(w, node, entering)
+	case *ast.Table:
+		r.table(w, node, entering)
+	case *ast.TableCell:
+		r.tableCell(w, node, entering)
+	case *ast.TableHeader, *ast.TableBody, *ast.TableFooter:
+	case *ast.TableRow:
+		r.outOneOf(w, entering, "", "\n")
+	case *ast.BlockQuote:
+		r.delimited(w, node, "____", entering)
+	case *ast.Aside:
+		r.delimited(w, node, "****", entering)
+	case *mast.Div:
+		// rendered as its contents, AsciiDoc roles need a named block.
+	case *ast.CrossReference:
+		if !entering {
+			break
+		}
+		if text := r.inline(node); text != "" {
+			r.outs(w, "<<"+string(node.Destination)+","+text+">>")
+			return ast.SkipChildren
+		}
+		r.outs(w, "<<"+string(node.Destination)+">>")
+		return ast.SkipChildren
+	case *ast.Index:
+		if entering && !mast.IndexRedirect(node) && !mast.IndexRangeEnd(node) {
+			term := string(node.Item)
+			if len(node.Subitem) > 0 {
+				term += ", " + string(node.Subitem)
+			}
+			r.outs(w, "((("+term+")))")
+		}
+	case *ast.Link:
+		return r.link(w, node, entering)
+	case *ast.Math:
+		r.outs(w, "stem:["+bracket(string(node.Literal))+"]")
+	case *ast.Image:
+		if entering {
+			r.outs(w, "image:"+string(node.Destination)+"["+bracket(r.inline(node))+"]")
+		}
+		return ast.SkipChildren
+	case *ast.Code:
+		r.outs(w, "``+"+string(node.Literal)+"+``")
+	case *ast.MathBlock:
+		if entering {
+			r.blockStart(w, node)
+			r.outs(w, "[stem]\n++++\n")
+			r.out(w, bytes.TrimSpace(node.Literal))
+			r.outs(w, "\n++++\n")
+		}
+	case *ast.Subscript:
+		r.outs(w, "~"+Escape(string(node.Literal))+"~")
+	case *ast.Superscript:
+		r.outs(w, "^"+Escape(string(node.Literal))+"^")
+	default:
+		panic(fmt.Sprintf("Unknown node %T", node))
+	}
+	return ast.GoToNext
+}
+
+// RenderHeader does nothing, the document header is output when the title block is seen.
+func (r *Renderer) RenderHeader(_ io.Writer, _ ast.Node) {}
+
+// RenderFooter makes sure the output ends in a newline.
+func (r *Renderer) RenderFooter(w io.Writer, _ ast.Node) { r.cr(w) }
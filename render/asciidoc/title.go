@@ -0,0 +1,62 @@
+package asciidoc
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// titleBlock outputs the document header: the title, the author line and attribute entries for
+// the other elements of the title block.
+func (r *Renderer) titleBlock(w io.Writer, t *mast.Title) {
+	d := t.TitleData
+	if d == nil {
+		return
+	}
+
+	r.outs(w, "= "+d.Title+"\n")
+	authors := []string{}
+	for _, a := range d.Author {
+		if a.Fullname == "" {
+			continue
+		}
+		author := a.Fullname
+		if a.Address.Email != "" {
+			author += " <" + a.Address.Email + ">"
+		}
+		authors = append(authors, author)
+	}
+	if len(authors) > 0 {
+		r.outs(w, strings.Join(authors, "; ")+"\n")
+	}
+	// the author line sets author, email, author_2, email_2, etc. We do the same for the organization.
+	for i, a := range d.Author {
+		suffix := ""
+		if i > 0 {
+			suffix = fmt.Sprintf("_%d", i+1)
+		}
+		r.attribute(w, "organization"+suffix, a.Organization)
+	}
+
+	if !d.Date.IsZero() {
+		r.attribute(w, "revdate", d.Date.Format("2006-01-02"))
+	}
+	r.attribute(w, "lang", d.Language)
+	r.attribute(w, "abbrev", d.Abbrev)
+	r.attribute(w, "keywords", strings.Join(d.Keyword, ", "))
+	r.attribute(w, "docname", d.SeriesInfo.Value)
+	r.attribute(w, "status", d.SeriesInfo.Status)
+	r.attribute(w, "stream", d.SeriesInfo.Stream)
+	r.attribute(w, "ipr", d.Ipr)
+	r.attribute(w, "area", d.Area)
+	r.attribute(w, "workgroup", d.Workgroup)
+	r.attribute(w, "submission-type", d.SubmissionType)
+	if d.Consensus {
+		r.attribute(w, "consensus", "true")
+	}
+	r.attribute(w, "updates", d.Updates.String())
+	r.attribute(w, "obsoletes", d.Obsoletes.String())
+	r.outs(w, "\n")
+}
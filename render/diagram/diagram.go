@@ -0,0 +1,231 @@
+// Package diagram converts diagrams in fenced code blocks, i.e. ```mermaid, to SVG. The conversion
+// is done by an Engine, usually an external command. The renderers use the SVG where they can, for
+// text output the diagram's text is used, if the engine can make text.
+package diagram
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// Engine converts the source of a diagram to SVG.
+type Engine interface {
+	SVG(src []byte) ([]byte, error)
+}
+
+// Texter is implemented by engines that can also convert a diagram to (ASCII) text.
+type Texter interface {
+	Text(src []byte) ([]byte, error)
+}
+
+// Command is an Engine that runs an external command. The source is written to the standard input of
+// the command and the SVG read from its standard output, unless the command uses {in} and {out}:
+// these are replaced with the names of temporary files for the source and the SVG.
+type Command string
+
+// SVG implements Engine.
+func (c Command) SVG(src []byte) ([]byte, error) { return run(string(c), src, ".svg") }
+
+// run runs the command c with src as its input, see Command. Ext is the extension of the {out} file.
+func run(c string, src []byte, ext string) ([]byte, error) {
+	args := strings.Fields(c)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("no command")
+	}
+	dir, err := os.MkdirTemp("", "mmark-diagram")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	in, out := filepath.Join(dir, "diagram.src"), filepath.Join(dir, "diagram"+ext)
+	files := false
+	for i := range args {
+		if strings.Contains(args[i], "{in}") || strings.Contains(args[i], "{out}") {
+			files = true
+		}
+		args[i] = strings.NewReplacer("{in}", in, "{out}", out).Replace(args[i])
+	}
+	if files {
+		if err := os.WriteFile(in, src, 0600); err != nil {
+			return nil, err
+		}
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.Stdin = bytes.NewReader(src)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %s", err, msg)
+		}
+		return nil, err
+	}
+	if !strings.Contains(c, "{out}") {
+		return stdout.Bytes(), nil
+	}
+	return os.ReadFile(out)
+}
+
+// Commands is an Engine that converts a diagram to SVG with the command SVGCommand and to text with
+// the command TextCommand, both are run as a Command. Without a TextCommand the text is the source.
+type Commands struct {
+	SVGCommand  Command
+	TextCommand Command
+}
+
+// SVG implements Engine.
+func (c Commands) SVG(src []byte) ([]byte, error) { return c.SVGCommand.SVG(src) }
+
+// Text implements Texter.
+func (c Commands) Text(src []byte) ([]byte, error) {
+	if c.TextCommand == "" {
+		return src, nil
+	}
+	return run(string(c.TextCommand), src, ".txt")
+}
+
+// Engines are the default engines, keyed by the language of the fenced code block.
+var Engines = map[string]Engine{
+	"mermaid":   Command("mmdc --quiet --input {in} --output {out}"),
+	"dot":       Commands{SVGCommand: "dot -Tsvg", TextCommand: "graph-easy --from=dot --as=ascii"},
+	"plantuml":  Commands{SVGCommand: "plantuml -tsvg -pipe", TextCommand: "plantuml -ttxt -pipe"},
+	"goat":      Goat{},
+	"ascii-art": Goat{},
+}
+
+// Diagram is a converted diagram.
+type Diagram struct {
+	SVG  []byte // The SVG without the XML declaration.
+	Text []byte // The text of the diagram, nil if the engine can't make text.
+}
+
+// Diagrams converts the diagrams of a document, it is safe to use a nil *Diagrams, which doesn't
+// convert anything.
+type Diagrams struct {
+	Engines map[string]Engine // Keyed by the language of the fenced code block.
+	Report  mast.Reporter     // If set, called with the failures, otherwise these are logged.
+
+	cache  map[*ast.CodeBlock]*Diagram
+	failed map[string]bool // engines that failed, to warn only once
+}
+
+// New returns a Diagrams that uses engines.
+func New(engines map[string]Engine) *Diagrams {
+	return &Diagrams{Engines: engines, cache: map[*ast.CodeBlock]*Diagram{}, failed: map[string]bool{}}
+}
+
+// Diagram returns the converted diagram of codeBlock, or nil if it isn't a diagram or the conversion
+// fails, in which case the code block should be rendered as usual. A failure is reported.
+func (d *Diagrams) Diagram(codeBlock *ast.CodeBlock) *Diagram {
+	if d == nil {
+		return nil
+	}
+	lang := Language(codeBlock.Info)
+	e, ok := d.Engines[lang]
+	if !ok {
+		return nil
+	}
+	if diag, ok := d.cache[codeBlock]; ok {
+		return diag
+	}
+
+	d.cache[codeBlock] = nil
+	svg, err := svg(e, codeBlock.Literal)
+	if err != nil {
+		d.warn(lang, "failure to convert %s diagram, showing its source: %s", lang, err)
+		return nil
+	}
+	diag := &Diagram{SVG: svg}
+	if t, ok := e.(Texter); ok {
+		text, err := t.Text(codeBlock.Literal)
+		if err != nil {
+			d.warn(lang+".text", "failure to convert %s diagram to text, showing its source: %s", lang, err)
+		} else {
+			diag.Text = text
+		}
+	}
+	d.cache[codeBlock] = diag
+	return diag
+}
+
+// Text returns the text of the diagram in codeBlock, or nil if it isn't a diagram, its engine can't
+// make text, or the conversion fails, in which case the code block should be rendered as usual. This is
+// for outputs that can't show SVG, it doesn't convert to SVG.
+func (d *Diagrams) Text(codeBlock *ast.CodeBlock) []byte {
+	if d == nil {
+		return nil
+	}
+	lang := Language(codeBlock.Info)
+	t, ok := d.Engines[lang].(Texter)
+	if !ok {
+		return nil
+	}
+	if diag, ok := d.cache[codeBlock]; ok && diag != nil {
+		return diag.Text
+	}
+	text, err := t.Text(codeBlock.Literal)
+	if err != nil {
+		d.warn(lang+".text", "failure to convert %s diagram to text, showing its source: %s", lang, err)
+		return nil
+	}
+	return text
+}
+
+// warn reports a warning once for key.
+func (d *Diagrams) warn(key, format string, v ...any) {
+	if d.failed[key] {
+		return
+	}
+	d.failed[key] = true
+	d.Report.Reportf(mast.SeverityWarning, "diagram", format, v...)
+}
+
+func svg(e Engine, src []byte) ([]byte, error) {
+	svg, err := e.SVG(src)
+	if err != nil {
+		return nil, err
+	}
+	svg = Strip(svg)
+	if !bytes.HasPrefix(svg, []byte("<svg")) {
+		return nil, fmt.Errorf("no SVG in the output")
+	}
+	return svg, nil
+}
+
+// Strip removes the XML declaration, the doctype and comments before the <svg> element, so the SVG
+// can be embedded in another document.
+func Strip(svg []byte) []byte {
+	svg = bytes.TrimSpace(svg)
+	for bytes.HasPrefix(svg, []byte("<?")) || bytes.HasPrefix(svg, []byte("<!")) {
+		end := []byte(">")
+		if bytes.HasPrefix(svg, []byte("<!--")) {
+			end = []byte("-->")
+		}
+		i := bytes.Index(svg, end)
+		if i < 0 {
+			break
+		}
+		svg = bytes.TrimSpace(svg[i+len(end):])
+	}
+	return svg
+}
+
+// Language returns the language of a fenced code block from its info string, i.e. "mermaid" for
+// "mermaid {.class}".
+func Language(info []byte) string {
+	f := strings.Fields(string(info))
+	if len(f) == 0 {
+		return ""
+	}
+	return strings.ToLower(f[0])
+}
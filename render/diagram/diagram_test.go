@@ -0,0 +1,117 @@
+package diagram
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+type engine struct{}
+
+func (engine) SVG(src []byte) ([]byte, error) {
+	return append([]byte(`<?xml version="1.0"?>`+"\n<!-- generated -->\n<svg>"), append(src, "</svg>"...)...), nil
+}
+
+func (engine) Text(src []byte) ([]byte, error) { return []byte("+---+\n| A |\n+---+\n"), nil }
+
+func TestDiagram(t *testing.T) {
+	d := New(map[string]Engine{"test": engine{}})
+	if x := d.Diagram(&ast.CodeBlock{Info: []byte("go"), Leaf: ast.Leaf{Literal: []byte("a")}}); x != nil {
+		t.Errorf("expected no diagram for go, got %v", x)
+	}
+	x := d.Diagram(&ast.CodeBlock{Info: []byte("test {.class}"), Leaf: ast.Leaf{Literal: []byte("A")}})
+	if x == nil {
+		t.Fatal("expected a diagram")
+	}
+	if string(x.SVG) != "<svg>A</svg>" {
+		t.Errorf("expected stripped SVG, got %q", x.SVG)
+	}
+	if string(x.Text) != "+---+\n| A |\n+---+\n" {
+		t.Errorf("expected text of the engine, got %q", x.Text)
+	}
+
+	var nilDiagrams *Diagrams
+	if x := nilDiagrams.Diagram(&ast.CodeBlock{Info: []byte("test")}); x != nil {
+		t.Errorf("expected no diagram from nil Diagrams, got %v", x)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	if _, err := exec.LookPath("cp"); err != nil {
+		t.Skip("no cp")
+	}
+	for _, c := range []Command{"cat", "cp {in} {out}"} {
+		svg, err := c.SVG([]byte("<svg/>"))
+		if err != nil {
+			t.Fatalf("%s: %s", c, err)
+		}
+		if string(svg) != "<svg/>" {
+			t.Errorf("%s: expected <svg/>, got %q", c, svg)
+		}
+	}
+	if _, err := Command("false").SVG(nil); err == nil {
+		t.Errorf("expected error from a failing command")
+	}
+}
+
+func TestText(t *testing.T) {
+	if _, err := exec.LookPath("tr"); err != nil {
+		t.Skip("no tr")
+	}
+	d := New(map[string]Engine{"dot": Commands{SVGCommand: "false", TextCommand: "tr a-z A-Z"}, "mermaid": Command("cat")})
+	if text := d.Text(&ast.CodeBlock{Info: []byte("dot"), Leaf: ast.Leaf{Literal: []byte("a -> b")}}); string(text) != "A -> B" {
+		t.Errorf("expected text of the command, got %q", text)
+	}
+	if text := d.Text(&ast.CodeBlock{Info: []byte("mermaid"), Leaf: ast.Leaf{Literal: []byte("a")}}); text != nil {
+		t.Errorf("expected no text from an engine without text, got %q", text)
+	}
+}
+
+func TestPlantUMLServer(t *testing.T) {
+	const uml = "@startuml\nBob -> Alice : hello\n@enduml\n"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		format, encoded, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/plantuml/"), "/")
+		src, err := plantUMLDecode(encoded)
+		if err != nil || string(src) != uml {
+			http.Error(w, "bad diagram", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, "%s of %d bytes", format, len(src))
+	}))
+	defer srv.Close()
+
+	p := PlantUMLServer(srv.URL + "/plantuml/")
+	svg, err := p.SVG([]byte(uml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(svg) != fmt.Sprintf("svg of %d bytes", len(uml)) {
+		t.Errorf("unexpected SVG: %q", svg)
+	}
+	if text, err := p.Text([]byte(uml)); err != nil || string(text) != fmt.Sprintf("txt of %d bytes", len(uml)) {
+		t.Errorf("unexpected text: %q, %v", text, err)
+	}
+	if _, err := p.SVG([]byte("other")); err == nil {
+		t.Errorf("expected error for a bad request")
+	}
+}
+
+func plantUMLDecode(s string) ([]byte, error) {
+	data := []byte{}
+	for i := 0; i+3 < len(s); i += 4 {
+		var c [4]byte
+		for j := range c {
+			c[j] = byte(strings.IndexByte(plantUMLAlphabet, s[i+j]))
+		}
+		data = append(data, c[0]<<2|c[1]>>4, c[1]<<4|c[2]>>2, c[2]<<6|c[3])
+	}
+	return io.ReadAll(flate.NewReader(bytes.NewReader(data)))
+}
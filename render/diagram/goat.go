@@ -0,0 +1,248 @@
+package diagram
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Goat is an Engine that converts ASCII art to SVG, in the style of goat (Go ASCII Tool). Lines are
+// drawn for runs of -, |, / and \, + is a corner or a junction, <, >, ^ and v at the end of a line are
+// arrow heads and * on a line is a dot. Everything else is text. As its text, the ASCII art is used.
+type Goat struct{}
+
+// The size of a character cell in the SVG.
+const (
+	cellWidth  = 8
+	cellHeight = 16
+)
+
+// SVG implements Engine.
+func (Goat) SVG(src []byte) ([]byte, error) {
+	g := newGrid(src)
+	if g.width == 0 {
+		return nil, fmt.Errorf("empty drawing")
+	}
+	b := &bytes.Buffer{}
+	w, h := g.width*cellWidth, len(g.rows)*cellHeight
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" version="1.2" baseProfile="tiny" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", w, h, w, h)
+	b.WriteString(`<g stroke="black" stroke-width="1" stroke-linecap="round" fill="none">` + "\n")
+	for y := range g.rows {
+		for x := range g.rows[y] {
+			g.line(b, x, y)
+		}
+	}
+	b.WriteString("</g>\n")
+	b.WriteString(`<g fill="black" stroke="none">` + "\n")
+	for y := range g.rows {
+		for x := range g.rows[y] {
+			g.mark(b, x, y)
+		}
+	}
+	b.WriteString("</g>\n")
+	b.WriteString(`<g fill="black" font-family="monospace" font-size="13" text-anchor="middle">` + "\n")
+	for y := range g.rows {
+		for x, c := range g.rows[y] {
+			if c == ' ' || g.drawn(x, y) {
+				continue
+			}
+			cx, cy := center(x, y)
+			fmt.Fprintf(b, `<text x="%d" y="%d">%s</text>`+"\n", cx, cy+4, html.EscapeString(string(c)))
+		}
+	}
+	b.WriteString("</g>\n</svg>\n")
+	return b.Bytes(), nil
+}
+
+// Text implements Texter.
+func (Goat) Text(src []byte) ([]byte, error) { return src, nil }
+
+type grid struct {
+	rows  [][]rune
+	width int
+}
+
+func newGrid(src []byte) *grid {
+	g := &grid{}
+	for _, l := range strings.Split(strings.TrimRight(string(src), "\n"), "\n") {
+		r := []rune(strings.ReplaceAll(l, "\t", "    "))
+		if len(r) > g.width {
+			g.width = len(r)
+		}
+		g.rows = append(g.rows, r)
+	}
+	return g
+}
+
+// at returns the character at x, y, or a space if that is outside the drawing.
+func (g *grid) at(x, y int) rune {
+	if y < 0 || y >= len(g.rows) || x < 0 || x >= len(g.rows[y]) {
+		return ' '
+	}
+	return g.rows[y][x]
+}
+
+func center(x, y int) (int, int) { return x*cellWidth + cellWidth/2, y*cellHeight + cellHeight/2 }
+
+// horizontal, vertical, slash and backslash return true if the character at x, y is a line in that direction.
+func (g *grid) horizontal(x, y int) bool {
+	switch g.at(x, y) {
+	case '-':
+		return strings.ContainsRune("-+<>*", g.at(x-1, y)) || strings.ContainsRune("-+<>*", g.at(x+1, y))
+	case '+':
+		return g.junction(x, y)
+	}
+	return false
+}
+
+func (g *grid) vertical(x, y int) bool {
+	switch g.at(x, y) {
+	case '|':
+		return strings.ContainsRune("|+^v*", g.at(x, y-1)) || strings.ContainsRune("|+^v*", g.at(x, y+1)) ||
+			g.horizontal(x-1, y) || g.horizontal(x+1, y)
+	case '+':
+		return g.junction(x, y)
+	}
+	return false
+}
+
+func (g *grid) slash(x, y int) bool {
+	return g.at(x, y) == '/' && (g.at(x+1, y-1) == '/' || g.at(x-1, y+1) == '/')
+}
+
+func (g *grid) backslash(x, y int) bool {
+	return g.at(x, y) == '\\' && (g.at(x-1, y-1) == '\\' || g.at(x+1, y+1) == '\\')
+}
+
+// junction returns true if the + at x, y connects to a line.
+func (g *grid) junction(x, y int) bool {
+	return g.at(x-1, y) == '-' || g.at(x+1, y) == '-' || g.at(x, y-1) == '|' || g.at(x, y+1) == '|' ||
+		g.at(x-1, y) == '+' || g.at(x+1, y) == '+' || g.at(x, y-1) == '+' || g.at(x, y+1) == '+' ||
+		g.slash(x+1, y-1) || g.slash(x-1, y+1) || g.backslash(x-1, y-1) || g.backslash(x+1, y+1)
+}
+
+// connects returns true if the character at x, y is a line, corner or arrow head that ends at the
+// neighbouring cell in direction dx, dy.
+func (g *grid) connects(x, y, dx, dy int) bool {
+	c := g.at(x, y)
+	switch {
+	case dy == 0:
+		return (c == '-' || c == '<' || c == '>' || c == '*') && g.horizontal(x, y) || c == '+' && g.junction(x, y) ||
+			c == '<' && dx == -1 || c == '>' && dx == 1
+	case dx == 0:
+		return c == '|' && g.vertical(x, y) || c == '+' && g.junction(x, y) || c == '^' && dy == -1 || c == 'v' && dy == 1
+	case dx == dy:
+		return g.backslash(x, y) || c == '+' && g.junction(x, y)
+	}
+	return g.slash(x, y) || c == '+' && g.junction(x, y)
+}
+
+// drawn returns true if the character at x, y is drawn as a line or mark, and not as text.
+func (g *grid) drawn(x, y int) bool {
+	switch g.at(x, y) {
+	case '-':
+		return g.horizontal(x, y)
+	case '|':
+		return g.vertical(x, y)
+	case '+':
+		return g.junction(x, y)
+	case '/':
+		return g.slash(x, y)
+	case '\\':
+		return g.backslash(x, y)
+	case '>', '<', '^', 'v', '*':
+		return g.arrow(x, y) != "" || g.dot(x, y)
+	}
+	return false
+}
+
+// arrow returns the direction of the arrow head at x, y: "right", "left", "up", "down" or "".
+func (g *grid) arrow(x, y int) string {
+	switch g.at(x, y) {
+	case '>':
+		if g.connects(x-1, y, 1, 0) {
+			return "right"
+		}
+	case '<':
+		if g.connects(x+1, y, -1, 0) {
+			return "left"
+		}
+	case '^':
+		if g.connects(x, y+1, 0, -1) {
+			return "up"
+		}
+	case 'v':
+		if g.connects(x, y-1, 0, 1) {
+			return "down"
+		}
+	}
+	return ""
+}
+
+// dot returns true if the * at x, y is on a line.
+func (g *grid) dot(x, y int) bool {
+	return g.at(x, y) == '*' && (g.connects(x-1, y, 1, 0) || g.connects(x+1, y, -1, 0) ||
+		g.connects(x, y-1, 0, 1) || g.connects(x, y+1, 0, -1))
+}
+
+// line writes the line segments of the character at x, y.
+func (g *grid) line(b *bytes.Buffer, x, y int) {
+	cx, cy := center(x, y)
+	segment := func(dx, dy int) {
+		fmt.Fprintf(b, `<path d="M %d,%d L %d,%d"/>`+"\n", cx, cy, cx+dx*cellWidth/2, cy+dy*cellHeight/2)
+	}
+	switch c := g.at(x, y); {
+	case c == '-' && g.horizontal(x, y):
+		fmt.Fprintf(b, `<path d="M %d,%d L %d,%d"/>`+"\n", cx-cellWidth/2, cy, cx+cellWidth/2, cy)
+	case c == '|' && g.vertical(x, y):
+		fmt.Fprintf(b, `<path d="M %d,%d L %d,%d"/>`+"\n", cx, cy-cellHeight/2, cx, cy+cellHeight/2)
+	case c == '/' && g.slash(x, y):
+		fmt.Fprintf(b, `<path d="M %d,%d L %d,%d"/>`+"\n", cx-cellWidth/2, cy+cellHeight/2, cx+cellWidth/2, cy-cellHeight/2)
+	case c == '\\' && g.backslash(x, y):
+		fmt.Fprintf(b, `<path d="M %d,%d L %d,%d"/>`+"\n", cx-cellWidth/2, cy-cellHeight/2, cx+cellWidth/2, cy+cellHeight/2)
+	case c == '+' && g.junction(x, y):
+		for _, d := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}, {-1, -1}, {1, 1}, {1, -1}, {-1, 1}} {
+			if g.connects(x+d[0], y+d[1], -d[0], -d[1]) {
+				segment(d[0], d[1])
+			}
+		}
+	case g.dot(x, y):
+		for _, d := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+			if g.connects(x+d[0], y+d[1], -d[0], -d[1]) {
+				segment(d[0], d[1])
+			}
+		}
+	case g.arrow(x, y) == "right":
+		segment(-1, 0)
+	case g.arrow(x, y) == "left":
+		segment(1, 0)
+	case g.arrow(x, y) == "up":
+		segment(0, 1)
+	case g.arrow(x, y) == "down":
+		segment(0, -1)
+	}
+}
+
+// mark writes the arrow head or dot of the character at x, y.
+func (g *grid) mark(b *bytes.Buffer, x, y int) {
+	cx, cy := center(x, y)
+	points := ""
+	switch g.arrow(x, y) {
+	case "right":
+		points = fmt.Sprintf("%d,%d %d,%d %d,%d", cx+4, cy, cx-4, cy-3, cx-4, cy+3)
+	case "left":
+		points = fmt.Sprintf("%d,%d %d,%d %d,%d", cx-4, cy, cx+4, cy-3, cx+4, cy+3)
+	case "up":
+		points = fmt.Sprintf("%d,%d %d,%d %d,%d", cx, cy-8, cx-3, cy, cx+3, cy)
+	case "down":
+		points = fmt.Sprintf("%d,%d %d,%d %d,%d", cx, cy+8, cx-3, cy, cx+3, cy)
+	}
+	if points != "" {
+		fmt.Fprintf(b, `<polygon points="%s"/>`+"\n", points)
+		return
+	}
+	if g.dot(x, y) {
+		fmt.Fprintf(b, `<circle cx="%d" cy="%d" r="3"/>`+"\n", cx, cy)
+	}
+}
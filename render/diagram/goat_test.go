@@ -0,0 +1,34 @@
+package diagram
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGoat(t *testing.T) {
+	const art = `+---+
+| A |--->
++---+
+TCP/IP`
+	svg, err := Goat{}.SVG([]byte(art))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(svg)
+	if !strings.HasPrefix(s, `<svg xmlns="http://www.w3.org/2000/svg" version="1.2" baseProfile="tiny" width="72" height="64"`) {
+		t.Errorf("unexpected svg element: %s", s)
+	}
+	for _, text := range []string{">A<", ">T<", ">/<", ">P<"} {
+		if !strings.Contains(s, "<text") || !strings.Contains(s, text+"/text>") {
+			t.Errorf("expected text %s in %s", text, s)
+		}
+	}
+	for _, line := range []string{">-<", ">|<", ">+<", ">&gt;<"} {
+		if strings.Contains(s, line+"/text>") {
+			t.Errorf("expected %s to be drawn, not text", line)
+		}
+	}
+	if n := strings.Count(s, "<polygon"); n != 1 {
+		t.Errorf("expected 1 arrow head, got %d", n)
+	}
+}
@@ -0,0 +1,62 @@
+package diagram
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PlantUMLServer is an Engine that converts PlantUML diagrams with a PlantUML server, the string is
+// the URL of the server, i.e. "https://www.plantuml.com/plantuml". Note that this sends the diagrams
+// to that server.
+type PlantUMLServer string
+
+// SVG implements Engine.
+func (p PlantUMLServer) SVG(src []byte) ([]byte, error) { return p.get("svg", src) }
+
+// Text implements Texter.
+func (p PlantUMLServer) Text(src []byte) ([]byte, error) { return p.get("txt", src) }
+
+// maxDiagramSize is the maximum size of a diagram fetched from a server.
+const maxDiagramSize = 10 << 20
+
+func (p PlantUMLServer) get(format string, src []byte) ([]byte, error) {
+	url := strings.TrimSuffix(string(p), "/") + "/" + format + "/" + plantUMLEncode(src)
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", p, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxDiagramSize))
+}
+
+const plantUMLAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz-_"
+
+// plantUMLEncode encodes src as PlantUML does in its URLs: deflated and then encoded as base64 with
+// its own alphabet, the last group of 3 bytes is padded with zeros.
+func plantUMLEncode(src []byte) string {
+	buf := &bytes.Buffer{}
+	w, _ := flate.NewWriter(buf, flate.BestCompression)
+	w.Write(src)
+	w.Close()
+	data := buf.Bytes()
+
+	s := &strings.Builder{}
+	for i := 0; i < len(data); i += 3 {
+		var b [3]byte
+		copy(b[:], data[i:])
+		s.WriteByte(plantUMLAlphabet[b[0]>>2])
+		s.WriteByte(plantUMLAlphabet[(b[0]&0x3)<<4|b[1]>>4])
+		s.WriteByte(plantUMLAlphabet[(b[1]&0xF)<<2|b[2]>>6])
+		s.WriteByte(plantUMLAlphabet[b[2]&0x3F])
+	}
+	return s.String()
+}
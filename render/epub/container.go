@@ -0,0 +1,252 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// MediaTypes maps file extensions of images to their media types.
+var MediaTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".svg":  "image/svg+xml",
+	".webp": "image/webp",
+}
+
+const container = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="EPUB/package.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+// item is a file in the EPUB container.
+type item struct {
+	id, href, mediaType string
+	properties          string
+	data                []byte
+}
+
+// write writes the EPUB container to w.
+func (r *Renderer) write(w io.Writer) {
+	items := []item{}
+	spine := []string{}
+
+	css := ""
+	if r.opts.CSS != "" {
+		data, err := ioutil.ReadFile(r.opts.CSS)
+		if err != nil {
+			r.opts.Report.Reportf(mast.SeverityError, "css", "couldn't open stylesheet %q: %s", r.opts.CSS, err)
+		} else {
+			css = "style.css"
+			items = append(items, item{id: "css", href: css, mediaType: "text/css", data: data})
+		}
+	}
+
+	nav := len(items)
+	items = append(items, item{id: "nav", href: "nav.xhtml", mediaType: "application/xhtml+xml", properties: "nav"})
+	if r.Title != nil {
+		items = append(items, item{id: "title", href: "title.xhtml", mediaType: "application/xhtml+xml", data: r.titlePage(css)})
+		spine = append(spine, "title")
+	}
+	for i, c := range r.chapters {
+		if len(bytes.TrimSpace(c.Bytes())) == 0 {
+			continue
+		}
+		id := fmt.Sprintf("chapter%d", i)
+		items = append(items, item{id: id, href: chapterName(i), mediaType: "application/xhtml+xml", data: r.xhtml(r.title(), css, c.Bytes())})
+		spine = append(spine, id)
+	}
+	first := "nav.xhtml"
+	if len(spine) > 0 {
+		first = items[nav+1].href
+	}
+	items[nav].data = r.navDocument(css, first)
+
+	seen := map[string]bool{}
+	for i, img := range r.images {
+		href := path.Clean(img)
+		if seen[href] || strings.Contains(img, "://") || strings.HasPrefix(href, "../") || path.IsAbs(href) {
+			continue
+		}
+		seen[href] = true
+		mediaType, ok := MediaTypes[strings.ToLower(path.Ext(href))]
+		if !ok {
+			r.opts.Report.Reportf(mast.SeverityWarning, "image", "unknown media type for image %q, not included", img)
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(r.opts.Dir, filepath.FromSlash(href)))
+		if err != nil {
+			r.opts.Report.Reportf(mast.SeverityError, "image", "couldn't open image %q: %s", img, err)
+			continue
+		}
+		items = append(items, item{id: fmt.Sprintf("image%d", i), href: href, mediaType: mediaType, data: data})
+	}
+
+	z := zip.NewWriter(w)
+	// The mimetype file must be first and must not be compressed.
+	f, _ := z.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store, Modified: r.opts.Modified})
+	io.WriteString(f, "application/epub+zip")
+
+	create := func(name string, data []byte) {
+		f, err := z.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: r.opts.Modified})
+		if err != nil {
+			r.opts.Report.Reportf(mast.SeverityError, "write", "couldn't create %q in the EPUB container: %s", name, err)
+			return
+		}
+		f.Write(data)
+	}
+	create("META-INF/container.xml", []byte(container))
+	create("EPUB/package.opf", r.packageDocument(items, spine))
+	for _, it := range items {
+		create("EPUB/"+it.href, it.data)
+	}
+	if err := z.Close(); err != nil {
+		r.opts.Report.Reportf(mast.SeverityError, "write", "couldn't write EPUB container: %s", err)
+	}
+}
+
+func (r *Renderer) title() string {
+	if r.opts.Title != "" {
+		return r.opts.Title
+	}
+	if r.Title != nil {
+		return r.Title.Title
+	}
+	return ""
+}
+
+func (r *Renderer) language() string {
+	if l := r.opts.Language.String(); l != "" {
+		return l
+	}
+	return "en"
+}
+
+func (r *Renderer) identifier() string {
+	if r.opts.Identifier != "" {
+		return r.opts.Identifier
+	}
+	return fmt.Sprintf("urn:mmark:%x", sha1.Sum([]byte(r.title())))
+}
+
+func escape(s string) string {
+	buf := &bytes.Buffer{}
+	html.EscapeHTML(buf, []byte(s))
+	return buf.String()
+}
+
+// packageDocument returns the package document, listing all items and the reading order.
+func (r *Renderer) packageDocument(items []item, spine []string) []byte {
+	b := &bytes.Buffer{}
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	fmt.Fprintf(b, `<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="uid" xml:lang="%s">`+"\n", r.language())
+	b.WriteString(`  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">` + "\n")
+	fmt.Fprintf(b, "    <dc:identifier id=\"uid\">%s</dc:identifier>\n", escape(r.identifier()))
+	fmt.Fprintf(b, "    <dc:title>%s</dc:title>\n", escape(r.title()))
+	fmt.Fprintf(b, "    <dc:language>%s</dc:language>\n", r.language())
+	if r.Title != nil {
+		for _, a := range r.Title.Author {
+			fmt.Fprintf(b, "    <dc:creator>%s</dc:creator>\n", escape(a.Fullname))
+		}
+		if !r.Title.Date.IsZero() {
+			fmt.Fprintf(b, "    <dc:date>%s</dc:date>\n", r.Title.Date.Format("2006-01-02"))
+		}
+	}
+	fmt.Fprintf(b, "    <meta property=\"dcterms:modified\">%s</meta>\n", r.opts.Modified.UTC().Format(time.RFC3339))
+	b.WriteString("  </metadata>\n  <manifest>\n")
+	for _, it := range items {
+		props := ""
+		if it.properties != "" {
+			props = ` properties="` + it.properties + `"`
+		}
+		fmt.Fprintf(b, "    <item id=\"%s\" href=\"%s\" media-type=\"%s\"%s/>\n", it.id, escape(it.href), it.mediaType, props)
+	}
+	b.WriteString("  </manifest>\n  <spine>\n")
+	for _, id := range spine {
+		fmt.Fprintf(b, "    <itemref idref=\"%s\"/>\n", id)
+	}
+	b.WriteString("  </spine>\n</package>\n")
+	return b.Bytes()
+}
+
+// xhtml wraps body in a complete XHTML document.
+func (r *Renderer) xhtml(title, css string, body []byte) []byte {
+	b := &bytes.Buffer{}
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n<!DOCTYPE html>\n")
+	fmt.Fprintf(b, `<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="%s" lang="%s">`+"\n", r.language(), r.language())
+	fmt.Fprintf(b, "<head>\n<meta charset=\"UTF-8\" />\n<title>%s</title>\n", escape(title))
+	if css != "" {
+		fmt.Fprintf(b, "<link rel=\"stylesheet\" type=\"text/css\" href=\"%s\" />\n", css)
+	}
+	b.WriteString("</head>\n<body>\n")
+	b.Write(body)
+	b.WriteString("</body>\n</html>\n")
+	return b.Bytes()
+}
+
+// titlePage returns the title page with the title, authors and date.
+func (r *Renderer) titlePage(css string) []byte {
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "<section epub:type=\"titlepage\">\n<h1 class=\"title\">%s</h1>\n", escape(r.title()))
+	for _, a := range r.Title.Author {
+		fmt.Fprintf(b, "<p class=\"author\">%s</p>\n", escape(a.Fullname))
+	}
+	if !r.Title.Date.IsZero() {
+		fmt.Fprintf(b, "<p class=\"date\">%s</p>\n", r.Title.Date.Format("2 January 2006"))
+	}
+	b.WriteString("</section>\n")
+	return r.xhtml(r.title(), css, b.Bytes())
+}
+
+// navDocument returns the navigation document, this is the table of contents.
+// If there are no headings, first is used as the only entry.
+func (r *Renderer) navDocument(css, first string) []byte {
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "<nav epub:type=\"toc\" id=\"toc\">\n<h1>%s</h1>\n", escape(r.opts.Language.Contents()))
+	entries := r.nav
+	if len(entries) == 0 {
+		entries = []navEntry{{level: 1, title: r.title(), href: first}}
+	}
+	depth := 0
+	for _, e := range entries {
+		level := e.level
+		if level > depth+1 {
+			level = depth + 1
+		}
+		switch {
+		case level > depth:
+			b.WriteString("<ol>\n")
+			depth = level
+		case level == depth:
+			b.WriteString("</li>\n")
+		default:
+			for depth > level {
+				b.WriteString("</li>\n</ol>\n")
+				depth--
+			}
+			b.WriteString("</li>\n")
+		}
+		fmt.Fprintf(b, "<li><a href=\"%s\">%s</a>", escape(e.href), escape(e.title))
+	}
+	for depth > 0 {
+		b.WriteString("</li>\n</ol>\n")
+		depth--
+	}
+	b.WriteString("</nav>\n")
+	return r.xhtml(r.opts.Language.Contents(), css, b.Bytes())
+}
@@ -0,0 +1,227 @@
+// The package epub outputs an EPUB3 container from mmark markdown. The HTML output is split into a
+// chapter per level 1 heading, images are included and a navigation document is generated.
+package epub
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/v2/lang"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// RendererOptions is a collection of supplementary parameters tweaking
+// the behavior of various parts of the EPUB renderer.
+type RendererOptions struct {
+	Language lang.Lang // Output language for the document.
+
+	Title      string    // Title of the book, defaults to the title from the title block.
+	Identifier string    // Unique identifier of the book, defaults to an URN derived from the title.
+	CSS        string    // Optional path to a CSS file that is included in the book.
+	Dir        string    // Directory relative to which images are read.
+	Modified   time.Time // Modification time of the book, defaults to now.
+
+	// if set, called at the start of RenderNode(). Allows replacing rendering of some nodes
+	RenderNodeHook html.RenderNodeFunc
+
+	// Report, if set, is called with the problems found while rendering, otherwise these are logged.
+	Report mast.Reporter
+}
+
+// Renderer implements Renderer interface for EPUB output.
+type Renderer struct {
+	opts RendererOptions
+	html *html.Renderer
+
+	Title *mast.Title
+
+	chapters []*bytes.Buffer
+	current  int
+	starts   map[ast.Node]int // headings that start a new chapter
+	ids      map[string]int   // anchor to chapter
+	nav      []navEntry
+	images   []string
+}
+
+// navEntry is an entry in the navigation document.
+type navEntry struct {
+	level int
+	title string
+	href  string
+}
+
+// NewRenderer creates and configures an Renderer object, which satisfies the Renderer interface.
+func NewRenderer(opts RendererOptions) *Renderer {
+	if opts.Modified.IsZero() {
+		opts.Modified = time.Now()
+	}
+	hopts := html.RendererOptions{
+		Flags: html.UseXHTML | html.FootnoteNoHRTag | html.FootnoteReturnLinks, // no smartypants, XHTML doesn't know the entities
+
+		RenderNodeHook: opts.RenderNodeHook,
+	}
+	return &Renderer{
+		opts:     opts,
+		html:     html.NewRenderer(hopts),
+		chapters: []*bytes.Buffer{{}},
+		starts:   map[ast.Node]int{},
+		ids:      map[string]int{},
+	}
+}
+
+// chapterName returns the file name of chapter i.
+func chapterName(i int) string { return fmt.Sprintf("chapter%d.xhtml", i) }
+
+// RenderNode renders a markdown node to XHTML into the current chapter.
+func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.WalkStatus {
+	if c, ok := r.starts[node]; ok && entering {
+		for len(r.chapters) <= c {
+			r.chapters = append(r.chapters, &bytes.Buffer{})
+		}
+		r.current = c
+	}
+	w = r.chapters[r.current]
+	switch node := node.(type) {
+	case *ast.DocumentMatter:
+		// sections would span chapters.
+		return ast.GoToNext
+	case *ast.HTMLSpan:
+		r.htmlSpan(w, node)
+		return ast.GoToNext
+	case *ast.NonBlockingSpace:
+		io.WriteString(w, "&#160;")
+		return ast.GoToNext
+	}
+	return r.html.RenderNode(w, node, entering)
+}
+
+// InlineTags are the HTML tags that are allowed in inline HTML, other tags are escaped and output as text.
+var InlineTags = map[string]bool{
+	"a": true, "abbr": true, "b": true, "cite": true, "code": true, "del": true, "dfn": true, "em": true,
+	"i": true, "ins": true, "kbd": true, "mark": true, "q": true, "s": true, "samp": true, "small": true,
+	"span": true, "strong": true, "sub": true, "sup": true, "time": true, "u": true, "var": true,
+}
+
+var tagName = regexp.MustCompile(`^</?([a-zA-Z][a-zA-Z0-9]*)`)
+
+func (r *Renderer) htmlSpan(w io.Writer, span *ast.HTMLSpan) {
+	if bytes.HasPrefix(span.Literal, []byte("<!--")) {
+		return
+	}
+	m := tagName.FindSubmatch(span.Literal)
+	switch {
+	case m == nil:
+		html.EscapeHTML(w, span.Literal)
+	case bytes.EqualFold(m[1], []byte("br")):
+		io.WriteString(w, "<br />")
+	case InlineTags[strings.ToLower(string(m[1]))]:
+		w.Write(span.Literal)
+	default:
+		html.EscapeHTML(w, span.Literal)
+	}
+}
+
+// RenderHeader walks the document to find the chapters, the anchors in them and the images.
+func (r *Renderer) RenderHeader(w io.Writer, doc ast.Node) {
+	chapter := 0
+	empty := true // no content in the current chapter yet
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch n := node.(type) {
+		case *mast.Title:
+			r.Title = n
+			return ast.SkipChildren
+		case *ast.DocumentMatter, *ast.Document:
+			return ast.GoToNext
+		case *ast.Heading:
+			if n.Level == 1 && !empty {
+				chapter++
+				r.starts[n] = chapter
+			}
+			if n.HeadingID != "" && n.Level <= 3 {
+				r.nav = append(r.nav, navEntry{level: n.Level, title: plain(n), href: chapterName(chapter) + "#" + n.HeadingID})
+			}
+		case *ast.Image:
+			r.images = append(r.images, string(n.Destination))
+		case *ast.Link:
+			if n.Footnote != nil {
+				r.ids["fnref:"+string(html.Slugify(n.Destination))] = chapter
+			}
+		}
+		empty = false
+		if id := nodeID(node); id != "" {
+			r.ids[id] = chapter
+		}
+		return ast.GoToNext
+	})
+	// footnotes are put at the end of the document.
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if l, ok := node.(*ast.Link); ok && l.Footnote != nil {
+			r.ids["fn:"+string(html.Slugify(l.Destination))] = chapter
+		}
+		return ast.GoToNext
+	})
+}
+
+// RenderFooter writes the EPUB container to w.
+func (r *Renderer) RenderFooter(w io.Writer, _ ast.Node) {
+	for i := range r.chapters {
+		r.chapters[i] = bytes.NewBuffer(r.relink(i, r.chapters[i].Bytes()))
+	}
+	r.write(w)
+}
+
+var hrefFragment = regexp.MustCompile(`href="#([^"]+)"`)
+
+// relink rewrites links to anchors in other chapters.
+func (r *Renderer) relink(chapter int, data []byte) []byte {
+	return hrefFragment.ReplaceAllFunc(data, func(href []byte) []byte {
+		id := string(hrefFragment.FindSubmatch(href)[1])
+		c, ok := r.ids[id]
+		if !ok || c == chapter {
+			return href
+		}
+		return []byte(`href="` + chapterName(c) + "#" + id + `"`)
+	})
+}
+
+// nodeID returns the ID from the attribute of node, or the heading ID.
+func nodeID(node ast.Node) string {
+	if h, ok := node.(*ast.Heading); ok {
+		return h.HeadingID
+	}
+	var a *ast.Attribute
+	if c := node.AsContainer(); c != nil {
+		a = c.Attribute
+	}
+	if l := node.AsLeaf(); l != nil {
+		a = l.Attribute
+	}
+	if a == nil {
+		return ""
+	}
+	return string(a.ID)
+}
+
+// plain returns the text of node's children, without markup.
+func plain(node ast.Node) string {
+	buf := &bytes.Buffer{}
+	ast.WalkFunc(node, func(n ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		if l := n.AsLeaf(); l != nil {
+			buf.Write(l.Literal)
+		}
+		return ast.GoToNext
+	})
+	return buf.String()
+}
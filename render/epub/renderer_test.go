@@ -0,0 +1,58 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/lang"
+	"github.com/mmarkdown/mmark/v2/mparser"
+)
+
+const book = `# One
+
+See (#two).
+
+# Two
+
+Text.
+`
+
+func TestEpub(t *testing.T) {
+	p := parser.NewWithExtensions(mparser.Extensions)
+	p.Opts = parser.Options{ParserHook: mparser.Hook}
+	doc := markdown.Parse([]byte(book), p)
+	renderer := NewRenderer(RendererOptions{Language: lang.New("en"), Title: "Book"})
+	data := markdown.Render(doc, renderer)
+
+	z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("not a zip container: %s", err)
+	}
+	if z.File[0].Name != "mimetype" || z.File[0].Method != zip.Store {
+		t.Errorf("expected mimetype to be the first, stored, file, got %q", z.File[0].Name)
+	}
+
+	files := map[string]string{}
+	for _, f := range z.File {
+		rc, _ := f.Open()
+		buf, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(buf)
+	}
+	for _, name := range []string{"META-INF/container.xml", "EPUB/package.opf", "EPUB/nav.xhtml", "EPUB/chapter0.xhtml", "EPUB/chapter1.xhtml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("expected %q in the container", name)
+		}
+	}
+	if !strings.Contains(files["EPUB/chapter0.xhtml"], `href="chapter1.xhtml#two"`) {
+		t.Errorf("expected link to the other chapter, got %s", files["EPUB/chapter0.xhtml"])
+	}
+	if !strings.Contains(files["EPUB/nav.xhtml"], `<a href="chapter1.xhtml#two">Two</a>`) {
+		t.Errorf("expected nav entry for the second chapter, got %s", files["EPUB/nav.xhtml"])
+	}
+}
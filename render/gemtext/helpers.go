@@ -0,0 +1,59 @@
+package gemtext
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// line writes a single line.
+func (r *Renderer) line(w io.Writer, s string) {
+	if r.blanked {
+		io.WriteString(w, "\n")
+		r.blanked = false
+	}
+	io.WriteString(w, s+"\n")
+	r.written = true
+}
+
+// text writes s as text lines, the first line is prefixed with first. In a block quote each line is a
+// quote line. A text line that looks like another line type gets a leading space.
+func (r *Renderer) text(w io.Writer, first, s string) {
+	prefix := ""
+	if r.quote > 0 {
+		prefix = "> "
+	}
+	for i, l := range strings.Split(s, string(hardBreak)) {
+		l = strings.TrimSpace(l)
+		if i == 0 {
+			l = first + l
+		} else if first != "" && l == "" {
+			continue
+		}
+		if prefix == "" && first == "" && lineStart(l) {
+			l = " " + l
+		}
+		r.line(w, prefix+l)
+	}
+}
+
+// blank makes sure the next line is preceded by an empty line.
+func (r *Renderer) blank(w io.Writer) { r.blanked = r.written }
+
+// lineStart returns true if l starts with a prefix that gives a line a type in gemtext.
+func lineStart(l string) bool {
+	for _, p := range []string{"=>", "#", "* ", ">", "```"} {
+		if strings.HasPrefix(l, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// pad pads s with spaces until it is width runes wide.
+func pad(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
@@ -0,0 +1,650 @@
+// The package gemtext outputs gemtext, the line oriented format of the Gemini protocol, from mmark
+// markdown. Gemtext has no inline links, so links and citations are collected and output as link
+// lines at the end of each section.
+package gemtext
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/v2/lang"
+	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/mast/reference"
+	xml "github.com/mmarkdown/mmark/v2/render/xml"
+)
+
+// Flags control optional behavior of the gemtext renderer.
+type Flags int
+
+// Gemtext renderer configuration options.
+const (
+	FlagsNone       Flags = 0
+	GemtextFragment Flags = 1 << iota // Don't generate a complete document, i.e. skip the title block
+
+	CommonFlags Flags = FlagsNone
+)
+
+// RendererOptions is a collection of supplementary parameters tweaking
+// the behavior of various parts of the gemtext renderer.
+type RendererOptions struct {
+	Flags Flags // Flags allow customizing this renderer's behavior
+
+	Language lang.Lang // Output language for the document.
+
+	// if set, called at the start of RenderNode(). Allows replacing rendering of some nodes
+	RenderNodeHook html.RenderNodeFunc
+}
+
+// Renderer implements Renderer interface for gemtext output.
+type Renderer struct {
+	opts RendererOptions
+
+	Title *mast.Title
+
+	inline *strings.Builder // inline text of the current block
+	starts []int            // start of the link texts in inline
+	offset int              // added to the heading level, 1 when we output the title
+
+	quote   int    // depth of block quotes and asides
+	bullet  string // list marker for the next line
+	counter []int  // item counters of open lists
+	caption string // caption of the current figure, used as alt text for preformatted text
+
+	links  []link            // link lines for the current section
+	labels map[string]string // destination to label for the current section
+	count  int               // link counter
+
+	targets map[string]string // bibliography anchor to URL
+	titles  map[string]string // bibliography anchor to title
+	xrefs   map[string]string // anchor to heading text
+
+	written bool // something has been output
+	blanked bool // an empty line is output before the next line
+}
+
+// hardBreak marks a line break in the inline text.
+const hardBreak = '\u2028'
+
+// link is a link line that will be output at the end of a section.
+type link struct {
+	dest  string
+	label string
+	text  string
+}
+
+// NewRenderer creates and configures an Renderer object, which satisfies the Renderer interface.
+func NewRenderer(opts RendererOptions) *Renderer {
+	return &Renderer{
+		opts:    opts,
+		inline:  &strings.Builder{},
+		labels:  map[string]string{},
+		targets: map[string]string{},
+		titles:  map[string]string{},
+		xrefs:   map[string]string{},
+	}
+}
+
+// inlineText returns the text of node's children.
+func (r *Renderer) inlineText(node ast.Node) string {
+	save := r.inline
+	r.inline = &strings.Builder{}
+	for _, c := range node.GetChildren() {
+		ast.WalkFunc(c, func(n ast.Node, entering bool) ast.WalkStatus {
+			return r.RenderNode(nil, n, entering)
+		})
+	}
+	s := r.inline.String()
+	r.inline = save
+	return strings.TrimSpace(s)
+}
+
+// take returns the inline text gathered so far and resets it.
+func (r *Renderer) take() string {
+	s := r.inline.String()
+	r.inline.Reset()
+	return strings.TrimSpace(s)
+}
+
+// addLink adds a link line for dest and returns its label. If the label is empty, a numbered one
+// is created. Destinations that are already linked in this section reuse the existing label.
+func (r *Renderer) addLink(dest, label, text string) string {
+	if l, ok := r.labels[dest]; ok {
+		return l
+	}
+	if label == "" {
+		r.count++
+		label = fmt.Sprintf("[%d]", r.count)
+	}
+	r.labels[dest] = label
+	r.links = append(r.links, link{dest: dest, label: label, text: text})
+	return label
+}
+
+// linkLines outputs the link lines gathered in this section.
+func (r *Renderer) linkLines(w io.Writer) {
+	if len(r.links) == 0 {
+		return
+	}
+	r.blank(w)
+	for _, l := range r.links {
+		r.line(w, strings.TrimSpace("=> "+l.dest+" "+l.label+" "+l.text))
+	}
+	r.blank(w)
+	r.links = nil
+	r.labels = map[string]string{}
+}
+
+// blockStart separates a block from the previous one, blocks in lists aren't separated.
+func (r *Renderer) blockStart(w io.Writer, node ast.Node) {
+	for p := node.GetParent(); p != nil; p = p.GetParent() {
+		if _, ok := p.(*ast.ListItem); ok {
+			return
+		}
+	}
+	r.blank(w)
+}
+
+func (r *Renderer) heading(w io.Writer, node *ast.Heading, entering bool) {
+	if entering {
+		r.linkLines(w)
+		r.blank(w)
+		r.take()
+		return
+	}
+	r.sectionTitle(w, node.Level, r.take())
+}
+
+// sectionTitle outputs a heading line, gemtext has three levels of headings.
+func (r *Renderer) sectionTitle(w io.Writer, level int, title string) {
+	level += r.offset
+	if level > 3 {
+		level = 3
+	}
+	r.blank(w)
+	r.line(w, strings.Repeat("#", level)+" "+title)
+	r.blank(w)
+}
+
+func (r *Renderer) paragraph(w io.Writer, node *ast.Paragraph, entering bool) {
+	if entering {
+		r.blockStart(w, node)
+		r.take()
+		return
+	}
+	text := r.take()
+	if text == "" {
+		return
+	}
+	bullet := r.bullet
+	r.bullet = ""
+	r.text(w, bullet, text)
+	r.blockStart(w, node)
+}
+
+func (r *Renderer) list(w io.Writer, node *ast.List, entering bool) {
+	if entering {
+		r.blockStart(w, node)
+		r.counter = append(r.counter, node.Start)
+		if node.Start == 0 {
+			r.counter[len(r.counter)-1] = 1
+		}
+		return
+	}
+	r.counter = r.counter[:len(r.counter)-1]
+	r.blockStart(w, node)
+}
+
+func (r *Renderer) listItem(w io.Writer, node *ast.ListItem, entering bool) {
+	if !entering {
+		// footnote items can hold text directly.
+		if text := r.take(); text != "" {
+			r.text(w, r.bullet, text)
+		}
+		r.bullet = ""
+		return
+	}
+	r.take()
+	n := len(r.counter) - 1
+	switch {
+	case node.RefLink != nil:
+		r.bullet = fmt.Sprintf("[^%d] ", r.counter[n])
+	case node.ListFlags&ast.ListTypeTerm != 0:
+		r.bullet = ""
+		return
+	case node.ListFlags&ast.ListTypeDefinition != 0:
+		r.bullet = "* "
+		return
+	case node.ListFlags&ast.ListTypeOrdered != 0:
+		r.bullet = fmt.Sprintf("* %d. ", r.counter[n])
+	default:
+		r.bullet = "* "
+	}
+	r.counter[n]++
+}
+
+// preformatted outputs literal as preformatted text, the caption of the figure, if any, is used
+// as the alt text.
+func (r *Renderer) preformatted(w io.Writer, node ast.Node, alt string, literal []byte) {
+	r.blank(w)
+	if r.caption != "" {
+		alt = r.caption
+		r.caption = ""
+	}
+	r.line(w, strings.TrimSpace("``` "+alt))
+	for _, l := range strings.Split(strings.TrimRight(string(literal), "\n"), "\n") {
+		if strings.HasPrefix(l, "```") {
+			l = " " + l
+		}
+		r.line(w, l)
+	}
+	r.line(w, "```")
+	r.blockStart(w, node)
+}
+
+// table outputs a table as preformatted text with the columns aligned.
+func (r *Renderer) table(w io.Writer, tab *ast.Table) {
+	rows := [][]string{}
+	header := 0
+	widths := []int{}
+	ast.WalkFunc(tab, func(node ast.Node, entering bool) ast.WalkStatus {
+		row, ok := node.(*ast.TableRow)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		cells := []string{}
+		for i, c := range row.GetChildren() {
+			text := r.inlineText(c)
+			cells = append(cells, text)
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if n := utf8.RuneCountInString(text); n > widths[i] {
+				widths[i] = n
+			}
+		}
+		if _, ok := row.Parent.(*ast.TableHeader); ok {
+			header++
+		}
+		rows = append(rows, cells)
+		return ast.SkipChildren
+	})
+
+	lines := []string{}
+	for i, cells := range rows {
+		if i > 0 && i == header {
+			sep := make([]string, len(widths))
+			for j := range widths {
+				sep[j] = strings.Repeat("-", widths[j])
+			}
+			lines = append(lines, strings.Join(sep, "-+-"))
+		}
+		for j := range cells {
+			cells[j] = pad(cells[j], widths[j])
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(cells, " | "), " "))
+	}
+	r.preformatted(w, tab, "", []byte(strings.Join(lines, "\n")))
+}
+
+func (r *Renderer) captionFigure(w io.Writer, figure *ast.CaptionFigure, entering bool) {
+	if !entering {
+		r.caption = ""
+		return
+	}
+	r.blockStart(w, figure)
+	for _, c := range figure.Children {
+		if capt, ok := c.(*ast.Caption); ok {
+			r.caption = r.inlineText(capt)
+		}
+	}
+}
+
+// captionLine outputs the caption of a figure when it isn't used as alt text.
+func (r *Renderer) captionLine(w io.Writer, caption *ast.Caption) {
+	if r.caption == "" {
+		return
+	}
+	text := r.caption
+	r.caption = ""
+	if _, ok := ast.GetFirstChild(caption.Parent).(*ast.BlockQuote); ok {
+		r.quote++
+		r.blanked = false
+		r.text(w, "", "— "+text)
+		r.quote--
+		return
+	}
+	r.text(w, "", text)
+}
+
+func (r *Renderer) citation(node *ast.Citation) {
+	cites := []string{}
+	for i, c := range node.Destination {
+		if node.Type[i] == ast.CitationTypeSuppressed {
+			continue
+		}
+		if a := xml.AuthorFromTitle(c, r.Title); a != nil {
+			cites = append(cites, a.Fullname)
+			continue
+		}
+		if a := xml.ContactFromTitle(c, r.Title); a != nil {
+			cites = append(cites, a.Fullname)
+			continue
+		}
+		anchor := string(c)
+		cite := "[" + anchor + "]"
+		if target, ok := r.targets[anchor]; ok {
+			r.addLink(target, cite, r.titles[anchor])
+		}
+		if len(node.Suffix) > i && len(node.Suffix[i]) > 0 {
+			cite += ", " + string(node.Suffix[i])
+		}
+		cites = append(cites, cite)
+	}
+	r.inline.WriteString(strings.Join(cites, ", "))
+}
+
+func (r *Renderer) link(link *ast.Link, entering bool) {
+	if link.Footnote != nil {
+		if entering {
+			fmt.Fprintf(r.inline, "[^%d]", link.NoteID)
+		}
+		return
+	}
+	if entering {
+		r.starts = append(r.starts, r.inline.Len())
+		return
+	}
+	start := r.starts[len(r.starts)-1]
+	r.starts = r.starts[:len(r.starts)-1]
+	text := strings.TrimSpace(r.inline.String()[start:])
+	dest := string(link.Destination)
+	if text == "" || text == dest {
+		text = ""
+	}
+	label := r.addLink(dest, "", text)
+	r.inline.WriteString(label)
+}
+
+func (r *Renderer) image(image *ast.Image) {
+	alt := r.inlineText(image)
+	if alt != "" {
+		r.inline.WriteString(alt)
+	}
+	label := r.addLink(string(image.Destination), "", alt)
+	r.inline.WriteString(label)
+}
+
+func (r *Renderer) bibliography(w io.Writer, node ast.Node, entering bool) {
+	if !entering || len(node.GetChildren()) == 0 {
+		return
+	}
+	r.linkLines(w)
+	title := r.opts.Language.Bibliography()
+	level := 1
+	if b, ok := node.(*mast.Bibliography); ok {
+		switch b.Type {
+		case ast.CitationTypeNormative:
+			title = "Normative References"
+		case ast.CitationTypeInformative:
+			title = "Informative References"
+		}
+		if _, ok := b.Parent.(*mast.BibliographyWrapper); ok {
+			level = 2
+		}
+	}
+	r.sectionTitle(w, level, title)
+}
+
+// bibliographyItem outputs a link line for the reference, or a text line if there is no URL.
+func (r *Renderer) bibliographyItem(w io.Writer, node *mast.BibliographyItem) {
+	anchor := string(node.Anchor)
+	text := r.titles[anchor]
+	if node.Reference != nil {
+		text = referenceText(node.Reference)
+	}
+	if target, ok := r.targets[anchor]; ok {
+		r.line(w, strings.TrimSpace("=> "+target+" ["+anchor+"] "+text))
+		return
+	}
+	r.text(w, "", strings.TrimSpace("["+anchor+"] "+text))
+}
+
+// collect walks the document to find the URLs of the references and the text of the headings.
+func (r *Renderer) collect(doc ast.Node) {
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch n := node.(type) {
+		case *mast.Title:
+			r.Title = n
+		case *ast.Heading:
+			if n.HeadingID != "" {
+				r.xrefs[n.HeadingID] = r.inlineText(n)
+			}
+			return ast.SkipChildren
+		case *mast.BibliographyItem:
+			anchor := string(n.Anchor)
+			if n.Reference != nil {
+				r.titles[anchor] = n.Reference.Front.Title
+				if n.Reference.Target != "" {
+					r.targets[anchor] = n.Reference.Target
+				}
+			}
+			if _, ok := r.targets[anchor]; !ok {
+				if target := anchorTarget(anchor); target != "" {
+					r.targets[anchor] = target
+				}
+			}
+		}
+		return ast.GoToNext
+	})
+	// inlineText added the links of the headings, these are added again when rendering.
+	r.links = nil
+	r.labels = map[string]string{}
+	r.count = 0
+}
+
+// anchorTarget returns the URL for RFC and Internet-Draft anchors.
+func anchorTarget(anchor string) string {
+	switch {
+	case strings.HasPrefix(anchor, "RFC"):
+		num := strings.TrimLeft(anchor[3:], "0")
+		if num == "" || strings.Trim(num, "0123456789") != "" {
+			return ""
+		}
+		return "https://www.rfc-editor.org/info/rfc" + num
+	case strings.HasPrefix(anchor, "I-D."):
+		draft := strings.TrimPrefix(anchor, "I-D.")
+		if hash := strings.Index(draft, "#"); hash > 0 {
+			draft = draft[:hash]
+		}
+		return "https://datatracker.ietf.org/doc/draft-" + draft + "/"
+	}
+	return ""
+}
+
+// referenceText returns the text for a reference: authors, title, series and date.
+func referenceText(ref *reference.Reference) string {
+	parts := []string{}
+	names := []string{}
+	for _, a := range ref.Front.Authors {
+		switch {
+		case a.Fullname != "":
+			names = append(names, a.Fullname)
+		case a.Surname != "":
+			names = append(names, strings.TrimSpace(a.Initials+" "+a.Surname))
+		case a.Organization != nil && a.Organization.Value != "":
+			names = append(names, a.Organization.Value)
+		}
+	}
+	if len(names) > 0 {
+		parts = append(parts, strings.Join(names, ", "))
+	}
+	parts = append(parts, `"`+ref.Front.Title+`"`)
+	for _, s := range ref.Series {
+		parts = append(parts, strings.TrimSpace(s.Name+" "+s.Value))
+	}
+	if d := ref.Front.Date; d != nil && d.Year != "" {
+		parts = append(parts, strings.TrimSpace(d.Month+" "+d.Year))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// RenderNode renders a markdown node to gemtext.
+func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.WalkStatus {
+	if r.opts.RenderNodeHook != nil {
+		status, didHandle := r.opts.RenderNodeHook(w, node, entering)
+		if didHandle {
+			return status
+		}
+	}
+
+	switch node := node.(type) {
+	case *ast.Document:
+		// do nothing
+	case *mast.Title:
+		r.Title = node
+		if entering && r.opts.Flags&GemtextFragment == 0 {
+			r.titleBlock(w, node)
+		}
+	case *mast.Authors:
+		// ignore
+	case *mast.BibliographyWrapper, *mast.Bibliography:
+		r.bibliography(w, node, entering)
+	case *mast.BibliographyItem:
+		if entering {
+			r.bibliographyItem(w, node)
+		}
+		return ast.SkipChildren
+	case *mast.DocumentIndex:
+		// gemtext has no anchors to point to
+		return ast.SkipChildren
+	case *mast.IndexLetter, *mast.IndexItem, *mast.IndexSubItem, *mast.IndexLink:
+	case *mast.ReferenceBlock:
+		// skip, added and done by AddBibliography
+	case *ast.Footnotes:
+		if entering {
+			r.linkLines(w)
+			r.sectionTitle(w, 1, r.opts.Language.Footnotes())
+		}
+	case *ast.Text:
+		r.inline.WriteString(strings.ReplaceAll(string(node.Literal), "\n", " "))
+	case *ast.Softbreak:
+		r.inline.WriteString(" ")
+	case *ast.Hardbreak:
+		r.inline.WriteRune(hardBreak)
+	case *ast.NonBlockingSpace:
+		r.inline.WriteString(" ")
+	case *ast.Callout:
+		r.inline.WriteString("<" + string(node.ID) + ">")
+	case *mast.Unicode:
+		r.inline.WriteString(node.String())
+	case *mast.Abbreviation:
+		r.inline.WriteString(node.String())
+	case *mast.Cref:
+		r.inline.WriteString(node.String())
+	case *ast.Emph, *ast.Strong, *ast.Del:
+		// no inline formatting in gemtext
+	case *ast.Citation:
+		if entering {
+			r.citation(node)
+		}
+	case *ast.DocumentMatter:
+		// do nothing
+	case *ast.Heading:
+		r.heading(w, node, entering)
+	case *ast.HorizontalRule:
+		r.blank(w)
+	case *ast.Paragraph:
+		r.paragraph(w, node, entering)
+	case *ast.HTMLSpan:
+		if xml.IsBr(node.Literal) {
+			r.inline.WriteRune(hardBreak)
+			break
+		}
+		if _, ok := xml.IsComment(node.Literal); ok {
+			break
+		}
+		r.inline.Write(node.Literal)
+	case *ast.HTMLBlock:
+		// skip
+	case *ast.List:
+		r.list(w, node, entering)
+	case *ast.ListItem:
+		r.listItem(w, node, entering)
+	case *ast.CodeBlock:
+		r.preformatted(w, node, string(node.Info), node.Literal)
+	case *ast.Caption:
+		if entering {
+			r.captionLine(w, node)
+		}
+		return ast.SkipChildren
+	case *ast.CaptionFigure:
+		r.captionFigure(w, node, entering)
+	case *ast.Table:
+		if entering {
+			r.table(w, node)
+		}
+		return ast.SkipChildren
+	case *ast.TableCell, *ast.TableHeader, *ast.TableBody, *ast.TableFooter, *ast.TableRow:
+		// done in table
+	case *ast.BlockQuote, *ast.Aside:
+		if entering {
+			r.blockStart(w, node)
+			r.quote++
+		} else {
+			r.quote--
+		}
+	case *mast.Div:
+		// gemtext has no containers, render the contents.
+	case *ast.CrossReference:
+		if !entering {
+			break
+		}
+		text := r.inlineText(node)
+		if text == "" {
+			text = r.xrefs[string(node.Destination)]
+		}
+		if text == "" {
+			text = "[" + string(node.Destination) + "]"
+		}
+		r.inline.WriteString(text)
+		return ast.SkipChildren
+	case *ast.Index:
+		// no index in gemtext
+	case *ast.Link:
+		r.link(node, entering)
+	case *ast.Math:
+		r.inline.Write(node.Literal)
+	case *ast.Image:
+		if entering {
+			r.image(node)
+		}
+		return ast.SkipChildren
+	case *ast.Code:
+		r.inline.Write(node.Literal)
+	case *ast.MathBlock:
+		if entering {
+			r.preformatted(w, node, "math", node.Literal)
+		}
+	case *ast.Subscript:
+		r.inline.WriteString("_" + string(node.Literal))
+	case *ast.Superscript:
+		r.inline.WriteString("^" + string(node.Literal))
+	default:
+		panic(fmt.Sprintf("Unknown node %T", node))
+	}
+	return ast.GoToNext
+}
+
+// RenderHeader collects the reference URLs and heading texts, so citations and cross references
+// can be resolved.
+func (r *Renderer) RenderHeader(w io.Writer, doc ast.Node) { r.collect(doc) }
+
+// RenderFooter outputs the remaining link lines.
+func (r *Renderer) RenderFooter(w io.Writer, _ ast.Node) { r.linkLines(w) }
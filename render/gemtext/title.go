@@ -0,0 +1,42 @@
+package gemtext
+
+import (
+	"io"
+	"strings"
+
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// titleBlock outputs the title as a level 1 heading, followed by the authors, the date and the
+// document name. Sections in the document start at level 2.
+func (r *Renderer) titleBlock(w io.Writer, t *mast.Title) {
+	d := t.TitleData
+	if d == nil {
+		return
+	}
+	r.offset = 1
+	r.line(w, "# "+d.Title)
+	r.blank(w)
+
+	authors := []string{}
+	for _, a := range d.Author {
+		if a.Fullname == "" {
+			continue
+		}
+		author := a.Fullname
+		if a.Organization != "" {
+			author += " (" + a.Organization + ")"
+		}
+		authors = append(authors, author)
+	}
+	if len(authors) > 0 {
+		r.text(w, "", strings.Join(authors, ", "))
+	}
+	if !d.Date.IsZero() {
+		r.line(w, d.Date.Format("2 January 2006"))
+	}
+	if d.SeriesInfo.Value != "" {
+		r.text(w, "", d.SeriesInfo.Value)
+	}
+	r.blank(w)
+}
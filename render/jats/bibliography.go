@@ -0,0 +1,121 @@
+package jats
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/mast/reference"
+)
+
+func (r *Renderer) bibliography(w io.Writer, node ast.Node, entering bool) {
+	if len(node.GetChildren()) == 0 {
+		return
+	}
+	if !entering {
+		r.outs(w, "</ref-list>\n")
+		return
+	}
+	if topLevel(node) {
+		r.part(w, partBack)
+		r.sectionClose(w, 1)
+		r.appGroupClose(w)
+	}
+
+	title := r.opts.Language.Bibliography()
+	if b, ok := node.(*mast.Bibliography); ok {
+		switch b.Type {
+		case ast.CitationTypeNormative:
+			title = "Normative References"
+		case ast.CitationTypeInformative:
+			title = "Informative References"
+		}
+	}
+	r.outs(w, "<ref-list>\n")
+	r.outTagContent(w, "title", title)
+	r.cr(w)
+}
+
+// bibliographyItem outputs a ref with an element-citation for the reference. If the reference isn't
+// known only the anchor is output as a mixed-citation.
+func (r *Renderer) bibliographyItem(w io.Writer, node *mast.BibliographyItem) {
+	r.outs(w, `<ref id="`+Escape(string(node.Anchor))+`">`)
+	r.outTagContent(w, "label", "["+string(node.Anchor)+"]")
+	ref := node.Reference
+	if ref == nil {
+		r.mixedCitation(w, node.Anchor)
+		r.outs(w, "</ref>\n")
+		return
+	}
+
+	r.outs(w, `<element-citation publication-type="`+publicationType(ref)+`">`)
+	if len(ref.Front.Authors) > 0 {
+		r.outs(w, `<person-group person-group-type="author">`)
+		for _, a := range ref.Front.Authors {
+			switch {
+			case a.Surname != "":
+				r.outs(w, "<name>")
+				r.outTagContent(w, "surname", a.Surname)
+				r.outTagContent(w, "given-names", a.Initials)
+				r.outs(w, "</name>")
+			case a.Fullname != "":
+				r.outTagContent(w, "string-name", a.Fullname)
+			case a.Organization != nil:
+				r.outTagContent(w, "collab", a.Organization.Value)
+			}
+		}
+		r.outs(w, "</person-group>")
+	}
+	r.outTagContent(w, "article-title", ref.Front.Title)
+	for _, s := range ref.Series {
+		if strings.EqualFold(s.Name, "DOI") {
+			continue
+		}
+		r.outTagContent(w, "series", strings.TrimSpace(s.Name+" "+s.Value))
+	}
+	if d := ref.Front.Date; d != nil {
+		r.outTagContent(w, "year", d.Year)
+		r.outTagContent(w, "month", d.Month)
+		r.outTagContent(w, "day", d.Day)
+	}
+	for _, s := range ref.Series {
+		if strings.EqualFold(s.Name, "DOI") {
+			r.outs(w, `<pub-id pub-id-type="doi">`+Escape(s.Value)+"</pub-id>")
+		}
+	}
+	if ref.Target != "" {
+		r.outs(w, `<ext-link ext-link-type="uri" xlink:href="`+Escape(ref.Target)+`">`+Escape(ref.Target)+"</ext-link>")
+	}
+	r.outs(w, "</element-citation></ref>\n")
+}
+
+// mixedCitation outputs a citation for a reference that isn't included in the document. For RFCs a
+// link to the RFC is added.
+func (r *Renderer) mixedCitation(w io.Writer, anchor []byte) {
+	num := strings.TrimLeft(strings.TrimPrefix(string(anchor), "RFC"), "0")
+	if !bytes.HasPrefix(anchor, []byte("RFC")) || num == "" || strings.Trim(num, "0123456789") != "" {
+		r.outTagContent(w, "mixed-citation", string(anchor))
+		return
+	}
+	target := "https://www.rfc-editor.org/info/rfc" + num
+	r.outs(w, `<mixed-citation publication-type="standard">RFC `+num+", ")
+	r.outs(w, `<ext-link ext-link-type="uri" xlink:href="`+target+`">`+target+"</ext-link>")
+	r.outs(w, "</mixed-citation>")
+}
+
+// publicationType returns the publication type of the reference, RFCs and Internet-Drafts are
+// standards, references with only a target are web pages.
+func publicationType(ref *reference.Reference) string {
+	for _, s := range ref.Series {
+		switch s.Name {
+		case "RFC", "BCP", "STD", "FYI", "Internet-Draft":
+			return "standard"
+		}
+	}
+	if ref.Target != "" && len(ref.Series) == 0 {
+		return "webpage"
+	}
+	return "other"
+}
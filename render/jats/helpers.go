@@ -0,0 +1,89 @@
+package jats
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+)
+
+func (r *Renderer) out(w io.Writer, d []byte)  { w.Write(d) }
+func (r *Renderer) outs(w io.Writer, s string) { io.WriteString(w, s) }
+func (r *Renderer) cr(w io.Writer)             { r.outs(w, "\n") }
+
+func (r *Renderer) outOneOf(w io.Writer, outFirst bool, first string, second string) {
+	if outFirst {
+		r.outs(w, first)
+	} else {
+		r.outs(w, second)
+	}
+}
+
+// outTagContent outputs <name>content</name>, when content is empty nothing is output.
+func (r *Renderer) outTagContent(w io.Writer, name, content string) {
+	if content == "" {
+		return
+	}
+	r.outs(w, "<"+name+">")
+	html.EscapeHTML(w, []byte(content))
+	r.outs(w, "</"+name+">")
+}
+
+// Escape returns s escaped for use in XML.
+func Escape(s string) string {
+	buf := &bytes.Buffer{}
+	html.EscapeHTML(buf, []byte(s))
+	return buf.String()
+}
+
+// id returns the id attribute for node, or the empty string if there is none.
+func id(node ast.Node) string {
+	if h, ok := node.(*ast.Heading); ok && h.HeadingID != "" {
+		return ` id="` + Escape(h.HeadingID) + `"`
+	}
+	if c, ok := node.(*ast.CaptionFigure); ok && c.HeadingID != "" {
+		return ` id="` + Escape(c.HeadingID) + `"`
+	}
+	var a *ast.Attribute
+	if c := node.AsContainer(); c != nil {
+		a = c.Attribute
+	}
+	if l := node.AsLeaf(); l != nil {
+		a = l.Attribute
+	}
+	if a == nil || len(a.ID) == 0 {
+		return ""
+	}
+	return ` id="` + Escape(string(a.ID)) + `"`
+}
+
+// topLevel returns true if node is a direct child of the document or of a document matter node.
+func topLevel(node ast.Node) bool {
+	switch node.GetParent().(type) {
+	case *ast.Document, *ast.DocumentMatter:
+		return true
+	}
+	return false
+}
+
+func isAbstract(heading *ast.Heading) bool {
+	return strings.EqualFold(string(heading.Literal), "abstract")
+}
+
+// footnoteID returns the id used for the footnote with the given reference.
+func footnoteID(ref []byte) string { return "fn-" + string(html.Slugify(ref)) }
+
+// cellAlign returns the value of the align attribute for a table cell.
+func cellAlign(a ast.CellAlignFlags) string {
+	switch a {
+	case ast.TableAlignmentLeft:
+		return "left"
+	case ast.TableAlignmentRight:
+		return "right"
+	case ast.TableAlignmentCenter:
+		return "center"
+	}
+	return ""
+}
@@ -0,0 +1,643 @@
+// The package jats outputs JATS (Journal Article Tag Suite) XML from mmark markdown. The title block
+// is converted to the article's front matter and the bibliography to a ref-list.
+package jats
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/v2/lang"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// Flags control optional behavior of JATS renderer.
+type Flags int
+
+// JATS renderer configuration options.
+const (
+	FlagsNone    Flags = 0
+	JATSFragment Flags = 1 << iota // Don't generate a complete document
+
+	CommonFlags Flags = FlagsNone
+)
+
+// RendererOptions is a collection of supplementary parameters tweaking
+// the behavior of various parts of JATS renderer.
+type RendererOptions struct {
+	Flags Flags // Flags allow customizing this renderer's behavior
+
+	// ArticleType is the article-type of the article, defaults to "research-article".
+	ArticleType string
+
+	Language lang.Lang // Output language for the document.
+
+	// if set, called at the start of RenderNode(). Allows replacing rendering of some nodes
+	RenderNodeHook html.RenderNodeFunc
+}
+
+// The parts of a JATS article.
+const (
+	partNone = iota
+	partFront
+	partBody
+	partBack
+)
+
+var partTags = []string{"", "front", "body", "back"}
+
+// section is an open section, the level is that of the heading that opened it.
+type section struct {
+	level int
+	tag   string
+}
+
+// Renderer implements Renderer interface for JATS output.
+type Renderer struct {
+	opts RendererOptions
+
+	Title    *mast.Title
+	current  int       // the part of the article we're in
+	meta     bool      // article-meta is open
+	appGroup bool      // app-group is open
+	sections []section // open sections
+}
+
+// NewRenderer creates and configures an Renderer object, which satisfies the Renderer interface.
+func NewRenderer(opts RendererOptions) *Renderer {
+	if opts.ArticleType == "" {
+		opts.ArticleType = "research-article"
+	}
+	return &Renderer{opts: opts}
+}
+
+// part closes the current part of the article and opens part p, if we're not already in it.
+func (r *Renderer) part(w io.Writer, p int) {
+	if r.current == p {
+		return
+	}
+	r.sectionClose(w, 1)
+	r.appGroupClose(w)
+	r.metaClose(w)
+	fragment := r.opts.Flags&JATSFragment != 0
+	if r.current != partNone && !fragment {
+		r.outs(w, "</"+partTags[r.current]+">\n")
+	}
+	r.current = p
+	if p == partNone || fragment {
+		return
+	}
+	r.outs(w, "<"+partTags[p]+">\n")
+	if p == partFront {
+		r.outs(w, "<article-meta>\n")
+		r.meta = true
+	}
+}
+
+func (r *Renderer) metaClose(w io.Writer) {
+	if !r.meta {
+		return
+	}
+	r.keywords(w, r.Title)
+	r.outs(w, "</article-meta>\n")
+	r.meta = false
+}
+
+func (r *Renderer) appGroupClose(w io.Writer) {
+	if !r.appGroup {
+		return
+	}
+	r.outs(w, "</app-group>\n")
+	r.appGroup = false
+}
+
+// sectionOpen opens a new section with tag.
+func (r *Renderer) sectionOpen(w io.Writer, level int, tag, attrs string) {
+	r.sectionClose(w, level)
+	r.outs(w, "<"+tag+attrs+">\n")
+	r.sections = append(r.sections, section{level: level, tag: tag})
+}
+
+// sectionClose closes all open sections with a level of level or deeper.
+func (r *Renderer) sectionClose(w io.Writer, level int) {
+	for len(r.sections) > 0 {
+		s := r.sections[len(r.sections)-1]
+		if s.level < level {
+			return
+		}
+		r.outs(w, "</"+s.tag+">\n")
+		r.sections = r.sections[:len(r.sections)-1]
+	}
+}
+
+func (r *Renderer) matter(w io.Writer, node *ast.DocumentMatter) {
+	switch node.Matter {
+	case ast.DocumentMatterFront:
+		r.part(w, partFront)
+	case ast.DocumentMatterMain:
+		r.part(w, partBody)
+	case ast.DocumentMatterBack:
+		r.part(w, partBack)
+	}
+}
+
+func (r *Renderer) heading(w io.Writer, node *ast.Heading, entering bool) ast.WalkStatus {
+	abstract := node.IsSpecial && isAbstract(node)
+	if !entering {
+		if !abstract || !r.meta {
+			r.outs(w, "</title>\n")
+		}
+		return ast.GoToNext
+	}
+
+	switch {
+	case abstract && r.meta:
+		r.sectionClose(w, 1)
+		r.sectionOpen(w, 1, "abstract", "")
+		return ast.SkipChildren
+	case node.IsSpecial && r.current == partFront:
+		r.metaClose(w)
+		r.sectionOpen(w, 1, "notes", id(node))
+	case r.current == partBack && node.Level == 1:
+		r.sectionClose(w, 1)
+		if !r.appGroup {
+			r.outs(w, "<app-group>\n")
+			r.appGroup = true
+		}
+		r.sectionOpen(w, 1, "app", id(node))
+	default:
+		if r.current == partNone || r.current == partFront {
+			r.part(w, partBody)
+		}
+		r.sectionOpen(w, node.Level, "sec", id(node))
+	}
+	r.outs(w, "<title>")
+	return ast.GoToNext
+}
+
+func (r *Renderer) citation(w io.Writer, node *ast.Citation, entering bool) {
+	if !entering {
+		return
+	}
+	for i, c := range node.Destination {
+		// author or contact citation, output the name.
+		if r.authorOrContact(c) {
+			html.EscapeHTML(w, c)
+			continue
+		}
+		if node.Type[i] == ast.CitationTypeSuppressed {
+			continue
+		}
+		text := string(c)
+		if len(node.Suffix) > i && len(node.Suffix[i]) > 0 {
+			text += ", " + string(node.Suffix[i])
+		}
+		r.outs(w, `<xref ref-type="bibr" rid="`+Escape(string(c))+`">[`+Escape(text)+"]</xref>")
+	}
+}
+
+func (r *Renderer) authorOrContact(name []byte) bool {
+	if r.Title == nil {
+		return false
+	}
+	for _, a := range r.Title.Author {
+		if strings.EqualFold(a.Fullname, string(name)) {
+			return true
+		}
+	}
+	for _, c := range r.Title.Contact {
+		if strings.EqualFold(c.Fullname, string(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Renderer) paragraph(w io.Writer, para *ast.Paragraph, entering bool) {
+	if p, ok := para.Parent.(*ast.ListItem); ok && p.ListFlags&ast.ListTypeTerm != 0 {
+		return
+	}
+	if figureImage(para) {
+		return
+	}
+	r.outOneOf(w, entering, "<p>", "</p>\n")
+}
+
+// figureImage returns true when node is a paragraph with only an image, in a figure.
+func figureImage(node ast.Node) bool {
+	para, ok := node.(*ast.Paragraph)
+	if !ok || len(para.Children) != 1 {
+		return false
+	}
+	if _, ok := para.Parent.(*ast.CaptionFigure); !ok {
+		return false
+	}
+	_, ok = para.Children[0].(*ast.Image)
+	return ok
+}
+
+func (r *Renderer) list(w io.Writer, list *ast.List, entering bool) {
+	if list.IsFootnotesList {
+		if entering && topLevel(list) {
+			r.part(w, partBack)
+			r.sectionClose(w, 1)
+			r.appGroupClose(w)
+		}
+		r.outOneOf(w, entering, "<fn-group>\n", "</fn-group>\n")
+		return
+	}
+	if !entering {
+		if list.ListFlags&ast.ListTypeDefinition != 0 {
+			r.outs(w, "</def-list>\n")
+			return
+		}
+		r.outs(w, "</list>\n")
+		return
+	}
+	switch {
+	case list.ListFlags&ast.ListTypeDefinition != 0:
+		r.outs(w, "<def-list"+id(list)+">\n")
+	case list.ListFlags&ast.ListTypeOrdered != 0:
+		r.outs(w, `<list`+id(list)+` list-type="order">`+"\n")
+	default:
+		r.outs(w, `<list`+id(list)+` list-type="bullet">`+"\n")
+	}
+}
+
+func (r *Renderer) listItem(w io.Writer, item *ast.ListItem, entering bool) {
+	if list, ok := item.Parent.(*ast.List); ok && list.IsFootnotesList {
+		// the item may hold the inline content without a paragraph.
+		_, block := ast.GetFirstChild(item).(*ast.Paragraph)
+		if entering {
+			r.outs(w, `<fn id="`+Escape(footnoteID(item.RefLink))+`">`)
+			if !block {
+				r.outs(w, "<p>")
+			}
+			return
+		}
+		if !block {
+			r.outs(w, "</p>")
+		}
+		r.outs(w, "</fn>\n")
+		return
+	}
+
+	// a definition item ends when the next item is not a definition.
+	endItem := func() {
+		next, ok := ast.GetNextNode(item).(*ast.ListItem)
+		if !ok || next.ListFlags&ast.ListTypeDefinition == 0 || next.ListFlags&ast.ListTypeTerm != 0 {
+			r.outs(w, "</def-item>\n")
+		}
+	}
+	switch {
+	case item.ListFlags&ast.ListTypeTerm != 0:
+		if entering {
+			r.outs(w, "<def-item><term>")
+			return
+		}
+		r.outs(w, "</term>")
+		endItem()
+	case item.ListFlags&ast.ListTypeDefinition != 0:
+		if entering {
+			r.outs(w, "<def>")
+			return
+		}
+		r.outs(w, "</def>")
+		endItem()
+	default:
+		r.outOneOf(w, entering, "<list-item>", "</list-item>\n")
+	}
+}
+
+func (r *Renderer) codeBlock(w io.Writer, codeBlock *ast.CodeBlock) {
+	info := strings.Fields(string(codeBlock.Info))
+	if len(info) > 0 {
+		r.outs(w, `<code language="`+Escape(info[0])+`">`)
+	} else {
+		r.outs(w, "<preformat>")
+	}
+	html.EscapeHTML(w, bytes.TrimSuffix(codeBlock.Literal, []byte("\n")))
+	if len(info) > 0 {
+		r.outs(w, "</code>\n")
+		return
+	}
+	r.outs(w, "</preformat>\n")
+}
+
+func (r *Renderer) table(w io.Writer, tab *ast.Table, entering bool) {
+	_, figure := tab.Parent.(*ast.CaptionFigure)
+	if !entering {
+		r.outs(w, "</table>\n")
+		if !figure {
+			r.outs(w, "</table-wrap>\n")
+		}
+		return
+	}
+	if !figure {
+		r.outs(w, "<table-wrap"+id(tab)+">\n")
+	}
+	r.outs(w, "<table>\n")
+}
+
+func (r *Renderer) tableCell(w io.Writer, cell *ast.TableCell, entering bool) {
+	tag := "td"
+	if cell.IsHeader {
+		tag = "th"
+	}
+	if !entering {
+		r.outs(w, "</"+tag+">")
+		return
+	}
+	attrs := ""
+	if a := cellAlign(cell.Align); a != "" {
+		attrs += ` align="` + a + `"`
+	}
+	if cell.ColSpan > 1 {
+		attrs += fmt.Sprintf(` colspan="%d"`, cell.ColSpan)
+	}
+	r.outs(w, "<"+tag+attrs+">")
+}
+
+func (r *Renderer) link(w io.Writer, link *ast.Link, entering bool) ast.WalkStatus {
+	if link.Footnote != nil {
+		if entering {
+			r.outs(w, fmt.Sprintf(`<xref ref-type="fn" rid="%s">%d</xref>`, Escape(footnoteID(link.Destination)), link.NoteID))
+		}
+		return ast.SkipChildren
+	}
+	if !entering {
+		r.outs(w, "</ext-link>")
+		return ast.GoToNext
+	}
+	r.outs(w, `<ext-link ext-link-type="uri" xlink:href="`+Escape(string(link.Destination))+`">`)
+	if len(link.Children) == 0 {
+		html.EscapeHTML(w, link.Destination)
+	}
+	return ast.GoToNext
+}
+
+func (r *Renderer) image(w io.Writer, image *ast.Image, entering bool) {
+	tag := "inline-graphic"
+	if figureImage(image.Parent) {
+		tag = "graphic"
+	}
+	if !entering {
+		r.outs(w, "</alt-text></"+tag+">")
+		if tag == "graphic" {
+			r.cr(w)
+		}
+		return
+	}
+	r.outs(w, "<"+tag+` xlink:href="`+Escape(string(image.Destination))+`"><alt-text>`)
+}
+
+func (r *Renderer) captionFigure(w io.Writer, figure *ast.CaptionFigure, entering bool) {
+	tag := figureTag(figure)
+	if !entering {
+		r.outs(w, "</"+tag+">\n")
+		return
+	}
+	r.outs(w, "<"+tag+id(figure)+">\n")
+	if tag == "disp-quote" {
+		return
+	}
+	// The caption comes first in JATS.
+	for _, c := range figure.Children {
+		caption, ok := c.(*ast.Caption)
+		if !ok {
+			continue
+		}
+		buf := &bytes.Buffer{}
+		ast.WalkFunc(caption, func(node ast.Node, entering bool) ast.WalkStatus {
+			if node == caption {
+				return ast.GoToNext
+			}
+			return r.RenderNode(buf, node, entering)
+		})
+		r.outs(w, "<caption><p>")
+		r.out(w, bytes.TrimSpace(buf.Bytes()))
+		r.outs(w, "</p></caption>\n")
+	}
+}
+
+// figureTag returns the element used for a figure.
+func figureTag(figure *ast.CaptionFigure) string {
+	switch ast.GetFirstChild(figure).(type) {
+	case *ast.Table:
+		return "table-wrap"
+	case *ast.BlockQuote:
+		return "disp-quote"
+	}
+	return "fig"
+}
+
+func (r *Renderer) caption(w io.Writer, caption *ast.Caption, entering bool) ast.WalkStatus {
+	figure, ok := caption.Parent.(*ast.CaptionFigure)
+	if ok && figureTag(figure) == "disp-quote" {
+		// a quote, the caption is the attribution.
+		r.outOneOf(w, entering, "<attrib>", "</attrib>\n")
+		return ast.GoToNext
+	}
+	// already output, see captionFigure.
+	return ast.SkipChildren
+}
+
+// RenderNode renders a markdown node to JATS.
+func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.WalkStatus {
+	if r.opts.RenderNodeHook != nil {
+		status, didHandle := r.opts.RenderNodeHook(w, node, entering)
+		if didHandle {
+			return status
+		}
+	}
+
+	if entering && topLevel(node) {
+		// content outside of a section before the main matter ends up in the body.
+		switch n := node.(type) {
+		case *ast.Heading, *ast.DocumentMatter, *mast.Title, *mast.Authors, *mast.ReferenceBlock, *ast.Footnotes,
+			*mast.BibliographyWrapper, *mast.Bibliography, *mast.DocumentIndex:
+		case *ast.List:
+			if !n.IsFootnotesList && (r.current == partNone || r.current == partFront && len(r.sections) == 0) {
+				r.part(w, partBody)
+			}
+		default:
+			if r.current == partNone || r.current == partFront && len(r.sections) == 0 {
+				r.part(w, partBody)
+			}
+		}
+	}
+
+	switch node := node.(type) {
+	case *ast.Document:
+		// do nothing
+	case *mast.Title:
+		r.Title = node
+		if r.opts.Flags&JATSFragment != 0 {
+			return ast.GoToNext
+		}
+		r.part(w, partFront)
+		if r.meta {
+			r.titleBlock(w, node)
+		}
+	case *mast.Authors:
+		// ignore
+	case *mast.BibliographyWrapper, *mast.Bibliography:
+		r.bibliography(w, node, entering)
+	case *mast.BibliographyItem:
+		if entering {
+			r.bibliographyItem(w, node)
+		}
+	case *mast.DocumentIndex, *mast.IndexLetter, *mast.IndexItem, *mast.IndexSubItem, *mast.IndexLink:
+		// JATS has no index
+		return ast.SkipChildren
+	case *mast.ReferenceBlock:
+		// skip, added and done by AddBibliography
+	case *ast.Footnotes:
+		// the footnote list follows
+	case *ast.Text:
+		html.EscapeHTML(w, node.Literal)
+	case *ast.Softbreak:
+		r.cr(w)
+	case *ast.Hardbreak:
+		// break is not allowed in paragraphs.
+		r.cr(w)
+	case *ast.NonBlockingSpace:
+		r.outs(w, "&#160;")
+	case *ast.Callout:
+		r.outs(w, "<italic>")
+		html.EscapeHTML(w, node.ID)
+		r.outs(w, "</italic>")
+	case *mast.Unicode:
+		html.EscapeHTML(w, []byte(node.String()))
+	case *mast.Abbreviation:
+		html.EscapeHTML(w, []byte(node.String()))
+	case *mast.Cref:
+		r.outs(w, "<italic>")
+		html.EscapeHTML(w, []byte(node.String()))
+		r.outs(w, "</italic>")
+	case *ast.Emph:
+		r.outOneOf(w, entering, "<italic>", "</italic>")
+	case *ast.Strong:
+		r.outOneOf(w, entering, "<bold>", "</bold>")
+	case *ast.Del:
+		r.outOneOf(w, entering, "<strike>", "</strike>")
+	case *ast.Citation:
+		r.citation(w, node, entering)
+	case *ast.DocumentMatter:
+		if entering {
+			r.matter(w, node)
+		}
+	case *ast.Heading:
+		return r.heading(w, node, entering)
+	case *ast.HorizontalRule:
+		// no equivalent in JATS
+	case *ast.Paragraph:
+		r.paragraph(w, node, entering)
+	case *ast.HTMLSpan:
+		if bytes.HasPrefix(node.Literal, []byte("<!--")) {
+			break
+		}
+		html.EscapeHTML(w, node.Literal)
+	case *ast.HTMLBlock:
+		// skip
+	case *ast.List:
+		r.list(w, node, entering)
+	case *ast.ListItem:
+		r.listItem(w, node, entering)
+	case *ast.CodeBlock:
+		r.codeBlock(w, node)
+	case *ast.Caption:
+		return r.caption(w, node, entering)
+	case *ast.CaptionFigure:
+		r.captionFigure(w, node, entering)
+	case *ast.Table:
+		r.table(w, node, entering)
+	case *ast.TableCell:
+		r.tableCell(w, node, entering)
+	case *ast.TableHeader:
+		r.outOneOf(w, entering, "<thead>\n", "</thead>\n")
+	case *ast.TableBody:
+		r.outOneOf(w, entering, "<tbody>\n", "</tbody>\n")
+	case *ast.TableFooter:
+		r.outOneOf(w, entering, "<tfoot>\n", "</tfoot>\n")
+	case *ast.TableRow:
+		r.outOneOf(w, entering, "<tr>", "</tr>\n")
+	case *ast.BlockQuote:
+		if figure, ok := node.Parent.(*ast.CaptionFigure); ok && figureTag(figure) == "disp-quote" {
+			break
+		}
+		r.outOneOf(w, entering, "<disp-quote"+id(node)+">\n", "</disp-quote>\n")
+	case *ast.Aside:
+		r.outOneOf(w, entering, "<boxed-text"+id(node)+">\n", "</boxed-text>\n")
+	case *mast.Div:
+		// only the contents, JATS has no generic container.
+	case *ast.CrossReference:
+		if !entering {
+			r.outs(w, "</xref>")
+			break
+		}
+		r.outs(w, `<xref rid="`+Escape(string(node.Destination))+`">`)
+		if len(node.Children) == 0 {
+			html.EscapeHTML(w, node.Destination)
+		}
+	case *ast.Index:
+		// JATS has no index
+	case *ast.Link:
+		return r.link(w, node, entering)
+	case *ast.Math:
+		r.outs(w, "<inline-formula><tex-math>")
+		html.EscapeHTML(w, node.Literal)
+		r.outs(w, "</tex-math></inline-formula>")
+	case *ast.Image:
+		r.image(w, node, entering)
+	case *ast.Code:
+		r.outs(w, "<monospace>")
+		html.EscapeHTML(w, node.Literal)
+		r.outs(w, "</monospace>")
+	case *ast.MathBlock:
+		if entering {
+			r.outs(w, "<disp-formula><tex-math>")
+			html.EscapeHTML(w, bytes.TrimSpace(node.Literal))
+			r.outs(w, "</tex-math></disp-formula>\n")
+		}
+	case *ast.Subscript:
+		r.outs(w, "<sub>")
+		html.EscapeHTML(w, node.Literal)
+		r.outs(w, "</sub>")
+	case *ast.Superscript:
+		r.outs(w, "<sup>")
+		html.EscapeHTML(w, node.Literal)
+		r.outs(w, "</sup>")
+	default:
+		panic(fmt.Sprintf("Unknown node %T", node))
+	}
+	return ast.GoToNext
+}
+
+// RenderHeader writes the XML declaration and opens the article.
+func (r *Renderer) RenderHeader(w io.Writer, _ ast.Node) {
+	if r.opts.Flags&JATSFragment != 0 {
+		return
+	}
+	language := r.opts.Language.String()
+	if language == "" {
+		language = "en"
+	}
+	r.outs(w, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	r.outs(w, `<!-- name="GENERATOR" content="github.com/mmarkdown/mmark Mmark Markdown Processor - mmark.miek.nl" -->`+"\n")
+	r.outs(w, `<!DOCTYPE article PUBLIC "-//NLM//DTD JATS (Z39.96) Journal Archiving and Interchange DTD v1.3 20210610//EN" "JATS-archivearticle1-3.dtd">`+"\n")
+	r.outs(w, `<article xmlns:xlink="http://www.w3.org/1999/xlink" xmlns:mml="http://www.w3.org/1998/Math/MathML"`)
+	r.outs(w, ` article-type="`+Escape(r.opts.ArticleType)+`" dtd-version="1.3" xml:lang="`+Escape(language)+`">`+"\n")
+}
+
+// RenderFooter closes all open elements and the article.
+func (r *Renderer) RenderFooter(w io.Writer, _ ast.Node) {
+	r.part(w, partNone)
+	if r.opts.Flags&JATSFragment != 0 {
+		return
+	}
+	r.outs(w, "</article>\n")
+}
@@ -0,0 +1,131 @@
+package jats
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// titleBlock outputs the article-meta from the title block, the article-meta element is left open
+// so that an abstract can be added.
+func (r *Renderer) titleBlock(w io.Writer, t *mast.Title) {
+	d := t.TitleData
+	if d == nil {
+		return
+	}
+
+	r.outs(w, "<title-group>\n")
+	r.outTagContent(w, "article-title", d.Title)
+	r.cr(w)
+	if d.Abbrev != "" {
+		r.outs(w, `<alt-title alt-title-type="running-head">`+Escape(d.Abbrev)+"</alt-title>\n")
+	}
+	r.outs(w, "</title-group>\n")
+
+	affs := []string{} // affiliations in order
+	affIDs := map[string]string{}
+	if len(d.Author) > 0 {
+		r.outs(w, "<contrib-group>\n")
+	}
+	for _, a := range d.Author {
+		r.outs(w, `<contrib contrib-type="author">`)
+		r.contribName(w, a)
+		r.outTagContent(w, "email", a.Address.Email)
+		for _, e := range a.Address.Emails {
+			r.outTagContent(w, "email", e)
+		}
+		if a.Address.URI != "" {
+			r.outs(w, `<uri xlink:href="`+Escape(a.Address.URI)+`">`+Escape(a.Address.URI)+"</uri>")
+		}
+		if aff := affiliation(a); aff != "" {
+			rid, ok := affIDs[aff]
+			if !ok {
+				rid = fmt.Sprintf("aff%d", len(affs)+1)
+				affIDs[aff] = rid
+				affs = append(affs, aff)
+			}
+			r.outs(w, `<xref ref-type="aff" rid="`+rid+`"/>`)
+		}
+		r.outs(w, "</contrib>\n")
+	}
+	if len(d.Author) > 0 {
+		r.outs(w, "</contrib-group>\n")
+	}
+	for _, aff := range affs {
+		r.outs(w, `<aff id="`+affIDs[aff]+`">`+aff+"</aff>\n")
+	}
+
+	if !d.Date.IsZero() {
+		r.outs(w, `<pub-date publication-format="electronic" date-type="pub">`)
+		r.outs(w, fmt.Sprintf("<day>%02d</day><month>%02d</month><year>%d</year>", d.Date.Day(), d.Date.Month(), d.Date.Year()))
+		r.outs(w, "</pub-date>\n")
+	}
+}
+
+// keywords outputs the keywords from the title block, this must be done after the abstract.
+func (r *Renderer) keywords(w io.Writer, t *mast.Title) {
+	if t == nil || t.TitleData == nil {
+		return
+	}
+	keywords := []string{}
+	for _, k := range t.Keyword {
+		if k = strings.TrimSpace(k); k != "" {
+			keywords = append(keywords, k)
+		}
+	}
+	if len(keywords) == 0 {
+		return
+	}
+	r.outs(w, `<kwd-group kwd-group-type="author">`)
+	for _, k := range keywords {
+		r.outTagContent(w, "kwd", k)
+	}
+	r.outs(w, "</kwd-group>\n")
+}
+
+// contribName outputs the name of an author, as a structured name if the surname is known.
+func (r *Renderer) contribName(w io.Writer, a mast.Author) {
+	if a.Surname == "" {
+		r.outTagContent(w, "string-name", a.Fullname)
+		return
+	}
+	given := a.Initials
+	if a.Fullname != "" {
+		given = strings.TrimSpace(strings.TrimSuffix(a.Fullname, a.Surname))
+	}
+	r.outs(w, "<name>")
+	r.outTagContent(w, "surname", a.Surname)
+	r.outTagContent(w, "given-names", given)
+	r.outs(w, "</name>")
+}
+
+// affiliation returns the (escaped) content of the aff element for an author.
+func affiliation(a mast.Author) string {
+	s := &strings.Builder{}
+	tag := func(name, content string) {
+		if content != "" {
+			s.WriteString("<" + name + ">" + Escape(content) + "</" + name + ">")
+		}
+	}
+	p := a.Address.Postal
+	tag("institution", a.Organization)
+	lines := append([]string{}, p.PostalLine...)
+	lines = append(lines, p.Street)
+	lines = append(lines, p.Streets...)
+	for _, l := range lines {
+		tag("addr-line", l)
+	}
+	tag("city", p.City)
+	for _, c := range p.Cities {
+		tag("city", c)
+	}
+	tag("state", p.Region)
+	tag("postal-code", p.Code)
+	tag("country", p.Country)
+	for _, c := range p.Countries {
+		tag("country", c)
+	}
+	return s.String()
+}
@@ -0,0 +1,145 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// types maps the type of a Node to the type of its AST node.
+var types = map[string]reflect.Type{}
+
+func init() {
+	for _, node := range []ast.Node{
+		&ast.Document{}, &ast.DocumentMatter{}, &ast.BlockQuote{}, &ast.Aside{}, &ast.List{}, &ast.ListItem{},
+		&ast.Paragraph{}, &ast.Math{}, &ast.MathBlock{}, &ast.Heading{}, &ast.HorizontalRule{}, &ast.Emph{},
+		&ast.Strong{}, &ast.Del{}, &ast.Link{}, &ast.CrossReference{}, &ast.Citation{}, &ast.Image{}, &ast.Text{},
+		&ast.HTMLBlock{}, &ast.CodeBlock{}, &ast.Softbreak{}, &ast.Hardbreak{}, &ast.NonBlockingSpace{},
+		&ast.Code{}, &ast.HTMLSpan{}, &ast.Table{}, &ast.TableCell{}, &ast.TableHeader{}, &ast.TableBody{},
+		&ast.TableRow{}, &ast.TableFooter{}, &ast.Caption{}, &ast.CaptionFigure{}, &ast.Callout{}, &ast.Index{},
+		&ast.Subscript{}, &ast.Superscript{}, &ast.Footnotes{},
+
+		&mast.Abbreviation{}, &mast.Authors{}, &mast.BibliographyWrapper{}, &mast.Bibliography{},
+		&mast.BibliographyItem{}, &mast.Cref{}, &mast.Div{}, &mast.DocumentIndex{}, &mast.IndexItem{},
+		&mast.IndexSubItem{}, &mast.IndexLetter{}, &mast.IndexLink{}, &mast.ReferenceBlock{}, &mast.Title{},
+		&mast.Unicode{},
+	} {
+		t := reflect.TypeOf(node).Elem()
+		types[strings.TrimPrefix(fmt.Sprintf("%T", node), "*")] = t
+	}
+}
+
+// Parse parses the JSON of a document, as written by the Renderer, back into an AST. Fields that refer
+// to other nodes, i.e. the footnote of a link, aren't in the JSON and are left empty.
+func Parse(data []byte) (ast.Node, error) {
+	root := &Node{}
+	if err := json.Unmarshal(data, root); err != nil {
+		return nil, err
+	}
+	return root.AST()
+}
+
+// AST converts n and its children back into AST nodes.
+func (n *Node) AST() (ast.Node, error) {
+	t, ok := types[n.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown node type %q", n.Type)
+	}
+	v := reflect.New(t)
+	node := v.Interface().(ast.Node)
+	for name, value := range n.Fields {
+		if err := setField(v.Elem(), name, value); err != nil {
+			return nil, fmt.Errorf("%s: %s: %s", n.Type, name, err)
+		}
+	}
+
+	var a *ast.Attribute
+	if n.Attribute != nil {
+		a = &ast.Attribute{ID: literal(n.Attribute.ID)}
+		for _, c := range n.Attribute.Classes {
+			a.Classes = append(a.Classes, []byte(c))
+		}
+		if len(n.Attribute.Attrs) > 0 {
+			a.Attrs = map[string][]byte{}
+			for k, v := range n.Attribute.Attrs {
+				a.Attrs[k] = []byte(v)
+			}
+		}
+	}
+	if c := node.AsContainer(); c != nil {
+		c.Literal, c.Attribute = literal(n.Literal), a
+	}
+	if l := node.AsLeaf(); l != nil {
+		l.Literal, l.Attribute = literal(n.Literal), a
+	}
+
+	for _, c := range n.Children {
+		child, err := c.AST()
+		if err != nil {
+			return nil, err
+		}
+		ast.AppendChild(node, child)
+	}
+	return node, nil
+}
+
+func literal(s string) []byte {
+	if s == "" {
+		return nil
+	}
+	return []byte(s)
+}
+
+// setField sets the field name of the struct v to value, embedded structs that are nil are allocated.
+// This is the reverse of value: byte slices are strings in the JSON.
+func setField(v reflect.Value, name string, value interface{}) error {
+	f, ok := v.Type().FieldByName(name)
+	if !ok {
+		return fmt.Errorf("unknown field")
+	}
+	fv := v
+	for _, i := range f.Index {
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+			fv = fv.Elem()
+		}
+		fv = fv.Field(i)
+	}
+
+	switch {
+	case fv.Type() == bytesType:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected a string")
+		}
+		fv.SetBytes([]byte(s))
+		return nil
+	case fv.Kind() == reflect.Slice && fv.Type().Elem() == bytesType:
+		var s []string
+		if err := remarshal(value, &s); err != nil {
+			return err
+		}
+		b := make([][]byte, len(s))
+		for i := range s {
+			b[i] = []byte(s[i])
+		}
+		fv.Set(reflect.ValueOf(b))
+		return nil
+	}
+	return remarshal(value, fv.Addr().Interface())
+}
+
+// remarshal sets v to value, a decoded JSON value, by encoding it again.
+func remarshal(value, v interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,225 @@
+// The package json outputs the mmark AST as JSON, so external tools can inspect and transform
+// documents without linking Go code.
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+// Flags control optional behavior of the JSON renderer.
+type Flags int
+
+// JSON renderer configuration options.
+const (
+	FlagsNone  Flags = 0
+	JSONIndent Flags = 1 << iota // Indent the JSON output.
+
+	CommonFlags Flags = JSONIndent
+)
+
+// RendererOptions is a collection of supplementary parameters tweaking
+// the behavior of various parts of the JSON renderer.
+type RendererOptions struct {
+	Flags Flags // Flags allow customizing this renderer's behavior
+
+	// Source is the markdown that was parsed, if set it is used to find the source positions of the nodes.
+	Source []byte
+}
+
+// Renderer implements Renderer interface for JSON output.
+type Renderer struct {
+	opts   RendererOptions
+	cursor int // offset in source where we continue searching for positions
+}
+
+// Node is the JSON representation of an AST node.
+type Node struct {
+	Type      string                 `json:"type"`
+	Position  *Position              `json:"position,omitempty"`
+	Literal   string                 `json:"literal,omitempty"`
+	Attribute *Attribute             `json:"attribute,omitempty"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+	Children  []*Node                `json:"children,omitempty"`
+}
+
+// Position is the position of a node in the source, lines and columns start at 1. Positions are found by
+// searching for the text of a node in the source, they are absent for nodes that come from includes.
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Offset int `json:"offset"`
+}
+
+// Attribute is the inline attribute list of a node.
+type Attribute struct {
+	ID      string            `json:"id,omitempty"`
+	Classes []string          `json:"classes,omitempty"`
+	Attrs   map[string]string `json:"attrs,omitempty"`
+}
+
+// NewRenderer creates and configures an Renderer object, which satisfies the Renderer interface.
+func NewRenderer(opts RendererOptions) *Renderer { return &Renderer{opts: opts} }
+
+// RenderNode does nothing, the entire tree is converted in RenderFooter.
+func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.WalkStatus {
+	return ast.Terminate
+}
+
+// RenderHeader does nothing.
+func (r *Renderer) RenderHeader(w io.Writer, _ ast.Node) {}
+
+// RenderFooter writes the JSON for doc to w.
+func (r *Renderer) RenderFooter(w io.Writer, doc ast.Node) {
+	r.cursor = 0
+	var (
+		buf []byte
+		err error
+	)
+	if r.opts.Flags&JSONIndent != 0 {
+		buf, err = json.MarshalIndent(r.Convert(doc), "", "  ")
+	} else {
+		buf, err = json.Marshal(r.Convert(doc))
+	}
+	if err != nil {
+		fmt.Fprintf(w, `{"error": %q}`, err.Error())
+		return
+	}
+	w.Write(buf)
+}
+
+// Convert converts node and its children to a Node.
+func (r *Renderer) Convert(node ast.Node) *Node {
+	n := &Node{Type: strings.TrimPrefix(fmt.Sprintf("%T", node), "*"), Fields: fields(node)}
+	var a *ast.Attribute
+	if c := node.AsContainer(); c != nil {
+		n.Literal = string(c.Literal)
+		a = c.Attribute
+	}
+	if l := node.AsLeaf(); l != nil {
+		n.Literal = string(l.Literal)
+		a = l.Attribute
+	}
+	if a != nil {
+		n.Attribute = &Attribute{ID: string(a.ID)}
+		for _, c := range a.Classes {
+			n.Attribute.Classes = append(n.Attribute.Classes, string(c))
+		}
+		if len(a.Attrs) > 0 {
+			n.Attribute.Attrs = map[string]string{}
+			for k, v := range a.Attrs {
+				n.Attribute.Attrs[k] = string(v)
+			}
+		}
+	}
+
+	n.Position = r.position(n.Literal)
+	for _, c := range node.GetChildren() {
+		child := r.Convert(c)
+		if n.Position == nil {
+			n.Position = child.Position
+		}
+		n.Children = append(n.Children, child)
+	}
+	return n
+}
+
+// position searches for literal in the source, from the current cursor onwards.
+func (r *Renderer) position(literal string) *Position {
+	if r.opts.Source == nil || strings.TrimSpace(literal) == "" {
+		return nil
+	}
+	// Only the first line is searched for, code blocks may have their indentation removed.
+	needle := strings.TrimSpace(strings.SplitN(literal, "\n", 2)[0])
+	if needle == "" {
+		return nil
+	}
+	i := bytes.Index(r.opts.Source[r.cursor:], []byte(needle))
+	if i < 0 {
+		return nil
+	}
+	offset := r.cursor + i
+	r.cursor = offset + len(needle)
+
+	line := bytes.Count(r.opts.Source[:offset], []byte("\n")) + 1
+	column := offset - (bytes.LastIndexByte(r.opts.Source[:offset], '\n') + 1) + 1
+	return &Position{Line: line, Column: column, Offset: offset}
+}
+
+var (
+	nodeType      = reflect.TypeOf((*ast.Node)(nil)).Elem()
+	containerType = reflect.TypeOf(ast.Container{})
+	leafType      = reflect.TypeOf(ast.Leaf{})
+	bytesType     = reflect.TypeOf([]byte{})
+)
+
+// fields returns the exported fields of the node that have a non zero value. Fields that refer to
+// other nodes are skipped.
+func fields(node ast.Node) map[string]interface{} {
+	v := reflect.ValueOf(node)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	m := map[string]interface{}{}
+	addFields(m, v)
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+func addFields(m map[string]interface{}, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		fv := v.Field(i)
+		if f.Anonymous {
+			if f.Type == containerType || f.Type == leafType {
+				continue
+			}
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				addFields(m, fv)
+				continue
+			}
+		}
+		if f.Type.Implements(nodeType) || (f.Type.Kind() == reflect.Slice && f.Type.Elem().Implements(nodeType)) {
+			continue
+		}
+		if fv.IsZero() {
+			continue
+		}
+		m[f.Name] = value(fv)
+	}
+}
+
+func value(v reflect.Value) interface{} {
+	switch {
+	case v.Type() == bytesType:
+		return string(v.Bytes())
+	case v.Kind() == reflect.Slice && v.Type().Elem() == bytesType:
+		s := make([]string, v.Len())
+		for i := range s {
+			s[i] = string(v.Index(i).Bytes())
+		}
+		return s
+	}
+	return v.Interface()
+}
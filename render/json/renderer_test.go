@@ -0,0 +1,60 @@
+package json
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/mparser"
+)
+
+func TestJSON(t *testing.T) {
+	source := []byte("# Introduction {#intro}\n\nSome *emphasized* text.\n")
+	p := parser.NewWithExtensions(mparser.Extensions)
+	p.Opts = parser.Options{ParserHook: mparser.Hook}
+	doc := markdown.Parse(source, p)
+	data := markdown.Render(doc, NewRenderer(RendererOptions{Source: source}))
+
+	root := &Node{}
+	if err := json.Unmarshal(data, root); err != nil {
+		t.Fatalf("invalid JSON: %s", err)
+	}
+	if root.Type != "ast.Document" || len(root.Children) != 2 {
+		t.Fatalf("expected document with 2 children, got %s with %d", root.Type, len(root.Children))
+	}
+	heading := root.Children[0]
+	if heading.Type != "ast.Heading" || heading.Fields["HeadingID"] != "intro" {
+		t.Errorf("expected heading with ID intro, got %s %v", heading.Type, heading.Fields)
+	}
+	para := root.Children[1]
+	if para.Position == nil || para.Position.Line != 3 || para.Position.Column != 1 {
+		t.Errorf("expected paragraph at line 3, column 1, got %+v", para.Position)
+	}
+	emph := para.Children[1]
+	if emph.Type != "ast.Emph" || emph.Position == nil || emph.Position.Column != 7 {
+		t.Errorf("expected emphasis at column 7, got %s at %+v", emph.Type, emph.Position)
+	}
+}
+
+func TestParse(t *testing.T) {
+	source := []byte("%%%\ntitle = \"Round Trip\"\n%%%\n\n# Introduction {#intro}\n\n" +
+		"Some *emphasized* text, with a [link](https://example.org) and `code` [@RFC2119].\n\n" +
+		"1. One\n2. Two\n\n| a | b |\n|---|---|\n| 1 | 2 |\n")
+	p := parser.NewWithExtensions(mparser.Extensions)
+	p.Opts = parser.Options{ParserHook: mparser.TitleHook}
+	doc := markdown.Parse(source, p)
+	data := markdown.Render(doc, NewRenderer(RendererOptions{}))
+
+	parsed, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again := markdown.Render(parsed, NewRenderer(RendererOptions{})); string(again) != string(data) {
+		t.Errorf("expected the same JSON after parsing, got %s", again)
+	}
+
+	if _, err := Parse([]byte(`{"type": "ast.Nope"}`)); err == nil {
+		t.Errorf("expected an error for an unknown node type")
+	}
+}
@@ -0,0 +1,91 @@
+package latex
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// BibTeX walks doc and writes a BibTeX entry for each bibliography item found to w. If a bibliography
+// item does not have a parsed reference a @misc entry is created for well known anchors (RFCs and I-Ds).
+// It returns the number of entries written.
+func BibTeX(w io.Writer, doc ast.Node) int {
+	n := 0
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		bib, ok := node.(*mast.BibliographyItem)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		bibTeXItem(w, bib)
+		n++
+		return ast.GoToNext
+	})
+	return n
+}
+
+func bibTeXItem(w io.Writer, bib *mast.BibliographyItem) {
+	fields := [][2]string{}
+	add := func(key, value string) {
+		if value == "" {
+			return
+		}
+		fields = append(fields, [2]string{key, value})
+	}
+
+	if ref := bib.Reference; ref != nil {
+		authors := []string{}
+		for _, a := range ref.Front.Authors {
+			switch {
+			case a.Fullname != "":
+				authors = append(authors, EscapeString(a.Fullname))
+			case a.Organization != nil && a.Organization.Value != "":
+				authors = append(authors, "{"+EscapeString(a.Organization.Value)+"}")
+			}
+		}
+		add("author", strings.Join(authors, " and "))
+		add("title", "{"+EscapeString(ref.Front.Title)+"}")
+		if ref.Front.Date != nil {
+			add("year", ref.Front.Date.Year)
+			add("month", ref.Front.Date.Month)
+		}
+		for _, s := range ref.Series {
+			add("series", EscapeString(s.Name+" "+s.Value))
+			break
+		}
+		if ref.Target != "" {
+			add("howpublished", `\url{`+ref.Target+`}`)
+		}
+	} else {
+		anchor := string(bib.Anchor)
+		switch {
+		case strings.HasPrefix(anchor, "RFC"):
+			add("title", "{"+anchor+"}")
+			add("howpublished", fmt.Sprintf(`\url{https://www.rfc-editor.org/rfc/rfc%s}`, anchor[3:]))
+		case strings.HasPrefix(anchor, "I-D."):
+			draft := anchor[4:]
+			if hash := strings.Index(draft, "#"); hash > 0 {
+				draft = draft[:hash]
+			}
+			add("title", "{Internet-Draft draft-"+EscapeString(draft)+"}")
+			add("howpublished", `\url{https://datatracker.ietf.org/doc/draft-`+draft+`}`)
+		default:
+			add("title", "{"+EscapeString(anchor)+"}")
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "@misc{%s,\n", label(bib.Anchor))
+	for i, f := range fields {
+		fmt.Fprintf(buf, "  %s = {%s}", f[0], f[1])
+		if i < len(fields)-1 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("}\n")
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,140 @@
+package latex
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+func (r *Renderer) out(w io.Writer, d []byte)  { w.Write(d) }
+func (r *Renderer) outs(w io.Writer, s string) { io.WriteString(w, s) }
+func (r *Renderer) cr(w io.Writer)             { r.outs(w, "\n") }
+
+func (r *Renderer) outOneOf(w io.Writer, outFirst bool, first string, second string) {
+	if outFirst {
+		r.outs(w, first)
+	} else {
+		r.outs(w, second)
+	}
+}
+
+// escaper maps the LaTeX special characters to their escaped form.
+var escaper = map[byte]string{
+	'\\': `\textbackslash{}`,
+	'{':  `\{`,
+	'}':  `\}`,
+	'$':  `\$`,
+	'&':  `\&`,
+	'#':  `\#`,
+	'^':  `\textasciicircum{}`,
+	'_':  `\_`,
+	'%':  `\%`,
+	'~':  `\textasciitilde{}`,
+	'<':  `\textless{}`,
+	'>':  `\textgreater{}`,
+}
+
+// unbreakable maps the non-breaking space and hyphen to LaTeX.
+var unbreakable = map[string]string{
+	"\u00a0": "~",
+	"\u2011": `\mbox{-}`,
+}
+
+// Escape writes text to w with all LaTeX special characters escaped.
+func Escape(w io.Writer, text []byte) {
+	start := 0
+	for i := 0; i < len(text); i++ {
+		esc, ok := escaper[text[i]]
+		n := 1
+		if !ok && text[i] >= 0xc2 {
+			for u, e := range unbreakable {
+				if bytes.HasPrefix(text[i:], []byte(u)) {
+					esc, ok, n = e, true, len(u)
+				}
+			}
+		}
+		if !ok {
+			continue
+		}
+		w.Write(text[start:i])
+		io.WriteString(w, esc)
+		start = i + n
+		i += n - 1
+	}
+	w.Write(text[start:])
+}
+
+// EscapeString returns s with all LaTeX special characters escaped.
+func EscapeString(s string) string {
+	buf := &strings.Builder{}
+	Escape(buf, []byte(s))
+	return buf.String()
+}
+
+// label returns a string that is safe to use in \label and \ref.
+func label(s []byte) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '\\', '{', '}', '#', '%', '~', '^', '$', '&', ',':
+			return '-'
+		}
+		return r
+	}, string(s))
+}
+
+// listings holds the languages the listings package knows about, keyed by the (lowercased) info
+// string of the code block. Using an unknown language makes LaTeX fail.
+var listings = map[string]string{
+	"c":      "C",
+	"c++":    "C++",
+	"cpp":    "C++",
+	"go":     "Go",
+	"golang": "Go",
+	"java":   "Java",
+	"python": "Python",
+	"py":     "Python",
+	"sh":     "sh",
+	"bash":   "bash",
+	"shell":  "bash",
+	"xml":    "XML",
+	"html":   "HTML",
+	"sql":    "SQL",
+	"perl":   "Perl",
+	"ruby":   "Ruby",
+	"tex":    "TeX",
+	"latex":  "TeX",
+	"make":   "make",
+}
+
+// cellAlign returns the column specification for a table cell.
+func cellAlign(a ast.CellAlignFlags) string {
+	switch a {
+	case ast.TableAlignmentRight:
+		return "r"
+	case ast.TableAlignmentCenter:
+		return "c"
+	}
+	return "l"
+}
+
+// columns returns the column specification of the table, this is derived from the first row.
+func columns(tab *ast.Table) string {
+	spec := ""
+	ast.WalkFunc(tab, func(node ast.Node, entering bool) ast.WalkStatus {
+		row, ok := node.(*ast.TableRow)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		for _, c := range row.GetChildren() {
+			cell := c.(*ast.TableCell)
+			spec += cellAlign(cell.Align) + "|"
+			for i := 1; i < cell.ColSpan; i++ {
+				spec += cellAlign(cell.Align) + "|"
+			}
+		}
+		return ast.Terminate
+	})
+	return "|" + spec
+}
@@ -0,0 +1,573 @@
+// The package latex outputs LaTeX from mmark markdown.
+package latex
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/v2/lang"
+	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/render/xml"
+)
+
+// Flags control optional behavior of LaTeX renderer.
+type Flags int
+
+// LaTeX renderer configuration options.
+const (
+	FlagsNone     Flags = 0
+	LatexFragment Flags = 1 << iota // Don't generate a complete document
+
+	CommonFlags Flags = FlagsNone
+)
+
+// RendererOptions is a collection of supplementary parameters tweaking
+// the behavior of various parts of LaTeX renderer.
+type RendererOptions struct {
+	Flags Flags // Flags allow customizing this renderer's behavior
+
+	// DocumentClass is the class used in \documentclass, defaults to "article".
+	DocumentClass string
+
+	Language lang.Lang // Output language for the document.
+
+	// if set, called at the start of RenderNode(). Allows replacing rendering of some nodes
+	RenderNodeHook html.RenderNodeFunc
+}
+
+// Renderer implements Renderer interface for LaTeX output.
+type Renderer struct {
+	opts RendererOptions
+
+	Title    *mast.Title
+	abstract bool // we are in an abstract environment
+	bibDone  bool // did we output \bibliography already
+}
+
+// NewRenderer creates and configures an Renderer object, which satisfies the Renderer interface.
+func NewRenderer(opts RendererOptions) *Renderer {
+	if opts.DocumentClass == "" {
+		opts.DocumentClass = "article"
+	}
+	return &Renderer{opts: opts}
+}
+
+// abstractClose closes the abstract environment if it is open.
+func (r *Renderer) abstractClose(w io.Writer) {
+	if !r.abstract {
+		return
+	}
+	r.outs(w, "\\end{abstract}\n\n")
+	r.abstract = false
+}
+
+func (r *Renderer) matter(w io.Writer, node *ast.DocumentMatter, entering bool) {
+	if !entering {
+		return
+	}
+	r.abstractClose(w)
+	if node.Matter == ast.DocumentMatterBack {
+		r.outs(w, "\\appendix\n\n")
+	}
+}
+
+func (r *Renderer) heading(w io.Writer, node *ast.Heading, entering bool) {
+	if node.IsSpecial && isAbstract(node) {
+		if entering {
+			r.abstractClose(w)
+			r.outs(w, "\\begin{abstract}\n")
+			r.abstract = true
+		}
+		return
+	}
+
+	if !entering {
+		r.outs(w, "}")
+		if node.HeadingID != "" && !node.IsSpecial {
+			r.outs(w, `\label{`+label([]byte(node.HeadingID))+"}")
+		}
+		r.outs(w, "\n\n")
+		return
+	}
+
+	r.abstractClose(w)
+	cmd := ""
+	switch node.Level {
+	case 1:
+		cmd = `\section`
+	case 2:
+		cmd = `\subsection`
+	case 3:
+		cmd = `\subsubsection`
+	case 4:
+		cmd = `\paragraph`
+	default:
+		cmd = `\subparagraph`
+	}
+	if node.IsSpecial {
+		cmd += "*"
+	}
+	r.outs(w, cmd+"{")
+}
+
+func (r *Renderer) citation(w io.Writer, node *ast.Citation, entering bool) {
+	if !entering {
+		return
+	}
+	for i, c := range node.Destination {
+		// author or contact citation, output the name.
+		if r.authorOrContact(c) {
+			Escape(w, c)
+			continue
+		}
+		cmd := `\cite`
+		if node.Type[i] == ast.CitationTypeSuppressed {
+			cmd = `\nocite`
+		}
+		r.outs(w, cmd)
+		if len(node.Suffix) > i && len(node.Suffix[i]) > 0 && cmd == `\cite` {
+			suffix := node.Suffix[i]
+			r.outs(w, "[")
+			if bytes.Contains(suffix, []byte("]")) {
+				// the braces hide the ] from the optional argument.
+				r.outs(w, "{")
+				Escape(w, suffix)
+				r.outs(w, "}")
+			} else {
+				Escape(w, suffix)
+			}
+			r.outs(w, "]")
+		}
+		r.outs(w, "{"+label(c)+"}")
+	}
+}
+
+func (r *Renderer) authorOrContact(name []byte) bool {
+	if r.Title == nil {
+		return false
+	}
+	for _, a := range r.Title.Author {
+		if strings.EqualFold(a.Fullname, string(name)) {
+			return true
+		}
+	}
+	for _, c := range r.Title.Contact {
+		if strings.EqualFold(c.Fullname, string(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Renderer) paragraph(w io.Writer, para *ast.Paragraph, entering bool) {
+	if entering {
+		return
+	}
+	if p, ok := para.Parent.(*ast.ListItem); ok {
+		if p.ListFlags&ast.ListTypeTerm != 0 {
+			return
+		}
+	}
+	if _, ok := para.Parent.(*ast.CaptionFigure); ok {
+		r.cr(w)
+		return
+	}
+	r.outs(w, "\n\n")
+}
+
+func (r *Renderer) list(w io.Writer, list *ast.List, entering bool) {
+	env := "itemize"
+	switch {
+	case list.ListFlags&ast.ListTypeOrdered != 0:
+		env = "enumerate"
+	case list.ListFlags&ast.ListTypeDefinition != 0:
+		env = "description"
+	}
+	if !entering {
+		r.outs(w, `\end{`+env+"}\n\n")
+		return
+	}
+	r.outs(w, `\begin{`+env+"}\n")
+	if env == "enumerate" && list.Start > 1 {
+		// The enumi counter is only valid for the outer most list, good enough.
+		r.outs(w, fmt.Sprintf("\\setcounter{enumi}{%d}\n", list.Start-1))
+	}
+}
+
+func (r *Renderer) listItem(w io.Writer, listItem *ast.ListItem, entering bool) {
+	switch {
+	case listItem.ListFlags&ast.ListTypeTerm != 0:
+		r.outOneOf(w, entering, `\item[`, "]\n")
+	case listItem.ListFlags&ast.ListTypeDefinition != 0:
+		// the definition follows the term.
+	default:
+		if entering {
+			r.outs(w, `\item `)
+		}
+	}
+}
+
+func (r *Renderer) codeBlock(w io.Writer, codeBlock *ast.CodeBlock) {
+	r.outs(w, `\begin{lstlisting}`)
+	if len(codeBlock.Info) > 0 {
+		info := string(codeBlock.Info)
+		if i := strings.IndexAny(info, "\t "); i > 0 {
+			info = info[:i]
+		}
+		if lang, ok := listings[strings.ToLower(info)]; ok {
+			r.outs(w, "[language="+lang+"]")
+		}
+	}
+	r.cr(w)
+	r.out(w, codeBlock.Literal)
+	if !bytes.HasSuffix(codeBlock.Literal, []byte("\n")) {
+		r.cr(w)
+	}
+	r.outs(w, "\\end{lstlisting}\n\n")
+}
+
+func (r *Renderer) hardBreak(w io.Writer, node ast.Node) {
+	// \\ ends the row in a table.
+	if mast.InTableCell(node) {
+		r.outs(w, " ")
+		return
+	}
+	r.outs(w, "\\\\\n")
+}
+
+func (r *Renderer) table(w io.Writer, tab *ast.Table, entering bool) {
+	if !entering {
+		r.outs(w, "\\end{tabular}\n")
+		if _, ok := tab.Parent.(*ast.CaptionFigure); !ok {
+			r.cr(w)
+		}
+		return
+	}
+	r.outs(w, `\begin{tabular}{`+columns(tab)+"}\n\\hline\n")
+}
+
+func (r *Renderer) tableCell(w io.Writer, cell *ast.TableCell, entering bool) {
+	if !entering {
+		if cell.IsHeader {
+			r.outs(w, "}")
+		}
+		if cell.ColSpan > 1 {
+			r.outs(w, "}")
+		}
+		return
+	}
+	if ast.GetPrevNode(cell) != nil {
+		r.outs(w, " & ")
+	}
+	if cell.ColSpan > 1 {
+		r.outs(w, fmt.Sprintf(`\multicolumn{%d}{|%s|}{`, cell.ColSpan, cellAlign(cell.Align)))
+	}
+	if cell.IsHeader {
+		r.outs(w, `\textbf{`)
+	}
+}
+
+func (r *Renderer) link(w io.Writer, link *ast.Link, entering bool) {
+	if link.Footnote != nil {
+		if !entering {
+			return
+		}
+		// Render the footnote's content right here.
+		buf := &bytes.Buffer{}
+		ast.WalkFunc(link.Footnote, func(node ast.Node, entering bool) ast.WalkStatus {
+			if node == link.Footnote {
+				return ast.GoToNext
+			}
+			return r.RenderNode(buf, node, entering)
+		})
+		r.outs(w, `\footnote{`)
+		r.out(w, bytes.TrimSpace(buf.Bytes()))
+		r.outs(w, "}")
+		return
+	}
+	if !entering {
+		r.outs(w, "}")
+		return
+	}
+	r.outs(w, `\href{`+string(link.Destination)+"}{")
+}
+
+func (r *Renderer) image(w io.Writer, image *ast.Image, entering bool) ast.WalkStatus {
+	if entering {
+		r.outs(w, `\includegraphics[width=\linewidth]{`+string(image.Destination)+"}\n")
+	}
+	// The children are the alt text, which has no place in LaTeX.
+	return ast.SkipChildren
+}
+
+func (r *Renderer) captionFigure(w io.Writer, figure *ast.CaptionFigure, entering bool) {
+	env := figureEnv(figure)
+	if env == "" {
+		return
+	}
+	if !entering {
+		r.outs(w, `\end{`+env+"}\n\n")
+		return
+	}
+	r.outs(w, `\begin{`+env+"}[htbp]\n\\centering\n")
+}
+
+func (r *Renderer) caption(w io.Writer, caption *ast.Caption, entering bool) {
+	figure, _ := caption.Parent.(*ast.CaptionFigure)
+	if figure != nil && figureEnv(figure) == "" {
+		// a quote, the caption is the attribution.
+		r.outOneOf(w, entering, "\\begin{flushright}\n--- ", "\n\\end{flushright}\n\n")
+		return
+	}
+	if entering {
+		r.outs(w, `\caption{`)
+		return
+	}
+	r.outs(w, "}")
+	if figure != nil && figure.HeadingID != "" {
+		r.outs(w, `\label{`+label([]byte(figure.HeadingID))+"}")
+	}
+	r.cr(w)
+}
+
+// figureEnv returns the float environment for the figure, or the empty string if it should not be a float.
+func figureEnv(figure *ast.CaptionFigure) string {
+	switch ast.GetFirstChild(figure).(type) {
+	case *ast.Table:
+		return "table"
+	case *ast.BlockQuote:
+		return ""
+	}
+	return "figure"
+}
+
+func (r *Renderer) index(w io.Writer, index *ast.Index) {
+	r.outs(w, `\index{`)
+	Escape(w, index.Item)
+	if len(index.Subitem) > 0 {
+		r.outs(w, "!")
+		Escape(w, index.Subitem)
+	}
+	switch {
+	case mast.Attribute(index, mast.IndexSee) != nil:
+		r.outs(w, "|see{")
+		Escape(w, mast.Attribute(index, mast.IndexSee))
+		r.outs(w, "}")
+	case mast.Attribute(index, mast.IndexSeeAlso) != nil:
+		r.outs(w, "|seealso{")
+		Escape(w, mast.Attribute(index, mast.IndexSeeAlso))
+		r.outs(w, "}")
+	default:
+		format := ""
+		switch string(mast.Attribute(index, mast.IndexRange)) {
+		case "begin":
+			format = "("
+		case "end":
+			format = ")"
+		}
+		if index.Primary {
+			format += "textbf"
+		}
+		if format != "" {
+			r.outs(w, "|"+format)
+		}
+	}
+	r.outs(w, "}")
+}
+
+func (r *Renderer) bibliography(w io.Writer, entering bool) {
+	if !entering || r.bibDone {
+		return
+	}
+	r.abstractClose(w)
+	r.outs(w, "\\bibliographystyle{plain}\n\\bibliography{\\jobname}\n\n")
+	r.bibDone = true
+}
+
+// RenderNode renders a markdown node to LaTeX.
+func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.WalkStatus {
+	if r.opts.RenderNodeHook != nil {
+		status, didHandle := r.opts.RenderNodeHook(w, node, entering)
+		if didHandle {
+			return status
+		}
+	}
+
+	switch node := node.(type) {
+	case *ast.Document:
+		// do nothing
+	case *mast.Title:
+		r.titleBlock(w, node)
+		r.Title = node
+	case *mast.Authors:
+		// ignore
+	case *mast.BibliographyWrapper, *mast.Bibliography:
+		r.bibliography(w, entering)
+	case *mast.BibliographyItem:
+		// written to the .bib file, see BibTeX.
+	case *mast.DocumentIndex:
+		if entering {
+			r.abstractClose(w)
+			r.outs(w, "\\printindex\n")
+		}
+		return ast.SkipChildren
+	case *mast.IndexLetter, *mast.IndexItem, *mast.IndexSubItem, *mast.IndexLink:
+		// generated by makeindex
+	case *mast.ReferenceBlock:
+		// skip, added and done by AddBibliography
+	case *ast.Footnotes:
+		// rendered in place with \footnote
+		return ast.SkipChildren
+	case *ast.Text:
+		Escape(w, node.Literal)
+	case *ast.Softbreak:
+		r.cr(w)
+	case *ast.Hardbreak:
+		r.hardBreak(w, node)
+	case *ast.NonBlockingSpace:
+		r.outs(w, "~")
+	case *ast.Callout:
+		r.outs(w, `\emph{`+string(node.ID)+"}")
+	case *mast.Unicode:
+		Escape(w, []byte(node.String()))
+	case *mast.Abbreviation:
+		Escape(w, []byte(node.String()))
+	case *mast.Cref:
+		r.outs(w, `\emph{`)
+		Escape(w, []byte(node.String()))
+		r.outs(w, "}")
+	case *ast.Emph:
+		r.outOneOf(w, entering, `\emph{`, "}")
+	case *ast.Strong:
+		r.outOneOf(w, entering, `\textbf{`, "}")
+	case *ast.Del:
+		r.outOneOf(w, entering, `\sout{`, "}")
+	case *ast.Citation:
+		r.citation(w, node, entering)
+	case *ast.DocumentMatter:
+		r.matter(w, node, entering)
+	case *ast.Heading:
+		r.heading(w, node, entering)
+		if node.IsSpecial && isAbstract(node) {
+			return ast.SkipChildren
+		}
+	case *ast.HorizontalRule:
+		r.outs(w, "\\noindent\\rule{\\linewidth}{0.4pt}\n\n")
+	case *ast.Paragraph:
+		r.paragraph(w, node, entering)
+	case *ast.HTMLSpan:
+		if xml.IsBr(node.Literal) {
+			r.hardBreak(w, node)
+		}
+	case *ast.HTMLBlock:
+		// skip
+	case *ast.List:
+		if node.IsFootnotesList {
+			// rendered in place with \footnote
+			return ast.SkipChildren
+		}
+		r.list(w, node, entering)
+	case *ast.ListItem:
+		r.listItem(w, node, entering)
+	case *ast.CodeBlock:
+		r.codeBlock(w, node)
+	case *ast.Caption:
+		r.caption(w, node, entering)
+	case *ast.CaptionFigure:
+		r.captionFigure(w, node, entering)
+	case *ast.Table:
+		r.table(w, node, entering)
+	case *ast.TableCell:
+		r.tableCell(w, node, entering)
+	case *ast.TableHeader, *ast.TableBody, *ast.TableFooter:
+	case *ast.TableRow:
+		if !entering {
+			r.outs(w, " \\\\\n\\hline\n")
+		}
+	case *ast.BlockQuote:
+		r.outOneOf(w, entering, "\\begin{quote}\n", "\\end{quote}\n\n")
+	case *ast.Aside:
+		r.outOneOf(w, entering, "\\begin{quote}\\small\n", "\\end{quote}\n\n")
+	case *mast.Div:
+		// a div is just its contents in LaTeX.
+	case *ast.CrossReference:
+		if entering {
+			r.outs(w, `\ref{`+label(node.Destination)+"}")
+		}
+	case *ast.Index:
+		if entering {
+			r.index(w, node)
+		}
+	case *ast.Link:
+		r.link(w, node, entering)
+	case *ast.Math:
+		r.outs(w, "$")
+		r.out(w, node.Literal)
+		r.outs(w, "$")
+	case *ast.Image:
+		return r.image(w, node, entering)
+	case *ast.Code:
+		r.outs(w, `\texttt{`)
+		Escape(w, node.Literal)
+		r.outs(w, "}")
+	case *ast.MathBlock:
+		if entering {
+			// a blank line is a paragraph break, which isn't allowed in math.
+			r.outs(w, "\\[\n")
+			r.out(w, bytes.TrimSpace(node.Literal))
+			r.outs(w, "\n\\]\n\n")
+		}
+	case *ast.Subscript:
+		r.outs(w, `\textsubscript{`)
+		Escape(w, node.Literal)
+		r.outs(w, "}")
+	case *ast.Superscript:
+		r.outs(w, `\textsuperscript{`)
+		Escape(w, node.Literal)
+		r.outs(w, "}")
+	default:
+		panic(fmt.Sprintf("Unknown node %T", node))
+	}
+	return ast.GoToNext
+}
+
+// RenderHeader writes the LaTeX preamble. If the document has a bibliography, the BibTeX
+// entries are written to \jobname.bib with the filecontents environment.
+func (r *Renderer) RenderHeader(w io.Writer, doc ast.Node) {
+	if r.opts.Flags&LatexFragment != 0 {
+		return
+	}
+	r.outs(w, `% Generated by Mmark Markdown Processer - mmark.miek.nl`+"\n")
+
+	bib := &bytes.Buffer{}
+	if BibTeX(bib, doc) > 0 {
+		r.outs(w, "\\begin{filecontents*}[overwrite]{\\jobname.bib}\n")
+		r.out(w, bib.Bytes())
+		r.outs(w, "\\end{filecontents*}\n")
+	}
+
+	r.outs(w, `\documentclass{`+r.opts.DocumentClass+"}\n")
+	for _, pkg := range []string{"[utf8]{inputenc}", "{graphicx}", "{listings}", "{hyperref}", "[normalem]{ulem}", "{makeidx}"} {
+		r.outs(w, `\usepackage`+pkg+"\n")
+	}
+	r.outs(w, "\\lstset{basicstyle=\\ttfamily\\small,breaklines=true}\n")
+	r.outs(w, "\\makeindex\n\n")
+	r.outs(w, "\\begin{document}\n\n")
+}
+
+// RenderFooter writes the LaTeX document footer.
+func (r *Renderer) RenderFooter(w io.Writer, _ ast.Node) {
+	r.abstractClose(w)
+	if r.opts.Flags&LatexFragment != 0 {
+		return
+	}
+	r.outs(w, "\\end{document}\n")
+}
+
+func isAbstract(heading *ast.Heading) bool {
+	return strings.EqualFold(string(heading.Literal), "abstract")
+}
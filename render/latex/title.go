@@ -0,0 +1,40 @@
+package latex
+
+import (
+	"io"
+	"strings"
+
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// titleBlock outputs the \title, \author and \date and typesets them with \maketitle.
+func (r *Renderer) titleBlock(w io.Writer, t *mast.Title) {
+	d := t.TitleData
+	if d == nil {
+		return
+	}
+
+	r.outs(w, `\title{`)
+	Escape(w, []byte(d.Title))
+	r.outs(w, "}\n")
+
+	authors := []string{}
+	for _, a := range d.Author {
+		author := EscapeString(a.Fullname)
+		if a.Organization != "" {
+			author += ` \\ ` + EscapeString(a.Organization)
+		}
+		if a.Address.Email != "" {
+			author += ` \\ \texttt{` + EscapeString(a.Address.Email) + `}`
+		}
+		authors = append(authors, author)
+	}
+	r.outs(w, `\author{`+strings.Join(authors, " \\and ")+"}\n")
+
+	if d.Date.IsZero() {
+		r.outs(w, `\date{\today}`+"\n")
+	} else {
+		r.outs(w, `\date{`+d.Date.Format("2 January 2006")+"}\n")
+	}
+	r.outs(w, `\maketitle`+"\n")
+}
@@ -0,0 +1,44 @@
+package man
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/lang"
+	"github.com/mmarkdown/mmark/v2/mparser"
+)
+
+const cites = `Text [@!pandoc] and [@RFC2119].
+
+{backmatter}
+
+<reference anchor='pandoc' target='http://johnmacfarlane.net/pandoc/'>
+  <front>
+    <title>Pandoc, a universal document converter</title>
+    <author initials='J.' surname='MacFarlane' fullname='John MacFarlane'/>
+    <date year='2006'/>
+  </front>
+</reference>
+`
+
+func TestBibliography(t *testing.T) {
+	p := parser.NewWithExtensions(mparser.Extensions)
+	p.Opts = parser.Options{ParserHook: mparser.Hook}
+	doc := markdown.Parse([]byte(cites), p)
+	mparser.AddBibliography(doc)
+	out := string(markdown.Render(doc, NewRenderer(RendererOptions{Flags: ManFragment, Language: lang.New("en")})))
+
+	for _, want := range []string{
+		`.SH "BIBLIOGRAPHY"`,
+		`.SS "Normative References"`,
+		"[pandoc]\nJohn MacFarlane, \"Pandoc, a universal document converter\", 2006,\n\\[la]http://johnmacfarlane.net/pandoc/\\[ra].\n",
+		`.SS "Informative References"`,
+		"[RFC2119]\n\\[la]https://www.rfc-editor.org/info/rfc2119\\[ra]\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
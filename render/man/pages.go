@@ -0,0 +1,86 @@
+package man
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+// Page is a manual page, split off from the document by a level 1 heading with an ID like
+// {#mmark.1}.
+type Page struct {
+	Name string // Name of the page, including the section, i.e. mmark.1.
+	Data []byte
+}
+
+// pageID matches the IDs of the headings that start a new manual page: a name and a section.
+var pageID = regexp.MustCompile(`^([A-Za-z0-9_+:@-][A-Za-z0-9_.+:@-]*)\.([1-9][a-z]*)$`)
+
+// isPage returns true if node is a heading that starts a new manual page.
+func isPage(node ast.Node) bool {
+	h, ok := node.(*ast.Heading)
+	return ok && h.Level == 1 && !h.IsTitleblock && pageID.MatchString(h.HeadingID)
+}
+
+// hasPages returns true if the document contains headings that start a new manual page.
+func hasPages(doc ast.Node) bool {
+	found := false
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if isPage(node) {
+			found = true
+			return ast.Terminate
+		}
+		return ast.GoToNext
+	})
+	return found
+}
+
+// page starts a new manual page and outputs its .TH line. The date, area and workgroup are taken from
+// the title block.
+func (r *Renderer) page(node *ast.Heading) {
+	r.flush()
+	m := pageID.FindStringSubmatch(node.HeadingID)
+	r.pages = append(r.pages, Page{Name: node.HeadingID})
+	r.buf = &bytes.Buffer{}
+	r.listLevel, r.allListLevel = 0, 0
+
+	if r.opts.Flags&ManFragment == 0 {
+		r.outs(r.buf, `.\" Generated by Mmark Markdown Processer - mmark.miek.nl`+"\n")
+	}
+	date := r.opts.Date
+	area, workgroup := "", ""
+	if r.Title != nil && r.Title.TitleData != nil {
+		if !r.Title.Date.IsZero() {
+			date = r.Title.Date
+		}
+		area, workgroup = r.Title.Area, r.Title.Workgroup
+	}
+	r.outs(r.buf, fmt.Sprintf(".TH %q %s %q %q %q\n", strings.ToUpper(m[1]), m[2], date.Format("January 2006"), area, workgroup))
+}
+
+// Pages returns the manual pages the document was split in, this is nil when the document has no
+// headings that start a new page; the output is then a single manual page.
+func (r *Renderer) Pages() []Page {
+	r.flush()
+	return r.pages
+}
+
+// flush saves the output of the current page.
+func (r *Renderer) flush() {
+	if r.buf == nil {
+		return
+	}
+	r.pages[len(r.pages)-1].Data = r.buf.Bytes()
+}
+
+// writer returns the writer to write to, this is the current page when the document is split.
+func (r *Renderer) writer(w io.Writer) io.Writer {
+	if r.buf != nil {
+		return r.buf
+	}
+	return w
+}
@@ -0,0 +1,61 @@
+package man
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/mparser"
+)
+
+const tools = `# foo(1) {#foo.1}
+
+## NAME
+
+foo - does foo
+
+# bar(8) {#bar.8}
+
+## NAME
+
+bar - does bar
+`
+
+func TestPages(t *testing.T) {
+	p := parser.NewWithExtensions(mparser.Extensions)
+	doc := markdown.Parse([]byte(tools), p)
+	r := NewRenderer(RendererOptions{Flags: ManFragment, Date: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)})
+	markdown.Render(doc, r)
+
+	pages := r.Pages()
+	if len(pages) != 2 {
+		t.Fatalf("expected 2 pages, got %d", len(pages))
+	}
+	for i, want := range []struct{ name, th, text string }{
+		{"foo.1", `.TH "FOO" 1 "March 2020" `, "foo - does foo"},
+		{"bar.8", `.TH "BAR" 8 "March 2020" `, "bar - does bar"},
+	} {
+		page := string(pages[i].Data)
+		if pages[i].Name != want.name {
+			t.Errorf("expected page %q, got %q", want.name, pages[i].Name)
+		}
+		if !strings.HasPrefix(page, want.th) {
+			t.Errorf("expected page %q to start with %q, got %s", want.name, want.th, page)
+		}
+		if !strings.Contains(page, want.text) || strings.Count(page, ".TH") != 1 {
+			t.Errorf("expected page %q to contain only its own text, got %s", want.name, page)
+		}
+	}
+}
+
+func TestNoPages(t *testing.T) {
+	p := parser.NewWithExtensions(mparser.Extensions)
+	doc := markdown.Parse([]byte("# NAME {#name}\n\nfoo\n"), p)
+	r := NewRenderer(RendererOptions{Flags: ManFragment})
+	markdown.Render(doc, r)
+	if r.Pages() != nil {
+		t.Errorf("expected no pages, got %d", len(r.Pages()))
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
 	"strconv"
 	"strings"
 	"time"
@@ -18,6 +17,9 @@ import (
 	"github.com/gomarkdown/markdown/html"
 	"github.com/mmarkdown/mmark/v2/lang"
 	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/mast/reference"
+	"github.com/mmarkdown/mmark/v2/render/diagram"
+	"github.com/mmarkdown/mmark/v2/render/xml"
 )
 
 // Flags control optional behavior of Markdown renderer.
@@ -37,6 +39,7 @@ type RendererOptions struct {
 	Flags Flags // Flags allow customizing this renderer's behavior
 
 	Language lang.Lang // Output language for the document.
+	Date     time.Time // Date of the manual pages when the title block has none, defaults to now.
 
 	// if set, called at the start of RenderNode(). Allows replacing rendering of some nodes
 	RenderNodeHook html.RenderNodeFunc
@@ -44,6 +47,12 @@ type RendererOptions struct {
 	// Comments is a list of comments the renderer should detect when
 	// parsing code blocks and detecting callouts.
 	Comments [][]byte
+
+	// Diagrams converts the diagrams in fenced code blocks, their text is output instead of the source.
+	Diagrams *diagram.Diagrams
+
+	// Report, if set, is called with the problems found while rendering, otherwise these are logged.
+	Report mast.Reporter
 }
 
 // Renderer implements Renderer interface for Markdown output.
@@ -53,14 +62,26 @@ type Renderer struct {
 	Title        *mast.Title
 	listLevel    int
 	allListLevel int
+
+	split bool          // the document is split in multiple manual pages
+	pages []Page        // pages split off so far
+	buf   *bytes.Buffer // output of the current page
 }
 
 // NewRenderer creates and configures an Renderer object, which satisfies the Renderer interface.
 func NewRenderer(opts RendererOptions) *Renderer {
+	if opts.Date.IsZero() {
+		opts.Date = time.Now().UTC()
+	}
 	return &Renderer{opts: opts}
 }
 
-func (r *Renderer) hardBreak(w io.Writer, node *ast.Hardbreak) {
+func (r *Renderer) hardBreak(w io.Writer, node ast.Node) {
+	// tbl can't break a line in a cell.
+	if mast.InTableCell(node) {
+		r.outs(w, " ")
+		return
+	}
 	r.outs(w, "\n.br\n")
 }
 
@@ -74,7 +95,7 @@ func (r *Renderer) title(w io.Writer, node *mast.Title, entering bool) {
 	}
 
 	if node.Date.IsZero() {
-		node.Date = time.Now().UTC()
+		node.Date = r.opts.Date
 	}
 
 	// track back to first space and assume the rest is the section, don't parse it as a number
@@ -88,14 +109,14 @@ func (r *Renderer) title(w io.Writer, node *mast.Title, entering bool) {
 	case i > 0:
 		d, err := strconv.Atoi(node.Title[i:])
 		if err != nil {
-			log.Print("No section number found at end of title, defaulting to 1")
+			r.opts.Report.Reportf(mast.SeverityWarning, "title", "no section number found at end of title, defaulting to 1")
 		} else {
 			section = d
 			title = node.Title[:i-1]
 		}
 	}
 	if i == 0 {
-		log.Print("No section number found at end of title, defaulting to 1")
+		r.opts.Report.Reportf(mast.SeverityWarning, "title", "no section number found at end of title, defaulting to 1")
 	}
 
 	r.outs(w, fmt.Sprintf(".TH %q", strings.ToUpper(title)))
@@ -119,15 +140,38 @@ func (r *Renderer) heading(w io.Writer, node *ast.Heading, entering bool) {
 }
 
 func (r *Renderer) citation(w io.Writer, node *ast.Citation, entering bool) {
-	r.outs(w, "[")
+	cites := []string{}
+	suffix := false
 	for i, dest := range node.Destination {
-		if i > 0 {
-			r.outs(w, ", ")
+		if node.Type[i] == ast.CitationTypeSuppressed {
+			continue
 		}
-		r.out(w, dest)
+		cites = append(cites, string(dest))
+		if len(node.Suffix) > i && len(node.Suffix[i]) > 0 {
+			suffix = true
+		}
+	}
+	if len(cites) == 0 {
+		return
+	}
+	if !suffix {
+		escapeSpecialChars(r, w, []byte("["+strings.Join(cites, ", ")+"]"))
+		return
+	}
 
+	// With a suffix each citation gets its own brackets: "Section 5 of [RFC2535], [RFC2523]".
+	cites = cites[:0]
+	for i, dest := range node.Destination {
+		if node.Type[i] == ast.CitationTypeSuppressed {
+			continue
+		}
+		cite := "[" + string(dest) + "]"
+		if len(node.Suffix) > i {
+			cite = xml.ParseSuffix(node.Suffix[i], r.opts.Language).Cite(cite, r.opts.Language)
+		}
+		cites = append(cites, cite)
 	}
-	r.outs(w, "]")
+	escapeSpecialChars(r, w, []byte(strings.Join(cites, ", ")))
 }
 
 func (r *Renderer) paragraph(w io.Writer, para *ast.Paragraph, entering bool) {
@@ -237,8 +281,12 @@ func (r *Renderer) listItem(w io.Writer, listItem *ast.ListItem, entering bool)
 
 func (r *Renderer) codeBlock(w io.Writer, codeBlock *ast.CodeBlock, entering bool) {
 	if entering {
+		literal := codeBlock.Literal
+		if text := r.opts.Diagrams.Text(codeBlock); text != nil {
+			literal = text
+		}
 		r.outs(w, "\n.PP\n.RS\n\n.nf\n")
-		escapeSpecialChars(r, w, codeBlock.Literal)
+		escapeSpecialChars(r, w, literal)
 		r.outs(w, "\n.fi\n.RE\n")
 	}
 }
@@ -423,6 +471,7 @@ func (r *Renderer) aside(w io.Writer, block *ast.Aside, entering bool) {
 
 // RenderNode renders a markdown node to markdown.
 func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.WalkStatus {
+	w = r.writer(w)
 	if r.opts.RenderNodeHook != nil {
 		status, didHandle := r.opts.RenderNodeHook(w, node, entering)
 		if didHandle {
@@ -437,16 +486,20 @@ func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.Wal
 	case *ast.Document:
 		// do nothing
 	case *mast.Title:
-		r.title(w, node, entering)
+		if !r.split {
+			r.title(w, node, entering)
+		}
 		r.Title = node // save for later.
 	case *mast.Authors:
 		r.authors(w, node, entering)
-	case *mast.Bibliography, *mast.BibliographyWrapper:
+	case *mast.BibliographyWrapper:
 		if entering {
 			r.outs(w, "\n.SH \"")
 			r.outs(w, strings.ToUpper(r.opts.Language.Bibliography()))
 			r.outs(w, "\"\n")
 		}
+	case *mast.Bibliography:
+		r.bibliography(w, node, entering)
 	case *mast.BibliographyItem:
 		r.bibliographyItem(w, node, entering)
 	case *mast.DocumentIndex, *mast.IndexLetter, *mast.IndexItem, *mast.IndexSubItem, *mast.IndexLink:
@@ -461,9 +514,15 @@ func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.Wal
 	case *ast.Hardbreak:
 		r.hardBreak(w, node)
 	case *ast.NonBlockingSpace:
-		r.outs(w, "\\0")
+		r.outs(w, "\\~")
 	case *ast.Callout:
 		r.callout(w, node, entering)
+	case *mast.Unicode:
+		r.outs(w, node.String())
+	case *mast.Abbreviation:
+		r.outs(w, node.String())
+	case *mast.Cref:
+		r.outs(w, "\\fI"+node.String()+"\\fP")
 	case *ast.Emph:
 		r.outOneOf(w, entering, "\\fI", "\\fP")
 	case *ast.Strong:
@@ -475,6 +534,12 @@ func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.Wal
 	case *ast.DocumentMatter:
 		r.matter(w, node, entering)
 	case *ast.Heading:
+		if r.split && isPage(node) {
+			if entering {
+				r.page(node)
+			}
+			return ast.SkipChildren
+		}
 		r.heading(w, node, entering)
 	case *ast.HorizontalRule:
 		if entering {
@@ -483,6 +548,10 @@ func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.Wal
 	case *ast.Paragraph:
 		r.paragraph(w, node, entering)
 	case *ast.HTMLSpan:
+		if xml.IsBr(node.Literal) {
+			r.hardBreak(w, node)
+			break
+		}
 		r.out(w, node.Literal)
 	case *ast.HTMLBlock:
 		r.out(w, node.Literal)
@@ -509,6 +578,8 @@ func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.Wal
 		r.blockQuote(w, node, entering)
 	case *ast.Aside:
 		r.aside(w, node, entering)
+	case *mast.Div:
+		// no divs in manual pages.
 	case *ast.CrossReference:
 		r.crossReference(w, node, entering)
 	case *ast.Index:
@@ -568,7 +639,8 @@ func (r *Renderer) text(w io.Writer, node *ast.Text, entering bool) {
 		}
 	}
 
-	r.out(w, text)
+	// a non-breaking hyphen, see mparser.NonBreakingHyphen, becomes \- which groff doesn't break after.
+	r.out(w, bytes.ReplaceAll(text, []byte("\u2011"), []byte(`\-`)))
 }
 
 func (r *Renderer) footnotes(w io.Writer, node ast.Node, entering bool) {
@@ -578,8 +650,9 @@ func (r *Renderer) footnotes(w io.Writer, node ast.Node, entering bool) {
 	r.outs(w, "\n.SH \""+strings.ToUpper(r.opts.Language.Footnotes())+"\"\n")
 }
 
-func (r *Renderer) RenderHeader(w io.Writer, _ ast.Node) {
-	if r.opts.Flags&ManFragment != 0 {
+func (r *Renderer) RenderHeader(w io.Writer, doc ast.Node) {
+	r.split = hasPages(doc)
+	if r.opts.Flags&ManFragment != 0 || r.split {
 		return
 	}
 	r.outs(w, `.\" Generated by Mmark Markdown Processer - mmark.miek.nl`+"\n")
@@ -587,36 +660,91 @@ func (r *Renderer) RenderHeader(w io.Writer, _ ast.Node) {
 
 func (r *Renderer) RenderFooter(w io.Writer, node ast.Node) {}
 
-func (r *Renderer) bibliographyItem(w io.Writer, bib *mast.BibliographyItem, entering bool) {
+// bibliography outputs the heading of a references section, when there are normative and
+// informative references these become sub sections.
+func (r *Renderer) bibliography(w io.Writer, node *mast.Bibliography, entering bool) {
 	if !entering {
 		return
 	}
-	if bib.Reference == nil {
+	if _, ok := node.Parent.(*mast.BibliographyWrapper); ok {
+		title := r.opts.Language.Informative()
+		if node.Type == ast.CitationTypeNormative {
+			title = r.opts.Language.Normative()
+		}
+		r.outs(w, "\n.SS \""+title+"\"\n")
 		return
 	}
+	r.outs(w, "\n.SH \"")
+	r.outs(w, strings.ToUpper(r.opts.Language.Bibliography()))
+	r.outs(w, "\"\n")
+}
+
+func (r *Renderer) bibliographyItem(w io.Writer, bib *mast.BibliographyItem, entering bool) {
+	if !entering {
+		return
+	}
+	anchor := string(bib.Anchor)
+	if strings.HasPrefix(anchor, "I-D.") {
+		if hash := strings.Index(anchor, "#"); hash > 0 {
+			anchor = anchor[:hash]
+		}
+	}
 	r.outs(w, ".TP\n")
-	r.outs(w, fmt.Sprintf("[%s]\n", bib.Anchor))
-	for _, author := range bib.Reference.Front.Authors {
-		writeNonEmptyString(w, author.Fullname)
-		if author.Organization != nil {
-			writeNonEmptyString(w, author.Organization.Value)
+	r.outs(w, fmt.Sprintf("[%s]\n", anchor))
+	if bib.Reference == nil {
+		if target := anchorTarget(anchor); target != "" {
+			r.outs(w, "\\[la]"+target+"\\[ra]\n")
 		}
+		return
 	}
-
-	writeNonEmptyString(w, bib.Reference.Front.Title)
+	escapeSpecialChars(r, w, []byte(referenceText(bib.Reference)))
 	if bib.Reference.Target != "" {
-		r.outs(w, "\\[la]")
+		r.outs(w, ",\n\\[la]")
 		r.outs(w, bib.Reference.Target)
 		r.outs(w, "\\[ra]")
 	}
-	writeNonEmptyString(w, bib.Reference.Front.Date.Year)
-	r.outs(w, "\n")
+	r.outs(w, ".\n")
 }
 
-func writeNonEmptyString(w io.Writer, s string) {
-	if s == "" {
-		return
+// referenceText returns the text for a reference: authors, title, series and date.
+func referenceText(ref *reference.Reference) string {
+	parts := []string{}
+	names := []string{}
+	for _, a := range ref.Front.Authors {
+		switch {
+		case a.Fullname != "":
+			names = append(names, a.Fullname)
+		case a.Surname != "":
+			names = append(names, strings.TrimSpace(a.Initials+" "+a.Surname))
+		case a.Organization != nil && a.Organization.Value != "":
+			names = append(names, a.Organization.Value)
+		}
+	}
+	if len(names) > 0 {
+		parts = append(parts, strings.Join(names, ", "))
+	}
+	parts = append(parts, `"`+ref.Front.Title+`"`)
+	for _, s := range ref.Series {
+		parts = append(parts, strings.TrimSpace(s.Name+" "+s.Value))
+	}
+	parts = append(parts, ref.RefContent...)
+	if d := ref.Front.Date; d != nil && d.Year != "" {
+		parts = append(parts, strings.TrimSpace(d.Month+" "+d.Year))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// anchorTarget returns the URL for RFC and Internet-Draft anchors.
+func anchorTarget(anchor string) string {
+	switch {
+	case strings.HasPrefix(anchor, "RFC"):
+		num := strings.TrimLeft(anchor[3:], "0")
+		if num == "" || strings.Trim(num, "0123456789") != "" {
+			return ""
+		}
+		return "https://www.rfc-editor.org/info/rfc" + num
+	case strings.HasPrefix(anchor, "I-D."):
+		return "https://datatracker.ietf.org/doc/draft-" + anchor[4:] + "/"
 	}
-	io.WriteString(w, s)
-	io.WriteString(w, "\n")
+	return ""
 }
@@ -0,0 +1,159 @@
+package mdoc
+
+import (
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/render/xml"
+)
+
+func (r *Renderer) outs(w io.Writer, s string) {
+	if s == "" {
+		return
+	}
+	io.WriteString(w, s)
+	r.bol = s[len(s)-1] == '\n'
+}
+
+// cr makes sure the next output starts on a new line.
+func (r *Renderer) cr(w io.Writer) {
+	if !r.bol {
+		r.outs(w, "\n")
+	}
+}
+
+// macro outputs a macro line, the macro is left open so trailing punctuation can be added, see
+// text. Pending whitespace is dropped and an opening delimiter is moved to the macro line.
+func (r *Renderer) macro(w io.Writer, name string, args ...string) {
+	opener := r.opener
+	r.space, r.opener = "", ""
+	r.cr(w)
+	line := "." + name
+	if opener != "" {
+		line += " " + opener
+	}
+	for _, a := range args {
+		if a == "" {
+			continue
+		}
+		line += " " + a
+	}
+	r.outs(w, line)
+	r.open = true
+}
+
+// text outputs text, escaped. Punctuation directly after a macro is added to the macro line. Trailing
+// whitespace and an opening delimiter are held back until we know whether a macro follows.
+func (r *Renderer) text(w io.Writer, s string) {
+	if r.space != "" || r.opener != "" {
+		s = r.opener + r.space + s
+		r.space, r.opener = "", ""
+	}
+	trimmed := strings.TrimRight(s, " \t")
+	r.space = s[len(trimmed):]
+	s = trimmed
+	if r.space == "" && (strings.HasSuffix(s, "(") || strings.HasSuffix(s, "[")) {
+		r.opener = s[len(s)-1:]
+		s = strings.TrimRight(s[:len(s)-1], " \t")
+	}
+	if r.open {
+		r.open = false
+		if p := punctuation.FindString(s); p != "" {
+			r.outs(w, " "+strings.Join(strings.Split(strings.TrimSpace(p), ""), " "))
+			s = s[len(p):]
+		}
+		r.outs(w, "\n")
+	}
+	for _, line := range strings.SplitAfter(s, "\n") {
+		if r.bol {
+			line = strings.TrimLeft(line, " \t")
+		}
+		if line == "" || line == "\n" && r.bol {
+			continue
+		}
+		r.outs(w, escape(line, r.bol))
+	}
+}
+
+// close ends a pending macro line, held back text is flushed.
+func (r *Renderer) close(w io.Writer) {
+	if r.opener != "" {
+		r.outs(w, r.opener)
+		r.space, r.opener = "", ""
+	}
+	if r.open {
+		r.open = false
+		r.outs(w, "\n")
+	}
+}
+
+var punctuation = regexp.MustCompile(`^[.,:;)\]?!]+(\s|$)`)
+
+// unbreakable replaces the non-breaking space and hyphen with their roff escapes.
+var unbreakable = strings.NewReplacer("\u00a0", `\~`, "\u2011", `\-`)
+
+// escape escapes text for mdoc, bol is true when s starts at the beginning of a line.
+func escape(s string, bol bool) string {
+	s = unbreakable.Replace(strings.ReplaceAll(s, `\`, `\e`))
+	if bol && (strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'")) {
+		s = `\&` + s
+	}
+	return strings.ReplaceAll(s, "\n.", "\n\\&.")
+}
+
+// arg quotes s if it is needed for use as a macro argument, delimiters are escaped so they are
+// taken literally.
+func arg(s string) string {
+	s = unbreakable.Replace(strings.ReplaceAll(s, `\`, `\e`))
+	if len(s) == 1 && strings.Contains(".,:;()[]?!|", s) {
+		return `\&` + s
+	}
+	if strings.ContainsAny(s, " \t") || s == "" {
+		return `"` + strings.ReplaceAll(s, `"`, `\(dq`) + `"`
+	}
+	return s
+}
+
+// plain returns the text of node's children, without markup.
+func plain(node ast.Node) string {
+	b := &strings.Builder{}
+	ast.WalkFunc(node, func(n ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch n.(type) {
+		case *ast.NonBlockingSpace:
+			b.WriteString("\u00a0")
+		case *ast.Hardbreak:
+			b.WriteString(" ")
+		case *ast.HTMLSpan:
+			if xml.IsBr(n.AsLeaf().Literal) {
+				b.WriteString(" ")
+			}
+		default:
+			if l := n.AsLeaf(); l != nil {
+				b.Write(l.Literal)
+			}
+		}
+		return ast.GoToNext
+	})
+	return b.String()
+}
+
+var classIAL = regexp.MustCompile(`^\{\.([A-Z][a-z])\}`)
+
+// class returns the mdoc macro set with an IAL class directly after node, i.e. `-v`{.Fl}.
+func class(node ast.Node) string {
+	next := ast.GetNextNode(node)
+	t, ok := next.(*ast.Text)
+	if !ok {
+		return ""
+	}
+	m := classIAL.FindSubmatch(t.Literal)
+	if m == nil {
+		return ""
+	}
+	return string(m[1])
+}
@@ -0,0 +1,506 @@
+// The package mdoc outputs semantic BSD manual pages (mdoc(7)) from mmark markdown.
+//
+// Inline code, emphasis and strong text can be given an mdoc macro with an IAL class directly after the
+// element, i.e. `-v`{.Fl}, `ls(1)`{.Xr} or *file*{.Ar}. Without a class, code is output as .Ql,
+// emphasis as .Em and strong as .Sy. The paragraph in the NAME section should be "name - description".
+package mdoc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/v2/lang"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// Flags control optional behavior of the mdoc renderer.
+type Flags int
+
+// mdoc renderer configuration options.
+const (
+	FlagsNone    Flags = 0
+	MdocFragment Flags = 1 << iota // Don't generate a complete document
+
+	CommonFlags Flags = FlagsNone
+)
+
+// RendererOptions is a collection of supplementary parameters tweaking
+// the behavior of various parts of the mdoc renderer.
+type RendererOptions struct {
+	Flags Flags // Flags allow customizing this renderer's behavior
+
+	Language lang.Lang // Output language for the document.
+	Date     time.Time // Date of the manual page when the title block has none, defaults to now.
+
+	// if set, called at the start of RenderNode(). Allows replacing rendering of some nodes
+	RenderNodeHook html.RenderNodeFunc
+
+	// Report, if set, is called with the problems found while rendering, otherwise these are logged.
+	Report mast.Reporter
+}
+
+// Renderer implements Renderer interface for mdoc output.
+type Renderer struct {
+	opts RendererOptions
+
+	Title *mast.Title
+
+	bol     bool   // at the beginning of a line
+	open    bool   // a macro line is open
+	space   string // held back whitespace
+	opener  string // held back opening delimiter
+	section string
+	skip    map[ast.Node]bool // Text nodes that are an IAL
+}
+
+// Macros are the mdoc macros that can be used as an IAL class.
+var Macros = map[string]bool{
+	"Ad": true, "An": true, "Ar": true, "Cd": true, "Cm": true, "Dv": true, "Em": true, "Er": true,
+	"Ev": true, "Fa": true, "Fl": true, "Fn": true, "Ft": true, "Ic": true, "In": true, "Li": true,
+	"Nm": true, "Pa": true, "Ql": true, "Sx": true, "Sy": true, "Va": true, "Xr": true,
+}
+
+// NewRenderer creates and configures an Renderer object, which satisfies the Renderer interface.
+func NewRenderer(opts RendererOptions) *Renderer {
+	if opts.Date.IsZero() {
+		opts.Date = time.Now().UTC()
+	}
+	return &Renderer{opts: opts, bol: true, skip: map[ast.Node]bool{}}
+}
+
+func (r *Renderer) title(w io.Writer, node *mast.Title) {
+	if node.Date.IsZero() {
+		node.Date = r.opts.Date
+	}
+	// the section is the last word of the title, as with the man renderer.
+	title, section := node.Title, "1"
+	if i := strings.LastIndex(node.Title, " "); i > 0 {
+		if _, err := strconv.Atoi(node.Title[i+1:]); err == nil {
+			title, section = node.Title[:i], node.Title[i+1:]
+		} else {
+			r.opts.Report.Reportf(mast.SeverityWarning, "title", "no section number found at end of title, defaulting to 1")
+		}
+	}
+	r.macro(w, "Dd", node.Date.Format("January 2, 2006"))
+	r.macro(w, "Dt", strings.ToUpper(title), section)
+	r.macro(w, "Os")
+	r.close(w)
+}
+
+func (r *Renderer) heading(w io.Writer, node *ast.Heading, entering bool) {
+	if !entering {
+		r.close(w)
+		return
+	}
+	r.section = strings.ToUpper(plain(node))
+	name := "Sh"
+	if node.Level > 2 {
+		name = "Ss"
+	}
+	if node.Level <= 2 {
+		r.macro(w, name, r.section)
+		r.close(w)
+		return
+	}
+	r.macro(w, name, plain(node))
+	r.close(w)
+}
+
+// nameLine matches the "name - description" paragraph of the NAME section.
+var nameLine = regexp.MustCompile(`^\s*([^\s]+(?:\s*,\s*[^\s]+)*)\s+\\?[-–—]\s+(.*)$`)
+
+func (r *Renderer) paragraph(w io.Writer, para *ast.Paragraph, entering bool) ast.WalkStatus {
+	if !entering {
+		r.close(w)
+		r.cr(w)
+		return ast.GoToNext
+	}
+	if r.section == "NAME" {
+		if m := nameLine.FindStringSubmatch(strings.ReplaceAll(plain(para), "\n", " ")); m != nil {
+			for i, n := range strings.Split(m[1], ",") {
+				if i > 0 {
+					r.text(w, ",")
+				}
+				r.macro(w, "Nm", strings.TrimSpace(n))
+			}
+			r.macro(w, "Nd", m[2])
+			r.close(w)
+			return ast.SkipChildren
+		}
+	}
+	if _, ok := para.Parent.(*ast.ListItem); ok && ast.GetPrevNode(para) == nil {
+		return ast.GoToNext
+	}
+	if _, ok := para.Parent.(*ast.CaptionFigure); ok {
+		return ast.GoToNext
+	}
+	if prev := ast.GetPrevNode(para); prev != nil {
+		if _, ok := prev.(*ast.Heading); ok {
+			return ast.GoToNext // no .Pp directly after .Sh
+		}
+	}
+	r.macro(w, "Pp")
+	r.close(w)
+	return ast.GoToNext
+}
+
+func (r *Renderer) list(w io.Writer, list *ast.List, entering bool) {
+	if !entering {
+		r.macro(w, "El")
+		r.close(w)
+		return
+	}
+	switch {
+	case list.ListFlags&ast.ListTypeDefinition != 0:
+		r.macro(w, "Bl", "-tag", "-width", "Ds")
+	case list.ListFlags&ast.ListTypeOrdered != 0:
+		r.macro(w, "Bl", "-enum")
+	default:
+		r.macro(w, "Bl", "-bullet")
+	}
+	if list.Tight {
+		r.outs(w, " -compact")
+	}
+	r.close(w)
+}
+
+func (r *Renderer) listItem(w io.Writer, item *ast.ListItem, entering bool) {
+	if !entering {
+		r.close(w)
+		return
+	}
+	switch {
+	case item.ListFlags&ast.ListTypeTerm != 0:
+		r.macro(w, "It", r.inlineArgs(item))
+		r.close(w)
+	case item.ListFlags&ast.ListTypeDefinition != 0:
+		// definition follows the .It of the term.
+	default:
+		r.macro(w, "It")
+		r.close(w)
+	}
+}
+
+func (r *Renderer) inlineMacro(w io.Writer, node ast.Node, literal, def string) {
+	name := class(node)
+	if name != "" && Macros[name] {
+		r.skip[ast.GetNextNode(node)] = true
+	} else {
+		name = def
+	}
+	r.macro(w, name, macroArgs(name, literal)...)
+}
+
+// inlineArgs returns the inline children of node as arguments for a macro line, i.e. for .It.
+func (r *Renderer) inlineArgs(node ast.Node) string {
+	args := []string{}
+	ast.WalkFunc(node, func(n ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch n := n.(type) {
+		case *ast.Text:
+			s := string(n.Literal)
+			if r.skip[n] {
+				s = classIAL.ReplaceAllString(s, "")
+			}
+			for _, f := range strings.Fields(s) {
+				args = append(args, arg(f))
+			}
+		case *ast.Code, *ast.Emph, *ast.Strong:
+			def := "Ql"
+			switch n.(type) {
+			case *ast.Emph:
+				def = "Em"
+			case *ast.Strong:
+				def = "Sy"
+			}
+			name := class(n)
+			if name != "" && Macros[name] {
+				r.skip[ast.GetNextNode(n)] = true
+			} else {
+				name = def
+			}
+			args = append(args, name)
+			args = append(args, macroArgs(name, plain(n))...)
+			return ast.SkipChildren
+		}
+		return ast.GoToNext
+	})
+	return strings.Join(args, " ")
+}
+
+// macroArgs returns the arguments for macro name given the text literal.
+func macroArgs(name, literal string) []string {
+	switch name {
+	case "Fl":
+		return []string{arg(strings.TrimPrefix(literal, "-"))}
+	case "Xr":
+		// ls(1) -> .Xr ls 1
+		if i := strings.Index(literal, "("); i > 0 && strings.HasSuffix(literal, ")") {
+			return []string{arg(literal[:i]), arg(literal[i+1 : len(literal)-1])}
+		}
+	}
+	return []string{arg(literal)}
+}
+
+func (r *Renderer) textNode(w io.Writer, node *ast.Text) {
+	s := string(node.Literal)
+	if r.skip[node] {
+		s = classIAL.ReplaceAllString(s, "")
+	}
+	r.text(w, s)
+}
+
+func (r *Renderer) table(w io.Writer, tab *ast.Table) {
+	rows := [][]string{}
+	ast.WalkFunc(tab, func(node ast.Node, entering bool) ast.WalkStatus {
+		switch node := node.(type) {
+		case *ast.TableRow:
+			if entering {
+				rows = append(rows, []string{})
+			}
+		case *ast.TableCell:
+			if entering {
+				rows[len(rows)-1] = append(rows[len(rows)-1], strings.TrimSpace(plain(node)))
+			}
+			return ast.SkipChildren
+		}
+		return ast.GoToNext
+	})
+	if len(rows) == 0 {
+		return
+	}
+	// the column widths are given as the widest text in each column.
+	widths := []string{}
+	for c := range rows[0] {
+		widest := ""
+		for _, row := range rows {
+			if c < len(row) && len(row[c]) > len(widest) {
+				widest = row[c]
+			}
+		}
+		widths = append(widths, arg(widest))
+	}
+	r.macro(w, "Bl", append([]string{"-column"}, widths...)...)
+	r.close(w)
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i := range row {
+			cells[i] = escape(row[i], false)
+		}
+		r.macro(w, "It", strings.Join(cells, " Ta "))
+		r.close(w)
+	}
+	r.macro(w, "El")
+	r.close(w)
+}
+
+// literal outputs data as a literal display, for code and math blocks.
+func (r *Renderer) literal(w io.Writer, data []byte) {
+	r.macro(w, "Bd", "-literal", "-offset", "indent")
+	r.close(w)
+	r.outs(w, escape(string(data), true))
+	r.cr(w)
+	r.macro(w, "Ed")
+	r.close(w)
+}
+
+func (r *Renderer) citation(w io.Writer, node *ast.Citation) {
+	cites := []string{}
+	for _, d := range node.Destination {
+		cites = append(cites, string(d))
+	}
+	r.text(w, "["+strings.Join(cites, ", ")+"]")
+}
+
+func (r *Renderer) authors(w io.Writer) {
+	if r.Title == nil || len(r.Title.Author) == 0 {
+		return
+	}
+	r.macro(w, "Sh", "AUTHORS")
+	r.close(w)
+	for _, a := range r.Title.Author {
+		r.macro(w, "An", a.Fullname)
+		if a.Address.Email != "" {
+			r.macro(w, "Aq", "Mt", a.Address.Email)
+		}
+		r.close(w)
+	}
+}
+
+// RenderNode renders a markdown node to mdoc.
+func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.WalkStatus {
+	if r.opts.RenderNodeHook != nil {
+		status, didHandle := r.opts.RenderNodeHook(w, node, entering)
+		if didHandle {
+			return status
+		}
+	}
+
+	switch node := node.(type) {
+	case *ast.Document:
+		// do nothing
+	case *mast.Title:
+		r.Title = node
+		if entering && r.opts.Flags&MdocFragment == 0 {
+			r.title(w, node)
+		}
+	case *mast.Authors:
+		if entering {
+			r.authors(w)
+		}
+	case *mast.Bibliography, *mast.BibliographyWrapper, *mast.BibliographyItem:
+		// a bibliography can't be expressed in mdoc.
+		return ast.SkipChildren
+	case *mast.DocumentIndex, *mast.IndexLetter, *mast.IndexItem, *mast.IndexSubItem, *mast.IndexLink:
+		return ast.SkipChildren
+	case *mast.ReferenceBlock:
+	case *ast.Footnotes:
+		if entering {
+			r.macro(w, "Sh", r.opts.Language.Footnotes())
+			r.close(w)
+		}
+	case *ast.Text:
+		r.textNode(w, node)
+	case *ast.Softbreak:
+		r.text(w, "\n")
+	case *ast.Hardbreak:
+		r.macro(w, "br")
+		r.close(w)
+	case *ast.NonBlockingSpace:
+		r.text(w, "\u00a0")
+	case *ast.Emph:
+		if entering {
+			r.inlineMacro(w, node, plain(node), "Em")
+		}
+		return ast.SkipChildren
+	case *ast.Strong:
+		if entering {
+			r.inlineMacro(w, node, plain(node), "Sy")
+		}
+		return ast.SkipChildren
+	case *ast.Code:
+		r.inlineMacro(w, node, string(node.Literal), "Ql")
+	case *ast.Del:
+	case *ast.Citation:
+		if entering {
+			r.citation(w, node)
+		}
+	case *ast.Callout:
+		r.text(w, "<"+string(node.ID)+">")
+	case *mast.Unicode:
+		r.text(w, node.String())
+	case *mast.Abbreviation:
+		r.text(w, node.String())
+	case *mast.Cref:
+		r.text(w, node.String())
+	case *ast.DocumentMatter:
+	case *ast.Heading:
+		r.heading(w, node, entering)
+		return ast.SkipChildren
+	case *ast.HorizontalRule:
+	case *ast.Paragraph:
+		return r.paragraph(w, node, entering)
+	case *ast.HTMLSpan, *ast.HTMLBlock:
+	case *ast.List:
+		if !node.IsFootnotesList {
+			r.list(w, node, entering)
+			break
+		}
+		if entering {
+			r.macro(w, "Bl", "-tag", "-width", "Ds")
+		} else {
+			r.macro(w, "El")
+		}
+		r.close(w)
+	case *ast.ListItem:
+		if node.RefLink != nil {
+			if entering {
+				r.macro(w, "It", fmt.Sprintf("[%s]", node.RefLink))
+			}
+			r.close(w)
+			break
+		}
+		r.listItem(w, node, entering)
+		if node.ListFlags&ast.ListTypeTerm != 0 {
+			return ast.SkipChildren
+		}
+	case *ast.CodeBlock:
+		if !entering {
+			break
+		}
+		r.literal(w, node.Literal)
+	case *ast.MathBlock:
+		// a container, its children are the text of the math again.
+		if entering {
+			r.literal(w, bytes.Trim(node.Literal, "\n"))
+		}
+		return ast.SkipChildren
+	case *ast.Caption:
+		if entering {
+			r.macro(w, "Pp")
+			r.close(w)
+		}
+	case *ast.CaptionFigure:
+	case *ast.Table:
+		if entering {
+			r.table(w, node)
+		}
+		return ast.SkipChildren
+	case *ast.TableCell, *ast.TableHeader, *ast.TableBody, *ast.TableFooter, *ast.TableRow:
+	case *ast.BlockQuote, *ast.Aside:
+		if entering {
+			r.macro(w, "Bd", "-ragged", "-offset", "indent")
+		} else {
+			r.macro(w, "Ed")
+		}
+		r.close(w)
+	case *mast.Div:
+		// no divs in manual pages.
+	case *ast.CrossReference:
+		if entering {
+			r.macro(w, "Sx", arg(strings.ToUpper(string(node.Destination))))
+		}
+		return ast.SkipChildren
+	case *ast.Index:
+	case *ast.Link:
+		if node.Footnote != nil {
+			if entering {
+				r.text(w, fmt.Sprintf("[%d]", node.NoteID))
+			}
+			return ast.SkipChildren
+		}
+		if entering {
+			text := plain(node)
+			if text == "" || text == string(node.Destination) {
+				r.macro(w, "Lk", arg(string(node.Destination)))
+			} else {
+				r.macro(w, "Lk", arg(string(node.Destination)), arg(text))
+			}
+		}
+		return ast.SkipChildren
+	case *ast.Math:
+		r.text(w, string(node.Literal))
+	case *ast.Image:
+		return ast.SkipChildren
+	case *ast.Subscript, *ast.Superscript:
+		r.text(w, string(node.AsLeaf().Literal))
+	default:
+		panic(fmt.Sprintf("Unknown node %T", node))
+	}
+	return ast.GoToNext
+}
+
+// RenderHeader does nothing, the title block outputs the prologue.
+func (r *Renderer) RenderHeader(w io.Writer, _ ast.Node) {}
+
+// RenderFooter ends the last macro line.
+func (r *Renderer) RenderFooter(w io.Writer, _ ast.Node) { r.close(w) }
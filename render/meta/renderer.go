@@ -0,0 +1,173 @@
+// The package meta outputs the metadata of a mmark document as JSON: the parsed title block and data
+// computed from the document, such as the sections, the cited references and the expiration date.
+// This is meant for automation that needs to know, for instance, the name and version of a draft.
+package meta
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"sort"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// RendererOptions is a collection of supplementary parameters tweaking
+// the behavior of various parts of the meta renderer.
+type RendererOptions struct{}
+
+// Renderer implements Renderer interface for metadata output.
+type Renderer struct {
+	opts RendererOptions
+
+	Title *mast.Title
+
+	sections    []Section
+	normative   map[string]bool
+	informative map[string]bool
+	matter      string
+}
+
+// Meta is the metadata of a document.
+type Meta struct {
+	TitleBlock *mast.TitleData `json:"titleBlock,omitempty"`
+	DocName    string          `json:"docName,omitempty"` // name of the draft, without the version
+	Version    string          `json:"version,omitempty"` // version of the draft, i.e. "00"
+	Date       string          `json:"date,omitempty"`
+	Expires    string          `json:"expires,omitempty"` // expiration date of a draft
+	Sections   []Section       `json:"sections"`
+	Citations  Citations       `json:"citations"`
+}
+
+// Section is a heading in the document.
+type Section struct {
+	Level   int    `json:"level"`
+	Title   string `json:"title"`
+	ID      string `json:"id,omitempty"`
+	Matter  string `json:"matter,omitempty"` // front, main or back
+	Special bool   `json:"special,omitempty"`
+}
+
+// Citations holds the references that are cited in the document. A reference that is cited both
+// normatively and informatively is listed as normative.
+type Citations struct {
+	Count       int      `json:"count"`
+	Normative   []string `json:"normative"`
+	Informative []string `json:"informative"`
+}
+
+// NewRenderer creates and configures an Renderer object, which satisfies the Renderer interface.
+func NewRenderer(opts RendererOptions) *Renderer {
+	return &Renderer{opts: opts, normative: map[string]bool{}, informative: map[string]bool{}}
+}
+
+// RenderNode gathers the sections and citations of the document.
+func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.GoToNext
+	}
+	switch node := node.(type) {
+	case *mast.Title:
+		r.Title = node
+		return ast.SkipChildren
+	case *ast.DocumentMatter:
+		switch node.Matter {
+		case ast.DocumentMatterFront:
+			r.matter = "front"
+		case ast.DocumentMatterMain:
+			r.matter = "main"
+		case ast.DocumentMatterBack:
+			r.matter = "back"
+		}
+	case *ast.Heading:
+		if node.IsTitleblock {
+			return ast.SkipChildren
+		}
+		r.sections = append(r.sections, Section{Level: node.Level, Title: plain(node), ID: node.HeadingID, Matter: r.matter, Special: node.IsSpecial})
+		return ast.SkipChildren
+	case *ast.Citation:
+		for i, c := range node.Destination {
+			switch node.Type[i] {
+			case ast.CitationTypeNormative:
+				r.normative[string(c)] = true
+			default:
+				r.informative[string(c)] = true
+			}
+		}
+	case *mast.Bibliography, *mast.BibliographyWrapper, *mast.DocumentIndex, *mast.ReferenceBlock:
+		return ast.SkipChildren
+	}
+	return ast.GoToNext
+}
+
+// RenderHeader does nothing, the metadata is written when the whole document has been seen.
+func (r *Renderer) RenderHeader(w io.Writer, doc ast.Node) {}
+
+var version = regexp.MustCompile(`^(.+)-(\d\d)$`)
+
+// RenderFooter writes the metadata as JSON to w.
+func (r *Renderer) RenderFooter(w io.Writer, _ ast.Node) {
+	m := Meta{Sections: r.sections}
+	if m.Sections == nil {
+		m.Sections = []Section{}
+	}
+	if r.Title != nil && r.Title.TitleData != nil {
+		d := r.Title.TitleData
+		m.TitleBlock = d
+		if d.SeriesInfo.Name == "Internet-Draft" {
+			m.DocName = d.SeriesInfo.Value
+			if v := version.FindStringSubmatch(d.SeriesInfo.Value); v != nil {
+				m.DocName, m.Version = v[1], v[2]
+			}
+		}
+		if !d.Date.IsZero() {
+			m.Date = d.Date.Format("2006-01-02")
+		}
+		if e := d.Expires(); !e.IsZero() {
+			m.Expires = e.Format("2006-01-02")
+		}
+	}
+	for anchor := range r.informative {
+		if r.normative[anchor] {
+			delete(r.informative, anchor)
+		}
+	}
+	m.Citations = Citations{
+		Count:       len(r.normative) + len(r.informative),
+		Normative:   keys(r.normative),
+		Informative: keys(r.informative),
+	}
+
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	enc.Encode(m)
+	w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
+
+func keys(m map[string]bool) []string {
+	k := make([]string, 0, len(m))
+	for s := range m {
+		k = append(k, s)
+	}
+	sort.Strings(k)
+	return k
+}
+
+// plain returns the text of node's children, without markup.
+func plain(node ast.Node) string {
+	buf := &bytes.Buffer{}
+	ast.WalkFunc(node, func(n ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		if l := n.AsLeaf(); l != nil {
+			buf.Write(l.Literal)
+		}
+		return ast.GoToNext
+	})
+	return buf.String()
+}
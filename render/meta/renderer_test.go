@@ -0,0 +1,52 @@
+package meta
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/mparser"
+)
+
+const doc = `%%%
+title = "Test"
+date = 2024-01-02T00:00:00Z
+[seriesInfo]
+name = "Internet-Draft"
+value = "draft-gieben-test-03"
+%%%
+
+# One
+
+See [@!RFC2119] and [@?RFC8174].
+
+# Two {#two}
+
+Again [@RFC2119].
+`
+
+func TestMeta(t *testing.T) {
+	p := parser.NewWithExtensions(mparser.Extensions)
+	p.Opts = parser.Options{ParserHook: mparser.Hook}
+	d := markdown.Parse([]byte(doc), p)
+	out := markdown.Render(d, NewRenderer(RendererOptions{}))
+
+	m := Meta{}
+	if err := json.Unmarshal(out, &m); err != nil {
+		t.Fatalf("failed to parse output: %s: %s", err, out)
+	}
+	if m.DocName != "draft-gieben-test" || m.Version != "03" {
+		t.Errorf("expected draft-gieben-test and 03, got %q and %q", m.DocName, m.Version)
+	}
+	if m.Date != "2024-01-02" || m.Expires != "2024-07-05" {
+		t.Errorf("expected date 2024-01-02 and expires 2024-07-05, got %q and %q", m.Date, m.Expires)
+	}
+	if len(m.Sections) != 2 || m.Sections[1].ID != "two" || m.Sections[1].Title != "Two" {
+		t.Errorf("unexpected sections: %v", m.Sections)
+	}
+	c := m.Citations
+	if c.Count != 2 || len(c.Normative) != 1 || c.Normative[0] != "RFC2119" || len(c.Informative) != 1 || c.Informative[0] != "RFC8174" {
+		t.Errorf("unexpected citations: %v", c)
+	}
+}
@@ -0,0 +1,114 @@
+package mhtml
+
+import (
+	"io"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/mast/reference"
+)
+
+// bibliography outputs the heading of a references section. When there are normative and
+// informative references these are sub sections of the bibliography.
+func (r RendererOptions) bibliography(w io.Writer, node *mast.Bibliography, entering bool) {
+	if !entering {
+		io.WriteString(w, "</dl>\n</div>\n")
+		return
+	}
+	if _, ok := node.Parent.(*mast.BibliographyWrapper); ok {
+		id, title := "informative-references", r.Language.Informative()
+		if node.Type == ast.CitationTypeNormative {
+			id, title = "normative-references", r.Language.Normative()
+		}
+		io.WriteString(w, "<h2 id=\""+id+"\">"+escape(title)+"</h2>\n")
+	} else {
+		io.WriteString(w, "<h1 id=\"bibliography-section\">"+r.Language.Bibliography()+"</h1>\n")
+	}
+	io.WriteString(w, "<div class=\"bibliography\">\n<dl>\n")
+}
+
+func (r RendererOptions) bibliographyItem(w io.Writer, bib *mast.BibliographyItem, entering bool) {
+	io.WriteString(w, `<dt class="bibliography-cite" id="`+escape(string(bib.Anchor))+`">[`+escape(r.label(string(bib.Anchor)))+"]</dt>\n")
+	io.WriteString(w, `<dd>`)
+	defer io.WriteString(w, "</dd>\n")
+	if bib.Reference == nil {
+		if target := anchorTarget(string(bib.Anchor)); target != "" {
+			io.WriteString(w, `<a class="bibliography-target" href="`+escape(target)+`">`+escape(target)+"</a>")
+		}
+		return
+	}
+	io.WriteString(w, referenceHTML(bib.Reference))
+}
+
+// referenceHTML returns the HTML for a reference: authors, linked title, series, date and target.
+func referenceHTML(ref *reference.Reference) string {
+	parts := []string{}
+	names := []string{}
+	for _, a := range ref.Front.Authors {
+		switch {
+		case a.Fullname != "":
+			names = append(names, a.Fullname)
+		case a.Surname != "":
+			names = append(names, strings.TrimSpace(a.Initials+" "+a.Surname))
+		case a.Organization != nil && a.Organization.Value != "":
+			names = append(names, a.Organization.Value)
+		}
+	}
+	for i := range names {
+		names[i] = `<span class="bibliography-author">` + escape(names[i]) + "</span>"
+	}
+	if len(names) > 0 {
+		parts = append(parts, strings.Join(names, ", "))
+	}
+
+	title := `"` + escape(ref.Front.Title) + `"`
+	if ref.Target != "" {
+		title = `<a href="` + escape(ref.Target) + `">` + title + "</a>"
+	}
+	parts = append(parts, `<span class="bibliography-title">`+title+"</span>")
+
+	series := []string{}
+	for _, s := range ref.Series {
+		series = append(series, escape(strings.TrimSpace(s.Name+" "+s.Value)))
+	}
+	for _, c := range ref.RefContent {
+		series = append(series, escape(c))
+	}
+	if len(series) > 0 {
+		parts = append(parts, `<span class="bibliography-series">`+strings.Join(series, ", ")+"</span>")
+	}
+	if d := ref.Front.Date; d != nil && d.Year != "" {
+		parts = append(parts, `<span class="bibliography-date">`+escape(strings.TrimSpace(d.Month+" "+d.Year))+"</span>")
+	}
+	if ref.Target != "" {
+		parts = append(parts, `<a class="bibliography-target" href="`+escape(ref.Target)+`">`+escape(ref.Target)+"</a>")
+	}
+	return strings.Join(parts, ", ") + "."
+}
+
+// displayAnchor removes the draft version from the anchor.
+func displayAnchor(anchor string) string {
+	if strings.HasPrefix(anchor, "I-D.") {
+		if hash := strings.Index(anchor, "#"); hash > 0 {
+			return anchor[:hash]
+		}
+	}
+	return anchor
+}
+
+// anchorTarget returns the URL for RFC and Internet-Draft anchors.
+func anchorTarget(anchor string) string {
+	switch {
+	case strings.HasPrefix(anchor, "RFC"):
+		num := strings.TrimLeft(anchor[3:], "0")
+		if num == "" || strings.Trim(num, "0123456789") != "" {
+			return ""
+		}
+		return "https://www.rfc-editor.org/info/rfc" + num
+	case strings.HasPrefix(anchor, "I-D."):
+		draft := displayAnchor(anchor)[4:]
+		return "https://datatracker.ietf.org/doc/draft-" + draft + "/"
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+package mhtml
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// calloutCodeBlock writes a code block that has a legend, see mparser.AddCallouts, with each callout
+// as a link to its item in the legend.
+func calloutCodeBlock(w io.Writer, codeBlock *ast.CodeBlock, legend *ast.List) {
+	attrs := []string{}
+	if lang := strings.Fields(string(codeBlock.Info)); len(lang) > 0 {
+		attrs = append(attrs, `class="language-`+lang[0]+`"`)
+	}
+	attrs = append(attrs, html.BlockAttrs(codeBlock)...)
+
+	io.WriteString(w, "<pre>"+html.TagWithAttributes("<code", attrs))
+	lines := bytes.Split(bytes.TrimSuffix(codeBlock.Literal, []byte("\n")), []byte("\n"))
+	for i, line := range lines {
+		ids, start := mast.CalloutMarkers(line)
+		if start < 0 {
+			html.EscapeHTML(w, line)
+		} else {
+			html.EscapeHTML(w, bytes.TrimRight(line[:start], " \t"))
+			for _, id := range ids {
+				fmt.Fprintf(w, ` <sup class="callout"><a href="#%s-%s">%s</a></sup>`, legend.Attribute.ID, id, id)
+			}
+		}
+		if i < len(lines)-1 || bytes.HasSuffix(codeBlock.Literal, []byte("\n")) {
+			io.WriteString(w, "\n")
+		}
+	}
+	io.WriteString(w, "</code></pre>\n")
+}
+
+// calloutItem writes the <li> of an item in a legend, with an id the callouts link to.
+func calloutItem(w io.Writer, item *ast.ListItem, entering bool) {
+	if !entering {
+		io.WriteString(w, "</li>\n")
+		return
+	}
+	list := item.Parent.(*ast.List)
+	n := 1
+	for _, c := range list.Children {
+		if c == item {
+			break
+		}
+		n++
+	}
+	fmt.Fprintf(w, `<li id="%s-%d">`, list.Attribute.ID, n)
+}
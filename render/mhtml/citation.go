@@ -0,0 +1,148 @@
+package mhtml
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/mast/reference"
+	"github.com/mmarkdown/mmark/v2/render/xml"
+)
+
+// CitationStyle is the style of the citations and the labels in the bibliography.
+type CitationStyle int
+
+const (
+	CiteAnchor     CitationStyle = iota // [RFC2119], the default
+	CiteNumeric                         // [1], numbered in the order of the bibliography
+	CiteAuthorYear                      // [Bradner 1997]
+)
+
+// Labels returns the labels for the bibliography items in doc in the citation style. For CiteAnchor
+// nil is returned, as the anchors are used as is.
+func Labels(doc ast.Node, style CitationStyle) map[string]string {
+	if style == CiteAnchor {
+		return nil
+	}
+	labels := map[string]string{}
+	seen := map[string]int{} // author-year labels, to make them unique
+	items := []*mast.BibliographyItem{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if b, ok := node.(*mast.BibliographyItem); ok && entering {
+			items = append(items, b)
+		}
+		return ast.GoToNext
+	})
+	for i, b := range items {
+		anchor := string(b.Anchor)
+		switch style {
+		case CiteNumeric:
+			labels[anchor] = fmt.Sprintf("%d", i+1)
+		case CiteAuthorYear:
+			if b.Reference == nil {
+				labels[anchor] = displayAnchor(anchor)
+				continue
+			}
+			labels[anchor] = authorYear(b.Reference)
+			seen[labels[anchor]]++
+		}
+	}
+	if style != CiteAuthorYear {
+		return labels
+	}
+
+	// Same authors in the same year get a suffix: Bradner 1997a, Bradner 1997b.
+	suffix := map[string]int{}
+	for _, b := range items {
+		anchor := string(b.Anchor)
+		l := labels[anchor]
+		if seen[l] < 2 {
+			continue
+		}
+		labels[anchor] = l + string(rune('a'+suffix[l]%26))
+		suffix[l]++
+	}
+	return labels
+}
+
+// authorYear returns the author-year label for ref: "Bradner 1997", "Bradner and Leiba 2017" or
+// "Gieben et al. 2019".
+func authorYear(ref *reference.Reference) string {
+	names := []string{}
+	for _, a := range ref.Front.Authors {
+		switch {
+		case a.Surname != "":
+			names = append(names, a.Surname)
+		case a.Fullname != "":
+			f := strings.Fields(a.Fullname)
+			names = append(names, f[len(f)-1])
+		case a.Organization != nil && a.Organization.Value != "":
+			names = append(names, a.Organization.Value)
+		}
+	}
+	label := ref.Anchor
+	switch len(names) {
+	case 0:
+	case 1:
+		label = names[0]
+	case 2:
+		label = names[0] + " and " + names[1]
+	default:
+		label = names[0] + " et al."
+	}
+	if d := ref.Front.Date; d != nil && d.Year != "" {
+		label += " " + d.Year
+	}
+	return label
+}
+
+// label returns the label for anchor.
+func (r RendererOptions) label(anchor string) string {
+	if l, ok := r.Labels[anchor]; ok {
+		return l
+	}
+	return displayAnchor(anchor)
+}
+
+// citation renders a citation that has a suffix or needs a label, and returns false if it should be
+// left to the html renderer.
+func (r RendererOptions) citation(w io.Writer, node *ast.Citation) bool {
+	suffix := false
+	for i := range node.Destination {
+		if len(node.Suffix) > i && len(node.Suffix[i]) > 0 {
+			suffix = true
+		}
+	}
+	if !suffix && r.Labels == nil {
+		return false
+	}
+
+	cites := []string{}
+	for i, c := range node.Destination {
+		class := "none"
+		switch node.Type[i] {
+		case ast.CitationTypeNormative:
+			class = "normative"
+		case ast.CitationTypeInformative:
+			class = "informative"
+		case ast.CitationTypeSuppressed:
+			class = "suppressed"
+		}
+		cite := fmt.Sprintf(`<a href="#%s"><sup>[%s]</sup></a>`, escape(string(c)), escape(r.label(string(c))))
+		if len(node.Suffix) > i {
+			s := xml.ParseSuffix(node.Suffix[i], r.Language)
+			label := escape(s.Label())
+			// link directly to the section of the published document.
+			if url := s.URL(string(c)); url != "" {
+				label = `<a class="section" href="` + escape(url) + `">` + label + "</a>"
+			}
+			s.Text = escape(s.Text)
+			cite = s.Join(cite, label, r.Language)
+		}
+		cites = append(cites, `<cite class="`+class+`">`+cite+"</cite>")
+	}
+	io.WriteString(w, strings.Join(cites, ", "))
+	return true
+}
@@ -0,0 +1,53 @@
+package mhtml
+
+import (
+	"testing"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/mast/reference"
+)
+
+func TestLabels(t *testing.T) {
+	ref := func(anchor, year string, surnames ...string) *reference.Reference {
+		r := &reference.Reference{Anchor: anchor}
+		for _, s := range surnames {
+			r.Front.Authors = append(r.Front.Authors, reference.Author{Surname: s})
+		}
+		r.Front.Date = &reference.Date{Year: year}
+		return r
+	}
+	bib := &mast.Bibliography{}
+	for _, b := range []*mast.BibliographyItem{
+		{Anchor: []byte("RFC2119"), Reference: ref("RFC2119", "1997", "Bradner")},
+		{Anchor: []byte("RFC2026"), Reference: ref("RFC2026", "1997", "Bradner")},
+		{Anchor: []byte("RFC8174"), Reference: ref("RFC8174", "2017", "Leiba")},
+		{Anchor: []byte("RFC7991"), Reference: ref("RFC7991", "2016", "Hoffman", "Smith", "Jones")},
+		{Anchor: []byte("I-D.foo#02")},
+	} {
+		ast.AppendChild(bib, b)
+	}
+
+	if labels := Labels(bib, CiteAnchor); labels != nil {
+		t.Errorf("expected no labels for the anchor style, got %v", labels)
+	}
+
+	numeric := Labels(bib, CiteNumeric)
+	if numeric["RFC2119"] != "1" || numeric["I-D.foo#02"] != "5" {
+		t.Errorf("unexpected numeric labels %v", numeric)
+	}
+
+	want := map[string]string{
+		"RFC2119":    "Bradner 1997a",
+		"RFC2026":    "Bradner 1997b",
+		"RFC8174":    "Leiba 2017",
+		"RFC7991":    "Hoffman et al. 2016",
+		"I-D.foo#02": "I-D.foo",
+	}
+	got := Labels(bib, CiteAuthorYear)
+	for anchor, label := range want {
+		if got[anchor] != label {
+			t.Errorf("expected %q for %s, got %q", label, anchor, got[anchor])
+		}
+	}
+}
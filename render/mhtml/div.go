@@ -0,0 +1,30 @@
+package mhtml
+
+import (
+	"io"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// div renders a fenced div, a div named "section" becomes a <section>, all others a <div> with the
+// name as its first class.
+func div(w io.Writer, div *mast.Div, entering bool) {
+	tag := "div"
+	if div.Name == "section" {
+		tag = "section"
+	}
+	if !entering {
+		io.WriteString(w, "</"+tag+">\n")
+		return
+	}
+	if ast.GetPrevNode(div) != nil {
+		io.WriteString(w, "\n")
+	}
+	if tag == "div" && div.Name != "" {
+		mast.AttributeInit(div)
+		div.Attribute.Classes = append([][]byte{[]byte(div.Name)}, div.Attribute.Classes...)
+	}
+	io.WriteString(w, html.TagWithAttributes("<"+tag, html.BlockAttrs(div))+"\n")
+}
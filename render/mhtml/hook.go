@@ -1,12 +1,17 @@
 package mhtml
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 
 	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
 	"github.com/mmarkdown/mmark/v2/lang"
 	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/render/diagram"
+	"github.com/mmarkdown/mmark/v2/render/tex"
+	"github.com/mmarkdown/mmark/v2/render/xml"
 )
 
 var (
@@ -17,6 +22,17 @@ var (
 // RenderOptions are options for RenderHook.
 type RendererOptions struct {
 	Language lang.Lang
+
+	// Labels are the labels of the citations and bibliography items, keyed by anchor, as returned by
+	// Labels. If nil the anchor is used.
+	Labels map[string]string
+
+	// Diagrams converts the diagrams in fenced code blocks to inline SVG.
+	Diagrams *diagram.Diagrams
+
+	// SVG, if set, returns the (sanitized) SVG of an image's destination, that is then inlined instead
+	// of referenced with an <img>. It returns nil to keep the <img>.
+	SVG func(src string) []byte
 }
 
 // RenderHook is used to render mmark specific AST nodes.
@@ -29,23 +45,108 @@ func (r RendererOptions) RenderHook(w io.Writer, node ast.Node, entering bool) (
 		}
 		io.WriteString(w, `<h1 id="footnote-section">`)
 		io.WriteString(w, r.Language.Footnotes())
-	case *mast.Bibliography, *mast.BibliographyWrapper:
-		if !entering {
-			io.WriteString(w, "</dl>\n")
-			return ast.GoToNext, true
+	case *mast.BibliographyWrapper:
+		if entering {
+			io.WriteString(w, "<h1 id=\"bibliography-section\">"+r.Language.Bibliography()+"</h1>\n")
 		}
-		io.WriteString(w, "<h1 id=\"bibliography-section\">"+r.Language.Bibliography()+"</h1>\n<div class=\"bibliography\">\n")
-		io.WriteString(w, "<dl>\n")
+		return ast.GoToNext, true
+	case *mast.Bibliography:
+		r.bibliography(w, node, entering)
 		return ast.GoToNext, true
 	case *mast.BibliographyItem:
 		if !entering {
 			return ast.GoToNext, true
 		}
-		bibliographyItem(w, node, entering)
+		r.bibliographyItem(w, node, entering)
 		return ast.GoToNext, true
 	case *mast.Title:
 		// we out if in mmark.go with a hack to capture it.
 		return ast.GoToNext, true
+	case *ast.CodeBlock:
+		d := r.Diagrams.Diagram(node)
+		if d == nil {
+			if legend := mast.CalloutLegend(node); legend != nil && legend.Attribute != nil {
+				calloutCodeBlock(w, node, legend)
+				return ast.GoToNext, true
+			}
+			return ast.GoToNext, false
+		}
+		io.WriteString(w, `<div class="diagram">`+"\n")
+		w.Write(d.SVG)
+		io.WriteString(w, "\n</div>\n")
+		return ast.GoToNext, true
+	case *ast.List:
+		if !entering || !styledList(node) {
+			return ast.GoToNext, false
+		}
+		definitionList(w, node)
+		return ast.GoToNext, true
+	case *ast.ListItem:
+		list, ok := node.Parent.(*ast.List)
+		if ok && entering && styledList(list) {
+			definitionItem(w, node, list)
+			return ast.GoToNext, true
+		}
+		if !ok || list.Delimiter != mast.CalloutDelimiter || list.Attribute == nil {
+			return ast.GoToNext, false
+		}
+		calloutItem(w, node, entering)
+		return ast.GoToNext, true
+	case *ast.Strong:
+		if t, ok := ast.GetFirstChild(node).(*ast.Text); !ok || !xml.Is2119(t.Literal) {
+			return ast.GoToNext, false
+		}
+		if entering {
+			io.WriteString(w, `<strong class="bcp14">`)
+		} else {
+			io.WriteString(w, "</strong>")
+		}
+		return ast.GoToNext, true
+	case *mast.Cref:
+		io.WriteString(w, `<mark class="cref">`)
+		html.EscapeHTML(w, []byte(node.String()))
+		io.WriteString(w, "</mark>")
+		return ast.GoToNext, true
+	case *mast.Abbreviation:
+		io.WriteString(w, `<abbr title="`)
+		html.EscapeHTML(w, []byte(node.Title))
+		io.WriteString(w, `">`)
+		html.EscapeHTML(w, node.Literal)
+		io.WriteString(w, "</abbr>")
+		return ast.GoToNext, true
+	case *mast.Unicode:
+		fmt.Fprintf(w, `<span class="unicode" title="%s">`, node.Format)
+		html.EscapeHTML(w, []byte(node.String()))
+		io.WriteString(w, "</span>")
+		return ast.GoToNext, true
+	case *ast.Math:
+		io.WriteString(w, tex.MathML(string(node.Literal), false))
+		return ast.GoToNext, true
+	case *ast.MathBlock:
+		if entering {
+			io.WriteString(w, tex.MathML(string(bytes.TrimSpace(node.Literal)), true)+"\n")
+		}
+		return ast.GoToNext, true
+	case *ast.TableCell:
+		if mast.Attribute(node, "rowspan") == nil {
+			return ast.GoToNext, false
+		}
+		tableCell(w, node, entering)
+		return ast.GoToNext, true
+	case *mast.Div:
+		div(w, node, entering)
+		return ast.GoToNext, true
+	case *ast.BlockQuote:
+		if mast.Attribute(node, "quotedFrom") == nil {
+			return ast.GoToNext, false
+		}
+		blockQuote(w, node, entering)
+		return ast.GoToNext, true
+	case *ast.Image:
+		if r.SVG == nil || !r.image(w, node, entering) {
+			return ast.GoToNext, false
+		}
+		return ast.SkipChildren, true
 	case *mast.DocumentIndex:
 		if !entering {
 			io.WriteString(w, "\n</div>\n")
@@ -63,67 +164,85 @@ func (r RendererOptions) RenderHook(w io.Writer, node ast.Node, entering bool) (
 		// use id= idxref idxitm.
 		io.WriteString(w, "<dl>\n")
 		io.WriteString(w, `<dt>`)
-		io.WriteString(w, string(node.Literal))
+		html.EscapeHTML(w, node.Literal)
 		io.WriteString(w, "</dt>\n")
 		io.WriteString(w, "<dd>\n")
 		io.WriteString(w, "<ul>\n")
 		return ast.GoToNext, true
 	case *mast.IndexItem:
 		if !entering {
+			r.indexSee(w, node.See, node.SeeAlso)
 			io.WriteString(w, "</li>\n")
 			return ast.GoToNext, true
 		}
 		io.WriteString(w, "<li>\n")
-		w.Write(node.Item)
+		html.EscapeHTML(w, node.Item)
 		return ast.GoToNext, true
 	case *mast.IndexSubItem:
 		if !entering {
+			r.indexSee(w, node.See, node.SeeAlso)
+			io.WriteString(w, "</li>\n")
 			if lastSubItem(node) {
 				io.WriteString(w, "</ul>\n")
 			}
-			io.WriteString(w, "</li>\n")
 			return ast.GoToNext, true
 		}
 		if firstSubItem(node) {
 			io.WriteString(w, "<ul>\n")
 		}
 		io.WriteString(w, "<li>\n")
-		w.Write(node.Subitem)
+		html.EscapeHTML(w, node.Subitem)
 		return ast.GoToNext, true
 	case *mast.IndexLink:
 		if !entering {
 			io.WriteString(w, "</a>")
 			return ast.GoToNext, true
 		}
-		io.WriteString(w, ` <a class="index-return" href="#`+string(node.Destination)+`">`)
+		// the end of a range is joined to its start: [go]–[go].
+		sep := " "
+		if node.End {
+			sep = "&ndash;"
+		}
+		class := "index-return"
+		if node.Primary {
+			class += " index-primary"
+		}
+		io.WriteString(w, sep+`<a class="`+class+`" href="#`+string(node.Destination)+`">`)
 		io.WriteString(w, IndexReturnLinkContents)
 		return ast.GoToNext, true
 	case *mast.ReferenceBlock:
 		// ignore these for HTML output as this is XML and not used at all.
 		return ast.GoToNext, true
+	case *ast.Citation:
+		// only citations with a suffix or a citation style are handled here, the rest is left to
+		// the html renderer.
+		return ast.GoToNext, r.citation(w, node)
 	}
 	return ast.GoToNext, false
 }
 
-func bibliographyItem(w io.Writer, bib *mast.BibliographyItem, entering bool) {
-	io.WriteString(w, `<dt class="bibliography-cite" id="`+string(bib.Anchor)+`">`+fmt.Sprintf("[%s]", bib.Anchor)+"</dt>\n")
-	io.WriteString(w, `<dd>`)
-	defer io.WriteString(w, "</dd>\n")
-	if bib.Reference == nil {
-		return
-	}
-	for _, author := range bib.Reference.Front.Authors {
-		io.WriteString(w, `<span class="bibliography-author">`+author.Fullname+"</span>\n")
-	}
-	io.WriteString(w, `<span class="bibliography-title">`+bib.Reference.Front.Title+"</span>\n")
-	if bib.Reference.Target != "" {
-		io.WriteString(w, `<a class="bliography-target" href="`+bib.Reference.Target+"\">"+bib.Reference.Target+"</a>\n")
-	}
-	if bib.Reference.Front.Date.Year != "" {
-		io.WriteString(w, `<date class="bibliography-date">`+bib.Reference.Front.Date.Year+"</date>\n")
+// indexSee renders the "see" and "see also" redirects of an index item.
+func (r RendererOptions) indexSee(w io.Writer, see, seeAlso [][]byte) {
+	for i, list := range [][][]byte{see, seeAlso} {
+		if len(list) == 0 {
+			continue
+		}
+		term := r.Language.See()
+		if i == 1 {
+			term = r.Language.SeeAlso()
+		}
+		io.WriteString(w, `, <span class="index-see"><em>`+escape(term)+"</em> ")
+		html.EscapeHTML(w, bytes.Join(list, []byte("; ")))
+		io.WriteString(w, "</span>")
 	}
 }
 
+func escape(s string) string {
+	buf := &bytes.Buffer{}
+	html.EscapeHTML(buf, []byte(s))
+	return buf.String()
+}
+
 func firstSubItem(node ast.Node) bool {
 	prev := ast.GetPrevNode(node)
 	if prev == nil {
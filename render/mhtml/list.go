@@ -0,0 +1,84 @@
+package mhtml
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// DefinitionListAttributes are the RFC 7991 attributes of a definition list that are translated to CSS
+// in the HTML output.
+var DefinitionListAttributes = []string{"newline", "spacing", "indent"}
+
+// styledList returns true if list is a definition list with one of the DefinitionListAttributes.
+func styledList(list *ast.List) bool {
+	if list.ListFlags&ast.ListTypeDefinition == 0 {
+		return false
+	}
+	for _, a := range DefinitionListAttributes {
+		if mast.Attribute(list, a) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// definitionList opens the <dl> of a styled list, without the DefinitionListAttributes.
+func definitionList(w io.Writer, list *ast.List) {
+	if ast.GetPrevNode(list) != nil {
+		io.WriteString(w, "\n")
+	}
+	attrs := []string{}
+Attrs:
+	for _, a := range html.BlockAttrs(list) {
+		for _, dl := range DefinitionListAttributes {
+			if strings.HasPrefix(a, dl+"=") {
+				continue Attrs
+			}
+		}
+		attrs = append(attrs, a)
+	}
+	io.WriteString(w, html.TagWithAttributes("<dl", attrs)+"\n")
+}
+
+// definitionItem opens the <dt> or <dd> of an item of a styled list: with newline="false" the term
+// floats left of the definition (as in xml2rfc), indent="N" indents the definition N characters and
+// spacing sets the space after it.
+func definitionItem(w io.Writer, item *ast.ListItem, list *ast.List) {
+	if html.ListItemOpenCR(item) {
+		io.WriteString(w, "\n")
+	}
+	indent := string(mast.Attribute(list, "indent"))
+	newline := string(mast.Attribute(list, "newline"))
+	if item.ListFlags&ast.ListTypeTerm != 0 {
+		if newline == "false" {
+			io.WriteString(w, `<dt style="float: left; clear: left; margin-right: 1ch">`)
+			return
+		}
+		io.WriteString(w, "<dt>")
+		return
+	}
+
+	style := []string{}
+	switch {
+	case indent != "" && strings.Trim(indent, "0123456789") == "":
+		style = append(style, fmt.Sprintf("margin-left: %sch", indent))
+	case newline == "false":
+		style = append(style, "margin-left: 3ch") // the default indent of xml2rfc
+	}
+	switch string(mast.Attribute(list, "spacing")) {
+	case "compact":
+		style = append(style, "margin-bottom: 0")
+	case "normal":
+		style = append(style, "margin-bottom: 1em")
+	}
+	if len(style) == 0 {
+		io.WriteString(w, "<dd>")
+		return
+	}
+	io.WriteString(w, `<dd style="`+strings.Join(style, "; ")+`">`)
+}
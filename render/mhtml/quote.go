@@ -0,0 +1,39 @@
+package mhtml
+
+import (
+	"io"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// blockQuote renders a block quote with a quotedFrom attribute, which isn't valid HTML, as a
+// blockquote with a <footer> holding the attribution in a <cite>. If the quote has a cite attribute as
+// well, the attribution links to it.
+func blockQuote(w io.Writer, quote *ast.BlockQuote, entering bool) {
+	from := mast.Attribute(quote, "quotedFrom")
+	if entering {
+		attrs := []string{}
+		for _, a := range html.BlockAttrs(quote) {
+			if !strings.HasPrefix(a, "quotedFrom=") {
+				attrs = append(attrs, a)
+			}
+		}
+		io.WriteString(w, html.TagWithAttributes("<blockquote", attrs)+"\n")
+		return
+	}
+	io.WriteString(w, "<footer>&mdash; <cite>")
+	cite := mast.Attribute(quote, "cite")
+	if cite != nil {
+		io.WriteString(w, `<a href="`)
+		html.EscLink(w, cite)
+		io.WriteString(w, `">`)
+	}
+	html.EscapeHTML(w, from)
+	if cite != nil {
+		io.WriteString(w, "</a>")
+	}
+	io.WriteString(w, "</cite></footer>\n</blockquote>\n")
+}
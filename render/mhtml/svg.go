@@ -0,0 +1,25 @@
+package mhtml
+
+import (
+	"io"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// image inlines the SVG of image, if RendererOptions.SVG returns one. The alt text is not rendered. It
+// returns true if the image was handled.
+func (r RendererOptions) image(w io.Writer, image *ast.Image, entering bool) bool {
+	if !entering {
+		return mast.Attribute(image, "svg") != nil
+	}
+	svg := r.SVG(string(image.Destination))
+	if svg == nil {
+		return false
+	}
+	w.Write(svg)
+	// remember that we've done this image for the exit.
+	mast.AttributeInit(image)
+	mast.SetAttribute(image, "svg", []byte("inline"))
+	return true
+}
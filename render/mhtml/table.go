@@ -0,0 +1,37 @@
+package mhtml
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// tableCell renders a table cell that spans rows, the html renderer has no rowspan. Cells covered by
+// such a cell are not rendered.
+func tableCell(w io.Writer, cell *ast.TableCell, entering bool) {
+	span := mast.RowSpan(cell)
+	if span == 0 {
+		return
+	}
+	tag := "td"
+	if cell.IsHeader {
+		tag = "th"
+	}
+	if !entering {
+		io.WriteString(w, "</"+tag+">\n")
+		return
+	}
+	if ast.GetPrevNode(cell) == nil {
+		io.WriteString(w, "\n")
+	}
+	io.WriteString(w, "<"+tag)
+	if align := cell.Align.String(); align != "" {
+		fmt.Fprintf(w, ` align="%s"`, align)
+	}
+	if cell.ColSpan > 0 {
+		fmt.Fprintf(w, ` colspan="%d"`, cell.ColSpan)
+	}
+	fmt.Fprintf(w, ` rowspan="%d">`, span)
+}
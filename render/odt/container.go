@@ -0,0 +1,133 @@
+package odt
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// MediaTypes maps file extensions of images to their media types.
+var MediaTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".svg":  "image/svg+xml",
+}
+
+func mediaType(file string) string {
+	if m, ok := MediaTypes[strings.ToLower(path.Ext(file))]; ok {
+		return m
+	}
+	return "application/octet-stream"
+}
+
+const mimetype = "application/vnd.oasis.opendocument.text"
+
+// write writes the ODT package to w.
+func (r *Renderer) write(w io.Writer) {
+	z := zip.NewWriter(w)
+	// The mimetype file must be first and must not be compressed.
+	f, _ := z.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store, Modified: r.opts.Modified})
+	io.WriteString(f, mimetype)
+
+	create := func(name string, data []byte) {
+		f, err := z.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: r.opts.Modified})
+		if err != nil {
+			r.opts.Report.Reportf(mast.SeverityError, "write", "couldn't create %q in the ODT package: %s", name, err)
+			return
+		}
+		f.Write(data)
+	}
+	create("META-INF/manifest.xml", r.manifest())
+	create("content.xml", r.contentDocument())
+	create("styles.xml", []byte(styles))
+	create("meta.xml", r.meta())
+	for _, p := range r.images {
+		create(p.href, p.data)
+	}
+	if err := z.Close(); err != nil {
+		r.opts.Report.Reportf(mast.SeverityError, "write", "couldn't write ODT package: %s", err)
+	}
+}
+
+// manifest returns the manifest, listing all files in the package.
+func (r *Renderer) manifest() []byte {
+	b := &bytes.Buffer{}
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString(`<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2">` + "\n")
+	fmt.Fprintf(b, "  <manifest:file-entry manifest:full-path=\"/\" manifest:version=\"1.2\" manifest:media-type=\"%s\"/>\n", mimetype)
+	for _, f := range []string{"content.xml", "styles.xml", "meta.xml"} {
+		fmt.Fprintf(b, "  <manifest:file-entry manifest:full-path=\"%s\" manifest:media-type=\"text/xml\"/>\n", f)
+	}
+	for _, p := range r.images {
+		fmt.Fprintf(b, "  <manifest:file-entry manifest:full-path=\"%s\" manifest:media-type=\"%s\"/>\n", Escape(p.href), p.mediaType)
+	}
+	b.WriteString("</manifest:manifest>\n")
+	return b.Bytes()
+}
+
+// contentDocument wraps the rendered text in a complete content.xml.
+func (r *Renderer) contentDocument() []byte {
+	b := &bytes.Buffer{}
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString("<office:document-content " + namespaces + ">\n<office:body>\n<office:text>\n")
+	b.Write(r.content.Bytes())
+	b.WriteString("</office:text>\n</office:body>\n</office:document-content>\n")
+	return b.Bytes()
+}
+
+// meta returns meta.xml with the title, authors, language and keywords of the document.
+func (r *Renderer) meta() []byte {
+	b := &bytes.Buffer{}
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString("<office:document-meta " + namespaces + ">\n<office:meta>\n")
+	b.WriteString("  <meta:generator>mmark</meta:generator>\n")
+	if r.Title != nil && r.Title.TitleData != nil {
+		fmt.Fprintf(b, "  <dc:title>%s</dc:title>\n", Escape(r.Title.Title))
+		for _, a := range r.Title.Author {
+			if a.Fullname != "" {
+				fmt.Fprintf(b, "  <meta:initial-creator>%s</meta:initial-creator>\n", Escape(a.Fullname))
+				break
+			}
+		}
+		for _, k := range r.Title.Keyword {
+			fmt.Fprintf(b, "  <meta:keyword>%s</meta:keyword>\n", Escape(k))
+		}
+	}
+	if l := r.opts.Language.String(); l != "" {
+		fmt.Fprintf(b, "  <dc:language>%s</dc:language>\n", l)
+	}
+	fmt.Fprintf(b, "  <dc:date>%s</dc:date>\n", r.opts.Modified.UTC().Format("2006-01-02T15:04:05"))
+	b.WriteString("</office:meta>\n</office:document-meta>\n")
+	return b.Bytes()
+}
+
+// titleBlock outputs the title, the authors and the date of the document.
+func (r *Renderer) titleBlock(w io.Writer, t *mast.Title) {
+	if t.TitleData == nil {
+		return
+	}
+	r.outs(w, `<text:p text:style-name="Title">`+Escape(t.Title)+"</text:p>\n")
+	authors := []string{}
+	for _, a := range t.Author {
+		name := a.Fullname
+		if a.Organization != "" {
+			name += ", " + a.Organization
+		}
+		if name != "" {
+			authors = append(authors, name)
+		}
+	}
+	if len(authors) > 0 {
+		r.outs(w, `<text:p text:style-name="Subtitle">`+Escape(strings.Join(authors, "; "))+"</text:p>\n")
+	}
+	if !t.Date.IsZero() {
+		r.outs(w, `<text:p text:style-name="Subtitle">`+t.Date.Format("2 January 2006")+"</text:p>\n")
+	}
+}
@@ -0,0 +1,95 @@
+package odt
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+func (r *Renderer) outs(w io.Writer, s string) { io.WriteString(w, s) }
+
+func (r *Renderer) outOneOf(w io.Writer, outFirst bool, first string, second string) {
+	if outFirst {
+		r.outs(w, first)
+	} else {
+		r.outs(w, second)
+	}
+}
+
+// Escape escapes the XML special characters in s.
+func Escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(s)
+}
+
+// preserve escapes s and keeps its white space: runs of spaces become <text:s/> and tabs <text:tab/>,
+// because white space is collapsed in ODF text.
+func preserve(s string) string {
+	b := &strings.Builder{}
+	spaces := 0
+	flush := func() {
+		switch {
+		case spaces == 1 && b.Len() > 0:
+			b.WriteByte(' ')
+		case spaces == 1:
+			b.WriteString("<text:s/>")
+		case spaces > 1:
+			if b.Len() > 0 {
+				b.WriteByte(' ')
+				spaces--
+			}
+			b.WriteString(fmt.Sprintf(`<text:s text:c="%d"/>`, spaces))
+		}
+		spaces = 0
+	}
+	for _, c := range s {
+		switch c {
+		case ' ':
+			spaces++
+			continue
+		case '\t':
+			flush()
+			b.WriteString("<text:tab/>")
+			continue
+		}
+		flush()
+		b.WriteString(Escape(string(c)))
+	}
+	flush()
+	return b.String()
+}
+
+// nodeID returns the ID from the attribute of node, or the heading ID.
+func nodeID(node ast.Node) string {
+	if h, ok := node.(*ast.Heading); ok {
+		return h.HeadingID
+	}
+	var a *ast.Attribute
+	if c := node.AsContainer(); c != nil {
+		a = c.Attribute
+	}
+	if l := node.AsLeaf(); l != nil {
+		a = l.Attribute
+	}
+	if a == nil {
+		return ""
+	}
+	return string(a.ID)
+}
+
+// plain returns the text of node's children, without markup.
+func plain(node ast.Node) string {
+	buf := &bytes.Buffer{}
+	ast.WalkFunc(node, func(n ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		if l := n.AsLeaf(); l != nil {
+			buf.Write(l.Literal)
+		}
+		return ast.GoToNext
+	})
+	return buf.String()
+}
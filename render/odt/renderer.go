@@ -0,0 +1,686 @@
+// The package odt outputs an OpenDocument text (ODT) file from mmark markdown. The document uses named
+// styles for headings, code, tables and the other elements, and includes a table of contents that
+// is updated by the word processor.
+package odt
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif" // register image formats for DecodeConfig
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/v2/lang"
+	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/mast/reference"
+	"github.com/mmarkdown/mmark/v2/render/xml"
+)
+
+// RendererOptions is a collection of supplementary parameters tweaking
+// the behavior of various parts of the ODT renderer.
+type RendererOptions struct {
+	Language lang.Lang // Output language for the document.
+
+	Dir      string    // Directory relative to which images are read.
+	Modified time.Time // Modification time of the files in the package, defaults to now.
+
+	// if set, called at the start of RenderNode(). Allows replacing rendering of some nodes
+	RenderNodeHook html.RenderNodeFunc
+
+	// Report, if set, is called with the problems found while rendering, otherwise these are logged.
+	Report mast.Reporter
+}
+
+// Renderer implements Renderer interface for ODT output.
+type Renderer struct {
+	opts RendererOptions
+
+	Title *mast.Title
+
+	content *bytes.Buffer
+	styles  []string // paragraph styles, the last one is used for paragraphs
+	tables  int      // table counter, tables need a name
+	notes   int      // footnote counter
+	images  []picture
+	xrefs   map[string]string // anchor to heading text
+	toc     []tocEntry
+	tocDone bool
+}
+
+// tocEntry is an entry in the table of contents.
+type tocEntry struct {
+	level int
+	title string
+}
+
+// picture is an image that is included in the package.
+type picture struct {
+	href, mediaType string
+	data            []byte
+}
+
+// NewRenderer creates and configures an Renderer object, which satisfies the Renderer interface.
+func NewRenderer(opts RendererOptions) *Renderer {
+	if opts.Modified.IsZero() {
+		opts.Modified = time.Now()
+	}
+	return &Renderer{
+		opts:    opts,
+		content: &bytes.Buffer{},
+		styles:  []string{"Text_20_body"},
+		xrefs:   map[string]string{},
+	}
+}
+
+func (r *Renderer) pushStyle(s string) { r.styles = append(r.styles, s) }
+func (r *Renderer) popStyle()          { r.styles = r.styles[:len(r.styles)-1] }
+func (r *Renderer) style() string      { return r.styles[len(r.styles)-1] }
+
+// paragraphOpen opens a paragraph with the current style.
+func (r *Renderer) paragraphOpen(w io.Writer) {
+	r.outs(w, `<text:p text:style-name="`+r.style()+`">`)
+}
+
+func (r *Renderer) heading(w io.Writer, node *ast.Heading, entering bool) {
+	if !entering {
+		r.outs(w, "</text:h>\n")
+		return
+	}
+	if !node.IsSpecial && r.toc != nil {
+		r.tocBlock(w)
+	}
+	level := node.Level
+	if level > 6 {
+		level = 6
+	}
+	attrs := fmt.Sprintf(` text:style-name="Heading_20_%d" text:outline-level="%d"`, level, level)
+	if node.IsSpecial || node.IsTitleblock {
+		attrs += ` text:is-list-header="true"`
+	}
+	r.outs(w, "<text:h"+attrs+">")
+	r.bookmark(w, node.HeadingID)
+}
+
+// sectionTitle outputs a heading with title, for sections we generate ourselves.
+func (r *Renderer) sectionTitle(w io.Writer, level int, title string, special bool) {
+	attrs := fmt.Sprintf(` text:style-name="Heading_20_%d" text:outline-level="%d"`, level, level)
+	if special {
+		attrs += ` text:is-list-header="true"`
+	}
+	r.outs(w, "<text:h"+attrs+">"+Escape(title)+"</text:h>\n")
+}
+
+// bookmark outputs a bookmark for id, these are the targets of cross references.
+func (r *Renderer) bookmark(w io.Writer, id string) {
+	if id != "" {
+		r.outs(w, `<text:bookmark text:name="`+Escape(id)+`"/>`)
+	}
+}
+
+// tocBlock outputs the table of contents, with the entries filled in. The page numbers are added
+// when the table of contents is updated in the word processor.
+func (r *Renderer) tocBlock(w io.Writer) {
+	if r.tocDone || r.Title == nil {
+		return
+	}
+	r.tocDone = true
+	title := Escape(r.opts.Language.Contents())
+	r.outs(w, `<text:table-of-content text:style-name="Standard" text:protected="true" text:name="Contents">`+"\n")
+	r.outs(w, `<text:table-of-content-source text:outline-level="3">`)
+	r.outs(w, `<text:index-title-template text:style-name="Contents_20_Heading">`+title+`</text:index-title-template>`)
+	for i := 1; i <= 3; i++ {
+		r.outs(w, fmt.Sprintf(`<text:table-of-content-entry-template text:outline-level="%d" text:style-name="Contents_20_%d">`, i, i))
+		r.outs(w, `<text:index-entry-link-start/><text:index-entry-chapter/><text:index-entry-text/><text:index-entry-tab-stop style:type="right" style:leader-char="."/><text:index-entry-page-number/><text:index-entry-link-end/>`)
+		r.outs(w, `</text:table-of-content-entry-template>`)
+	}
+	r.outs(w, "</text:table-of-content-source>\n<text:index-body>\n")
+	r.outs(w, `<text:index-title text:style-name="Standard" text:name="Contents_Head"><text:p text:style-name="Contents_20_Heading">`+title+"</text:p></text:index-title>\n")
+	for _, e := range r.toc {
+		r.outs(w, fmt.Sprintf(`<text:p text:style-name="Contents_20_%d">%s</text:p>`+"\n", e.level, Escape(e.title)))
+	}
+	r.outs(w, "</text:index-body>\n</text:table-of-content>\n")
+}
+
+func (r *Renderer) paragraph(w io.Writer, node *ast.Paragraph, entering bool) {
+	if !entering {
+		r.outs(w, "</text:p>\n")
+		return
+	}
+	r.paragraphOpen(w)
+	r.bookmark(w, nodeID(node))
+}
+
+func (r *Renderer) list(w io.Writer, node *ast.List, entering bool) {
+	if node.IsFootnotesList {
+		return
+	}
+	if node.ListFlags&ast.ListTypeDefinition != 0 {
+		return
+	}
+	if !entering {
+		r.outs(w, "</text:list>\n")
+		r.popStyle()
+		return
+	}
+	style := "Bullets"
+	if node.ListFlags&ast.ListTypeOrdered != 0 {
+		style = "Numbering"
+	}
+	r.outs(w, `<text:list text:style-name="`+style+`">`+"\n")
+	r.pushStyle("List_20_Contents")
+}
+
+func (r *Renderer) listItem(w io.Writer, node *ast.ListItem, entering bool) {
+	wrap := !hasBlock(node) // terms and tight items hold text directly
+	if node.ListFlags&ast.ListTypeDefinition != 0 || node.ListFlags&ast.ListTypeTerm != 0 {
+		if !entering {
+			if wrap {
+				r.outs(w, "</text:p>\n")
+			}
+			r.popStyle()
+			return
+		}
+		if node.ListFlags&ast.ListTypeTerm != 0 {
+			r.pushStyle("Definition_20_Term")
+		} else {
+			r.pushStyle("Definition")
+		}
+		if wrap {
+			r.paragraphOpen(w)
+		}
+		return
+	}
+	if !entering {
+		if wrap {
+			r.outs(w, "</text:p>")
+		}
+		r.outs(w, "</text:list-item>\n")
+		return
+	}
+	r.outs(w, "<text:list-item")
+	if list, ok := node.Parent.(*ast.List); ok && list.Start > 1 && ast.GetPrevNode(node) == nil {
+		r.outs(w, fmt.Sprintf(` text:start-value="%d"`, list.Start))
+	}
+	r.outs(w, ">")
+	if wrap {
+		r.paragraphOpen(w)
+	}
+}
+
+// hasBlock returns true if node has a block level child.
+func hasBlock(node ast.Node) bool {
+	for _, c := range node.GetChildren() {
+		switch c.(type) {
+		case *ast.Paragraph, *ast.List, *ast.CodeBlock, *ast.Table, *ast.BlockQuote, *ast.Aside,
+			*ast.CaptionFigure, *ast.MathBlock, *ast.HTMLBlock, *ast.HorizontalRule, *ast.Heading:
+			return true
+		}
+	}
+	return false
+}
+
+// preformatted outputs literal as preformatted text, a paragraph per line.
+func (r *Renderer) preformatted(w io.Writer, node ast.Node, literal []byte) {
+	lines := strings.Split(strings.TrimRight(string(literal), "\n"), "\n")
+	for i, l := range lines {
+		r.outs(w, `<text:p text:style-name="Preformatted_20_Text">`)
+		if i == 0 {
+			r.bookmark(w, nodeID(node))
+		}
+		r.outs(w, preserve(l)+"</text:p>\n")
+	}
+}
+
+func (r *Renderer) table(w io.Writer, tab *ast.Table, entering bool) {
+	if !entering {
+		r.outs(w, "</table:table>\n")
+		return
+	}
+	r.tables++
+	name := fmt.Sprintf("Table%d", r.tables)
+	if id := nodeID(tab); id != "" {
+		name = id
+	}
+	r.outs(w, `<table:table table:name="`+Escape(name)+`" table:style-name="Table">`)
+	r.outs(w, fmt.Sprintf(`<table:table-column table:number-columns-repeated="%d"/>`+"\n", columns(tab)))
+}
+
+// columns returns the number of columns of a table, derived from the first row.
+func columns(tab *ast.Table) int {
+	n := 0
+	ast.WalkFunc(tab, func(node ast.Node, entering bool) ast.WalkStatus {
+		row, ok := node.(*ast.TableRow)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		for _, c := range row.GetChildren() {
+			if span := c.(*ast.TableCell).ColSpan; span > 1 {
+				n += span
+				continue
+			}
+			n++
+		}
+		return ast.Terminate
+	})
+	return n
+}
+
+func (r *Renderer) tableCell(w io.Writer, cell *ast.TableCell, entering bool) {
+	if !entering {
+		r.outs(w, "</text:p></table:table-cell>")
+		for i := 1; i < cell.ColSpan; i++ {
+			r.outs(w, "<table:covered-table-cell/>")
+		}
+		r.outs(w, "\n")
+		return
+	}
+	r.outs(w, `<table:table-cell table:style-name="TableCell" office:value-type="string"`)
+	if cell.ColSpan > 1 {
+		r.outs(w, fmt.Sprintf(` table:number-columns-spanned="%d"`, cell.ColSpan))
+	}
+	r.outs(w, ">")
+	style := "Table_20_Contents"
+	if cell.IsHeader {
+		style = "Table_20_Heading"
+	}
+	r.outs(w, `<text:p text:style-name="`+style+`">`)
+}
+
+func (r *Renderer) citation(w io.Writer, node *ast.Citation) {
+	cites := 0
+	for i, c := range node.Destination {
+		if node.Type[i] == ast.CitationTypeSuppressed {
+			continue
+		}
+		if cites > 0 {
+			r.outs(w, ", ")
+		}
+		cites++
+		if a := xml.AuthorFromTitle(c, r.Title); a != nil {
+			r.outs(w, Escape(a.Fullname))
+			continue
+		}
+		if a := xml.ContactFromTitle(c, r.Title); a != nil {
+			r.outs(w, Escape(a.Fullname))
+			continue
+		}
+		r.outs(w, `<text:a xlink:type="simple" xlink:href="#`+Escape(string(c))+`">[`+Escape(string(c))+"]</text:a>")
+		if len(node.Suffix) > i && len(node.Suffix[i]) > 0 {
+			r.outs(w, ", "+Escape(string(node.Suffix[i])))
+		}
+	}
+}
+
+// footnote outputs the footnote that link refers to as a note.
+func (r *Renderer) footnote(w io.Writer, link *ast.Link) {
+	r.notes++
+	r.outs(w, fmt.Sprintf(`<text:note text:id="ftn%d" text:note-class="footnote"><text:note-citation>%d</text:note-citation><text:note-body>`, r.notes, r.notes))
+	r.pushStyle("Footnote")
+	if link.Footnote != nil {
+		wrap := !hasBlock(link.Footnote)
+		if wrap {
+			r.paragraphOpen(w)
+		}
+		for _, c := range link.Footnote.GetChildren() {
+			ast.WalkFunc(c, func(n ast.Node, entering bool) ast.WalkStatus {
+				return r.RenderNode(w, n, entering)
+			})
+		}
+		if wrap {
+			r.outs(w, "</text:p>")
+		}
+	}
+	r.popStyle()
+	r.outs(w, "</text:note-body></text:note>")
+}
+
+func (r *Renderer) image(w io.Writer, img *ast.Image) {
+	alt := plain(img)
+	dest := string(img.Destination)
+	width, height := 10.0, 7.5 // cm, used when we can't determine the size.
+
+	href := dest
+	if !strings.Contains(dest, "://") {
+		data, err := ioutil.ReadFile(filepath.Join(r.opts.Dir, filepath.FromSlash(dest)))
+		if err != nil {
+			r.opts.Report.Reportf(mast.SeverityError, "image", "couldn't open image %q: %s", dest, err)
+		} else {
+			href = fmt.Sprintf("Pictures/image%d%s", len(r.images)+1, strings.ToLower(path.Ext(dest)))
+			r.images = append(r.images, picture{href: href, mediaType: mediaType(dest), data: data})
+			if c, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil && c.Width > 0 {
+				// 96 dpi, but never wider than the text
+				width, height = float64(c.Width)*2.54/96, float64(c.Height)*2.54/96
+				if width > 17 {
+					width, height = 17, height*17/width
+				}
+			}
+		}
+	}
+	r.outs(w, fmt.Sprintf(`<draw:frame draw:name="Image%d" text:anchor-type="as-char" svg:width="%.2fcm" svg:height="%.2fcm">`, len(r.images)+1, width, height))
+	r.outs(w, `<draw:image xlink:href="`+Escape(href)+`" xlink:type="simple" xlink:show="embed" xlink:actuate="onLoad"/>`)
+	if alt != "" {
+		r.outs(w, "<svg:desc>"+Escape(alt)+"</svg:desc>")
+	}
+	r.outs(w, "</draw:frame>")
+}
+
+func (r *Renderer) bibliography(w io.Writer, node ast.Node, entering bool) {
+	if !entering || len(node.GetChildren()) == 0 {
+		return
+	}
+	r.tocBlock(w)
+	title := r.opts.Language.Bibliography()
+	level := 1
+	if b, ok := node.(*mast.Bibliography); ok {
+		switch b.Type {
+		case ast.CitationTypeNormative:
+			title = "Normative References"
+		case ast.CitationTypeInformative:
+			title = "Informative References"
+		}
+		if _, ok := b.Parent.(*mast.BibliographyWrapper); ok {
+			level = 2
+		}
+	}
+	r.sectionTitle(w, level, title, false)
+}
+
+// bibliographyItem outputs a reference with a bookmark for the anchor, so citations can link to it.
+func (r *Renderer) bibliographyItem(w io.Writer, node *mast.BibliographyItem) {
+	anchor := string(node.Anchor)
+	r.outs(w, `<text:p text:style-name="Bibliography">`)
+	r.bookmark(w, anchor)
+	r.outs(w, "["+Escape(anchor)+"]<text:tab/>")
+	switch {
+	case node.Reference != nil:
+		r.outs(w, Escape(referenceText(node.Reference)))
+	case strings.HasPrefix(anchor, "RFC"):
+		num := strings.TrimLeft(anchor[3:], "0")
+		target := "https://www.rfc-editor.org/info/rfc" + num
+		r.outs(w, "RFC "+Escape(num)+`, <text:a xlink:type="simple" xlink:href="`+Escape(target)+`">`+Escape(target)+"</text:a>")
+	}
+	r.outs(w, "</text:p>\n")
+}
+
+// referenceText returns the text for a reference: authors, title, series, date and target.
+func referenceText(ref *reference.Reference) string {
+	parts := []string{}
+	names := []string{}
+	for _, a := range ref.Front.Authors {
+		switch {
+		case a.Fullname != "":
+			names = append(names, a.Fullname)
+		case a.Surname != "":
+			names = append(names, strings.TrimSpace(a.Initials+" "+a.Surname))
+		case a.Organization != nil && a.Organization.Value != "":
+			names = append(names, a.Organization.Value)
+		}
+	}
+	if len(names) > 0 {
+		parts = append(parts, strings.Join(names, ", "))
+	}
+	parts = append(parts, `"`+ref.Front.Title+`"`)
+	for _, s := range ref.Series {
+		parts = append(parts, strings.TrimSpace(s.Name+" "+s.Value))
+	}
+	if d := ref.Front.Date; d != nil && d.Year != "" {
+		parts = append(parts, strings.TrimSpace(d.Month+" "+d.Year))
+	}
+	if ref.Target != "" {
+		parts = append(parts, ref.Target)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// documentIndex outputs an alphabetical index, it is filled from the index marks when the index is
+// updated in the word processor.
+func (r *Renderer) documentIndex(w io.Writer) {
+	title := Escape(r.opts.Language.Index())
+	r.outs(w, `<text:alphabetical-index text:style-name="Standard" text:protected="true" text:name="Index">`+"\n")
+	r.outs(w, `<text:alphabetical-index-source text:main-entry-style-name="Strong_20_Emphasis" alphabetical-separators="true">`)
+	r.outs(w, `<text:index-title-template text:style-name="Index_20_Heading">`+title+`</text:index-title-template>`)
+	for i := 1; i <= 2; i++ {
+		r.outs(w, fmt.Sprintf(`<text:alphabetical-index-entry-template text:outline-level="%d" text:style-name="Index_20_%d">`, i, i))
+		r.outs(w, `<text:index-entry-text/><text:index-entry-tab-stop style:type="left" style:position="0cm" style:leader-char=" "/><text:index-entry-page-number/>`)
+		r.outs(w, `</text:alphabetical-index-entry-template>`)
+	}
+	r.outs(w, "</text:alphabetical-index-source>\n<text:index-body>\n")
+	r.outs(w, `<text:index-title text:style-name="Standard" text:name="Index_Head"><text:p text:style-name="Index_20_Heading">`+title+"</text:p></text:index-title>\n")
+	r.outs(w, "</text:index-body>\n</text:alphabetical-index>\n")
+}
+
+// RenderNode renders a markdown node to ODF XML. The output is gathered and written as content.xml
+// in RenderFooter.
+func (r *Renderer) RenderNode(_ io.Writer, node ast.Node, entering bool) ast.WalkStatus {
+	w := r.content
+	if r.opts.RenderNodeHook != nil {
+		status, didHandle := r.opts.RenderNodeHook(w, node, entering)
+		if didHandle {
+			return status
+		}
+	}
+
+	switch node := node.(type) {
+	case *ast.Document:
+		// do nothing
+	case *mast.Title:
+		r.Title = node
+		if entering {
+			r.titleBlock(w, node)
+		}
+	case *mast.Authors:
+		// ignore
+	case *mast.BibliographyWrapper, *mast.Bibliography:
+		r.bibliography(w, node, entering)
+	case *mast.BibliographyItem:
+		if entering {
+			r.bibliographyItem(w, node)
+		}
+		return ast.SkipChildren
+	case *mast.DocumentIndex:
+		if entering {
+			r.documentIndex(w)
+		}
+		return ast.SkipChildren
+	case *mast.IndexLetter, *mast.IndexItem, *mast.IndexSubItem, *mast.IndexLink:
+	case *mast.ReferenceBlock:
+		// skip, added and done by AddBibliography
+	case *ast.Footnotes:
+		// footnotes are output as notes where they are referenced.
+		return ast.SkipChildren
+	case *ast.Text:
+		r.outs(w, Escape(strings.ReplaceAll(string(node.Literal), "\n", " ")))
+	case *ast.Softbreak:
+		r.outs(w, " ")
+	case *ast.Hardbreak:
+		r.outs(w, "<text:line-break/>")
+	case *ast.NonBlockingSpace:
+		r.outs(w, " ")
+	case *ast.Callout:
+		r.outs(w, "&lt;"+Escape(string(node.ID))+"&gt;")
+	case *mast.Unicode:
+		r.outs(w, Escape(node.String()))
+	case *mast.Abbreviation:
+		r.outs(w, Escape(node.String()))
+	case *mast.Cref:
+		r.span(w, "Emphasis", true)
+		r.outs(w, Escape(node.String()))
+		r.span(w, "Emphasis", false)
+	case *ast.Emph:
+		r.span(w, "Emphasis", entering)
+	case *ast.Strong:
+		r.span(w, "Strong_20_Emphasis", entering)
+	case *ast.Del:
+		r.span(w, "Strikeout", entering)
+	case *ast.Citation:
+		if entering {
+			r.citation(w, node)
+		}
+	case *ast.DocumentMatter:
+		if entering && node.Matter == ast.DocumentMatterMain {
+			r.tocBlock(w)
+		}
+	case *ast.Heading:
+		r.heading(w, node, entering)
+	case *ast.HorizontalRule:
+		r.outs(w, `<text:p text:style-name="Horizontal_20_Line"/>`+"\n")
+	case *ast.Paragraph:
+		r.paragraph(w, node, entering)
+	case *ast.HTMLSpan:
+		if xml.IsBr(node.Literal) {
+			r.outs(w, "<text:line-break/>")
+			break
+		}
+		if _, ok := xml.IsComment(node.Literal); ok {
+			break
+		}
+		r.outs(w, Escape(string(node.Literal)))
+	case *ast.HTMLBlock:
+		// skip
+	case *ast.List:
+		if node.IsFootnotesList {
+			return ast.SkipChildren
+		}
+		r.list(w, node, entering)
+	case *ast.ListItem:
+		r.listItem(w, node, entering)
+	case *ast.CodeBlock:
+		r.preformatted(w, node, node.Literal)
+	case *ast.Caption:
+		if entering {
+			r.outs(w, `<text:p text:style-name="Caption">`)
+			if figure, ok := node.Parent.(*ast.CaptionFigure); ok {
+				r.bookmark(w, figure.HeadingID)
+			}
+		} else {
+			r.outs(w, "</text:p>\n")
+		}
+	case *ast.CaptionFigure:
+	case *ast.Table:
+		r.table(w, node, entering)
+	case *ast.TableCell:
+		r.tableCell(w, node, entering)
+	case *ast.TableHeader:
+		r.outOneOf(w, entering, "<table:table-header-rows>\n", "</table:table-header-rows>\n")
+	case *ast.TableBody, *ast.TableFooter:
+	case *ast.TableRow:
+		r.outOneOf(w, entering, "<table:table-row>\n", "</table:table-row>\n")
+	case *ast.BlockQuote:
+		r.blockStyle("Quotations", entering)
+	case *ast.Aside:
+		r.blockStyle("Aside", entering)
+	case *mast.Div:
+		// only the contents, a div has no paragraph style of its own.
+	case *ast.CrossReference:
+		if !entering {
+			break
+		}
+		text := plain(node)
+		if text == "" {
+			text = r.xrefs[string(node.Destination)]
+		}
+		if text == "" {
+			text = string(node.Destination)
+		}
+		r.outs(w, `<text:a xlink:type="simple" xlink:href="#`+Escape(string(node.Destination))+`">`+Escape(text)+"</text:a>")
+		return ast.SkipChildren
+	case *ast.Index:
+		if !entering || mast.IndexRedirect(node) || mast.IndexRangeEnd(node) {
+			break
+		}
+		if len(node.Subitem) > 0 {
+			r.outs(w, `<text:alphabetical-index-mark text:string-value="`+Escape(string(node.Subitem))+`" text:key1="`+Escape(string(node.Item))+`"/>`)
+			break
+		}
+		r.outs(w, `<text:alphabetical-index-mark text:string-value="`+Escape(string(node.Item))+`"/>`)
+	case *ast.Link:
+		if node.Footnote != nil {
+			if entering {
+				r.footnote(w, node)
+			}
+			return ast.SkipChildren
+		}
+		if entering {
+			r.outs(w, `<text:a xlink:type="simple" xlink:href="`+Escape(string(node.Destination))+`">`)
+			if len(node.Children) == 0 {
+				r.outs(w, Escape(string(node.Destination)))
+			}
+		} else {
+			r.outs(w, "</text:a>")
+		}
+	case *ast.Math:
+		r.outs(w, `<text:span text:style-name="Source_20_Text">`+Escape(string(node.Literal))+"</text:span>")
+	case *ast.Image:
+		if entering {
+			r.image(w, node)
+		}
+		return ast.SkipChildren
+	case *ast.Code:
+		r.outs(w, `<text:span text:style-name="Source_20_Text">`+preserve(string(node.Literal))+"</text:span>")
+	case *ast.MathBlock:
+		if entering {
+			r.preformatted(w, node, node.Literal)
+		}
+	case *ast.Subscript:
+		r.outs(w, `<text:span text:style-name="Subscript">`+Escape(string(node.Literal))+"</text:span>")
+	case *ast.Superscript:
+		r.outs(w, `<text:span text:style-name="Superscript">`+Escape(string(node.Literal))+"</text:span>")
+	default:
+		panic(fmt.Sprintf("Unknown node %T", node))
+	}
+	return ast.GoToNext
+}
+
+func (r *Renderer) span(w io.Writer, style string, entering bool) {
+	r.outOneOf(w, entering, `<text:span text:style-name="`+style+`">`, "</text:span>")
+}
+
+// blockStyle sets the paragraph style for the paragraphs in a block quote or aside.
+func (r *Renderer) blockStyle(style string, entering bool) {
+	if entering {
+		r.pushStyle(style)
+		return
+	}
+	r.popStyle()
+}
+
+// RenderHeader walks the document to gather the table of contents and the text of the headings
+// for cross references.
+func (r *Renderer) RenderHeader(_ io.Writer, doc ast.Node) {
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch n := node.(type) {
+		case *mast.Title:
+			r.Title = n
+		case *ast.Heading:
+			text := plain(n)
+			if n.HeadingID != "" {
+				r.xrefs[n.HeadingID] = text
+			}
+			if !n.IsSpecial && n.Level <= 3 {
+				r.toc = append(r.toc, tocEntry{level: n.Level, title: text})
+			}
+			return ast.SkipChildren
+		}
+		return ast.GoToNext
+	})
+	if r.toc == nil {
+		r.toc = []tocEntry{}
+	}
+}
+
+// RenderFooter writes the ODT package to w.
+func (r *Renderer) RenderFooter(w io.Writer, _ ast.Node) {
+	r.write(w)
+}
@@ -0,0 +1,94 @@
+package odt
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/lang"
+	"github.com/mmarkdown/mmark/v2/mparser"
+)
+
+const doc = `%%%
+title = "Review"
+%%%
+
+{mainmatter}
+
+# One
+
+See (#two) and a note[^1].
+
+[^1]: The note.
+
+# Two
+
+| a | b |
+|---|---|
+| 1 | 2 |
+
+~~~
+if x {
+	  y
+}
+~~~
+`
+
+func TestODT(t *testing.T) {
+	p := parser.NewWithExtensions(mparser.Extensions)
+	p.Opts = parser.Options{ParserHook: mparser.Hook}
+	d := markdown.Parse([]byte(doc), p)
+	renderer := NewRenderer(RendererOptions{Language: lang.New("en")})
+	data := markdown.Render(d, renderer)
+
+	z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("not a zip package: %s", err)
+	}
+	if z.File[0].Name != "mimetype" || z.File[0].Method != zip.Store {
+		t.Errorf("expected mimetype to be the first, stored, file, got %q", z.File[0].Name)
+	}
+
+	files := map[string]string{}
+	for _, f := range z.File {
+		rc, _ := f.Open()
+		buf, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(buf)
+	}
+	for _, name := range []string{"META-INF/manifest.xml", "content.xml", "styles.xml", "meta.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("expected %q in the package", name)
+			continue
+		}
+		dec := xml.NewDecoder(strings.NewReader(files[name]))
+		for {
+			if _, err := dec.Token(); err != nil {
+				if err != io.EOF {
+					t.Errorf("%s is not well-formed: %s", name, err)
+				}
+				break
+			}
+		}
+	}
+
+	content := files["content.xml"]
+	for _, want := range []string{
+		`<text:p text:style-name="Title">Review</text:p>`,
+		`<text:p text:style-name="Contents_20_1">Two</text:p>`,
+		`<text:a xlink:type="simple" xlink:href="#two">Two</text:a>`,
+		`<text:note-body><text:p text:style-name="Footnote">The note.</text:p></text:note-body>`,
+		`<table:table-column table:number-columns-repeated="2"/>`,
+		`<text:p text:style-name="Preformatted_20_Text"><text:tab/> <text:s text:c="1"/>y</text:p>`,
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected %q in content.xml, got %s", want, content)
+		}
+	}
+}
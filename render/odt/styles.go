@@ -0,0 +1,198 @@
+package odt
+
+// namespaces are the XML namespaces used in the ODF documents.
+const namespaces = `xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" ` +
+	`xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" ` +
+	`xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" ` +
+	`xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" ` +
+	`xmlns:draw="urn:oasis:names:tc:opendocument:xmlns:drawing:1.0" ` +
+	`xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0" ` +
+	`xmlns:xlink="http://www.w3.org/1999/xlink" ` +
+	`xmlns:dc="http://purl.org/dc/elements/1.1/" ` +
+	`xmlns:meta="urn:oasis:names:tc:opendocument:xmlns:meta:1.0" ` +
+	`xmlns:svg="urn:oasis:names:tc:opendocument:xmlns:svg-compatible:1.0" ` +
+	`office:version="1.2"`
+
+// styles is the styles.xml of the document. All styles used in content.xml are defined here, so
+// reviewers can change the look of the document by changing the styles.
+const styles = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-styles ` + namespaces + `>
+<office:font-face-decls>
+  <style:font-face style:name="Liberation Serif" svg:font-family="'Liberation Serif'" style:font-family-generic="roman" style:font-pitch="variable"/>
+  <style:font-face style:name="Liberation Sans" svg:font-family="'Liberation Sans'" style:font-family-generic="swiss" style:font-pitch="variable"/>
+  <style:font-face style:name="Liberation Mono" svg:font-family="'Liberation Mono'" style:font-family-generic="modern" style:font-pitch="fixed"/>
+</office:font-face-decls>
+<office:styles>
+  <style:default-style style:family="paragraph">
+    <style:paragraph-properties style:tab-stop-distance="1.25cm"/>
+    <style:text-properties style:font-name="Liberation Serif" fo:font-size="12pt"/>
+  </style:default-style>
+  <style:style style:name="Standard" style:family="paragraph" style:class="text"/>
+  <style:style style:name="Text_20_body" style:display-name="Text body" style:family="paragraph" style:parent-style-name="Standard" style:class="text">
+    <style:paragraph-properties fo:margin-top="0cm" fo:margin-bottom="0.25cm" fo:line-height="115%"/>
+  </style:style>
+  <style:style style:name="Heading" style:family="paragraph" style:parent-style-name="Standard" style:next-style-name="Text_20_body" style:class="text">
+    <style:paragraph-properties fo:margin-top="0.42cm" fo:margin-bottom="0.21cm" fo:keep-with-next="always"/>
+    <style:text-properties style:font-name="Liberation Sans" fo:font-size="14pt"/>
+  </style:style>
+  <style:style style:name="Heading_20_1" style:display-name="Heading 1" style:family="paragraph" style:parent-style-name="Heading" style:default-outline-level="1" style:class="text">
+    <style:text-properties fo:font-size="130%" fo:font-weight="bold"/>
+  </style:style>
+  <style:style style:name="Heading_20_2" style:display-name="Heading 2" style:family="paragraph" style:parent-style-name="Heading" style:default-outline-level="2" style:class="text">
+    <style:text-properties fo:font-size="115%" fo:font-weight="bold"/>
+  </style:style>
+  <style:style style:name="Heading_20_3" style:display-name="Heading 3" style:family="paragraph" style:parent-style-name="Heading" style:default-outline-level="3" style:class="text">
+    <style:text-properties fo:font-size="101%" fo:font-weight="bold"/>
+  </style:style>
+  <style:style style:name="Heading_20_4" style:display-name="Heading 4" style:family="paragraph" style:parent-style-name="Heading" style:default-outline-level="4" style:class="text">
+    <style:text-properties fo:font-size="95%" fo:font-style="italic" fo:font-weight="bold"/>
+  </style:style>
+  <style:style style:name="Heading_20_5" style:display-name="Heading 5" style:family="paragraph" style:parent-style-name="Heading" style:default-outline-level="5" style:class="text">
+    <style:text-properties fo:font-size="85%" fo:font-weight="bold"/>
+  </style:style>
+  <style:style style:name="Heading_20_6" style:display-name="Heading 6" style:family="paragraph" style:parent-style-name="Heading" style:default-outline-level="6" style:class="text">
+    <style:text-properties fo:font-size="85%" fo:font-style="italic" fo:font-weight="bold"/>
+  </style:style>
+  <style:style style:name="Title" style:family="paragraph" style:parent-style-name="Heading" style:next-style-name="Subtitle" style:class="chapter">
+    <style:paragraph-properties fo:text-align="center"/>
+    <style:text-properties fo:font-size="28pt" fo:font-weight="bold"/>
+  </style:style>
+  <style:style style:name="Subtitle" style:family="paragraph" style:parent-style-name="Heading" style:next-style-name="Text_20_body" style:class="chapter">
+    <style:paragraph-properties fo:margin-top="0.1cm" fo:text-align="center"/>
+    <style:text-properties fo:font-size="14pt"/>
+  </style:style>
+  <style:style style:name="List_20_Contents" style:display-name="List Contents" style:family="paragraph" style:parent-style-name="Text_20_body" style:class="list"/>
+  <style:style style:name="Definition_20_Term" style:display-name="Definition Term" style:family="paragraph" style:parent-style-name="Text_20_body" style:next-style-name="Definition" style:class="list">
+    <style:paragraph-properties fo:margin-bottom="0cm" fo:keep-with-next="always"/>
+    <style:text-properties fo:font-weight="bold"/>
+  </style:style>
+  <style:style style:name="Definition" style:family="paragraph" style:parent-style-name="Text_20_body" style:class="list">
+    <style:paragraph-properties fo:margin-left="1cm"/>
+  </style:style>
+  <style:style style:name="Preformatted_20_Text" style:display-name="Preformatted Text" style:family="paragraph" style:parent-style-name="Standard" style:class="html">
+    <style:paragraph-properties fo:margin-top="0cm" fo:margin-bottom="0cm" fo:background-color="#f4f4f4" fo:keep-together="always"/>
+    <style:text-properties style:font-name="Liberation Mono" fo:font-size="10pt"/>
+  </style:style>
+  <style:style style:name="Quotations" style:family="paragraph" style:parent-style-name="Text_20_body" style:class="html">
+    <style:paragraph-properties fo:margin-left="1cm" fo:margin-right="1cm"/>
+    <style:text-properties fo:font-style="italic"/>
+  </style:style>
+  <style:style style:name="Aside" style:family="paragraph" style:parent-style-name="Text_20_body" style:class="text">
+    <style:paragraph-properties fo:margin-left="1cm" fo:margin-right="1cm" fo:padding="0.2cm" fo:border="0.5pt solid #808080"/>
+    <style:text-properties fo:font-size="90%"/>
+  </style:style>
+  <style:style style:name="Caption" style:family="paragraph" style:parent-style-name="Standard" style:class="extra">
+    <style:paragraph-properties fo:margin-top="0.21cm" fo:margin-bottom="0.42cm" fo:text-align="center"/>
+    <style:text-properties fo:font-size="10pt" fo:font-style="italic"/>
+  </style:style>
+  <style:style style:name="Table_20_Contents" style:display-name="Table Contents" style:family="paragraph" style:parent-style-name="Standard" style:class="extra"/>
+  <style:style style:name="Table_20_Heading" style:display-name="Table Heading" style:family="paragraph" style:parent-style-name="Table_20_Contents" style:class="extra">
+    <style:text-properties fo:font-weight="bold"/>
+  </style:style>
+  <style:style style:name="Footnote" style:family="paragraph" style:parent-style-name="Standard" style:class="extra">
+    <style:paragraph-properties fo:margin-left="0.6cm" fo:text-indent="-0.6cm"/>
+    <style:text-properties fo:font-size="10pt"/>
+  </style:style>
+  <style:style style:name="Bibliography" style:family="paragraph" style:parent-style-name="Text_20_body" style:class="index">
+    <style:paragraph-properties fo:margin-left="3cm" fo:text-indent="-3cm">
+      <style:tab-stops><style:tab-stop style:position="0cm"/></style:tab-stops>
+    </style:paragraph-properties>
+  </style:style>
+  <style:style style:name="Horizontal_20_Line" style:display-name="Horizontal Line" style:family="paragraph" style:parent-style-name="Standard" style:class="html">
+    <style:paragraph-properties fo:margin-bottom="0.5cm" fo:border-bottom="0.5pt solid #808080"/>
+  </style:style>
+  <style:style style:name="Contents_20_Heading" style:display-name="Contents Heading" style:family="paragraph" style:parent-style-name="Heading" style:class="index">
+    <style:text-properties fo:font-size="16pt" fo:font-weight="bold"/>
+  </style:style>
+  <style:style style:name="Contents_20_1" style:display-name="Contents 1" style:family="paragraph" style:parent-style-name="Standard" style:class="index">
+    <style:paragraph-properties><style:tab-stops><style:tab-stop style:position="17cm" style:type="right" style:leader-style="dotted" style:leader-text="."/></style:tab-stops></style:paragraph-properties>
+  </style:style>
+  <style:style style:name="Contents_20_2" style:display-name="Contents 2" style:family="paragraph" style:parent-style-name="Contents_20_1" style:class="index">
+    <style:paragraph-properties fo:margin-left="0.5cm"/>
+  </style:style>
+  <style:style style:name="Contents_20_3" style:display-name="Contents 3" style:family="paragraph" style:parent-style-name="Contents_20_1" style:class="index">
+    <style:paragraph-properties fo:margin-left="1cm"/>
+  </style:style>
+  <style:style style:name="Index_20_Heading" style:display-name="Index Heading" style:family="paragraph" style:parent-style-name="Contents_20_Heading" style:class="index"/>
+  <style:style style:name="Index_20_1" style:display-name="Index 1" style:family="paragraph" style:parent-style-name="Standard" style:class="index"/>
+  <style:style style:name="Index_20_2" style:display-name="Index 2" style:family="paragraph" style:parent-style-name="Index_20_1" style:class="index">
+    <style:paragraph-properties fo:margin-left="0.5cm"/>
+  </style:style>
+  <style:style style:name="Emphasis" style:family="text">
+    <style:text-properties fo:font-style="italic"/>
+  </style:style>
+  <style:style style:name="Strong_20_Emphasis" style:display-name="Strong Emphasis" style:family="text">
+    <style:text-properties fo:font-weight="bold"/>
+  </style:style>
+  <style:style style:name="Source_20_Text" style:display-name="Source Text" style:family="text">
+    <style:text-properties style:font-name="Liberation Mono"/>
+  </style:style>
+  <style:style style:name="Strikeout" style:family="text">
+    <style:text-properties style:text-line-through-style="solid"/>
+  </style:style>
+  <style:style style:name="Subscript" style:family="text">
+    <style:text-properties style:text-position="sub 58%"/>
+  </style:style>
+  <style:style style:name="Superscript" style:family="text">
+    <style:text-properties style:text-position="super 58%"/>
+  </style:style>
+  <style:style style:name="Table" style:family="table">
+    <style:table-properties style:width="17cm" table:align="center" fo:margin-bottom="0.21cm"/>
+  </style:style>
+  <style:style style:name="TableCell" style:family="table-cell">
+    <style:table-cell-properties fo:padding="0.1cm" fo:border="0.5pt solid #000000"/>
+  </style:style>
+  <text:outline-style style:name="Outline">
+    <text:outline-level-style text:level="1" style:num-format="1" style:num-suffix=".">
+      <style:list-level-properties text:list-level-position-and-space-mode="label-alignment"><style:list-level-label-alignment text:label-followed-by="space"/></style:list-level-properties>
+    </text:outline-level-style>
+    <text:outline-level-style text:level="2" style:num-format="1" style:num-suffix="." text:display-levels="2">
+      <style:list-level-properties text:list-level-position-and-space-mode="label-alignment"><style:list-level-label-alignment text:label-followed-by="space"/></style:list-level-properties>
+    </text:outline-level-style>
+    <text:outline-level-style text:level="3" style:num-format="1" style:num-suffix="." text:display-levels="3">
+      <style:list-level-properties text:list-level-position-and-space-mode="label-alignment"><style:list-level-label-alignment text:label-followed-by="space"/></style:list-level-properties>
+    </text:outline-level-style>
+    <text:outline-level-style text:level="4" style:num-format=""/>
+    <text:outline-level-style text:level="5" style:num-format=""/>
+    <text:outline-level-style text:level="6" style:num-format=""/>
+  </text:outline-style>
+  <text:list-style style:name="Bullets">
+    <text:list-level-style-bullet text:level="1" text:bullet-char="•">
+      <style:list-level-properties text:list-level-position-and-space-mode="label-alignment"><style:list-level-label-alignment text:label-followed-by="listtab" text:list-tab-stop-position="0.64cm" fo:text-indent="-0.64cm" fo:margin-left="0.64cm"/></style:list-level-properties>
+    </text:list-level-style-bullet>
+    <text:list-level-style-bullet text:level="2" text:bullet-char="◦">
+      <style:list-level-properties text:list-level-position-and-space-mode="label-alignment"><style:list-level-label-alignment text:label-followed-by="listtab" text:list-tab-stop-position="1.27cm" fo:text-indent="-0.64cm" fo:margin-left="1.27cm"/></style:list-level-properties>
+    </text:list-level-style-bullet>
+    <text:list-level-style-bullet text:level="3" text:bullet-char="▪">
+      <style:list-level-properties text:list-level-position-and-space-mode="label-alignment"><style:list-level-label-alignment text:label-followed-by="listtab" text:list-tab-stop-position="1.91cm" fo:text-indent="-0.64cm" fo:margin-left="1.91cm"/></style:list-level-properties>
+    </text:list-level-style-bullet>
+  </text:list-style>
+  <text:list-style style:name="Numbering">
+    <text:list-level-style-number text:level="1" style:num-format="1" style:num-suffix=".">
+      <style:list-level-properties text:list-level-position-and-space-mode="label-alignment"><style:list-level-label-alignment text:label-followed-by="listtab" text:list-tab-stop-position="0.64cm" fo:text-indent="-0.64cm" fo:margin-left="0.64cm"/></style:list-level-properties>
+    </text:list-level-style-number>
+    <text:list-level-style-number text:level="2" style:num-format="a" style:num-suffix=".">
+      <style:list-level-properties text:list-level-position-and-space-mode="label-alignment"><style:list-level-label-alignment text:label-followed-by="listtab" text:list-tab-stop-position="1.27cm" fo:text-indent="-0.64cm" fo:margin-left="1.27cm"/></style:list-level-properties>
+    </text:list-level-style-number>
+    <text:list-level-style-number text:level="3" style:num-format="i" style:num-suffix=".">
+      <style:list-level-properties text:list-level-position-and-space-mode="label-alignment"><style:list-level-label-alignment text:label-followed-by="listtab" text:list-tab-stop-position="1.91cm" fo:text-indent="-0.64cm" fo:margin-left="1.91cm"/></style:list-level-properties>
+    </text:list-level-style-number>
+  </text:list-style>
+</office:styles>
+<office:automatic-styles>
+  <style:page-layout style:name="PageLayout">
+    <style:page-layout-properties fo:page-width="21cm" fo:page-height="29.7cm" fo:margin-top="2cm" fo:margin-bottom="1.5cm" fo:margin-left="2cm" fo:margin-right="2cm"/>
+    <style:footer-style><style:header-footer-properties fo:min-height="0.6cm" fo:margin-top="0.5cm"/></style:footer-style>
+  </style:page-layout>
+  <style:style style:name="FooterText" style:family="paragraph" style:parent-style-name="Standard">
+    <style:paragraph-properties fo:text-align="center"/>
+    <style:text-properties fo:font-size="10pt"/>
+  </style:style>
+</office:automatic-styles>
+<office:master-styles>
+  <style:master-page style:name="Standard" style:page-layout-name="PageLayout">
+    <style:footer><text:p text:style-name="FooterText"><text:page-number text:select-page="current"/></text:p></style:footer>
+  </style:master-page>
+</office:master-styles>
+</office:document-styles>
+`
@@ -0,0 +1,359 @@
+// The package outline outputs a graph of the structure of a mmark document: the sections, the
+// cross references between them and the citations. The graph is written in the Graphviz DOT
+// language or as a Mermaid flowchart.
+package outline
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// Flags control optional behavior of the outline renderer.
+type Flags int
+
+// Outline renderer configuration options.
+const (
+	FlagsNone Flags = 0
+	Mermaid   Flags = 1 << iota // Output a Mermaid flowchart instead of a DOT graph.
+
+	CommonFlags Flags = FlagsNone
+)
+
+// RendererOptions is a collection of supplementary parameters tweaking
+// the behavior of various parts of the outline renderer.
+type RendererOptions struct {
+	Flags Flags // Flags allow customizing this renderer's behavior
+}
+
+// Renderer implements Renderer interface for outline output.
+type Renderer struct {
+	opts RendererOptions
+
+	Title *mast.Title
+
+	sections []*section
+	stack    []*section        // open sections, the last one contains the current node
+	ids      map[string]int    // anchor to the index of the section it's in
+	refs     []string          // cited anchors, in order of the first citation
+	cited    map[string]bool   // anchors that are cited
+	edges    []edge            // cross references and citations
+	targeted map[int]bool      // sections that are the target of a cross reference
+	unknown  map[string]string // targets that aren't in the document, to their node name
+}
+
+// section is a heading in the document.
+type section struct {
+	index   int
+	level   int
+	title   string
+	special bool
+	parent  int // index of the parent section, -1 for top level sections
+}
+
+type edgeType int
+
+const (
+	xref edgeType = iota
+	normative
+	informative
+)
+
+// edge is a cross reference or a citation from a section.
+type edge struct {
+	from int // -1 for text before the first heading
+	to   string
+	typ  edgeType
+}
+
+// NewRenderer creates and configures an Renderer object, which satisfies the Renderer interface.
+func NewRenderer(opts RendererOptions) *Renderer {
+	return &Renderer{
+		opts:     opts,
+		ids:      map[string]int{},
+		cited:    map[string]bool{},
+		targeted: map[int]bool{},
+		unknown:  map[string]string{},
+	}
+}
+
+// current returns the index of the section that contains the current node.
+func (r *Renderer) current() int {
+	if len(r.stack) == 0 {
+		return -1
+	}
+	return r.stack[len(r.stack)-1].index
+}
+
+func (r *Renderer) heading(node *ast.Heading) {
+	for len(r.stack) > 0 && r.stack[len(r.stack)-1].level >= node.Level {
+		r.stack = r.stack[:len(r.stack)-1]
+	}
+	s := &section{index: len(r.sections), level: node.Level, title: plain(node), special: node.IsSpecial, parent: r.current()}
+	r.sections = append(r.sections, s)
+	r.stack = append(r.stack, s)
+	if node.HeadingID != "" {
+		r.ids[node.HeadingID] = s.index
+	}
+}
+
+func (r *Renderer) citation(node *ast.Citation) {
+	for i, c := range node.Destination {
+		anchor := string(c)
+		typ := informative
+		switch node.Type[i] {
+		case ast.CitationTypeSuppressed:
+			continue
+		case ast.CitationTypeNormative:
+			typ = normative
+		}
+		if !r.cited[anchor] {
+			r.refs = append(r.refs, anchor)
+			r.cited[anchor] = true
+		}
+		r.edges = append(r.edges, edge{from: r.current(), to: anchor, typ: typ})
+	}
+}
+
+// RenderNode gathers the sections, cross references and citations of the document.
+func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.GoToNext
+	}
+	switch node := node.(type) {
+	case *mast.Title:
+		r.Title = node
+		return ast.SkipChildren
+	case *ast.Heading:
+		if node.IsTitleblock {
+			return ast.SkipChildren
+		}
+		r.heading(node)
+		return ast.SkipChildren
+	case *ast.CrossReference:
+		r.edges = append(r.edges, edge{from: r.current(), to: string(node.Destination), typ: xref})
+	case *ast.Citation:
+		r.citation(node)
+	case *mast.Bibliography, *mast.BibliographyWrapper, *mast.DocumentIndex, *mast.ReferenceBlock:
+		return ast.SkipChildren
+	default:
+		if id := nodeID(node); id != "" && r.current() >= 0 {
+			r.ids[id] = r.current()
+		}
+	}
+	return ast.GoToNext
+}
+
+// RenderHeader does nothing, the graph is written when the whole document has been seen.
+func (r *Renderer) RenderHeader(w io.Writer, doc ast.Node) {}
+
+// RenderFooter writes the graph to w.
+func (r *Renderer) RenderFooter(w io.Writer, _ ast.Node) {
+	// resolve the cross references to sections, so we know which sections are never referenced.
+	for _, e := range r.edges {
+		if e.typ != xref {
+			continue
+		}
+		if i, ok := r.ids[e.to]; ok {
+			r.targeted[i] = true
+			continue
+		}
+		if _, ok := r.unknown[e.to]; !ok {
+			r.unknown[e.to] = fmt.Sprintf("u%d", len(r.unknown))
+		}
+	}
+	if r.opts.Flags&Mermaid != 0 {
+		r.mermaid(w)
+		return
+	}
+	r.dot(w)
+}
+
+// orphan returns true if s is a regular section that isn't the target of a cross reference.
+func (r *Renderer) orphan(s *section) bool { return !s.special && !r.targeted[s.index] }
+
+// target returns the node name for the target of an edge.
+func (r *Renderer) target(e edge) string {
+	if e.typ != xref {
+		return fmt.Sprintf("r%d", indexOf(r.refs, e.to))
+	}
+	if i, ok := r.ids[e.to]; ok {
+		return fmt.Sprintf("s%d", i)
+	}
+	return r.unknown[e.to]
+}
+
+// source returns the node name for the source of an edge, text before the first heading is the
+// document node.
+func source(i int) string {
+	if i < 0 {
+		return "doc"
+	}
+	return fmt.Sprintf("s%d", i)
+}
+
+// hasDoc returns true if there are edges from the text before the first heading.
+func (r *Renderer) hasDoc() bool {
+	for _, e := range r.edges {
+		if e.from < 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Renderer) title() string {
+	if r.Title != nil && r.Title.TitleData != nil && r.Title.Title != "" {
+		return r.Title.Title
+	}
+	return "document"
+}
+
+func (r *Renderer) dot(w io.Writer) {
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "digraph %s {\n", dotQuote(r.title()))
+	b.WriteString("  rankdir=LR;\n  node [shape=box];\n")
+	if r.hasDoc() {
+		fmt.Fprintf(b, "  doc [label=%s, shape=folder];\n", dotQuote(r.title()))
+	}
+	for _, s := range r.sections {
+		attrs := "label=" + dotQuote(s.title)
+		if r.orphan(s) {
+			attrs += ", style=dashed"
+		}
+		fmt.Fprintf(b, "  s%d [%s];\n", s.index, attrs)
+	}
+	for i, ref := range r.refs {
+		fmt.Fprintf(b, "  r%d [label=%s, shape=note];\n", i, dotQuote(ref))
+	}
+	for _, u := range sortedKeys(r.unknown) {
+		fmt.Fprintf(b, "  %s [label=%s, color=red];\n", r.unknown[u], dotQuote(u))
+	}
+	for _, s := range r.sections {
+		if s.parent >= 0 {
+			fmt.Fprintf(b, "  s%d -> s%d [arrowhead=none, color=gray];\n", s.parent, s.index)
+		}
+	}
+	for _, e := range r.edges {
+		attrs := ""
+		switch e.typ {
+		case normative:
+			attrs = " [style=bold]"
+		case informative:
+			attrs = " [style=dashed]"
+		}
+		fmt.Fprintf(b, "  %s -> %s%s;\n", source(e.from), r.target(e), attrs)
+	}
+	b.WriteString("}\n")
+	w.Write(b.Bytes())
+}
+
+func (r *Renderer) mermaid(w io.Writer) {
+	b := &bytes.Buffer{}
+	b.WriteString("flowchart LR\n")
+	if r.hasDoc() {
+		fmt.Fprintf(b, "  doc[%s]\n", mermaidQuote(r.title()))
+	}
+	orphans := []string{}
+	for _, s := range r.sections {
+		fmt.Fprintf(b, "  s%d[%s]\n", s.index, mermaidQuote(s.title))
+		if r.orphan(s) {
+			orphans = append(orphans, fmt.Sprintf("s%d", s.index))
+		}
+	}
+	for i, ref := range r.refs {
+		fmt.Fprintf(b, "  r%d([%s])\n", i, mermaidQuote(ref))
+	}
+	unknown := []string{}
+	for _, u := range sortedKeys(r.unknown) {
+		fmt.Fprintf(b, "  %s[%s]\n", r.unknown[u], mermaidQuote(u))
+		unknown = append(unknown, r.unknown[u])
+	}
+	for _, s := range r.sections {
+		if s.parent >= 0 {
+			fmt.Fprintf(b, "  s%d --- s%d\n", s.parent, s.index)
+		}
+	}
+	for _, e := range r.edges {
+		arrow := "-->"
+		switch e.typ {
+		case normative:
+			arrow = "==>"
+		case informative:
+			arrow = "-.->"
+		}
+		fmt.Fprintf(b, "  %s %s %s\n", source(e.from), arrow, r.target(e))
+	}
+	if len(orphans) > 0 {
+		b.WriteString("  classDef orphan stroke-dasharray: 5 5\n")
+		b.WriteString("  class " + strings.Join(orphans, ",") + " orphan\n")
+	}
+	if len(unknown) > 0 {
+		b.WriteString("  classDef unknown stroke:red\n")
+		b.WriteString("  class " + strings.Join(unknown, ",") + " unknown\n")
+	}
+	w.Write(b.Bytes())
+}
+
+// dotQuote returns s as a quoted DOT string.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ").Replace(s) + `"`
+}
+
+// mermaidQuote returns s as a quoted Mermaid label.
+func mermaidQuote(s string) string {
+	return `"` + strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(s) + `"`
+}
+
+func indexOf(list []string, s string) int {
+	for i := range list {
+		if list[i] == s {
+			return i
+		}
+	}
+	return -1
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// nodeID returns the ID from the attribute of node.
+func nodeID(node ast.Node) string {
+	var a *ast.Attribute
+	if c := node.AsContainer(); c != nil {
+		a = c.Attribute
+	}
+	if l := node.AsLeaf(); l != nil {
+		a = l.Attribute
+	}
+	if a == nil {
+		return ""
+	}
+	return string(a.ID)
+}
+
+// plain returns the text of node's children, without markup.
+func plain(node ast.Node) string {
+	buf := &bytes.Buffer{}
+	ast.WalkFunc(node, func(n ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		if l := n.AsLeaf(); l != nil {
+			buf.Write(l.Literal)
+		}
+		return ast.GoToNext
+	})
+	return buf.String()
+}
@@ -0,0 +1,66 @@
+package outline
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/mparser"
+)
+
+const doc = `# One
+
+See (#two) and [@!RFC2119].
+
+## Sub
+
+# Two {#two}
+
+See (#one) and (#missing) [@?RFC8174].
+`
+
+func render(flags Flags) string {
+	p := parser.NewWithExtensions(mparser.Extensions)
+	p.Opts = parser.Options{ParserHook: mparser.Hook}
+	d := markdown.Parse([]byte(doc), p)
+	return string(markdown.Render(d, NewRenderer(RendererOptions{Flags: flags})))
+}
+
+func TestDot(t *testing.T) {
+	out := render(FlagsNone)
+	for _, want := range []string{
+		`s0 [label="One"];`,
+		`s1 [label="Sub", style=dashed];`,
+		`u0 [label="missing", color=red];`,
+		"s0 -> s1 [arrowhead=none, color=gray];",
+		"s0 -> s2;",
+		"s2 -> s0;",
+		"s2 -> u0;",
+		"s0 -> r0 [style=bold];",
+		"s2 -> r1 [style=dashed];",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got %s", want, out)
+		}
+	}
+}
+
+func TestMermaid(t *testing.T) {
+	out := render(Mermaid)
+	for _, want := range []string{
+		"flowchart LR\n",
+		`s0["One"]`,
+		`r0(["RFC2119"])`,
+		"s0 --- s1",
+		"s0 --> s2",
+		"s0 ==> r0",
+		"s2 -.-> r1",
+		"class s1 orphan",
+		"class u0 unknown",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got %s", want, out)
+		}
+	}
+}
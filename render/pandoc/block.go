@@ -0,0 +1,300 @@
+package pandoc
+
+import (
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// blocks converts block level nodes.
+func (r *Renderer) blocks(nodes []ast.Node) []Element {
+	blocks := []Element{}
+	for _, n := range nodes {
+		blocks = append(blocks, r.block(n)...)
+	}
+	return blocks
+}
+
+// tight returns the blocks for the children of a tight list item, paragraphs are converted to plain text.
+func (r *Renderer) tight(item ast.Node) []Element {
+	blocks := r.blocks(item.GetChildren())
+	for i := range blocks {
+		if blocks[i].T == "Para" {
+			blocks[i].T = "Plain"
+		}
+	}
+	return blocks
+}
+
+func (r *Renderer) block(node ast.Node) []Element {
+	if r.opts.ElementHook != nil {
+		if e := r.opts.ElementHook(node); e != nil {
+			return e
+		}
+	}
+
+	switch node := node.(type) {
+	case *mast.Title:
+		r.Title = node
+		return nil
+	case *mast.Authors, *mast.ReferenceBlock, *mast.DocumentIndex, *ast.Footnotes:
+		// Footnotes are converted to notes where they are referenced, pandoc creates its own index.
+		return nil
+	case *ast.DocumentMatter:
+		return r.blocks(node.GetChildren())
+	case *mast.BibliographyWrapper:
+		return []Element{{T: "Div", C: []interface{}{attr(node, "refs", []string{"references"}, nil), r.blocks(node.GetChildren())}}}
+	case *mast.Bibliography:
+		kind := "informative"
+		if node.Type == ast.CitationTypeNormative {
+			kind = "normative"
+		}
+		return []Element{{T: "Div", C: []interface{}{attr(node, "", []string{"references"}, [][]string{{"type", kind}}), r.blocks(node.GetChildren())}}}
+	case *mast.BibliographyItem:
+		para := Element{T: "Para", C: r.reference(node)}
+		return []Element{{T: "Div", C: []interface{}{attr(node, "ref-"+string(node.Anchor), []string{"csl-entry"}, nil), []Element{para}}}}
+	case *ast.Heading:
+		return []Element{{T: "Header", C: []interface{}{node.Level, attr(node, node.HeadingID, nil, nil), r.inlines(node.GetChildren())}}}
+	case *ast.Paragraph:
+		return []Element{{T: "Para", C: r.inlines(node.GetChildren())}}
+	case *ast.CodeBlock:
+		classes := []string{}
+		if lang := strings.Fields(string(node.Info)); len(lang) > 0 {
+			classes = append(classes, lang[0])
+		}
+		return []Element{{T: "CodeBlock", C: []interface{}{attr(node, "", classes, nil), strings.TrimSuffix(string(node.Literal), "\n")}}}
+	case *ast.MathBlock:
+		math := Element{T: "Math", C: []interface{}{Element{T: "DisplayMath"}, strings.TrimSpace(string(node.Literal))}}
+		return []Element{{T: "Para", C: []Element{math}}}
+	case *ast.HTMLBlock:
+		return []Element{{T: "RawBlock", C: []interface{}{"html", string(node.Literal)}}}
+	case *ast.HorizontalRule:
+		return []Element{{T: "HorizontalRule"}}
+	case *ast.BlockQuote:
+		return []Element{{T: "BlockQuote", C: r.blocks(node.GetChildren())}}
+	case *ast.Aside:
+		return []Element{{T: "Div", C: []interface{}{attr(node, "", []string{"aside"}, nil), r.blocks(node.GetChildren())}}}
+	case *mast.Div:
+		classes := []string{}
+		if node.Name != "" {
+			classes = append(classes, node.Name)
+		}
+		return []Element{{T: "Div", C: []interface{}{attr(node, "", classes, nil), r.blocks(node.GetChildren())}}}
+	case *ast.List:
+		return r.list(node)
+	case *ast.Table:
+		return []Element{r.table(node, nil)}
+	case *ast.CaptionFigure:
+		return r.figure(node)
+	case *ast.Caption:
+		return []Element{{T: "Para", C: r.inlines(node.GetChildren())}}
+	}
+	// inline content in block context, i.e. the text of a footnote.
+	return []Element{{T: "Plain", C: r.inline(node)}}
+}
+
+func (r *Renderer) list(list *ast.List) []Element {
+	if list.IsFootnotesList {
+		return nil
+	}
+	items := [][]Element{}
+	for _, c := range list.GetChildren() {
+		if list.Tight {
+			items = append(items, r.tight(c))
+			continue
+		}
+		items = append(items, r.blocks(c.GetChildren()))
+	}
+
+	switch {
+	case list.ListFlags&ast.ListTypeDefinition != 0:
+		defs := []interface{}{}
+		var term []Element
+		var definitions [][]Element
+		flush := func() {
+			if term != nil {
+				defs = append(defs, []interface{}{term, definitions})
+			}
+			term, definitions = nil, [][]Element{}
+		}
+		for i, c := range list.GetChildren() {
+			item := c.(*ast.ListItem)
+			if item.ListFlags&ast.ListTypeTerm != 0 {
+				flush()
+				term = []Element{}
+				for _, b := range items[i] {
+					if inl, ok := b.C.([]Element); ok {
+						term = append(term, inl...)
+					}
+				}
+				continue
+			}
+			definitions = append(definitions, r.tight(item))
+		}
+		flush()
+		return []Element{{T: "DefinitionList", C: defs}}
+	case list.ListFlags&ast.ListTypeOrdered != 0:
+		start := list.Start
+		if start == 0 {
+			start = 1
+		}
+		delim := "Period"
+		if list.Delimiter == ')' {
+			delim = "OneParen"
+		}
+		attrs := []interface{}{start, Element{T: "Decimal"}, Element{T: delim}}
+		return []Element{{T: "OrderedList", C: []interface{}{attrs, items}}}
+	}
+	return []Element{{T: "BulletList", C: items}}
+}
+
+var alignments = map[ast.CellAlignFlags]string{
+	ast.TableAlignmentLeft:   "AlignLeft",
+	ast.TableAlignmentRight:  "AlignRight",
+	ast.TableAlignmentCenter: "AlignCenter",
+}
+
+func align(a ast.CellAlignFlags) Element {
+	if t, ok := alignments[a]; ok {
+		return Element{T: t}
+	}
+	return Element{T: "AlignDefault"}
+}
+
+// table converts a table, caption is the optional table caption.
+func (r *Renderer) table(tab *ast.Table, caption []Element) Element {
+	var head, body, foot [][]interface{}
+	colspecs := []interface{}{}
+	rows := func(section ast.Node) [][]interface{} {
+		rs := [][]interface{}{}
+		for _, row := range section.GetChildren() {
+			cells := []interface{}{}
+			for _, c := range row.GetChildren() {
+				cell := c.(*ast.TableCell)
+				rowspan := mast.RowSpan(cell)
+				if rowspan == 0 {
+					continue
+				}
+				span := cell.ColSpan
+				if span < 1 {
+					span = 1
+				}
+				content := []Element{}
+				if mast.BlockCell(cell) {
+					content = r.blocks(cell.GetChildren())
+				} else if inl := r.inlines(cell.GetChildren()); len(inl) > 0 {
+					content = append(content, Element{T: "Plain", C: inl})
+				}
+				cells = append(cells, []interface{}{attr(nil, "", nil, nil), align(cell.Align), rowspan, span, content})
+				if len(rs) == 0 && len(colspecs) < len(row.GetChildren()) {
+					colspecs = append(colspecs, []interface{}{align(cell.Align), Element{T: "ColWidthDefault"}})
+				}
+			}
+			rs = append(rs, []interface{}{attr(nil, "", nil, nil), cells})
+		}
+		return rs
+	}
+	for _, section := range tab.GetChildren() {
+		switch section.(type) {
+		case *ast.TableHeader:
+			head = append(head, rows(section)...)
+		case *ast.TableBody:
+			body = append(body, rows(section)...)
+		case *ast.TableFooter:
+			foot = append(foot, rows(section)...)
+		}
+	}
+	if head == nil {
+		head = [][]interface{}{}
+	}
+	if body == nil {
+		body = [][]interface{}{}
+	}
+	if foot == nil {
+		foot = [][]interface{}{}
+	}
+	capt := []interface{}{nil, []Element{}}
+	if caption != nil {
+		capt = []interface{}{nil, []Element{{T: "Plain", C: caption}}}
+	}
+	bodies := []interface{}{[]interface{}{attr(nil, "", nil, nil), 0, [][]interface{}{}, body}}
+	return Element{T: "Table", C: []interface{}{
+		attr(tab, "", nil, nil),
+		capt,
+		colspecs,
+		[]interface{}{attr(nil, "", nil, nil), head},
+		bodies,
+		[]interface{}{attr(nil, "", nil, nil), foot},
+	}}
+}
+
+// figure converts a node with a caption. Tables get the caption as the table caption, a quote gets its
+// attribution as the last paragraph and the rest becomes a Figure.
+func (r *Renderer) figure(fig *ast.CaptionFigure) []Element {
+	var caption []Element
+	content := []ast.Node{}
+	for _, c := range fig.GetChildren() {
+		if capt, ok := c.(*ast.Caption); ok {
+			caption = r.inlines(capt.GetChildren())
+			continue
+		}
+		content = append(content, c)
+	}
+	if len(content) == 1 {
+		switch c := content[0].(type) {
+		case *ast.Table:
+			t := r.table(c, caption)
+			if fig.HeadingID != "" {
+				t.C.([]interface{})[0].([]interface{})[0] = fig.HeadingID
+			}
+			return []Element{t}
+		case *ast.BlockQuote:
+			blocks := r.blocks(c.GetChildren())
+			if caption != nil {
+				attribution := append([]Element{{T: "Str", C: "—"}, {T: "Space"}}, caption...)
+				blocks = append(blocks, Element{T: "Para", C: attribution})
+			}
+			return []Element{{T: "BlockQuote", C: blocks}}
+		}
+	}
+	capt := []interface{}{nil, []Element{}}
+	if caption != nil {
+		capt = []interface{}{nil, []Element{{T: "Plain", C: caption}}}
+	}
+	return []Element{{T: "Figure", C: []interface{}{attr(fig, fig.HeadingID, nil, nil), capt, r.blocks(content)}}}
+}
+
+// reference returns the text for a bibliography item.
+func (r *Renderer) reference(item *mast.BibliographyItem) []Element {
+	ref := item.Reference
+	out := text("[" + string(item.Anchor) + "]")
+	if ref == nil {
+		return out
+	}
+	names := []string{}
+	for _, a := range ref.Front.Authors {
+		if a.Fullname != "" {
+			names = append(names, a.Fullname)
+		}
+	}
+	if len(names) > 0 {
+		out = append(out, Element{T: "Space"})
+		out = append(out, text(strings.Join(names, ", ")+",")...)
+	}
+	out = append(out, Element{T: "Space"})
+	out = append(out, Element{T: "Quoted", C: []interface{}{Element{T: "DoubleQuote"}, text(ref.Front.Title)}})
+	for _, s := range ref.Series {
+		out = append(out, Element{T: "Str", C: ","}, Element{T: "Space"})
+		out = append(out, text(s.Name+" "+s.Value)...)
+	}
+	if d := ref.Front.Date; d != nil && d.Year != "" {
+		out = append(out, Element{T: "Str", C: ","}, Element{T: "Space"})
+		out = append(out, text(strings.TrimSpace(d.Month+" "+d.Year))...)
+	}
+	if ref.Target != "" {
+		link := Element{T: "Link", C: []interface{}{attr(nil, "", nil, nil), text(ref.Target), []string{ref.Target, ""}}}
+		out = append(out, Element{T: "Str", C: ","}, Element{T: "Space"}, link)
+	}
+	return append(out, Element{T: "Str", C: "."})
+}
@@ -0,0 +1,156 @@
+package pandoc
+
+import (
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// text splits s into Str, Space and SoftBreak elements.
+func text(s string) []Element {
+	out := []Element{}
+	word := &strings.Builder{}
+	flush := func() {
+		if word.Len() > 0 {
+			out = append(out, Element{T: "Str", C: word.String()})
+			word.Reset()
+		}
+	}
+	for _, r := range s {
+		switch r {
+		case ' ', '\t':
+			flush()
+			if len(out) > 0 && (out[len(out)-1].T == "Space" || out[len(out)-1].T == "SoftBreak") {
+				continue
+			}
+			out = append(out, Element{T: "Space"})
+		case '\n':
+			flush()
+			if len(out) > 0 && out[len(out)-1].T == "Space" {
+				out = out[:len(out)-1]
+			}
+			out = append(out, Element{T: "SoftBreak"})
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+	return out
+}
+
+// inlines converts inline nodes.
+func (r *Renderer) inlines(nodes []ast.Node) []Element {
+	inlines := []Element{}
+	for _, n := range nodes {
+		inlines = append(inlines, r.inline(n)...)
+	}
+	return inlines
+}
+
+func (r *Renderer) inline(node ast.Node) []Element {
+	if r.opts.ElementHook != nil {
+		if e := r.opts.ElementHook(node); e != nil {
+			return e
+		}
+	}
+
+	switch node := node.(type) {
+	case *ast.Text:
+		return text(string(node.Literal))
+	case *ast.Softbreak:
+		return []Element{{T: "SoftBreak"}}
+	case *ast.Hardbreak:
+		return []Element{{T: "LineBreak"}}
+	case *ast.NonBlockingSpace:
+		return []Element{{T: "Str", C: "\u00a0"}}
+	case *ast.Emph:
+		return []Element{{T: "Emph", C: r.inlines(node.GetChildren())}}
+	case *ast.Strong:
+		return []Element{{T: "Strong", C: r.inlines(node.GetChildren())}}
+	case *ast.Del:
+		return []Element{{T: "Strikeout", C: r.inlines(node.GetChildren())}}
+	case *ast.Subscript:
+		return []Element{{T: "Subscript", C: text(string(node.Literal))}}
+	case *ast.Superscript:
+		return []Element{{T: "Superscript", C: text(string(node.Literal))}}
+	case *ast.Code:
+		return []Element{{T: "Code", C: []interface{}{attr(node, "", nil, nil), string(node.Literal)}}}
+	case *ast.Math:
+		return []Element{{T: "Math", C: []interface{}{Element{T: "InlineMath"}, string(node.Literal)}}}
+	case *ast.HTMLSpan:
+		return []Element{{T: "RawInline", C: []interface{}{"html", string(node.Literal)}}}
+	case *ast.Link:
+		if node.Footnote != nil {
+			return []Element{{T: "Note", C: r.blocks(node.Footnote.GetChildren())}}
+		}
+		return []Element{{T: "Link", C: []interface{}{attr(node, "", nil, nil), r.inlines(node.GetChildren()), []string{string(node.Destination), string(node.Title)}}}}
+	case *ast.Image:
+		return []Element{{T: "Image", C: []interface{}{attr(node, "", nil, nil), r.inlines(node.GetChildren()), []string{string(node.Destination), string(node.Title)}}}}
+	case *ast.CrossReference:
+		content := r.inlines(node.GetChildren())
+		if len(content) == 0 {
+			content = text(string(node.Destination))
+		}
+		return []Element{{T: "Link", C: []interface{}{attr(node, "", []string{"cross-reference"}, nil), content, []string{"#" + string(node.Destination), ""}}}}
+	case *ast.Citation:
+		return []Element{r.citation(node)}
+	case *ast.Index:
+		kv := [][]string{{"item", string(node.Item)}}
+		if len(node.Subitem) > 0 {
+			kv = append(kv, []string{"subitem", string(node.Subitem)})
+		}
+		if node.Primary {
+			kv = append(kv, []string{"primary", "true"})
+		}
+		return []Element{{T: "Span", C: []interface{}{attr(node, node.ID, []string{"index"}, kv), []Element{}}}}
+	case *ast.Callout:
+		return []Element{{T: "Span", C: []interface{}{attr(node, "", []string{"callout"}, nil), text(string(node.ID))}}}
+	case *mast.Cref:
+		kv := [][]string{}
+		if node.Source != "" {
+			kv = append(kv, []string{"source", node.Source})
+		}
+		return []Element{{T: "Span", C: []interface{}{attr(node, "", []string{"cref"}, kv), text(string(node.Literal))}}}
+	case *mast.Abbreviation:
+		return text(node.String())
+	case *mast.Unicode:
+		return []Element{{T: "Span", C: []interface{}{attr(node, "", []string{"unicode"}, [][]string{{"format", node.Format}}), text(node.String())}}}
+	}
+	return r.inlines(node.GetChildren())
+}
+
+var citationTypes = map[ast.CitationTypes]string{
+	ast.CitationTypeNormative:   "normative",
+	ast.CitationTypeInformative: "informative",
+	ast.CitationTypeSuppressed:  "suppressed",
+}
+
+// citation converts a citation into a Cite wrapped in a span that records the citation types.
+func (r *Renderer) citation(node *ast.Citation) Element {
+	citations := []interface{}{}
+	types := []string{}
+	literal := []string{}
+	for i, dest := range node.Destination {
+		mode := "NormalCitation"
+		if node.Type[i] == ast.CitationTypeSuppressed {
+			mode = "SuppressAuthor"
+		}
+		suffix := []Element{}
+		if len(node.Suffix) > i && len(node.Suffix[i]) > 0 {
+			suffix = append([]Element{{T: "Str", C: ","}, {T: "Space"}}, text(string(node.Suffix[i]))...)
+		}
+		citations = append(citations, map[string]interface{}{
+			"citationId":      string(dest),
+			"citationPrefix":  []Element{},
+			"citationSuffix":  suffix,
+			"citationMode":    Element{T: mode},
+			"citationNoteNum": 0,
+			"citationHash":    0,
+		})
+		types = append(types, citationTypes[node.Type[i]])
+		literal = append(literal, "@"+string(dest))
+	}
+	cite := Element{T: "Cite", C: []interface{}{citations, text("[" + strings.Join(literal, "; ") + "]")}}
+	return Element{T: "Span", C: []interface{}{attr(node, "", []string{"citation"}, [][]string{{"type", strings.Join(types, " ")}}), []Element{cite}}}
+}
@@ -0,0 +1,149 @@
+// The package pandoc outputs Pandoc's JSON AST from mmark markdown, so documents can be converted with
+// pandoc -f json. Nodes that only exist in mmark are mapped to spans and divs with attributes.
+package pandoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// APIVersion is the version of the pandoc-types API we output.
+var APIVersion = []int{1, 23, 1}
+
+// RendererOptions is a collection of supplementary parameters tweaking
+// the behavior of various parts of the Pandoc renderer.
+type RendererOptions struct {
+	// if set, called for every block and inline node before the default conversion.
+	// Returning nil uses the default conversion.
+	ElementHook func(node ast.Node) []Element
+}
+
+// Renderer implements Renderer interface for Pandoc JSON output.
+type Renderer struct {
+	opts RendererOptions
+
+	Title *mast.Title
+}
+
+// Element is a Pandoc AST element, a block or an inline.
+type Element struct {
+	T string      `json:"t"`
+	C interface{} `json:"c,omitempty"`
+}
+
+// Document is the top level Pandoc document.
+type Document struct {
+	APIVersion []int                  `json:"pandoc-api-version"`
+	Meta       map[string]interface{} `json:"meta"`
+	Blocks     []Element              `json:"blocks"`
+}
+
+// NewRenderer creates and configures an Renderer object, which satisfies the Renderer interface.
+func NewRenderer(opts RendererOptions) *Renderer { return &Renderer{opts: opts} }
+
+// RenderNode does nothing, the entire tree is converted in RenderFooter.
+func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.WalkStatus {
+	return ast.Terminate
+}
+
+// RenderHeader does nothing.
+func (r *Renderer) RenderHeader(w io.Writer, _ ast.Node) {}
+
+// RenderFooter writes the Pandoc JSON for doc to w.
+func (r *Renderer) RenderFooter(w io.Writer, doc ast.Node) {
+	buf, err := json.Marshal(r.Convert(doc))
+	if err != nil {
+		fmt.Fprintf(w, `{"error": %q}`, err.Error())
+		return
+	}
+	w.Write(buf)
+}
+
+// Convert converts doc to a Pandoc document.
+func (r *Renderer) Convert(doc ast.Node) *Document {
+	d := &Document{APIVersion: APIVersion, Meta: map[string]interface{}{}}
+	d.Blocks = r.blocks(doc.GetChildren())
+	if d.Blocks == nil {
+		d.Blocks = []Element{}
+	}
+	if r.Title != nil {
+		d.Meta = r.meta(r.Title)
+	}
+	return d
+}
+
+// meta converts the title block to the document's metadata.
+func (r *Renderer) meta(t *mast.Title) map[string]interface{} {
+	m := map[string]interface{}{}
+	str := func(s string) Element { return Element{T: "MetaInlines", C: text(s)} }
+	if t.Title != "" {
+		m["title"] = str(t.Title)
+	}
+	if t.Abbrev != "" {
+		m["title-abbrev"] = str(t.Abbrev)
+	}
+	authors := []Element{}
+	for _, a := range t.Author {
+		authors = append(authors, str(a.Fullname))
+	}
+	if len(authors) > 0 {
+		m["author"] = Element{T: "MetaList", C: authors}
+	}
+	if !t.Date.IsZero() {
+		m["date"] = str(t.Date.Format("2006-01-02"))
+	}
+	if t.Language != "" {
+		m["lang"] = Element{T: "MetaString", C: t.Language}
+	}
+	keywords := []Element{}
+	for _, k := range t.Keyword {
+		if k != "" {
+			keywords = append(keywords, str(k))
+		}
+	}
+	if len(keywords) > 0 {
+		m["keywords"] = Element{T: "MetaList", C: keywords}
+	}
+	return m
+}
+
+// attr returns a Pandoc attribute, with node's attribute (if any) merged in.
+func attr(node ast.Node, id string, classes []string, kv [][]string) []interface{} {
+	if classes == nil {
+		classes = []string{}
+	}
+	if kv == nil {
+		kv = [][]string{}
+	}
+	var a *ast.Attribute
+	if node != nil {
+		if c := node.AsContainer(); c != nil {
+			a = c.Attribute
+		}
+		if l := node.AsLeaf(); l != nil {
+			a = l.Attribute
+		}
+	}
+	if a != nil {
+		if len(a.ID) > 0 {
+			id = string(a.ID)
+		}
+		for _, c := range a.Classes {
+			classes = append(classes, string(c))
+		}
+		keys := []string{}
+		for k := range a.Attrs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			kv = append(kv, []string{k, string(a.Attrs[k])})
+		}
+	}
+	return []interface{}{id, classes, kv}
+}
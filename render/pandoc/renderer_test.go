@@ -0,0 +1,47 @@
+package pandoc
+
+import (
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/mparser"
+)
+
+func TestPandoc(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{
+			"# Hi {#hi}\n",
+			`{"pandoc-api-version":[1,23,1],"meta":{},"blocks":[{"t":"Header","c":[1,["hi",[],[]],[{"t":"Str","c":"Hi"}]]}]}`,
+		},
+		{
+			"A *b*\nc.\n",
+			`{"pandoc-api-version":[1,23,1],"meta":{},"blocks":[{"t":"Para","c":[{"t":"Str","c":"A"},{"t":"Space"},{"t":"Emph","c":[{"t":"Str","c":"b"}]},{"t":"SoftBreak"},{"t":"Str","c":"c."}]}]}`,
+		},
+		{
+			"* a\n* b\n",
+			`{"pandoc-api-version":[1,23,1],"meta":{},"blocks":[{"t":"BulletList","c":[[{"t":"Plain","c":[{"t":"Str","c":"a"}]}],[{"t":"Plain","c":[{"t":"Str","c":"b"}]}]]}]}`,
+		},
+		{
+			"See [@!RFC2119].\n",
+			`{"pandoc-api-version":[1,23,1],"meta":{},"blocks":[{"t":"Para","c":[{"t":"Str","c":"See"},{"t":"Space"},{"t":"Span","c":[["",["citation"],[["type","normative"]]],[{"t":"Cite","c":[[{"citationHash":0,"citationId":"RFC2119","citationMode":{"t":"NormalCitation"},"citationNoteNum":0,"citationPrefix":[],"citationSuffix":[]}],[{"t":"Str","c":"[@RFC2119]"}]]}]]},{"t":"Str","c":"."}]}]}`,
+		},
+		{
+			"A:\n\n* B\n",
+			`{"pandoc-api-version":[1,23,1],"meta":{},"blocks":[{"t":"Para","c":[{"t":"Str","c":"A:"}]},{"t":"BulletList","c":[[{"t":"Plain","c":[{"t":"Str","c":"B"}]}]]}]}`,
+		},
+	}
+
+	for i, tc := range tests {
+		p := parser.NewWithExtensions(mparser.Extensions)
+		p.Opts = parser.Options{ParserHook: mparser.Hook}
+		doc := markdown.Parse([]byte(tc.in), p)
+		got := string(markdown.Render(doc, NewRenderer(RendererOptions{})))
+		if got != tc.want {
+			t.Errorf("test %d: got\n%s\nwant\n%s", i, got, tc.want)
+		}
+	}
+}
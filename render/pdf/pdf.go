@@ -0,0 +1,228 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/mmarkdown/mmark/v2/render/text"
+)
+
+// document holds the settings for writing a PDF file.
+type document struct {
+	size    Size
+	width   int // characters on a line
+	length  int // lines on a page
+	title   string
+	authors []string
+
+	objects [][]byte // object i has number i+1
+}
+
+// margin is the minimal margin around the text in points.
+const margin = 36
+
+// object sets the contents of object n.
+func (d *document) object(n int, format string, a ...interface{}) {
+	for len(d.objects) < n {
+		d.objects = append(d.objects, nil)
+	}
+	d.objects[n-1] = []byte(fmt.Sprintf(format, a...))
+}
+
+// write writes the pages as a PDF file to w, the toc is used for the outline.
+func (d *document) write(w io.Writer, pages [][]string, toc []text.TOCEntry) {
+	const (
+		catalog = 1 + iota
+		pagesObj
+		font
+		info
+		first // first page object, each page is followed by its content stream.
+	)
+	outline := first + 2*len(pages)
+
+	// the font size is the largest that fits both the line width and the page length, up to 12pt.
+	size := 12.0
+	if s := (d.size.Width - 2*margin) / (0.6 * float64(d.width)); s < size {
+		size = s
+	}
+	if s := (d.size.Height - 2*margin) / float64(d.length); s < size {
+		size = s
+	}
+	left := (d.size.Width - 0.6*size*float64(d.width)) / 2
+	// the ' operator moves to the next line before showing the text, so we start a line above the first.
+	top := d.size.Height - (d.size.Height-size*float64(d.length))/2
+
+	kids := []string{}
+	for i, page := range pages {
+		n := first + 2*i
+		kids = append(kids, fmt.Sprintf("%d 0 R", n))
+		d.object(n, "<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+			pagesObj, number(d.size.Width), number(d.size.Height), font, n+1)
+
+		content := &bytes.Buffer{}
+		fmt.Fprintf(content, "BT\n/F1 %s Tf\n%s TL\n%s %s Td\n", number(size), number(size), number(left), number(top))
+		for _, l := range page {
+			fmt.Fprintf(content, "(%s) '\n", escape(winAnsi(l)))
+		}
+		content.WriteString("ET\n")
+		d.object(n+1, "%s", stream(content.Bytes()))
+	}
+
+	d.object(pagesObj, "<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+	d.object(font, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	d.object(info, "<< /Title %s /Author %s /Producer (mmark) >>", textString(d.title), textString(strings.Join(d.authors, ", ")))
+
+	if len(toc) == 0 || len(pages) == 0 {
+		d.object(catalog, "<< /Type /Catalog /Pages %d 0 R >>", pagesObj)
+	} else {
+		d.object(catalog, "<< /Type /Catalog /Pages %d 0 R /Outlines %d 0 R /PageMode /UseOutlines >>", pagesObj, outline)
+		d.outline(outline, toc, func(page int) int {
+			if page < 1 || page > len(pages) {
+				page = 1
+			}
+			return first + 2*(page-1)
+		})
+	}
+
+	d.output(w, catalog, info)
+}
+
+// item is an item in the document outline.
+type item struct {
+	entry    text.TOCEntry
+	n        int // object number
+	parent   *item
+	children []*item
+}
+
+// outline adds the outline dictionary as object n and the outline items after it. Items with
+// children are closed.
+func (d *document) outline(n int, toc []text.TOCEntry, page func(int) int) {
+	root := &item{n: n, entry: text.TOCEntry{Level: -1}}
+	stack := []*item{root}
+	for i, e := range toc {
+		for len(stack) > 1 && stack[len(stack)-1].entry.Level >= e.Level {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1]
+		it := &item{entry: e, n: n + 1 + i, parent: parent}
+		parent.children = append(parent.children, it)
+		stack = append(stack, it)
+	}
+
+	d.object(n, "<< /Type /Outlines%s /Count %d >>", firstLast(root), len(root.children))
+	var add func(*item)
+	add = func(parent *item) {
+		for i, it := range parent.children {
+			title := strings.TrimSpace(it.entry.Number + " " + it.entry.Title)
+			dict := fmt.Sprintf("<< /Title %s /Parent %d 0 R /Dest [%d 0 R /XYZ null null null]", textString(title), parent.n, page(it.entry.Page))
+			if i > 0 {
+				dict += fmt.Sprintf(" /Prev %d 0 R", parent.children[i-1].n)
+			}
+			if i < len(parent.children)-1 {
+				dict += fmt.Sprintf(" /Next %d 0 R", parent.children[i+1].n)
+			}
+			if len(it.children) > 0 {
+				dict += firstLast(it) + fmt.Sprintf(" /Count -%d", len(it.children))
+			}
+			d.object(it.n, "%s >>", dict)
+			add(it)
+		}
+	}
+	add(root)
+}
+
+func firstLast(it *item) string {
+	if len(it.children) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" /First %d 0 R /Last %d 0 R", it.children[0].n, it.children[len(it.children)-1].n)
+}
+
+// output writes the objects, the cross reference table and the trailer.
+func (d *document) output(w io.Writer, root, info int) {
+	buf := &bytes.Buffer{}
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(d.objects))
+	for i, o := range d.objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(buf, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(buf, "xref\n0 %d\n0000000000 65535 f \n", len(d.objects)+1)
+	for _, o := range offsets {
+		fmt.Fprintf(buf, "%010d 00000 n \n", o)
+	}
+	fmt.Fprintf(buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(d.objects)+1, root, info, xref)
+	w.Write(buf.Bytes())
+}
+
+// stream returns a compressed stream object for data.
+func stream(data []byte) string {
+	buf := &bytes.Buffer{}
+	z := zlib.NewWriter(buf)
+	z.Write(data)
+	z.Close()
+	return fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", buf.Len(), buf.Bytes())
+}
+
+// number formats f with at most two decimals.
+func number(f float64) string {
+	s := fmt.Sprintf("%.2f", f)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// escape escapes the characters that have a meaning in a PDF string.
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`).Replace(s)
+}
+
+// textString returns s as a PDF text string, UTF-16 encoded if s isn't ASCII.
+func textString(s string) string {
+	ascii := true
+	for _, r := range s {
+		if r < 0x20 || r > 0x7e {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return "(" + escape(s) + ")"
+	}
+	b := &strings.Builder{}
+	b.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(b, "%04X", u)
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+// winAnsiExtra are the characters in WinAnsiEncoding outside of Latin-1.
+var winAnsiExtra = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b,
+	'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// winAnsi encodes s in WinAnsiEncoding, characters that can't be encoded are replaced with a '?'.
+func winAnsi(s string) string {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r < 0x80 || (r >= 0xa0 && r <= 0xff):
+			b = append(b, byte(r))
+		case winAnsiExtra[r] != 0:
+			b = append(b, winAnsiExtra[r])
+		default:
+			b = append(b, '?')
+		}
+	}
+	return string(b)
+}
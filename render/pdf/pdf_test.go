@@ -0,0 +1,59 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mmarkdown/mmark/v2/render/text"
+)
+
+func TestWrite(t *testing.T) {
+	doc := &document{size: Letter, width: 72, length: 58, title: "Évil"}
+	pages := [][]string{{"one (1)", "two"}, {"three"}}
+	toc := []text.TOCEntry{
+		{Level: 0, Number: "1.", Title: "Introduction", Page: 1},
+		{Level: 1, Number: "1.1.", Title: "Terminology", Page: 1},
+		{Level: 0, Number: "2.", Title: "Syntax", Page: 2},
+	}
+	buf := &bytes.Buffer{}
+	doc.write(buf, pages, toc)
+	got := buf.Bytes()
+
+	if !bytes.HasPrefix(got, []byte("%PDF-1.4\n")) {
+		t.Fatalf("expected PDF header, got %q", got[:10])
+	}
+	// every entry in the cross reference table must point to its object.
+	xref := bytes.Index(got, []byte("\nxref\n")) + 1
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n`).FindAllSubmatch(got[xref:], -1)
+	if len(offsets) != len(doc.objects) {
+		t.Fatalf("expected %d objects in the xref table, got %d", len(doc.objects), len(offsets))
+	}
+	for i, o := range offsets {
+		off, _ := strconv.Atoi(string(o[1]))
+		if !bytes.HasPrefix(got[off:], []byte(fmt.Sprintf("%d 0 obj", i+1))) {
+			t.Errorf("xref entry %d doesn't point to its object", i+1)
+		}
+	}
+	if !bytes.Contains(got, []byte(fmt.Sprintf("startxref\n%d\n", xref))) {
+		t.Errorf("startxref doesn't point to the xref table")
+	}
+	if !bytes.Contains(got, []byte("/Count 2 >>")) {
+		t.Errorf("expected two pages and two top level outline items")
+	}
+	if !bytes.Contains(got, []byte("/Title (1.1. Terminology) /Parent 10 0 R")) {
+		t.Errorf("expected nested outline item, got:\n%s", got)
+	}
+	if !strings.Contains(string(got), "/Title <FEFF00C900760069006C>") {
+		t.Errorf("expected UTF-16 title, got:\n%s", got)
+	}
+}
+
+func TestWinAnsi(t *testing.T) {
+	if s := winAnsi("é – ✓"); s != "\xe9 \x96 ?" {
+		t.Errorf("got %q", s)
+	}
+}
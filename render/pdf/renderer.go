@@ -0,0 +1,133 @@
+// The package pdf outputs PDF from mmark markdown. The document is laid out by the text renderer and
+// each page is set in Courier, so the PDF looks like the plain text output. The table of contents is
+// added as the document outline (bookmarks).
+package pdf
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/v2/lang"
+	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/render/text"
+)
+
+// Flags control optional behavior of the PDF renderer.
+type Flags int
+
+// PDF renderer configuration options.
+const (
+	FlagsNone   Flags = 0
+	PDFFragment Flags = 1 << iota // Don't generate a complete document: no title page, TOC and page headers
+
+	CommonFlags Flags = FlagsNone
+)
+
+// Page sizes in points.
+var (
+	Letter = Size{612, 792}
+	A4     = Size{595, 842}
+)
+
+// Size is the width and height of a page in points.
+type Size struct {
+	Width, Height float64
+}
+
+// RendererOptions is a collection of supplementary parameters tweaking
+// the behavior of various parts of the PDF renderer.
+type RendererOptions struct {
+	Flags Flags // Flags allow customizing this renderer's behavior
+
+	Size       Size // Size of the pages, defaults to Letter.
+	Width      int  // Width of the text in characters, defaults to 72.
+	PageLength int  // Number of lines on a page, including header and footer, defaults to 58.
+
+	Language lang.Lang // Output language for the document.
+
+	// if set, called at the start of RenderNode(). Allows replacing rendering of some nodes
+	RenderNodeHook html.RenderNodeFunc
+}
+
+// Renderer implements Renderer interface for PDF output.
+type Renderer struct {
+	opts RendererOptions
+	text *text.Renderer
+
+	Title *mast.Title
+}
+
+// NewRenderer creates and configures an Renderer object, which satisfies the Renderer interface.
+func NewRenderer(opts RendererOptions) *Renderer {
+	if opts.Size.Width == 0 || opts.Size.Height == 0 {
+		opts.Size = Letter
+	}
+	if opts.Width == 0 {
+		opts.Width = 72
+	}
+	if opts.PageLength == 0 {
+		opts.PageLength = 58
+	}
+	topts := text.RendererOptions{
+		Width:      opts.Width,
+		PageLength: opts.PageLength,
+		Language:   opts.Language,
+
+		RenderNodeHook: opts.RenderNodeHook,
+	}
+	if opts.Flags&PDFFragment != 0 {
+		topts.Flags |= text.TextFragment
+	}
+	return &Renderer{opts: opts, text: text.NewRenderer(topts)}
+}
+
+// RenderNode renders a markdown node with the text renderer.
+func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.WalkStatus {
+	if t, ok := node.(*mast.Title); ok {
+		r.Title = t
+	}
+	return r.text.RenderNode(w, node, entering)
+}
+
+// RenderHeader calls the text renderer's RenderHeader.
+func (r *Renderer) RenderHeader(w io.Writer, doc ast.Node) { r.text.RenderHeader(w, doc) }
+
+// RenderFooter lets the text renderer paginate the document and writes the PDF to w.
+func (r *Renderer) RenderFooter(w io.Writer, node ast.Node) {
+	buf := &bytes.Buffer{}
+	r.text.RenderFooter(buf, node)
+
+	pages := [][]string{}
+	for _, page := range bytes.Split(buf.Bytes(), []byte("\f\n")) {
+		lines := lines(page)
+		// a fragment isn't paginated by the text renderer.
+		for len(lines) > r.opts.PageLength {
+			pages = append(pages, lines[:r.opts.PageLength])
+			lines = lines[r.opts.PageLength:]
+		}
+		pages = append(pages, lines)
+	}
+
+	doc := &document{size: r.opts.Size, width: r.opts.Width, length: r.opts.PageLength}
+	if r.Title != nil && r.Title.TitleData != nil {
+		doc.title = r.Title.Title
+		for _, a := range r.Title.Author {
+			if a.Fullname != "" {
+				doc.authors = append(doc.authors, a.Fullname)
+			}
+		}
+	}
+	doc.write(w, pages, r.text.TOC())
+}
+
+// lines splits the text of a page in lines, without the trailing empty line.
+func lines(page []byte) []string {
+	page = bytes.TrimSuffix(page, []byte("\n"))
+	if len(page) == 0 {
+		return nil
+	}
+	return strings.Split(string(page), "\n")
+}
@@ -0,0 +1,151 @@
+// The package refs outputs the resolved bibliography of a mmark document as JSON: every cited
+// reference with its type and the details from its XML, after the references have been fetched and
+// merged with the ones in the document. This is meant for tooling that audits the references, i.e.
+// to check their targets or to make a reading list.
+package refs
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/mast/reference"
+)
+
+// RendererOptions is a collection of supplementary parameters tweaking
+// the behavior of various parts of the refs renderer.
+type RendererOptions struct{}
+
+// Renderer implements Renderer interface for bibliography output.
+type Renderer struct {
+	opts RendererOptions
+
+	refs []Reference
+}
+
+// Bibliography is the resolved bibliography of a document.
+type Bibliography struct {
+	References []Reference `json:"references"`
+}
+
+// Reference is a cited reference. A reference without XML, Resolved is false, is left for xml2rfc
+// to resolve. For a reference group, i.e. a BCP or STD, the references of the group are in
+// References.
+type Reference struct {
+	Anchor     string       `json:"anchor"`
+	Type       string       `json:"type"` // normative, informative or suppressed
+	Resolved   bool         `json:"resolved"`
+	Title      string       `json:"title,omitempty"`
+	Target     string       `json:"target,omitempty"`
+	Date       *Date        `json:"date,omitempty"`
+	Authors    []Author     `json:"authors,omitempty"`
+	SeriesInfo []SeriesInfo `json:"seriesInfo,omitempty"`
+	RefContent []string     `json:"refcontent,omitempty"`
+	References []Reference  `json:"references,omitempty"`
+}
+
+// Author is an author of a reference.
+type Author struct {
+	Fullname     string `json:"fullname,omitempty"`
+	Initials     string `json:"initials,omitempty"`
+	Surname      string `json:"surname,omitempty"`
+	Role         string `json:"role,omitempty"`
+	Organization string `json:"organization,omitempty"`
+}
+
+// Date is the date of a reference.
+type Date struct {
+	Year  string `json:"year,omitempty"`
+	Month string `json:"month,omitempty"`
+	Day   string `json:"day,omitempty"`
+}
+
+// SeriesInfo is a series the reference is part of, i.e. RFC 2119 or DOI 10.1145/3133956.
+type SeriesInfo struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// NewRenderer creates and configures an Renderer object, which satisfies the Renderer interface.
+func NewRenderer(opts RendererOptions) *Renderer {
+	return &Renderer{opts: opts, refs: []Reference{}}
+}
+
+// RenderNode gathers the items of the bibliography.
+func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.GoToNext
+	}
+	item, ok := node.(*mast.BibliographyItem)
+	if !ok {
+		return ast.GoToNext
+	}
+	ref := Reference{Anchor: string(item.Anchor), Type: citationType(item.Type)}
+	switch {
+	case item.Reference != nil:
+		ref = fromReference(*item.Reference, ref)
+	case item.ReferenceGroup != nil:
+		g := reference.Group{}
+		if err := xml.Unmarshal(item.ReferenceGroup, &g); err != nil {
+			break
+		}
+		ref.Resolved = true
+		ref.Target = g.Target
+		for _, x := range g.References {
+			ref.References = append(ref.References, fromReference(x, Reference{Anchor: x.Anchor, Type: ref.Type}))
+		}
+	}
+	r.refs = append(r.refs, ref)
+	return ast.GoToNext
+}
+
+// RenderHeader does nothing, the bibliography is written when the whole document has been seen.
+func (r *Renderer) RenderHeader(w io.Writer, doc ast.Node) {}
+
+// RenderFooter writes the bibliography as JSON to w.
+func (r *Renderer) RenderFooter(w io.Writer, _ ast.Node) {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	enc.Encode(Bibliography{References: r.refs})
+	w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
+
+// fromReference fills in ref from the parsed XML reference x.
+func fromReference(x reference.Reference, ref Reference) Reference {
+	ref.Resolved = true
+	ref.Title = x.Front.Title
+	ref.Target = x.Target
+	if ref.Target == "" && x.Format != nil {
+		ref.Target = x.Format.Target
+	}
+	if d := x.Front.Date; d != nil && (d.Year != "" || d.Month != "" || d.Day != "") {
+		ref.Date = &Date{Year: d.Year, Month: d.Month, Day: d.Day}
+	}
+	for _, a := range x.Front.Authors {
+		author := Author{Fullname: a.Fullname, Initials: a.Initials, Surname: a.Surname, Role: a.Role}
+		if a.Organization != nil {
+			author.Organization = a.Organization.Value
+		}
+		ref.Authors = append(ref.Authors, author)
+	}
+	for _, s := range x.Series {
+		ref.SeriesInfo = append(ref.SeriesInfo, SeriesInfo{Name: s.Name, Value: s.Value})
+	}
+	ref.RefContent = x.RefContent
+	return ref
+}
+
+func citationType(t ast.CitationTypes) string {
+	switch t {
+	case ast.CitationTypeNormative:
+		return "normative"
+	case ast.CitationTypeSuppressed:
+		return "suppressed"
+	}
+	return "informative"
+}
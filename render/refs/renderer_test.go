@@ -0,0 +1,63 @@
+package refs
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/mparser"
+)
+
+const doc = `{backmatter}
+
+See [@!RFC2119], [@?BCP14] and [@!pandoc] and again [@pandoc].
+
+<reference anchor='pandoc' target='https://pandoc.org'>
+  <front>
+    <title>Pandoc</title>
+    <author fullname='John MacFarlane'><organization>UC Berkeley</organization></author>
+    <date year='2024'/>
+  </front>
+</reference>
+
+<referencegroup anchor='BCP14' target='https://www.rfc-editor.org/info/bcp14'>
+  <reference anchor='RFC2119' target='https://www.rfc-editor.org/info/rfc2119'>
+    <front><title>Key words</title><date year='1997' month='March'/></front>
+    <seriesInfo name='RFC' value='2119'/>
+  </reference>
+</referencegroup>
+`
+
+func TestRefs(t *testing.T) {
+	p := parser.NewWithExtensions(mparser.Extensions)
+	p.Opts = parser.Options{ParserHook: mparser.Hook}
+	d := markdown.Parse([]byte(doc), p)
+	mparser.AddBibliography(d)
+	out := markdown.Render(d, NewRenderer(RendererOptions{}))
+
+	b := Bibliography{}
+	if err := json.Unmarshal(out, &b); err != nil {
+		t.Fatalf("failed to parse output: %s: %s", err, out)
+	}
+	if len(b.References) != 3 {
+		t.Fatalf("expected 3 references, got %d: %s", len(b.References), out)
+	}
+	pandoc := b.References[1]
+	if pandoc.Anchor != "pandoc" || pandoc.Type != "normative" || !pandoc.Resolved || pandoc.Title != "Pandoc" {
+		t.Errorf("unexpected reference: %+v", pandoc)
+	}
+	if len(pandoc.Authors) != 1 || pandoc.Authors[0].Organization != "UC Berkeley" || pandoc.Date.Year != "2024" {
+		t.Errorf("unexpected authors or date: %+v", pandoc)
+	}
+	if rfc := b.References[0]; rfc.Anchor != "RFC2119" || rfc.Resolved {
+		t.Errorf("expected unresolved RFC2119, got %+v", rfc)
+	}
+	bcp := b.References[2]
+	if bcp.Type != "informative" || bcp.Target != "https://www.rfc-editor.org/info/bcp14" || len(bcp.References) != 1 {
+		t.Fatalf("unexpected reference group: %+v", bcp)
+	}
+	if s := bcp.References[0].SeriesInfo; len(s) != 1 || s[0].Value != "2119" {
+		t.Errorf("unexpected seriesInfo: %+v", s)
+	}
+}
@@ -0,0 +1,238 @@
+// The package slides outputs a reveal.js slide deck from mmark markdown. Each level 2 heading starts
+// a new slide, a level 1 heading starts a new vertical stack of slides.
+package slides
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/v2/lang"
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+// Flags control optional behavior of the slides renderer.
+type Flags int
+
+// Slides renderer configuration options.
+const (
+	FlagsNone      Flags = 0
+	SlidesFragment Flags = 1 << iota // Don't generate a complete document, only the slides
+
+	CommonFlags Flags = FlagsNone
+)
+
+// Defaults for the reveal.js options.
+const (
+	DefaultTheme      = "white"
+	DefaultTransition = "slide"
+	DefaultReveal     = "https://cdn.jsdelivr.net/npm/reveal.js@5"
+)
+
+// RendererOptions is a collection of supplementary parameters tweaking
+// the behavior of various parts of the slides renderer.
+type RendererOptions struct {
+	Flags    Flags     // Flags allow customizing this renderer's behavior
+	Language lang.Lang // Output language for the document.
+
+	Theme      string // reveal.js theme, defaults to DefaultTheme.
+	Transition string // reveal.js slide transition, defaults to DefaultTransition.
+	Reveal     string // URL where reveal.js is found, defaults to DefaultReveal.
+	Title      string // Title of the page, defaults to the title from the title block.
+	CSS        string // Optional URL to an extra CSS stylesheet.
+
+	// if set, called at the start of RenderNode(). Allows replacing rendering of some nodes
+	RenderNodeHook html.RenderNodeFunc
+}
+
+// Renderer implements Renderer interface for reveal.js output.
+type Renderer struct {
+	opts RendererOptions
+	html *html.Renderer
+
+	Title *mast.Title
+
+	stack bool // a vertical stack (<section>) is open
+	slide bool // a slide (<section>) is open
+}
+
+// NewRenderer creates and configures an Renderer object, which satisfies the Renderer interface.
+func NewRenderer(opts RendererOptions) *Renderer {
+	if opts.Theme == "" {
+		opts.Theme = DefaultTheme
+	}
+	if opts.Transition == "" {
+		opts.Transition = DefaultTransition
+	}
+	if opts.Reveal == "" {
+		opts.Reveal = DefaultReveal
+	}
+	hopts := html.RendererOptions{
+		Flags:          html.CommonFlags | html.FootnoteNoHRTag | html.FootnoteReturnLinks,
+		Comments:       [][]byte{[]byte("//"), []byte("#")},
+		RenderNodeHook: opts.RenderNodeHook,
+	}
+	return &Renderer{opts: opts, html: html.NewRenderer(hopts)}
+}
+
+func (r *Renderer) openSlide(w io.Writer) {
+	r.closeSlide(w)
+	io.WriteString(w, "<section>\n")
+	r.slide = true
+}
+
+func (r *Renderer) closeSlide(w io.Writer) {
+	if r.slide {
+		io.WriteString(w, "</section>\n")
+		r.slide = false
+	}
+}
+
+func (r *Renderer) openStack(w io.Writer) {
+	r.closeStack(w)
+	io.WriteString(w, "<section>\n")
+	r.stack = true
+}
+
+func (r *Renderer) closeStack(w io.Writer) {
+	r.closeSlide(w)
+	if r.stack {
+		io.WriteString(w, "</section>\n")
+		r.stack = false
+	}
+}
+
+// RenderNode renders a markdown node to HTML, top level nodes are put in slides.
+func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.WalkStatus {
+	if entering && topLevel(node) {
+		switch node := node.(type) {
+		case *mast.Title:
+			r.Title = node
+			r.titleSlide(w, node)
+			return ast.GoToNext
+		case *ast.Heading:
+			if node.Level == 1 {
+				r.openStack(w)
+			}
+			if node.Level <= 2 {
+				r.openSlide(w)
+			}
+		case *mast.BibliographyWrapper, *mast.Bibliography, *mast.DocumentIndex, *ast.Footnotes:
+			r.openSlide(w)
+		}
+		if !r.slide {
+			r.openSlide(w)
+		}
+	}
+
+	switch node.(type) {
+	case *ast.DocumentMatter:
+		// sections would span slides.
+		return ast.GoToNext
+	case *ast.Aside:
+		// asides are the speaker notes.
+		if entering {
+			io.WriteString(w, "<aside class=\"notes\">\n")
+			return ast.GoToNext
+		}
+		io.WriteString(w, "</aside>\n")
+		return ast.GoToNext
+	}
+	return r.html.RenderNode(w, node, entering)
+}
+
+// topLevel returns true if node is a direct child of the document, when this is a DocumentMatter
+// node its children are top level nodes.
+func topLevel(node ast.Node) bool {
+	switch node.GetParent().(type) {
+	case *ast.Document, *ast.DocumentMatter:
+		_, matter := node.(*ast.DocumentMatter)
+		return !matter
+	}
+	return false
+}
+
+func (r *Renderer) titleSlide(w io.Writer, t *mast.Title) {
+	r.openStack(w)
+	r.openSlide(w)
+	io.WriteString(w, "<h1>")
+	html.EscapeHTML(w, []byte(t.Title))
+	io.WriteString(w, "</h1>\n")
+	for _, a := range t.Author {
+		if a.Fullname == "" {
+			continue
+		}
+		io.WriteString(w, `<p class="author">`)
+		html.EscapeHTML(w, []byte(a.Fullname))
+		if a.Organization != "" {
+			io.WriteString(w, ", ")
+			html.EscapeHTML(w, []byte(a.Organization))
+		}
+		io.WriteString(w, "</p>\n")
+	}
+	if !t.Date.IsZero() {
+		fmt.Fprintf(w, "<p class=\"date\">%s</p>\n", t.Date.Format("2006-01-02"))
+	}
+	r.closeStack(w)
+}
+
+// RenderHeader writes the HTML head and opens the slides container.
+func (r *Renderer) RenderHeader(w io.Writer, doc ast.Node) {
+	if r.opts.Flags&SlidesFragment != 0 {
+		return
+	}
+	title := r.opts.Title
+	if title == "" {
+		ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+			if t, ok := node.(*mast.Title); ok {
+				title = t.Title
+				return ast.Terminate
+			}
+			return ast.GoToNext
+		})
+	}
+	language := r.opts.Language.String()
+	if language == "" {
+		language = "en"
+	}
+	reveal := strings.TrimSuffix(r.opts.Reveal, "/")
+
+	io.WriteString(w, "<!DOCTYPE html>\n")
+	fmt.Fprintf(w, "<html lang=\"%s\">\n<head>\n", language)
+	io.WriteString(w, "  <meta charset=\"utf-8\">\n")
+	io.WriteString(w, "  <meta name=\"viewport\" content=\"width=device-width, initial-scale=1.0\">\n")
+	io.WriteString(w, "  <title>")
+	html.EscapeHTML(w, []byte(title))
+	io.WriteString(w, "</title>\n")
+	fmt.Fprintf(w, "  <link rel=\"stylesheet\" href=\"%s/dist/reveal.css\">\n", reveal)
+	fmt.Fprintf(w, "  <link rel=\"stylesheet\" href=\"%s/dist/theme/%s.css\">\n", reveal, attrEscape(r.opts.Theme))
+	if r.opts.CSS != "" {
+		fmt.Fprintf(w, "  <link rel=\"stylesheet\" href=\"%s\">\n", attrEscape(r.opts.CSS))
+	}
+	io.WriteString(w, "</head>\n<body>\n")
+	io.WriteString(w, "<div class=\"reveal\">\n<div class=\"slides\">\n")
+}
+
+// RenderFooter closes the open slides and initializes reveal.js.
+func (r *Renderer) RenderFooter(w io.Writer, _ ast.Node) {
+	r.closeStack(w)
+	if r.opts.Flags&SlidesFragment != 0 {
+		return
+	}
+	reveal := strings.TrimSuffix(r.opts.Reveal, "/")
+
+	io.WriteString(w, "</div>\n</div>\n")
+	fmt.Fprintf(w, "<script src=\"%s/dist/reveal.js\"></script>\n", reveal)
+	fmt.Fprintf(w, "<script src=\"%s/plugin/notes/notes.js\"></script>\n", reveal)
+	fmt.Fprintf(w, "<script>Reveal.initialize({hash: true, transition: %q, plugins: [RevealNotes]});</script>\n", r.opts.Transition)
+	io.WriteString(w, "</body>\n</html>\n")
+}
+
+func attrEscape(s string) string {
+	buf := &bytes.Buffer{}
+	html.EscapeHTML(buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,58 @@
+package slides
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/mparser"
+)
+
+const deck = `Intro.
+
+# One
+
+## A
+
+Text.
+
+A> Notes.
+
+## B
+
+# Two
+`
+
+func TestSlides(t *testing.T) {
+	p := parser.NewWithExtensions(mparser.Extensions)
+	p.Opts = parser.Options{ParserHook: mparser.Hook}
+	doc := markdown.Parse([]byte(deck), p)
+	got := string(markdown.Render(doc, NewRenderer(RendererOptions{Flags: SlidesFragment})))
+
+	if n := strings.Count(got, "<section>"); n != 7 {
+		t.Errorf("expected 7 sections, got %d:\n%s", n, got)
+	}
+	if n := strings.Count(got, "</section>"); n != 7 {
+		t.Errorf("expected 7 closed sections, got %d:\n%s", n, got)
+	}
+	if !strings.Contains(got, `<aside class="notes">`) {
+		t.Errorf("expected speaker notes, got:\n%s", got)
+	}
+	if strings.Contains(got, "<html") {
+		t.Errorf("expected no HTML head with SlidesFragment, got:\n%s", got)
+	}
+}
+
+func TestSlidesDocument(t *testing.T) {
+	p := parser.NewWithExtensions(mparser.Extensions)
+	p.Opts = parser.Options{ParserHook: mparser.Hook}
+	doc := markdown.Parse([]byte(deck), p)
+	got := string(markdown.Render(doc, NewRenderer(RendererOptions{Theme: "black", Transition: "fade"})))
+
+	for _, want := range []string{"dist/theme/black.css", `transition: "fade"`, `<div class="slides">`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in the output, got:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package tex
+
+import (
+	"bytes"
+	"html"
+)
+
+// MathML returns the MathML of the TeX math in src. If display is true the formula is a display
+// (block) formula, otherwise it's inline.
+func MathML(src string, display bool) string {
+	b := &bytes.Buffer{}
+	b.WriteString(`<math xmlns="http://www.w3.org/1998/Math/MathML"`)
+	if display {
+		b.WriteString(` display="block"`)
+	}
+	b.WriteString(`>`)
+
+	rows := lines(parse(src))
+	if len(rows) == 1 {
+		mathml(b, rows[0])
+	} else {
+		b.WriteString("<mtable>")
+		for _, r := range rows {
+			b.WriteString("<mtr><mtd>")
+			mathml(b, r)
+			b.WriteString("</mtd></mtr>")
+		}
+		b.WriteString("</mtable>")
+	}
+	b.WriteString("</math>")
+	return b.String()
+}
+
+func mathml(b *bytes.Buffer, n *node) {
+	switch n.kind {
+	case row:
+		if len(n.kids) == 1 {
+			mathml(b, n.kids[0])
+			return
+		}
+		element(b, "mrow", n.kids...)
+	case ident:
+		token(b, "mi", n.text)
+	case function:
+		b.WriteString(`<mi mathvariant="normal">`)
+		b.WriteString(html.EscapeString(n.text))
+		b.WriteString("</mi>")
+	case number:
+		token(b, "mn", n.text)
+	case operator:
+		token(b, "mo", n.text)
+	case text:
+		token(b, "mtext", n.text)
+	case space:
+		b.WriteString(`<mspace width="` + n.text + `"/>`)
+	case frac:
+		element(b, "mfrac", n.kids...)
+	case sqrt:
+		element(b, "msqrt", n.kids...)
+	case root:
+		element(b, "mroot", n.kids...)
+	case sup:
+		element(b, "msup", n.kids...)
+	case sub:
+		element(b, "msub", n.kids...)
+	case subsup:
+		element(b, "msubsup", n.kids...)
+	}
+}
+
+func token(b *bytes.Buffer, name, text string) {
+	b.WriteString("<" + name + ">")
+	b.WriteString(html.EscapeString(text))
+	b.WriteString("</" + name + ">")
+}
+
+// element writes the element name with the children kids, each child is a single element as MathML
+// requires for the children of mfrac, msup and friends.
+func element(b *bytes.Buffer, name string, kids ...*node) {
+	b.WriteString("<" + name + ">")
+	for _, k := range kids {
+		if k.kind == row && len(k.kids) != 1 {
+			element(b, "mrow", k.kids...)
+			continue
+		}
+		mathml(b, k)
+	}
+	b.WriteString("</" + name + ">")
+}
@@ -0,0 +1,332 @@
+// Package tex converts the TeX math of $...$ and $$...$$ to MathML, for HTML output, and to plain
+// text, for the output formats that can only show text, like RFC 7991 XML. Only the commonly used
+// part of TeX math is supported: fractions, roots, sub- and superscripts, Greek letters, operators,
+// functions and text. Unknown commands are shown as their name.
+package tex
+
+import (
+	"strings"
+	"unicode"
+)
+
+type kind int
+
+const (
+	row kind = iota
+	ident
+	function
+	number
+	operator
+	text
+	space
+	frac
+	sqrt
+	root
+	sup
+	sub
+	subsup
+	newline
+)
+
+// node is a parsed element of a formula.
+type node struct {
+	kind  kind
+	text  string // Unicode text for MathML
+	ascii string // text for the plain text output, if different from text
+	kids  []*node
+}
+
+func (n *node) plain() string {
+	if n.ascii != "" {
+		return n.ascii
+	}
+	return n.text
+}
+
+type symbol struct {
+	kind        kind
+	text, ascii string
+}
+
+// symbols are the commands that are a single symbol.
+var symbols = map[string]symbol{
+	"alpha": {ident, "α", "alpha"}, "beta": {ident, "β", "beta"}, "gamma": {ident, "γ", "gamma"},
+	"delta": {ident, "δ", "delta"}, "epsilon": {ident, "ϵ", "epsilon"}, "varepsilon": {ident, "ε", "epsilon"},
+	"zeta": {ident, "ζ", "zeta"}, "eta": {ident, "η", "eta"}, "theta": {ident, "θ", "theta"},
+	"iota": {ident, "ι", "iota"}, "kappa": {ident, "κ", "kappa"}, "lambda": {ident, "λ", "lambda"},
+	"mu": {ident, "μ", "mu"}, "nu": {ident, "ν", "nu"}, "xi": {ident, "ξ", "xi"}, "pi": {ident, "π", "pi"},
+	"rho": {ident, "ρ", "rho"}, "sigma": {ident, "σ", "sigma"}, "tau": {ident, "τ", "tau"},
+	"upsilon": {ident, "υ", "upsilon"}, "phi": {ident, "ϕ", "phi"}, "varphi": {ident, "φ", "phi"},
+	"chi": {ident, "χ", "chi"}, "psi": {ident, "ψ", "psi"}, "omega": {ident, "ω", "omega"},
+	"Gamma": {ident, "Γ", "Gamma"}, "Delta": {ident, "Δ", "Delta"}, "Theta": {ident, "Θ", "Theta"},
+	"Lambda": {ident, "Λ", "Lambda"}, "Xi": {ident, "Ξ", "Xi"}, "Pi": {ident, "Π", "Pi"},
+	"Sigma": {ident, "Σ", "Sigma"}, "Phi": {ident, "Φ", "Phi"}, "Psi": {ident, "Ψ", "Psi"},
+	"Omega": {ident, "Ω", "Omega"}, "infty": {ident, "∞", "infinity"}, "ell": {ident, "ℓ", "l"},
+
+	"cdot": {operator, "⋅", "*"}, "times": {operator, "×", "x"}, "div": {operator, "÷", "/"},
+	"pm": {operator, "±", "+/-"}, "mp": {operator, "∓", "-/+"}, "ast": {operator, "∗", "*"},
+	"leq": {operator, "≤", "<="}, "le": {operator, "≤", "<="}, "geq": {operator, "≥", ">="},
+	"ge": {operator, "≥", ">="}, "neq": {operator, "≠", "!="}, "ne": {operator, "≠", "!="},
+	"approx": {operator, "≈", "~="}, "equiv": {operator, "≡", "=="}, "sim": {operator, "∼", "~"},
+	"ll": {operator, "≪", "<<"}, "gg": {operator, "≫", ">>"}, "propto": {operator, "∝", "~"},
+	"to": {operator, "→", "->"}, "rightarrow": {operator, "→", "->"}, "leftarrow": {operator, "←", "<-"},
+	"Rightarrow": {operator, "⇒", "=>"}, "Leftarrow": {operator, "⇐", "<="}, "leftrightarrow": {operator, "↔", "<->"},
+	"Leftrightarrow": {operator, "⇔", "<=>"}, "mapsto": {operator, "↦", "|->"}, "iff": {operator, "⟺", "<=>"},
+	"in": {operator, "∈", "in"}, "notin": {operator, "∉", "not in"}, "subset": {operator, "⊂", "subset"},
+	"subseteq": {operator, "⊆", "subseteq"}, "cup": {operator, "∪", "union"}, "cap": {operator, "∩", "intersect"},
+	"oplus": {operator, "⊕", "XOR"}, "otimes": {operator, "⊗", "(x)"}, "wedge": {operator, "∧", "AND"},
+	"land": {operator, "∧", "AND"}, "vee": {operator, "∨", "OR"}, "lor": {operator, "∨", "OR"},
+	"neg": {operator, "¬", "NOT "}, "lnot": {operator, "¬", "NOT "}, "forall": {operator, "∀", "for all "},
+	"exists": {operator, "∃", "exists "}, "partial": {operator, "∂", "d"}, "nabla": {operator, "∇", "nabla"},
+	"sum": {operator, "∑", "SUM"}, "prod": {operator, "∏", "PROD"}, "int": {operator, "∫", "INT"},
+	"ldots": {operator, "…", "..."}, "cdots": {operator, "⋯", "..."}, "dots": {operator, "…", "..."},
+	"lfloor": {operator, "⌊", "floor("}, "rfloor": {operator, "⌋", ")"}, "lceil": {operator, "⌈", "ceil("},
+	"rceil": {operator, "⌉", ")"}, "langle": {operator, "⟨", "<"}, "rangle": {operator, "⟩", ">"},
+	"mid": {operator, "∣", "|"}, "vert": {operator, "|", "|"}, "|": {operator, "‖", "||"},
+	"{": {operator, "{", "{"}, "}": {operator, "}", "}"}, "%": {operator, "%", "%"}, "$": {operator, "$", "$"},
+	"#": {operator, "#", "#"}, "_": {operator, "_", "_"}, "&": {operator, "&", "&"}, "bmod": {operator, "mod", "mod"},
+	"mod": {operator, "mod", "mod"},
+
+	",": {space, "0.167em", " "}, ":": {space, "0.222em", " "}, ";": {space, "0.278em", " "},
+	"!": {space, "0em", ""}, " ": {space, "0.333em", " "}, "quad": {space, "1em", "  "}, "qquad": {space, "2em", "    "},
+}
+
+// functions are the commands that are shown as an upright name.
+var functions = map[string]bool{
+	"sin": true, "cos": true, "tan": true, "cot": true, "sec": true, "csc": true, "arcsin": true, "arccos": true,
+	"arctan": true, "sinh": true, "cosh": true, "tanh": true, "log": true, "ln": true, "lg": true, "exp": true,
+	"min": true, "max": true, "sup": true, "inf": true, "lim": true, "det": true, "gcd": true, "deg": true,
+	"dim": true, "ker": true, "Pr": true, "arg": true,
+}
+
+// parser parses TeX math.
+type parser struct {
+	s string
+	i int
+}
+
+// parse parses the formula s into a row.
+func parse(s string) *node {
+	p := &parser{s: s}
+	return p.row(0)
+}
+
+// row parses elements until the closing character end, or the end of the input if end is 0.
+func (p *parser) row(end byte) *node {
+	r := &node{kind: row}
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		if end != 0 && c == end {
+			p.i++
+			return r
+		}
+		n := p.atom()
+		if n == nil {
+			continue
+		}
+		r.kids = append(r.kids, p.scripts(n))
+	}
+	return r
+}
+
+// scripts parses the sub- and superscripts of base.
+func (p *parser) scripts(base *node) *node {
+	var subscript, superscript *node
+	for {
+		p.skipSpace()
+		if p.i >= len(p.s) {
+			break
+		}
+		switch p.s[p.i] {
+		case '_':
+			p.i++
+			subscript = p.argument()
+			continue
+		case '^':
+			p.i++
+			superscript = p.argument()
+			continue
+		case '\'':
+			p.i++
+			superscript = &node{kind: operator, text: "′", ascii: "'"}
+			continue
+		}
+		break
+	}
+	switch {
+	case subscript != nil && superscript != nil:
+		return &node{kind: subsup, kids: []*node{base, subscript, superscript}}
+	case subscript != nil:
+		return &node{kind: sub, kids: []*node{base, subscript}}
+	case superscript != nil:
+		return &node{kind: sup, kids: []*node{base, superscript}}
+	}
+	return base
+}
+
+func (p *parser) skipSpace() {
+	for p.i < len(p.s) && (p.s[p.i] == ' ' || p.s[p.i] == '\t' || p.s[p.i] == '\n') {
+		p.i++
+	}
+}
+
+// argument parses the argument of a command or script: a group or a single element.
+func (p *parser) argument() *node {
+	p.skipSpace()
+	if p.i >= len(p.s) {
+		return &node{kind: row}
+	}
+	if p.s[p.i] == '{' {
+		p.i++
+		return p.row('}')
+	}
+	if c := p.s[p.i]; c >= '0' && c <= '9' {
+		// only the first digit, as in x^23 which is x²3 in TeX.
+		p.i++
+		return &node{kind: number, text: string(c)}
+	}
+	n := p.atom()
+	if n == nil {
+		return &node{kind: row}
+	}
+	return n
+}
+
+// raw returns the text of a group without parsing it, as for \text{...}.
+func (p *parser) raw() string {
+	p.skipSpace()
+	if p.i >= len(p.s) || p.s[p.i] != '{' {
+		return ""
+	}
+	depth := 0
+	start := p.i + 1
+	for ; p.i < len(p.s); p.i++ {
+		switch p.s[p.i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				p.i++
+				return p.s[start : p.i-1]
+			}
+		}
+	}
+	return p.s[start:]
+}
+
+// atom parses a single element, it returns nil for elements that aren't shown.
+func (p *parser) atom() *node {
+	c := p.s[p.i]
+	switch {
+	case c == ' ' || c == '\t' || c == '\n' || c == '&':
+		p.i++
+		return nil
+	case c == '{':
+		p.i++
+		return p.row('}')
+	case c == '}':
+		p.i++ // unbalanced
+		return nil
+	case c == '\\':
+		return p.command()
+	case c >= '0' && c <= '9' || c == '.' && p.i+1 < len(p.s) && p.s[p.i+1] >= '0' && p.s[p.i+1] <= '9':
+		start := p.i
+		for p.i < len(p.s) && (p.s[p.i] >= '0' && p.s[p.i] <= '9' || p.s[p.i] == '.') {
+			p.i++
+		}
+		return &node{kind: number, text: p.s[start:p.i]}
+	}
+	r := []rune(p.s[p.i:])[0]
+	p.i += len(string(r))
+	if unicode.IsLetter(r) {
+		return &node{kind: ident, text: string(r)}
+	}
+	switch r {
+	case '-':
+		return &node{kind: operator, text: "−", ascii: "-"}
+	case '*':
+		return &node{kind: operator, text: "∗", ascii: "*"}
+	}
+	return &node{kind: operator, text: string(r)}
+}
+
+// command parses a command, p.i is at the backslash.
+func (p *parser) command() *node {
+	p.i++
+	if p.i >= len(p.s) {
+		return nil
+	}
+	start := p.i
+	if !unicode.IsLetter(rune(p.s[p.i])) {
+		p.i++ // a single character command: \, \{ \\ and friends
+	} else {
+		for p.i < len(p.s) && unicode.IsLetter(rune(p.s[p.i])) {
+			p.i++
+		}
+	}
+	name := p.s[start:p.i]
+
+	switch name {
+	case "\\":
+		return &node{kind: newline}
+	case "frac", "dfrac", "tfrac":
+		return &node{kind: frac, kids: []*node{p.argument(), p.argument()}}
+	case "sqrt":
+		p.skipSpace()
+		if p.i < len(p.s) && p.s[p.i] == '[' {
+			p.i++
+			index := p.row(']')
+			return &node{kind: root, kids: []*node{p.argument(), index}}
+		}
+		return &node{kind: sqrt, kids: []*node{p.argument()}}
+	case "text", "textrm", "mbox", "textit", "textbf", "mathrm":
+		return &node{kind: text, text: p.raw()}
+	case "operatorname":
+		return &node{kind: function, text: p.raw()}
+	case "mathbf", "mathit", "mathcal", "mathbb", "boldsymbol", "mathsf", "mathtt", "bm":
+		return p.argument()
+	case "left", "right", "big", "Big", "bigg", "Bigg", "bigl", "bigr", "Bigl", "Bigr":
+		p.skipSpace()
+		if p.i < len(p.s) && p.s[p.i] == '.' {
+			p.i++ // no delimiter
+			return nil
+		}
+		if p.i < len(p.s) && (p.s[p.i] == '\\' || !unicode.IsLetter(rune(p.s[p.i]))) {
+			return p.atom()
+		}
+		return nil
+	case "displaystyle", "textstyle", "limits", "nolimits":
+		return nil
+	}
+	if functions[name] {
+		return &node{kind: function, text: name}
+	}
+	if s, ok := symbols[name]; ok {
+		return &node{kind: s.kind, text: s.text, ascii: s.ascii}
+	}
+	return &node{kind: function, text: name}
+}
+
+// lines splits the row r on the newlines, \\.
+func lines(r *node) []*node {
+	rows := []*node{{kind: row}}
+	for _, k := range r.kids {
+		if k.kind == newline {
+			rows = append(rows, &node{kind: row})
+			continue
+		}
+		last := rows[len(rows)-1]
+		last.kids = append(last.kids, k)
+	}
+	return rows
+}
+
+// trim removes the leading and trailing space of every line in s.
+func trim(s string) string {
+	l := strings.Split(s, "\n")
+	for i := range l {
+		l[i] = strings.TrimSpace(l[i])
+	}
+	return strings.Join(l, "\n")
+}
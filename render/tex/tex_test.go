@@ -0,0 +1,40 @@
+package tex
+
+import "testing"
+
+func TestText(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{`x^2 + y^2 = z^2`, `x^2 + y^2 = z^2`},
+		{`\frac{a+1}{b}`, `(a + 1)/b`},
+		{`\sqrt{x} \leq \sqrt[3]{y}`, `sqrt(x) <= root(3, y)`},
+		{`\alpha_i \cdot \beta`, `alpha_i * beta`},
+		{`-x \in S`, `-x in S`},
+		{`f(-x) = \sin x`, `f(-x) = sin x`},
+		{`\sum_{i=1}^{n} i`, `SUM_(i = 1)^n i`},
+		{`a &= b \\ c &= d`, "a = b\nc = d"},
+		{`\text{if } x > 0`, `if  x > 0`},
+		{`\left( \frac{1}{2} \right)`, `(1/2)`},
+	} {
+		if got := Text(tc.in); got != tc.want {
+			t.Errorf("Text(%q): got %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestMathML(t *testing.T) {
+	for _, tc := range []struct {
+		in      string
+		display bool
+		want    string
+	}{
+		{`x^2`, false, `<math xmlns="http://www.w3.org/1998/Math/MathML"><msup><mi>x</mi><mn>2</mn></msup></math>`},
+		{`\frac{a+1}{b}`, true, `<math xmlns="http://www.w3.org/1998/Math/MathML" display="block"><mfrac><mrow><mi>a</mi><mo>+</mo><mn>1</mn></mrow><mi>b</mi></mfrac></math>`},
+		{`\sqrt[n]{x} < \alpha`, false, `<math xmlns="http://www.w3.org/1998/Math/MathML"><mrow><mroot><mi>x</mi><mi>n</mi></mroot><mo>&lt;</mo><mi>α</mi></mrow></math>`},
+		{`\sin x_{i,j}`, false, `<math xmlns="http://www.w3.org/1998/Math/MathML"><mrow><mi mathvariant="normal">sin</mi><msub><mi>x</mi><mrow><mi>i</mi><mo>,</mo><mi>j</mi></mrow></msub></mrow></math>`},
+		{`a \\ b`, true, `<math xmlns="http://www.w3.org/1998/Math/MathML" display="block"><mtable><mtr><mtd><mi>a</mi></mtd></mtr><mtr><mtd><mi>b</mi></mtd></mtr></mtable></math>`},
+	} {
+		if got := MathML(tc.in, tc.display); got != tc.want {
+			t.Errorf("MathML(%q):\ngot  %s\nwant %s", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package tex
+
+import (
+	"strings"
+)
+
+// Text returns the TeX math in src as plain (ASCII) text, i.e. "\frac{a+1}{b}" becomes "(a + 1)/b"
+// and "x_i^2" "x_i^2". Lines of a formula, separated with \\, are returned as lines.
+func Text(src string) string {
+	rows := lines(parse(src))
+	l := make([]string, len(rows))
+	for i := range rows {
+		b := &strings.Builder{}
+		plain(b, rows[i])
+		l[i] = b.String()
+	}
+	return trim(strings.Join(l, "\n"))
+}
+
+func plain(b *strings.Builder, n *node) {
+	switch n.kind {
+	case row:
+		for i, k := range n.kids {
+			// juxtaposed names need a space to stay apart: "\sin x", "a \in S".
+			if i > 0 && needSpace(n.kids[i-1], k) {
+				b.WriteByte(' ')
+			}
+			// a leading operator is unary: "-x".
+			if k.kind == operator && binary(k) && i > 0 && !opening(n.kids[i-1]) {
+				b.WriteString(" " + k.plain() + " ")
+				continue
+			}
+			plain(b, k)
+		}
+	case ident, number, text:
+		b.WriteString(n.plain())
+	case function:
+		b.WriteString(n.plain())
+	case operator:
+		b.WriteString(n.plain())
+		if n.text == "," {
+			b.WriteByte(' ')
+		}
+	case space:
+		b.WriteString(n.plain())
+	case frac:
+		operand(b, n.kids[0])
+		b.WriteString("/")
+		operand(b, n.kids[1])
+	case sqrt:
+		b.WriteString("sqrt(")
+		plain(b, n.kids[0])
+		b.WriteString(")")
+	case root:
+		b.WriteString("root(")
+		plain(b, n.kids[1])
+		b.WriteString(", ")
+		plain(b, n.kids[0])
+		b.WriteString(")")
+	case sup:
+		operand(b, n.kids[0])
+		b.WriteString("^")
+		operand(b, n.kids[1])
+	case sub:
+		operand(b, n.kids[0])
+		b.WriteString("_")
+		operand(b, n.kids[1])
+	case subsup:
+		operand(b, n.kids[0])
+		b.WriteString("_")
+		operand(b, n.kids[1])
+		b.WriteString("^")
+		operand(b, n.kids[2])
+	}
+}
+
+// operand writes n, in parentheses if it consists of more than one element.
+func operand(b *strings.Builder, n *node) {
+	if n.kind == row && len(n.kids) == 1 {
+		n = n.kids[0]
+	}
+	switch n.kind {
+	case ident, number, function, operator, sqrt, root:
+		plain(b, n)
+		return
+	}
+	b.WriteString("(")
+	plain(b, n)
+	b.WriteString(")")
+}
+
+// binary returns true if the operator n is written with spaces around it.
+func binary(n *node) bool {
+	switch n.text {
+	case "(", ")", "[", "]", "{", "}", "|", ",", ".", ";", ":", "!", "′", "/", "⌊", "⌋", "⌈", "⌉", "⟨", "⟩", "∣", "‖":
+		return false
+	}
+	switch n.plain() {
+	case "SUM", "PROD", "INT", "d", "nabla", "...", "NOT ", "for all ", "exists ":
+		return false
+	}
+	return true
+}
+
+// opening returns true if n is an operator after which an operator is unary, as in "(-x" or "= -x".
+func opening(n *node) bool {
+	if n.kind != operator {
+		return false
+	}
+	switch n.text {
+	case ")", "]", "}", "|", "′", "⌋", "⌉", "⟩", "‖":
+		return false
+	}
+	return true
+}
+
+func needSpace(prev, next *node) bool {
+	word := func(n *node) bool {
+		switch n.kind {
+		case function, text:
+			return true
+		case ident:
+			return len(n.plain()) > 1
+		}
+		return false
+	}
+	if prev.kind == operator || next.kind == operator || prev.kind == space || next.kind == space {
+		return false
+	}
+	// "x^2 y" and not "x^2y" which reads as x^(2y).
+	if prev.kind == sup || prev.kind == sub || prev.kind == subsup {
+		return true
+	}
+	return word(prev) || word(next)
+}
@@ -0,0 +1,180 @@
+package text
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// line is a single line of output.
+type line struct {
+	s     string
+	key   interface{} // set for lines that start a section, used to find page numbers for the TOC.
+	group int         // lines with the same non zero group are kept together on a page, if possible.
+	toc   int         // index+1 in the TOC entries, if this line is a TOC line.
+}
+
+// emit adds lines to the output.
+func (r *Renderer) emit(s ...string) {
+	for _, l := range s {
+		r.lines = append(r.lines, line{s: strings.TrimRight(l, " ")})
+	}
+}
+
+// blank adds a blank line, unless the last line is already blank.
+func (r *Renderer) blank() {
+	if len(r.lines) == 0 || r.lines[len(r.lines)-1].s == "" {
+		return
+	}
+	r.emit("")
+}
+
+// group adds lines that should be kept together on a page.
+func (r *Renderer) group(s []string) {
+	r.groups++
+	for _, l := range s {
+		r.lines = append(r.lines, line{s: strings.TrimRight(l, " "), group: r.groups})
+	}
+}
+
+// paginate splits lines into pages of at most n lines. Groups are not split unless they are larger than
+// a page and lines starting a section are not put at the bottom of a page.
+func paginate(lines []line, n int) [][]line {
+	pages := [][]line{}
+	page := []line{}
+	newPage := func() {
+		// trim trailing blank lines
+		for len(page) > 0 && page[len(page)-1].s == "" {
+			page = page[:len(page)-1]
+		}
+		pages = append(pages, page)
+		page = []line{}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		l := lines[i]
+		if len(page) == 0 && l.s == "" && len(pages) > 0 {
+			continue // no blank lines at the top of a page
+		}
+		if len(page) >= n {
+			newPage()
+			if l.s == "" {
+				continue
+			}
+		}
+		if l.group > 0 && (i == 0 || lines[i-1].group != l.group) {
+			size := 0
+			for j := i; j < len(lines) && lines[j].group == l.group; j++ {
+				size++
+			}
+			if size <= n && len(page)+size > n {
+				newPage()
+			}
+		}
+		// don't leave section titles dangling at the bottom of a page.
+		if l.key != nil && len(page)+3 > n {
+			newPage()
+		}
+		page = append(page, l)
+	}
+	if len(page) > 0 {
+		newPage()
+	}
+	return pages
+}
+
+// writePages writes the pages with header and footer to w.
+func (r *Renderer) writePages(w io.Writer, pages [][]line) {
+	for i, page := range pages {
+		if i > 0 {
+			io.WriteString(w, r.header()+"\n\n\n")
+		}
+		for _, l := range page {
+			io.WriteString(w, l.s+"\n")
+		}
+		pad := r.opts.PageLength - len(page) - 1
+		if i > 0 {
+			pad -= 3
+		}
+		io.WriteString(w, strings.Repeat("\n", pad))
+		io.WriteString(w, r.footer(i+1)+"\n")
+		if i < len(pages)-1 {
+			io.WriteString(w, "\f\n")
+		}
+	}
+}
+
+// bodyLength returns the number of body lines on a page, this is the page length minus the header and footer.
+func (r *Renderer) bodyLength() int {
+	return r.opts.PageLength - 3 - 3
+}
+
+// fillTOC fills in the page numbers in the table of contents.
+func (r *Renderer) fillTOC(pages [][]line) {
+	page := map[interface{}]int{}
+	for i := range pages {
+		for _, l := range pages[i] {
+			if l.key != nil {
+				page[l.key] = i + 1
+			}
+		}
+	}
+	for i := range r.toc {
+		r.toc[i].page = page[r.toc[i].key]
+	}
+	for i := range pages {
+		for j, l := range pages[i] {
+			if l.toc == 0 {
+				continue
+			}
+			e := r.toc[l.toc-1]
+			pages[i][j].s = tocLine(e, e.page, r.opts.Width)
+		}
+	}
+}
+
+// TOCEntry is an entry in the table of contents.
+type TOCEntry struct {
+	Level  int    // Level of the entry, starting at 0.
+	Number string // Section number, i.e. "2.1.", may be empty.
+	Title  string
+	Page   int // Page number, starting at 1.
+}
+
+// TOC returns the table of contents with the page numbers, these are only known after RenderFooter
+// has paginated the document.
+func (r *Renderer) TOC() []TOCEntry {
+	toc := make([]TOCEntry, len(r.toc))
+	for i, e := range r.toc {
+		toc[i] = TOCEntry{Level: e.level, Number: e.number, Title: e.title, Page: e.page}
+	}
+	return toc
+}
+
+// tocLine formats a TOC entry with dotted leaders.
+func tocLine(e tocEntry, page, width int) string {
+	left := strings.Repeat(" ", 3*e.level) + e.number
+	if e.number != "" {
+		left += "  "
+	}
+	left += e.title
+	right := fmt.Sprintf("%d", page)
+
+	room := width - length(right) - 1
+	if length(left)+2 > room {
+		runes := []rune(left)
+		left = string(runes[:room-5]) + "..."
+	}
+	line := left + " "
+	for length(line) < room-1 {
+		if length(line)%2 == 0 {
+			line += "."
+		} else {
+			line += " "
+		}
+	}
+	for length(line) < width-length(right) {
+		line += " "
+	}
+	return line + right
+}
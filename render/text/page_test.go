@@ -0,0 +1,78 @@
+package text
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// pageText returns the pages as strings, the lines separated by a |.
+func pageText(pages [][]line) []string {
+	s := []string{}
+	for _, page := range pages {
+		l := []string{}
+		for _, x := range page {
+			l = append(l, x.s)
+		}
+		s = append(s, strings.Join(l, "|"))
+	}
+	return s
+}
+
+func TestPaginate(t *testing.T) {
+	section := 1 // a key, for a line starting a section.
+	tests := []struct {
+		name  string
+		lines []line
+		want  []string
+	}{
+		{
+			"split",
+			[]line{{s: "a"}, {s: "b"}, {s: "c"}, {s: "d"}, {s: "e"}},
+			[]string{"a|b|c|d", "e"},
+		},
+		{
+			"no blank lines at the top or bottom",
+			[]line{{s: "a"}, {s: "b"}, {s: "c"}, {s: ""}, {s: ""}, {s: "d"}},
+			[]string{"a|b|c", "d"},
+		},
+		{
+			"group kept together",
+			[]line{{s: "a"}, {s: "b"}, {s: "c", group: 1}, {s: "d", group: 1}, {s: "e", group: 1}},
+			[]string{"a|b", "c|d|e"},
+		},
+		{
+			"group larger than a page",
+			[]line{{s: "a"}, {s: "b", group: 1}, {s: "c", group: 1}, {s: "d", group: 1}, {s: "e", group: 1}, {s: "f", group: 1}},
+			[]string{"a|b|c|d", "e|f"},
+		},
+		{
+			"section title not at the bottom",
+			[]line{{s: "a"}, {s: "b"}, {s: "1.  Title", key: section}, {s: "c"}},
+			[]string{"a|b", "1.  Title|c"},
+		},
+	}
+	for _, tc := range tests {
+		if got := pageText(paginate(tc.lines, 4)); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestTOCLine(t *testing.T) {
+	tests := []struct {
+		entry tocEntry
+		page  int
+		want  string
+	}{
+		{tocEntry{number: "1.", title: "Introduction"}, 3, "1.  Introduction  . . . . .  3"},
+		{tocEntry{level: 1, number: "1.1.", title: "Terms"}, 12, "   1.1.  Terms  . . . . .   12"},
+		{tocEntry{title: "Author's Address"}, 7, "Author's Address  . . . . .  7"},
+		{tocEntry{number: "2.", title: "A Title That Is Much Too Long"}, 9, "2.  A Title That Is Muc...   9"},
+	}
+	for _, tc := range tests {
+		if got := tocLine(tc.entry, tc.page, 30); got != tc.want {
+			t.Errorf("expected %q, got %q", tc.want, got)
+		}
+	}
+}
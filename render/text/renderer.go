@@ -0,0 +1,784 @@
+// The package text outputs plain text, paginated RFC 7994 style, from mmark markdown.
+package text
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/v2/lang"
+	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/render/diagram"
+	"github.com/mmarkdown/mmark/v2/render/tex"
+	xml "github.com/mmarkdown/mmark/v2/render/xml"
+)
+
+// Flags control optional behavior of the text renderer.
+type Flags int
+
+// Text renderer configuration options.
+const (
+	FlagsNone    Flags = 0
+	TextFragment Flags = 1 << iota // Don't generate a complete document: no pagination, title page and TOC
+
+	CommonFlags Flags = FlagsNone
+)
+
+// RendererOptions is a collection of supplementary parameters tweaking
+// the behavior of various parts of the text renderer.
+type RendererOptions struct {
+	Flags Flags // Flags allow customizing this renderer's behavior
+
+	Width      int // Width of the text, defaults to 72.
+	PageLength int // Number of lines on a page, including header and footer, defaults to 58.
+
+	Language lang.Lang // Output language for the document.
+
+	// if set, called at the start of RenderNode(). Allows replacing rendering of some nodes
+	RenderNodeHook html.RenderNodeFunc
+
+	// Diagrams converts the diagrams in fenced code blocks, their text is output instead of the source.
+	Diagrams *diagram.Diagrams
+}
+
+// Renderer implements Renderer interface for plain text output.
+type Renderer struct {
+	opts RendererOptions
+
+	Title *mast.Title
+
+	lines  []line
+	groups int // group counter
+
+	inline  *strings.Builder // inline text of the current block
+	indent  int              // current indentation
+	bullet  string           // bullet to use for the next line
+	bullets []int            // bullet widths of open list items
+	counter []int            // item counters of open lists
+
+	numbers map[ast.Node]string // section numbers
+	xrefs   map[string]string   // anchor to text to use for cross references
+	toc     []tocEntry
+	tocDone bool
+	done    map[ast.Node]bool // nodes already rendered out of order
+}
+
+// tocEntry is an entry in the table of contents.
+type tocEntry struct {
+	level  int
+	number string
+	title  string
+	key    interface{}
+	page   int
+}
+
+const keyAuthors = "authors"
+
+// NewRenderer creates and configures an Renderer object, which satisfies the Renderer interface.
+func NewRenderer(opts RendererOptions) *Renderer {
+	if opts.Width == 0 {
+		opts.Width = 72
+	}
+	if opts.PageLength == 0 {
+		opts.PageLength = 58
+	}
+	return &Renderer{
+		opts:    opts,
+		inline:  &strings.Builder{},
+		indent:  3,
+		numbers: map[ast.Node]string{},
+		xrefs:   map[string]string{},
+		done:    map[ast.Node]bool{},
+	}
+}
+
+func (r *Renderer) fragment() bool { return r.opts.Flags&TextFragment != 0 }
+
+// prefix returns the indentation for a line, using the pending bullet if there is one.
+func (r *Renderer) prefix(indent int) string {
+	if r.bullet == "" {
+		return strings.Repeat(" ", indent)
+	}
+	b := r.bullet
+	r.bullet = ""
+	if length(b) > indent {
+		return b
+	}
+	return strings.Repeat(" ", indent-length(b)) + b
+}
+
+// flush wraps the inline text gathered so far and outputs it.
+func (r *Renderer) flush() {
+	text := r.inline.String()
+	r.inline.Reset()
+	if strings.TrimSpace(text) == "" {
+		if r.bullet != "" {
+			r.emit(r.prefix(r.indent))
+		}
+		return
+	}
+	for _, l := range wrap(text, r.opts.Width-r.indent) {
+		r.emit(r.prefix(r.indent) + l)
+	}
+}
+
+// inlineText returns the text of node's children.
+func (r *Renderer) inlineText(node ast.Node) string {
+	save := r.inline
+	r.inline = &strings.Builder{}
+	ast.WalkFunc(node, func(n ast.Node, entering bool) ast.WalkStatus {
+		if n == node {
+			return ast.GoToNext
+		}
+		return r.RenderNode(nil, n, entering)
+	})
+	s := r.inline.String()
+	r.inline = save
+	return strings.TrimSpace(s)
+}
+
+// number walks the document and numbers the sections, figures and tables, it also creates the TOC entries.
+func (r *Renderer) number(doc ast.Node) {
+	matter := ast.DocumentMatterNone
+	counters := [6]int{}
+	top := 0
+	figures, tables := 0, 0
+	section := "" // cross reference text of the current section
+
+	depth := 3
+	if r.Title != nil && r.Title.TocDepth > 0 {
+		depth = r.Title.TocDepth
+	}
+
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch n := node.(type) {
+		case *ast.DocumentMatter:
+			matter = n.Matter
+			counters = [6]int{}
+			if matter == ast.DocumentMatterBack {
+				r.numberBibliography(n, top+1)
+			}
+		case *ast.Heading:
+			if n.IsSpecial || matter == ast.DocumentMatterFront {
+				return ast.SkipChildren
+			}
+			level := n.Level
+			if level > 6 {
+				level = 6
+			}
+			counters[level-1]++
+			for i := level; i < 6; i++ {
+				counters[i] = 0
+			}
+			nums := []string{}
+			for i := 0; i < level; i++ {
+				nums = append(nums, fmt.Sprintf("%d", counters[i]))
+			}
+			xref := "Section "
+			if matter == ast.DocumentMatterBack {
+				nums[0] = string(rune('A' + (counters[0]-1)%26))
+				xref = "Appendix "
+			} else {
+				top = counters[0]
+			}
+			num := strings.Join(nums, ".") + "."
+			section = xref + strings.TrimSuffix(num, ".")
+			if n.HeadingID != "" {
+				r.xrefs[n.HeadingID] = section
+			}
+			if matter == ast.DocumentMatterBack && level == 1 {
+				num = "Appendix " + num
+			}
+			r.numbers[n] = num
+			if level <= depth {
+				r.toc = append(r.toc, tocEntry{level: level - 1, number: num, title: r.inlineText(n), key: n})
+			}
+			return ast.SkipChildren
+		case *ast.CaptionFigure:
+			label := ""
+			switch ast.GetFirstChild(n).(type) {
+			case *ast.Table:
+				tables++
+				label = fmt.Sprintf("Table %d", tables)
+			case *ast.BlockQuote:
+			default:
+				figures++
+				label = fmt.Sprintf("Figure %d", figures)
+			}
+			if label != "" {
+				r.numbers[n] = label
+				if n.HeadingID != "" {
+					r.xrefs[n.HeadingID] = label
+				}
+			}
+		default:
+			// other elements with an anchor are referenced by the section they are in.
+			if id := nodeID(n); id != "" && section != "" {
+				r.xrefs[id] = section
+			}
+		}
+		return ast.GoToNext
+	})
+
+	if r.Title != nil && len(r.Title.Author) > 0 {
+		title := "Author's Address"
+		if len(r.Title.Author) > 1 {
+			title = "Authors' Addresses"
+		}
+		r.toc = append(r.toc, tocEntry{title: title, key: keyAuthors})
+	}
+}
+
+// nodeID returns the ID from the attribute of node, if any.
+func nodeID(node ast.Node) string {
+	var a *ast.Attribute
+	if c := node.AsContainer(); c != nil {
+		a = c.Attribute
+	}
+	if l := node.AsLeaf(); l != nil {
+		a = l.Attribute
+	}
+	if a == nil {
+		return ""
+	}
+	return string(a.ID)
+}
+
+// numberBibliography numbers the bibliography sections found in matter, with top as the section number.
+func (r *Renderer) numberBibliography(matter ast.Node, top int) {
+	for _, child := range matter.GetChildren() {
+		switch b := child.(type) {
+		case *mast.BibliographyWrapper:
+			num := fmt.Sprintf("%d.", top)
+			r.numbers[b] = num
+			r.toc = append(r.toc, tocEntry{number: num, title: "References", key: b})
+			for i, c := range b.GetChildren() {
+				num := fmt.Sprintf("%d.%d.", top, i+1)
+				r.numbers[c] = num
+				r.toc = append(r.toc, tocEntry{level: 1, number: num, title: bibliographyTitle(c), key: c})
+			}
+			return
+		case *mast.Bibliography:
+			num := fmt.Sprintf("%d.", top)
+			r.numbers[b] = num
+			r.toc = append(r.toc, tocEntry{number: num, title: bibliographyTitle(b), key: b})
+			return
+		}
+	}
+}
+
+func bibliographyTitle(node ast.Node) string {
+	if b, ok := node.(*mast.Bibliography); ok && b.Type == ast.CitationTypeNormative {
+		return "Normative References"
+	}
+	return "Informative References"
+}
+
+// sectionTitle outputs a section title with number.
+func (r *Renderer) sectionTitle(key ast.Node, title string) {
+	num := r.numbers[key]
+	r.blank()
+	hang := 0
+	if num != "" {
+		hang = length(num) + 2
+	}
+	for i, l := range wrap(title, r.opts.Width-hang) {
+		if i == 0 {
+			if num != "" {
+				l = num + "  " + l
+			}
+			r.lines = append(r.lines, line{s: l, key: key})
+			continue
+		}
+		r.emit(strings.Repeat(" ", hang) + l)
+	}
+	r.emit("")
+}
+
+func (r *Renderer) tableOfContents() {
+	if r.tocDone || r.fragment() || r.Title == nil || !r.Title.TocInclude || len(r.toc) == 0 {
+		return
+	}
+	r.tocDone = true
+	r.blank()
+	r.emit("Table of Contents", "")
+	for i := range r.toc {
+		r.lines = append(r.lines, line{s: r.toc[i].title, toc: i + 1})
+	}
+	r.emit("")
+}
+
+func (r *Renderer) matter(node *ast.DocumentMatter, entering bool) {
+	if !entering {
+		return
+	}
+	switch node.Matter {
+	case ast.DocumentMatterMain:
+		r.tableOfContents()
+	case ast.DocumentMatterBack:
+		// References come before the appendices.
+		for _, child := range node.GetChildren() {
+			switch child.(type) {
+			case *mast.BibliographyWrapper, *mast.Bibliography:
+				ast.WalkFunc(child, func(n ast.Node, entering bool) ast.WalkStatus {
+					return r.RenderNode(nil, n, entering)
+				})
+				r.done[child] = true
+			}
+		}
+	}
+}
+
+func (r *Renderer) heading(node *ast.Heading, entering bool) {
+	if entering {
+		if !node.IsSpecial && r.numbers[node] != "" {
+			r.tableOfContents()
+		}
+		r.inline.Reset()
+		return
+	}
+	title := strings.TrimSpace(r.inline.String())
+	r.inline.Reset()
+	r.sectionTitle(node, title)
+}
+
+func (r *Renderer) paragraph(para *ast.Paragraph, entering bool) {
+	if entering {
+		r.inline.Reset()
+		return
+	}
+	r.flush()
+	if item, ok := para.Parent.(*ast.ListItem); ok {
+		if list, ok := item.Parent.(*ast.List); ok && list.Tight {
+			return
+		}
+		if item.ListFlags&ast.ListTypeTerm != 0 {
+			return
+		}
+	}
+	if _, ok := para.Parent.(*ast.CaptionFigure); ok {
+		return
+	}
+	r.emit("")
+}
+
+func (r *Renderer) list(list *ast.List, entering bool) {
+	if entering {
+		r.counter = append(r.counter, list.Start)
+		return
+	}
+	r.counter = r.counter[:len(r.counter)-1]
+	r.blank()
+}
+
+func (r *Renderer) listItem(item *ast.ListItem, entering bool) {
+	bullet := ""
+	switch {
+	case item.ListFlags&ast.ListTypeTerm != 0:
+		return
+	case item.ListFlags&ast.ListTypeDefinition != 0:
+		bullet = ""
+	case item.RefLink != nil: // footnote
+		if entering {
+			r.counter[len(r.counter)-1]++
+		}
+		bullet = fmt.Sprintf("[%d]", r.counter[len(r.counter)-1])
+	case item.ListFlags&ast.ListTypeOrdered != 0:
+		if !entering {
+			break
+		}
+		n := r.counter[len(r.counter)-1]
+		if n == 0 {
+			n = 1
+		}
+		r.counter[len(r.counter)-1] = n + 1
+		bullet = fmt.Sprintf("%d.", n)
+		if item.Delimiter == mast.CalloutDelimiter {
+			bullet = fmt.Sprintf("<%d>", n)
+		}
+	default:
+		bullet = "*"
+		if len(r.counter)%2 == 0 {
+			bullet = "-"
+		}
+	}
+	if !entering {
+		r.flush()
+		r.indent -= r.bullets[len(r.bullets)-1]
+		r.bullets = r.bullets[:len(r.bullets)-1]
+		return
+	}
+	width := 3
+	if bullet != "" {
+		bullet += "  "
+		if length(bullet) > width {
+			width = length(bullet)
+		}
+	}
+	r.bullet = bullet
+	r.indent += width
+	r.bullets = append(r.bullets, width)
+}
+
+// artwork outputs the lines of literal text, indented and kept together.
+func (r *Renderer) artwork(literal []byte) {
+	if r.bullet != "" {
+		r.emit(r.prefix(r.indent))
+	}
+	lines := strings.Split(strings.TrimRight(string(literal), "\n"), "\n")
+	for i := range lines {
+		lines[i] = strings.Repeat(" ", r.indent+3) + expandTabs(lines[i])
+	}
+	r.group(lines)
+	r.emit("")
+}
+
+func (r *Renderer) table(tab *ast.Table) {
+	lines := table(r.tableCells(tab), r.opts.Width-r.indent-3)
+	for i := range lines {
+		lines[i] = strings.Repeat(" ", r.indent+3) + lines[i]
+	}
+	r.group(lines)
+	r.emit("")
+}
+
+func (r *Renderer) caption(caption *ast.Caption, entering bool) {
+	if entering {
+		r.flush()
+		return
+	}
+	text := strings.TrimSpace(r.inline.String())
+	r.inline.Reset()
+	label := r.numbers[caption.Parent]
+	if label == "" { // quote attribution
+		for _, l := range wrap("-- "+text, r.opts.Width-r.indent-3) {
+			r.emit(strings.Repeat(" ", r.indent+3) + l)
+		}
+		r.emit("")
+		return
+	}
+	if text != "" {
+		label += ": " + text
+	}
+	r.blank()
+	for _, l := range wrap(label, r.opts.Width-r.indent-6) {
+		r.emit(center(l, r.opts.Width))
+	}
+	r.emit("")
+}
+
+func (r *Renderer) image(image *ast.Image) {
+	dest := string(image.Destination)
+	if path.Ext(dest) == ".ascii-art" {
+		r.flush()
+		img, err := ioutil.ReadFile(dest)
+		if err != nil {
+			img = []byte(err.Error())
+		}
+		r.artwork(img)
+		return
+	}
+	fmt.Fprintf(r.inline, "(Artwork only available as %s: see %s)", strings.TrimPrefix(path.Ext(dest), "."), dest)
+}
+
+func (r *Renderer) citation(node *ast.Citation) {
+	cites := []string{}
+	for i, c := range node.Destination {
+		if node.Type[i] == ast.CitationTypeSuppressed {
+			continue
+		}
+		if a := xml.AuthorFromTitle(c, r.Title); a != nil {
+			cites = append(cites, a.Fullname)
+			continue
+		}
+		if a := xml.ContactFromTitle(c, r.Title); a != nil {
+			cites = append(cites, a.Fullname)
+			continue
+		}
+		cite := "[" + r.displayAnchor(string(c)) + "]"
+		if len(node.Suffix) > i {
+			cite = xml.ParseSuffix(node.Suffix[i], r.opts.Language).Cite(cite, r.opts.Language)
+		}
+		cites = append(cites, cite)
+	}
+	r.inline.WriteString(strings.Join(cites, ", "))
+}
+
+// displayAnchor returns the anchor as set in the title block's displayReference, or the anchor
+// without the draft version.
+func (r *Renderer) displayAnchor(anchor string) string {
+	if r.Title != nil {
+		if to, ok := r.Title.DisplayReference[anchor]; ok {
+			return to
+		}
+	}
+	if strings.HasPrefix(anchor, "I-D.") {
+		if hash := strings.Index(anchor, "#"); hash > 0 {
+			anchor = anchor[:hash]
+		}
+	}
+	if r.Title != nil {
+		if to, ok := r.Title.DisplayReference[anchor]; ok {
+			return to
+		}
+	}
+	return anchor
+}
+
+func (r *Renderer) link(link *ast.Link, entering bool) {
+	if link.Footnote != nil {
+		if entering {
+			fmt.Fprintf(r.inline, "[%d]", link.NoteID)
+		}
+		return
+	}
+	if entering {
+		r.inline.WriteString(string(nbsp)) // mark the start, see below
+		return
+	}
+	// If the link text is equal to the destination, only output the destination.
+	s := r.inline.String()
+	start := strings.LastIndex(s, string(nbsp))
+	text := s[start+len(string(nbsp)):]
+	r.inline.Reset()
+	r.inline.WriteString(s[:start])
+	if text == string(link.Destination) || text == "" {
+		r.inline.WriteString("<" + string(link.Destination) + ">")
+		return
+	}
+	r.inline.WriteString(text + " (" + string(link.Destination) + ")")
+}
+
+func (r *Renderer) bibliography(node ast.Node, entering bool) {
+	if !entering {
+		return
+	}
+	title := "References"
+	if b, ok := node.(*mast.Bibliography); ok {
+		title = bibliographyTitle(b)
+	}
+	r.sectionTitle(node, title)
+}
+
+func (r *Renderer) bibliographyItem(node *mast.BibliographyItem) {
+	// hanging indent is derived from the longest anchor in this references section.
+	hang := 0
+	for _, c := range node.Parent.GetChildren() {
+		if b, ok := c.(*mast.BibliographyItem); ok {
+			if l := length(r.displayAnchor(string(b.Anchor))) + 4; l > hang {
+				hang = l
+			}
+		}
+	}
+	if hang > 14 {
+		hang = 14
+	}
+
+	anchor := "[" + r.displayAnchor(string(node.Anchor)) + "]"
+	text := anchorText(string(node.Anchor))
+	if node.Reference != nil {
+		text = referenceText(node.Reference)
+	}
+	lines := []string{}
+	indent := strings.Repeat(" ", 3+hang)
+	wrapped := wrap(text, r.opts.Width-3-hang)
+	if length(anchor)+2 > hang {
+		lines = append(lines, "   "+anchor)
+	} else {
+		lines = append(lines, "   "+anchor+strings.Repeat(" ", hang-length(anchor))+wrapped[0])
+		wrapped = wrapped[1:]
+	}
+	for _, l := range wrapped {
+		lines = append(lines, indent+l)
+	}
+	lines = append(lines, "")
+	r.group(lines)
+}
+
+// RenderNode renders a markdown node to plain text.
+func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.WalkStatus {
+	if r.opts.RenderNodeHook != nil {
+		status, didHandle := r.opts.RenderNodeHook(w, node, entering)
+		if didHandle {
+			return status
+		}
+	}
+
+	if r.done[node] {
+		return ast.SkipChildren
+	}
+
+	switch node := node.(type) {
+	case *ast.Document:
+		// do nothing
+	case *mast.Title:
+		r.Title = node
+		if !r.fragment() {
+			r.titleBlock(node)
+		}
+	case *mast.Authors:
+		// ignore
+	case *mast.BibliographyWrapper, *mast.Bibliography:
+		r.bibliography(node, entering)
+	case *mast.BibliographyItem:
+		r.bibliographyItem(node)
+	case *mast.DocumentIndex:
+		return ast.SkipChildren
+	case *mast.IndexLetter, *mast.IndexItem, *mast.IndexSubItem, *mast.IndexLink:
+	case *mast.ReferenceBlock:
+		// skip, added and done by AddBibliography
+	case *ast.Footnotes:
+		if entering {
+			r.blank()
+			r.emit("Footnotes", "")
+		}
+	case *ast.Text:
+		r.inline.Write(node.Literal)
+	case *ast.Softbreak:
+		r.inline.WriteString(" ")
+	case *ast.Hardbreak:
+		r.inline.WriteRune(hardBreak)
+	case *ast.NonBlockingSpace:
+		r.inline.WriteRune(nbsp)
+	case *ast.Callout:
+		r.inline.WriteString("<" + string(node.ID) + ">")
+	case *mast.Unicode:
+		r.inline.WriteString(node.String())
+	case *mast.Abbreviation:
+		r.inline.WriteString(node.String())
+	case *mast.Cref:
+		r.inline.WriteString(node.String())
+	case *ast.Emph, *ast.Strong:
+		// see below
+	case *ast.Del:
+	case *ast.Citation:
+		if entering {
+			r.citation(node)
+		}
+	case *ast.DocumentMatter:
+		r.matter(node, entering)
+	case *ast.Heading:
+		r.heading(node, entering)
+	case *ast.HorizontalRule:
+		r.blank()
+	case *ast.Paragraph:
+		r.paragraph(node, entering)
+	case *ast.HTMLSpan:
+		if xml.IsBr(node.Literal) {
+			r.inline.WriteRune(hardBreak)
+			break
+		}
+		if _, ok := xml.IsComment(node.Literal); ok {
+			break
+		}
+		r.inline.Write(node.Literal)
+	case *ast.HTMLBlock:
+		// skip
+	case *ast.List:
+		r.list(node, entering)
+	case *ast.ListItem:
+		r.listItem(node, entering)
+	case *ast.CodeBlock:
+		if text := r.opts.Diagrams.Text(node); text != nil {
+			r.artwork(text)
+			break
+		}
+		r.artwork(node.Literal)
+	case *ast.Caption:
+		r.caption(node, entering)
+	case *ast.CaptionFigure:
+	case *ast.Table:
+		if entering {
+			r.table(node)
+		}
+		return ast.SkipChildren
+	case *ast.TableCell, *ast.TableHeader, *ast.TableBody, *ast.TableFooter, *ast.TableRow:
+		// done in table
+	case *ast.BlockQuote, *ast.Aside:
+		if entering {
+			r.indent += 3
+		} else {
+			r.indent -= 3
+		}
+	case *mast.Div:
+		// no divs in text output, only the contents are rendered.
+	case *ast.CrossReference:
+		if entering {
+			if x, ok := r.xrefs[string(node.Destination)]; ok {
+				r.inline.WriteString(x)
+				break
+			}
+			r.inline.WriteString("[" + string(node.Destination) + "]")
+		}
+	case *ast.Index:
+		// no index in text output
+	case *ast.Link:
+		r.link(node, entering)
+	case *ast.Math:
+		r.inline.WriteString(tex.Text(string(node.Literal)))
+	case *ast.Image:
+		if entering {
+			r.image(node)
+		}
+		return ast.SkipChildren
+	case *ast.Code:
+		r.inline.Write(node.Literal)
+	case *ast.MathBlock:
+		if entering {
+			r.artwork([]byte(tex.Text(string(node.Literal)) + "\n"))
+		}
+	case *ast.Subscript:
+		r.inline.WriteString("_" + string(node.Literal))
+	case *ast.Superscript:
+		r.inline.WriteString("^" + string(node.Literal))
+	default:
+		panic(fmt.Sprintf("Unknown node %T", node))
+	}
+
+	// emphasis markers surround the children.
+	switch node := node.(type) {
+	case *ast.Emph:
+		r.inline.WriteString("_")
+	case *ast.Strong:
+		if t, ok := ast.GetFirstChild(node).(*ast.Text); ok && xml.Is2119(t.Literal) {
+			break
+		}
+		r.inline.WriteString("*")
+	}
+	return ast.GoToNext
+}
+
+// RenderHeader numbers the sections, tables and figures so cross references can be resolved.
+func (r *Renderer) RenderHeader(w io.Writer, doc ast.Node) {
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if t, ok := node.(*mast.Title); ok {
+			r.Title = t
+			return ast.Terminate
+		}
+		return ast.GoToNext
+	})
+	r.number(doc)
+}
+
+// RenderFooter writes the entire document, paginated when we have a title block.
+func (r *Renderer) RenderFooter(w io.Writer, _ ast.Node) {
+	r.flush()
+	if r.fragment() || r.Title == nil {
+		for _, l := range r.lines {
+			io.WriteString(w, l.s+"\n")
+		}
+		return
+	}
+
+	r.authorsAddresses()
+	pages := paginate(r.lines, r.bodyLength())
+	r.fillTOC(pages)
+	r.writePages(w, pages)
+}
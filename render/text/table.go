@@ -0,0 +1,166 @@
+package text
+
+import (
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+// cell is a rendered table cell.
+type cell struct {
+	text   string
+	align  ast.CellAlignFlags
+	span   int
+	header bool
+}
+
+// tableCells returns the rendered cells of the table per row.
+func (r *Renderer) tableCells(tab *ast.Table) [][]cell {
+	rows := [][]cell{}
+	ast.WalkFunc(tab, func(node ast.Node, entering bool) ast.WalkStatus {
+		switch x := node.(type) {
+		case *ast.TableRow:
+			if entering {
+				rows = append(rows, []cell{})
+			}
+		case *ast.TableCell:
+			if !entering {
+				return ast.GoToNext
+			}
+			span := x.ColSpan
+			if span < 1 {
+				span = 1
+			}
+			c := cell{text: r.inlineText(x), align: x.Align, span: span, header: x.IsHeader}
+			rows[len(rows)-1] = append(rows[len(rows)-1], c)
+			return ast.SkipChildren
+		}
+		return ast.GoToNext
+	})
+	return rows
+}
+
+// table renders the table as ASCII art that fits in width.
+func table(rows [][]cell, width int) []string {
+	ncol := 0
+	for _, row := range rows {
+		n := 0
+		for _, c := range row {
+			n += c.span
+		}
+		if n > ncol {
+			ncol = n
+		}
+	}
+	if ncol == 0 {
+		return nil
+	}
+
+	// natural widths, spanning cells are not taken into account
+	widths := make([]int, ncol)
+	for _, row := range rows {
+		col := 0
+		for _, c := range row {
+			if c.span == 1 && length(c.text) > widths[col] {
+				widths[col] = length(c.text)
+			}
+			col += c.span
+		}
+	}
+	// each column takes 3 extra characters "| " and " ", plus the final "|"
+	avail := width - 3*ncol - 1
+	total := 0
+	for _, w := range widths {
+		total += w
+	}
+	for total > avail {
+		// shrink the widest column
+		max := 0
+		for i := range widths {
+			if widths[i] > widths[max] {
+				max = i
+			}
+		}
+		if widths[max] <= 3 {
+			break
+		}
+		widths[max]--
+		total--
+	}
+	for i := range widths {
+		if widths[i] == 0 {
+			widths[i] = 1
+		}
+	}
+
+	sep := func(c byte) string {
+		s := "+"
+		for _, w := range widths {
+			s += strings.Repeat(string(c), w+2) + "+"
+		}
+		return s
+	}
+
+	lines := []string{sep('-')}
+	for i, row := range rows {
+		cells := make([][]string, len(row))
+		height := 0
+		col := 0
+		for j, c := range row {
+			w := widths[col]
+			for k := 1; k < c.span && col+k < ncol; k++ {
+				w += widths[col+k] + 3
+			}
+			cells[j] = wrap(c.text, w)
+			for k := range cells[j] {
+				cells[j][k] = align(cells[j][k], w, c.align)
+			}
+			if len(cells[j]) > height {
+				height = len(cells[j])
+			}
+			col += c.span
+		}
+		for h := 0; h < height; h++ {
+			s := "|"
+			col := 0
+			for j, c := range row {
+				w := widths[col]
+				for k := 1; k < c.span && col+k < ncol; k++ {
+					w += widths[col+k] + 3
+				}
+				text := strings.Repeat(" ", w)
+				if h < len(cells[j]) {
+					text = cells[j][h]
+				}
+				s += " " + text + " |"
+				col += c.span
+			}
+			// missing cells
+			for ; col < ncol; col++ {
+				s += strings.Repeat(" ", widths[col]+2) + "|"
+			}
+			lines = append(lines, s)
+		}
+		if len(row) > 0 && row[0].header && (i+1 == len(rows) || len(rows[i+1]) == 0 || !rows[i+1][0].header) {
+			lines = append(lines, sep('='))
+			continue
+		}
+		lines = append(lines, sep('-'))
+	}
+	return lines
+}
+
+// align aligns s in a field of width w.
+func align(s string, w int, a ast.CellAlignFlags) string {
+	pad := w - length(s)
+	if pad <= 0 {
+		return s
+	}
+	switch a {
+	case ast.TableAlignmentRight:
+		return strings.Repeat(" ", pad) + s
+	case ast.TableAlignmentCenter:
+		return strings.Repeat(" ", pad/2) + s + strings.Repeat(" ", pad-pad/2)
+	}
+	return s + strings.Repeat(" ", pad)
+}
@@ -0,0 +1,264 @@
+package text
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/mast/reference"
+)
+
+// Categories translates the seriesInfo status to the category that is shown on the first page.
+var Categories = map[string]string{
+	"full-standard": "Standards Track",
+	"standard":      "Standards Track",
+	"informational": "Informational",
+	"experimental":  "Experimental",
+	"bcp":           "Best Current Practice",
+	"historic":      "Historic",
+}
+
+func (r *Renderer) isRFC() bool {
+	return r.Title != nil && r.Title.SeriesInfo.Name == "RFC"
+}
+
+// date returns the document date as shown in the header.
+func (r *Renderer) date() string {
+	if r.Title == nil || r.Title.Date.IsZero() {
+		return ""
+	}
+	return r.Title.Date.Format("January 2006")
+}
+
+// header returns the page header.
+func (r *Renderer) header() string {
+	left := "Internet-Draft"
+	if r.isRFC() {
+		left = "RFC " + r.Title.SeriesInfo.Value
+	}
+	title := r.Title.Abbrev
+	if title == "" {
+		title = r.Title.Title
+	}
+	return spread(left, title, r.date(), r.opts.Width)
+}
+
+// footer returns the page footer for page.
+func (r *Renderer) footer(page int) string {
+	surnames := []string{}
+	for _, a := range r.Title.Author {
+		surnames = append(surnames, surname(a))
+	}
+	left := ""
+	switch len(surnames) {
+	case 0:
+	case 1:
+		left = surnames[0]
+	case 2:
+		left = surnames[0] + " & " + surnames[1]
+	default:
+		left = surnames[0] + ", et al."
+	}
+	return spread(left, Categories[r.Title.SeriesInfo.Status], fmt.Sprintf("[Page %d]", page), r.opts.Width)
+}
+
+func surname(a mast.Author) string {
+	if a.Surname != "" {
+		return a.Surname
+	}
+	if i := strings.LastIndex(a.Fullname, " "); i > 0 {
+		return a.Fullname[i+1:]
+	}
+	return a.Fullname
+}
+
+// shortName returns the name as "I. Surname", with ", Ed." for an editor (RFC 7322, Section 4.1.1).
+func shortName(a mast.Author) string {
+	name := a.Fullname
+	if a.Initials != "" {
+		name = a.Initials + " " + surname(a)
+	}
+	if a.Role == "editor" {
+		name += ", Ed."
+	}
+	return name
+}
+
+// titleBlock outputs the first page's top part: the left and right columns and the centered title.
+func (r *Renderer) titleBlock(t *mast.Title) {
+	d := t.TitleData
+	workgroup := d.Workgroup
+	if workgroup == "" {
+		workgroup = "Network Working Group"
+	}
+	left := []string{workgroup}
+	category := Categories[d.SeriesInfo.Status]
+	if r.isRFC() {
+		left = append(left, "Request for Comments: "+d.SeriesInfo.Value)
+		for _, s := range d.SeriesInfos {
+			switch s.Name {
+			case "BCP", "STD", "FYI":
+				left = append(left, s.Name+": "+s.Value)
+			}
+		}
+		if len(d.Updates) > 0 {
+			left = append(left, "Updates: "+d.Updates.String())
+		}
+		if len(d.Obsoletes) > 0 {
+			left = append(left, "Obsoletes: "+d.Obsoletes.String())
+		}
+		if category != "" {
+			left = append(left, "Category: "+category)
+		}
+	} else {
+		left = append(left, "Internet-Draft")
+		if len(d.Updates) > 0 {
+			left = append(left, "Updates: "+d.Updates.String()+" (if approved)")
+		}
+		if len(d.Obsoletes) > 0 {
+			left = append(left, "Obsoletes: "+d.Obsoletes.String()+" (if approved)")
+		}
+		if category != "" {
+			left = append(left, "Intended status: "+category)
+		}
+		if e := d.Expires(); !e.IsZero() {
+			left = append(left, "Expires: "+e.Format("2 January 2006"))
+		}
+	}
+
+	right := []string{}
+	for i, a := range d.Author {
+		right = append(right, shortName(a))
+		org := a.OrganizationAbbrev
+		if org == "" {
+			org = a.Organization
+		}
+		if org == "" {
+			continue
+		}
+		// authors from the same organization list it once.
+		if i+1 < len(d.Author) && (d.Author[i+1].Organization == a.Organization) {
+			continue
+		}
+		right = append(right, org)
+	}
+	right = append(right, r.date())
+
+	r.emit(columns(left, right, r.opts.Width)...)
+	r.emit("", "")
+	for _, l := range wrap(d.Title, r.opts.Width-10) {
+		r.emit(center(l, r.opts.Width))
+	}
+	if !r.isRFC() && d.SeriesInfo.Value != "" {
+		r.emit(center(d.SeriesInfo.Value, r.opts.Width))
+	}
+	r.emit("")
+}
+
+// authorsAddresses outputs the Authors' Addresses section.
+func (r *Renderer) authorsAddresses() {
+	if r.Title == nil || len(r.Title.Author) == 0 {
+		return
+	}
+	r.blank()
+	title := "Author's Address"
+	if len(r.Title.Author) > 1 {
+		title = "Authors' Addresses"
+	}
+	r.lines = append(r.lines, line{s: title, key: keyAuthors})
+	r.emit("")
+	for _, a := range r.Title.Author {
+		lines := []string{a.Fullname}
+		lines = append(lines, a.Organization)
+		p := a.Address.Postal
+		lines = append(lines, p.Street)
+		lines = append(lines, p.Streets...)
+		lines = append(lines, strings.TrimSpace(strings.Join([]string{p.City, p.Region, p.Code}, " ")))
+		lines = append(lines, p.Cities...)
+		lines = append(lines, p.Country)
+		lines = append(lines, p.Countries...)
+		if a.Address.Phone != "" {
+			lines = append(lines, "Phone: "+a.Address.Phone)
+		}
+		if a.Address.Email != "" {
+			lines = append(lines, "Email: "+a.Address.Email)
+		}
+		for _, e := range a.Address.Emails {
+			lines = append(lines, "Email: "+e)
+		}
+		if a.Address.URI != "" {
+			lines = append(lines, "URI:   "+a.Address.URI)
+		}
+		out := []string{}
+		for _, l := range lines {
+			if l != "" {
+				out = append(out, "   "+l)
+			}
+		}
+		out = append(out, "")
+		r.group(out)
+	}
+}
+
+// referenceText returns the text of a reference as in RFC 7322, Section 4.8.6.
+func referenceText(ref *reference.Reference) string {
+	parts := []string{}
+
+	names := []string{}
+	for i, a := range ref.Front.Authors {
+		name := ""
+		switch {
+		case a.Surname != "" && i == 0:
+			name = a.Surname + ", " + a.Initials
+		case a.Surname != "":
+			name = a.Initials + " " + a.Surname
+		case a.Fullname != "":
+			name = a.Fullname
+		case a.Organization != nil:
+			name = a.Organization.Value
+		}
+		name = strings.TrimSpace(strings.TrimSuffix(name, ","))
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	switch len(names) {
+	case 0:
+	case 1:
+		parts = append(parts, names[0])
+	case 2:
+		parts = append(parts, names[0]+" and "+names[1])
+	default:
+		parts = append(parts, strings.Join(names[:len(names)-1], ", ")+", and "+names[len(names)-1])
+	}
+	parts = append(parts, `"`+ref.Front.Title+`"`)
+	for _, s := range ref.Series {
+		parts = append(parts, s.Name+" "+s.Value)
+	}
+	parts = append(parts, ref.RefContent...)
+	if d := ref.Front.Date; d != nil {
+		date := strings.TrimSpace(d.Month + " " + d.Year)
+		if date != "" {
+			parts = append(parts, date)
+		}
+	}
+	if ref.Target != "" {
+		parts = append(parts, "<"+ref.Target+">")
+	}
+	return strings.Join(parts, ", ") + "."
+}
+
+// anchorText returns the reference text for references that we don't have the XML for.
+func anchorText(anchor string) string {
+	switch {
+	case strings.HasPrefix(anchor, "RFC"):
+		return fmt.Sprintf("RFC %s, <https://www.rfc-editor.org/info/rfc%s>.", anchor[3:], anchor[3:])
+	case strings.HasPrefix(anchor, "I-D."):
+		draft := anchor[4:]
+		if hash := strings.Index(draft, "#"); hash > 0 {
+			draft = draft[:hash] + "-" + draft[hash+1:]
+		}
+		return fmt.Sprintf("Work in Progress, Internet-Draft, draft-%s, <https://datatracker.ietf.org/doc/draft-%s/>.", draft, draft)
+	}
+	return ""
+}
@@ -0,0 +1,107 @@
+package text
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// hardBreak is used in the inline text to signal a forced line break.
+	hardBreak = '\u2028'
+	// nbsp is a non breaking space, it is converted to a space after wrapping.
+	nbsp = '\u00a0'
+	// nbhy is a non breaking hyphen, it is converted to a hyphen after wrapping.
+	nbhy = '\u2011'
+)
+
+// wrap word wraps text to lines of at most width characters. Words longer than width are put on a line
+// of their own.
+func wrap(text string, width int) []string {
+	lines := []string{}
+	for _, para := range strings.Split(text, string(hardBreak)) {
+		words := strings.FieldsFunc(para, func(r rune) bool {
+			return r == ' ' || r == '\n' || r == '\t'
+		})
+		line := ""
+		for _, word := range words {
+			switch {
+			case line == "":
+				line = word
+			case length(line)+1+length(word) <= width:
+				line += " " + word
+			default:
+				lines = append(lines, unspace(line))
+				line = word
+			}
+		}
+		lines = append(lines, unspace(line))
+	}
+	// A trailing hard break doesn't result in an extra line.
+	if len(lines) > 1 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func unspace(s string) string {
+	return strings.NewReplacer(string(nbsp), " ", string(nbhy), "-").Replace(s)
+}
+
+// length returns the number of characters in s.
+func length(s string) int { return utf8.RuneCountInString(s) }
+
+// center centers s in width.
+func center(s string, width int) string {
+	l := length(s)
+	if l >= width {
+		return s
+	}
+	return strings.Repeat(" ", (width-l)/2) + s
+}
+
+// spread returns a line of width characters with left flush left, middle centered and right flush right.
+// If the parts don't fit middle is truncated.
+func spread(left, middle, right string, width int) string {
+	room := width - length(left) - length(right) - 2
+	if length(middle) > room {
+		if room < 0 {
+			room = 0
+		}
+		middle = string([]rune(middle)[:room])
+	}
+	line := []rune(strings.Repeat(" ", width))
+	copy(line, []rune(left))
+	start := (width - length(middle)) / 2
+	if start < length(left)+1 {
+		start = length(left) + 1
+	}
+	if start+length(middle) > width-length(right)-1 {
+		start = width - length(right) - 1 - length(middle)
+	}
+	copy(line[start:], []rune(middle))
+	copy(line[width-length(right):], []rune(right))
+	return strings.TrimRight(string(line), " ")
+}
+
+// columns returns the left and right columns joined, with right flush right.
+func columns(left, right []string, width int) []string {
+	n := len(left)
+	if len(right) > n {
+		n = len(right)
+	}
+	lines := make([]string, n)
+	for i := 0; i < n; i++ {
+		l, r := "", ""
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		lines[i] = spread(l, "", r, width)
+	}
+	return lines
+}
+
+// expandTabs expands tabs to 4 spaces.
+func expandTabs(s string) string { return strings.ReplaceAll(s, "\t", "    ") }
@@ -5,11 +5,40 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/gomarkdown/markdown/ast"
 	"github.com/mmarkdown/mmark/v2/mast"
 )
 
+// displayReferences outputs a <displayreference> for each of the title block's displayReference
+// entries, these must come first in the <back>.
+func (r *Renderer) displayReferences(w io.Writer) {
+	if r.title == nil || len(r.title.DisplayReference) == 0 {
+		return
+	}
+	targets := make([]string, 0, len(r.title.DisplayReference))
+	for t := range r.title.DisplayReference {
+		targets = append(targets, t)
+	}
+	sort.Strings(targets)
+	for _, t := range targets {
+		to := r.title.DisplayReference[t]
+		// the xref targets of I-Ds don't have the version.
+		if strings.HasPrefix(t, "I-D.") {
+			if hash := strings.Index(t, "#"); hash > 0 {
+				t = t[:hash]
+			}
+		}
+		r.outTag(w, "<displayreference", Attributes([]string{"target", "to"}, []string{t, to}))
+		r.outs(w, "</displayreference>")
+		r.cr(w)
+	}
+}
+
 func (r *Renderer) bibliographyWrapper(w io.Writer, node *mast.BibliographyWrapper, entering bool) {
 	if len(node.GetChildren()) == 0 {
 		return
@@ -46,6 +75,13 @@ func (r *Renderer) bibliography(w io.Writer, node *mast.Bibliography, entering b
 }
 
 func (r *Renderer) bibliographyItem(w io.Writer, node *mast.BibliographyItem) {
+	tag := xiInclude(node.Anchor)
+	if tag != "" && r.title != nil && r.title.XIInclude.Includes(string(node.Anchor)) {
+		r.outs(w, tag)
+		r.cr(w)
+		return
+	}
+
 	if node.Reference != nil {
 		data, _ := xml.MarshalIndent(node.Reference, "", "  ")
 		r.out(w, data)
@@ -60,18 +96,31 @@ func (r *Renderer) bibliographyItem(w io.Writer, node *mast.BibliographyItem) {
 		return
 	}
 
-	tag := ""
+	r.outs(w, tag)
+	r.cr(w)
+}
+
+var bcpStd = regexp.MustCompile(`^(BCP|STD)(\d+)$`)
+
+// xiInclude returns the xi:include of the bib.ietf.org reference for anchor, or the empty string if
+// there is none.
+func xiInclude(anchor []byte) string {
 	switch {
-	case bytes.HasPrefix(node.Anchor, []byte("RFC")):
-		tag = makeXiInclude(BibRFC, fmt.Sprintf("reference.RFC.%s.xml", node.Anchor[3:]))
+	case bytes.HasPrefix(anchor, []byte("RFC")):
+		return makeXiInclude(BibRFC, fmt.Sprintf("reference.RFC.%s.xml", anchor[3:]))
+
+	case bytes.HasPrefix(anchor, []byte("W3C.")):
+		return makeXiInclude(BibW3C, fmt.Sprintf("reference.W3C.%s.xml", anchor[4:]))
 
-	case bytes.HasPrefix(node.Anchor, []byte("W3C.")):
-		tag = makeXiInclude(BibW3C, fmt.Sprintf("reference.W3C.%s.xml", node.Anchor[4:]))
+	case bytes.HasPrefix(anchor, []byte("3GPP.")):
+		return makeXiInclude(Bib3GPP, fmt.Sprintf("reference.3GPP.%s.xml", anchor[5:]))
 
-	case bytes.HasPrefix(node.Anchor, []byte("I-D.")):
-		hash := bytes.Index(node.Anchor, []byte("#"))
-		draft := ""
-		if hash > 0 {
+	case bytes.HasPrefix(anchor, []byte("IEEE.")):
+		return makeXiInclude(BibIEEE, fmt.Sprintf("reference.IEEE.%s.xml", anchor[5:]))
+
+	case bytes.HasPrefix(anchor, []byte("I-D.")):
+		draft := anchor[4:]
+		if hash := bytes.Index(draft, []byte("#")); hash > 0 {
 			// no version: https://bib.ietf.org/public/rfc/bibxml3/reference.I-D.brzozowski-dhc-dhcvp6-leasequery.xml
 			//
 			// with version: https://bib.ietf.org/public/rfc/bibxml3/reference.I-D.draft-brzozowski-dhc-dhcvp6-leasequery-00.xml
@@ -80,15 +129,16 @@ func (r *Renderer) bibliographyItem(w io.Writer, node *mast.BibliographyItem) {
 			// the anchor text from the reference is: anchor="I-D.brzozowski-dhc-dhcvp6-leasequery"
 			// problem here is that the original xref includes #00, which isn't the case in the reference
 			// any more.
-
-			draft = "draft-"
-			node.Anchor[hash] = '-'
-			defer func() { node.Anchor[hash] = '#' }() // never know if this will be used again
+			draft = []byte(fmt.Sprintf("draft-%s-%s", draft[:hash], draft[hash+1:]))
 		}
-		tag = makeXiInclude(BibID, fmt.Sprintf("reference.I-D.%s%s.xml", draft, node.Anchor[4:]))
+		return makeXiInclude(BibID, fmt.Sprintf("reference.I-D.%s.xml", draft))
+
+	case bcpStd.Match(anchor):
+		m := bcpStd.FindSubmatch(anchor)
+		n, _ := strconv.Atoi(string(m[2]))
+		return makeXiInclude(BibBCP, fmt.Sprintf("reference.%s.%04d.xml", m[1], n))
 	}
-	r.outs(w, tag)
-	r.cr(w)
+	return ""
 }
 
 func makeXiInclude(url, reference string) string {
@@ -97,7 +147,10 @@ func makeXiInclude(url, reference string) string {
 }
 
 var (
-	BibRFC = "https://bib.ietf.org/public/rfc/bibxml"
-	BibID  = "https://bib.ietf.org/public/rfc/bibxml3"
-	BibW3C = "https://bib.ietf.org/public/rfc/bibxml4"
+	BibRFC  = "https://bib.ietf.org/public/rfc/bibxml"
+	BibID   = "https://bib.ietf.org/public/rfc/bibxml3"
+	BibW3C  = "https://bib.ietf.org/public/rfc/bibxml4"
+	Bib3GPP = "https://bib.ietf.org/public/rfc/bibxml5"
+	BibIEEE = "https://bib.ietf.org/public/rfc/bibxml6"
+	BibBCP  = "https://bib.ietf.org/public/rfc/bibxml9" // BCPs and STDs
 )
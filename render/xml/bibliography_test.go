@@ -0,0 +1,74 @@
+package xml
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/mast/reference"
+)
+
+func TestXiInclude(t *testing.T) {
+	for anchor, want := range map[string]string{
+		"RFC2119":         BibRFC + "/reference.RFC.2119.xml",
+		"I-D.ietf-foo":    BibID + "/reference.I-D.ietf-foo.xml",
+		"I-D.ietf-foo#03": BibID + "/reference.I-D.draft-ietf-foo-03.xml",
+		"BCP14":           BibBCP + "/reference.BCP.0014.xml",
+		"STD7":            BibBCP + "/reference.STD.0007.xml",
+		"3GPP.23.501":     Bib3GPP + "/reference.3GPP.23.501.xml",
+		"IEEE.802.1Q":     BibIEEE + "/reference.IEEE.802.1Q.xml",
+	} {
+		if got := xiInclude([]byte(anchor)); got != `<xi:include href="`+want+`"/>` {
+			t.Errorf("expected xi:include of %s for %s, got %s", want, anchor, got)
+		}
+	}
+	if got := xiInclude([]byte("pandoc")); got != "" {
+		t.Errorf("expected no xi:include, got %s", got)
+	}
+}
+
+func TestBibliographyItemXiInclude(t *testing.T) {
+	d := &mast.TitleData{}
+	if _, err := toml.Decode(`xiInclude = ["RFC2119"]`, d); err != nil {
+		t.Fatal(err)
+	}
+	r := NewRenderer(RendererOptions{})
+	r.title = &mast.Title{TitleData: d}
+
+	ref := &reference.Reference{Anchor: "RFC2119"}
+	buf := &bytes.Buffer{}
+	r.bibliographyItem(buf, &mast.BibliographyItem{Anchor: []byte("RFC2119"), Reference: ref})
+	if !strings.HasPrefix(buf.String(), "<xi:include") {
+		t.Errorf("expected xi:include for RFC2119, got %s", buf.String())
+	}
+
+	ref = &reference.Reference{Anchor: "RFC8174"}
+	buf.Reset()
+	r.bibliographyItem(buf, &mast.BibliographyItem{Anchor: []byte("RFC8174"), Reference: ref})
+	if !strings.HasPrefix(buf.String(), "<reference") {
+		t.Errorf("expected the reference for RFC8174, got %s", buf.String())
+	}
+}
+
+func TestDisplayReferences(t *testing.T) {
+	d := &mast.TitleData{}
+	if _, err := toml.Decode(`[displayReference]
+"I-D.ietf-foo-bar#07" = "FOO"
+RFC2119 = "BCP14 Keywords"
+`, d); err != nil {
+		t.Fatal(err)
+	}
+	r := NewRenderer(RendererOptions{})
+	r.title = &mast.Title{TitleData: d}
+
+	buf := &bytes.Buffer{}
+	r.displayReferences(buf)
+	want := `<displayreference target="I-D.ietf-foo-bar" to="FOO"></displayreference>
+<displayreference target="RFC2119" to="BCP14 Keywords"></displayreference>
+`
+	if got := buf.String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,143 @@
+package xml
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mmarkdown/mmark/v2/lang"
+)
+
+// Suffix is the parsed suffix of a citation, the text after the comma in [@RFC2119, see, Section 2].
+type Suffix struct {
+	Format   string // sectionFormat: "of", "comma", "parens" or "bare", empty when there is no section
+	Name     string // the word used for the section, i.e. "Section" or "Appendix"
+	Section  string // the section, i.e. "2" or "A.1"
+	Relative string // the fragment of the section in the referenced document, i.e. "#name-terminology"
+	Text     string // the display text of the citation, given in double quotes
+}
+
+// ParseSuffix parses the suffix of a citation. The suffix is an optional display text in double
+// quotes, followed by the section: "Section 2" (sectionFormat "of"), "see, Section 2" or "see Section
+// 2" ("comma"), "(see) Section 2" ("parens") or anything else ("bare"). "Appendix" can be used
+// instead of "Section". A last word that starts with # is the relative part, the fragment of the
+// section in the referenced document. Words are matched case insensitively in language l.
+func ParseSuffix(suffix []byte, l lang.Lang) Suffix {
+	s := Suffix{}
+	rest := strings.TrimSpace(string(suffix))
+
+	if strings.HasPrefix(rest, `"`) {
+		if end := strings.Index(rest[1:], `"`); end >= 0 {
+			s.Text = rest[1 : end+1]
+			rest = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest[end+2:]), ","))
+		}
+	}
+	if i := strings.LastIndexAny(rest, " \t"); strings.HasPrefix(rest[i+1:], "#") {
+		s.Relative = rest[i+1:]
+		rest = strings.TrimSpace(rest[:i+1])
+	}
+	if rest == "" {
+		return s
+	}
+
+	see := strings.ToLower(l.See())
+	lower := strings.ToLower(rest)
+	for _, name := range []string{strings.ToLower(l.Section()), "section", "appendix"} {
+		if name == "" {
+			continue
+		}
+		prefixes := []struct{ prefix, format string }{
+			{name + " ", "of"},
+		}
+		if see != "" {
+			prefixes = append(prefixes, []struct{ prefix, format string }{
+				{"(" + see + ") " + name + " ", "parens"},
+				{see + ", " + name + " ", "comma"},
+				{see + " " + name + " ", "comma"},
+			}...)
+		}
+		for _, p := range prefixes {
+			if strings.HasPrefix(lower, p.prefix) {
+				s.Format = p.format
+				s.Section = strings.TrimSpace(rest[len(p.prefix):])
+				s.Name = rest[len(p.prefix)-len(name)-1 : len(p.prefix)-1]
+				return s
+			}
+		}
+	}
+	s.Format = "bare"
+	s.Section = rest
+	return s
+}
+
+// Attributes returns the attributes for the <xref> of the citation.
+func (s Suffix) Attributes() []string {
+	if s.Section == "" {
+		return nil
+	}
+	return Attributes([]string{"sectionFormat", "section", "relative"}, []string{s.Format, s.Section, s.Relative})
+}
+
+// Label returns the label of the section, i.e. "Section 2", or just the section for sectionFormat "bare".
+// The name is capitalized, as xml2rfc does, also when it's written as "section 2".
+func (s Suffix) Label() string {
+	if s.Format == "bare" || s.Name == "" {
+		return s.Section
+	}
+	r, n := utf8.DecodeRuneInString(s.Name)
+	return string(unicode.ToUpper(r)) + s.Name[n:] + " " + s.Section
+}
+
+// Cite returns cite, the citation as text, i.e. "[RFC2119]", combined with the suffix, as the text
+// output of xml2rfc does: "Section 2 of [RFC2119]", "[RFC2119], Section 2" or "[RFC2119] (Section 2)".
+// The parts of the suffix are used as is, so these need to be escaped for the output format first.
+func (s Suffix) Cite(cite string, l lang.Lang) string { return s.Join(cite, s.Label(), l) }
+
+// Join is like Cite, but uses label as the label of the section, i.e. to make it a link.
+func (s Suffix) Join(cite, label string, l lang.Lang) string {
+	if s.Text != "" {
+		cite = s.Text + " " + cite
+	}
+	switch s.Format {
+	case "of":
+		of := l.Of()
+		if of == "" {
+			return cite + ", " + label
+		}
+		return label + " " + of + " " + cite
+	case "comma", "bare":
+		return cite + ", " + label
+	case "parens":
+		return cite + " (" + label + ")"
+	}
+	return cite
+}
+
+// URL returns the URL of the section in the published document of anchor, for RFCs and I-Ds. For
+// other documents, or when the suffix has no section, the empty string is returned.
+func (s Suffix) URL(anchor string) string {
+	if s.Section == "" {
+		return ""
+	}
+	url := ""
+	switch {
+	case strings.HasPrefix(anchor, "RFC") && len(anchor) > 3 && strings.Trim(anchor[3:], "0123456789") == "":
+		url = "https://www.rfc-editor.org/rfc/rfc" + strings.TrimLeft(anchor[3:], "0") + ".html"
+	case strings.HasPrefix(anchor, "I-D.") && len(anchor) > 4:
+		draft := anchor[4:]
+		if hash := strings.Index(draft, "#"); hash > 0 {
+			draft = draft[:hash] + "-" + draft[hash+1:]
+		}
+		url = "https://datatracker.ietf.org/doc/html/draft-" + draft
+	default:
+		return ""
+	}
+	if s.Relative != "" {
+		return url + s.Relative
+	}
+	fragment := "#section-"
+	if strings.EqualFold(s.Name, "appendix") || (s.Format == "bare" && s.Section[0] >= 'A' && s.Section[0] <= 'Z') {
+		fragment = "#appendix-"
+	}
+	return url + fragment + s.Section
+}
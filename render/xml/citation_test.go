@@ -0,0 +1,55 @@
+package xml
+
+import (
+	"testing"
+
+	"github.com/mmarkdown/mmark/v2/lang"
+)
+
+func TestParseSuffix(t *testing.T) {
+	l := lang.New("en")
+	tests := []struct {
+		suffix string
+		want   Suffix
+		cite   string
+	}{
+		{"section 5", Suffix{Format: "of", Name: "section", Section: "5"}, "Section 5 of [RFC2535]"},
+		{"Section 5", Suffix{Format: "of", Name: "Section", Section: "5"}, "Section 5 of [RFC2535]"},
+		{"see, Section 5", Suffix{Format: "comma", Name: "Section", Section: "5"}, "[RFC2535], Section 5"},
+		{"see Appendix A.1", Suffix{Format: "comma", Name: "Appendix", Section: "A.1"}, "[RFC2535], Appendix A.1"},
+		{"(see) section 5", Suffix{Format: "parens", Name: "section", Section: "5"}, "[RFC2535] (Section 5)"},
+		{"5.1", Suffix{Format: "bare", Section: "5.1"}, "[RFC2535], 5.1"},
+		{"Section 5 #name-security", Suffix{Format: "of", Name: "Section", Section: "5", Relative: "#name-security"}, "Section 5 of [RFC2535]"},
+		{`"DNSSEC", see, Section 5`, Suffix{Format: "comma", Name: "Section", Section: "5", Text: "DNSSEC"}, "DNSSEC [RFC2535], Section 5"},
+		{`"DNSSEC"`, Suffix{Text: "DNSSEC"}, "DNSSEC [RFC2535]"},
+	}
+	for _, tc := range tests {
+		got := ParseSuffix([]byte(tc.suffix), l)
+		if got != tc.want {
+			t.Errorf("%q: expected %+v, got %+v", tc.suffix, tc.want, got)
+		}
+		if cite := got.Cite("[RFC2535]", l); cite != tc.cite {
+			t.Errorf("%q: expected %q, got %q", tc.suffix, tc.cite, cite)
+		}
+	}
+}
+
+func TestSuffixURL(t *testing.T) {
+	l := lang.New("en")
+	tests := []struct {
+		anchor, suffix, want string
+	}{
+		{"RFC7991", "section 2.45", "https://www.rfc-editor.org/rfc/rfc7991.html#section-2.45"},
+		{"RFC0793", "see, Appendix A", "https://www.rfc-editor.org/rfc/rfc793.html#appendix-A"},
+		{"RFC7991", "B.1", "https://www.rfc-editor.org/rfc/rfc7991.html#appendix-B.1"},
+		{"RFC7991", "section 2 #name-xref", "https://www.rfc-editor.org/rfc/rfc7991.html#name-xref"},
+		{"I-D.ietf-foo#03", "section 3", "https://datatracker.ietf.org/doc/html/draft-ietf-foo-03#section-3"},
+		{"RFC7991", `"xml2rfc v3"`, ""},
+		{"pandoc", "section 3", ""},
+	}
+	for _, tc := range tests {
+		if got := ParseSuffix([]byte(tc.suffix), l).URL(tc.anchor); got != tc.want {
+			t.Errorf("%s, %s: expected %q, got %q", tc.anchor, tc.suffix, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package xml
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+// FoldWidth is the maximum line length of sourcecode and artwork in an RFC.
+const FoldWidth = 69
+
+// The headers of RFC 8792 folded text, for the single and double backslash strategies.
+const (
+	foldHeaderSingle = `=============== NOTE: '\' line wrapping per RFC 8792 ================`
+	foldHeaderDouble = `============== NOTE: '\\' line wrapping per RFC 8792 ===============`
+)
+
+// Fold folds the lines of text longer than FoldWidth as described in RFC 8792. With the single
+// backslash strategy a folded line ends in a '\' and continues on the next line. When that isn't
+// possible, because a line already ends in a '\' or can only be continued with a space, or when double
+// is true, the double backslash strategy is used: the continuation line starts with a '\' as well. If no
+// line is too long text is returned as is.
+func Fold(text []byte, double bool) []byte {
+	lines := strings.Split(strings.TrimSuffix(string(text), "\n"), "\n")
+	long := false
+	for _, l := range lines {
+		if utf8.RuneCountInString(l) > FoldWidth {
+			long = true
+			break
+		}
+	}
+	if !long {
+		return text
+	}
+
+	if !double {
+		if folded, ok := foldSingle(lines); ok {
+			return folded
+		}
+	}
+	return foldDouble(lines)
+}
+
+// foldSingle folds lines with the single backslash strategy, it returns false if that can't be done.
+func foldSingle(lines []string) ([]byte, bool) {
+	buf := &bytes.Buffer{}
+	buf.WriteString(foldHeaderSingle + "\n\n")
+	for _, l := range lines {
+		if strings.HasSuffix(l, `\`) {
+			return nil, false
+		}
+		r := []rune(l)
+		for len(r) > FoldWidth {
+			// break before FoldWidth-1, but not so the next line starts with a space, as the
+			// leading whitespace of a continuation line is removed when unfolding.
+			i := FoldWidth - 1
+			for i > 0 && r[i] == ' ' {
+				i--
+			}
+			if i == 0 {
+				return nil, false
+			}
+			buf.WriteString(string(r[:i]) + "\\\n")
+			r = r[i:]
+		}
+		buf.WriteString(string(r) + "\n")
+	}
+	return buf.Bytes(), true
+}
+
+// foldDouble folds lines with the double backslash strategy.
+func foldDouble(lines []string) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString(foldHeaderDouble + "\n\n")
+	for _, l := range lines {
+		r := []rune(l)
+		width := FoldWidth
+		for len(r) > width {
+			buf.WriteString(string(r[:width-1]) + "\\\n\\")
+			r = r[width-1:]
+			width = FoldWidth - 1 // the continuation line starts with a '\'
+		}
+		buf.WriteString(string(r) + "\n")
+	}
+	return buf.Bytes()
+}
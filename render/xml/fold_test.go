@@ -0,0 +1,50 @@
+package xml
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFold(t *testing.T) {
+	long := strings.Repeat("0123456789", 8) // 80 characters
+	short := "short line\n"
+	if got := string(Fold([]byte(short), false)); got != short {
+		t.Errorf("expected %q to be left alone, got %q", short, got)
+	}
+
+	tests := []struct {
+		in     string
+		double bool
+		want   string
+	}{
+		{
+			long + "\n", false,
+			foldHeaderSingle + "\n\n" + long[:68] + "\\\n" + long[68:] + "\n",
+		},
+		{
+			long + "\n", true,
+			foldHeaderDouble + "\n\n" + long[:68] + "\\\n\\" + long[68:] + "\n",
+		},
+		{
+			// the continuation can't start with a space, so the line is broken earlier.
+			long[:67] + "  abc" + long + "\n", false,
+			foldHeaderSingle + "\n\n" + long[:66] + "\\\n" + long[66:67] + "  abc" + long[:62] + "\\\n" + long[62:] + "\n",
+		},
+		{
+			// a line ending in a backslash needs the double backslash strategy.
+			long + "\nend\\\n", false,
+			foldHeaderDouble + "\n\n" + long[:68] + "\\\n\\" + long[68:] + "\nend\\\n",
+		},
+	}
+	for i, tc := range tests {
+		got := string(Fold([]byte(tc.in), tc.double))
+		if got != tc.want {
+			t.Errorf("test %d, expected\n%s\ngot\n%s", i, tc.want, got)
+		}
+		for _, l := range strings.Split(got, "\n") {
+			if len(l) > FoldWidth {
+				t.Errorf("test %d, line %q is longer than %d", i, l, FoldWidth)
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package xml
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+)
+
+// FootnoteStyle is how footnotes are rendered, RFC 7991 has no footnotes.
+type FootnoteStyle int
+
+const (
+	FootnoteNone  FootnoteStyle = iota // Footnotes are left out, the default.
+	FootnoteCref                       // A footnote becomes a <cref> where it is referenced.
+	FootnoteNotes                      // Footnotes are put in a "Footnotes" section, referenced with an <xref>.
+)
+
+// footnoteAnchor returns the anchor of the nth footnote.
+func footnoteAnchor(n int) string { return fmt.Sprintf("footnote-%d", n) }
+
+// footnoteLink renders the reference to a footnote.
+func (r *Renderer) footnoteLink(w io.Writer, link *ast.Link) {
+	switch r.opts.Footnotes {
+	case FootnoteCref:
+		r.outs(w, "<cref>")
+		for i, c := range link.Footnote.GetChildren() {
+			// a <cref> can only contain inline elements, so the paragraphs of the footnote are joined.
+			if para, ok := c.(*ast.Paragraph); ok {
+				if i > 0 {
+					r.outs(w, " ")
+				}
+				for _, inline := range para.Children {
+					ast.WalkFunc(inline, func(node ast.Node, entering bool) ast.WalkStatus {
+						return r.RenderNode(w, node, entering)
+					})
+				}
+				continue
+			}
+			ast.WalkFunc(c, func(node ast.Node, entering bool) ast.WalkStatus {
+				return r.RenderNode(w, node, entering)
+			})
+		}
+		r.outs(w, "</cref>")
+	case FootnoteNotes:
+		r.outs(w, `<xref target="`+footnoteAnchor(link.NoteID)+`"/>`)
+	}
+}
+
+// footnotes renders the list of footnotes, as a section when the style is FootnoteNotes, otherwise
+// the list is skipped.
+func (r *Renderer) footnotes(w io.Writer, list *ast.List, entering bool) ast.WalkStatus {
+	if r.opts.Footnotes != FootnoteNotes {
+		return ast.SkipChildren
+	}
+	if !entering {
+		r.outs(w, "</ol>")
+		r.cr(w)
+		r.outs(w, "</section>")
+		r.cr(w)
+		return ast.GoToNext
+	}
+	r.sectionClose(w, nil)
+	r.cr(w)
+	r.outs(w, `<section anchor="footnotes">`)
+	r.outs(w, "<name>")
+	html.EscapeHTML(w, []byte(r.opts.Language.Footnotes()))
+	r.outs(w, "</name>")
+	r.cr(w)
+	r.outs(w, "<ol>")
+	r.cr(w)
+	return ast.GoToNext
+}
+
+// footnoteItem renders a footnote in the list of footnotes.
+func (r *Renderer) footnoteItem(w io.Writer, item *ast.ListItem, entering bool) {
+	if !entering {
+		r.outs(w, "</li>")
+		r.cr(w)
+		return
+	}
+	// the parser doesn't set this for footnotes, without it the paragraphs don't get a <t>.
+	for _, c := range item.Children {
+		if _, ok := c.(*ast.Paragraph); ok {
+			item.ListFlags |= ast.ListItemContainsBlock
+		}
+	}
+	n := 1
+	for _, c := range item.Parent.GetChildren() {
+		if c == item {
+			break
+		}
+		n++
+	}
+	r.outs(w, `<li anchor="`+footnoteAnchor(n)+`">`)
+}
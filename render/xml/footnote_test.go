@@ -0,0 +1,37 @@
+package xml
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/lang"
+)
+
+func TestFootnotes(t *testing.T) {
+	const doc = "Text[^1] and more^[inline *note*].\n\n[^1]: The note.\n\n    Second paragraph.\n"
+	tests := []struct {
+		style FootnoteStyle
+		want  []string
+	}{
+		{FootnoteNone, []string{"<t>Text and more.</t>"}},
+		{FootnoteCref, []string{"<t>Text<cref>The note. Second paragraph.</cref> and more<cref>inline <em>note</em></cref>.</t>"}},
+		{FootnoteNotes, []string{
+			`<t>Text<xref target="footnote-1"/> and more<xref target="footnote-2"/>.</t>`,
+			`<section anchor="footnotes"><name>Footnotes</name>`,
+			"<li anchor=\"footnote-1\"><t>The note.</t>\n<t>Second paragraph.</t>\n</li>",
+			`<li anchor="footnote-2">inline <em>note</em></li>`,
+		}},
+	}
+	for _, tc := range tests {
+		p := parser.NewWithExtensions(parser.CommonExtensions | parser.Footnotes)
+		d := markdown.Parse([]byte(doc), p)
+		out := markdown.Render(d, NewRenderer(RendererOptions{Flags: XMLFragment, Language: lang.New("en"), Footnotes: tc.style}))
+		for _, w := range tc.want {
+			if !bytes.Contains(out, []byte(w)) {
+				t.Errorf("style %d: expected %s in:\n%s", tc.style, w, out)
+			}
+		}
+	}
+}
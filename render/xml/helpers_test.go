@@ -1,6 +1,9 @@
 package xml
 
-import "testing"
+import (
+	"io"
+	"testing"
+)
 
 func TestAttributesContains(t *testing.T) {
 	attrs := []string{`style="symbols"`, `class="boo"`}
@@ -12,3 +15,21 @@ func TestAttributesContains(t *testing.T) {
 		t.Errorf("expected %s to be not present in attrs", "stle")
 	}
 }
+
+func BenchmarkAttributes(b *testing.B) {
+	keys := []string{"anchor", "target", "quoteTitle", "derivedAnchor"}
+	values := []string{"RFC2119", "https://www.rfc-editor.org/info/rfc2119", "true", ""}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Attributes(keys, values)
+	}
+}
+
+func BenchmarkOutTag(b *testing.B) {
+	r := NewRenderer(RendererOptions{})
+	attrs := Attributes([]string{"anchor", "numbered", "toc"}, []string{"section-1", "true", "default"})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.outTag(io.Discard, "<section", attrs)
+	}
+}
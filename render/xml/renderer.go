@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"fmt"
 	"io"
-	"log"
 	"path"
 	"path/filepath"
 	"strconv"
@@ -15,6 +14,8 @@ import (
 	"github.com/gomarkdown/markdown/html"
 	"github.com/mmarkdown/mmark/v2/lang"
 	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/render/diagram"
+	"github.com/mmarkdown/mmark/v2/render/tex"
 )
 
 // Flags control optional behavior of XML3 renderer.
@@ -27,6 +28,9 @@ const (
 	SkipHTML                       // Skip preformatted HTML blocks - skips comments
 	SkipImages                     // Skip embedded images
 	AllowUnicode                   // Allow bare unicode, otherwise wrap in <u>
+	FoldLines                      // Fold long lines in sourcecode and artwork, see Fold
+	FoldDouble                     // Fold long lines with the double backslash strategy, see Fold
+	KeepComments                   // Output HTML comments as XML comments
 
 	CommonFlags Flags = FlagsNone
 )
@@ -51,6 +55,23 @@ type RendererOptions struct {
 	Generator string
 
 	Language lang.Lang // Input/Output language for the document.
+
+	// Diagrams converts the diagrams in fenced code blocks to SVG, if nil they are output as source code.
+	Diagrams *diagram.Diagrams
+
+	// Footnotes is how footnotes are rendered, for these the parser must not skip the footnote list.
+	Footnotes FootnoteStyle
+
+	// Sourcecode maps the language of a fenced code block to the type of the <sourcecode>, it takes
+	// precedence over SourcecodeAliases.
+	Sourcecode map[string]string
+
+	// SVG, if set, returns the (sanitized) SVG of an image's destination, that is then inlined in the
+	// artwork instead of referenced with src. It returns nil to keep the reference.
+	SVG func(src string) []byte
+
+	// Report, if set, is called with the problems found while rendering, otherwise these are logged.
+	Report mast.Reporter
 }
 
 // Renderer implements Renderer interface for IETF XMLv3 output. See RFC 7991.
@@ -63,6 +84,9 @@ type Renderer struct {
 	filter         mast.FilterFunc     // filter for attributes
 	contacts       bool                // we are outputing a special "para" with only <contact>s
 	indices        bool                // we are outputting a speicla "para" with only <iref>s
+	artset         ast.Node            // the figure with the class "artset" we are in, see inArtset
+	svg            []byte              // the SVG of the image we are in, to be inlined
+	warned         map[string]bool     // the unknown sourcecode types we warned about
 
 	// Track heading IDs to prevent ID collision in a single generation.
 	headingIDs map[string]int
@@ -120,7 +144,7 @@ func (r *Renderer) text(w io.Writer, text *ast.Text) {
 	// each string of unicode chars is outputted between <u> tags.
 	uni := 0
 	for i, c := range string(text.Literal) {
-		if c > unicode.MaxASCII {
+		if c > unicode.MaxASCII && !allowed(c) {
 			uni += len(string(c))
 			continue
 		}
@@ -142,6 +166,15 @@ func (r *Renderer) text(w io.Writer, text *ast.Text) {
 
 }
 
+// allowed returns true for the non-ASCII characters that xml2rfc allows without a <u>, see RFC 7997.
+func allowed(c rune) bool {
+	switch c {
+	case '\u00a0', '\u200b', '\u2011', '\u2060':
+		return true
+	}
+	return false
+}
+
 func (r *Renderer) hardBreak(w io.Writer, node *ast.Hardbreak) {
 	r.outs(w, "<br />")
 	r.cr(w)
@@ -169,6 +202,7 @@ func (r *Renderer) matter(w io.Writer, node *ast.DocumentMatter) {
 		r.outs(w, "<front>")
 		r.cr(w)
 	case ast.DocumentMatterMain:
+		r.titleNotes(w)
 		r.cr(w)
 		r.outs(w, "</front>")
 		r.cr(w)
@@ -182,6 +216,7 @@ func (r *Renderer) matter(w io.Writer, node *ast.DocumentMatter) {
 		r.cr(w)
 		r.outs(w, "<back>")
 		r.cr(w)
+		r.displayReferences(w)
 	}
 	r.documentMatter = node.Matter
 }
@@ -266,37 +301,16 @@ func (r *Renderer) citation(w io.Writer, node *ast.Citation, entering bool) {
 
 		attr := []string{fmt.Sprintf(`target="%s"`, c)}
 
-		// Attempt to parse the suffix.
+		suffix := Suffix{}
 		if len(node.Suffix) > i {
-			suf := node.Suffix[i]
-			if len(suf) > 0 {
-				section := r.opts.Language.Section() + " "                                          // section
-				seesection := r.opts.Language.See() + ", " + r.opts.Language.Section() + " "        // see, section
-				seepsection := "(" + r.opts.Language.See() + ") " + r.opts.Language.Section() + " " // (see) section
-				switch {
-				case bytes.HasPrefix(suf, []byte(section)):
-					num := suf[len(section):]
-					attr = append(attr, `sectionFormat="of"`)
-					attr = append(attr, `section="`+string(num)+`"`)
-
-				case bytes.HasPrefix(suf, []byte(seesection)):
-					num := suf[len(seesection):]
-					attr = append(attr, `sectionFormat="comma"`)
-					attr = append(attr, `section="`+string(num)+`"`)
-
-				case bytes.HasPrefix(suf, []byte(seepsection)):
-					num := suf[len(seepsection):]
-					attr = append(attr, `sectionFormat="parens"`)
-					attr = append(attr, `section="`+string(num)+`"`)
-
-				default:
-					attr = append(attr, `sectionFormat="bare"`)
-					attr = append(attr, `section="`+string(suf)+`"`)
-				}
-			}
+			suffix = ParseSuffix(node.Suffix[i], r.opts.Language)
+			attr = append(attr, suffix.Attributes()...)
 		}
 
 		r.outTag(w, "<xref", attr)
+		if suffix.Text != "" {
+			html.EscapeHTML(w, []byte(suffix.Text))
+		}
 		r.outs(w, "</xref>")
 	}
 }
@@ -407,12 +421,16 @@ func (r *Renderer) listEnter(w io.Writer, nodeData *ast.List) {
 				mast.SetAttribute(nodeData, "type", []byte("%d)"))
 			}
 		}
+		if nodeData.Delimiter == mast.CalloutDelimiter {
+			mast.SetAttribute(nodeData, "type", []byte("&lt;%d&gt;"))
+		}
 
 		openTag = "<ol"
 	}
 	if nodeData.ListFlags&ast.ListTypeDefinition != 0 {
 		openTag = "<dl"
 	}
+	listAttributes(nodeData, r.opts.Report)
 	if nodeData.Tight {
 		set := mast.Attribute(nodeData, "spacing")
 		if len(set) == 0 {
@@ -423,6 +441,33 @@ func (r *Renderer) listEnter(w io.Writer, nodeData *ast.List) {
 	r.cr(w)
 }
 
+// listAttributes removes the newline, spacing and indent attributes from list when their value isn't
+// allowed by xml2rfc, newline is only allowed on a definition list. These are reported with report.
+func listAttributes(list *ast.List, report mast.Reporter) {
+	for key, valid := range map[string]func(v string) bool{
+		"newline": func(v string) bool { return v == "true" || v == "false" },
+		"spacing": func(v string) bool { return v == "normal" || v == "compact" },
+		"indent": func(v string) bool {
+			_, err := strconv.ParseUint(v, 10, 32)
+			return err == nil || v == "adaptive"
+		},
+	} {
+		v := mast.Attribute(list, key)
+		if v == nil {
+			continue
+		}
+		if key == "newline" && list.ListFlags&ast.ListTypeDefinition == 0 {
+			report.Reportf(mast.SeverityWarning, "list-attribute", "attribute %q is only allowed on a definition list", key)
+			mast.DeleteAttribute(list, key)
+			continue
+		}
+		if !valid(string(v)) {
+			report.Reportf(mast.SeverityWarning, "list-attribute", "invalid value %q for list attribute %q", v, key)
+			mast.DeleteAttribute(list, key)
+		}
+	}
+}
+
 func (r *Renderer) listExit(w io.Writer, list *ast.List) {
 	if list.IsFootnotesList {
 		return
@@ -442,7 +487,7 @@ func (r *Renderer) listExit(w io.Writer, list *ast.List) {
 		if ast.GetNextNode(list) != nil {
 			r.cr(w)
 		}
-	case *ast.Document, *ast.BlockQuote, *ast.Aside:
+	case *ast.Document, *ast.BlockQuote, *ast.Aside, *mast.Div:
 		r.cr(w)
 	}
 
@@ -499,12 +544,17 @@ func (r *Renderer) listItem(w io.Writer, listItem *ast.ListItem, entering bool)
 }
 
 func (r *Renderer) codeBlock(w io.Writer, codeBlock *ast.CodeBlock) {
+	if d := r.opts.Diagrams.Diagram(codeBlock); d != nil {
+		r.diagram(w, codeBlock, d)
+		return
+	}
 	mast.AttributeInit(codeBlock)
 	appendLanguageAttr(codeBlock, codeBlock.Info)
 
 	name := "artwork"
-	if len(codeBlock.Info) != 0 {
+	if len(codeBlock.Info) != 0 && !r.inArtset(codeBlock.Parent) {
 		name = "sourcecode"
+		mast.SetAttribute(codeBlock, "type", []byte(r.sourcecodeType(string(mast.Attribute(codeBlock, "type")))))
 	}
 
 	r.cr(w)
@@ -513,18 +563,61 @@ func (r *Renderer) codeBlock(w io.Writer, codeBlock *ast.CodeBlock) {
 	if r.opts.Comments != nil {
 		callout = callouts(codeBlock.Literal, r.opts.Comments)
 	}
+	literal := codeBlock.Literal
+	if r.opts.Flags&(FoldLines|FoldDouble) != 0 {
+		literal = Fold(literal, r.opts.Flags&FoldDouble != 0)
+	}
 	if callout {
-		EscapeHTMLCallouts(w, codeBlock.Literal, r.opts.Comments)
+		EscapeHTMLCallouts(w, literal, r.opts.Comments)
 	} else {
 		r.outs(w, "<![CDATA[")
-		r.out(w, codeBlock.Literal)
+		r.out(w, literal)
 		r.outs(w, "]]>\n")
 	}
 	r.outs(w, "</"+name+">")
 	r.cr(w)
 }
 
+// diagram outputs the SVG of a diagram, if it has text as well both are put in an <artset>. In a
+// figure that is an artset already, see inArtset, they are just added to that.
+func (r *Renderer) diagram(w io.Writer, codeBlock *ast.CodeBlock, d *diagram.Diagram) {
+	r.cr(w)
+	wrap := d.Text != nil && !r.inArtset(codeBlock.Parent)
+	if wrap {
+		r.outTag(w, "<artset", html.BlockAttrs(codeBlock))
+		r.cr(w)
+	}
+	if d.Text != nil {
+		r.outs(w, `<artwork type="ascii-art"><![CDATA[`)
+		r.out(w, d.Text)
+		if !bytes.HasSuffix(d.Text, []byte("\n")) {
+			r.outs(w, "\n")
+		}
+		r.outs(w, "]]>\n</artwork>\n")
+		r.outs(w, `<artwork type="svg">`)
+	} else {
+		r.outTag(w, `<artwork type="svg"`, html.BlockAttrs(codeBlock))
+	}
+	r.cr(w)
+	r.out(w, d.SVG)
+	r.cr(w)
+	r.outs(w, "</artwork>")
+	if wrap {
+		r.outs(w, "\n</artset>")
+	}
+	r.cr(w)
+}
+
+// inArtset returns true if node is a figure with the class "artset", its artworks are alternatives
+// of each other and are put in a single <artset>.
+func (r *Renderer) inArtset(node ast.Node) bool {
+	return node != nil && node == r.artset
+}
+
 func (r *Renderer) tableCell(w io.Writer, tableCell *ast.TableCell, entering bool) {
+	if mast.RowSpan(tableCell) == 0 {
+		return
+	}
 	if !entering {
 		r.outOneOf(w, tableCell.IsHeader, "</th>", "</td>")
 		r.cr(w)
@@ -545,7 +638,15 @@ func (r *Renderer) tableCell(w io.Writer, tableCell *ast.TableCell, entering boo
 		mast.SetAttribute(tableCell, "colspan", []byte(fmt.Sprintf("%d", colspan)))
 
 	}
-	if ast.GetPrevNode(tableCell) == nil {
+	// the first cell of the row that is rendered, cells covered by a row span are not.
+	prev := ast.GetPrevNode(tableCell)
+	for prev != nil {
+		if c, ok := prev.(*ast.TableCell); !ok || mast.RowSpan(c) > 0 {
+			break
+		}
+		prev = ast.GetPrevNode(prev)
+	}
+	if prev == nil {
 		r.cr(w)
 	}
 	r.outTag(w, openTag, html.BlockAttrs(tableCell))
@@ -557,6 +658,9 @@ func (r *Renderer) tableBody(w io.Writer, node *ast.TableBody, entering bool) {
 
 func (r *Renderer) htmlSpan(w io.Writer, span *ast.HTMLSpan) {
 	if _, ok := IsComment(span.Literal); ok {
+		if r.opts.Flags&KeepComments != 0 {
+			r.out(w, span.Literal)
+		}
 		return
 	}
 	if IsBr(span.Literal) {
@@ -571,6 +675,11 @@ func (r *Renderer) htmlSpan(w io.Writer, span *ast.HTMLSpan) {
 
 func (r *Renderer) htmlBlock(w io.Writer, block *ast.HTMLBlock) {
 	if _, ok := IsComment(block.Literal); ok {
+		if r.opts.Flags&KeepComments != 0 {
+			r.cr(w)
+			r.out(w, bytes.TrimSpace(block.Literal))
+			r.cr(w)
+		}
 		return
 	}
 	if r.opts.Flags&SkipHTML == 0 {
@@ -584,6 +693,24 @@ func (r *Renderer) callout(w io.Writer, callout *ast.Callout) {
 	r.outs(w, "</em>")
 }
 
+func (r *Renderer) unicode(w io.Writer, u *mast.Unicode) {
+	r.outs(w, fmt.Sprintf(`<u format="%s">`, u.Format))
+	html.EscapeHTML(w, u.Literal)
+	r.outs(w, "</u>")
+}
+
+func (r *Renderer) cref(w io.Writer, cref *mast.Cref) {
+	r.outs(w, "<cref")
+	if cref.Source != "" {
+		r.outs(w, ` source="`)
+		html.EscapeHTML(w, []byte(cref.Source))
+		r.outs(w, `"`)
+	}
+	r.outs(w, ">")
+	html.EscapeHTML(w, cref.Literal)
+	r.outs(w, "</cref>")
+}
+
 func (r *Renderer) crossReference(w io.Writer, cr *ast.CrossReference, entering bool) {
 	if entering {
 		attr := []string{fmt.Sprintf(`target="%s"`, cr.Destination)}
@@ -603,6 +730,10 @@ func (r *Renderer) crossReference(w io.Writer, cr *ast.CrossReference, entering
 }
 
 func (r *Renderer) index(w io.Writer, index *ast.Index) {
+	// RFC 7991 has no redirects or ranges, the start of a range is where the <iref> goes.
+	if mast.IndexRedirect(index) || mast.IndexRangeEnd(index) {
+		return
+	}
 	r.outs(w, "<iref")
 	r.outs(w, " item=\"")
 	html.EscapeHTML(w, index.Item)
@@ -642,21 +773,36 @@ func (r *Renderer) image(w io.Writer, node *ast.Image, entering bool) {
 
 func (r *Renderer) imageEnter(w io.Writer, image *ast.Image) {
 	dest := image.Destination
-	r.outs(w, `<artwork src="`)
-	html.EscapeHTML(w, dest)
-	r.outs(w, `"`)
+	r.svg = nil
+	if r.opts.SVG != nil {
+		r.svg = r.opts.SVG(string(dest))
+	}
 	ext := path.Ext(string(dest))
-	if len(ext) > 2 {
+	if r.svg != nil {
+		r.outs(w, `<artwork type="svg"`)
+	} else {
+		r.outs(w, `<artwork src="`)
+		html.EscapeHTML(w, dest)
+		r.outs(w, `"`)
+	}
+	if len(ext) > 2 && r.svg == nil {
 		// warn if not svn or ascii-art.
 		switch ext {
 		case ".svg", ".ascii-art":
 		default:
-			log.Printf("Image extension of %q will likely create errors in XML2RFC", ext)
+			r.opts.Report.Reportf(mast.SeverityWarning, "image", "image extension of %q will likely create errors in xml2rfc", ext)
 		}
 		r.outs(w, ` type="`)
 		r.outs(w, ext[1:])
 		r.outs(w, `"`)
 	}
+	for _, key := range []string{"width", "height", "align"} {
+		if v := mast.Attribute(image, key); v != nil {
+			r.outs(w, " "+key+`="`)
+			html.EscapeHTML(w, v)
+			r.outs(w, `"`)
+		}
+	}
 
 	// alt= will be the alt text (which is normal text so rendered by the normal render flow)
 	r.outs(w, ` alt="`)
@@ -668,7 +814,14 @@ func (r *Renderer) imageExit(w io.Writer, image *ast.Image) {
 		r.outs(w, `" name="`)
 		html.EscapeHTML(w, image.Title)
 	}
-	r.outs(w, `"/>`)
+	if r.svg == nil {
+		r.outs(w, `"/>`)
+		return
+	}
+	r.outs(w, `">`)
+	r.out(w, r.svg)
+	r.outs(w, `</artwork>`)
+	r.svg = nil
 }
 
 func (r *Renderer) code(w io.Writer, node *ast.Code) {
@@ -678,12 +831,9 @@ func (r *Renderer) code(w io.Writer, node *ast.Code) {
 }
 
 func (r *Renderer) mathBlock(w io.Writer, mathBlock *ast.MathBlock) {
-	r.outs(w, `<artwork type="math">`+"\n")
-	if r.opts.Comments != nil {
-		EscapeHTMLCallouts(w, mathBlock.Literal, r.opts.Comments)
-	} else {
-		html.EscapeHTML(w, mathBlock.Literal)
-	}
+	// RFC 7991 has no math, show it as text.
+	r.outs(w, `<artwork type="ascii-art">`+"\n")
+	html.EscapeHTML(w, []byte(tex.Text(string(mathBlock.Literal))+"\n"))
 	r.outs(w, `</artwork>`)
 	r.cr(w)
 }
@@ -721,6 +871,10 @@ func (r *Renderer) captionFigure(w io.Writer, captionFigure *ast.CaptionFigure,
 		}
 	}
 
+	if r.inArtset(captionFigure) {
+		artset = true
+	}
+
 	if !entering {
 		if artset {
 			r.outs(w, "</artset>\n")
@@ -825,15 +979,16 @@ func (r *Renderer) blockQuote(w io.Writer, block *ast.BlockQuote, entering bool)
 			// so we should loose the tags. Hence we render each child separate. This may
 			// still create tags, which is wrong, but up to the user.
 
-			if len(caption.GetChildren()) > 0 {
+			// An explicit quotedFrom attribute wins over the caption.
+			if len(caption.GetChildren()) > 0 && mast.Attribute(block, "quotedFrom") == nil {
 				r.outs(w, ` quotedFrom="`)
+				for _, child1 := range caption.GetChildren() {
+					ast.WalkFunc(child1, func(node ast.Node, entering bool) ast.WalkStatus {
+						return r.RenderNode(w, node, entering)
+					})
+				}
+				r.outs(w, `"`) // closes quotedFrom
 			}
-			for _, child1 := range caption.GetChildren() {
-				ast.WalkFunc(child1, func(node ast.Node, entering bool) ast.WalkStatus {
-					return r.RenderNode(w, node, entering)
-				})
-			}
-			r.outs(w, `"`) // closes quotedFrom
 
 			ast.RemoveFromTree(caption)
 			break
@@ -844,6 +999,10 @@ func (r *Renderer) blockQuote(w io.Writer, block *ast.BlockQuote, entering bool)
 // RenderNode renders a markdown node to XML.
 func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.WalkStatus {
 
+	// the class is filtered out below, so remember the figure.
+	if f, ok := node.(*ast.CaptionFigure); ok && entering && mast.AttributeClass(f, "artset") {
+		r.artset = f
+	}
 	mast.AttributeFilter(node, r.filter)
 
 	if r.opts.RenderNodeHook != nil {
@@ -878,9 +1037,15 @@ func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.Wal
 	case *ast.Hardbreak:
 		r.hardBreak(w, node)
 	case *ast.NonBlockingSpace:
-		// skip
+		r.outs(w, "&#160;")
 	case *ast.Callout:
 		r.callout(w, node)
+	case *mast.Unicode:
+		r.unicode(w, node)
+	case *mast.Abbreviation:
+		html.EscapeHTML(w, []byte(node.String()))
+	case *mast.Cref:
+		r.cref(w, node)
 	case *ast.Emph:
 		r.outOneOf(w, entering, "<em>", "</em>")
 	case *ast.Strong:
@@ -903,9 +1068,18 @@ func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.Wal
 		r.htmlSpan(w, node)
 	case *ast.HTMLBlock:
 		r.htmlBlock(w, node)
+	case *ast.Footnotes:
+		// see the footnotes list
 	case *ast.List:
+		if node.IsFootnotesList {
+			return r.footnotes(w, node, entering)
+		}
 		r.list(w, node, entering)
 	case *ast.ListItem:
+		if node.RefLink != nil {
+			r.footnoteItem(w, node, entering)
+			break
+		}
 		r.listItem(w, node, entering)
 	case *ast.CodeBlock:
 		r.codeBlock(w, node)
@@ -937,6 +1111,8 @@ func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.Wal
 	case *ast.Aside:
 		tag := tagWithAttributes("<aside", html.BlockAttrs(node))
 		r.outOneOfCr(w, entering, tag, "</aside>")
+	case *mast.Div:
+		// RFC 7991 has no divs, only the contents are rendered.
 	case *ast.CrossReference:
 		r.crossReference(w, node, entering)
 	case *ast.Index:
@@ -944,10 +1120,16 @@ func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.Wal
 			r.index(w, node)
 		}
 	case *ast.Link:
+		if node.Footnote != nil {
+			if entering {
+				r.footnoteLink(w, node)
+			}
+			break
+		}
 		r.link(w, node, entering)
 	case *ast.Math:
 		r.outOneOf(w, true, "<tt>", "</tt>")
-		html.EscapeHTML(w, node.Literal)
+		html.EscapeHTML(w, []byte(tex.Text(string(node.Literal))))
 		r.outOneOf(w, false, "<tt>", "</tt>")
 	case *ast.Image:
 		if r.opts.Flags&SkipImages != 0 {
@@ -957,7 +1139,9 @@ func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.Wal
 	case *ast.Code:
 		r.code(w, node)
 	case *ast.MathBlock:
-		r.mathBlock(w, node)
+		if entering {
+			r.mathBlock(w, node)
+		}
 	case *ast.Subscript:
 		r.outOneOf(w, true, "<sub>", "</sub>")
 		if entering {
@@ -991,6 +1175,7 @@ func (r *Renderer) RenderFooter(w io.Writer, _ ast.Node) {
 
 	switch r.documentMatter {
 	case ast.DocumentMatterFront:
+		r.titleNotes(w)
 		r.outs(w, "\n</front>\n")
 	case ast.DocumentMatterMain:
 		r.outs(w, "\n</middle>\n")
@@ -0,0 +1,119 @@
+package xml
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/render/diagram"
+)
+
+// SourcecodeTypes are the types of <sourcecode> the RFC Editor recognizes, see
+// https://www.rfc-editor.org/materials/sourcecode-types.txt.
+var SourcecodeTypes = map[string]bool{
+	"abnf": true, "asn.1": true, "bash": true, "c": true, "c#": true, "c++": true, "cbor": true,
+	"cbor-diag": true, "cbor-pretty": true, "cddl": true, "dns-rr": true, "dtd": true, "go": true,
+	"html": true, "http-message": true, "java": true, "javascript": true, "json": true, "jsonpath": true,
+	"mib": true, "ocaml": true, "perl": true, "pseudocode": true, "python": true, "rnc": true, "rust": true,
+	"shell": true, "sieve": true, "sql": true, "swift": true, "tcl": true, "tls-presentation": true,
+	"typescript": true, "xml": true, "xsd": true, "yang": true,
+}
+
+// SourcecodeAliases maps the languages commonly used in a fenced code block to their sourcecode type.
+var SourcecodeAliases = map[string]string{
+	"asn1":       "asn.1",
+	"cpp":        "c++",
+	"csharp":     "c#",
+	"diag":       "cbor-diag",
+	"edn":        "cbor-diag",
+	"golang":     "go",
+	"http":       "http-message",
+	"js":         "javascript",
+	"py":         "python",
+	"rs":         "rust",
+	"sh":         "shell",
+	"ts":         "typescript",
+	"tls":        "tls-presentation",
+	"zsh":        "shell",
+	"relax-ng":   "rnc",
+	"abnf-rfc":   "abnf",
+	"yang-model": "yang",
+}
+
+// sourcecodeType returns the sourcecode type for the language of a fenced code block: the language is
+// looked up in the Sourcecode option and SourcecodeAliases. If the result isn't one of the
+// SourcecodeTypes a warning is logged (once per language), with a suggestion if there is a type
+// that looks like it. The languages of diagrams aren't warned about, these are only output as source
+// code when their conversion failed, which is reported, or is turned off.
+func (r *Renderer) sourcecodeType(lang string) string {
+	typ := strings.ToLower(lang)
+	if t, ok := r.opts.Sourcecode[typ]; ok {
+		return t
+	}
+	if t, ok := SourcecodeAliases[typ]; ok {
+		typ = t
+	}
+	if SourcecodeTypes[typ] || typ == "ascii-art" || r.warned[typ] || r.isDiagram(typ) {
+		return typ
+	}
+	if r.warned == nil {
+		r.warned = map[string]bool{}
+	}
+	r.warned[typ] = true
+	if s := suggestType(typ); s != "" {
+		r.opts.Report.Reportf(mast.SeverityWarning, "sourcecode", "unknown sourcecode type %q, did you mean %q?", lang, s)
+	} else {
+		r.opts.Report.Reportf(mast.SeverityWarning, "sourcecode", "unknown sourcecode type %q", lang)
+	}
+	return typ
+}
+
+// isDiagram returns true if lang is the language of a diagram, of the default engines or of the
+// Diagrams option.
+func (r *Renderer) isDiagram(lang string) bool {
+	if _, ok := diagram.Engines[lang]; ok {
+		return true
+	}
+	if r.opts.Diagrams == nil {
+		return false
+	}
+	_, ok := r.opts.Diagrams.Engines[lang]
+	return ok
+}
+
+// suggestType returns the sourcecode type closest to typ, or the empty string if none is close.
+func suggestType(typ string) string {
+	types := make([]string, 0, len(SourcecodeTypes))
+	for t := range SourcecodeTypes {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	best, dist := "", 3 // at most 2 edits
+	for _, t := range types {
+		if d := distance(typ, t); d < dist {
+			best, dist = t, d
+		}
+	}
+	return best
+}
+
+// distance returns the Levenshtein distance between a and b.
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,54 @@
+package xml
+
+import (
+	"testing"
+
+	"github.com/mmarkdown/mmark/v2/mast"
+	"github.com/mmarkdown/mmark/v2/render/diagram"
+)
+
+func TestSourcecodeType(t *testing.T) {
+	r := &Renderer{opts: RendererOptions{Sourcecode: map[string]string{"proto": "protobuf"}}}
+	tests := []struct {
+		lang string
+		want string
+	}{
+		{"go", "go"},
+		{"JS", "javascript"},
+		{"sh", "shell"},
+		{"proto", "protobuf"},
+		{"jsn", "jsn"},
+	}
+	for _, tc := range tests {
+		if got := r.sourcecodeType(tc.lang); got != tc.want {
+			t.Errorf("expected type %q for %q, got %q", tc.want, tc.lang, got)
+		}
+	}
+	if !r.warned["jsn"] {
+		t.Errorf("expected a warning for %q", "jsn")
+	}
+}
+
+func TestSourcecodeTypeDiagram(t *testing.T) {
+	r := &Renderer{opts: RendererOptions{
+		Diagrams: diagram.New(map[string]diagram.Engine{"ditaa": diagram.Command("ditaa - -")}),
+		Report:   func(d mast.Diagnostic) { t.Errorf("expected no warning, got %q", d.Message) },
+	}}
+	for _, lang := range []string{"mermaid", "plantuml", "ditaa"} {
+		if got := r.sourcecodeType(lang); got != lang {
+			t.Errorf("expected type %q for %q, got %q", lang, lang, got)
+		}
+	}
+	r.opts.Diagrams = nil
+	if got := r.sourcecodeType("dot"); got != "dot" {
+		t.Errorf("expected type %q for %q, got %q", "dot", "dot", got)
+	}
+}
+
+func TestSuggestType(t *testing.T) {
+	for in, want := range map[string]string{"jsn": "json", "pyhton": "python", "fortran": ""} {
+		if got := suggestType(in); got != want {
+			t.Errorf("expected suggestion %q for %q, got %q", want, in, got)
+		}
+	}
+}
@@ -3,10 +3,11 @@ package xml
 import (
 	"fmt"
 	"io"
-	"log"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/gomarkdown/markdown/ast"
 	"github.com/gomarkdown/markdown/html"
@@ -24,6 +25,39 @@ var StatusToCategory = map[string]string{
 	"historic":      "historic",
 }
 
+// rfcAttributes merges the rfcAttributes of the title block into attrs. These are added, sorted, after
+// our attributes and take precedence: an attribute we also set is replaced and an empty value removes
+// the attribute altogether.
+func rfcAttributes(attrs []string, extra map[string]interface{}) []string {
+	if len(extra) == 0 {
+		return attrs
+	}
+	keys := make([]string, 0, len(extra))
+	for k := range extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	merged := []string{}
+	for _, a := range attrs {
+		if k, _, _ := strings.Cut(a, "="); !hasKey(extra, k) {
+			merged = append(merged, a)
+		}
+	}
+	for _, k := range keys {
+		if extra[k] == nil {
+			continue
+		}
+		merged = append(merged, Attributes([]string{k}, []string{fmt.Sprint(extra[k])})...)
+	}
+	return merged
+}
+
+func hasKey(m map[string]interface{}, k string) bool {
+	_, ok := m[k]
+	return ok
+}
+
 func (r *Renderer) titleBlock(w io.Writer, t *mast.Title) {
 	// Order is fixed in RFC 7991.
 	d := t.TitleData
@@ -40,9 +74,18 @@ func (r *Renderer) titleBlock(w io.Writer, t *mast.Title) {
 		[]string{"3", d.Ipr, t.SeriesInfo.Value, d.SubmissionType, StatusToCategory[d.SeriesInfo.Status], "en", "http://www.w3.org/2001/XInclude"},
 	)
 	attrs = append(attrs, Attributes(
-		[]string{"updates", "obsoletes", "indexInclude"},
-		[]string{IntSliceToString(d.Updates), IntSliceToString(d.Obsoletes), fmt.Sprintf("%t", d.IndexInclude)},
+		[]string{"updates", "obsoletes", "iprExtract"},
+		[]string{IntSliceToString(d.Updates.Numbers()), IntSliceToString(d.Obsoletes.Numbers()), d.IprExtract},
 	)...)
+	// RFC 7991 Sections 2.45.6, 2.45.12 and 2.45.13: Default is true, only output when false.
+	for _, a := range []struct {
+		name  string
+		value bool
+	}{{"indexInclude", d.IndexInclude}, {"symRefs", d.SymRefs}, {"tocInclude", d.TocInclude}} {
+		if !a.value {
+			attrs = append(attrs, Attributes([]string{a.name}, []string{"false"})...)
+		}
+	}
 	// RFC 7841 Appendix A.2.2: IETF and IRTF streams pay attention to the consensus attribute.
 	// RFC 7991 Section 2.45.2: Default is false.
 	if ((d.SubmissionType == "IETF") || (d.SubmissionType == "IRTF")) && d.Consensus {
@@ -73,9 +116,15 @@ func (r *Renderer) titleBlock(w io.Writer, t *mast.Title) {
 			[]string{t.SeriesInfo.Value},
 		)...)
 	}
-	r.outTag(w, "<rfc", attrs)
+	r.outTag(w, "<rfc", rfcAttributes(attrs, d.RfcAttributes))
 	r.cr(w)
 
+	for _, l := range d.Links {
+		r.outTag(w, "<link", Attributes([]string{"href", "rel"}, []string{l.Href, l.Rel}))
+		r.outs(w, "</link>")
+		r.cr(w)
+	}
+
 	r.matter(w, &ast.DocumentMatter{Matter: ast.DocumentMatterFront})
 
 	attrs = Attributes([]string{"abbrev"}, []string{d.Abbrev})
@@ -83,7 +132,7 @@ func (r *Renderer) titleBlock(w io.Writer, t *mast.Title) {
 	r.outs(w, d.Title)
 	r.outs(w, "</title>")
 
-	r.titleSeriesInfo(w, d.SeriesInfo)
+	r.titleSeriesInfo(w, append([]reference.SeriesInfo{d.SeriesInfo}, d.SeriesInfos...))
 
 	for _, author := range d.Author {
 		r.TitleAuthor(w, author, "author")
@@ -107,14 +156,27 @@ func (r *Renderer) titleBlock(w io.Writer, t *mast.Title) {
 // TitleAuthor outputs the author.
 func (r *Renderer) TitleAuthor(w io.Writer, a mast.Author, tag string) {
 
+	if a.AsciiFullname == "" {
+		a.AsciiFullname = a.ASCII
+	}
+	if !isASCII(a.Fullname) && a.AsciiFullname == "" {
+		r.opts.Report.Reportf(mast.SeverityWarning, "title", "author %q has a non-ASCII fullname, but no asciiFullname, resulting XML may fail to validate", a.Fullname)
+	}
+	if !isASCII(a.Surname) && a.AsciiSurname == "" {
+		r.opts.Report.Reportf(mast.SeverityWarning, "title", "author %q has a non-ASCII surname, but no asciiSurname, resulting XML may fail to validate", a.Fullname)
+	}
+	role := a.Role
+	if role != "editor" { // RFC 7991 Section 2.7.4: "editor" is the only role.
+		role = ""
+	}
 	attrs := Attributes(
-		[]string{"role", "initials", "surname", "fullname"},
-		[]string{a.Role, a.Initials, a.Surname, a.Fullname},
+		[]string{"role", "initials", "asciiInitials", "surname", "asciiSurname", "fullname", "asciiFullname"},
+		[]string{role, a.Initials, a.AsciiInitials, a.Surname, a.AsciiSurname, a.Fullname, a.AsciiFullname},
 	)
 
 	r.outTag(w, "<"+tag, attrs)
 
-	r.outTag(w, "<organization", Attributes([]string{"abbrev"}, []string{a.OrganizationAbbrev}))
+	r.outTag(w, "<organization", Attributes([]string{"abbrev", "ascii"}, []string{a.OrganizationAbbrev, a.OrganizationASCII}))
 	html.EscapeHTML(w, []byte(a.Organization))
 	r.outs(w, "</organization>")
 
@@ -205,27 +267,124 @@ func (r *Renderer) TitleKeyword(w io.Writer, keyword []string) {
 	}
 }
 
-// titleSeriesInfo outputs the seriesInfo from the TOML title block.
-func (r *Renderer) titleSeriesInfo(w io.Writer, s reference.SeriesInfo) {
-	if s.Value == "" {
-		log.Printf("Empty 'value' in [seriesInfo], resulting XML may fail to parse.")
+// titleNotes outputs the notes that are generated from the title block, these go at the end of the
+// front matter.
+func (r *Renderer) titleNotes(w io.Writer) {
+	r.titleVenue(w)
+	r.titleDocuments(w)
+}
+
+// titleDocuments outputs the Internet-Drafts that are updated or obsoleted, only RFC numbers can be
+// given in the updates and obsoletes attributes, so these are mentioned in a note that is removed
+// when the document is published as an RFC.
+func (r *Renderer) titleDocuments(w io.Writer) {
+	if r.title == nil || r.title.TitleData == nil {
+		return
+	}
+	updates, obsoletes := r.title.Updates.Names(), r.title.Obsoletes.Names()
+	if len(updates) == 0 && len(obsoletes) == 0 {
+		return
+	}
+	r.cr(w)
+	r.outs(w, `<note removeInRFC="true"><name>Updated and Obsoleted Drafts</name>`)
+	r.cr(w)
+	if len(updates) > 0 {
+		r.outs(w, "<t>This document updates ")
+		html.EscapeHTML(w, []byte(strings.Join(updates, ", ")))
+		r.outs(w, ".</t>")
+		r.cr(w)
+	}
+	if len(obsoletes) > 0 {
+		r.outs(w, "<t>This document obsoletes ")
+		html.EscapeHTML(w, []byte(strings.Join(obsoletes, ", ")))
+		r.outs(w, ".</t>")
+		r.cr(w)
+	}
+	r.outs(w, "</note>")
+	r.cr(w)
+}
+
+// titleVenue outputs the venue from the title block as a note that is removed when the document is
+// published as an RFC, as RFC 7991 has no element for it.
+func (r *Renderer) titleVenue(w io.Writer) {
+	if r.title == nil || r.title.TitleData == nil || r.title.Venue == (mast.Venue{}) {
+		return
+	}
+	v := r.title.Venue
+	r.cr(w)
+	r.outs(w, `<note removeInRFC="true"><name>Discussion Venues</name>`)
+	r.cr(w)
+	if v.Mail != "" {
+		typ := v.Type
+		if typ == "" {
+			typ = "Working Group"
+		}
+		arch := v.Arch
+		if arch == "" {
+			arch = "https://mailarchive.ietf.org/arch/browse/" + strings.SplitN(v.Mail, "@", 2)[0] + "/"
+		}
+		r.outs(w, "<t>Discussion of this document takes place on the ")
+		html.EscapeHTML(w, []byte(strings.TrimSpace(v.Group+" "+typ)))
+		r.outs(w, " mailing list (")
+		html.EscapeHTML(w, []byte(v.Mail))
+		r.outs(w, "), which is archived at ")
+		r.outTag(w, "<eref", Attributes([]string{"target"}, []string{arch}))
+		r.outs(w, "</eref>.</t>")
+		r.cr(w)
 	}
-	if s.Stream == "" {
-		log.Printf("Empty 'stream' in [seriesInfo], resulting XML may fail to parse.")
+	if v.GitHub != "" {
+		repo := v.GitHub
+		if !strings.Contains(repo, "://") {
+			repo = "https://github.com/" + repo
+		}
+		r.outs(w, "<t>Source for this draft and an issue tracker can be found at ")
+		r.outTag(w, "<eref", Attributes([]string{"target"}, []string{repo}))
+		r.outs(w, "</eref>.</t>")
+		r.cr(w)
 	}
-	if s.Status == "" {
-		log.Printf("Empty 'status' in [seriesInfo], resulting XML may fail to parse.")
+	if v.Latest != "" {
+		r.outs(w, "<t>The latest revision of this draft can be found at ")
+		r.outTag(w, "<eref", Attributes([]string{"target"}, []string{v.Latest}))
+		r.outs(w, "</eref>.</t>")
+		r.cr(w)
 	}
-	if s.Name == "" {
-		log.Printf("Empty 'name' in [seriesInfo], resulting XML may fail to parse.")
+	r.outs(w, "</note>")
+	r.cr(w)
+}
+
+// titleSeriesInfo outputs the seriesInfo elements from the TOML title block. The first one is the
+// document's own, the others only need a name and value, i.e. the BCP number of an RFC.
+func (r *Renderer) titleSeriesInfo(w io.Writer, series []reference.SeriesInfo) {
+	for i, s := range series {
+		if s.Value == "" {
+			r.opts.Report.Reportf(mast.SeverityWarning, "title", "empty 'value' in [seriesInfo], resulting XML may fail to parse")
+		}
+		if s.Stream == "" && i == 0 {
+			r.opts.Report.Reportf(mast.SeverityWarning, "title", "empty 'stream' in [seriesInfo], resulting XML may fail to parse")
+		}
+		if s.Status == "" && i == 0 {
+			r.opts.Report.Reportf(mast.SeverityWarning, "title", "empty 'status' in [seriesInfo], resulting XML may fail to parse")
+		}
+		if s.Name == "" {
+			r.opts.Report.Reportf(mast.SeverityWarning, "title", "empty 'name' in [seriesInfo], resulting XML may fail to parse")
+		}
+		attr := Attributes(
+			[]string{"value", "stream", "status", "name"},
+			[]string{s.Value, s.Stream, s.Status, s.Name},
+		)
+
+		r.outTag(w, "<seriesInfo", attr)
+		r.outs(w, "</seriesInfo>\n")
 	}
-	attr := Attributes(
-		[]string{"value", "stream", "status", "name"},
-		[]string{s.Value, s.Stream, s.Status, s.Name},
-	)
+}
 
-	r.outTag(w, "<seriesInfo", attr)
-	r.outs(w, "</seriesInfo>\n")
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
 }
 
 // IntSliceToString converts and int slice to a string.
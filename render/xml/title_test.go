@@ -0,0 +1,82 @@
+package xml
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mmarkdown/mmark/v2/mast"
+)
+
+func TestTitleVenue(t *testing.T) {
+	r := NewRenderer(RendererOptions{})
+	r.title = &mast.Title{TitleData: &mast.TitleData{Venue: mast.Venue{Group: "QUIC", Mail: "quic@ietf.org", GitHub: "quicwg/base-drafts"}}}
+	buf := &bytes.Buffer{}
+	r.titleVenue(buf)
+	for _, want := range []string{
+		`<note removeInRFC="true"><name>Discussion Venues</name>`,
+		"QUIC Working Group mailing list (quic@ietf.org)",
+		`<eref target="https://mailarchive.ietf.org/arch/browse/quic/"></eref>`,
+		`<eref target="https://github.com/quicwg/base-drafts"></eref>`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %q in venue, got %s", want, buf.String())
+		}
+	}
+
+	r.title = &mast.Title{TitleData: &mast.TitleData{}}
+	buf.Reset()
+	r.titleVenue(buf)
+	if buf.Len() != 0 {
+		t.Errorf("expected no venue, got %s", buf.String())
+	}
+}
+
+func TestTitleAuthorASCII(t *testing.T) {
+	r := NewRenderer(RendererOptions{})
+	buf := &bytes.Buffer{}
+	r.TitleAuthor(buf, mast.Author{
+		Initials: "Я.", Surname: "Иванов", Fullname: "Яков Иванов",
+		AsciiInitials: "Y.", AsciiSurname: "Ivanov", AsciiFullname: "Yakov Ivanov",
+		Organization: "Организация", OrganizationASCII: "Organizatsiya",
+	}, "author")
+	for _, want := range []string{
+		`<author initials="Я." asciiInitials="Y." surname="Иванов" asciiSurname="Ivanov" fullname="Яков Иванов" asciiFullname="Yakov Ivanov">`,
+		`<organization ascii="Organizatsiya">Организация</organization>`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %q in author, got %s", want, buf.String())
+		}
+	}
+}
+
+func TestRFCAttributes(t *testing.T) {
+	attrs := Attributes([]string{"version", "ipr", "tocInclude"}, []string{"3", "trust200902", "true"})
+	got := strings.Join(rfcAttributes(attrs, map[string]interface{}{"tocInclude": false, "ipr": "", "symRefs": "true", "sortRefs": nil}), " ")
+	if want := `version="3" symRefs="true" tocInclude="false"`; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestTitleBlockDefaults(t *testing.T) {
+	r := NewRenderer(RendererOptions{})
+	title := mast.NewTitle()
+	buf := &bytes.Buffer{}
+	r.titleBlock(buf, title)
+	rfc, _, _ := strings.Cut(buf.String(), "\n")
+	for _, attr := range []string{"indexInclude", "tocInclude", "symRefs", "sortRefs", "iprExtract"} {
+		if strings.Contains(rfc, attr) {
+			t.Errorf("expected no %s with the defaults, got %s", attr, rfc)
+		}
+	}
+
+	title.TocInclude, title.SymRefs, title.IprExtract = false, false, "code"
+	buf.Reset()
+	r.titleBlock(buf, title)
+	rfc, _, _ = strings.Cut(buf.String(), "\n")
+	for _, want := range []string{`tocInclude="false"`, `symRefs="false"`, `iprExtract="code"`} {
+		if !strings.Contains(rfc, want) {
+			t.Errorf("expected %s, got %s", want, rfc)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/mmarkdown/mmark/v2/lang"
+	"github.com/mmarkdown/mmark/v2/mparser"
+	"github.com/mmarkdown/mmark/v2/render/text"
+)
+
+// statsCommand prints the statistics of documents: the words per section, the references, figures and
+// tables, the number of pages of the text output and the longest code line.
+func statsCommand(args []string) int {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print the statistics as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "SYNOPSIS: %s stats [OPTIONS] %s\n", os.Args[0], "FILE...")
+		fmt.Fprintln(fs.Output(), "\nShow the statistics of a document.\n\nOPTIONS:")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	status := 0
+	for _, fileName := range fs.Args() {
+		doc, d, documentLanguage, err := parseText(fileName)
+		if err != nil {
+			reporter.reportError(fileName, "read", err)
+			status = 1
+			continue
+		}
+		s := mparser.Statistics(doc, d)
+		// pages are separated by a form feed in the text output.
+		x := markdown.Render(doc, text.NewRenderer(text.RendererOptions{Language: lang.New(documentLanguage)}))
+		pages := bytes.Count(x, []byte("\f")) + 1
+
+		if *asJSON {
+			data, _ := json.MarshalIndent(struct {
+				File string `json:"file"`
+				mparser.Stats
+				Pages int `json:"pages"`
+			}{fileName, s, pages}, "", "  ")
+			fmt.Println(string(data))
+			continue
+		}
+		fmt.Printf("%s\n", fileName)
+		fmt.Printf("  words: %d, pages: %d\n", s.Words, pages)
+		fmt.Printf("  references: %d normative, %d informative\n", s.Normative, s.Informative)
+		fmt.Printf("  figures: %d, tables: %d\n", s.Figures, s.Tables)
+		if s.Longest > 0 {
+			fmt.Printf("  longest code line: %d characters (line %d)\n", s.Longest, s.LongestLine)
+		}
+		for _, sec := range s.Sections {
+			title := strings.Repeat("  ", sec.Level) + sec.Title
+			fmt.Printf("  %-60s %6d\n", title, sec.Words)
+		}
+	}
+	return status
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/v2/mparser"
+)
+
+// WatchInterval is how often -watch looks at the modification times of the documents and their files.
+const WatchInterval = time.Second
+
+// dependencies returns the files the document d in fileName is made of: the document itself, the files
+// it includes, its CSL-JSON files and its local images. The document is only parsed, remote includes
+// aren't fetched. It returns false if these can't be known: when d is read from standard input, or an
+// include failed, which is the case for a remote include.
+func dependencies(fileName string, d []byte) ([]string, bool) {
+	if fileName == "os.Stdin" {
+		return nil, false
+	}
+	deps := []string{fileName}
+	init := newInitial(fileName, d)
+	init.Report = func(mparser.Diagnostic) {} // reported when the document is rendered.
+	init.Record = func(dep string) { deps = append(deps, dep) }
+
+	p := parser.NewWithExtensions(mparser.Extensions)
+	mparser.RegisterInlines(p)
+	p.Opts = parser.Options{ParserHook: init.Hook, ReadIncludeFn: init.ReadInclude}
+	doc := markdown.Parse(append([]byte(nil), d...), p)
+	if init.Failed() {
+		return nil, false
+	}
+	init.AddCSL(doc)
+
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		img, ok := node.(*ast.Image)
+		if !entering || !ok {
+			return ast.GoToNext
+		}
+		dest := string(img.Destination)
+		if strings.Contains(dest, "://") || strings.HasPrefix(dest, "data:") {
+			return ast.GoToNext
+		}
+		if !filepath.IsAbs(dest) {
+			dest = filepath.Join(filepath.Dir(fileName), dest)
+		}
+		// a missing image isn't read, so the output doesn't depend on it.
+		if _, err := os.Stat(dest); err == nil {
+			deps = append(deps, dest)
+		}
+		return ast.GoToNext
+	})
+	return deps, true
+}
+
+// upToDate returns true if the files of all targets of the document d of in are newer than all the
+// files the document is made of, see dependencies.
+func upToDate(in input, d []byte, targets []target) bool {
+	deps, ok := dependencies(in.file, d)
+	if !ok {
+		return false
+	}
+	newest := time.Time{}
+	for _, dep := range deps {
+		fi, err := os.Stat(dep) // a git include can't be, so it's always rendered.
+		if err != nil {
+			return false
+		}
+		if fi.ModTime().After(newest) {
+			newest = fi.ModTime()
+		}
+	}
+	for _, t := range targets {
+		fi, err := os.Stat(targetFile(t, in))
+		if err != nil || !fi.ModTime().After(newest) {
+			return false
+		}
+	}
+	return true
+}
+
+// watch renders the documents in args to the targets, and again every time one of the files they are
+// made of changes, or a document is added to a tree. Only the outputs that are older than their files
+// are rendered, as with -incremental. It doesn't return.
+func watch(args []string, targets []target) {
+	var seen map[string]time.Time
+	for {
+		inputs, err := expandArgs(args)
+		if err != nil {
+			reporter.reportError("", "read", err)
+		}
+		if files := modTimes(inputs, seen); !maps.Equal(files, seen) {
+			renderInputs(inputs, targets)
+			seen = modTimes(inputs, nil)
+		}
+		time.Sleep(WatchInterval)
+	}
+}
+
+// modTimes returns the modification times of the files the inputs are made of. The files are those in
+// seen, when all of the inputs are in it, otherwise they're found again with dependencies. A file that
+// can't be read has the zero time.
+func modTimes(inputs []input, seen map[string]time.Time) map[string]time.Time {
+	files := map[string]time.Time{}
+	for _, in := range inputs {
+		if _, ok := seen[in.file]; !ok {
+			seen = nil
+		}
+	}
+	if seen == nil {
+		for _, in := range inputs {
+			files[in.file] = time.Time{}
+			d, err := readDocument(in.file)
+			if err != nil {
+				continue
+			}
+			deps, _ := dependencies(in.file, d)
+			for _, dep := range deps {
+				files[dep] = time.Time{}
+			}
+		}
+	} else {
+		for file := range seen {
+			files[file] = time.Time{}
+		}
+	}
+	for file := range files {
+		if fi, err := os.Stat(file); err == nil {
+			files[file] = fi.ModTime()
+		}
+	}
+	return files
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDependencies(t *testing.T) {
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "draft.md")
+	for file, data := range map[string]string{"part.md": "Part.\n", "figure.svg": "<svg/>"} {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d := []byte("# Introduction\n\n{{part.md}}\n\n![figure](figure.svg) ![missing](missing.svg) ![remote](https://example.org/a.svg)\n")
+	deps, ok := dependencies(doc, d)
+	if !ok {
+		t.Fatalf("expected the dependencies to be known")
+	}
+	want := []string{doc, filepath.Join(dir, "part.md"), filepath.Join(dir, "figure.svg")}
+	if !slices.Equal(deps, want) {
+		t.Errorf("expected %v, got %v", want, deps)
+	}
+
+	if _, ok := dependencies(doc, []byte("{{https://example.org/part.md}}\n")); ok {
+		t.Errorf("expected the dependencies of a remote include to be unknown")
+	}
+}
+
+func TestUpToDate(t *testing.T) {
+	dir := t.TempDir()
+	doc, part := filepath.Join(dir, "draft.md"), filepath.Join(dir, "part.md")
+	d := []byte("# Introduction\n\n{{part.md}}\n")
+	for file, data := range map[string][]byte{doc: d, part: []byte("Part.\n")} {
+		if err := os.WriteFile(file, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	targets, _ := outputTargets(nil, "html", filepath.Join(dir, "out"))
+	in := input{file: doc, name: "draft"}
+	if upToDate(in, d, targets) {
+		t.Fatalf("expected a missing output not to be up to date")
+	}
+
+	out := targetFile(targets[0], in)
+	if err := writeFile(out, []byte("<html>")); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for file, mtime := range map[string]time.Time{doc: now.Add(-2 * time.Hour), part: now.Add(-2 * time.Hour), out: now.Add(-time.Hour)} {
+		if err := os.Chtimes(file, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !upToDate(in, d, targets) {
+		t.Errorf("expected an output newer than its files to be up to date")
+	}
+	if err := os.Chtimes(part, now, now); err != nil {
+		t.Fatal(err)
+	}
+	if upToDate(in, d, targets) {
+		t.Errorf("expected an output older than an include not to be up to date")
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmarkdown/mmark/v2/mparser"
+)
+
+// xml2rfc converts the RFC 7991 XML x to format, "text", "html" or "pdf", with xml2rfc. When url is
+// empty the locally installed xml2rfc is run, otherwise the XML is posted to the service at url, i.e.
+// https://author-tools.ietf.org/api/render, with the format appended to it. The messages of xml2rfc are
+// returned as well, these are the lines of its output with a line number in the XML.
+func xml2rfc(x []byte, format, url string) ([]byte, []string, error) {
+	switch format {
+	case "text", "html", "pdf":
+	default:
+		return nil, nil, fmt.Errorf("unknown format %q, use \"text\", \"html\" or \"pdf\"", format)
+	}
+	if url != "" {
+		return xml2rfcService(x, format, url)
+	}
+
+	dir, err := os.MkdirTemp("", "mmark-xml2rfc")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	in, out := filepath.Join(dir, "draft.xml"), filepath.Join(dir, "draft.out")
+	if err := os.WriteFile(in, x, 0600); err != nil {
+		return nil, nil, err
+	}
+	cmd := exec.Command("xml2rfc", "--"+format, "--out", out, in)
+	output := &bytes.Buffer{}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	err = cmd.Run()
+	messages := xml2rfcMessages(output.String())
+	if err != nil {
+		return nil, messages, fmt.Errorf("xml2rfc: %s", err)
+	}
+	data, err := os.ReadFile(out)
+	return data, messages, err
+}
+
+// xml2rfcService is xml2rfc using the service at url.
+func xml2rfcService(x []byte, format, url string) ([]byte, []string, error) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	f, err := w.CreateFormFile("file", "draft.xml")
+	if err != nil {
+		return nil, nil, err
+	}
+	f.Write(x)
+	w.Close()
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Post(strings.TrimSuffix(url, "/")+"/"+format, w.FormDataContentType(), body)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		return data, nil, nil
+	}
+
+	// errors come back as JSON: {"error": "...", "logs": {"errors": [...], "warnings": [...]}}.
+	e := struct {
+		Error string
+		Logs  struct{ Errors, Warnings []string }
+	}{}
+	if json.Unmarshal(data, &e) != nil || e.Error == "" {
+		return nil, nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	messages := xml2rfcMessages(strings.Join(append(e.Logs.Errors, e.Logs.Warnings...), "\n"))
+	return nil, messages, fmt.Errorf("%s: %s", url, e.Error)
+}
+
+// xml2rfcLine matches a message of xml2rfc with a line number: "draft.xml(12): Warning: ...".
+var xml2rfcLine = regexp.MustCompile(`^\S*\((\d+)\): (.+)$`)
+
+func xml2rfcMessages(output string) []string {
+	messages := []string{}
+	for _, l := range strings.Split(output, "\n") {
+		if xml2rfcLine.MatchString(strings.TrimSpace(l)) {
+			messages = append(messages, strings.TrimSpace(l))
+		}
+	}
+	return messages
+}
+
+// xmlAnchor matches an anchor attribute in the XML.
+var xmlAnchor = regexp.MustCompile(`anchor="([^"]+)"`)
+
+// xml2rfcDiagnostic returns the message of xml2rfc as a diagnostic, with the line in the markdown
+// source of the closest anchor before the message's line in the XML x that is set in the source. When
+// there is no such anchor the line in the XML is put in the message instead. Errors of xml2rfc have
+// SeverityError.
+func xml2rfcDiagnostic(message string, x, source []byte) mparser.Diagnostic {
+	diag := mparser.Diagnostic{Message: message, Check: "xml2rfc"}
+	if strings.Contains(message, "Error:") {
+		diag.Severity = mparser.SeverityError
+	}
+	m := xml2rfcLine.FindStringSubmatch(message)
+	if m == nil {
+		return diag
+	}
+	line, _ := strconv.Atoi(m[1])
+	lines := bytes.Split(x, []byte("\n"))
+	if line > len(lines) {
+		line = len(lines)
+	}
+	for i := line - 1; i >= 0; i-- {
+		a := xmlAnchor.FindAllSubmatch(lines[i], -1)
+		if a == nil {
+			continue
+		}
+		anchor := string(a[len(a)-1][1])
+		if l := anchorLine(source, anchor); l > 0 {
+			diag.Line, diag.Message = l, fmt.Sprintf("%s (in #%s)", m[2], anchor)
+			return diag
+		}
+	}
+	diag.Message = fmt.Sprintf("%s (line %s of the XML)", m[2], m[1])
+	return diag
+}
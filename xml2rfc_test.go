@@ -0,0 +1,69 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestXML2RFCService(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/render/text" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "unsupported", "logs": {"errors": ["draft.xml(3): Error: no text"], "warnings": []}}`))
+			return
+		}
+		if f, _, err := r.FormFile("file"); err != nil || f == nil {
+			t.Errorf("expected the XML as file, got %v", err)
+		}
+		w.Write([]byte("Hello World\n"))
+	}))
+	defer srv.Close()
+
+	data, _, err := xml2rfc([]byte("<rfc/>"), "text", srv.URL+"/render")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "Hello World\n" {
+		t.Errorf("expected %q, got %q", "Hello World\n", data)
+	}
+
+	_, messages, err := xml2rfc([]byte("<rfc/>"), "html", srv.URL+"/render/")
+	if err == nil || len(messages) != 1 {
+		t.Errorf("expected an error with one message, got %v and %q", err, messages)
+	}
+}
+
+func TestXML2RFCLocal(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho 'x.xml(2): Warning: careful' >&2\nwhile [ \"$1\" != --out ]; do shift; done\necho converted > \"$2\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "xml2rfc"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	data, messages, err := xml2rfc([]byte("<rfc/>"), "text", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "converted\n" || len(messages) != 1 {
+		t.Errorf("expected %q and one message, got %q and %q", "converted\n", data, messages)
+	}
+}
+
+func TestXML2RFCDiagnostic(t *testing.T) {
+	source := []byte("# Introduction\n\nText.\n\n{#sec-a}\n# A\n\nMore.\n")
+	x := []byte("<section anchor=\"introduction\">\n<t>Text.</t>\n</section>\n<section anchor=\"sec-a\">\n<t>More.</t>\n")
+
+	tests := map[string]string{
+		"draft.xml(5): Warning: careful": "5: Warning: careful (in #sec-a) [xml2rfc]",
+		"draft.xml(2): Error: oops":      "Error: oops (line 2 of the XML) [xml2rfc]",
+	}
+	for message, expected := range tests {
+		if got := xml2rfcDiagnostic(message, x, source).String(); got != expected {
+			t.Errorf("expected %q, got %q", expected, got)
+		}
+	}
+}